@@ -1,26 +1,39 @@
 package api
 
 import (
-	"encoding/json"
+	"crypto/subtle"
 	"errors"
 	"fmt"
+	"math"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
 
+	"github.com/go-skynet/LocalAI/api/backend"
 	config "github.com/go-skynet/LocalAI/api/config"
+	fiberContext "github.com/go-skynet/LocalAI/api/ctx"
 	"github.com/go-skynet/LocalAI/api/localai"
 	"github.com/go-skynet/LocalAI/api/openai"
 	"github.com/go-skynet/LocalAI/api/options"
 	"github.com/go-skynet/LocalAI/api/schema"
 	"github.com/go-skynet/LocalAI/internal"
 	"github.com/go-skynet/LocalAI/metrics"
+	"github.com/go-skynet/LocalAI/pkg/apikeys"
 	"github.com/go-skynet/LocalAI/pkg/assets"
+	"github.com/go-skynet/LocalAI/pkg/compression"
+	"github.com/go-skynet/LocalAI/pkg/contentfilter"
 	"github.com/go-skynet/LocalAI/pkg/model"
+	"github.com/go-skynet/LocalAI/pkg/routing"
+	"github.com/go-skynet/LocalAI/pkg/scheduler"
 	"github.com/go-skynet/LocalAI/pkg/startup"
+	"github.com/go-skynet/LocalAI/pkg/telemetry"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/pprof"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -37,11 +50,31 @@ func Startup(opts ...options.AppOption) (*options.Option, *config.ConfigLoader,
 	log.Info().Msgf("Starting LocalAI using %d threads, with models path: %s", options.Threads, options.Loader.ModelPath)
 	log.Info().Msgf("LocalAI version: %s", internal.PrintableVersion())
 
+	// Backend assets are extracted first, as they are also needed while installing models (e.g. the GGUF converter)
+	if options.AssetsDestination != "" {
+		// Extract files from the embedded FS
+		err := assets.ExtractFiles(options.BackendAssets, options.AssetsDestination)
+		log.Debug().Msgf("Extracting backend assets files to %s", options.AssetsDestination)
+		if err != nil {
+			log.Warn().Msgf("Failed extracting backend assets files: %s (might be required for some backends to work properly, like gpt4all)", err)
+		}
+	}
+
 	startup.PreloadModelsConfigurations(options.ModelLibraryURL, options.Loader.ModelPath, options.ModelsURL...)
 
 	cl := config.NewConfigLoader()
-	if err := cl.LoadConfigs(options.Loader.ModelPath); err != nil {
-		log.Error().Msgf("error loading config files: %s", err.Error())
+	cl.SetSecrets(options.Secrets)
+	// the configurations of the first directories take precedence
+	dirs := options.Loader.ModelDirs()
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := cl.LoadConfigs(dirs[i].Path); err != nil {
+			log.Error().Msgf("error loading config files of %s: %s", dirs[i].Path, err.Error())
+		}
+		if options.TenantNamespaces {
+			if err := cl.LoadTenantConfigs(dirs[i].Path); err != nil {
+				log.Error().Msgf("error loading the config files of the tenants of %s: %s", dirs[i].Path, err.Error())
+			}
+		}
 	}
 
 	if options.ConfigFile != "" {
@@ -50,18 +83,32 @@ func Startup(opts ...options.AppOption) (*options.Option, *config.ConfigLoader,
 		}
 	}
 
+	if options.ModelRoutesFile != "" {
+		routes, err := routing.Load(options.ModelRoutesFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		cl.SetRoutes(routes)
+	}
+
 	if err := cl.Preload(options.Loader.ModelPath); err != nil {
 		log.Error().Msgf("error downloading models: %s", err.Error())
 	}
 
 	if options.PreloadJSONModels != "" {
-		if err := localai.ApplyGalleryFromString(options.Loader.ModelPath, options.PreloadJSONModels, cl, options.Galleries); err != nil {
+		if err := localai.ApplyGalleryFromString(options.Loader.ModelPath, options.PreloadJSONModels, cl, options.GetGalleries(), options.GalleryInstallOptions()...); err != nil {
 			return nil, nil, err
 		}
 	}
 
 	if options.PreloadModelsFromPath != "" {
-		if err := localai.ApplyGalleryFromFile(options.Loader.ModelPath, options.PreloadModelsFromPath, cl, options.Galleries); err != nil {
+		if err := localai.ApplyGalleryFromFile(options.Loader.ModelPath, options.PreloadModelsFromPath, cl, options.GetGalleries(), options.GalleryInstallOptions()...); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if options.BootstrapFile != "" {
+		if err := localai.ApplyBootstrapFile(options.BootstrapFile, cl, options); err != nil {
 			return nil, nil, err
 		}
 	}
@@ -73,15 +120,6 @@ func Startup(opts ...options.AppOption) (*options.Option, *config.ConfigLoader,
 		}
 	}
 
-	if options.AssetsDestination != "" {
-		// Extract files from the embedded FS
-		err := assets.ExtractFiles(options.BackendAssets, options.AssetsDestination)
-		log.Debug().Msgf("Extracting backend assets files to %s", options.AssetsDestination)
-		if err != nil {
-			log.Warn().Msgf("Failed extracting backend assets files: %s (might be required for some backends to work properly, like gpt4all)", err)
-		}
-	}
-
 	// turn off any process that was started by GRPC if the context is canceled
 	go func() {
 		<-options.Context.Done()
@@ -89,6 +127,18 @@ func Startup(opts ...options.AppOption) (*options.Option, *config.ConfigLoader,
 		options.Loader.StopAllGRPC()
 	}()
 
+	if options.Workspace != nil {
+		go options.Workspace.Run(options.Context)
+	}
+
+	if options.Telemetry != nil {
+		go options.Telemetry.Run(options.Context)
+	}
+
+	if options.CrashDir != "" {
+		options.Loader.SetCrashDir(options.CrashDir)
+	}
+
 	if options.WatchDog {
 		wd := model.NewWatchDog(
 			options.Loader,
@@ -108,6 +158,55 @@ func Startup(opts ...options.AppOption) (*options.Option, *config.ConfigLoader,
 	return options, cl, nil
 }
 
+// errorHandler returns errors as JSON responses
+func errorHandler(ctx *fiber.Ctx, err error) error {
+	// Status code defaults to 500
+	code := fiber.StatusInternalServerError
+
+	// Retrieve the custom status code if it's a *fiber.Error
+	var e *fiber.Error
+	if errors.As(err, &e) {
+		code = e.Code
+	}
+
+	// the queue of the model is full
+	var full *scheduler.QueueFullError
+	if errors.As(err, &full) {
+		code = fiber.StatusTooManyRequests
+		ctx.Set("Retry-After", strconv.Itoa(int(math.Ceil(full.RetryAfter.Seconds()))))
+	}
+
+	// a content filter refused the prompt or the response
+	var rejected *contentfilter.RejectedError
+	if errors.As(err, &rejected) {
+		code = fiber.StatusBadRequest
+	}
+
+	// images were given to a model which can't read them
+	if errors.Is(err, backend.ErrNoVision) {
+		code = fiber.StatusBadRequest
+	}
+
+	// Send custom error page
+	return ctx.Status(code).JSON(
+		schema.ErrorResponse{
+			Error: &schema.APIError{Message: err.Error(), Code: code},
+		},
+	)
+}
+
+// newBatchApp returns the internal app executing the requests of the batches, which are already authenticated. Their
+// usage is accounted to the caller which created the batch, and the routes check its quotas with batchMiddleware.
+func newBatchApp(o *options.Option) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: errorHandler})
+	app.Use(recover.New())
+	app.Use(fiberContext.RequestMiddleware())
+	if o.Usage != nil {
+		app.Use(usageMiddleware(o.Usage))
+	}
+	return app
+}
+
 func App(opts ...options.AppOption) (*fiber.App, error) {
 
 	options, cl, err := Startup(opts...)
@@ -115,28 +214,33 @@ func App(opts ...options.AppOption) (*fiber.App, error) {
 		return nil, fmt.Errorf("failed basic startup tasks with error %s", err.Error())
 	}
 
+	// the smoke tests of the models are run when they are installed or reloaded
+	smokeTests := localai.NewSmokeTester(cl, options)
+
+	// the config files are scanned periodically, and on SIGHUP
+	watcher := localai.NewConfigWatcher(cl, options)
+	watcher.OnChange(func(models ...string) { smokeTests.RunChanged(models...) })
+	go watcher.Run(options.Context, options.ConfigWatchInterval)
+	go func() {
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		defer signal.Stop(hup)
+		for {
+			select {
+			case <-options.Context.Done():
+				return
+			case <-hup:
+				watcher.Rescan()
+			}
+		}
+	}()
+
 	// Return errors as JSON responses
 	app := fiber.New(fiber.Config{
 		BodyLimit:             options.UploadLimitMB * 1024 * 1024, // this is the default limit of 4MB
 		DisableStartupMessage: options.DisableMessage,
 		// Override default error handler
-		ErrorHandler: func(ctx *fiber.Ctx, err error) error {
-			// Status code defaults to 500
-			code := fiber.StatusInternalServerError
-
-			// Retrieve the custom status code if it's a *fiber.Error
-			var e *fiber.Error
-			if errors.As(err, &e) {
-				code = e.Code
-			}
-
-			// Send custom error page
-			return ctx.Status(code).JSON(
-				schema.ErrorResponse{
-					Error: &schema.APIError{Message: err.Error(), Code: code},
-				},
-			)
-		},
+		ErrorHandler: errorHandler,
 	})
 
 	if options.Debug {
@@ -147,53 +251,43 @@ func App(opts ...options.AppOption) (*fiber.App, error) {
 
 	// Default middleware config
 	app.Use(recover.New())
-	if options.Metrics != nil {
-		app.Use(metrics.APIMiddleware(options.Metrics))
+	unlogged := []string{"/healthz", "/readyz", "/healthz/backends", "/readyz/backends", "/metrics"}
+	app.Use(fiberContext.RequestMiddleware(unlogged...))
+	if options.AuditLog != nil {
+		app.Use(auditMiddleware(options.AuditLog, unlogged...))
 	}
-
-	// Auth middleware checking if API key is valid. If no API key is set, no auth is required.
-	auth := func(c *fiber.Ctx) error {
-		if len(options.ApiKeys) == 0 {
-			return c.Next()
-		}
-
-		// Check for api_keys.json file
-		fileContent, err := os.ReadFile("api_keys.json")
-		if err == nil {
-			// Parse JSON content from the file
-			var fileKeys []string
-			err := json.Unmarshal(fileContent, &fileKeys)
-			if err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Error parsing api_keys.json"})
-			}
-
-			// Add file keys to options.ApiKeys
-			options.ApiKeys = append(options.ApiKeys, fileKeys...)
-		}
-
-		if len(options.ApiKeys) == 0 {
-			return c.Next()
-		}
-
-		authHeader := c.Get("Authorization")
-		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "Authorization header missing"})
-		}
-		authHeaderParts := strings.Split(authHeader, " ")
-		if len(authHeaderParts) != 2 || authHeaderParts[0] != "Bearer" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "Invalid Authorization header format"})
-		}
-
-		apiKey := authHeaderParts[1]
-		for _, key := range options.ApiKeys {
-			if apiKey == key {
-				return c.Next()
+	if options.Usage != nil {
+		app.Use(usageMiddleware(options.Usage, unlogged...))
+		go func() {
+			<-options.Context.Done()
+			if err := options.Usage.Close(); err != nil {
+				log.Error().Msgf("Failed writing the usage: %s", err.Error())
 			}
+		}()
+	}
+	if options.Metrics != nil {
+		app.Use(metrics.APIMiddleware(options.Metrics))
+		options.Loader.SetLoadObserver(options.Metrics.ObserveBackendLoad)
+		if err := options.Metrics.ObserveBackendProcesses(options.Loader.LoadedBackends); err != nil {
+			return nil, err
 		}
+	}
+	if options.Telemetry != nil {
+		app.Use(telemetry.APIMiddleware(options.Telemetry))
+	}
+	app.Use(compression.RequestMiddleware(options.UploadLimitMB * 1024 * 1024))
 
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "Invalid API key"})
+	// compresses the responses of the endpoints returning large bodies, which are not streamed
+	compress := compression.ResponseMiddleware()
 
-	}
+	// Auth middleware checking if API key is valid. If no API key is set, no auth is required.
+	auth := authMiddleware(options, "")
+	// the same, for the endpoints restricted to the API keys with the given scope
+	chat := authMiddleware(options, apikeys.ScopeChat)
+	embeddings := authMiddleware(options, apikeys.ScopeEmbeddings)
+	audio := authMiddleware(options, apikeys.ScopeAudio)
+	images := authMiddleware(options, apikeys.ScopeImages)
+	admin := authMiddleware(options, apikeys.ScopeAdmin)
 
 	if options.CORS {
 		var c func(ctx *fiber.Ctx) error
@@ -207,7 +301,8 @@ func App(opts ...options.AppOption) (*fiber.App, error) {
 	}
 
 	// LocalAI API endpoints
-	galleryService := localai.NewGalleryService(options.Loader.ModelPath)
+	galleryService := localai.NewGalleryService(options.Loader.ModelPath, options.GalleryInstallOptions()...)
+	galleryService.SetSmokeTester(smokeTests)
 	galleryService.Start(options.Context, cl)
 
 	app.Get("/version", auth, func(c *fiber.Ctx) error {
@@ -220,49 +315,152 @@ func App(opts ...options.AppOption) (*fiber.App, error) {
 	os.MkdirAll(options.ImageDir, 0755)
 	os.MkdirAll(options.AudioDir, 0755)
 	os.MkdirAll(options.Loader.ModelPath, 0755)
+	if options.TraceDir != "" {
+		os.MkdirAll(options.TraceDir, 0755)
+	}
 
-	modelGalleryService := localai.CreateModelGalleryService(options.Galleries, options.Loader.ModelPath, galleryService)
-	app.Post("/models/apply", auth, modelGalleryService.ApplyModelGalleryEndpoint())
-	app.Get("/models/available", auth, modelGalleryService.ListModelFromGalleryEndpoint())
-	app.Get("/models/galleries", auth, modelGalleryService.ListModelGalleriesEndpoint())
-	app.Post("/models/galleries", auth, modelGalleryService.AddModelGalleryEndpoint())
-	app.Delete("/models/galleries", auth, modelGalleryService.RemoveModelGalleryEndpoint())
-	app.Get("/models/jobs/:uuid", auth, modelGalleryService.GetOpStatusEndpoint())
-	app.Get("/models/jobs", auth, modelGalleryService.GetAllStatusEndpoint())
+	modelGalleryService := localai.CreateModelGalleryService(options, galleryService)
+	app.Post("/models/apply", admin, modelGalleryService.ApplyModelGalleryEndpoint())
+	app.Get("/models/available", admin, modelGalleryService.ListModelFromGalleryEndpoint())
+	app.Get("/models/search", admin, compress, modelGalleryService.SearchModelsEndpoint())
+	app.Get("/models/galleries", admin, modelGalleryService.ListModelGalleriesEndpoint())
+	app.Post("/models/galleries", admin, modelGalleryService.AddModelGalleryEndpoint())
+	app.Delete("/models/galleries", admin, modelGalleryService.RemoveModelGalleryEndpoint())
+	app.Post("/models/quantize", admin, modelGalleryService.QuantizeModelEndpoint(cl, options))
+	app.Get("/models/jobs/:uuid", admin, modelGalleryService.GetOpStatusEndpoint())
+	app.Get("/models/jobs", admin, modelGalleryService.GetAllStatusEndpoint())
+	app.Get("/models/updates", admin, modelGalleryService.ListUpdatesEndpoint())
+	app.Post("/models/updates/check", admin, modelGalleryService.CheckUpdatesEndpoint())
+	app.Post("/models/updates/policy", admin, modelGalleryService.UpdatePolicyEndpoint())
+	modelGalleryService.StartGalleriesRefresh(options.Context, options.GalleriesRefreshInterval)
+	app.Post("/bootstrap", admin, localai.BootstrapEndpoint(cl, options))
+	app.Post("/config/diff", admin, localai.ConfigDiffEndpoint(cl, options))
+	app.Post("/config/apply", admin, localai.ConfigApplyEndpoint(cl, options))
+	app.Post("/models/smoke-test", admin, smokeTests.SmokeTestEndpoint())
+	app.Get("/models/smoke-test", admin, smokeTests.SmokeTestResultsEndpoint())
+	app.Post("/models/rollout", admin, smokeTests.RolloutEndpoint())
+	// registered after the other /models routes, which are not model names
+	app.Delete("/models/:name", admin, localai.DeleteModelEndpoint(cl, options))
 
 	// openAI compatible API endpoint
 
 	// chat
-	app.Post("/v1/chat/completions", auth, openai.ChatEndpoint(cl, options))
-	app.Post("/chat/completions", auth, openai.ChatEndpoint(cl, options))
+	app.Post("/v1/chat/completions", chat, openai.ChatEndpoint(cl, options))
+	app.Post("/chat/completions", chat, openai.ChatEndpoint(cl, options))
 
 	// edit
-	app.Post("/v1/edits", auth, openai.EditEndpoint(cl, options))
-	app.Post("/edits", auth, openai.EditEndpoint(cl, options))
+	app.Post("/v1/edits", chat, openai.EditEndpoint(cl, options))
+	app.Post("/edits", chat, openai.EditEndpoint(cl, options))
 
 	// completion
-	app.Post("/v1/completions", auth, openai.CompletionEndpoint(cl, options))
-	app.Post("/completions", auth, openai.CompletionEndpoint(cl, options))
-	app.Post("/v1/engines/:model/completions", auth, openai.CompletionEndpoint(cl, options))
+	app.Post("/v1/completions", chat, openai.CompletionEndpoint(cl, options))
+	app.Post("/completions", chat, openai.CompletionEndpoint(cl, options))
+	app.Post("/v1/engines/:model/completions", chat, openai.CompletionEndpoint(cl, options))
 
 	// embeddings
-	app.Post("/v1/embeddings", auth, openai.EmbeddingsEndpoint(cl, options))
-	app.Post("/embeddings", auth, openai.EmbeddingsEndpoint(cl, options))
-	app.Post("/v1/engines/:model/embeddings", auth, openai.EmbeddingsEndpoint(cl, options))
+	app.Post("/v1/embeddings", embeddings, compress, openai.EmbeddingsEndpoint(cl, options))
+	app.Post("/embeddings", embeddings, compress, openai.EmbeddingsEndpoint(cl, options))
+	app.Post("/v1/engines/:model/embeddings", embeddings, compress, openai.EmbeddingsEndpoint(cl, options))
+	app.Post("/embeddings/ensemble", embeddings, compress, localai.EnsembleEmbeddingsEndpoint(cl, options))
+
+	// moderations
+	app.Post("/v1/moderations", chat, openai.ModerationEndpoint(cl, options))
+	app.Post("/moderations", chat, openai.ModerationEndpoint(cl, options))
+
+	// tokenization
+	app.Post("/v1/tokenize", chat, localai.TokenizeEndpoint(cl, options))
+	app.Post("/v1/detokenize", chat, localai.DetokenizeEndpoint(cl, options))
+
+	// files
+	var files *openai.FilesService
+	var voices *openai.VoicesService
+	if options.FilesDir != "" {
+		files = openai.NewFilesService(options.FilesDir, int64(options.FilesQuotaMB)*1024*1024, callerFingerprint, callerTenant)
+		app.Post("/v1/files", chat, files.UploadFileEndpoint())
+		app.Get("/v1/files", chat, compress, files.ListFilesEndpoint())
+		app.Get("/v1/files/:file_id", chat, files.GetFileEndpoint())
+		app.Get("/v1/files/:file_id/content", chat, compress, files.GetFileContentEndpoint())
+		app.Delete("/v1/files/:file_id", chat, files.DeleteFileEndpoint())
+
+		// voices cloned from the samples of the files
+		voices = openai.NewVoicesService(options.FilesDir, cl, options, files, callerFingerprint, callerTenant)
+		app.Post("/v1/audio/voices", audio, voices.CreateVoiceEndpoint())
+		app.Get("/v1/audio/voices", audio, voices.ListVoicesEndpoint())
+		app.Get("/v1/audio/voices/:voice_id", audio, voices.GetVoiceEndpoint())
+		app.Delete("/v1/audio/voices/:voice_id", audio, voices.DeleteVoiceEndpoint())
+
+		batchApp := newBatchApp(options)
+		batchApp.Post("/v1/chat/completions", batchMiddleware(options, apikeys.ScopeChat), openai.ChatEndpoint(cl, options))
+		batchApp.Post("/v1/completions", batchMiddleware(options, apikeys.ScopeChat), openai.CompletionEndpoint(cl, options))
+		batchApp.Post("/v1/embeddings", batchMiddleware(options, apikeys.ScopeEmbeddings), openai.EmbeddingsEndpoint(cl, options))
+
+		batches := openai.NewBatchService(options.Context, files, batchApp.Handler(), options.BatchWorkers)
+		app.Post("/v1/batches", chat, batches.CreateBatchEndpoint())
+		app.Get("/v1/batches", chat, compress, batches.ListBatchesEndpoint())
+		app.Get("/v1/batches/:batch_id", chat, batches.GetBatchEndpoint())
+		app.Post("/v1/batches/:batch_id/cancel", chat, batches.CancelBatchEndpoint())
+	}
+
+	// assistants
+	if options.AssistantsDir != "" {
+		assistants := openai.NewAssistantsService(options.AssistantsDir, cl, options, files)
+		app.Post("/v1/assistants", chat, assistants.CreateAssistantEndpoint())
+		app.Get("/v1/assistants", chat, assistants.ListAssistantsEndpoint())
+		app.Get("/v1/assistants/:assistant_id", chat, assistants.GetAssistantEndpoint())
+		app.Post("/v1/assistants/:assistant_id", chat, assistants.ModifyAssistantEndpoint())
+		app.Delete("/v1/assistants/:assistant_id", chat, assistants.DeleteAssistantEndpoint())
+		app.Post("/v1/threads", chat, assistants.CreateThreadEndpoint())
+		app.Post("/v1/threads/runs", chat, assistants.CreateThreadAndRunEndpoint())
+		app.Post("/v1/threads/import", chat, assistants.ImportThreadEndpoint())
+		app.Get("/v1/threads/:thread_id", chat, assistants.GetThreadEndpoint())
+		app.Post("/v1/threads/:thread_id", chat, assistants.ModifyThreadEndpoint())
+		app.Delete("/v1/threads/:thread_id", chat, assistants.DeleteThreadEndpoint())
+		app.Get("/v1/threads/:thread_id/export", chat, assistants.ExportThreadEndpoint())
+		app.Post("/v1/threads/:thread_id/messages", chat, assistants.CreateMessageEndpoint())
+		app.Get("/v1/threads/:thread_id/messages", chat, assistants.ListMessagesEndpoint())
+		app.Get("/v1/threads/:thread_id/messages/:message_id", chat, assistants.GetMessageEndpoint())
+		app.Post("/v1/threads/:thread_id/runs", chat, assistants.CreateRunEndpoint())
+		app.Get("/v1/threads/:thread_id/runs", chat, assistants.ListRunsEndpoint())
+		app.Get("/v1/threads/:thread_id/runs/:run_id", chat, assistants.GetRunEndpoint())
+		app.Post("/v1/threads/:thread_id/runs/:run_id/cancel", chat, assistants.CancelRunEndpoint())
+		app.Post("/v1/threads/:thread_id/runs/:run_id/submit_tool_outputs", chat, assistants.SubmitToolOutputsEndpoint())
+	}
+
+	// vector store
+	if options.VectorStore != nil {
+		app.Get("/stores", embeddings, localai.ListStoresEndpoint(options))
+		app.Post("/stores", embeddings, localai.CreateStoreEndpoint(options))
+		app.Get("/stores/:name", embeddings, localai.GetStoreEndpoint(options))
+		app.Delete("/stores/:name", embeddings, localai.DeleteStoreEndpoint(options))
+		app.Post("/stores/:name/upsert", embeddings, localai.UpsertStoreEndpoint(cl, options))
+		app.Post("/stores/:name/query", embeddings, compress, localai.QueryStoreEndpoint(cl, options))
+		app.Post("/stores/:name/delete", embeddings, localai.DeleteVectorsEndpoint(options))
+	}
 
 	// audio
-	app.Post("/v1/audio/transcriptions", auth, openai.TranscriptEndpoint(cl, options))
-	app.Post("/tts", auth, localai.TTSEndpoint(cl, options))
+	app.Post("/v1/audio/transcriptions", audio, openai.TranscriptEndpoint(cl, options))
+	app.Post("/v1/audio/translations", audio, openai.TranslationEndpoint(cl, options))
+	app.Post("/v1/audio/speech", audio, openai.SpeechEndpoint(cl, options, voices))
+	app.Post("/v1/audio/vad", audio, openai.VADEndpoint(cl, options))
+	app.Post("/tts", audio, localai.TTSEndpoint(cl, options))
+
+	// realtime
+	app.Get("/v1/realtime", chat, openai.RealtimeEndpoint(cl, options))
 
 	// images
-	app.Post("/v1/images/generations", auth, openai.ImageEndpoint(cl, options))
+	app.Post("/v1/images/generations", images, openai.ImageEndpoint(cl, options))
+	app.Post("/v1/images/edits", images, openai.ImageEditEndpoint(cl, options))
+	app.Post("/v1/images/variations", images, openai.ImageVariationEndpoint(cl, options))
+	app.Post("/v1/images/upscale", images, openai.UpscaleEndpoint(cl, options))
 
 	if options.ImageDir != "" {
-		app.Static("/generated-images", options.ImageDir)
+		app.Use("/generated-images", fiberContext.ConditionalStatic("/generated-images", options.ImageDir))
+		app.Static("/generated-images", options.ImageDir, fiber.Static{ByteRange: true})
 	}
 
 	if options.AudioDir != "" {
-		app.Static("/generated-audio", options.AudioDir)
+		app.Use("/generated-audio", fiberContext.ConditionalStatic("/generated-audio", options.AudioDir))
+		app.Static("/generated-audio", options.AudioDir, fiber.Static{ByteRange: true})
 	}
 
 	ok := func(c *fiber.Ctx) error {
@@ -272,11 +470,21 @@ func App(opts ...options.AppOption) (*fiber.App, error) {
 	// Kubernetes health checks
 	app.Get("/healthz", ok)
 	app.Get("/readyz", ok)
+	// the variants checking the backends of the loaded models
+	app.Get("/healthz/backends", localai.BackendHealthEndpoint(options))
+	app.Get("/readyz/backends", localai.BackendHealthEndpoint(options))
 
 	// Experimental Backend Statistics Module
 	backendMonitor := localai.NewBackendMonitor(cl, options) // Split out for now
 	app.Get("/backend/monitor", localai.BackendMonitorEndpoint(backendMonitor))
 	app.Post("/backend/shutdown", localai.BackendShutdownEndpoint(backendMonitor))
+	app.Post("/backend/load", admin, localai.BackendLoadEndpoint(cl, options))
+	app.Post("/backend/unload", admin, localai.BackendUnloadEndpoint(cl, options))
+	app.Get("/backend/external", admin, localai.ListExternalBackendsEndpoint(options))
+	app.Post("/backend/external", admin, localai.RegisterExternalBackendEndpoint(options))
+	app.Delete("/backend/external", admin, localai.UnregisterExternalBackendEndpoint(options))
+	app.Get("/backend/prompt-cache", admin, localai.PromptCacheEndpoint())
+	app.Get("/backend/throughput", admin, localai.ThroughputEndpoint())
 
 	// models
 	app.Get("/v1/models", auth, openai.ListModelsEndpoint(options.Loader, cl))
@@ -284,5 +492,54 @@ func App(opts ...options.AppOption) (*fiber.App, error) {
 
 	app.Get("/metrics", metrics.MetricsHandler())
 
+	// the admin key is only accepted for the endpoints of the admins
+	isAdminKey := func(c *fiber.Ctx) bool {
+		key := strings.TrimPrefix(c.Get("Authorization"), "Bearer ")
+		return options.AdminKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(options.AdminKey)) == 1
+	}
+	// requests with the admin key skip the check of the API key
+	orAdminKey := func(next fiber.Handler) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			if isAdminKey(c) {
+				return c.Next()
+			}
+			return next(c)
+		}
+	}
+
+	if options.Usage != nil {
+		isAdmin := func(c *fiber.Ctx) bool {
+			k, ok := requestAPIKey(c)
+			return isAdminKey(c) || (ok && k.Allows(apikeys.ScopeAdmin))
+		}
+		app.Get("/usage", orAdminKey(auth), localai.UsageEndpoint(options, isAdmin, callerFingerprint))
+	}
+
+	if options.ApiKeyStore != nil {
+		if options.ApiKeyStore.Empty() && len(options.GetApiKeys()) == 0 && options.AdminKey == "" && options.OIDC == nil {
+			log.Warn().Msgf("The API key store is empty and there is no static or admin key to create the first key: all the requests will be refused")
+		}
+		app.Get("/keys", orAdminKey(admin), localai.ListAPIKeysEndpoint(options))
+		app.Post("/keys", orAdminKey(admin), localai.CreateAPIKeyEndpoint(options))
+		app.Delete("/keys/:id", orAdminKey(admin), localai.RevokeAPIKeyEndpoint(options))
+	}
+
+	// profiling and diagnostics, for the admins only
+	if options.AdminKey != "" {
+		adminKey := func(c *fiber.Ctx) error {
+			if !isAdminKey(c) {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "Invalid admin key"})
+			}
+			return c.Next()
+		}
+		app.Use("/debug", adminKey, pprof.New())
+		app.Get("/debug/diagnostics", localai.DiagnosticsEndpoint(options))
+		app.Get("/debug/crashes", localai.CrashesEndpoint(options))
+		app.Get("/debug/crashes/:model", localai.CrashesEndpoint(options))
+		app.Get("/debug/logs", localai.LogsEndpoint(options))
+	}
+
+	app.Get("/openapi.json", openAPIEndpoint(app))
+
 	return app, nil
 }
@@ -12,13 +12,17 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"time"
 
 	. "github.com/go-skynet/LocalAI/api"
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/localai"
 	"github.com/go-skynet/LocalAI/api/options"
 	"github.com/go-skynet/LocalAI/metrics"
 	"github.com/go-skynet/LocalAI/pkg/downloader"
 	"github.com/go-skynet/LocalAI/pkg/gallery"
 	"github.com/go-skynet/LocalAI/pkg/model"
+	"github.com/go-skynet/LocalAI/pkg/routing"
 	"github.com/gofiber/fiber/v2"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -172,6 +176,10 @@ var _ = Describe("API test", func() {
 				},
 			}
 
+			routes := filepath.Join(GinkgoT().TempDir(), "routes.yaml")
+			err = os.WriteFile(routes, []byte("- match: gpt-4\n  model: bert\n  parameters:\n    temperature: 0.1\n- match: gpt-3.5-*\n  model: bert\n"), 0644)
+			Expect(err).ToNot(HaveOccurred())
+
 			metricsService, err := metrics.SetupMetrics()
 			Expect(err).ToNot(HaveOccurred())
 
@@ -180,6 +188,8 @@ var _ = Describe("API test", func() {
 					options.WithMetrics(metricsService),
 					options.WithContext(c),
 					options.WithGalleries(galleries),
+					options.WithModelRoutesFile(routes),
+					options.WithConfigWatchInterval(100*time.Millisecond),
 					options.WithModelLoader(modelLoader), options.WithBackendAssets(backendAssets), options.WithBackendAssetsOutput(tmpdir))...)
 			Expect(err).ToNot(HaveOccurred())
 			go app.Listen("127.0.0.1:9090")
@@ -303,6 +313,99 @@ var _ = Describe("API test", func() {
 				Expect(content["backend"]).To(Equal("bert-embeddings"))
 			})
 
+			It("routes the aliases to the configured models", func() {
+				Expect(os.WriteFile(filepath.Join(tmpdir, "bert.yaml"), []byte("name: bert\nparameters:\n  model: bert.bin\n  temperature: 0.7\n  top_p: 0.5\n"), 0644)).To(Succeed())
+
+				models, err := client.ListModels(context.TODO())
+				Expect(err).ToNot(HaveOccurred())
+				ids := []string{}
+				for _, m := range models.Models {
+					ids = append(ids, m.ID)
+				}
+				// the wildcard rules are not listed
+				Expect(ids).To(ContainElement("gpt-4"))
+				Expect(ids).ToNot(ContainElement("gpt-3.5-*"))
+
+				cm := config.NewConfigLoader()
+				routes, err := routing.New([]routing.Rule{{Match: "gpt-4", Model: "bert", Parameters: map[string]interface{}{"temperature": 0.1}}})
+				Expect(err).ToNot(HaveOccurred())
+				cm.SetRoutes(routes)
+				cfg, err := config.Load("gpt-4", tmpdir, cm, false, 4, 512, false)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(cfg.Name).To(Equal("bert"))
+				Expect(cfg.Model).To(Equal("bert.bin"))
+				Expect(cfg.Temperature).To(Equal(0.1))
+				Expect(cfg.TopP).To(Equal(0.5))
+			})
+
+			It("deletes the models and their files", func() {
+				Expect(os.WriteFile(filepath.Join(tmpdir, "doomed.yaml"), []byte("name: doomed\nmmproj: shared.bin\nparameters:\n  model: doomed.gguf\n"), 0644)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(tmpdir, "other.yaml"), []byte("name: other\nmmproj: shared.bin\nparameters:\n  model: other.gguf\n"), 0644)).To(Succeed())
+				for _, f := range []string{"doomed.gguf", "doomed.gguf.tmpl", "shared.bin"} {
+					Expect(os.WriteFile(filepath.Join(tmpdir, f), []byte("weights"), 0644)).To(Succeed())
+				}
+				deleteModel := func(query string) (int, localai.DeleteModelResponse) {
+					req, err := http.NewRequest(http.MethodDelete, "http://127.0.0.1:9090/models/doomed"+query, nil)
+					Expect(err).ToNot(HaveOccurred())
+					resp, err := http.DefaultClient.Do(req)
+					Expect(err).ToNot(HaveOccurred())
+					defer resp.Body.Close()
+					res := localai.DeleteModelResponse{}
+					json.NewDecoder(resp.Body).Decode(&res)
+					return resp.StatusCode, res
+				}
+
+				// the config files are registered by the watcher
+				Eventually(func() int {
+					status, _ := deleteModel("?files=true&dry_run=true")
+					return status
+				}, "10s").Should(Equal(200))
+				_, res := deleteModel("?files=true&dry_run=true")
+				Expect(res.DryRun).To(BeTrue())
+				paths := []string{}
+				for _, f := range res.Files {
+					paths = append(paths, filepath.Base(f.Path))
+				}
+				// the files of the other models are kept
+				Expect(paths).To(ConsistOf("doomed.gguf", "doomed.gguf.tmpl", "doomed.yaml"))
+				Expect(res.Freed).To(BeNumerically(">", 14))
+				Expect(filepath.Join(tmpdir, "doomed.gguf")).To(BeAnExistingFile())
+
+				status, res := deleteModel("?files=true")
+				Expect(status).To(Equal(200))
+				Expect(res.Files).To(HaveLen(3))
+				Expect(filepath.Join(tmpdir, "doomed.gguf")).ToNot(BeAnExistingFile())
+				Expect(filepath.Join(tmpdir, "doomed.yaml")).ToNot(BeAnExistingFile())
+				Expect(filepath.Join(tmpdir, "shared.bin")).To(BeAnExistingFile())
+
+				status, _ = deleteModel("")
+				Expect(status).To(Equal(404))
+			})
+
+			It("reloads the config files when they change", func() {
+				names := func() []string {
+					models, err := client.ListModels(context.TODO())
+					Expect(err).ToNot(HaveOccurred())
+					res := []string{}
+					for _, m := range models.Models {
+						res = append(res, m.ID)
+					}
+					return res
+				}
+
+				path := filepath.Join(tmpdir, "hot.yaml")
+				Expect(os.WriteFile(path, []byte("name: hot\nbackend: bert-embeddings\n"), 0644)).To(Succeed())
+				Eventually(names, "10s").Should(ContainElement("hot"))
+
+				// the file now defines another model
+				Expect(os.WriteFile(path, []byte("name: hot-renamed\nbackend: bert-embeddings\n"), 0644)).To(Succeed())
+				Eventually(names, "10s").Should(ContainElement("hot-renamed"))
+				Expect(names()).ToNot(ContainElement("hot"))
+
+				Expect(os.Remove(path)).To(Succeed())
+				Eventually(names, "10s").ShouldNot(ContainElement("hot-renamed"))
+			})
+
 			It("runs openllama(llama-ggml backend)", Label("llama"), func() {
 				if runtime.GOOS != "linux" {
 					Skip("test supported only on linux")
@@ -637,6 +740,36 @@ var _ = Describe("API test", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(models.Models)).To(Equal(6)) // If "config.yaml" should be included, this should be 8?
 		})
+		It("serves the OpenAPI document", func() {
+			resp, err := http.Get("http://127.0.0.1:9090/openapi.json")
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(200))
+
+			document := struct {
+				OpenAPI string                            `json:"openapi"`
+				Paths   map[string]map[string]interface{} `json:"paths"`
+			}{}
+			Expect(json.NewDecoder(resp.Body).Decode(&document)).To(Succeed())
+			Expect(document.OpenAPI).To(Equal("3.1.0"))
+			Expect(document.Paths).To(HaveKey("/v1/chat/completions"))
+			Expect(document.Paths).To(HaveKey("/models/apply"))
+			Expect(document.Paths["/v1/threads/{thread_id}/runs/{run_id}"]).To(HaveKey("get"))
+		})
+		It("returns the ID of the requests", func() {
+			resp, err := http.Get("http://127.0.0.1:9090/v1/models")
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			Expect(resp.Header.Get("X-Request-ID")).ToNot(BeEmpty())
+
+			req, err := http.NewRequest("GET", "http://127.0.0.1:9090/v1/models", nil)
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("X-Request-ID", "client-request-1")
+			resp, err = http.DefaultClient.Do(req)
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			Expect(resp.Header.Get("X-Request-ID")).To(Equal("client-request-1"))
+		})
 		It("can generate completions", func() {
 			resp, err := client.CreateCompletion(context.TODO(), openai.CompletionRequest{Model: "testmodel", Prompt: testPrompt})
 			Expect(err).ToNot(HaveOccurred())
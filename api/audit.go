@@ -0,0 +1,49 @@
+package api
+
+import (
+	"time"
+
+	fiberContext "github.com/go-skynet/LocalAI/api/ctx"
+	"github.com/go-skynet/LocalAI/pkg/audit"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// auditMiddleware records the API calls in the audit log, once served. It must run after the request middleware.
+func auditMiddleware(l *audit.Logger, skip ...string) fiber.Handler {
+	skipped := map[string]bool{}
+	for _, p := range skip {
+		skipped[p] = true
+	}
+
+	return func(c *fiber.Ctx) error {
+		if skipped[c.Path()] {
+			return c.Next()
+		}
+
+		start := time.Now()
+		err := c.Next()
+
+		e := audit.Entry{
+			Time:       start,
+			Caller:     callerFingerprint(c),
+			RemoteIP:   c.IP(),
+			Method:     c.Method(),
+			Path:       c.Path(),
+			Status:     fiberContext.ResponseStatus(c, err),
+			Model:      fiberContext.RequestModel(c),
+			DurationMS: time.Since(start).Milliseconds(),
+		}
+		if r := fiberContext.RequestFromCtx(c); r != nil {
+			served := r.Served()
+			e.RequestID = r.ID
+			e.Prompts = served.Prompts
+			e.PromptTokens = served.PromptTokens
+			e.CompletionTokens = served.CompletionTokens
+		}
+		if aerr := l.Record(e); aerr != nil {
+			log.Error().Msgf("Failed recording the API call in the audit log: %s", aerr.Error())
+		}
+		return err
+	}
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	fiberContext "github.com/go-skynet/LocalAI/api/ctx"
+	"github.com/go-skynet/LocalAI/api/openai"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/apikeys"
+	"github.com/go-skynet/LocalAI/pkg/audit"
+	"github.com/go-skynet/LocalAI/pkg/oidc"
+	"github.com/go-skynet/LocalAI/pkg/quota"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// the key of the managed API key of the request in the locals of the fiber context
+const apiKeyLocal = "apikey"
+
+// authMiddleware checks that the API key, or the OIDC token, of the requests is valid and allowed the scope, any key
+// being allowed the empty scope. The static API keys are allowed all the scopes. No auth is required without static
+// API keys, key store and OIDC issuer: an empty key store still requires it, so that revoking the last key doesn't
+// open the API.
+func authMiddleware(o *options.Option, scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if o.TenantNamespaces {
+			// the callers without tenant see the shared models only
+			fiberContext.SetNamespace(c, "", true)
+		}
+		if len(o.GetApiKeys()) == 0 && o.ApiKeyStore == nil && o.OIDC == nil {
+			return c.Next()
+		}
+
+		// Check for api_keys.json file
+		fileContent, err := os.ReadFile("api_keys.json")
+		if err == nil {
+			// Parse JSON content from the file
+			var fileKeys []string
+			err := json.Unmarshal(fileContent, &fileKeys)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Error parsing api_keys.json"})
+			}
+
+			// Add file keys to options.ApiKeys
+			o.AddApiKeys(fileKeys...)
+		}
+
+		authHeader := c.Get("Authorization")
+		if authHeader == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "Authorization header missing"})
+		}
+		authHeaderParts := strings.Split(authHeader, " ")
+		if len(authHeaderParts) != 2 || authHeaderParts[0] != "Bearer" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "Invalid Authorization header format"})
+		}
+
+		apiKey := authHeaderParts[1]
+		if o.HasApiKey(apiKey) {
+			return enforceQuota(c, o, scope, audit.Fingerprint(apiKey), "")
+		}
+
+		if o.ApiKeyStore != nil {
+			if k, ok := o.ApiKeyStore.Lookup(apiKey); ok {
+				return authorize(c, o, scope, k)
+			}
+		}
+
+		if o.OIDC != nil && oidc.IsJWT(apiKey) {
+			k, err := o.OIDC.Verify(apiKey)
+			if err != nil {
+				log.Debug().Msgf("Invalid OIDC token: %s", err.Error())
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "Invalid token"})
+			}
+			return authorize(c, o, scope, k)
+		}
+
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "Invalid API key"})
+	}
+}
+
+// batchMiddleware serves the requests of the batches as the caller which created them, as authMiddleware does for
+// the requests of the caller: in the models namespace of its tenant, and within its quotas
+func batchMiddleware(o *options.Option, scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		caller, tenant, _ := openai.BatchCaller(c)
+		if o.TenantNamespaces {
+			fiberContext.SetNamespace(c, tenant, o.TenantSharedModels)
+		}
+		return enforceQuota(c, o, scope, caller, tenant)
+	}
+}
+
+// authorize serves the request of the managed API key, or of the OIDC token, if it is allowed the scope
+func authorize(c *fiber.Ctx, o *options.Option, scope string, k apikeys.Key) error {
+	if !k.Allows(scope) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "API key not allowed the " + scope + " scope"})
+	}
+	c.Locals(apiKeyLocal, k)
+	if o.TenantNamespaces && k.Tenant != "" {
+		fiberContext.SetNamespace(c, k.Tenant, o.TenantSharedModels)
+	}
+	return enforceQuota(c, o, scope, k.Fingerprint, k.Tenant)
+}
+
+// enforceQuota rejects the requests of the inference scopes once the quota of their API key, or of its tenant, is
+// exhausted, or serves them with the fallback model of the quota
+func enforceQuota(c *fiber.Ctx, o *options.Option, scope, caller, tenant string) error {
+	if o.Quotas == nil || scope == "" || scope == apikeys.ScopeAdmin {
+		return c.Next()
+	}
+	q, exhausted, err := o.Quotas.Exhausted(caller, tenant)
+	if err != nil {
+		log.Error().Msgf("Failed checking the quota: %s", err.Error())
+		return c.Next()
+	}
+	if !exhausted {
+		return c.Next()
+	}
+
+	reset := q.Reset(time.Now())
+	if q.OnExhausted == quota.Degrade {
+		log.Debug().Msgf("Quota exhausted until %s, serving with %s", reset.Format(time.RFC3339), q.FallbackModel)
+		fiberContext.OverrideModel(c, q.FallbackModel)
+		return c.Next()
+	}
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(time.Until(reset).Seconds())+1))
+	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+		"message": fmt.Sprintf("%s quota of %d tokens exhausted, reset at %s", q.Period, q.Tokens, reset.Format(time.RFC3339)),
+	})
+}
+
+// requestAPIKey returns the managed API key, or the OIDC token, the request was authenticated with, if any
+func requestAPIKey(c *fiber.Ctx) (apikeys.Key, bool) {
+	k, ok := c.Locals(apiKeyLocal).(apikeys.Key)
+	return k, ok
+}
+
+// callerTenant returns the tenant of the managed API key, or of the OIDC token, of the request, if any. The requests of
+// the batches have the tenant of the caller which created the batch.
+func callerTenant(c *fiber.Ctx) string {
+	if _, tenant, ok := openai.BatchCaller(c); ok {
+		return tenant
+	}
+	k, _ := requestAPIKey(c)
+	return k.Tenant
+}
+
+// callerFingerprint identifies the caller of the request, by the fingerprint of its API key or the subject of its
+// OIDC token. The requests of the batches are the ones of the caller which created the batch.
+func callerFingerprint(c *fiber.Ctx) string {
+	if caller, _, ok := openai.BatchCaller(c); ok {
+		return caller
+	}
+	if k, ok := requestAPIKey(c); ok {
+		return k.Fingerprint
+	}
+	return audit.Fingerprint(strings.TrimPrefix(c.Get("Authorization"), "Bearer "))
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/apikeys"
+	"github.com/gofiber/fiber/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("authMiddleware", func() {
+	var app *fiber.App
+	var store *apikeys.Store
+
+	BeforeEach(func() {
+		var err error
+		store, err = apikeys.Open(filepath.Join(GinkgoT().TempDir(), "keys.json"))
+		Expect(err).ToNot(HaveOccurred())
+
+		app = fiber.New()
+		app.Get("/", authMiddleware(&options.Option{ApiKeyStore: store}, apikeys.ScopeAdmin), func(c *fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusOK)
+		})
+	})
+
+	status := func(key string) int {
+		req := httptest.NewRequest("GET", "/", nil)
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		resp, err := app.Test(req)
+		Expect(err).ToNot(HaveOccurred())
+		return resp.StatusCode
+	}
+
+	It("requires a key with an empty key store", func() {
+		Expect(status("")).To(Equal(fiber.StatusUnauthorized))
+	})
+
+	It("requires a key once the last key is revoked", func() {
+		k, secret, err := store.Create("admin", "", []string{apikeys.ScopeAdmin})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status(secret)).To(Equal(fiber.StatusOK))
+
+		Expect(store.Revoke(k.ID)).To(Succeed())
+		Expect(store.Empty()).To(BeTrue())
+		Expect(status("")).To(Equal(fiber.StatusUnauthorized))
+		Expect(status(secret)).To(Equal(fiber.StatusUnauthorized))
+	})
+})
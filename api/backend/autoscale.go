@@ -0,0 +1,155 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultMinSlotContext    = 1024
+	defaultAutoscaleInterval = 10 * time.Second
+	// checks with queued requests before adding slots
+	scaleUpChecks = 2
+	// idle checks before removing slots
+	scaleDownChecks = 6
+	// time to wait for the requests being processed before giving up a resize
+	resizeTimeout = 2 * time.Minute
+)
+
+// initialSlots returns the number of slots the model starts with
+func initialSlots(c config.Config) int {
+	slots := c.Scheduler.Slots
+	a := c.Scheduler.Autoscale
+	if a.MaxSlots <= 0 {
+		return slots
+	}
+	if slots < a.MinSlots {
+		slots = a.MinSlots
+	}
+	if slots > a.MaxSlots {
+		slots = a.MaxSlots
+	}
+	return slots
+}
+
+// currentSlots returns the number of slots of the model, as scaled by its scheduler
+func currentSlots(c config.Config) int {
+	schedulersMu.Lock()
+	s, ok := schedulers[c.Name]
+	schedulersMu.Unlock()
+	if ok {
+		return s.Slots()
+	}
+	return initialSlots(c)
+}
+
+// maxSlots returns the maximum number of slots of the model, so that each slot keeps enough context
+func maxSlots(c config.Config) int {
+	a := c.Scheduler.Autoscale
+	n := a.MaxSlots
+	minContext := a.MinSlotContext
+	if minContext == 0 {
+		minContext = defaultMinSlotContext
+	}
+	if c.ContextSize > 0 && c.ContextSize/minContext < n {
+		n = c.ContextSize / minContext
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// autoscale grows the slots of the model while requests are queued, and shrinks them while they are idle, until the
+// context of the options is done or the backend doesn't support the reconfiguration
+func autoscale(o *options.Option, c config.Config, s *modelScheduler) {
+	interval := time.Duration(c.Scheduler.Autoscale.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultAutoscaleInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	up, idle := 0, 0
+	for {
+		select {
+		case <-o.Context.Done():
+			return
+		case <-ticker.C:
+		}
+
+		slots, busy, queued := s.Slots(), s.Busy(), s.Queued()
+		target := slots
+		switch {
+		case queued > 0 && slots < maxSlots(c):
+			idle = 0
+			if up++; up >= scaleUpChecks {
+				target = busy + queued
+				if target > maxSlots(c) {
+					target = maxSlots(c)
+				}
+			}
+		case queued == 0 && busy <= slots/2 && slots > c.Scheduler.Autoscale.MinSlots && slots > 1:
+			up = 0
+			if idle++; idle >= scaleDownChecks {
+				target = (slots + 1) / 2
+				if target < c.Scheduler.Autoscale.MinSlots {
+					target = c.Scheduler.Autoscale.MinSlots
+				}
+			}
+		default:
+			up, idle = 0, 0
+		}
+		if target == slots {
+			continue
+		}
+		up, idle = 0, 0
+
+		if err := resizeSlots(o, c, s, target); err != nil {
+			if status.Code(err) == codes.Unimplemented {
+				log.Warn().Msgf("The backend of %s can't change its slots, not scaling them anymore", c.Name)
+				return
+			}
+			log.Warn().Msgf("Failed scaling the slots of %s from %d to %d: %s", c.Name, slots, target, err.Error())
+			continue
+		}
+		log.Info().Msgf("Scaled the slots of %s from %d to %d", c.Name, slots, target)
+	}
+}
+
+// resizeSlots waits for the requests of the model being processed, and reconfigures its backend with the slots
+func resizeSlots(o *options.Option, c config.Config, s *modelScheduler, slots int) error {
+	ctx, cancel := context.WithTimeout(o.Context, resizeTimeout)
+	defer cancel()
+	return s.Resize(ctx, slots, func() error {
+		// the backend starts with the slots of the scheduler when it's not loaded
+		if addr, loaded := o.Loader.LoadedAddress(c.Model); loaded {
+			res, err := addr.GRPC(true, nil).Reconfigure(ctx, &pb.ReconfigureRequest{Parallel: int32(slots)})
+			if err != nil {
+				return err
+			}
+			if !res.Success {
+				return errors.New(res.Message)
+			}
+		}
+
+		// the KV cache of the slots was cleared
+		if c.PrefixCache.Slots == 0 {
+			promptCachesMu.Lock()
+			r, ok := promptCaches[c.Name]
+			promptCachesMu.Unlock()
+			if ok {
+				r.Resize(slots)
+			}
+		}
+		return nil
+	})
+}
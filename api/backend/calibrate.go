@@ -0,0 +1,121 @@
+package backend
+
+import (
+	"fmt"
+	"strconv"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/calibration"
+	model "github.com/go-skynet/LocalAI/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// CalibrationSample is a labeled input of the calibration dataset
+type CalibrationSample struct {
+	Input string `json:"input"`
+	Label bool   `json:"label"`
+}
+
+type CalibrationReport struct {
+	Calibration config.Calibration
+	// Samples whose score was fitted, and samples without a score in the output of the model
+	Samples int
+	Skipped int
+	// Log loss and expected calibration error of the scores, before and after the calibration
+	LogLoss           float64
+	CalibratedLogLoss float64
+	ECE               float64
+	CalibratedECE     float64
+}
+
+// CalibrateModel runs the samples through the model, and fits the temperature scaling of its scores.
+// The score of a sample is generated by the model from the completion template of the input,
+// which asks it to score the input from 0 to the scale of the calibration. With a label, the
+// score is the one of this label of a classification model instead.
+func CalibrateModel(samples []CalibrationSample, label string, loader *model.ModelLoader, c config.Config, o *options.Option) (CalibrationReport, error) {
+	report := CalibrationReport{}
+	scale := c.Calibration.Scale
+	// the scores are fitted before any calibration
+	c.Calibration = config.Calibration{}
+
+	scores := []float64{}
+	labels := []bool{}
+	for i, sample := range samples {
+		score, ok, err := sampleScore(sample.Input, label, scale, loader, c, o)
+		if err != nil {
+			return report, err
+		}
+		if !ok {
+			log.Debug().Msgf("calibration: no score for sample %d", i)
+			report.Skipped++
+			continue
+		}
+		scores = append(scores, score)
+		labels = append(labels, sample.Label)
+	}
+	report.Samples = len(scores)
+
+	temperature, bias, err := calibration.Fit(scores, labels)
+	if err != nil {
+		return report, err
+	}
+	report.Calibration = config.Calibration{Temperature: temperature, Bias: bias, Scale: scale}
+
+	calibrated := make([]float64, len(scores))
+	for i, s := range scores {
+		calibrated[i] = calibration.Apply(s, temperature, bias)
+	}
+	report.LogLoss = calibration.LogLoss(scores, labels)
+	report.CalibratedLogLoss = calibration.LogLoss(calibrated, labels)
+	report.ECE = calibration.ExpectedCalibrationError(scores, labels, 10)
+	report.CalibratedECE = calibration.ExpectedCalibrationError(calibrated, labels, 10)
+	return report, nil
+}
+
+func sampleScore(input, label string, scale float64, loader *model.ModelLoader, c config.Config, o *options.Option) (float64, bool, error) {
+	if label != "" {
+		labels, err := ModelClassify(input, loader, c, o)
+		if err != nil {
+			return 0, false, err
+		}
+		for _, l := range labels {
+			if l.Label == label {
+				return float64(l.Score), true, nil
+			}
+		}
+		return 0, false, fmt.Errorf("the model has no label %s", label)
+	}
+
+	prompt := input
+	if c.TemplateConfig.Completion != "" {
+		templated, err := loader.EvaluateTemplateForPrompt(model.CompletionPromptTemplate, c.TemplateConfig.Completion, model.PromptTemplateData{
+			SystemPrompt: c.SystemPrompt,
+			Input:        input,
+		})
+		if err != nil {
+			return 0, false, err
+		}
+		prompt = templated
+	}
+
+	predFunc, err := ModelInference(o.Context, prompt, []string{}, loader, c, o, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	prediction, err := predFunc()
+	if err != nil {
+		return 0, false, err
+	}
+	score, ok := calibration.ParseScore(Finetune(c, prompt, prediction.Response), scale)
+	return score, ok, nil
+}
+
+// calibratedScore returns the calibrated probability of the score, on the scale of the model
+func calibratedScore(c config.Calibration, score float64) string {
+	scale := c.Scale
+	if scale <= 0 {
+		scale = 1
+	}
+	return strconv.FormatFloat(calibration.Apply(score, c.Temperature, c.Bias)*scale, 'f', 4, 64)
+}
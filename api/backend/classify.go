@@ -0,0 +1,49 @@
+package backend
+
+import (
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/calibration"
+	"github.com/go-skynet/LocalAI/pkg/grpc"
+	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
+	model "github.com/go-skynet/LocalAI/pkg/model"
+)
+
+// ModelClassify returns the scores of the labels of a classification model for the text
+func ModelClassify(s string, loader *model.ModelLoader, c config.Config, o *options.Option) ([]*pb.ClassifyLabel, error) {
+	modelFile := c.Model
+
+	grpcOpts := gRPCModelOpts(c)
+
+	var inferenceModel grpc.Backend
+	var err error
+
+	opts := modelOpts(c, o, []model.Option{
+		model.WithLoadGRPCLoadModelOpts(grpcOpts),
+		model.WithThreads(uint32(c.Threads)),
+		model.WithAssetDir(o.AssetsDestination),
+		model.WithModel(modelFile),
+		model.WithContext(o.Context),
+	})
+
+	if c.Backend == "" {
+		inferenceModel, err = loader.GreedyLoader(opts...)
+	} else {
+		opts = append(opts, model.WithBackendString(c.Backend))
+		inferenceModel, err = loader.BackendLoader(opts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := inferenceModel.Classify(o.Context, &pb.ClassifyRequest{Text: s})
+	if err != nil {
+		return nil, err
+	}
+	if c.Calibration.Enabled() {
+		for _, l := range res.Labels {
+			l.Score = float32(calibration.Apply(float64(l.Score), c.Calibration.Temperature, c.Calibration.Bias))
+		}
+	}
+	return res.Labels, nil
+}
@@ -0,0 +1,25 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/contentfilter"
+)
+
+// filterContent runs the prompt or the response through the content filters the model enables for the stage
+func filterContent(ctx context.Context, c config.Config, o *options.Option, stage contentfilter.Stage, text string) (string, error) {
+	names := c.ContentFilters.Prompt
+	if stage == contentfilter.Response {
+		names = c.ContentFilters.Response
+	}
+	if len(names) == 0 {
+		return text, nil
+	}
+	if o.ContentFilters == nil {
+		return "", fmt.Errorf("no content filters registered, the model %s needs %v", c.Name, names)
+	}
+	return o.ContentFilters.Apply(ctx, names, contentfilter.Content{Model: c.Name, Stage: stage, Text: text})
+}
@@ -0,0 +1,78 @@
+package backend
+
+import (
+	"context"
+	"time"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/go-skynet/LocalAI/pkg/grpc"
+	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
+	model "github.com/go-skynet/LocalAI/pkg/model"
+)
+
+// ModelDiarization returns the turns of the speakers of the audio, found by a diarization model
+func ModelDiarization(ctx context.Context, audio string, req *pb.DiarizeRequest, loader *model.ModelLoader, c config.Config, o *options.Option) ([]*pb.SpeakerTurn, error) {
+	grpcOpts := gRPCModelOpts(c)
+
+	var inferenceModel grpc.Backend
+	var err error
+
+	opts := modelOpts(c, o, []model.Option{
+		model.WithLoadGRPCLoadModelOpts(grpcOpts),
+		model.WithThreads(uint32(c.Threads)),
+		model.WithAssetDir(o.AssetsDestination),
+		model.WithModel(c.Model),
+		model.WithContext(o.Context),
+	})
+
+	if c.Backend == "" {
+		inferenceModel, err = loader.GreedyLoader(opts...)
+	} else {
+		opts = append(opts, model.WithBackendString(c.Backend))
+		inferenceModel, err = loader.BackendLoader(opts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	req.Dst = audio
+	req.Threads = uint32(c.Threads)
+	res, err := inferenceModel.Diarize(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return res.Turns, nil
+}
+
+// LabelSpeakers sets the speaker of each segment to the one talking the most during the segment, or to the closest
+// one when nobody talks during the segment
+func LabelSpeakers(segments []schema.Segment, turns []*pb.SpeakerTurn) {
+	if len(turns) == 0 {
+		return
+	}
+	for i := range segments {
+		s := &segments[i]
+		talked := map[string]time.Duration{}
+		best, bestTalked := "", time.Duration(0)
+		closest, closestGap := "", time.Duration(-1)
+		for _, t := range turns {
+			start, end := time.Duration(t.Start), time.Duration(t.End)
+			if overlap := min(end, s.End) - max(start, s.Start); overlap > 0 {
+				talked[t.Speaker] += overlap
+				if talked[t.Speaker] > bestTalked {
+					best, bestTalked = t.Speaker, talked[t.Speaker]
+				}
+			}
+			gap := max(start-s.End, s.Start-end)
+			if closestGap < 0 || gap < closestGap {
+				closest, closestGap = t.Speaker, gap
+			}
+		}
+		if best == "" {
+			best = closest
+		}
+		s.Speaker = best
+	}
+}
@@ -1,15 +1,165 @@
 package backend
 
 import (
+	"context"
 	"fmt"
+	"sync"
+	"sync/atomic"
 
 	config "github.com/go-skynet/LocalAI/api/config"
 	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/embeddings"
 	"github.com/go-skynet/LocalAI/pkg/grpc"
+	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
 	model "github.com/go-skynet/LocalAI/pkg/model"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// ModelEmbedding returns the function computing the embedding of the string, or of the tokens when they are set
 func ModelEmbedding(s string, tokens []int, loader *model.ModelLoader, c config.Config, o *options.Option) (func() ([]float32, error), error) {
+	embed, err := modelEmbedder(loader, c, o)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() ([]float32, error) {
+		out, err := embed(s, tokens)
+		if err != nil {
+			return nil, err
+		}
+		res, err := embeddings.Process([]embeddings.Output{out}, c.Pooling.Method, c.Pooling.Normalize)
+		if err != nil {
+			return nil, err
+		}
+		return res[0], nil
+	}, nil
+}
+
+// embeddingBatchSize is the number of inputs sent to the backend at once
+const embeddingBatchSize = 64
+
+// ModelEmbeddings computes the embeddings of the strings, or of the lists of tokens when they are set.
+// The inputs are sent to the backend by batches, as many at once as the model has slots, and their embeddings
+// pooled and normalized in parallel. The backends without batches compute the inputs one after the other.
+func ModelEmbeddings(inputs []string, tokens [][]int, loader *model.ModelLoader, c config.Config, o *options.Option) ([][]float32, error) {
+	backend, err := loadEmbeddingModel(loader, c, o)
+	if err != nil {
+		return nil, err
+	}
+
+	parallel := 1
+	if c.Scheduler.Policy != "" {
+		parallel = currentSlots(c)
+	}
+	ctx, cancel := context.WithCancel(o.Context)
+	defer cancel()
+
+	n := len(inputs) + len(tokens)
+	outputs := make([]embeddings.Output, n)
+	// set once the backend reported that it doesn't support the batches
+	var unbatched atomic.Bool
+	var wg sync.WaitGroup
+	var once sync.Once
+	var batchErr error
+	fail := func(err error) {
+		once.Do(func() {
+			batchErr = err
+			cancel()
+		})
+	}
+	sem := make(chan struct{}, parallel)
+	for start := 0; start < n; start += embeddingBatchSize {
+		end := min(start+embeddingBatchSize, n)
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			req := &pb.EmbeddingBatchRequest{Options: embeddingOptions(c, loader.ModelPath)}
+			// the backend sends the strings first, and then the tokens
+			indexes := []int{}
+			for i := start; i < end && i < len(inputs); i++ {
+				req.Inputs = append(req.Inputs, inputs[i])
+				indexes = append(indexes, i)
+			}
+			for i := max(start, len(inputs)); i < end; i++ {
+				req.Tokens = append(req.Tokens, &pb.EmbeddingTokens{Tokens: int32Tokens(tokens[i-len(inputs)])})
+				indexes = append(indexes, i)
+			}
+
+			if !unbatched.Load() {
+				err := backend.EmbeddingBatch(ctx, req, func(res *pb.EmbeddingBatchResult) {
+					if i := int(res.Index); i >= 0 && i < len(indexes) && res.Result != nil {
+						outputs[indexes[i]] = embeddingOutput(res.Result)
+					}
+				})
+				if status.Code(err) != codes.Unimplemented {
+					if err != nil {
+						fail(err)
+					}
+					return
+				}
+				unbatched.Store(true)
+			}
+
+			for i, index := range indexes {
+				opts := embeddingOptions(c, loader.ModelPath)
+				if i < len(req.Inputs) {
+					opts.Embeddings = req.Inputs[i]
+				} else {
+					opts.EmbeddingTokens = req.Tokens[i-len(req.Inputs)].Tokens
+				}
+				res, err := backend.Embeddings(ctx, opts)
+				if err != nil {
+					fail(err)
+					return
+				}
+				outputs[index] = embeddingOutput(res)
+			}
+		}(start, end)
+	}
+	wg.Wait()
+	if batchErr != nil {
+		return nil, batchErr
+	}
+	if err := o.Context.Err(); err != nil {
+		return nil, err
+	}
+	return embeddings.Process(outputs, c.Pooling.Method, c.Pooling.Normalize)
+}
+
+// modelEmbedder loads the model, and returns the function computing the embedding of a string or of tokens with it
+func modelEmbedder(loader *model.ModelLoader, c config.Config, o *options.Option) (func(s string, tokens []int) (embeddings.Output, error), error) {
+	backend, err := loadEmbeddingModel(loader, c, o)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(s string, tokens []int) (embeddings.Output, error) {
+		opts := embeddingOptions(c, loader.ModelPath)
+		if len(tokens) > 0 {
+			opts.EmbeddingTokens = int32Tokens(tokens)
+		} else {
+			opts.Embeddings = s
+		}
+		res, err := backend.Embeddings(o.Context, opts)
+		if err != nil {
+			return embeddings.Output{}, err
+		}
+		return embeddingOutput(res), nil
+	}, nil
+}
+
+// loadEmbeddingModel loads the model, and returns its backend
+func loadEmbeddingModel(loader *model.ModelLoader, c config.Config, o *options.Option) (grpc.Backend, error) {
 	if !c.Embeddings {
 		return nil, fmt.Errorf("endpoint disabled for this model by API configuration")
 	}
@@ -39,54 +189,42 @@ func ModelEmbedding(s string, tokens []int, loader *model.ModelLoader, c config.
 		return nil, err
 	}
 
-	var fn func() ([]float32, error)
-	switch model := inferenceModel.(type) {
-	case grpc.Backend:
-		fn = func() ([]float32, error) {
-			predictOptions := gRPCPredictOpts(c, loader.ModelPath)
-			if len(tokens) > 0 {
-				embeds := []int32{}
-
-				for _, t := range tokens {
-					embeds = append(embeds, int32(t))
-				}
-				predictOptions.EmbeddingTokens = embeds
-
-				res, err := model.Embeddings(o.Context, predictOptions)
-				if err != nil {
-					return nil, err
-				}
-
-				return res.Embeddings, nil
-			}
-			predictOptions.Embeddings = s
+	backend, ok := inferenceModel.(grpc.Backend)
+	if !ok {
+		return nil, fmt.Errorf("embeddings not supported by the backend")
+	}
+	return backend, nil
+}
 
-			res, err := model.Embeddings(o.Context, predictOptions)
-			if err != nil {
-				return nil, err
-			}
+// embeddingOptions returns the options of the embeddings of the model
+func embeddingOptions(c config.Config, modelPath string) *pb.PredictOptions {
+	opts := gRPCPredictOpts(c, modelPath)
+	// the backends which don't support it return the pooled embedding, which is used as is
+	opts.TokenEmbeddings = c.Pooling.Method != ""
+	return opts
+}
 
-			return res.Embeddings, nil
-		}
-	default:
-		fn = func() ([]float32, error) {
-			return nil, fmt.Errorf("embeddings not supported by the backend")
-		}
+func int32Tokens(tokens []int) []int32 {
+	res := make([]int32, 0, len(tokens))
+	for _, t := range tokens {
+		res = append(res, int32(t))
 	}
+	return res
+}
 
-	return func() ([]float32, error) {
-		embeds, err := fn()
-		if err != nil {
-			return embeds, err
-		}
-		// Remove trailing 0s
-		for i := len(embeds) - 1; i >= 0; i-- {
-			if embeds[i] == 0.0 {
-				embeds = embeds[:i]
-			} else {
-				break
-			}
+// embeddingOutput returns the embedding computed by the backend, to be pooled
+func embeddingOutput(res *pb.EmbeddingResult) embeddings.Output {
+	embeds := res.Embeddings
+	if res.Tokens > 0 {
+		return embeddings.Output{Data: embeds, Tokens: int(res.Tokens)}
+	}
+	// Remove trailing 0s
+	for i := len(embeds) - 1; i >= 0; i-- {
+		if embeds[i] == 0.0 {
+			embeds = embeds[:i]
+		} else {
+			break
 		}
-		return embeds, nil
-	}, nil
+	}
+	return embeddings.Output{Data: embeds}
 }
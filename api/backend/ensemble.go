@@ -0,0 +1,47 @@
+package backend
+
+import (
+	"fmt"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/embeddings"
+)
+
+// ModelEnsembleEmbedding embeds the text with all the models of the ensemble, and returns their embeddings
+func ModelEnsembleEmbedding(s string, e config.Ensemble, cm *config.ConfigLoader, o *options.Option) ([][]float32, error) {
+	if len(e.Models) == 0 {
+		return nil, fmt.Errorf("the ensemble has no models")
+	}
+
+	vectors := [][]float32{}
+	for _, m := range e.Models {
+		c, err := config.Load(m.Model, o.Loader.ModelPath, cm, o.Debug, o.Threads, o.ContextSize, o.F16)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading the configuration of %q: %w", m.Model, err)
+		}
+		if len(c.Ensemble.Models) > 0 {
+			return nil, fmt.Errorf("model %q is an ensemble, ensembles can't be nested", m.Model)
+		}
+
+		embedFn, err := ModelEmbedding(s, []int{}, o.Loader, *c, o)
+		if err != nil {
+			return nil, fmt.Errorf("model %q: %w", m.Model, err)
+		}
+		v, err := embedFn()
+		if err != nil {
+			return nil, fmt.Errorf("model %q: %w", m.Model, err)
+		}
+		vectors = append(vectors, v)
+	}
+	return vectors, nil
+}
+
+// FuseEnsembleEmbedding fuses the embeddings of the models of the ensemble
+func FuseEnsembleEmbedding(e config.Ensemble, vectors [][]float32) ([]float32, error) {
+	weights := []float64{}
+	for _, m := range e.Models {
+		weights = append(weights, m.Weight)
+	}
+	return embeddings.Fuse(e.Fusion, vectors, weights)
+}
@@ -1,13 +1,31 @@
 package backend
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+
 	config "github.com/go-skynet/LocalAI/api/config"
 	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/grpc"
 	"github.com/go-skynet/LocalAI/pkg/grpc/proto"
 	model "github.com/go-skynet/LocalAI/pkg/model"
 )
 
-func ImageGeneration(height, width, mode, step, seed int, positive_prompt, negative_prompt, src, dst string, loader *model.ModelLoader, c config.Config, o *options.Option) (func() error, error) {
+// ErrNoInpainting is returned when a mask is given to a model whose backend doesn't inpaint
+var ErrNoInpainting = errors.New("the model can't inpaint the masks of the images")
+
+// the number of images generated at once by the backends which batch them, unless set by the model
+const defaultImageBatch = 4
+
+// ImageGeneration returns the generation of the images of dsts, with successive seeds from seed and with the LoRAs
+// of the model of loras by weight. The backends which batch the images generate them by batches, the others one
+// after the other, as many at once as the model has slots. When progress is not nil, it is called with the index of
+// an image in dsts and its progress after each step, if the backend reports it, with a preview of the image if
+// requested.
+func ImageGeneration(height, width, mode, step, seed int, strength, cfgScale float32, positive_prompt, negative_prompt, scheduler, src, mask, control, controlType string, dsts []string, loras map[string]float32, preview bool, progress func(int, *proto.ImageProgress), loader *model.ModelLoader, c config.Config, o *options.Option) (func() error, error) {
 
 	opts := modelOpts(c, o, []model.Option{
 		model.WithBackendString(c.Backend),
@@ -28,6 +46,8 @@ func ImageGeneration(height, width, mode, step, seed int, positive_prompt, negat
 			CLIPSubfolder: c.Diffusers.ClipSubFolder,
 			CLIPSkip:      int32(c.Diffusers.ClipSkip),
 			ControlNet:    c.Diffusers.ControlNet,
+			ControlNets:   c.Diffusers.ControlNets,
+			Loras:         loraFiles(c, loader.ModelPath),
 		}),
 	})
 
@@ -38,24 +58,119 @@ func ImageGeneration(height, width, mode, step, seed int, positive_prompt, negat
 		return nil, err
 	}
 
+	// the inpainting, the progress and the batches depend on the model, the backends report them once the model is
+	// loaded
+	caps, _ := inferenceModel.Capabilities(o.Context)
+	if mask != "" && !grpc.ReportsCapability(caps, grpc.CapabilityInpaint) {
+		return nil, fmt.Errorf("%w: %s", ErrNoInpainting, c.Name)
+	}
+	streamed := progress != nil && grpc.ReportsCapability(caps, grpc.CapabilityImageProgress)
+	batch := 1
+	if grpc.ReportsCapability(caps, grpc.CapabilityImageBatch) {
+		batch = c.Diffusers.BatchSize
+		if batch <= 0 {
+			batch = defaultImageBatch
+		}
+	}
+	parallel := 1
+	if c.Scheduler.Policy != "" {
+		parallel = max(currentSlots(c), 1)
+	}
+
+	request := func(start, end int) *proto.GenerateImageRequest {
+		req := &proto.GenerateImageRequest{
+			Height:           int32(height),
+			Width:            int32(width),
+			Mode:             int32(mode),
+			Step:             int32(step),
+			Seed:             int32(seed + start),
+			CLIPSkip:         int32(c.Diffusers.ClipSkip),
+			PositivePrompt:   positive_prompt,
+			NegativePrompt:   negative_prompt,
+			Src:              src,
+			Strength:         strength,
+			Mask:             mask,
+			ControlImage:     control,
+			ControlType:      controlType,
+			Scheduler:        scheduler,
+			CfgScale:         cfgScale,
+			EnableParameters: c.Diffusers.EnableParameters,
+			Preview:          preview && streamed,
+			Loras:            loras,
+		}
+		if end-start == 1 {
+			req.Dst = dsts[start]
+		} else {
+			req.Dsts = dsts[start:end]
+		}
+		return req
+	}
+
 	fn := func() error {
-		_, err := inferenceModel.GenerateImage(
-			o.Context,
-			&proto.GenerateImageRequest{
-				Height:           int32(height),
-				Width:            int32(width),
-				Mode:             int32(mode),
-				Step:             int32(step),
-				Seed:             int32(seed),
-				CLIPSkip:         int32(c.Diffusers.ClipSkip),
-				PositivePrompt:   positive_prompt,
-				NegativePrompt:   negative_prompt,
-				Dst:              dst,
-				Src:              src,
-				EnableParameters: c.Diffusers.EnableParameters,
+		ctx, cancel := context.WithCancel(o.Context)
+		defer cancel()
+
+		var wg sync.WaitGroup
+		var once sync.Once
+		var generationErr error
+		fail := func(err error) {
+			once.Do(func() {
+				generationErr = err
+				cancel()
 			})
-		return err
+		}
+		// the progress of the images generated at once is reported one after the other
+		var progressMu sync.Mutex
+		sem := make(chan struct{}, parallel)
+		for start := 0; start < len(dsts); start += batch {
+			end := min(start+batch, len(dsts))
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+			}
+			if ctx.Err() != nil {
+				break
+			}
+			wg.Add(1)
+			go func(start, end int) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				req := request(start, end)
+				if !streamed {
+					if _, err := inferenceModel.GenerateImage(ctx, req); err != nil {
+						fail(err)
+					}
+					return
+				}
+				err := inferenceModel.GenerateImageStream(ctx, req, func(p *proto.ImageProgress) {
+					progressMu.Lock()
+					defer progressMu.Unlock()
+					progress(start+int(p.Index), p)
+				})
+				if err != nil {
+					fail(err)
+				}
+			}(start, end)
+		}
+		wg.Wait()
+		return generationErr
 	}
 
 	return fn, nil
 }
+
+// loraFiles returns the paths of the weights of the LoRAs of the model by name, the relative ones in the models path
+func loraFiles(c config.Config, modelPath string) map[string]string {
+	if len(c.Diffusers.Loras) == 0 {
+		return nil
+	}
+	files := map[string]string{}
+	for name, l := range c.Diffusers.Loras {
+		files[name] = l.File
+		if !filepath.IsAbs(l.File) {
+			files[name] = filepath.Join(modelPath, l.File)
+		}
+	}
+	return files
+}
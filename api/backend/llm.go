@@ -2,28 +2,43 @@ package backend
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 	"unicode/utf8"
 
 	config "github.com/go-skynet/LocalAI/api/config"
+	fiberContext "github.com/go-skynet/LocalAI/api/ctx"
 	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/calibration"
+	"github.com/go-skynet/LocalAI/pkg/contentfilter"
 	"github.com/go-skynet/LocalAI/pkg/gallery"
 	"github.com/go-skynet/LocalAI/pkg/grpc"
+	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
 	model "github.com/go-skynet/LocalAI/pkg/model"
+	"github.com/go-skynet/LocalAI/pkg/throughput"
 	"github.com/go-skynet/LocalAI/pkg/utils"
 )
 
+// ErrNoVision is returned when images are given to a model whose backend doesn't read them
+var ErrNoVision = errors.New("the model can't read images")
+
 type LLMResponse struct {
 	Response string // should this be []byte?
 	Usage    TokenUsage
+	// Log probabilities of the generated tokens, when requested
+	Logprobs []*pb.TokenLogprob
 }
 
 type TokenUsage struct {
 	Prompt     int
 	Completion int
+	// Log probabilities of the tokens passed to the token callback, when requested
+	Logprobs []*pb.TokenLogprob
 }
 
 func ModelInference(ctx context.Context, s string, images []string, loader *model.ModelLoader, c config.Config, o *options.Option, tokenCallback func(string, TokenUsage) bool) (func() (LLMResponse, error), error) {
@@ -34,12 +49,23 @@ func ModelInference(ctx context.Context, s string, images []string, loader *mode
 	var inferenceModel grpc.Backend
 	var err error
 
+	s, err = filterContent(ctx, c, o, contentfilter.Prompt, s)
+	if err != nil {
+		return nil, err
+	}
+	// the streamed tokens are held back until the response is filtered, and sent at once
+	streamCallback := tokenCallback
+	if len(c.ContentFilters.Response) > 0 && tokenCallback != nil {
+		tokenCallback = func(string, TokenUsage) bool { return true }
+	}
+
 	opts := modelOpts(c, o, []model.Option{
 		model.WithLoadGRPCLoadModelOpts(grpcOpts),
 		model.WithThreads(uint32(c.Threads)), // some models uses this to allocate threads during startup
 		model.WithAssetDir(o.AssetsDestination),
 		model.WithModel(modelFile),
 		model.WithContext(o.Context),
+		model.WithLogger(fiberContext.Logger(ctx)),
 	})
 
 	if c.Backend != "" {
@@ -51,7 +77,7 @@ func ModelInference(ctx context.Context, s string, images []string, loader *mode
 		if _, err := os.Stat(modelFile); os.IsNotExist(err) {
 			utils.ResetDownloadTimers()
 			// if we failed to load the model, we try to download it
-			err := gallery.InstallModelFromGalleryByName(o.Galleries, modelFile, loader.ModelPath, gallery.GalleryModel{}, utils.DisplayDownloadFunction)
+			err := gallery.InstallModelFromGalleryByName(o.GetGalleries(), modelFile, loader.ModelPath, gallery.GalleryModel{}, utils.DisplayDownloadFunction, o.GalleryInstallOptions()...)
 			if err != nil {
 				return nil, err
 			}
@@ -67,14 +93,72 @@ func ModelInference(ctx context.Context, s string, images []string, loader *mode
 	if err != nil {
 		return nil, err
 	}
+	request := fiberContext.RequestFromContext(ctx)
+	if b, ok := loader.LoadedBackend(modelFile); ok && request != nil {
+		request.SetBackend(b)
+	}
+
+	// the images are only read by the vision models, e.g. llava with its multimodal projector
+	if len(images) > 0 {
+		caps, _ := inferenceModel.Capabilities(ctx)
+		if !grpc.HasCapability(caps, grpc.CapabilityVision) {
+			return nil, fmt.Errorf("%w: %s", ErrNoVision, c.Name)
+		}
+	}
 
 	// in GRPC, the backend is supposed to answer to 1 single token if stream is not supported
-	fn := func() (LLMResponse, error) {
+	fn := func() (res LLMResponse, err error) {
 		opts := gRPCPredictOpts(c, loader.ModelPath)
 		opts.Prompt = s
 		opts.Images = images
 
+		var trace *Trace
+		if o.TraceDir != "" && traced(ctx) {
+			trace = newTrace(c, grpcOpts, opts)
+			defer func() { trace.save(o.TraceDir, res, err) }()
+		}
+
+		if request != nil {
+			request.AddPrompt(s)
+		}
+
+		done, err := schedule(ctx, c, o, s)
+		if err != nil {
+			return LLMResponse{}, err
+		}
+		outputTokens := 0
 		tokenUsage := TokenUsage{}
+		// the tokens of the prompt evaluated by the backend, and the time it took to stream the first token
+		evaluatedTokens := 0
+		var firstToken time.Duration
+		start := time.Now()
+		defer func() {
+			done(outputTokens)
+			if err == nil && outputTokens > 0 {
+				if evaluatedTokens == 0 {
+					evaluatedTokens = tokenUsage.Prompt
+				}
+				inferences.Observe(c.Name, throughput.Inference{
+					PromptTokens: evaluatedTokens,
+					OutputTokens: outputTokens,
+					FirstToken:   firstToken,
+					Latency:      time.Since(start),
+				})
+			}
+			if request != nil {
+				request.AddTokens(tokenUsage.Prompt, outputTokens)
+			}
+			if o.Metrics != nil && outputTokens > 0 {
+				o.Metrics.ObserveTokens(c.Name, outputTokens, time.Since(start).Seconds())
+			}
+		}()
+
+		slot, release := routePrompt(c, s)
+		defer release()
+		if slot > 0 {
+			opts.Slot = slot
+			opts.PromptCacheAll = true
+		}
 
 		// check the per-model feature flag for usage, since tokenCallback may have a cost.
 		// Defaults to off as for now it is still experimental
@@ -86,23 +170,42 @@ func ModelInference(ctx context.Context, s string, images []string, loader *mode
 				}
 			}
 
-			promptInfo, pErr := inferenceModel.TokenizeString(ctx, opts)
+			promptInfo, pErr := tokenize(ctx, loader, inferenceModel, c, opts)
 			if pErr == nil && promptInfo.Length > 0 {
 				tokenUsage.Prompt = int(promptInfo.Length)
 			}
 
 			tokenCallback = func(token string, usage TokenUsage) bool {
 				tokenUsage.Completion++
-				return userTokenCallback(token, tokenUsage)
+				usage.Prompt, usage.Completion = tokenUsage.Prompt, tokenUsage.Completion
+				return userTokenCallback(token, usage)
 			}
 		}
 
 		if tokenCallback != nil {
 			ss := ""
+			logprobs := []*pb.TokenLogprob{}
 
 			var partialRune []byte
-			err := inferenceModel.PredictStream(ctx, opts, func(chars []byte) {
+			// the logprobs of the incomplete runes are sent along with the rest of their bytes
+			var partialLogprobs []*pb.TokenLogprob
+			err := inferenceModel.PredictStream(ctx, opts, func(reply *pb.Reply) {
+				// the cache statistics come with the last reply
+				observePromptCache(c, reply.TokensCached, reply.TokensEvaluated)
+				if reply.TokensEvaluated > 0 {
+					evaluatedTokens = int(reply.TokensEvaluated)
+				}
+				chars := reply.Message
+				if trace != nil {
+					trace.Tokens = append(trace.Tokens, string(chars))
+				}
+				if outputTokens == 0 {
+					firstToken = time.Since(start)
+				}
+				outputTokens++
 				partialRune = append(partialRune, chars...)
+				partialLogprobs = append(partialLogprobs, reply.Logprobs...)
+				logprobs = append(logprobs, reply.Logprobs...)
 
 				for len(partialRune) > 0 {
 					r, size := utf8.DecodeRune(partialRune)
@@ -111,7 +214,9 @@ func ModelInference(ctx context.Context, s string, images []string, loader *mode
 						break
 					}
 
-					tokenCallback(string(r), tokenUsage)
+					usage := tokenUsage
+					usage.Logprobs, partialLogprobs = partialLogprobs, nil
+					tokenCallback(string(r), usage)
 					ss += string(r)
 
 					partialRune = partialRune[size:]
@@ -120,6 +225,7 @@ func ModelInference(ctx context.Context, s string, images []string, loader *mode
 			return LLMResponse{
 				Response: ss,
 				Usage:    tokenUsage,
+				Logprobs: logprobs,
 			}, err
 		} else {
 			// TODO: Is the chicken bit the only way to get here? is that acceptable?
@@ -127,14 +233,36 @@ func ModelInference(ctx context.Context, s string, images []string, loader *mode
 			if err != nil {
 				return LLMResponse{}, err
 			}
+			observePromptCache(c, reply.TokensCached, reply.TokensEvaluated)
+			evaluatedTokens = int(reply.TokensEvaluated)
+			// roughly 4 characters per token
+			outputTokens = len(reply.Message) / 4
 			return LLMResponse{
 				Response: string(reply.Message),
 				Usage:    tokenUsage,
+				Logprobs: reply.Logprobs,
 			}, err
 		}
 	}
 
-	return fn, nil
+	if len(c.ContentFilters.Response) == 0 {
+		return fn, nil
+	}
+	return func() (LLMResponse, error) {
+		res, err := fn()
+		if err != nil {
+			return res, err
+		}
+		if res.Response, err = filterContent(ctx, c, o, contentfilter.Response, res.Response); err != nil {
+			return LLMResponse{}, err
+		}
+		// the log probabilities are of the tokens generated, not of the filtered response
+		res.Logprobs, res.Usage.Logprobs = nil, nil
+		if streamCallback != nil {
+			streamCallback(res.Response, res.Usage)
+		}
+		return res, nil
+	}, nil
 }
 
 var cutstrings map[string]*regexp.Regexp = make(map[string]*regexp.Regexp)
@@ -163,5 +291,12 @@ func Finetune(config config.Config, input, prediction string) string {
 	for _, c := range config.TrimSuffix {
 		prediction = strings.TrimSpace(strings.TrimSuffix(prediction, c))
 	}
+
+	// the scores generated by the model are replaced by their calibrated value
+	if config.Calibration.Enabled() {
+		if score, ok := calibration.ParseScore(prediction, config.Calibration.Scale); ok {
+			prediction = calibratedScore(config.Calibration, score)
+		}
+	}
 	return prediction
 }
@@ -1,11 +1,17 @@
 package backend
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/go-skynet/LocalAI/pkg/downloader"
+	"github.com/go-skynet/LocalAI/pkg/grpc"
 	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
 	model "github.com/go-skynet/LocalAI/pkg/model"
+	"github.com/go-skynet/LocalAI/pkg/sandbox"
+	"github.com/rs/zerolog/log"
 
 	config "github.com/go-skynet/LocalAI/api/config"
 	"github.com/go-skynet/LocalAI/api/options"
@@ -20,6 +26,15 @@ func modelOpts(c config.Config, o *options.Option, opts []model.Option) []model.
 		opts = append(opts, model.EnableParallelRequests)
 	}
 
+	if slots := initialSlots(c); c.Scheduler.Policy != "" && (slots > 1 || c.Scheduler.Autoscale.MaxSlots > 1) {
+		// the scheduler admits up to slots requests at once, which llama.cpp processes in its parallel slots,
+		// batching their tokens together (continuous batching)
+		opts = append(opts, model.EnableParallelRequests)
+		if _, set := c.Environment["LLAMACPP_PARALLEL"]; !set {
+			opts = append(opts, model.WithEnvironment(fmt.Sprintf("LLAMACPP_PARALLEL=%d", slots)))
+		}
+	}
+
 	if c.GRPC.Attempts != 0 {
 		opts = append(opts, model.WithGRPCAttempts(c.GRPC.Attempts))
 	}
@@ -28,6 +43,91 @@ func modelOpts(c config.Config, o *options.Option, opts []model.Option) []model.
 		opts = append(opts, model.WithGRPCAttemptsDelay(c.GRPC.AttemptsSleepTime))
 	}
 
+	if c.GRPC.BackoffMultiplier != 0 || c.GRPC.MaxSleepTime != 0 {
+		opts = append(opts, model.WithGRPCBackoff(c.GRPC.BackoffMultiplier, c.GRPC.MaxSleepTime))
+	}
+
+	if c.GRPC.Jitter != 0 {
+		opts = append(opts, model.WithGRPCJitter(c.GRPC.Jitter))
+	}
+
+	if c.GRPC.Deadline != 0 {
+		opts = append(opts, model.WithGRPCDeadline(c.GRPC.Deadline))
+	}
+
+	if c.GRPC.KeepaliveTime != 0 {
+		if time.Duration(c.GRPC.KeepaliveTime)*time.Second < grpc.MinKeepaliveTime {
+			log.Warn().Msgf("The keepalive_time of the model %s is raised to %s, the minimum the backends accept", c.Name, grpc.MinKeepaliveTime)
+		}
+		timeout := c.GRPC.KeepaliveTimeout
+		if timeout == 0 {
+			timeout = 20
+		}
+		opts = append(opts, model.WithGRPCClientOptions(grpc.WithKeepalive(time.Duration(c.GRPC.KeepaliveTime)*time.Second, time.Duration(timeout)*time.Second)))
+	}
+
+	if c.GRPC.CallTimeout != 0 {
+		opts = append(opts, model.WithGRPCClientOptions(grpc.WithCallTimeout(time.Duration(c.GRPC.CallTimeout)*time.Second)))
+	}
+
+	if l := c.Limits; l.Nice != 0 || len(l.CPUs) > 0 || l.MaxMemoryMB != 0 || l.MaxOpenFiles != 0 {
+		opts = append(opts, model.WithProcessLimits(model.ProcessLimits{
+			Nice:         l.Nice,
+			CPUs:         l.CPUs,
+			MaxMemory:    l.MaxMemoryMB * 1024 * 1024,
+			MaxOpenFiles: l.MaxOpenFiles,
+		}))
+	}
+
+	if c.Isolation.Enabled || o.BackendIsolation {
+		opts = append(opts, model.WithIsolation(isolation(c, o)))
+	}
+
+	if spec := sandboxSpec(c, o); spec.Enabled() {
+		opts = append(opts, model.WithSandbox(spec))
+	}
+
+	// the distribute policy is applied by llama.cpp, the others to the process of the backend
+	if c.NUMA != "" && c.NUMA != config.NUMADistribute {
+		opts = append(opts, model.WithNUMAPolicy(string(c.NUMA)))
+	}
+
+	if c.Warmup.Tokens > 0 {
+		warmup := gRPCPredictOpts(c, o.Loader.ModelPath)
+		warmup.Prompt = c.Warmup.Prompt
+		if warmup.Prompt == "" {
+			warmup.Prompt = "Hello"
+		}
+		warmup.Tokens = int32(c.Warmup.Tokens)
+		// the warm-up prompt must not end up in the prompt cache
+		warmup.PromptCachePath = ""
+		warmup.PromptCacheAll = false
+		opts = append(opts, model.WithWarmup(warmup))
+	}
+
+	if c.NGPULayers == nil {
+		opts = append(opts, model.WithAutoGPULayers())
+	}
+
+	if err := c.CheckGPUs(); err != nil {
+		log.Warn().Msgf("The GPUs of the model %s are misconfigured: %s", c.Name, err.Error())
+	}
+	if len(c.GPUs) > 0 {
+		// the environment of the model can still set the devices
+		opts = append(opts, model.WithGPUs(c.GPUs...))
+	}
+
+	// backends write their scratch files (e.g. converted audio) to the workspace too
+	if _, set := c.Environment["TMPDIR"]; o.Workspace != nil && !set {
+		opts = append(opts, model.WithEnvironment("TMPDIR="+o.Workspace.Dir()))
+	}
+
+	opts = append(opts, model.WithEnvironment(downloader.BackendEnvironment()...))
+
+	for k, v := range c.Environment {
+		opts = append(opts, model.WithEnvironment(fmt.Sprintf("%s=%s", k, v)))
+	}
+
 	for k, v := range o.ExternalGRPCBackends {
 		opts = append(opts, model.WithExternalBackend(k, v))
 	}
@@ -35,12 +135,89 @@ func modelOpts(c config.Config, o *options.Option, opts []model.Option) []model.
 	return opts
 }
 
+// isolation returns the paths the isolated backend of the model needs, besides the model itself
+func isolation(c config.Config, o *options.Option) model.Isolation {
+	modelPath := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return o.Loader.ModelFile(p)
+	}
+
+	i := model.Isolation{
+		ReadOnly:  []string{modelPath(c.MMProj), modelPath(c.LoraAdapter), modelPath(c.LoraBase)},
+		ReadWrite: []string{o.ImageDir, o.AudioDir},
+	}
+	if o.Workspace != nil {
+		i.ReadWrite = append(i.ReadWrite, o.Workspace.Dir())
+	}
+	if c.PromptCachePath != "" {
+		// the directory of the prompt cache is writable, unless it's the models path itself
+		cache := c.PromptCachePath
+		if !filepath.IsAbs(cache) {
+			// the prompt cache is written to the models path, even if it exists in another directory
+			cache = filepath.Join(o.Loader.ModelPath, cache)
+		}
+		if dir := filepath.Dir(cache); dir != filepath.Clean(o.Loader.ModelPath) {
+			i.ReadWrite = append(i.ReadWrite, dir)
+		}
+	}
+	for _, p := range c.Isolation.ReadOnly {
+		i.ReadOnly = append(i.ReadOnly, modelPath(p))
+	}
+	for _, p := range c.Isolation.ReadWrite {
+		i.ReadWrite = append(i.ReadWrite, modelPath(p))
+	}
+	return i
+}
+
+// sandboxSpec returns the sandbox of the backend of the model, its config overriding the defaults of LocalAI
+func sandboxSpec(c config.Config, o *options.Option) sandbox.Spec {
+	spec := o.BackendSandbox
+	if c.Isolation.User != "" {
+		spec.User = c.Isolation.User
+	}
+	spec.Landlock = spec.Landlock || c.Isolation.Landlock
+	if p := c.Isolation.Seccomp; p != "" {
+		if !filepath.IsAbs(p) {
+			p = o.Loader.ModelFile(p)
+		}
+		spec.Seccomp = p
+	}
+	if spec.Landlock {
+		// the same paths as the isolation
+		i := isolation(c, o)
+		spec.ReadOnly, spec.ReadWrite = i.ReadOnly, i.ReadWrite
+	}
+	return spec
+}
+
+// mmap returns whether the model file is memory mapped, which is the default
+func mmap(c config.Config) bool {
+	return c.MMap == nil || *c.MMap
+}
+
+func mlock(c config.Config) bool {
+	return c.MMlock != nil && *c.MMlock
+}
+
 func gRPCModelOpts(c config.Config) *pb.ModelOptions {
 	b := 512
 	if c.Batch != 0 {
 		b = c.Batch
 	}
 
+	nGPULayers := 0
+	if c.NGPULayers != nil {
+		nGPULayers = *c.NGPULayers
+	}
+
+	parallel := 0
+	if _, set := c.Environment["LLAMACPP_PARALLEL"]; c.Scheduler.Policy != "" && !set {
+		// the slots of the scheduler, which are scaled with its queue
+		parallel = currentSlots(c)
+	}
+
 	return &pb.ModelOptions{
 		ContextSize:    int32(c.ContextSize),
 		Seed:           int32(c.Seed),
@@ -61,19 +238,20 @@ func gRPCModelOpts(c config.Config) *pb.ModelOptions {
 		NGQA:           c.NGQA,
 		RMSNormEps:     c.RMSNormEps,
 		F16Memory:      c.F16,
-		MLock:          c.MMlock,
+		MLock:          mlock(c),
 		RopeFreqBase:   c.RopeFreqBase,
 		RopeScaling:    c.RopeScaling,
 		Type:           c.ModelType,
 		RopeFreqScale:  c.RopeFreqScale,
-		NUMA:           c.NUMA,
+		NUMA:           c.NUMA == config.NUMADistribute,
 		Embeddings:     c.Embeddings,
 		LowVRAM:        c.LowVRAM,
-		NGPULayers:     int32(c.NGPULayers),
-		MMap:           c.MMap,
+		NGPULayers:     int32(nGPULayers),
+		MMap:           mmap(c),
 		MainGPU:        c.MainGPU,
 		Threads:        int32(c.Threads),
 		TensorSplit:    c.TensorSplit,
+		Parallel:       int32(parallel),
 		// AutoGPTQ
 		ModelBaseName:    c.AutoGPTQ.ModelBaseName,
 		Device:           c.AutoGPTQ.Device,
@@ -119,11 +297,13 @@ func gRPCPredictOpts(c config.Config, modelPath string) *pb.PredictOptions {
 		IgnoreEOS:           c.IgnoreEOS,
 		Seed:                int32(c.Seed),
 		FrequencyPenalty:    float32(c.FrequencyPenalty),
-		MLock:               c.MMlock,
-		MMap:                c.MMap,
+		MLock:               mlock(c),
+		MMap:                mmap(c),
 		MainGPU:             c.MainGPU,
 		TensorSplit:         c.TensorSplit,
 		TailFreeSamplingZ:   float32(c.TFZ),
 		TypicalP:            float32(c.TypicalP),
+		Logprobs:            c.Logprobs,
+		TopLogprobs:         int32(c.TopLogprobs),
 	}
 }
@@ -0,0 +1,56 @@
+package backend
+
+import (
+	"sync"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/pkg/promptcache"
+)
+
+var promptCachesMu sync.Mutex
+var promptCaches = map[string]*promptcache.Router{}
+
+// routePrompt returns the slot of the backend for the prompt, when the model has prefix caching enabled, with the
+// function to call once the inference is done. The slot is 0 otherwise, letting the backend choose.
+func routePrompt(c config.Config, prompt string) (int32, func()) {
+	if !c.PrefixCache.Enabled {
+		return 0, func() {}
+	}
+
+	promptCachesMu.Lock()
+	r, ok := promptCaches[c.Name]
+	if !ok {
+		slots := c.PrefixCache.Slots
+		if slots == 0 {
+			slots = currentSlots(c)
+		}
+		r = promptcache.New(slots, c.PrefixCache.BlockSize)
+		promptCaches[c.Name] = r
+	}
+	promptCachesMu.Unlock()
+
+	slot, release := r.Route(prompt)
+	// the slots of the backend start from 0, 0 is unset in the request
+	return int32(slot + 1), release
+}
+
+// observePromptCache records the tokens of the prompt the backend of the model reused from its cache
+func observePromptCache(c config.Config, cached, evaluated int32) {
+	promptCachesMu.Lock()
+	r, ok := promptCaches[c.Name]
+	promptCachesMu.Unlock()
+	if ok && (cached > 0 || evaluated > 0) {
+		r.Observe(int(cached), int(evaluated))
+	}
+}
+
+// PromptCacheStats returns the statistics of the prompt cache of the models with prefix caching enabled
+func PromptCacheStats() map[string]promptcache.Stats {
+	promptCachesMu.Lock()
+	defer promptCachesMu.Unlock()
+	stats := map[string]promptcache.Stats{}
+	for name, r := range promptCaches {
+		stats[name] = r.Stats()
+	}
+	return stats
+}
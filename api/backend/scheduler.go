@@ -0,0 +1,132 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/scheduler"
+	"github.com/rs/zerolog/log"
+)
+
+type modelScheduler struct {
+	*scheduler.Scheduler
+	predictor *scheduler.Predictor
+}
+
+var schedulersMu sync.Mutex
+var schedulers = map[string]*modelScheduler{}
+
+type queueStatusKey struct{}
+
+// WithQueueStatus sets the function called with the position of the inferences run with the returned context while
+// they are queued, and with a zero status once they start after having been queued
+func WithQueueStatus(ctx context.Context, fn func(scheduler.Status)) context.Context {
+	return context.WithValue(ctx, queueStatusKey{}, fn)
+}
+
+func queueStatus(ctx context.Context) func(scheduler.Status) {
+	fn, _ := ctx.Value(queueStatusKey{}).(func(scheduler.Status))
+	return fn
+}
+
+type clientKey struct{}
+
+type priorityKey struct{}
+
+// defaultPriorities are the priority classes of the models which don't define theirs
+var defaultPriorities = map[string]int{"high": 1, "normal": 0, "low": -1}
+
+// WithPriority sets the priority class of the inferences run with the returned context, among the classes of the model
+func WithPriority(ctx context.Context, class string) context.Context {
+	return context.WithValue(ctx, priorityKey{}, class)
+}
+
+// priority returns the priority of the class of the inference on the model, the unknown classes are ignored
+func priority(ctx context.Context, c config.Config) int {
+	class, _ := ctx.Value(priorityKey{}).(string)
+	if class == "" {
+		return 0
+	}
+	classes := c.Scheduler.Priorities
+	if len(classes) == 0 {
+		classes = defaultPriorities
+	}
+	p, ok := classes[class]
+	if !ok {
+		log.Warn().Msgf("Unknown priority class %q for the model %s, ignoring it", class, c.Name)
+	}
+	return p
+}
+
+// WithClient sets the client of the inferences run with the returned context, which the fair scheduling policy serves in turn with the other clients
+func WithClient(ctx context.Context, client string) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// schedule waits for the turn of the prompt on the model, when the model has a scheduling policy.
+// The returned function must be called with the length of the output once the inference is done.
+func schedule(ctx context.Context, c config.Config, o *options.Option, prompt string) (func(outputTokens int), error) {
+	if c.Scheduler.Policy == "" {
+		return func(int) {}, nil
+	}
+
+	schedulersMu.Lock()
+	s, ok := schedulers[c.Name]
+	if !ok {
+		sc, err := scheduler.New(initialSlots(c), scheduler.Policy(c.Scheduler.Policy), time.Duration(c.Scheduler.MaxWait)*time.Second)
+		if err != nil {
+			schedulersMu.Unlock()
+			return nil, err
+		}
+		s = &modelScheduler{Scheduler: sc, predictor: scheduler.NewPredictor()}
+		schedulers[c.Name] = s
+		if c.Scheduler.Autoscale.MaxSlots > 0 {
+			go autoscale(o, c, s)
+		}
+	}
+	schedulersMu.Unlock()
+	// the depth follows the changes of the config
+	s.SetMaxQueue(c.Scheduler.MaxQueue)
+
+	fn := queueStatus(ctx)
+	queued := false
+	var enqueued time.Time
+	status := func(st scheduler.Status) {
+		if !queued {
+			queued, enqueued = true, time.Now()
+			if o.Metrics != nil {
+				o.Metrics.ObserveQueued(c.Name)
+			}
+		}
+		if fn != nil {
+			fn(st)
+		}
+	}
+	client, _ := ctx.Value(clientKey{}).(string)
+	release, err := s.AcquireRequest(ctx, scheduler.Request{
+		Client:   client,
+		Priority: priority(ctx, c),
+		Cost:     s.predictor.Predict(len(prompt), c.Maxtokens),
+	}, status)
+	if queued && o.Metrics != nil {
+		o.Metrics.ObserveDequeued(c.Name, time.Since(enqueued).Seconds())
+	}
+	if err != nil {
+		var full *scheduler.QueueFullError
+		if errors.As(err, &full) && o.Metrics != nil {
+			o.Metrics.ObserveRejected(c.Name)
+		}
+		return nil, err
+	}
+	if queued && fn != nil {
+		fn(scheduler.Status{})
+	}
+	return func(outputTokens int) {
+		release()
+		s.predictor.Observe(len(prompt), outputTokens)
+	}, nil
+}
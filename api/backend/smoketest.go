@@ -0,0 +1,77 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	model "github.com/go-skynet/LocalAI/pkg/model"
+)
+
+type SmokeTestResult struct {
+	Name     string `json:"name"`
+	Passed   bool   `json:"passed"`
+	Response string `json:"response,omitempty"`
+	Latency  int64  `json:"latency_ms"`
+	Error    string `json:"error,omitempty"`
+}
+
+// RunSmokeTests runs the smoke tests defined in the model config.
+// Prompts are sent to the model as-is, without applying any template.
+func RunSmokeTests(c config.Config, loader *model.ModelLoader, o *options.Option) []SmokeTestResult {
+	results := []SmokeTestResult{}
+	for i, t := range c.SmokeTests {
+		name := t.Name
+		if name == "" {
+			name = fmt.Sprintf("%s-%d", c.Name, i)
+		}
+		results = append(results, runSmokeTest(name, t, c, loader, o))
+	}
+	return results
+}
+
+func runSmokeTest(name string, t config.SmokeTest, c config.Config, loader *model.ModelLoader, o *options.Option) SmokeTestResult {
+	res := SmokeTestResult{Name: name}
+
+	start := time.Now()
+	fn, err := ModelInference(o.Context, t.Prompt, nil, loader, c, o, nil)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	prediction, err := fn()
+	res.Latency = time.Since(start).Milliseconds()
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Response = Finetune(c, t.Prompt, prediction.Response)
+
+	if t.MaxLatency > 0 && res.Latency > int64(t.MaxLatency) {
+		res.Error = fmt.Sprintf("latency %dms exceeds the maximum of %dms", res.Latency, t.MaxLatency)
+		return res
+	}
+
+	if t.Expect != "" {
+		re, err := regexp.Compile(t.Expect)
+		if err != nil {
+			res.Error = fmt.Sprintf("invalid expect regex %q: %s", t.Expect, err.Error())
+			return res
+		}
+		if !re.MatchString(res.Response) {
+			res.Error = fmt.Sprintf("response does not match %q", t.Expect)
+			return res
+		}
+	}
+
+	if t.JSON && !json.Valid([]byte(res.Response)) {
+		res.Error = "response is not valid JSON"
+		return res
+	}
+
+	res.Passed = true
+	return res
+}
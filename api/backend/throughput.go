@@ -0,0 +1,13 @@
+package backend
+
+import (
+	"github.com/go-skynet/LocalAI/pkg/throughput"
+)
+
+// the inferences of the models over the last minutes
+var inferences = throughput.New(throughput.DefaultWindow)
+
+// ThroughputStats returns the token throughput and latency statistics of the models with inferences in the window
+func ThroughputStats() map[string]throughput.Stats {
+	return inferences.Stats()
+}
@@ -0,0 +1,151 @@
+package backend
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/go-skynet/LocalAI/pkg/grpc"
+	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
+	model "github.com/go-skynet/LocalAI/pkg/model"
+	"github.com/go-skynet/LocalAI/pkg/tokenizer"
+	"github.com/rs/zerolog/log"
+)
+
+// sharedTokenizer is the tokenizer embedded in a GGUF model file, loaded once and shared by the requests and by the
+// token counting of the inferences of the model
+type sharedTokenizer struct {
+	modTime time.Time
+	size    int64
+	once    sync.Once
+	// nil when the vocabulary of the file is not supported, the model is then tokenized by its backend
+	tokenizer *tokenizer.Tokenizer
+}
+
+var (
+	sharedTokenizersMu sync.Mutex
+	sharedTokenizers   = map[string]*sharedTokenizer{}
+)
+
+// modelTokenizer returns the shared tokenizer of the model, loaded again when its file changes
+func modelTokenizer(loader *model.ModelLoader, c config.Config) *tokenizer.Tokenizer {
+	path := loader.ModelFile(c.Model)
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return nil
+	}
+
+	sharedTokenizersMu.Lock()
+	st, ok := sharedTokenizers[path]
+	if !ok || !st.modTime.Equal(info.ModTime()) || st.size != info.Size() {
+		st = &sharedTokenizer{modTime: info.ModTime(), size: info.Size()}
+		sharedTokenizers[path] = st
+	}
+	sharedTokenizersMu.Unlock()
+
+	st.once.Do(func() {
+		t, err := tokenizer.FromGGUF(path)
+		if err != nil {
+			log.Debug().Msgf("The model %s is tokenized by its backend: %v", c.Model, err)
+			return
+		}
+		st.tokenizer = t
+	})
+	return st.tokenizer
+}
+
+// maximum number of prompts tokenized by the backends kept for each model
+const tokenizerCacheSize = 256
+
+// tokenizerCache is shared between the tokenize endpoint and the token counting
+// done during inference, so the same prompt is not tokenized twice by the backend
+// of the models without a shared tokenizer.
+type tokenizerCache struct {
+	sync.Mutex
+	models map[string]map[string]*pb.TokenizationResponse
+}
+
+var tokenizers = &tokenizerCache{models: map[string]map[string]*pb.TokenizationResponse{}}
+
+func (tc *tokenizerCache) get(model, s string) (*pb.TokenizationResponse, bool) {
+	tc.Lock()
+	defer tc.Unlock()
+	res, ok := tc.models[model][s]
+	return res, ok
+}
+
+func (tc *tokenizerCache) set(model, s string, res *pb.TokenizationResponse) {
+	tc.Lock()
+	defer tc.Unlock()
+	cache, ok := tc.models[model]
+	if !ok || len(cache) >= tokenizerCacheSize {
+		cache = map[string]*pb.TokenizationResponse{}
+		tc.models[model] = cache
+	}
+	cache[s] = res
+}
+
+// tokenize tokenizes the prompt in opts with the shared tokenizer of the model, or with the given backend, reusing
+// cached results when available
+func tokenize(ctx context.Context, loader *model.ModelLoader, backend grpc.Backend, c config.Config, opts *pb.PredictOptions) (*pb.TokenizationResponse, error) {
+	if t := modelTokenizer(loader, c); t != nil {
+		tokens := t.Encode(opts.Prompt)
+		return &pb.TokenizationResponse{Length: int32(len(tokens)), Tokens: tokens}, nil
+	}
+	if res, ok := tokenizers.get(c.Model, opts.Prompt); ok {
+		return res, nil
+	}
+
+	res, err := backend.TokenizeString(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenizers.set(c.Model, opts.Prompt, res)
+	return res, nil
+}
+
+// ModelTokenize tokenizes the text with the shared tokenizer of the model, without loading it, or with its backend
+func ModelTokenize(s string, loader *model.ModelLoader, c config.Config, o *options.Option) (schema.TokenizeResponse, error) {
+	if t := modelTokenizer(loader, c); t != nil {
+		tokens := t.Encode(s)
+		return schema.TokenizeResponse{Tokens: tokens, Count: len(tokens)}, nil
+	}
+
+	inferenceModel, err := ModelLoad(loader, c, o)
+	if err != nil {
+		return schema.TokenizeResponse{}, err
+	}
+
+	predictOptions := gRPCPredictOpts(c, loader.ModelPath)
+	predictOptions.Prompt = s
+
+	res, err := tokenize(o.Context, loader, inferenceModel, c, predictOptions)
+	if err != nil {
+		return schema.TokenizeResponse{}, err
+	}
+
+	return schema.TokenizeResponse{Tokens: res.Tokens, Count: len(res.Tokens)}, nil
+}
+
+func ModelDetokenize(tokens []int32, loader *model.ModelLoader, c config.Config, o *options.Option) (schema.DetokenizeResponse, error) {
+	if t := modelTokenizer(loader, c); t != nil {
+		return schema.DetokenizeResponse{Content: t.Decode(tokens)}, nil
+	}
+
+	inferenceModel, err := ModelLoad(loader, c, o)
+	if err != nil {
+		return schema.DetokenizeResponse{}, err
+	}
+
+	res, err := inferenceModel.Detokenize(o.Context, &pb.DetokenizationRequest{Tokens: tokens})
+	if err != nil {
+		return schema.DetokenizeResponse{}, err
+	}
+
+	return schema.DetokenizeResponse{Content: res.Content}, nil
+}
@@ -0,0 +1,156 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/internal"
+	"github.com/go-skynet/LocalAI/pkg/grpc"
+	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
+	model "github.com/go-skynet/LocalAI/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// Trace is the record of an inference: everything needed to run it again, and its output
+type Trace struct {
+	Version        string             `json:"version"`
+	Time           time.Time          `json:"time"`
+	Model          string             `json:"model"`
+	Backend        string             `json:"backend,omitempty"`
+	ModelOptions   *pb.ModelOptions   `json:"model_options"`
+	PredictOptions *pb.PredictOptions `json:"predict_options"`
+	// Tokens are the chunks streamed by the backend, empty if the prediction was not streamed
+	Tokens   []string `json:"tokens,omitempty"`
+	Response string   `json:"response"`
+	Error    string   `json:"error,omitempty"`
+}
+
+type traceKey struct{}
+
+// WithTrace flags the inferences run with the returned context, so that they are recorded when a trace directory is set
+func WithTrace(ctx context.Context) context.Context {
+	return context.WithValue(ctx, traceKey{}, true)
+}
+
+func traced(ctx context.Context) bool {
+	v, _ := ctx.Value(traceKey{}).(bool)
+	return v
+}
+
+func newTrace(c config.Config, modelOpts *pb.ModelOptions, predictOpts *pb.PredictOptions) *Trace {
+	// a random seed can't be replayed: pick one and record it
+	if predictOpts.Seed == -1 {
+		predictOpts.Seed = rand.Int31()
+	}
+	return &Trace{
+		Version:        internal.PrintableVersion(),
+		Time:           time.Now(),
+		Model:          c.Model,
+		Backend:        c.Backend,
+		ModelOptions:   modelOpts,
+		PredictOptions: predictOpts,
+	}
+}
+
+func (t *Trace) save(dir string, res LLMResponse, err error) {
+	t.Response = res.Response
+	if err != nil {
+		t.Error = err.Error()
+	}
+
+	dat, mErr := json.MarshalIndent(t, "", "  ")
+	if mErr == nil {
+		name := fmt.Sprintf("%s-%s.json", t.Time.Format("20060102-150405.000000"), filepath.Base(t.Model))
+		mErr = os.WriteFile(filepath.Join(dir, name), dat, 0600)
+	}
+	if mErr != nil {
+		log.Error().Msgf("failed writing the inference trace: %s", mErr.Error())
+	}
+}
+
+func LoadTrace(path string) (*Trace, error) {
+	dat, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	t := &Trace{}
+	if err := json.Unmarshal(dat, t); err != nil {
+		return nil, fmt.Errorf("failed parsing trace %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// ReplayTrace runs again the inference recorded in t, with the same model and prediction options
+func ReplayTrace(t *Trace, loader *model.ModelLoader, o *options.Option) (*Trace, error) {
+	opts := []model.Option{
+		model.WithLoadGRPCLoadModelOpts(t.ModelOptions),
+		model.WithThreads(uint32(t.ModelOptions.Threads)),
+		model.WithAssetDir(o.AssetsDestination),
+		model.WithModel(t.Model),
+		model.WithContext(o.Context),
+	}
+
+	var inferenceModel grpc.Backend
+	var err error
+	if t.Backend == "" {
+		inferenceModel, err = loader.GreedyLoader(opts...)
+	} else {
+		inferenceModel, err = loader.BackendLoader(append(opts, model.WithBackendString(t.Backend))...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	replay := &Trace{
+		Version:        internal.PrintableVersion(),
+		Time:           time.Now(),
+		Model:          t.Model,
+		Backend:        t.Backend,
+		ModelOptions:   t.ModelOptions,
+		PredictOptions: t.PredictOptions,
+	}
+	err = inferenceModel.PredictStream(o.Context, t.PredictOptions, func(reply *pb.Reply) {
+		replay.Tokens = append(replay.Tokens, string(reply.Message))
+		replay.Response += string(reply.Message)
+	})
+	if err != nil {
+		replay.Error = err.Error()
+	}
+	return replay, nil
+}
+
+// Diff describes where the output of the replay differs from the original trace, it is empty if they match
+func (t *Trace) Diff(replay *Trace) string {
+	if t.Response == replay.Response && t.Error == replay.Error {
+		return ""
+	}
+
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "recorded with %s, replayed with %s\n", t.Version, replay.Version)
+	if t.Error != replay.Error {
+		fmt.Fprintf(sb, "error: %q != %q\n", t.Error, replay.Error)
+	}
+
+	// the traces of non-streamed predictions have no tokens, diff the responses instead
+	a, b, unit := t.Tokens, replay.Tokens, "tokens"
+	if len(a) == 0 {
+		a, b, unit = strings.Split(t.Response, ""), strings.Split(replay.Response, ""), "characters"
+	}
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	common := strings.Join(a[:i], "")
+	fmt.Fprintf(sb, "outputs diverge after %d %s: %q\n", i, unit, common)
+	fmt.Fprintf(sb, "- %q\n", strings.Join(a[i:], ""))
+	fmt.Fprintf(sb, "+ %q\n", strings.Join(b[i:], ""))
+	return sb.String()
+}
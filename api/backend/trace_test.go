@@ -0,0 +1,50 @@
+package backend_test
+
+import (
+	. "github.com/go-skynet/LocalAI/api/backend"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Trace", func() {
+	recorded := &Trace{Version: "v1", Tokens: []string{"Hello", ",", " world"}, Response: "Hello, world"}
+
+	replayed := func(tokens ...string) *Trace {
+		t := &Trace{Version: "v2", Tokens: tokens}
+		for _, token := range tokens {
+			t.Response += token
+		}
+		return t
+	}
+
+	It("has no diff when the outputs match", func() {
+		Expect(recorded.Diff(replayed("Hello", ",", " world"))).To(BeEmpty())
+	})
+
+	DescribeTable("shows where the outputs diverge",
+		func(replay *Trace, expected string) {
+			Expect(recorded.Diff(replay)).To(Equal("recorded with v1, replayed with v2\n" + expected))
+		},
+		Entry("with added tokens", replayed("Hello", ",", " world", "!"),
+			"outputs diverge after 3 tokens: \"Hello, world\"\n- \"\"\n+ \"!\"\n"),
+		Entry("with removed tokens", replayed("Hello", ","),
+			"outputs diverge after 2 tokens: \"Hello,\"\n- \" world\"\n+ \"\"\n"),
+		Entry("with changed tokens", replayed("Hello", ",", " there"),
+			"outputs diverge after 2 tokens: \"Hello,\"\n- \" world\"\n+ \" there\"\n"),
+	)
+
+	It("shows the changed errors", func() {
+		replay := replayed("Hello", ",", " world")
+		replay.Error = "backend crashed"
+		Expect(recorded.Diff(replay)).To(Equal("recorded with v1, replayed with v2\n" +
+			"error: \"\" != \"backend crashed\"\n" +
+			"outputs diverge after 3 tokens: \"Hello, world\"\n- \"\"\n+ \"\"\n"))
+	})
+
+	It("diffs the characters of the predictions which were not streamed", func() {
+		t := &Trace{Version: "v1", Response: "Hello, world"}
+		replay := &Trace{Version: "v2", Response: "Hello, there"}
+		Expect(t.Diff(replay)).To(Equal("recorded with v1, replayed with v2\n" +
+			"outputs diverge after 7 characters: \"Hello, \"\n- \"world\"\n+ \"there\"\n"))
+	})
+})
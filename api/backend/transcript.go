@@ -2,18 +2,102 @@ package backend
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"time"
 
 	config "github.com/go-skynet/LocalAI/api/config"
 	"github.com/go-skynet/LocalAI/api/schema"
 
 	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/grpc"
 	"github.com/go-skynet/LocalAI/pkg/grpc/proto"
 	model "github.com/go-skynet/LocalAI/pkg/model"
+	"github.com/go-skynet/LocalAI/pkg/realtime"
 )
 
-func ModelTranscription(audio, language string, loader *model.ModelLoader, c config.Config, o *options.Option) (*schema.Result, error) {
+// TranscriptionRate is the sample rate of the audio of the transcriptions, the one of whisper
+const TranscriptionRate = 16000
 
+// ErrNoTranslation is returned when a translation is asked to a backend speaking a protocol which predates them
+var ErrNoTranslation = errors.New("the backend can't translate the audio, its assets might be outdated")
+
+// ModelTranscription transcribes the audio, with the timestamps of its words if words is set
+func ModelTranscription(audio, language string, translate, words bool, loader *model.ModelLoader, c config.Config, o *options.Option) (*schema.Result, error) {
+	whisperModel, err := transcriptionModel(loader, c, o, translate)
+	if err != nil {
+		return nil, err
+	}
+
+	return whisperModel.AudioTranscription(context.Background(), &proto.TranscriptRequest{
+		Dst:            audio,
+		Language:       language,
+		Translate:      translate,
+		Threads:        uint32(c.Threads),
+		WordTimestamps: words,
+	})
+}
+
+// ModelTranscriptionStream transcribes a long audio in chunks of length, written in dir, and calls f with the segments
+// of each chunk as soon as it is transcribed. The timestamps and the IDs of the segments, and the timestamps of the
+// words, are the ones of the whole audio.
+func ModelTranscriptionStream(ctx context.Context, audio, dir, language string, translate, words bool, length time.Duration, loader *model.ModelLoader, c config.Config, o *options.Option, f func(*schema.Result) error) error {
+	whisperModel, err := transcriptionModel(loader, c, o, translate)
+	if err != nil {
+		return err
+	}
+
+	samples, err := realtime.DecodeAudio(ctx, o.FFmpegPath(), audio, TranscriptionRate)
+	if err != nil {
+		return err
+	}
+	defer samples.Close()
+
+	id := 0
+	return realtime.SplitAudio(samples, TranscriptionRate, length, func(chunk realtime.Chunk) error {
+		file := filepath.Join(dir, "chunk.wav")
+		out, err := os.Create(file)
+		if err != nil {
+			return err
+		}
+		err = realtime.WriteWAV(out, chunk.Samples, TranscriptionRate)
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+		defer os.Remove(file)
+
+		tr, err := whisperModel.AudioTranscription(ctx, &proto.TranscriptRequest{
+			Dst:            file,
+			Language:       language,
+			Translate:      translate,
+			Threads:        uint32(c.Threads),
+			WordTimestamps: words,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to transcribe the audio at %s: %w", chunk.Start, err)
+		}
+		for i := range tr.Segments {
+			tr.Segments[i].Id = id
+			tr.Segments[i].Start += chunk.Start
+			tr.Segments[i].End += chunk.Start
+			id++
+		}
+		for i := range tr.Words {
+			tr.Words[i].Start += chunk.Start
+			tr.Words[i].End += chunk.Start
+		}
+		return f(tr)
+	})
+}
+
+// transcriptionModel loads the transcription backend of the model, which must speak a protocol version knowing the
+// translations if translate is set
+func transcriptionModel(loader *model.ModelLoader, c config.Config, o *options.Option, translate bool) (grpc.Backend, error) {
 	opts := modelOpts(c, o, []model.Option{
 		model.WithBackendString(model.WhisperBackend),
 		model.WithModel(c.Model),
@@ -31,9 +115,11 @@ func ModelTranscription(audio, language string, loader *model.ModelLoader, c con
 		return nil, fmt.Errorf("could not load whisper model")
 	}
 
-	return whisperModel.AudioTranscription(context.Background(), &proto.TranscriptRequest{
-		Dst:      audio,
-		Language: language,
-		Threads:  uint32(c.Threads),
-	})
+	if translate {
+		caps, err := whisperModel.Capabilities(o.Context)
+		if err != nil || caps.ProtocolVersion < grpc.TranslationProtocolVersion {
+			return nil, fmt.Errorf("%w: %s", ErrNoTranslation, c.Name)
+		}
+	}
+	return whisperModel, nil
 }
@@ -5,13 +5,19 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/go-audio/wav"
 	api_config "github.com/go-skynet/LocalAI/api/config"
 	config "github.com/go-skynet/LocalAI/api/config"
 	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/grpc"
 	"github.com/go-skynet/LocalAI/pkg/grpc/proto"
 	model "github.com/go-skynet/LocalAI/pkg/model"
+	"github.com/go-skynet/LocalAI/pkg/realtime"
+	"github.com/go-skynet/LocalAI/pkg/ssml"
 	"github.com/go-skynet/LocalAI/pkg/utils"
+	"github.com/rs/zerolog/log"
 )
 
 func generateUniqueFileName(dir, baseName, ext string) string {
@@ -30,11 +36,13 @@ func generateUniqueFileName(dir, baseName, ext string) string {
 	}
 }
 
-func ModelTTS(backend, text, modelFile string, loader *model.ModelLoader, o *options.Option, c config.Config) (string, *proto.Result, error) {
-	bb := backend
+// ModelTTS synthesizes the speech of the text with the voice, in a WAV file of the audio directory
+func ModelTTS(text string, voice config.Voice, loader *model.ModelLoader, o *options.Option, c config.Config) (string, *proto.Result, error) {
+	bb := voice.Backend
 	if bb == "" {
 		bb = model.PiperBackend
 	}
+	modelFile := voice.Model
 
 	grpcOpts := gRPCModelOpts(c)
 
@@ -65,20 +73,105 @@ func ModelTTS(backend, text, modelFile string, loader *model.ModelLoader, o *opt
 	modelPath := ""
 	if modelFile != "" {
 		if bb != model.TransformersMusicGen {
-			modelPath = filepath.Join(o.Loader.ModelPath, modelFile)
-			if err := utils.VerifyPath(modelPath, o.Loader.ModelPath); err != nil {
+			if err := utils.VerifyPath(modelFile, o.Loader.ModelPath); err != nil {
 				return "", nil, err
 			}
+			modelPath = o.Loader.ModelFile(modelFile)
 		} else {
 			modelPath = modelFile
 		}
 	}
 
-	res, err := piperModel.TTS(context.Background(), &proto.TTSRequest{
-		Text:  text,
-		Model: modelPath,
-		Dst:   filePath,
-	})
+	// the reference audio of the speaker is given by its path, the speakers of the model by their name
+	speaker := voice.Speaker
+	if speaker != "" && utils.VerifyPath(speaker, o.Loader.ModelPath) == nil {
+		if _, err := os.Stat(o.Loader.ModelFile(speaker)); err == nil {
+			speaker = o.Loader.ModelFile(speaker)
+		}
+	}
 
+	req := &proto.TTSRequest{
+		Text:     text,
+		Model:    modelPath,
+		Dst:      filePath,
+		Voice:    speaker,
+		Language: voice.Language,
+	}
+	// the SSML documents are passed as is to the backends understanding them
+	if voice.SSML || !ssml.IsSSML(text) {
+		res, err := piperModel.TTS(context.Background(), req)
+		return filePath, res, err
+	}
+	res, err := speakSSML(context.Background(), piperModel, req)
 	return filePath, res, err
 }
+
+// speakSSML speaks the texts of the SSML document of the request one by one, joined with the silences of its breaks,
+// in the WAV file of the request. The backends not generating 16 bit PCM speak the text of the document at once.
+func speakSSML(ctx context.Context, b grpc.Backend, req *proto.TTSRequest) (*proto.Result, error) {
+	parts, err := ssml.Parse(req.Text)
+	if err != nil {
+		return nil, err
+	}
+
+	var samples []int16
+	rate := 0
+	// the silence before the next text, added once the sample rate of the speech is known
+	var silence time.Duration
+	for _, p := range parts {
+		if p.Text == "" {
+			silence += p.Break
+			continue
+		}
+		part := req.Dst + ".part"
+		res, err := b.TTS(ctx, &proto.TTSRequest{Text: p.Text, Model: req.Model, Dst: part, Voice: req.Voice, Language: req.Language})
+		if err != nil || !res.Success {
+			os.Remove(part)
+			return res, err
+		}
+		dat, err := os.ReadFile(part)
+		os.Remove(part)
+		if err != nil {
+			return nil, err
+		}
+		speech, speechRate, err := realtime.ReadWAV(dat)
+		if err != nil {
+			log.Debug().Msgf("Speaking the text of the SSML document at once: %s", err.Error())
+			text, err := ssml.Text(req.Text)
+			if err != nil {
+				return nil, err
+			}
+			return b.TTS(ctx, &proto.TTSRequest{Text: text, Model: req.Model, Dst: req.Dst, Voice: req.Voice, Language: req.Language})
+		}
+		if rate == 0 {
+			rate = speechRate
+		}
+		samples = append(samples, make([]int16, int64(silence)*int64(rate)/int64(time.Second))...)
+		silence = 0
+		samples = append(samples, realtime.Amplify(realtime.Resample(speech, speechRate, rate), p.Gain)...)
+	}
+	if rate == 0 {
+		return nil, fmt.Errorf("the SSML document has no text to speak")
+	}
+	samples = append(samples, make([]int16, int64(silence)*int64(rate)/int64(time.Second))...)
+
+	out, err := os.Create(req.Dst)
+	if err != nil {
+		return nil, err
+	}
+	defer out.Close()
+	if err := realtime.WriteWAV(out, samples, rate); err != nil {
+		return nil, err
+	}
+	return &proto.Result{Success: true}, nil
+}
+
+// AudioDuration returns the duration of the WAV file generated by a TTS backend
+func AudioDuration(path string) (time.Duration, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return wav.NewDecoder(f).Duration()
+}
@@ -0,0 +1,45 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/grpc"
+	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
+	model "github.com/go-skynet/LocalAI/pkg/model"
+)
+
+// ErrNoUpscaling is returned when an image is upscaled by a model whose backend doesn't upscale images
+var ErrNoUpscaling = errors.New("the model can't upscale images")
+
+// ModelUpscale upscales the image of req.Src to req.Dst with an upscaling model
+func ModelUpscale(ctx context.Context, req *pb.UpscaleRequest, loader *model.ModelLoader, c config.Config, o *options.Option) error {
+	opts := modelOpts(c, o, []model.Option{
+		model.WithLoadGRPCLoadModelOpts(gRPCModelOpts(c)),
+		model.WithThreads(uint32(c.Threads)),
+		model.WithAssetDir(o.AssetsDestination),
+		model.WithModel(c.Model),
+		model.WithContext(o.Context),
+		model.WithBackendString(c.Backend),
+	})
+	inferenceModel, err := loader.BackendLoader(opts...)
+	if err != nil {
+		return err
+	}
+
+	caps, err := inferenceModel.Capabilities(ctx)
+	if err != nil || !grpc.ReportsCapability(caps, grpc.CapabilityUpscale) {
+		return fmt.Errorf("%w: %s", ErrNoUpscaling, c.Name)
+	}
+	res, err := inferenceModel.Upscale(ctx, req)
+	if err != nil {
+		return err
+	}
+	if !res.Success {
+		return errors.New(res.Message)
+	}
+	return nil
+}
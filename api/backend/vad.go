@@ -0,0 +1,97 @@
+package backend
+
+import (
+	"context"
+	"os"
+	"time"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/go-skynet/LocalAI/pkg/grpc"
+	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
+	model "github.com/go-skynet/LocalAI/pkg/model"
+	"github.com/go-skynet/LocalAI/pkg/realtime"
+)
+
+// ModelVAD returns the parts of the audio with speech, found by a voice activity detection model
+func ModelVAD(ctx context.Context, audio string, req *pb.VADRequest, loader *model.ModelLoader, c config.Config, o *options.Option) ([]realtime.Span, error) {
+	grpcOpts := gRPCModelOpts(c)
+
+	var inferenceModel grpc.Backend
+	var err error
+
+	opts := modelOpts(c, o, []model.Option{
+		model.WithLoadGRPCLoadModelOpts(grpcOpts),
+		model.WithThreads(uint32(c.Threads)),
+		model.WithAssetDir(o.AssetsDestination),
+		model.WithModel(c.Model),
+		model.WithContext(o.Context),
+	})
+
+	if c.Backend == "" {
+		inferenceModel, err = loader.GreedyLoader(opts...)
+	} else {
+		opts = append(opts, model.WithBackendString(c.Backend))
+		inferenceModel, err = loader.BackendLoader(opts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	req.Dst = audio
+	req.Threads = uint32(c.Threads)
+	res, err := inferenceModel.VAD(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	spans := make([]realtime.Span, 0, len(res.Segments))
+	for _, s := range res.Segments {
+		spans = append(spans, realtime.Span{Start: time.Duration(s.Start), End: time.Duration(s.End)})
+	}
+	return spans, nil
+}
+
+// the silence between the parts with speech of the audio, when its silences are removed
+const speechGap = 500 * time.Millisecond
+
+// KeepSpeech writes in dst the parts of the WAV audio with speech found by the VAD model, padded by padding, and
+// returns their timeline in the audio
+func KeepSpeech(ctx context.Context, audio, dst string, req *pb.VADRequest, padding time.Duration, loader *model.ModelLoader, c config.Config, o *options.Option) (realtime.Timeline, error) {
+	spans, err := ModelVAD(ctx, audio, req, loader, c, o)
+	if err != nil {
+		return nil, err
+	}
+	dat, err := os.ReadFile(audio)
+	if err != nil {
+		return nil, err
+	}
+	samples, rate, err := realtime.ReadWAV(dat)
+	if err != nil {
+		return nil, err
+	}
+	speech, timeline := realtime.KeepSpans(samples, rate, spans, padding, speechGap)
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return nil, err
+	}
+	err = realtime.WriteWAV(out, speech, rate)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return timeline, err
+}
+
+// RestoreTimestamps moves the timestamps of the transcription of the speech kept by KeepSpeech to the ones of the
+// whole audio
+func RestoreTimestamps(tr *schema.Result, timeline realtime.Timeline) {
+	for i := range tr.Segments {
+		tr.Segments[i].Start = timeline.Original(tr.Segments[i].Start)
+		tr.Segments[i].End = timeline.Original(tr.Segments[i].End)
+	}
+	for i := range tr.Words {
+		tr.Words[i].Start = timeline.Original(tr.Words[i].Start)
+		tr.Words[i].End = timeline.Original(tr.Words[i].End)
+	}
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	fiberContext "github.com/go-skynet/LocalAI/api/ctx"
+	"github.com/go-skynet/LocalAI/api/openai"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/go-skynet/LocalAI/pkg/apikeys"
+	"github.com/go-skynet/LocalAI/pkg/audit"
+	"github.com/go-skynet/LocalAI/pkg/model"
+	"github.com/go-skynet/LocalAI/pkg/quota"
+	"github.com/go-skynet/LocalAI/pkg/usage"
+	"github.com/gofiber/fiber/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("newBatchApp", func() {
+	var app *fiber.App
+	var store *usage.Store
+
+	BeforeEach(func() {
+		dir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, "model.bin"), []byte{}, 0600)).To(Succeed())
+		cm := config.NewConfigLoader()
+		loader := model.NewModelLoader(dir)
+
+		var err error
+		store, err = usage.Open(filepath.Join(dir, "usage.db"))
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(store.Close)
+		o := &options.Option{
+			Usage:  store,
+			Quotas: quota.NewEnforcer([]quota.Quota{{Key: audit.Fingerprint("bob"), Period: quota.Daily, Tokens: 10}}, store),
+		}
+
+		// every request of the batches uses 3 prompt and 2 completion tokens
+		batchApp := newBatchApp(o)
+		batchApp.Post("/v1/chat/completions", batchMiddleware(o, apikeys.ScopeChat), func(c *fiber.Ctx) error {
+			input := new(schema.OpenAIRequest)
+			if err := c.BodyParser(input); err != nil {
+				return err
+			}
+			m, err := fiberContext.ModelFromContext(c, cm, loader, input.Model, false)
+			if err != nil {
+				return err
+			}
+			fiberContext.RequestFromCtx(c).AddTokens(3, 2)
+			return c.JSON(fiber.Map{"model": m})
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		DeferCleanup(cancel)
+		files := openai.NewFilesService(filepath.Join(dir, "files"), 0, callerFingerprint, callerTenant)
+		batches := openai.NewBatchService(ctx, files, batchApp.Handler(), 1)
+
+		app = fiber.New()
+		app.Post("/v1/files", files.UploadFileEndpoint())
+		app.Get("/v1/files/:file_id/content", files.GetFileContentEndpoint())
+		app.Post("/v1/batches", batches.CreateBatchEndpoint())
+		app.Get("/v1/batches/:batch_id", batches.GetBatchEndpoint())
+	})
+
+	call := func(req *http.Request, key string) []byte {
+		req.Header.Set("Authorization", "Bearer "+key)
+		resp, err := app.Test(req)
+		Expect(err).ToNot(HaveOccurred())
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(fiber.StatusOK), string(body))
+		return body
+	}
+
+	// run executes a batch of two requests as the caller with the key, and returns it once completed
+	run := func(key string) schema.Batch {
+		body := &bytes.Buffer{}
+		w := multipart.NewWriter(body)
+		Expect(w.WriteField("purpose", "batch")).To(Succeed())
+		part, err := w.CreateFormFile("file", "requests.jsonl")
+		Expect(err).ToNot(HaveOccurred())
+		for _, id := range []string{"1", "2"} {
+			_, err = part.Write([]byte(`{"custom_id": "` + id + `", "method": "POST", "url": "/v1/chat/completions", "body": {"model": "model.bin"}}` + "\n"))
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(w.Close()).To(Succeed())
+		req := httptest.NewRequest("POST", "/v1/files", body)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		f := schema.File{}
+		Expect(json.Unmarshal(call(req, key), &f)).To(Succeed())
+
+		req = httptest.NewRequest("POST", "/v1/batches", strings.NewReader(`{"input_file_id": "`+f.ID+`", "endpoint": "/v1/chat/completions"}`))
+		req.Header.Set("Content-Type", "application/json")
+		b := schema.Batch{}
+		Expect(json.Unmarshal(call(req, key), &b)).To(Succeed())
+		Eventually(func() string {
+			Expect(json.Unmarshal(call(httptest.NewRequest("GET", "/v1/batches/"+b.ID, nil), key), &b)).To(Succeed())
+			return b.Status
+		}).Should(Equal(schema.BatchCompleted))
+		return b
+	}
+
+	It("accounts the usage of the requests to the caller which created the batch", func() {
+		b := run("alice")
+		Expect(b.RequestCounts.Completed).To(Equal(2))
+
+		entries, err := store.Query(usage.Query{Key: audit.Fingerprint("alice")})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Model).To(Equal("model.bin"))
+		Expect(entries[0].Requests).To(Equal(int64(2)))
+		Expect(entries[0].PromptTokens).To(Equal(int64(6)))
+		Expect(entries[0].CompletionTokens).To(Equal(int64(4)))
+	})
+
+	It("rejects the requests once the quota of the caller which created the batch is exhausted", func() {
+		store.Add(usage.Record{Key: audit.Fingerprint("bob"), Model: "model.bin", PromptTokens: 10})
+
+		b := run("bob")
+		Expect(b.RequestCounts.Failed).To(Equal(2))
+		errors := call(httptest.NewRequest("GET", "/v1/files/"+b.ErrorFileID+"/content", nil), "bob")
+		Expect(string(errors)).To(ContainSubstring(`"status_code":429`))
+	})
+})
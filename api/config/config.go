@@ -1,15 +1,23 @@
 package api_config
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/go-skynet/LocalAI/pkg/downloader"
+	"github.com/go-skynet/LocalAI/pkg/routing"
+	"github.com/go-skynet/LocalAI/pkg/secrets"
+	"github.com/go-skynet/LocalAI/pkg/templates"
 	"github.com/go-skynet/LocalAI/pkg/utils"
 	"github.com/rs/zerolog/log"
 	"gopkg.in/yaml.v3"
@@ -26,10 +34,16 @@ type Config struct {
 	Embeddings     bool              `yaml:"embeddings"`
 	Backend        string            `yaml:"backend"`
 	TemplateConfig TemplateConfig    `yaml:"template"`
+	// Bundle of default templates and stop words: detected from the GGUF metadata of the model when empty,
+	// the name of a bundle to force it, or "none" to disable them
+	TemplateBundle string `yaml:"template_bundle"`
 
 	PromptStrings, InputStrings                []string `yaml:"-"`
 	InputToken                                 [][]int  `yaml:"-"`
 	functionCallString, functionCallNameString string   `yaml:"-"`
+	// Log probabilities of the generated tokens, and number of alternatives, requested by the client
+	Logprobs    bool `yaml:"-"`
+	TopLogprobs int  `yaml:"-"`
 
 	FunctionsConfig Functions `yaml:"function"`
 
@@ -47,23 +61,96 @@ type Config struct {
 	// GRPC Options
 	GRPC GRPC `yaml:"grpc"`
 
+	// Resource limits of the backend process
+	Limits ProcessLimits `yaml:"limits"`
+
+	// Filesystem isolation of the backend process
+	Isolation Isolation `yaml:"isolation"`
+
+	// Warm-up run after loading the model
+	Warmup Warmup `yaml:"warmup"`
+
+	// Environment variables of the backend process
+	Environment map[string]string `yaml:"environment"`
+
+	// GPUs the backend process can use (CUDA_VISIBLE_DEVICES), all of them when empty.
+	// The backend numbers them from 0 in this order, for main_gpu and tensor_split.
+	GPUs []int `yaml:"gpus"`
+
+	// Scheduling of the requests waiting for the model
+	Scheduler Scheduler `yaml:"scheduler"`
+
+	// Routing of the prompts sharing a prefix to the same slot of the backend
+	PrefixCache PrefixCache `yaml:"prefix_cache"`
+
+	// Cache of the responses of the chat and text completions
+	ResponseCache ResponseCache `yaml:"response_cache"`
+
 	// Vall-e-x
 	VallE VallE `yaml:"vall-e"`
 
+	// Models chained by the Realtime API
+	Pipeline Pipeline `yaml:"pipeline"`
+
+	// Diarization of the transcriptions of the model
+	Diarization Diarization `yaml:"diarization"`
+	// VAD removes the silences of the audio before the transcriptions of the model
+	VAD VAD `yaml:"vad"`
+	// Upscale are the settings of the images upscaled by the model
+	Upscale Upscale `yaml:"upscale"`
+
+	// Voices of a TTS model, by name, each synthesized by its own backend
+	Voices map[string]Voice `yaml:"voices"`
+	// SSML is set when the TTS backend of the model understands the SSML documents
+	SSML bool `yaml:"ssml"`
+
+	// Moderation categories of the labels of a classification model
+	Moderation Moderation `yaml:"moderation"`
+
+	// Filters of the prompts and the responses of the model
+	ContentFilters ContentFilters `yaml:"content_filters"`
+
+	// Embedding models whose embeddings are fused
+	Ensemble Ensemble `yaml:"ensemble"`
+
+	// Pooling and normalization of the embeddings of the tokens, done by LocalAI instead of the backend
+	Pooling Pooling `yaml:"pooling"`
+
+	// Temperature scaling of the scores generated by the model, fitted with the calibrate command
+	Calibration Calibration `yaml:"calibration"`
+
 	// CUDA
 	// Explicitly enable CUDA or not (some backends might need it)
 	CUDA bool `yaml:"cuda"`
 
 	DownloadFiles []File `yaml:"download_files"`
+	// Secret is the name of the secret authenticating the downloads of the model and of its files
+	Secret string `yaml:"secret"`
 
 	Description string `yaml:"description"`
 	Usage       string `yaml:"usage"`
+
+	SmokeTests []SmokeTest `yaml:"smoke_tests"`
+}
+
+// SmokeTest is a test case run against the model to check that it is working as expected
+type SmokeTest struct {
+	Name   string `yaml:"name" json:"name"`
+	Prompt string `yaml:"prompt" json:"prompt"`
+	// Expect is a regular expression the response has to match
+	Expect string `yaml:"expect" json:"expect"`
+	// JSON requires the response to be valid JSON
+	JSON bool `yaml:"json" json:"json"`
+	// MaxLatency is the maximum time allowed for the response, in milliseconds
+	MaxLatency int `yaml:"max_latency" json:"max_latency"`
 }
 
 type File struct {
 	Filename string `yaml:"filename" json:"filename"`
 	SHA256   string `yaml:"sha256" json:"sha256"`
 	URI      string `yaml:"uri" json:"uri"`
+	// Secret authenticates the download instead of the secret of the model, if set
+	Secret string `yaml:"secret" json:"secret,omitempty"`
 }
 
 type VallE struct {
@@ -78,8 +165,223 @@ func (ff FeatureFlag) Enabled(s string) bool {
 }
 
 type GRPC struct {
-	Attempts          int `yaml:"attempts"`
-	AttemptsSleepTime int `yaml:"attempts_sleep_time"`
+	Attempts          int     `yaml:"attempts"`
+	AttemptsSleepTime int     `yaml:"attempts_sleep_time"`
+	BackoffMultiplier float64 `yaml:"backoff_multiplier"`
+	MaxSleepTime      int     `yaml:"max_sleep_time"`
+	Jitter            float64 `yaml:"jitter"`
+	Deadline          int     `yaml:"deadline"`
+	KeepaliveTime     int     `yaml:"keepalive_time"`
+	KeepaliveTimeout  int     `yaml:"keepalive_timeout"`
+	CallTimeout       int     `yaml:"call_timeout"`
+}
+
+type Warmup struct {
+	// Number of tokens to generate, warm-up is disabled when 0
+	Tokens int    `yaml:"tokens"`
+	Prompt string `yaml:"prompt"`
+}
+
+type Scheduler struct {
+	// fifo, sjf (shortest job first) or fair (the clients in turn), requests are not queued when empty
+	Policy string `yaml:"policy"`
+	// Number of requests processed at once, the max batch size of the backend.
+	// The llama-cpp backend is started with as many parallel slots, unless LLAMACPP_PARALLEL is set in the environment.
+	Slots int `yaml:"slots"`
+	// Seconds after which a queued request is served before the shorter ones
+	MaxWait int `yaml:"max_wait"`
+	// Maximum number of queued requests, the others are rejected with a 429, no limit when 0
+	MaxQueue int `yaml:"max_queue"`
+	// Priority classes of the requests, set by the X-LocalAI-Priority header, by name.
+	// Defaults to high (1), normal (0) and low (-1), the requests without class are normal.
+	Priorities map[string]int `yaml:"priorities"`
+	// Scaling of the slots with the queue of the model
+	Autoscale Autoscale `yaml:"autoscale"`
+}
+
+// Autoscale grows the slots of the llama-cpp backend while requests are queued, and shrinks them while they are idle.
+// The context of the model is shared by the slots, which limits their number.
+type Autoscale struct {
+	// Bounds of the number of slots, the slots are scaled when max_slots is set
+	MinSlots int `yaml:"min_slots"`
+	MaxSlots int `yaml:"max_slots"`
+	// Minimum number of tokens of the context of each slot, defaults to 1024
+	MinSlotContext int `yaml:"min_slot_context"`
+	// Seconds between the checks of the queue, defaults to 10
+	Interval int `yaml:"interval"`
+}
+
+type PrefixCache struct {
+	Enabled bool `yaml:"enabled"`
+	// Number of parallel slots of the backend, defaults to the slots of the scheduler
+	Slots int `yaml:"slots"`
+	// Size in bytes of the blocks of the prompts compared, defaults to 256
+	BlockSize int `yaml:"block_size"`
+}
+
+type ResponseCache struct {
+	Enabled bool `yaml:"enabled"`
+	// Seconds after which the responses expire, never when 0
+	TTL int `yaml:"ttl"`
+	// Maximum number of cached responses, defaults to 1000
+	MaxEntries int `yaml:"max_entries"`
+	// Returns the responses of the requests with similar prompts, when the embeddings model is set
+	Semantic SemanticCache `yaml:"semantic"`
+}
+
+type SemanticCache struct {
+	// Model computing the embeddings of the prompts
+	Model string `yaml:"model"`
+	// Minimum cosine similarity of the prompts, defaults to 0.95
+	Threshold float64 `yaml:"threshold"`
+}
+
+type ProcessLimits struct {
+	Nice         int    `yaml:"nice"`
+	CPUs         []int  `yaml:"cpus"`
+	MaxMemoryMB  uint64 `yaml:"max_memory_mb"`
+	MaxOpenFiles uint64 `yaml:"max_open_files"`
+}
+
+type Isolation struct {
+	Enabled bool `yaml:"enabled"`
+	// Additional paths the backend can read, or write to, relative to the models path
+	ReadOnly  []string `yaml:"read_only"`
+	ReadWrite []string `yaml:"read_write"`
+	// User the backend runs as, by name or as uid[:gid]
+	User string `yaml:"user"`
+	// Landlock restricts the filesystem of the backend like the isolation, without namespaces
+	Landlock bool `yaml:"landlock"`
+	// Seccomp profile filtering the system calls of the backend, relative to the models path
+	Seccomp string `yaml:"seccomp"`
+}
+
+type Pipeline struct {
+	// Models transcribing the speech, generating the reply, and synthesizing it
+	Transcription string `yaml:"transcription"`
+	LLM           string `yaml:"llm"`
+	TTS           string `yaml:"tts"`
+}
+
+type Diarization struct {
+	// Model finding the speakers of the audio, whose labels are set on the segments of the transcriptions
+	Model string `yaml:"model"`
+	// Default diarizes all the transcriptions, not only the requests with diarize
+	Default bool `yaml:"default"`
+}
+
+type VAD struct {
+	// Model finding the speech of the audio, whose silences are removed before the transcriptions
+	Model string `yaml:"model"`
+	// Default filters all the transcriptions, not only the requests with vad
+	Default bool `yaml:"default"`
+	// Threshold is the probability above which a frame is speech, 0 for the default of the model
+	Threshold float32 `yaml:"threshold"`
+	// MinSilenceMs is the shortest silence removed, and MinSpeechMs the shortest speech kept
+	MinSilenceMs int `yaml:"min_silence_ms"`
+	MinSpeechMs  int `yaml:"min_speech_ms"`
+	// PaddingMs is the audio kept around the speech, 200 by default
+	PaddingMs int `yaml:"padding_ms"`
+}
+
+type Upscale struct {
+	// Scale is the scale factor of the images, 0 for the one of the model
+	Scale float32 `yaml:"scale"`
+	// Tile is the size of the tiles the images are upscaled by, to bound the memory, 0 to upscale them at once
+	Tile int `yaml:"tile"`
+}
+
+// Voice is a voice of a TTS model. The backend and the model default to the ones of the TTS model.
+type Voice struct {
+	Backend string `yaml:"backend"`
+	Model   string `yaml:"model"`
+	// Speaker is the reference audio of the speaker (XTTS), relative to the models path, or the name of a speaker of
+	// the model (XTTS, Bark)
+	Speaker string `yaml:"speaker"`
+	// Language of the speech of the multilingual models
+	Language string `yaml:"language"`
+	// SSML is set when the backend understands the SSML documents, which LocalAI renders for the others
+	SSML bool `yaml:"ssml"`
+}
+
+// Voice returns the voice of the TTS model by name, the one of the model when empty. When the model has no voices,
+// the name is the model file of the backend of the model, e.g. a voice of piper.
+func (c Config) Voice(name string) (Voice, error) {
+	voice := Voice{Backend: c.Backend, Model: c.Model, SSML: c.SSML}
+	if name == "" {
+		return voice, nil
+	}
+	if len(c.Voices) == 0 {
+		voice.Model = name
+		return voice, nil
+	}
+	v, ok := c.Voices[name]
+	if !ok {
+		return voice, fmt.Errorf("the model %s has no voice %q", c.Name, name)
+	}
+	if v.Backend == "" {
+		v.Backend = voice.Backend
+		v.SSML = v.SSML || voice.SSML
+	}
+	if v.Model == "" && v.Backend == voice.Backend {
+		v.Model = voice.Model
+	}
+	return v, nil
+}
+
+type Moderation struct {
+	// Moderation categories (e.g. harassment) of the labels of the model (e.g. insult),
+	// the labels which are already moderation categories don't need to be mapped
+	Categories map[string][]string `yaml:"categories"`
+	// Score from which a category is flagged, 0.5 by default
+	Threshold float64 `yaml:"threshold"`
+}
+
+// ContentFilters are the names of the content filters enabled for the model, run in order
+type ContentFilters struct {
+	// Filters of the prompts, before the inference
+	Prompt []string `yaml:"prompt" json:"prompt"`
+	// Filters of the responses, before they are returned. The streamed responses are sent at once, once filtered.
+	Response []string `yaml:"response" json:"response"`
+}
+
+type Ensemble struct {
+	Models []EnsembleModel `yaml:"models" json:"models"`
+	// concat (by default), weighted or none
+	Fusion string `yaml:"fusion" json:"fusion"`
+}
+
+type EnsembleModel struct {
+	Model string `yaml:"model" json:"model"`
+	// 1 by default
+	Weight float64 `yaml:"weight" json:"weight"`
+}
+
+type Pooling struct {
+	// mean, cls, last or max. When set, the backend returns the embeddings of the tokens, which LocalAI pools
+	Method string `yaml:"method" json:"method"`
+	// Normalize the embeddings to a unit length
+	Normalize bool `yaml:"normalize" json:"normalize"`
+}
+
+type Calibration struct {
+	// Temperature dividing the logits of the scores, the calibration is disabled when it is not set
+	Temperature float64 `yaml:"temperature" json:"temperature"`
+	// Bias added to the scaled logits
+	Bias float64 `yaml:"bias" json:"bias"`
+	// Highest score generated by the model (e.g. 10 for the scores from 0 to 10), 1 by default
+	Scale float64 `yaml:"scale,omitempty" json:"scale,omitempty"`
+}
+
+func (c Calibration) Enabled() bool {
+	return c.Temperature > 0
+}
+
+// DiffusersSchedulers are the names of the schedulers of the diffusers backend, the ones prefixed with k_ use the
+// Karras sigmas
+var DiffusersSchedulers = []string{
+	"ddim", "pndm", "heun", "unipc", "euler", "euler_a", "lms", "k_lms", "dpm_2", "k_dpm_2", "dpm_2_a", "k_dpm_2_a",
+	"dpmpp_2m", "k_dpmpp_2m", "dpmpp_sde", "k_dpmpp_sde", "dpmpp_2m_sde", "k_dpmpp_2m_sde",
 }
 
 type Diffusers struct {
@@ -93,6 +395,81 @@ type Diffusers struct {
 	ClipModel        string  `yaml:"clip_model"`        // Clip model to use
 	ClipSubFolder    string  `yaml:"clip_subfolder"`    // Subfolder to use for clip model
 	ControlNet       string  `yaml:"control_net"`
+	// ControlNets are the ControlNet models conditioning the generations, by control type (e.g. canny, depth, pose)
+	ControlNets map[string]string `yaml:"control_nets"`
+	// Loras are the LoRA adapters of the model, by name, which the generations select
+	Loras map[string]Lora `yaml:"loras"`
+	// BatchSize is the number of images generated at once, 4 by default
+	BatchSize int `yaml:"batch_size"`
+}
+
+// Lora is a LoRA adapter of a diffusion model
+type Lora struct {
+	// File is the weights of the LoRA, relative to the models path
+	File string `yaml:"file"`
+	// Weight is the scale of the LoRA, 1 by default
+	Weight float32 `yaml:"weight"`
+	// Default LoRAs are applied to the generations which don't select their LoRAs
+	Default bool `yaml:"default"`
+}
+
+// promptLora matches the LoRAs selected in the prompts, <lora:name> or <lora:name:weight>
+var promptLora = regexp.MustCompile(`<lora:([^:>]+)(?::([^>]*))?>`)
+
+// PromptLoras removes the LoRAs selected in a prompt, and returns their weights by name, 0 when not given
+func PromptLoras(prompt string) (string, map[string]float32, error) {
+	loras := map[string]float32{}
+	var err error
+	prompt = promptLora.ReplaceAllStringFunc(prompt, func(m string) string {
+		sub := promptLora.FindStringSubmatch(m)
+		var weight float64
+		if sub[2] != "" {
+			w, perr := strconv.ParseFloat(strings.TrimSpace(sub[2]), 32)
+			if perr != nil && err == nil {
+				err = fmt.Errorf("invalid weight of the LoRA %s: %s", sub[1], sub[2])
+			}
+			weight = w
+		}
+		loras[strings.TrimSpace(sub[1])] = float32(weight)
+		return ""
+	})
+	return strings.Join(strings.Fields(prompt), " "), loras, err
+}
+
+// ImageLoras returns the weights of the LoRAs of a generation by name: the selected ones, whose weight is the one of
+// the model when 0, or the default ones of the model when none is selected
+func (c Config) ImageLoras(selected map[string]float32) (map[string]float32, error) {
+	weight := func(l Lora) float32 {
+		if l.Weight == 0 {
+			return 1
+		}
+		return l.Weight
+	}
+	loras := map[string]float32{}
+	if len(selected) == 0 {
+		for name, l := range c.Diffusers.Loras {
+			if l.Default {
+				loras[name] = weight(l)
+			}
+		}
+		return loras, nil
+	}
+	for name, w := range selected {
+		l, ok := c.Diffusers.Loras[name]
+		if !ok {
+			names := make([]string, 0, len(c.Diffusers.Loras))
+			for n := range c.Diffusers.Loras {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("the model %s has no LoRA %q, it has the ones of %v", c.Name, name, names)
+		}
+		if w == 0 {
+			w = weight(l)
+		}
+		loras[name] = w
+	}
+	return loras, nil
 }
 
 type LLMConfig struct {
@@ -107,9 +484,9 @@ type LLMConfig struct {
 	MirostatETA     float64  `yaml:"mirostat_eta"`
 	MirostatTAU     float64  `yaml:"mirostat_tau"`
 	Mirostat        int      `yaml:"mirostat"`
-	NGPULayers      int      `yaml:"gpu_layers"`
-	MMap            bool     `yaml:"mmap"`
-	MMlock          bool     `yaml:"mmlock"`
+	NGPULayers      *int     `yaml:"gpu_layers"`
+	MMap            *bool    `yaml:"mmap"`   // Memory maps the model file, defaults to true
+	MMlock          *bool    `yaml:"mmlock"` // Locks the model in RAM
 	LowVRAM         bool     `yaml:"low_vram"`
 	Grammar         string   `yaml:"grammar"`
 	StopWords       []string `yaml:"stopwords"`
@@ -117,16 +494,18 @@ type LLMConfig struct {
 	TrimSpace       []string `yaml:"trimspace"`
 	TrimSuffix      []string `yaml:"trimsuffix"`
 
-	ContextSize  int     `yaml:"context_size"`
-	NUMA         bool    `yaml:"numa"`
-	LoraAdapter  string  `yaml:"lora_adapter"`
-	LoraBase     string  `yaml:"lora_base"`
-	LoraScale    float32 `yaml:"lora_scale"`
-	NoMulMatQ    bool    `yaml:"no_mulmatq"`
-	DraftModel   string  `yaml:"draft_model"`
-	NDraft       int32   `yaml:"n_draft"`
-	Quantization string  `yaml:"quantization"`
-	MMProj       string  `yaml:"mmproj"`
+	ContextSize  int        `yaml:"context_size"`
+	NUMA         NUMAPolicy `yaml:"numa"`
+	LoraAdapter  string     `yaml:"lora_adapter"`
+	LoraBase     string     `yaml:"lora_base"`
+	LoraScale    float32    `yaml:"lora_scale"`
+	NoMulMatQ    bool       `yaml:"no_mulmatq"`
+	DraftModel   string     `yaml:"draft_model"`
+	NDraft       int32      `yaml:"n_draft"`
+	Quantization string     `yaml:"quantization"`
+	MMProj       string     `yaml:"mmproj"`
+	// The images of the chat messages whose largest side is larger are downscaled to it, 1344 pixels by default
+	MaxImageSize int `yaml:"max_image_size"`
 
 	RopeScaling string `yaml:"rope_scaling"`
 	ModelType   string `yaml:"type"`
@@ -160,6 +539,8 @@ type TemplateConfig struct {
 
 type ConfigLoader struct {
 	configs map[string]Config
+	routes  *routing.Table
+	secrets *secrets.Store
 	sync.Mutex
 }
 
@@ -183,8 +564,87 @@ func (c *Config) FunctionToCall() string {
 	return c.functionCallNameString
 }
 
+// CheckGPUs returns an error if the main GPU or the tensor split of the model refer to GPUs it cannot use
+func (c *Config) CheckGPUs() error {
+	seen := map[int]bool{}
+	for _, id := range c.GPUs {
+		if id < 0 || seen[id] {
+			return fmt.Errorf("invalid GPU %d in gpus", id)
+		}
+		seen[id] = true
+	}
+
+	if c.MainGPU != "" {
+		main, err := strconv.Atoi(c.MainGPU)
+		if err != nil || main < 0 {
+			return fmt.Errorf("invalid main_gpu %q", c.MainGPU)
+		}
+		if len(c.GPUs) > 0 && main >= len(c.GPUs) {
+			return fmt.Errorf("main_gpu %d is not one of the %d gpus of the model, which are numbered from 0", main, len(c.GPUs))
+		}
+	}
+
+	if c.TensorSplit != "" {
+		split := strings.FieldsFunc(c.TensorSplit, func(r rune) bool { return r == ',' || r == '/' })
+		for _, s := range split {
+			if v, err := strconv.ParseFloat(strings.TrimSpace(s), 32); err != nil || v < 0 {
+				return fmt.Errorf("invalid tensor_split %q", c.TensorSplit)
+			}
+		}
+		if len(c.GPUs) > 0 && len(split) > len(c.GPUs) {
+			return fmt.Errorf("tensor_split %q has more parts than the %d gpus of the model", c.TensorSplit, len(c.GPUs))
+		}
+	}
+	return nil
+}
+
+// NUMA policies of the models
+const (
+	// NUMADistribute spreads the threads of llama.cpp over the nodes
+	NUMADistribute = "distribute"
+	// NUMAInterleave interleaves the memory of the backend over all the nodes
+	NUMAInterleave = "interleave"
+	// NUMANodePrefix binds the backend, and its memory, to a node (e.g. node:1)
+	NUMANodePrefix = "node:"
+)
+
+// NUMAPolicy is the NUMA policy of the backend of a model, true is the distribute policy
+type NUMAPolicy string
+
+func (p *NUMAPolicy) UnmarshalYAML(value *yaml.Node) error {
+	var enabled bool
+	if err := value.Decode(&enabled); err == nil {
+		*p = ""
+		if enabled {
+			*p = NUMADistribute
+		}
+		return nil
+	}
+	var policy string
+	if err := value.Decode(&policy); err != nil {
+		return err
+	}
+	switch {
+	case policy == "", policy == NUMADistribute, policy == NUMAInterleave:
+	case strings.HasPrefix(policy, NUMANodePrefix):
+		if node, err := strconv.Atoi(strings.TrimPrefix(policy, NUMANodePrefix)); err != nil || node < 0 {
+			return fmt.Errorf("invalid NUMA node in %q", policy)
+		}
+	default:
+		return fmt.Errorf("unknown NUMA policy %q", policy)
+	}
+	*p = NUMAPolicy(policy)
+	return nil
+}
+
 // Load a config file for a model
 func Load(modelName, modelPath string, cm *ConfigLoader, debug bool, threads, ctx int, f16 bool) (*Config, error) {
+	rule, routed := cm.route(modelName, modelPath)
+	if routed {
+		log.Debug().Msgf("Routing the requests of %s to %s", modelName, rule.Model)
+		modelName = rule.Model
+	}
+
 	// Load a config file if present after the model name
 	modelConfig := filepath.Join(modelPath, modelName+".yaml")
 
@@ -234,9 +694,108 @@ func Load(modelName, modelPath string, cm *ConfigLoader, debug bool, threads, ct
 		cfg.Debug = true
 	}
 
+	if routed && len(rule.Parameters) > 0 {
+		// only the parameters set by the rule are replaced
+		dat, err := yaml.Marshal(rule.Parameters)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(dat, &cfg.PredictionOptions); err != nil {
+			return nil, fmt.Errorf("invalid parameters of the route to %s: %w", rule.Model, err)
+		}
+	}
+
+	cfg.applyTemplateBundle(modelPath)
+
 	return cfg, nil
 }
 
+// route returns the routing rule of the model name. The names of the configured models,
+// and of the files of the models path, are never routed.
+func (cm *ConfigLoader) route(modelName, modelPath string) (routing.Rule, bool) {
+	cm.Lock()
+	routes := cm.routes
+	_, configured := cm.configs[modelName]
+	cm.Unlock()
+	if routes == nil || configured || modelName == "" {
+		return routing.Rule{}, false
+	}
+	for _, f := range []string{modelName, modelName + ".yaml"} {
+		if _, err := os.Stat(filepath.Join(modelPath, f)); err == nil {
+			return routing.Rule{}, false
+		}
+	}
+	return routes.Resolve(modelName)
+}
+
+// SetRoutes sets the rules routing the requested model names to the configured models
+func (cm *ConfigLoader) SetRoutes(routes *routing.Table) {
+	cm.Lock()
+	defer cm.Unlock()
+	cm.routes = routes
+}
+
+// Routes returns the rules routing the requested model names, nil without rules
+func (cm *ConfigLoader) Routes() *routing.Table {
+	cm.Lock()
+	defer cm.Unlock()
+	return cm.routes
+}
+
+// applyTemplateBundle completes the configuration with the bundle of templates and stop words of the model family,
+// when the model has no templates: the defaults assume English conventions, which garble the outputs of other models
+func (c *Config) applyTemplateBundle(modelPath string) {
+	if c.TemplateBundle == "none" || c.Model == "" {
+		return
+	}
+
+	var bundle *templates.Bundle
+	var ok bool
+	if c.TemplateBundle != "" {
+		if bundle, ok = templates.Get(c.TemplateBundle); !ok {
+			log.Warn().Msgf("Model %s: unknown template bundle %q", c.Name, c.TemplateBundle)
+			return
+		}
+	} else {
+		t := c.TemplateConfig
+		if t.Chat != "" || t.ChatMessage != "" || t.Completion != "" || t.Functions != "" {
+			return
+		}
+		// a template file next to the model takes precedence
+		if _, err := os.Stat(filepath.Join(modelPath, c.Model+".tmpl")); err == nil {
+			return
+		}
+		if bundle, ok = templates.DetectFile(filepath.Join(modelPath, c.Model)); !ok {
+			return
+		}
+		log.Debug().Msgf("Model %s: using the template bundle %s", c.Name, bundle.Name)
+	}
+
+	if c.TemplateConfig.Chat == "" {
+		c.TemplateConfig.Chat = bundle.Template.Chat
+	}
+	if c.TemplateConfig.ChatMessage == "" {
+		c.TemplateConfig.ChatMessage = bundle.Template.ChatMessage
+	}
+	if c.TemplateConfig.Completion == "" {
+		c.TemplateConfig.Completion = bundle.Template.Completion
+	}
+	if c.TemplateConfig.Functions == "" {
+		c.TemplateConfig.Functions = bundle.Template.Functions
+	}
+	if c.SystemPrompt == "" {
+		c.SystemPrompt = bundle.SystemPrompt
+	}
+	// the stop words are shared with the loaded configuration, they are copied before being completed
+	stopWords := slices.Clone(c.StopWords)
+	for _, w := range bundle.StopWords {
+		if !slices.Contains(stopWords, w) {
+			stopWords = append(stopWords, w)
+		}
+	}
+	c.StopWords = stopWords
+}
+
 func defaultPredictOptions(modelFile string) PredictionOptions {
 	return PredictionOptions{
 		TopP:        0.7,
@@ -267,6 +826,11 @@ func ReadConfigFile(file string) ([]*Config, error) {
 	if err := yaml.Unmarshal(f, c); err != nil {
 		return nil, fmt.Errorf("cannot unmarshal config file: %w", err)
 	}
+	if tenant, ok := tenantOfFile(file); ok {
+		for _, cc := range *c {
+			cc.namespace(tenant, filepath.Dir(file))
+		}
+	}
 
 	return *c, nil
 }
@@ -280,10 +844,70 @@ func ReadConfig(file string) (*Config, error) {
 	if err := yaml.Unmarshal(f, c); err != nil {
 		return nil, fmt.Errorf("cannot unmarshal config file: %w", err)
 	}
+	if tenant, ok := tenantOfFile(file); ok {
+		c.namespace(tenant, filepath.Dir(file))
+	}
 
 	return c, nil
 }
 
+// UpdateConfigFile sets the value of a top level key of a config file, keeping the rest of the file as it is
+func UpdateConfigFile(file, key string, value interface{}) error {
+	f, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("cannot read config file: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(f, &doc); err != nil {
+		return fmt.Errorf("cannot unmarshal config file: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("config file %s does not contain a single model", file)
+	}
+
+	var v yaml.Node
+	if err := v.Encode(value); err != nil {
+		return err
+	}
+	root := doc.Content[0]
+	found := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == key {
+			root.Content[i+1] = &v
+			found = true
+		}
+	}
+	if !found {
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &v)
+	}
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	return os.WriteFile(file, out.Bytes(), 0644)
+}
+
+// FindConfigFile returns the config file of a model in the models path
+func FindConfigFile(path, name string) (string, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		if !strings.Contains(entry.Name(), ".yaml") && !strings.Contains(entry.Name(), ".yml") {
+			continue
+		}
+		c, err := ReadConfig(filepath.Join(path, entry.Name()))
+		if err == nil && c.Name == name {
+			return filepath.Join(path, entry.Name()), nil
+		}
+	}
+	return "", fmt.Errorf("no config file found for model %s", name)
+}
+
 func (cm *ConfigLoader) LoadConfigFile(file string) error {
 	cm.Lock()
 	defer cm.Unlock()
@@ -317,6 +941,13 @@ func (cm *ConfigLoader) GetConfig(m string) (Config, bool) {
 	return v, exists
 }
 
+// RemoveConfig forgets the config of the model with the given name
+func (cm *ConfigLoader) RemoveConfig(m string) {
+	cm.Lock()
+	defer cm.Unlock()
+	delete(cm.configs, m)
+}
+
 func (cm *ConfigLoader) GetAllConfigs() []Config {
 	cm.Lock()
 	defer cm.Unlock()
@@ -337,6 +968,25 @@ func (cm *ConfigLoader) ListConfigs() []string {
 	return res
 }
 
+// SetSecrets sets the secrets the configs reference by name, to authenticate the downloads of their models
+func (cm *ConfigLoader) SetSecrets(s *secrets.Store) {
+	cm.Lock()
+	defer cm.Unlock()
+	cm.secrets = s
+}
+
+// secret returns the secret of the name to download the URL, nil without name
+func (cm *ConfigLoader) secret(name, uri string) (*secrets.Secret, error) {
+	if name == "" {
+		return nil, nil
+	}
+	s, err := cm.secrets.ForURL(name, downloader.ConvertURL(uri))
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
 // Preload prepare models if they are not local but url or huggingface repositories
 func (cm *ConfigLoader) Preload(modelPath string) error {
 	cm.Lock()
@@ -360,7 +1010,15 @@ func (cm *ConfigLoader) Preload(modelPath string) error {
 			// Create file path
 			filePath := filepath.Join(modelPath, file.Filename)
 
-			if err := downloader.DownloadFile(file.URI, filePath, file.SHA256, status); err != nil {
+			name := file.Secret
+			if name == "" {
+				name = config.Secret
+			}
+			secret, err := cm.secret(name, file.URI)
+			if err != nil {
+				return fmt.Errorf("model %s: %w", config.Name, err)
+			}
+			if err := downloader.DownloadFileWithSecret(file.URI, filePath, file.SHA256, secret, status); err != nil {
 				return err
 			}
 		}
@@ -374,7 +1032,11 @@ func (cm *ConfigLoader) Preload(modelPath string) error {
 
 			// check if file exists
 			if _, err := os.Stat(filepath.Join(modelPath, md5Name)); errors.Is(err, os.ErrNotExist) {
-				err := downloader.DownloadFile(modelURL, filepath.Join(modelPath, md5Name), "", status)
+				secret, err := cm.secret(config.Secret, modelURL)
+				if err != nil {
+					return fmt.Errorf("model %s: %w", config.Name, err)
+				}
+				err = downloader.DownloadFileWithSecret(modelURL, filepath.Join(modelPath, md5Name), "", secret, status)
 				if err != nil {
 					return err
 				}
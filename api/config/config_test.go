@@ -2,12 +2,14 @@ package api_config_test
 
 import (
 	"os"
+	"path/filepath"
 
 	. "github.com/go-skynet/LocalAI/api/config"
 	"github.com/go-skynet/LocalAI/api/options"
 	"github.com/go-skynet/LocalAI/pkg/model"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
 )
 
 var _ = Describe("Test cases for config related functions", func() {
@@ -53,4 +55,173 @@ var _ = Describe("Test cases for config related functions", func() {
 			Expect(cm.ListConfigs()).To(ContainElements("whisper-1"))
 		})
 	})
+
+	Context("Test the GPUs of the models", func() {
+		It("accepts the main GPU and the tensor split among the GPUs of the model", func() {
+			c := Config{GPUs: []int{2, 3}, LLMConfig: LLMConfig{MainGPU: "1", TensorSplit: "3,1"}}
+			Expect(c.CheckGPUs()).To(Succeed())
+
+			c = Config{LLMConfig: LLMConfig{MainGPU: "4", TensorSplit: "1/1/2"}}
+			Expect(c.CheckGPUs()).To(Succeed())
+		})
+
+		It("rejects the GPUs the model cannot use", func() {
+			c := Config{GPUs: []int{2}, LLMConfig: LLMConfig{MainGPU: "2"}}
+			Expect(c.CheckGPUs()).To(MatchError(ContainSubstring("main_gpu")))
+
+			c = Config{GPUs: []int{0, 1}, LLMConfig: LLMConfig{TensorSplit: "1,1,1"}}
+			Expect(c.CheckGPUs()).To(MatchError(ContainSubstring("tensor_split")))
+
+			c = Config{GPUs: []int{1, 1}}
+			Expect(c.CheckGPUs()).ToNot(Succeed())
+		})
+	})
+
+	Context("Test the NUMA policy of the models", func() {
+		It("reads the policies, and the booleans", func() {
+			for in, policy := range map[string]NUMAPolicy{
+				"numa: true":       NUMADistribute,
+				"numa: false":      "",
+				"numa: interleave": NUMAInterleave,
+				"numa: node:1":     "node:1",
+			} {
+				c := Config{}
+				Expect(yaml.Unmarshal([]byte(in), &c)).To(Succeed())
+				Expect(c.NUMA).To(Equal(policy))
+			}
+		})
+
+		It("rejects the unknown policies", func() {
+			c := Config{}
+			Expect(yaml.Unmarshal([]byte("numa: spread"), &c)).ToNot(Succeed())
+			Expect(yaml.Unmarshal([]byte("numa: node:first"), &c)).ToNot(Succeed())
+		})
+	})
+
+	Context("Test the voices of the TTS models", func() {
+		c := Config{}
+		BeforeEach(func() {
+			c = Config{}
+			Expect(yaml.Unmarshal([]byte(`name: tts
+backend: piper
+parameters:
+  model: en-us-amy-low.onnx
+voices:
+  amy: {}
+  narrator:
+    backend: coqui
+    model: tts_models/multilingual/multi-dataset/xtts_v2
+    speaker: voices/narrator.wav
+    language: en
+  announcer:
+    backend: bark
+    speaker: v2/en_speaker_6
+`), &c)).To(Succeed())
+		})
+
+		It("selects the backend of each voice", func() {
+			v, err := c.Voice("")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(v).To(Equal(Voice{Backend: "piper", Model: "en-us-amy-low.onnx"}))
+			v, err = c.Voice("amy")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(v).To(Equal(Voice{Backend: "piper", Model: "en-us-amy-low.onnx"}))
+			v, err = c.Voice("narrator")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(v).To(Equal(Voice{Backend: "coqui", Model: "tts_models/multilingual/multi-dataset/xtts_v2", Speaker: "voices/narrator.wav", Language: "en"}))
+			// the model of the TTS model is not the one of another backend
+			v, err = c.Voice("announcer")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(v).To(Equal(Voice{Backend: "bark", Speaker: "v2/en_speaker_6"}))
+			_, err = c.Voice("unknown")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("uses the voice as model file without voices", func() {
+			c.Voices = nil
+			v, err := c.Voice("en-us-ryan-high.onnx")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(v).To(Equal(Voice{Backend: "piper", Model: "en-us-ryan-high.onnx"}))
+		})
+	})
+
+	Context("Test the LoRAs of the diffusion models", func() {
+		var c Config
+		BeforeEach(func() {
+			c = Config{}
+			Expect(yaml.Unmarshal([]byte(`
+name: sdxl
+diffusers:
+  loras:
+    pixel:
+      file: loras/pixel.safetensors
+      weight: 0.8
+      default: true
+    ink:
+      file: loras/ink.safetensors
+`), &c)).To(Succeed())
+		})
+
+		It("removes the LoRAs selected in the prompts", func() {
+			prompt, loras, err := PromptLoras("an otter <lora:ink:0.5>, swimming <lora:pixel>")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(prompt).To(Equal("an otter , swimming"))
+			Expect(loras).To(Equal(map[string]float32{"ink": 0.5, "pixel": 0}))
+			_, _, err = PromptLoras("an otter <lora:ink:strong>")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("applies the selected LoRAs, or the default ones", func() {
+			loras, err := c.ImageLoras(nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loras).To(Equal(map[string]float32{"pixel": 0.8}))
+			loras, err = c.ImageLoras(map[string]float32{"ink": 0, "pixel": 1.5})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loras).To(Equal(map[string]float32{"ink": 1, "pixel": 1.5}))
+			_, err = c.ImageLoras(map[string]float32{"unknown": 1})
+			Expect(err).To(MatchError(ContainSubstring("[ink pixel]")))
+		})
+	})
+
+	Context("Test the tenant namespaces", func() {
+		It("loads the configs of the tenants in their namespaces", func() {
+			dir, err := os.MkdirTemp("", "tenants")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+			tenant := filepath.Join(dir, TenantsDir, "team-a")
+			Expect(os.MkdirAll(tenant, 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(tenant, "tuned.gguf"), []byte{}, 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(tenant, "chat.tmpl"), []byte("{{.Input}}"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(tenant, "assistant.yaml"), []byte("name: assistant\nparameters:\n  model: tuned.gguf\ntemplate:\n  chat: chat\n"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(tenant, "base.yaml"), []byte("name: base\nparameters:\n  model: mistral.gguf\n"), 0644)).To(Succeed())
+
+			cm := NewConfigLoader()
+			Expect(cm.LoadTenantConfigs(dir)).To(Succeed())
+			Expect(cm.ListConfigs()).To(ConsistOf("tenants/team-a/assistant", "tenants/team-a/base"))
+
+			c, _ := cm.GetConfig("tenants/team-a/assistant")
+			Expect(c.Model).To(Equal("tenants/team-a/tuned.gguf"))
+			Expect(c.TemplateConfig.Chat).To(Equal("tenants/team-a/chat"))
+			// the files of the models path are shared
+			c, _ = cm.GetConfig("tenants/team-a/base")
+			Expect(c.Model).To(Equal("mistral.gguf"))
+		})
+
+		It("keeps the names in the namespaces", func() {
+			m, ok := TenantModel("team-a", "assistant")
+			Expect(ok).To(BeTrue())
+			Expect(m).To(Equal("tenants/team-a/assistant"))
+			_, ok = TenantModel("team-a", "../team-b/assistant")
+			Expect(ok).To(BeFalse())
+			_, ok = TenantModel("../team-b", "assistant")
+			Expect(ok).To(BeFalse())
+
+			tenant, name, ok := SplitTenant("tenants/team-a/assistant")
+			Expect(ok).To(BeTrue())
+			Expect(tenant).To(Equal("team-a"))
+			Expect(name).To(Equal("assistant"))
+			_, _, ok = SplitTenant("assistant")
+			Expect(ok).To(BeFalse())
+		})
+	})
 })
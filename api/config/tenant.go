@@ -0,0 +1,116 @@
+package api_config
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-skynet/LocalAI/pkg/downloader"
+)
+
+// TenantsDir is the directory of the models path holding the models and the configs of each tenant, in a directory
+// named after the tenant
+const TenantsDir = "tenants"
+
+// TenantPrefix returns the prefix of the names of the models of the tenant, their path relative to the models path
+func TenantPrefix(tenant string) string {
+	return TenantsDir + "/" + tenant + "/"
+}
+
+// ValidTenant returns true if the tenant can name a directory of the models path
+func ValidTenant(tenant string) bool {
+	return tenant != "" && tenant != "." && tenant != ".." && !strings.ContainsAny(tenant, `/\`)
+}
+
+// TenantModel returns the name of a model in the namespace of the tenant. The names leaving the namespace (e.g. with
+// "..") are refused.
+func TenantModel(tenant, name string) (string, bool) {
+	prefix := TenantPrefix(tenant)
+	if !ValidTenant(tenant) || name == "" {
+		return "", false
+	}
+	n := path.Clean(prefix + name)
+	return n, strings.HasPrefix(n, prefix)
+}
+
+// SplitTenant returns the tenant of a model name of a tenant namespace, and its name in the namespace
+func SplitTenant(name string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(name, TenantsDir+"/")
+	if !ok {
+		return "", "", false
+	}
+	return strings.Cut(rest, "/")
+}
+
+// tenantOfFile returns the tenant of a config file of the directory of a tenant
+func tenantOfFile(file string) (string, bool) {
+	dir := filepath.Dir(file)
+	if filepath.Base(filepath.Dir(dir)) != TenantsDir {
+		return "", false
+	}
+	tenant := filepath.Base(dir)
+	return tenant, ValidTenant(tenant)
+}
+
+// namespace moves the config of a file of the directory of a tenant in its namespace: its name is prefixed, as well as
+// the files it references which are in the directory. The other files are the ones of the models path, which the
+// backends serve to all the tenants.
+func (c *Config) namespace(tenant, dir string) {
+	prefix := TenantPrefix(tenant)
+	c.Name = prefix + c.Name
+
+	inDir := func(f string) bool {
+		if f == "" || filepath.IsAbs(f) || downloader.LooksLikeURL(downloader.ConvertURL(f)) {
+			return false
+		}
+		_, err := os.Stat(filepath.Join(dir, f))
+		return err == nil
+	}
+	for _, f := range []*string{&c.Model, &c.MMProj, &c.LoraAdapter, &c.LoraBase, &c.DraftModel} {
+		if inDir(*f) {
+			*f = prefix + *f
+		}
+	}
+	for name, v := range c.Voices {
+		for _, f := range []*string{&v.Model, &v.Speaker} {
+			if inDir(*f) {
+				*f = prefix + *f
+			}
+		}
+		c.Voices[name] = v
+	}
+	for name, l := range c.Diffusers.Loras {
+		if inDir(l.File) {
+			l.File = prefix + l.File
+		}
+		c.Diffusers.Loras[name] = l
+	}
+	t := &c.TemplateConfig
+	for _, f := range []*string{&t.Chat, &t.ChatMessage, &t.Completion, &t.Edit, &t.Functions} {
+		if *f != "" && inDir(*f+".tmpl") {
+			*f = prefix + *f
+		}
+	}
+}
+
+// LoadTenantConfigs loads the configs of the directories of the tenants of the models path, in their namespaces
+func (cm *ConfigLoader) LoadTenantConfigs(modelPath string) error {
+	entries, err := os.ReadDir(filepath.Join(modelPath, TenantsDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if !e.IsDir() || !ValidTenant(e.Name()) {
+			continue
+		}
+		if err := cm.LoadConfigs(filepath.Join(modelPath, TenantsDir, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package fiberContext
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FileETag returns a strong ETag of a file, derived from its size and modification time
+func FileETag(info os.FileInfo) string {
+	return fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size())
+}
+
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ConditionalFile sets the ETag of the file at path, and handles the If-None-Match and If-Range headers
+// of the request before the file is sent with Range support (e.g. by SendFile).
+// It returns true if the client has the file already, in which case the response is 304 Not Modified.
+func ConditionalFile(c *fiber.Ctx, path string) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false, nil
+	}
+	etag := FileETag(info)
+	c.Set(fiber.HeaderETag, etag)
+
+	if match := c.Get(fiber.HeaderIfNoneMatch); match != "" {
+		if etagMatches(match, etag) {
+			return true, c.SendStatus(fiber.StatusNotModified)
+		}
+		// If-Modified-Since must be ignored when If-None-Match is set
+		c.Request().Header.Del(fiber.HeaderIfModifiedSince)
+	}
+
+	// a partial content is only sent if the file did not change since the client got it, otherwise the whole file is sent
+	if ifRange := c.Get(fiber.HeaderIfRange); ifRange != "" && ifRange != etag {
+		date, err := http.ParseTime(ifRange)
+		if err != nil || info.ModTime().Truncate(time.Second).After(date) {
+			c.Request().Header.Del(fiber.HeaderRange)
+		}
+	}
+	return false, nil
+}
+
+// ConditionalStatic is a middleware for the files served with Static from root under prefix,
+// adding their ETag and handling the conditional requests
+func ConditionalStatic(prefix, root string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return c.Next()
+		}
+		// the path is cleaned as an absolute path so it can't escape root
+		rel := filepath.Clean("/" + strings.TrimPrefix(c.Path(), prefix))
+		if notModified, err := ConditionalFile(c, filepath.Join(root, rel)); notModified || err != nil {
+			return err
+		}
+		return c.Next()
+	}
+}
@@ -2,22 +2,56 @@ package fiberContext
 
 import (
 	"fmt"
+	"path"
+	"sort"
 	"strings"
 
+	config "github.com/go-skynet/LocalAI/api/config"
 	"github.com/go-skynet/LocalAI/pkg/model"
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog/log"
 )
 
+// the key of the model of the request in the locals of the fiber context
+const modelLocal = "model"
+
+// the key of the model replacing the one requested, in the locals of the fiber context
+const modelOverrideLocal = "model_override"
+
+// the key of the models namespace of the request, in the locals of the fiber context
+const namespaceLocal = "namespace"
+
+// namespace restricts the models of a request to the ones of its tenant, and to the shared ones if allowed
+type namespace struct {
+	tenant string
+	shared bool
+}
+
 // ModelFromContext returns the model from the context
 // If no model is specified, it will take the first available
 // Takes a model string as input which should be the one received from the user request.
 // It returns the model name resolved from the context and an error if any.
-func ModelFromContext(ctx *fiber.Ctx, loader *model.ModelLoader, modelInput string, firstModel bool) (string, error) {
+// With the tenant namespaces, the name is resolved in the namespace of the tenant of the request.
+func ModelFromContext(ctx *fiber.Ctx, cm *config.ConfigLoader, loader *model.ModelLoader, modelInput string, firstModel bool) (string, error) {
+	if m, ok := ctx.Locals(modelOverrideLocal).(string); ok {
+		log.Debug().Msgf("Model %s replaced by %s", modelInput, m)
+		ctx.Locals(modelLocal, m)
+		return m, nil
+	}
+
 	if ctx.Params("model") != "" {
 		modelInput = ctx.Params("model")
 	}
 
+	if ns, ok := ctx.Locals(namespaceLocal).(namespace); ok {
+		m, err := ns.resolve(cm, loader, modelInput, firstModel)
+		if err != nil {
+			return "", err
+		}
+		ctx.Locals(modelLocal, m)
+		return m, nil
+	}
+
 	// Set model from bearer token, if available
 	bearer := strings.TrimLeft(ctx.Get("authorization"), "Bearer ")
 	bearerExists := bearer != "" && loader.ExistsInModelPath(bearer)
@@ -39,5 +73,93 @@ func ModelFromContext(ctx *fiber.Ctx, loader *model.ModelLoader, modelInput stri
 		log.Debug().Msgf("Using model from bearer token: %s", bearer)
 		modelInput = bearer
 	}
+	ctx.Locals(modelLocal, modelInput)
 	return modelInput, nil
 }
+
+// OverrideModel serves the request with the given model, whatever the model requested
+func OverrideModel(ctx *fiber.Ctx, model string) {
+	ctx.Locals(modelOverrideLocal, model)
+}
+
+// SetNamespace restricts the models of the request to the ones of the namespace of the tenant, and to the shared
+// models of the models path if shared is true. The requests without tenant only see the shared models.
+func SetNamespace(ctx *fiber.Ctx, tenant string, shared bool) {
+	ctx.Locals(namespaceLocal, namespace{tenant: tenant, shared: shared || tenant == ""})
+}
+
+// VisibleModel returns the name the request sees a model with, or false if the model is not in its namespace
+func VisibleModel(ctx *fiber.Ctx, name string) (string, bool) {
+	ns, ok := ctx.Locals(namespaceLocal).(namespace)
+	if !ok {
+		return name, true
+	}
+	if tenant, rest, ok := config.SplitTenant(name); ok {
+		return rest, tenant == ns.tenant && ns.tenant != ""
+	}
+	return name, ns.shared && name != config.TenantsDir
+}
+
+// Tenant returns the tenant of the namespace of the request, or an empty string
+func Tenant(ctx *fiber.Ctx) string {
+	ns, _ := ctx.Locals(namespaceLocal).(namespace)
+	return ns.tenant
+}
+
+// resolve returns the model of the namespace with the name. The models of the tenant take precedence over the shared
+// ones of the same name.
+func (ns namespace) resolve(cm *config.ConfigLoader, loader *model.ModelLoader, name string, firstModel bool) (string, error) {
+	if name == "" {
+		if !firstModel {
+			return "", nil
+		}
+		models := []string{}
+		if ns.tenant != "" {
+			for _, c := range cm.GetAllConfigs() {
+				if tenant, _, ok := config.SplitTenant(c.Name); ok && tenant == ns.tenant {
+					models = append(models, c.Name)
+				}
+			}
+			sort.Strings(models)
+			files, _ := loader.ListModelsIn(config.TenantPrefix(ns.tenant))
+			models = append(models, files...)
+		}
+		if ns.shared {
+			files, _ := loader.ListModels()
+			for _, f := range files {
+				if f != config.TenantsDir {
+					models = append(models, f)
+				}
+			}
+		}
+		if len(models) == 0 {
+			log.Debug().Msgf("No model specified, returning error")
+			return "", fmt.Errorf("no model specified")
+		}
+		log.Debug().Msgf("No model specified, using: %s", models[0])
+		return models[0], nil
+	}
+
+	// the namespaces are only reachable through the tenants, the name is cleaned so that e.g. x/../tenants doesn't
+	// reach them, nor the parent of the models path
+	if clean := path.Clean(name); clean == config.TenantsDir || strings.HasPrefix(clean, config.TenantsDir+"/") ||
+		clean == ".." || strings.HasPrefix(clean, "../") || path.IsAbs(clean) {
+		return "", fmt.Errorf("model %s not found", name)
+	}
+	if ns.tenant != "" {
+		m, ok := config.TenantModel(ns.tenant, name)
+		if !ok {
+			return "", fmt.Errorf("model %s not found", name)
+		}
+		if _, configured := cm.GetConfig(m); configured || loader.ExistsInModelPath(m) || !ns.shared {
+			return m, nil
+		}
+	}
+	return name, nil
+}
+
+// RequestModel returns the model of the request resolved by ModelFromContext, or an empty string
+func RequestModel(ctx *fiber.Ctx) string {
+	m, _ := ctx.Locals(modelLocal).(string)
+	return m
+}
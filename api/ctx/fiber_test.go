@@ -0,0 +1,74 @@
+package fiberContext_test
+
+import (
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	. "github.com/go-skynet/LocalAI/api/ctx"
+	"github.com/go-skynet/LocalAI/pkg/model"
+	"github.com/gofiber/fiber/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ModelFromContext", func() {
+	var app *fiber.App
+
+	BeforeEach(func() {
+		dir := GinkgoT().TempDir()
+		for _, f := range []string{"shared.bin", "tenants/alice/model.bin", "tenants/bob/model.bin"} {
+			Expect(os.MkdirAll(filepath.Dir(filepath.Join(dir, f)), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, f), []byte{}, 0600)).To(Succeed())
+		}
+		cm := config.NewConfigLoader()
+		loader := model.NewModelLoader(dir)
+
+		app = fiber.New()
+		app.Get("/", func(c *fiber.Ctx) error {
+			SetNamespace(c, c.Query("tenant"), c.QueryBool("shared"))
+			m, err := ModelFromContext(c, cm, loader, c.Query("model"), false)
+			if err != nil {
+				return fiber.NewError(fiber.StatusNotFound, err.Error())
+			}
+			return c.SendString(m)
+		})
+	})
+
+	resolve := func(tenant string, shared bool, name string) (int, string) {
+		q := url.Values{"tenant": {tenant}, "model": {name}}
+		if shared {
+			q.Set("shared", "true")
+		}
+		resp, err := app.Test(httptest.NewRequest("GET", "/?"+q.Encode(), nil))
+		Expect(err).ToNot(HaveOccurred())
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	It("resolves the models of the namespace of the tenant, and the shared ones", func() {
+		status, m := resolve("alice", true, "model.bin")
+		Expect(status).To(Equal(fiber.StatusOK))
+		Expect(m).To(Equal("tenants/alice/model.bin"))
+
+		status, m = resolve("alice", true, "shared.bin")
+		Expect(status).To(Equal(fiber.StatusOK))
+		Expect(m).To(Equal("shared.bin"))
+	})
+
+	DescribeTable("refuses the names leaving the namespace",
+		func(tenant, name string) {
+			status, _ := resolve(tenant, true, name)
+			Expect(status).To(Equal(fiber.StatusNotFound))
+		},
+		Entry("the tenants directory", "", "tenants/bob/model.bin"),
+		Entry("a path through the tenants directory", "alice", "x/../tenants/bob/model.bin"),
+		Entry("a dotted path through the tenants directory", "", "./tenants/bob/model.bin"),
+		Entry("the parent of the namespace", "alice", "../bob/model.bin"),
+		Entry("the parent of the models path", "", "../model.bin"),
+		Entry("an absolute path", "", "/etc/passwd"),
+	)
+})
@@ -0,0 +1,166 @@
+package fiberContext
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// RequestIDHeader is the header with the ID of the request, set by the clients or generated, and returned in the responses
+const RequestIDHeader = "X-Request-ID"
+
+// the key of the request in the locals of the fiber context
+const requestLocal = "request"
+
+type requestKey struct{}
+
+// the IDs set by the clients are kept if they are safe to log
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// Request is an API call being served, with what the backends report while serving it
+type Request struct {
+	ID     string
+	Logger zerolog.Logger
+
+	mu     sync.Mutex
+	served Served
+}
+
+// Served is what the backends reported while serving a request
+type Served struct {
+	Backend          string
+	PromptTokens     int
+	CompletionTokens int
+	// AudioSeconds is the duration of the audio transcribed or generated
+	AudioSeconds float64
+	// Prompts are the prompts sent to the models, once templated
+	Prompts []string
+}
+
+// SetBackend records the backend serving the request
+func (r *Request) SetBackend(backend string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.served.Backend = backend
+}
+
+// AddTokens adds the tokens of the prompt and the tokens generated by an inference of the request
+func (r *Request) AddTokens(prompt, completion int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.served.PromptTokens += prompt
+	r.served.CompletionTokens += completion
+}
+
+// AddAudio adds the duration of audio transcribed or generated for the request
+func (r *Request) AddAudio(seconds float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.served.AudioSeconds += seconds
+}
+
+// AddPrompt records a prompt sent to the model
+func (r *Request) AddPrompt(prompt string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.served.Prompts = append(r.served.Prompts, prompt)
+}
+
+// Served returns what the backends reported so far
+func (r *Request) Served() Served {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.served
+	s.Prompts = append([]string{}, r.served.Prompts...)
+	return s
+}
+
+// RequestMiddleware assigns an ID to every API call, returned in the X-Request-ID header and logged with the lines about
+// the call, and writes the access log once the call is done. The calls of the paths skipped are not logged.
+func RequestMiddleware(skip ...string) fiber.Handler {
+	skipped := map[string]bool{}
+	for _, p := range skip {
+		skipped[p] = true
+	}
+
+	return func(c *fiber.Ctx) error {
+		id := c.Get(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = uuid.New().String()
+		}
+		r := &Request{ID: id, Logger: log.With().Str("request_id", id).Logger()}
+		c.Locals(requestLocal, r)
+		c.Set(RequestIDHeader, id)
+
+		start := time.Now()
+		err := c.Next()
+		if skipped[c.Path()] {
+			return err
+		}
+
+		status := ResponseStatus(c, err)
+		served := r.Served()
+		r.Logger.Info().
+			Str("method", c.Method()).
+			Str("path", c.Path()).
+			Int("status", status).
+			Str("model", RequestModel(c)).
+			Str("backend", served.Backend).
+			Int("prompt_tokens", served.PromptTokens).
+			Int("completion_tokens", served.CompletionTokens).
+			Dur("latency", time.Since(start)).
+			Msg("API call")
+		return err
+	}
+}
+
+// ResponseStatus returns the status of the response to the request, once served by the handlers with err
+func ResponseStatus(c *fiber.Ctx, err error) int {
+	if err == nil {
+		return c.Response().StatusCode()
+	}
+	// the errors are turned into responses by the error handler, once the middlewares returned
+	var e *fiber.Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return fiber.StatusInternalServerError
+}
+
+// RequestFromCtx returns the request being served, or nil outside of the RequestMiddleware
+func RequestFromCtx(c *fiber.Ctx) *Request {
+	r, _ := c.Locals(requestLocal).(*Request)
+	return r
+}
+
+// WithRequest returns a context carrying the request r and its logger, when r is not nil
+func WithRequest(ctx context.Context, r *Request) context.Context {
+	if r == nil {
+		return ctx
+	}
+	return r.Logger.WithContext(context.WithValue(ctx, requestKey{}, r))
+}
+
+// RequestFromContext returns the request carried by the context, or nil
+func RequestFromContext(ctx context.Context) *Request {
+	if ctx == nil {
+		return nil
+	}
+	r, _ := ctx.Value(requestKey{}).(*Request)
+	return r
+}
+
+// Logger returns the logger of the request carried by the context, which logs its ID, or the global logger
+func Logger(ctx context.Context) *zerolog.Logger {
+	if r := RequestFromContext(ctx); r != nil {
+		return &r.Logger
+	}
+	return &log.Logger
+}
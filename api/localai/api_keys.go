@@ -0,0 +1,57 @@
+package localai
+
+import (
+	"errors"
+
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/apikeys"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Tenant string   `json:"tenant"`
+	Scopes []string `json:"scopes"`
+}
+
+type CreateAPIKeyResponse struct {
+	apikeys.Key
+	// Secret is the API key to authenticate with, only returned when it is created
+	Secret string `json:"key"`
+}
+
+// ListAPIKeysEndpoint lists the API keys, without their secrets
+func ListAPIKeysEndpoint(o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(o.ApiKeyStore.List())
+	}
+}
+
+// CreateAPIKeyEndpoint creates an API key allowed the scopes of the request
+func CreateAPIKeyEndpoint(o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		input := new(CreateAPIKeyRequest)
+		if err := c.BodyParser(input); err != nil {
+			return err
+		}
+		k, secret, err := o.ApiKeyStore.Create(input.Name, input.Tenant, input.Scopes)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+		}
+		return c.Status(fiber.StatusCreated).JSON(CreateAPIKeyResponse{Key: k, Secret: secret})
+	}
+}
+
+// RevokeAPIKeyEndpoint deletes an API key, which is refused from then on
+func RevokeAPIKeyEndpoint(o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		err := o.ApiKeyStore.Revoke(c.Params("id"))
+		if errors.Is(err, apikeys.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": err.Error()})
+		}
+		if err != nil {
+			return err
+		}
+		return c.JSON(fiber.Map{"message": "API key revoked"})
+	}
+}
@@ -0,0 +1,34 @@
+package localai
+
+import (
+	"time"
+
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/model"
+	"github.com/gofiber/fiber/v2"
+)
+
+// the time a backend has to answer the health check, before it is reported as wedged
+const backendHealthTimeout = 5 * time.Second
+
+type BackendHealthResponse struct {
+	// Healthy is false if the backend of any of the loaded models failed its health check
+	Healthy  bool                  `json:"healthy"`
+	Backends []model.BackendHealth `json:"backends"`
+}
+
+// BackendHealthEndpoint runs the health check of the backends of the loaded models, and answers with a 503 if any failed
+func BackendHealthEndpoint(o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		resp := BackendHealthResponse{Healthy: true, Backends: o.Loader.CheckBackends(c.Context(), backendHealthTimeout)}
+		for _, b := range resp.Backends {
+			if !b.Healthy {
+				resp.Healthy = false
+			}
+		}
+		if !resp.Healthy {
+			c.Status(fiber.StatusServiceUnavailable)
+		}
+		return c.JSON(resp)
+	}
+}
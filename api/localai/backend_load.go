@@ -0,0 +1,90 @@
+package localai
+
+import (
+	"fmt"
+
+	"github.com/go-skynet/LocalAI/api/backend"
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type BackendLoadRequest struct {
+	Model string `json:"model" yaml:"model"`
+}
+
+type BackendLoadResponse struct {
+	Model string `json:"model"`
+	// the file of the model, which identifies its backend process
+	File    string `json:"file"`
+	Backend string `json:"backend,omitempty"`
+	Address string `json:"address,omitempty"`
+	Loaded  bool   `json:"loaded"`
+}
+
+func readBackendLoadRequest(c *fiber.Ctx) (*BackendLoadRequest, error) {
+	input := new(BackendLoadRequest)
+	if err := c.BodyParser(input); err != nil {
+		return nil, err
+	}
+	if input.Model == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "no model specified")
+	}
+	return input, nil
+}
+
+// BackendLoadEndpoint loads a model with its backend ahead of the requests, and returns the backend serving it
+func BackendLoadEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		input, err := readBackendLoadRequest(c)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.Load(input.Model, o.Loader.ModelPath, cm, o.Debug, o.Threads, o.ContextSize, o.F16)
+		if err != nil {
+			return err
+		}
+
+		if _, err := backend.ModelLoad(o.Loader, *cfg, o); err != nil {
+			return fmt.Errorf("failed loading model %s: %w", input.Model, err)
+		}
+		log.Info().Msgf("Loaded model %s on request", input.Model)
+
+		resp := BackendLoadResponse{Model: input.Model, File: cfg.Model}
+		resp.Backend, _ = o.Loader.LoadedBackend(cfg.Model)
+		addr, loaded := o.Loader.LoadedAddress(cfg.Model)
+		resp.Address, resp.Loaded = string(addr), loaded
+		return c.JSON(resp)
+	}
+}
+
+// BackendUnloadEndpoint stops the backend of a model to free its memory, the model is loaded again by the next request
+func BackendUnloadEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		input, err := readBackendLoadRequest(c)
+		if err != nil {
+			return err
+		}
+
+		file := input.Model
+		if cfg, exists := cm.GetConfig(input.Model); exists {
+			file = cfg.Model
+		}
+
+		resp := BackendLoadResponse{Model: input.Model, File: file}
+		resp.Backend, _ = o.Loader.LoadedBackend(file)
+		addr, loaded := o.Loader.LoadedAddress(file)
+		if !loaded {
+			return fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("model %s is not loaded", input.Model))
+		}
+		resp.Address = string(addr)
+
+		if err := o.Loader.ShutdownModel(file); err != nil {
+			return fmt.Errorf("failed unloading model %s: %w", input.Model, err)
+		}
+		log.Info().Msgf("Unloaded model %s on request", input.Model)
+		return c.JSON(resp)
+	}
+}
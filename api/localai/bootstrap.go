@@ -0,0 +1,199 @@
+package localai
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/gallery"
+	"github.com/go-skynet/LocalAI/pkg/vectorstore"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// Bootstrap is a declarative description of the state of a LocalAI instance.
+// Applying it is idempotent: models which are already installed are skipped,
+// keys, galleries, backends and vector stores are only added when missing, and
+// the aliases are routed to their model.
+type Bootstrap struct {
+	Galleries        []gallery.Gallery    `json:"galleries" yaml:"galleries"`
+	Models           []galleryModel       `json:"models" yaml:"models"`
+	APIKeys          []string             `json:"api_keys" yaml:"api_keys"`
+	ExternalBackends map[string]string    `json:"external_backends" yaml:"external_backends"`
+	Stores           []CreateStoreRequest `json:"stores" yaml:"stores"`
+	// Aliases route model names to the models, e.g. gpt-4 to the installed model serving it
+	Aliases map[string]string `json:"aliases" yaml:"aliases"`
+}
+
+type BootstrapResponse struct {
+	Message string `json:"message"`
+	// Errors are the failures of the parts of the manifest, the other parts are applied
+	Errors []string `json:"errors,omitempty"`
+}
+
+func ApplyBootstrapFile(file string, cm *config.ConfigLoader, o *options.Option) error {
+	dat, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	b := Bootstrap{}
+	if err := yaml.Unmarshal(dat, &b); err != nil {
+		return fmt.Errorf("failed parsing bootstrap file %s: %w", file, err)
+	}
+
+	return ApplyBootstrap(b, cm, o)
+}
+
+// ApplyBootstrap applies every part of the manifest, going on after the failures, and returns the error of each part
+// which failed
+func ApplyBootstrap(b Bootstrap, cm *config.ConfigLoader, o *options.Option) error {
+	errs := []error{}
+	for _, g := range b.Galleries {
+		o.AddGallery(g)
+	}
+	o.AddApiKeys(b.APIKeys...)
+
+	for name, uri := range b.ExternalBackends {
+		if o.ExternalGRPCBackends[name] == uri || o.Loader.ListExternalBackends()[name] == uri {
+			continue
+		}
+		if err := o.Loader.RegisterExternalBackend(name, uri); err != nil {
+			errs = append(errs, fmt.Errorf("failed registering the external backend %s: %w", name, err))
+		}
+	}
+
+	toInstall := []galleryModel{}
+	for _, m := range b.Models {
+		if name := bootstrapModelName(m); name != "" {
+			if _, err := os.Stat(filepath.Join(o.Loader.ModelPath, name+".yaml")); err == nil {
+				log.Debug().Msgf("Model %s is already installed, skipping", name)
+				continue
+			}
+		}
+		toInstall = append(toInstall, m)
+	}
+
+	if len(toInstall) > 0 {
+		if err := processRequests(o.Loader.ModelPath, "", cm, o.GetGalleries(), toInstall, o.GalleryInstallOptions()...); err != nil {
+			errs = append(errs, err)
+		}
+		if err := cm.LoadConfigs(o.Loader.ModelPath); err != nil {
+			errs = append(errs, fmt.Errorf("failed loading the model configs: %w", err))
+		}
+	}
+
+	for _, s := range b.Stores {
+		if err := bootstrapStore(o, s); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(b.Aliases) > 0 {
+		if err := bootstrapAliases(cm, o, b.Aliases); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// bootstrapStore creates the collection of the vector store if it doesn't exist yet
+func bootstrapStore(o *options.Option, s CreateStoreRequest) error {
+	if o.VectorStore == nil {
+		return fmt.Errorf("cannot create the vector store %s: the vector stores are disabled", s.Name)
+	}
+	existing, err := o.VectorStore.Get(s.Name)
+	if err == nil {
+		if existing.Dimensions != s.Dimensions {
+			return fmt.Errorf("the vector store %s exists with %d dimensions, not %d", s.Name, existing.Dimensions, s.Dimensions)
+		}
+		return nil
+	}
+	if !errors.Is(err, vectorstore.ErrNotFound) {
+		return err
+	}
+	if _, err := o.VectorStore.Create(s.Name, s.Dimensions, s.Metric, s.Model); err != nil {
+		return fmt.Errorf("failed creating the vector store %s: %w", s.Name, err)
+	}
+	return nil
+}
+
+// bootstrapAliases routes the aliases to their model and saves the routes
+func bootstrapAliases(cm *config.ConfigLoader, o *options.Option, aliases map[string]string) error {
+	names := []string{}
+	for alias := range aliases {
+		names = append(names, alias)
+	}
+	sort.Strings(names)
+
+	routesMu.Lock()
+	defer routesMu.Unlock()
+	errs := []error{}
+	routes := cm.Routes()
+	for _, alias := range names {
+		if _, configured := cm.GetConfig(alias); configured {
+			errs = append(errs, fmt.Errorf("cannot route the alias %s: it is the name of a model", alias))
+			continue
+		}
+		r, err := routes.WithAlias(alias, aliases[alias])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot route the alias %s: %w", alias, err))
+			continue
+		}
+		routes = r
+	}
+	if err := setRoutes(cm, o, routes); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// bootstrapModelName returns the name the model is installed with, if it can be known in advance
+func bootstrapModelName(m galleryModel) string {
+	if m.Name != "" {
+		return m.Name
+	}
+	if m.ID == "" {
+		return ""
+	}
+	if _, name, found := strings.Cut(m.ID, "@"); found {
+		return name
+	}
+	return m.ID
+}
+
+// errorMessages returns the message of each error joined in err
+func errorMessages(err error) []string {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []string{err.Error()}
+	}
+	messages := []string{}
+	for _, e := range joined.Unwrap() {
+		messages = append(messages, errorMessages(e)...)
+	}
+	return messages
+}
+
+// BootstrapEndpoint applies a bootstrap manifest, in YAML or JSON, sent in the request body
+func BootstrapEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		b := Bootstrap{}
+		if err := yaml.Unmarshal(c.Body(), &b); err != nil {
+			return err
+		}
+
+		if err := ApplyBootstrap(b, cm, o); err != nil {
+			resp := BootstrapResponse{Message: "bootstrap partially applied", Errors: errorMessages(err)}
+			return c.Status(fiber.StatusInternalServerError).JSON(resp)
+		}
+		return c.JSON(BootstrapResponse{Message: "bootstrap applied"})
+	}
+}
@@ -0,0 +1,150 @@
+package localai_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/localai"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/gallery"
+	"github.com/go-skynet/LocalAI/pkg/model"
+	"github.com/go-skynet/LocalAI/pkg/routing"
+	"github.com/go-skynet/LocalAI/pkg/vectorstore"
+	"github.com/gofiber/fiber/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+)
+
+var _ = Describe("Bootstrap", func() {
+	var dir string
+	var cm *config.ConfigLoader
+	var o *options.Option
+	var store *vectorstore.Store
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		var err error
+		store, err = vectorstore.Open(filepath.Join(dir, "stores"))
+		Expect(err).ToNot(HaveOccurred())
+		o = options.NewOptions(
+			options.WithContext(context.Background()),
+			options.WithModelLoader(model.NewModelLoader(dir)),
+			options.WithModelRoutesFile(filepath.Join(dir, "routes.yaml")),
+			options.WithVectorStore(store),
+		)
+		cm = config.NewConfigLoader()
+		file := filepath.Join(dir, "mistral.yaml")
+		Expect(os.WriteFile(file, []byte("name: mistral\nparameters:\n  model: mistral.gguf\n"), 0644)).To(Succeed())
+		Expect(cm.LoadConfig(file)).To(Succeed())
+	})
+
+	manifest := func(s string) localai.Bootstrap {
+		b := localai.Bootstrap{}
+		Expect(yaml.Unmarshal([]byte(s), &b)).To(Succeed())
+		return b
+	}
+
+	It("creates the stores and routes the aliases, idempotently", func() {
+		b := manifest(`
+stores:
+- name: docs
+  dimensions: 3
+  model: bert
+aliases:
+  gpt-4: mistral
+  gpt-3.5-turbo: mistral
+`)
+		for i := 0; i < 2; i++ {
+			Expect(localai.ApplyBootstrap(b, cm, o)).To(Succeed())
+		}
+
+		c, err := store.Get("docs")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c.Dimensions).To(Equal(3))
+		Expect(c.Model).To(Equal("bert"))
+
+		r, ok := cm.Routes().Resolve("gpt-4")
+		Expect(ok).To(BeTrue())
+		Expect(r.Model).To(Equal("mistral"))
+
+		saved, err := routing.Load(filepath.Join(dir, "routes.yaml"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(saved.Rules()).To(HaveLen(2))
+	})
+
+	It("applies the other parts and reports every failure", func() {
+		b := manifest(`
+models:
+- id: nowhere@missing
+stores:
+- name: docs
+  dimensions: 3
+- name: "bad name!"
+  dimensions: 3
+aliases:
+  mistral: other
+  gpt-4: mistral
+`)
+		err := localai.ApplyBootstrap(b, cm, o)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("failed installing nowhere@missing"))
+		Expect(err.Error()).To(ContainSubstring("bad name!"))
+		Expect(err.Error()).To(ContainSubstring("cannot route the alias mistral"))
+
+		_, err = store.Get("docs")
+		Expect(err).ToNot(HaveOccurred())
+		r, ok := cm.Routes().Resolve("gpt-4")
+		Expect(ok).To(BeTrue())
+		Expect(r.Model).To(Equal("mistral"))
+	})
+
+	It("refuses to change the dimensions of the existing stores", func() {
+		_, err := store.Create("docs", 3, "", "")
+		Expect(err).ToNot(HaveOccurred())
+		err = localai.ApplyBootstrap(manifest("stores:\n- name: docs\n  dimensions: 4\n"), cm, o)
+		Expect(err).To(MatchError(ContainSubstring("exists with 3 dimensions")))
+	})
+
+	It("shares the galleries it adds with the gallery service", func() {
+		mgs := localai.CreateModelGalleryService(o, nil)
+		app := fiber.New()
+		app.Get("/models/galleries", mgs.ListModelGalleriesEndpoint())
+
+		Expect(localai.ApplyBootstrap(manifest("galleries:\n- name: extra\n  url: https://example.com/index.yaml\n"), cm, o)).To(Succeed())
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/models/galleries", nil))
+		Expect(err).ToNot(HaveOccurred())
+		dat, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		galleries := []gallery.Gallery{}
+		Expect(json.Unmarshal(dat, &galleries)).To(Succeed())
+		Expect(galleries).To(ContainElement(gallery.Gallery{Name: "extra", URL: "https://example.com/index.yaml"}))
+	})
+
+	It("adds the keys while they are read", func() {
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				defer GinkgoRecover()
+				Expect(localai.ApplyBootstrap(manifest("api_keys: [a, b]\ngalleries:\n- name: extra\n  url: https://example.com/index.yaml\n"), cm, o)).To(Succeed())
+			}()
+			go func() {
+				defer wg.Done()
+				o.HasApiKey("a")
+				o.GetGalleries()
+			}()
+		}
+		wg.Wait()
+		Expect(o.GetApiKeys()).To(Equal([]string{"a", "b"}))
+		Expect(o.GetGalleries()).To(HaveLen(1))
+	})
+})
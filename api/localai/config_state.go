@@ -0,0 +1,534 @@
+package localai
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/downloader"
+	"github.com/go-skynet/LocalAI/pkg/gallery"
+	"github.com/go-skynet/LocalAI/pkg/grpc"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigState is the desired configuration of the models, and of the server
+// settings which can be changed at runtime. It is applied as a whole, or not at all.
+type ConfigState struct {
+	Models []config.Config `yaml:"models"`
+	// Prune removes the models which are not in Models
+	Prune bool `yaml:"prune"`
+	// Server settings, left unchanged when not set
+	Server *ServerState `yaml:"server"`
+
+	// the models as sent, so the files are written without all the default values
+	raw []map[string]interface{}
+}
+
+func (s *ConfigState) UnmarshalYAML(value *yaml.Node) error {
+	type plain ConfigState
+	if err := value.Decode((*plain)(s)); err != nil {
+		return err
+	}
+	raw := struct {
+		Models []map[string]interface{} `yaml:"models"`
+	}{}
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+	s.raw = raw.Models
+	return nil
+}
+
+type ServerState struct {
+	APIKeys          []string          `yaml:"api_keys"`
+	Galleries        []gallery.Gallery `yaml:"galleries"`
+	ExternalBackends map[string]string `yaml:"external_backends"`
+}
+
+// Actions of the changes of a ConfigDiff
+const (
+	ConfigAdded   = "added"
+	ConfigRemoved = "removed"
+	ConfigChanged = "changed"
+)
+
+type FieldChange struct {
+	Path string      `json:"path"`
+	Old  interface{} `json:"old"`
+	New  interface{} `json:"new"`
+}
+
+type ConfigChange struct {
+	// model, api_key, gallery or external_backend
+	Kind   string        `json:"kind"`
+	Name   string        `json:"name"`
+	Action string        `json:"action"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+type ConfigDiff struct {
+	Changes  []ConfigChange `json:"changes"`
+	Errors   []string       `json:"errors,omitempty"`
+	Warnings []string       `json:"warnings,omitempty"`
+	Applied  bool           `json:"applied"`
+}
+
+// only one configuration is applied at a time
+var configMu sync.Mutex
+
+// flatten returns the fields of v, as marshalled to YAML, by their dotted path
+func flatten(v interface{}) (map[string]interface{}, error) {
+	dat, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	if err := yaml.Unmarshal(dat, &m); err != nil {
+		return nil, err
+	}
+
+	res := map[string]interface{}{}
+	var walk func(prefix string, m map[string]interface{})
+	walk = func(prefix string, m map[string]interface{}) {
+		for k, v := range m {
+			if nested, ok := v.(map[string]interface{}); ok && len(nested) > 0 {
+				walk(prefix+k+".", nested)
+				continue
+			}
+			res[prefix+k] = v
+		}
+	}
+	walk("", m)
+	return res, nil
+}
+
+func diffFields(old, new interface{}) ([]FieldChange, error) {
+	o, err := flatten(old)
+	if err != nil {
+		return nil, err
+	}
+	n, err := flatten(new)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []FieldChange{}
+	for path, v := range n {
+		if !reflect.DeepEqual(o[path], v) {
+			changes = append(changes, FieldChange{Path: path, Old: o[path], New: v})
+		}
+	}
+	for path, v := range o {
+		if _, ok := n[path]; !ok {
+			changes = append(changes, FieldChange{Path: path, Old: v})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// maskKey hides an API key in the diffs, keeping just enough to recognize it
+func maskKey(k string) string {
+	if len(k) <= 8 {
+		return strings.Repeat("*", len(k))
+	}
+	return k[:4] + strings.Repeat("*", len(k)-4)
+}
+
+// configFiles returns the file of the models path defining each model
+func configFiles(modelPath string) map[string]string {
+	files := map[string]string{}
+	entries, err := os.ReadDir(modelPath)
+	if err != nil {
+		return files
+	}
+	for _, e := range entries {
+		if !strings.Contains(e.Name(), ".yaml") && !strings.Contains(e.Name(), ".yml") {
+			continue
+		}
+		p := filepath.Join(modelPath, e.Name())
+		if c, err := config.ReadConfig(p); err == nil && c.Name != "" {
+			files[c.Name] = p
+		}
+	}
+	return files
+}
+
+// Diff compares the state with the running configuration, and validates it
+func (s ConfigState) Diff(cm *config.ConfigLoader, o *options.Option) ConfigDiff {
+	d := ConfigDiff{Changes: []ConfigChange{}}
+	files := configFiles(o.Loader.ModelPath)
+
+	running := map[string]config.Config{}
+	for _, c := range cm.GetAllConfigs() {
+		running[c.Name] = c
+	}
+
+	proposed := map[string]bool{}
+	for _, c := range s.Models {
+		switch {
+		case c.Name == "":
+			d.Errors = append(d.Errors, "a model has no name")
+			continue
+		case strings.ContainsAny(c.Name, `/\`) || c.Name == "." || c.Name == "..":
+			d.Errors = append(d.Errors, fmt.Sprintf("model %s: the name can't contain path separators", c.Name))
+			continue
+		case proposed[c.Name]:
+			d.Errors = append(d.Errors, fmt.Sprintf("model %s is defined more than once", c.Name))
+			continue
+		}
+		proposed[c.Name] = true
+
+		if c.GRPC.KeepaliveTime < 0 || (c.GRPC.KeepaliveTime != 0 && time.Duration(c.GRPC.KeepaliveTime)*time.Second < grpc.MinKeepaliveTime) {
+			d.Errors = append(d.Errors, fmt.Sprintf("model %s: keepalive_time must be at least %s, the backends close the connections pinged more often", c.Name, grpc.MinKeepaliveTime))
+		}
+
+		if c.Model != "" && !downloader.LooksLikeURL(c.Model) {
+			if _, err := os.Stat(filepath.Join(o.Loader.ModelPath, c.Model)); err != nil {
+				d.Warnings = append(d.Warnings, fmt.Sprintf("model %s: the file %s is not in the models path, the backend has to download it", c.Name, c.Model))
+			}
+		}
+
+		old, exists := running[c.Name]
+		if !exists {
+			d.Changes = append(d.Changes, ConfigChange{Kind: "model", Name: c.Name, Action: ConfigAdded})
+			continue
+		}
+		fields, err := diffFields(old, c)
+		if err != nil {
+			d.Errors = append(d.Errors, fmt.Sprintf("model %s: %s", c.Name, err.Error()))
+			continue
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		if _, ok := files[c.Name]; !ok {
+			d.Errors = append(d.Errors, fmt.Sprintf("model %s is not defined in the models path (e.g. it comes from --config-file), it can't be changed at runtime", c.Name))
+		}
+		d.Changes = append(d.Changes, ConfigChange{Kind: "model", Name: c.Name, Action: ConfigChanged, Fields: fields})
+	}
+
+	if s.Prune {
+		for name := range running {
+			if proposed[name] {
+				continue
+			}
+			if _, ok := files[name]; !ok {
+				d.Errors = append(d.Errors, fmt.Sprintf("model %s is not defined in the models path (e.g. it comes from --config-file), it can't be removed at runtime", name))
+			}
+			d.Changes = append(d.Changes, ConfigChange{Kind: "model", Name: name, Action: ConfigRemoved})
+		}
+	}
+
+	if s.Server != nil {
+		d.diffServer(*s.Server, o)
+	}
+
+	sort.SliceStable(d.Changes, func(i, j int) bool {
+		if d.Changes[i].Kind != d.Changes[j].Kind {
+			return d.Changes[i].Kind < d.Changes[j].Kind
+		}
+		return d.Changes[i].Name < d.Changes[j].Name
+	})
+	return d
+}
+
+func (d *ConfigDiff) diffServer(s ServerState, o *options.Option) {
+	keys := map[string]bool{}
+	for _, k := range s.APIKeys {
+		if k == "" {
+			d.Errors = append(d.Errors, "an API key is empty")
+		}
+		keys[k] = true
+		if !o.HasApiKey(k) {
+			d.Changes = append(d.Changes, ConfigChange{Kind: "api_key", Name: maskKey(k), Action: ConfigAdded})
+		}
+	}
+	for _, k := range o.GetApiKeys() {
+		if !keys[k] {
+			d.Changes = append(d.Changes, ConfigChange{Kind: "api_key", Name: maskKey(k), Action: ConfigRemoved})
+		}
+	}
+
+	galleries := map[string]gallery.Gallery{}
+	for _, g := range o.GetGalleries() {
+		galleries[g.Name] = g
+	}
+	proposed := map[string]bool{}
+	for _, g := range s.Galleries {
+		if g.Name == "" || g.URL == "" {
+			d.Errors = append(d.Errors, "both the name and the url of a gallery are required")
+			continue
+		}
+		proposed[g.Name] = true
+		old, exists := galleries[g.Name]
+		switch {
+		case !exists:
+			d.Changes = append(d.Changes, ConfigChange{Kind: "gallery", Name: g.Name, Action: ConfigAdded})
+		case old.URL != g.URL:
+			d.Changes = append(d.Changes, ConfigChange{Kind: "gallery", Name: g.Name, Action: ConfigChanged,
+				Fields: []FieldChange{{Path: "url", Old: old.URL, New: g.URL}}})
+		}
+	}
+	for name := range galleries {
+		if !proposed[name] {
+			d.Changes = append(d.Changes, ConfigChange{Kind: "gallery", Name: name, Action: ConfigRemoved})
+		}
+	}
+
+	// the backends registered at runtime override the ones given at startup
+	registered := o.Loader.ListExternalBackends()
+	current := map[string]string{}
+	for name, uri := range o.ExternalGRPCBackends {
+		current[name] = uri
+	}
+	for name, uri := range registered {
+		current[name] = uri
+	}
+	for name, uri := range s.ExternalBackends {
+		if name == "" || uri == "" {
+			d.Errors = append(d.Errors, "both the name and the uri of an external backend are required")
+			continue
+		}
+		old, exists := current[name]
+		switch {
+		case !exists:
+			d.Changes = append(d.Changes, ConfigChange{Kind: "external_backend", Name: name, Action: ConfigAdded})
+		case old != uri:
+			d.Changes = append(d.Changes, ConfigChange{Kind: "external_backend", Name: name, Action: ConfigChanged,
+				Fields: []FieldChange{{Path: "uri", Old: old, New: uri}}})
+		}
+	}
+	for name := range current {
+		if _, ok := s.ExternalBackends[name]; ok {
+			continue
+		}
+		if _, ok := o.ExternalGRPCBackends[name]; ok {
+			d.Errors = append(d.Errors, fmt.Sprintf("external backend %s was given at startup, it can't be removed at runtime", name))
+			continue
+		}
+		d.Changes = append(d.Changes, ConfigChange{Kind: "external_backend", Name: name, Action: ConfigRemoved})
+	}
+}
+
+// fileBackup is the content of a file before applying a configuration, nil if it didn't exist
+type fileBackup struct {
+	path string
+	dat  []byte
+}
+
+func restore(backups []fileBackup) {
+	for i := len(backups) - 1; i >= 0; i-- {
+		b := backups[i]
+		var err error
+		if b.dat == nil {
+			err = os.Remove(b.path)
+		} else {
+			err = os.WriteFile(b.path, b.dat, 0644)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			log.Error().Msgf("failed restoring %s: %s", b.path, err.Error())
+		}
+	}
+}
+
+// writeAtomic replaces the content of the file, recording its previous content
+func writeAtomic(path string, dat []byte, backups *[]fileBackup) error {
+	old, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	*backups = append(*backups, fileBackup{path: path, dat: old})
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, dat, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Apply validates the state and applies it. If any step fails, the previous configuration is restored.
+func (s ConfigState) Apply(cm *config.ConfigLoader, o *options.Option) (ConfigDiff, error) {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	d := s.Diff(cm, o)
+	if len(d.Errors) > 0 {
+		return d, fiber.NewError(fiber.StatusBadRequest, "invalid configuration: "+strings.Join(d.Errors, "; "))
+	}
+	if len(d.Changes) == 0 {
+		return d, nil
+	}
+
+	files := configFiles(o.Loader.ModelPath)
+	proposed := map[string]config.Config{}
+	raw := map[string]interface{}{}
+	for i, c := range s.Models {
+		proposed[c.Name] = c
+		raw[c.Name] = c
+		if i < len(s.raw) {
+			raw[c.Name] = s.raw[i]
+		}
+	}
+	previous := map[string]config.Config{}
+	for _, c := range cm.GetAllConfigs() {
+		previous[c.Name] = c
+	}
+
+	backups := []fileBackup{}
+	apiKeys, galleries := o.GetApiKeys(), o.GetGalleries()
+	registered := o.Loader.ListExternalBackends()
+
+	rollback := func(err error) (ConfigDiff, error) {
+		restore(backups)
+		o.SetApiKeys(apiKeys)
+		o.SetGalleries(galleries)
+		for name := range o.Loader.ListExternalBackends() {
+			if _, ok := registered[name]; !ok {
+				o.Loader.UnregisterExternalBackend(name)
+			}
+		}
+		for name, uri := range registered {
+			o.Loader.RegisterExternalBackend(name, uri)
+		}
+		for name := range proposed {
+			if _, ok := previous[name]; !ok {
+				cm.RemoveConfig(name)
+			}
+		}
+		if lerr := cm.LoadConfigs(o.Loader.ModelPath); lerr != nil {
+			log.Error().Msgf("failed reloading the configuration after a rollback: %s", lerr.Error())
+		}
+		return d, fmt.Errorf("failed applying the configuration, it was rolled back: %w", err)
+	}
+
+	// the backends of the changed models are stopped, so they are loaded again with the new configuration
+	stop := []string{}
+	for _, ch := range d.Changes {
+		if ch.Kind != "model" {
+			continue
+		}
+		switch ch.Action {
+		case ConfigAdded, ConfigChanged:
+			dat, err := yaml.Marshal(raw[ch.Name])
+			if err != nil {
+				return rollback(err)
+			}
+			path, ok := files[ch.Name]
+			if !ok {
+				path = filepath.Join(o.Loader.ModelPath, ch.Name+".yaml")
+			}
+			if err := writeAtomic(path, dat, &backups); err != nil {
+				return rollback(err)
+			}
+			// make sure the file is loaded back as expected
+			if c, err := config.ReadConfig(path); err != nil {
+				return rollback(err)
+			} else if c.Name != ch.Name {
+				return rollback(fmt.Errorf("model %s was written to %s, but it is read back as %s", ch.Name, path, c.Name))
+			}
+		case ConfigRemoved:
+			path := files[ch.Name]
+			dat, err := os.ReadFile(path)
+			if err != nil {
+				return rollback(err)
+			}
+			backups = append(backups, fileBackup{path: path, dat: dat})
+			if err := os.Remove(path); err != nil {
+				return rollback(err)
+			}
+		}
+		if old, ok := previous[ch.Name]; ok {
+			stop = append(stop, old.Model)
+		}
+	}
+
+	if s.Server != nil {
+		o.SetApiKeys(s.Server.APIKeys)
+		o.SetGalleries(s.Server.Galleries)
+		for name := range registered {
+			if _, ok := s.Server.ExternalBackends[name]; !ok {
+				if err := o.Loader.UnregisterExternalBackend(name); err != nil {
+					return rollback(err)
+				}
+			}
+		}
+		for name, uri := range s.Server.ExternalBackends {
+			if current, ok := registered[name]; current == uri || (!ok && o.ExternalGRPCBackends[name] == uri) {
+				continue
+			}
+			if err := o.Loader.RegisterExternalBackend(name, uri); err != nil {
+				return rollback(err)
+			}
+		}
+	}
+
+	for _, ch := range d.Changes {
+		if ch.Kind == "model" && ch.Action == ConfigRemoved {
+			cm.RemoveConfig(ch.Name)
+		}
+	}
+	if err := cm.LoadConfigs(o.Loader.ModelPath); err != nil {
+		return rollback(err)
+	}
+
+	for _, m := range stop {
+		if _, loaded := o.Loader.LoadedBackend(m); !loaded {
+			continue
+		}
+		if err := o.Loader.ShutdownModel(m); err == nil {
+			log.Info().Msgf("Stopped the backend of %s, to load it with the new configuration", m)
+		}
+	}
+
+	d.Applied = true
+	log.Info().Msgf("Applied a configuration with %d changes", len(d.Changes))
+	return d, nil
+}
+
+func readConfigState(c *fiber.Ctx) (ConfigState, error) {
+	s := ConfigState{}
+	if err := yaml.Unmarshal(c.Body(), &s); err != nil {
+		return s, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("failed parsing the configuration: %s", err.Error()))
+	}
+	return s, nil
+}
+
+// ConfigDiffEndpoint returns the differences between the configuration sent in the request body, in YAML or JSON, and the running one
+func ConfigDiffEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		s, err := readConfigState(c)
+		if err != nil {
+			return err
+		}
+		return c.JSON(s.Diff(cm, o))
+	}
+}
+
+// ConfigApplyEndpoint validates and applies the configuration sent in the request body, in YAML or JSON
+func ConfigApplyEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		s, err := readConfigState(c)
+		if err != nil {
+			return err
+		}
+		d, err := s.Apply(cm, o)
+		if err != nil {
+			status := fiber.StatusInternalServerError
+			if e, ok := err.(*fiber.Error); ok {
+				status = e.Code
+			}
+			return c.Status(status).JSON(fiber.Map{"error": err.Error(), "diff": d})
+		}
+		return c.JSON(d)
+	}
+}
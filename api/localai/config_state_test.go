@@ -0,0 +1,26 @@
+package localai_test
+
+import (
+	"context"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/localai"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/model"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConfigState", func() {
+	It("refuses the keepalives shorter than the backends accept", func() {
+		o := options.NewOptions(
+			options.WithContext(context.Background()),
+			options.WithModelLoader(model.NewModelLoader(GinkgoT().TempDir())),
+		)
+		fast := config.Config{Name: "fast", GRPC: config.GRPC{KeepaliveTime: 1}}
+		slow := config.Config{Name: "slow", GRPC: config.GRPC{KeepaliveTime: 30}}
+
+		d := localai.ConfigState{Models: []config.Config{fast, slow}}.Diff(config.NewConfigLoader(), o)
+		Expect(d.Errors).To(ConsistOf(ContainSubstring("model fast: keepalive_time must be at least 5s")))
+	})
+})
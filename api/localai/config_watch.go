@@ -0,0 +1,198 @@
+package localai
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/rs/zerolog/log"
+)
+
+// ConfigWatcher applies the changes of the config files of the models directories without restarting.
+// The new files register their models, the edited ones replace the configs and stop the backends
+// of the models, which are loaded again with the new configuration on the next request, and the deleted ones unload the models.
+type ConfigWatcher struct {
+	cm     *config.ConfigLoader
+	o      *options.Option
+	rescan chan struct{}
+	// called with the models registered or reloaded by a scan
+	onChange func(models ...string)
+
+	// the config files found at the last scan
+	files map[string]watchedFile
+}
+
+type watchedFile struct {
+	modTime time.Time
+	size    int64
+	// name of the model defined by the file, empty if it could not be read
+	model string
+}
+
+// NewConfigWatcher returns a watcher of the config files of the models directories, which were already loaded
+func NewConfigWatcher(cm *config.ConfigLoader, o *options.Option) *ConfigWatcher {
+	w := &ConfigWatcher{cm: cm, o: o, rescan: make(chan struct{}, 1)}
+	w.files = w.stat()
+	for path, f := range w.files {
+		if c, err := config.ReadConfig(path); err == nil && c.Name != "" {
+			f.model = c.Name
+			w.files[path] = f
+		}
+	}
+	return w
+}
+
+// stat returns the config files of the models directories, and of the directories of the tenants with the namespaces
+func (w *ConfigWatcher) stat() map[string]watchedFile {
+	files := map[string]watchedFile{}
+	for _, d := range w.o.Loader.ModelDirs() {
+		statDir(d.Path, files)
+		if !w.o.TenantNamespaces {
+			continue
+		}
+		tenants, err := os.ReadDir(filepath.Join(d.Path, config.TenantsDir))
+		if err != nil {
+			continue
+		}
+		for _, t := range tenants {
+			if t.IsDir() && config.ValidTenant(t.Name()) {
+				statDir(filepath.Join(d.Path, config.TenantsDir, t.Name()), files)
+			}
+		}
+	}
+	return files
+}
+
+// statDir adds the config files of the directory to files
+func statDir(dir string, files map[string]watchedFile) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.IsDir() || (!strings.Contains(e.Name(), ".yaml") && !strings.Contains(e.Name(), ".yml")) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files[filepath.Join(dir, e.Name())] = watchedFile{modTime: info.ModTime(), size: info.Size()}
+	}
+}
+
+// OnChange sets the function called in the background with the models registered or reloaded by the scans
+func (w *ConfigWatcher) OnChange(fn func(models ...string)) {
+	w.onChange = fn
+}
+
+// Rescan requests a scan of the config files, without waiting for the next one
+func (w *ConfigWatcher) Rescan() {
+	select {
+	case w.rescan <- struct{}{}:
+	default:
+	}
+}
+
+// Run scans the config files at every interval, and when a rescan is requested, until the context is canceled.
+// With an interval of 0, the files are only scanned on request.
+func (w *ConfigWatcher) Run(ctx context.Context, interval time.Duration) {
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+		case <-w.rescan:
+			log.Info().Msgf("Rescanning the config files of the models directories")
+		}
+		w.Scan()
+	}
+}
+
+// Scan applies the changes of the config files since the last scan
+func (w *ConfigWatcher) Scan() {
+	// the configuration API writes the same files
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	current := w.stat()
+	defined := map[string]bool{}
+	changed := []string{}
+	for path, f := range current {
+		old, seen := w.files[path]
+		if seen && old.modTime.Equal(f.modTime) && old.size == f.size {
+			current[path] = old
+			defined[old.model] = true
+			continue
+		}
+
+		c, err := config.ReadConfig(path)
+		if err != nil || c.Name == "" {
+			// the file might still be written, the model is kept as it was until the file changes again
+			log.Warn().Msgf("Ignoring the config file %s, as it cannot be read: %v", path, err)
+			f.model = old.model
+			current[path] = f
+			defined[old.model] = true
+			continue
+		}
+		f.model = c.Name
+		current[path] = f
+		defined[c.Name] = true
+
+		running, exists := w.cm.GetConfig(c.Name)
+		if exists && reflect.DeepEqual(running, *c) {
+			continue
+		}
+		if err := w.cm.LoadConfig(path); err != nil {
+			log.Warn().Msgf("Failed loading the config file %s: %s", path, err.Error())
+			continue
+		}
+		if exists {
+			log.Info().Msgf("Reloaded the config of %s from %s", c.Name, path)
+			w.stop(running.Model)
+		} else {
+			log.Info().Msgf("Registered the model %s from %s", c.Name, path)
+		}
+		changed = append(changed, c.Name)
+	}
+
+	// the models of the deleted files, or of the files which now define another model, are unloaded
+	for path, old := range w.files {
+		if old.model == "" || defined[old.model] {
+			continue
+		}
+		c, exists := w.cm.GetConfig(old.model)
+		if !exists {
+			continue
+		}
+		w.cm.RemoveConfig(old.model)
+		w.stop(c.Model)
+		log.Info().Msgf("Unloaded the model %s, as %s does not define it anymore", old.model, path)
+	}
+
+	w.files = current
+	if w.onChange != nil && len(changed) > 0 {
+		go w.onChange(changed...)
+	}
+}
+
+// stop stops the backend of the model if it is loaded, it is loaded again on the next request
+func (w *ConfigWatcher) stop(model string) {
+	if _, loaded := w.o.Loader.LoadedBackend(model); !loaded {
+		return
+	}
+	if err := w.o.Loader.ShutdownModel(model); err == nil {
+		log.Info().Msgf("Stopped the backend of %s, to load it with the new configuration", model)
+	}
+}
@@ -0,0 +1,20 @@
+package localai
+
+import (
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CrashesEndpoint lists the last crash of the backend of each model, or of the model given as parameter
+func CrashesEndpoint(o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		if m := c.Params("model"); m != "" {
+			crash, ok := o.Loader.LastCrash(m)
+			if !ok {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "no crash of the backend of " + m})
+			}
+			return c.JSON(crash)
+		}
+		return c.JSON(o.Loader.Crashes())
+	}
+}
@@ -0,0 +1,43 @@
+package localai
+
+import (
+	"runtime"
+
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/model"
+	"github.com/gofiber/fiber/v2"
+)
+
+type RuntimeDiagnostics struct {
+	Goroutines int `json:"goroutines"`
+	// the memory of the heap, and obtained from the OS, in bytes
+	HeapAlloc  uint64 `json:"heap_alloc"`
+	HeapInuse  uint64 `json:"heap_inuse"`
+	Sys        uint64 `json:"sys"`
+	NumGC      uint32 `json:"num_gc"`
+	GOMAXPROCS int    `json:"gomaxprocs"`
+}
+
+type DiagnosticsResponse struct {
+	Runtime RuntimeDiagnostics `json:"runtime"`
+	Loader  model.Diagnostics  `json:"loader"`
+}
+
+// DiagnosticsEndpoint dumps the state of the runtime and of the model loader, to debug the leaks and the stuck loads
+func DiagnosticsEndpoint(o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+		return c.JSON(DiagnosticsResponse{
+			Runtime: RuntimeDiagnostics{
+				Goroutines: runtime.NumGoroutine(),
+				HeapAlloc:  mem.HeapAlloc,
+				HeapInuse:  mem.HeapInuse,
+				Sys:        mem.Sys,
+				NumGC:      mem.NumGC,
+				GOMAXPROCS: runtime.GOMAXPROCS(0),
+			},
+			Loader: o.Loader.Diagnostics(),
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package localai
+
+import (
+	"fmt"
+
+	"github.com/go-skynet/LocalAI/api/backend"
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/go-skynet/LocalAI/pkg/embeddings"
+	"github.com/gofiber/fiber/v2"
+)
+
+// EnsembleEmbeddingsEndpoint embeds the input with all the models of an ensemble,
+// and returns the embeddings of each model along with their fusion
+func EnsembleEmbeddingsEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		input := new(schema.EnsembleEmbeddingsRequest)
+		if err := c.BodyParser(input); err != nil {
+			return err
+		}
+
+		ensemble := config.Ensemble{Models: input.Models}
+		if input.Model != "" {
+			cfg, err := config.Load(input.Model, o.Loader.ModelPath, cm, false, o.Threads, o.ContextSize, o.F16)
+			if err != nil {
+				return err
+			}
+			if len(cfg.Ensemble.Models) == 0 {
+				return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("model %q is not an ensemble", input.Model))
+			}
+			ensemble = cfg.Ensemble
+		}
+		if input.Fusion != "" {
+			ensemble.Fusion = input.Fusion
+		}
+		if ensemble.Fusion == "" {
+			ensemble.Fusion = embeddings.Concat
+		}
+		if len(ensemble.Models) == 0 {
+			return fiber.NewError(fiber.StatusBadRequest, "an ensemble model or a list of models is required")
+		}
+
+		inputs := []string{}
+		switch i := input.Input.(type) {
+		case string:
+			inputs = append(inputs, i)
+		case []interface{}:
+			for _, s := range i {
+				str, ok := s.(string)
+				if !ok {
+					return fiber.NewError(fiber.StatusBadRequest, "the input must be a string or a list of strings")
+				}
+				inputs = append(inputs, str)
+			}
+		default:
+			return fiber.NewError(fiber.StatusBadRequest, "the input must be a string or a list of strings")
+		}
+
+		resp := schema.EnsembleEmbeddingsResponse{
+			Object: "list",
+			Model:  input.Model,
+			Fusion: ensemble.Fusion,
+			Data:   []schema.EnsembleEmbedding{},
+		}
+		for i, s := range inputs {
+			vectors, err := backend.ModelEnsembleEmbedding(s, ensemble, cm, o)
+			if err != nil {
+				return err
+			}
+
+			item := schema.EnsembleEmbedding{Object: "embedding", Index: i}
+			for j, v := range vectors {
+				item.Embeddings = append(item.Embeddings, schema.EnsembleModelEmbedding{Model: ensemble.Models[j].Model, Embedding: v})
+			}
+			if ensemble.Fusion != embeddings.None {
+				if item.Embedding, err = backend.FuseEnsembleEmbedding(ensemble, vectors); err != nil {
+					return fiber.NewError(fiber.StatusBadRequest, err.Error())
+				}
+			}
+			resp.Data = append(resp.Data, item)
+		}
+		return c.JSON(resp)
+	}
+}
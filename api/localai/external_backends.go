@@ -0,0 +1,58 @@
+package localai
+
+import (
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type ExternalBackendRequest struct {
+	Name string `json:"name" yaml:"name"`
+	URI  string `json:"uri" yaml:"uri"`
+}
+
+// ListExternalBackendsEndpoint lists the external backends, both given at startup and registered at runtime
+func ListExternalBackendsEndpoint(o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		backends := map[string]string{}
+		for k, v := range o.ExternalGRPCBackends {
+			backends[k] = v
+		}
+		for k, v := range o.Loader.ListExternalBackends() {
+			backends[k] = v
+		}
+		return c.JSON(backends)
+	}
+}
+
+func RegisterExternalBackendEndpoint(o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		input := new(ExternalBackendRequest)
+		// Get input data from the request body
+		if err := c.BodyParser(input); err != nil {
+			return err
+		}
+
+		if err := o.Loader.RegisterExternalBackend(input.Name, input.URI); err != nil {
+			return err
+		}
+		log.Info().Msgf("Registered external backend %s: %s", input.Name, input.URI)
+		return c.JSON(input)
+	}
+}
+
+func UnregisterExternalBackendEndpoint(o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		input := new(ExternalBackendRequest)
+		// Get input data from the request body
+		if err := c.BodyParser(input); err != nil {
+			return err
+		}
+
+		if err := o.Loader.UnregisterExternalBackend(input.Name); err != nil {
+			return err
+		}
+		log.Info().Msgf("Unregistered external backend %s", input.Name)
+		return c.Send(nil)
+	}
+}
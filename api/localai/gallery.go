@@ -2,16 +2,19 @@ package localai
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
-	"slices"
+	"reflect"
 	"strings"
 	"sync"
+	"time"
 
 	json "github.com/json-iterator/go"
 	"gopkg.in/yaml.v3"
 
 	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
 	"github.com/go-skynet/LocalAI/pkg/gallery"
 	"github.com/go-skynet/LocalAI/pkg/utils"
 
@@ -35,24 +38,53 @@ type galleryOpStatus struct {
 	Progress           float64 `json:"progress"`
 	TotalFileSize      string  `json:"file_size"`
 	DownloadedFileSize string  `json:"downloaded_size"`
+	// SmokeTests are the results of the smoke tests of the models installed, which define some
+	SmokeTests []SmokeTestResponse `json:"smoke_tests,omitempty"`
 }
 
 type galleryApplier struct {
 	modelPath string
 	sync.Mutex
-	C        chan galleryOp
-	statuses map[string]*galleryOpStatus
+	C           chan galleryOp
+	statuses    map[string]*galleryOpStatus
+	installOpts []gallery.InstallOption
+	smokeTests  *SmokeTester
 }
 
-func NewGalleryService(modelPath string) *galleryApplier {
+func NewGalleryService(modelPath string, installOpts ...gallery.InstallOption) *galleryApplier {
 	return &galleryApplier{
-		modelPath: modelPath,
-		C:         make(chan galleryOp),
-		statuses:  make(map[string]*galleryOpStatus),
+		modelPath:   modelPath,
+		C:           make(chan galleryOp),
+		statuses:    make(map[string]*galleryOpStatus),
+		installOpts: installOpts,
 	}
 }
 
-func prepareModel(modelPath string, req gallery.GalleryModel, cm *config.ConfigLoader, downloadStatus func(string, string, string, float64)) error {
+// SetSmokeTester runs the smoke tests of the models once they are installed
+func (g *galleryApplier) SetSmokeTester(t *SmokeTester) {
+	g.smokeTests = t
+}
+
+// changedConfigs returns the names of the models whose configs differ from the ones before
+func changedConfigs(cm *config.ConfigLoader, before map[string]config.Config) []string {
+	changed := []string{}
+	for _, c := range cm.GetAllConfigs() {
+		if old, ok := before[c.Name]; !ok || !reflect.DeepEqual(old, c) {
+			changed = append(changed, c.Name)
+		}
+	}
+	return changed
+}
+
+func configsByName(cm *config.ConfigLoader) map[string]config.Config {
+	configs := map[string]config.Config{}
+	for _, c := range cm.GetAllConfigs() {
+		configs[c.Name] = c
+	}
+	return configs
+}
+
+func prepareModel(modelPath string, req gallery.GalleryModel, cm *config.ConfigLoader, downloadStatus func(string, string, string, float64), installOpts ...gallery.InstallOption) error {
 
 	config, err := gallery.GetGalleryConfigFromURL(req.URL)
 	if err != nil {
@@ -61,7 +93,7 @@ func prepareModel(modelPath string, req gallery.GalleryModel, cm *config.ConfigL
 
 	config.Files = append(config.Files, req.AdditionalFiles...)
 
-	return gallery.InstallModel(modelPath, req.Name, &config, req.Overrides, downloadStatus)
+	return gallery.InstallModel(modelPath, req.Name, &config, req.Overrides, downloadStatus, installOpts...)
 }
 
 func (g *galleryApplier) updateStatus(s string, op *galleryOpStatus) {
@@ -110,12 +142,12 @@ func (g *galleryApplier) Start(c context.Context, cm *config.ConfigLoader) {
 				// if the request contains a gallery name, we apply the gallery from the gallery list
 				if op.galleryName != "" {
 					if strings.Contains(op.galleryName, "@") {
-						err = gallery.InstallModelFromGallery(op.galleries, op.galleryName, g.modelPath, op.req, progressCallback)
+						err = gallery.InstallModelFromGallery(op.galleries, op.galleryName, g.modelPath, op.req, progressCallback, g.installOpts...)
 					} else {
-						err = gallery.InstallModelFromGalleryByName(op.galleries, op.galleryName, g.modelPath, op.req, progressCallback)
+						err = gallery.InstallModelFromGalleryByName(op.galleries, op.galleryName, g.modelPath, op.req, progressCallback, g.installOpts...)
 					}
 				} else {
-					err = prepareModel(g.modelPath, op.req, cm, progressCallback)
+					err = prepareModel(g.modelPath, op.req, cm, progressCallback, g.installOpts...)
 				}
 
 				if err != nil {
@@ -124,6 +156,7 @@ func (g *galleryApplier) Start(c context.Context, cm *config.ConfigLoader) {
 				}
 
 				// Reload models
+				before := configsByName(cm)
 				err = cm.LoadConfigs(g.modelPath)
 				if err != nil {
 					updateError(err)
@@ -136,37 +169,69 @@ func (g *galleryApplier) Start(c context.Context, cm *config.ConfigLoader) {
 					continue
 				}
 
-				g.updateStatus(op.id, &galleryOpStatus{Processed: true, Message: "completed", Progress: 100})
+				if g.smokeTests == nil {
+					g.updateStatus(op.id, &galleryOpStatus{Processed: true, Message: "completed", Progress: 100})
+					continue
+				}
+
+				// the smoke tests query the models, they don't hold the next operations
+				g.updateStatus(op.id, &galleryOpStatus{Message: "running the smoke tests", Progress: 100})
+				go g.runSmokeTests(op.id, changedConfigs(cm, before))
 			}
 		}
 	}()
 }
 
+// runSmokeTests runs the smoke tests of the models installed by the operation, and completes its status with the results
+func (g *galleryApplier) runSmokeTests(id string, models []string) {
+	status := &galleryOpStatus{Processed: true, Message: "completed", Progress: 100}
+	status.SmokeTests = g.smokeTests.RunChanged(models...)
+	for _, r := range status.SmokeTests {
+		if !r.Passed {
+			status.Message = "completed, smoke tests failed"
+		}
+	}
+	g.updateStatus(id, status)
+}
+
 type galleryModel struct {
 	gallery.GalleryModel `yaml:",inline"` // https://github.com/go-yaml/yaml/issues/63
 	ID                   string           `json:"id"`
 }
 
-func processRequests(modelPath, s string, cm *config.ConfigLoader, galleries []gallery.Gallery, requests []galleryModel) error {
-	var err error
+// processRequests installs the models of the requests, going on after the failures, and returns the error of each
+// model which failed
+func processRequests(modelPath, s string, cm *config.ConfigLoader, galleries []gallery.Gallery, requests []galleryModel, installOpts ...gallery.InstallOption) error {
+	errs := []error{}
 	for _, r := range requests {
+		var err error
 		utils.ResetDownloadTimers()
 		if r.ID == "" {
-			err = prepareModel(modelPath, r.GalleryModel, cm, utils.DisplayDownloadFunction)
+			err = prepareModel(modelPath, r.GalleryModel, cm, utils.DisplayDownloadFunction, installOpts...)
 		} else {
 			if strings.Contains(r.ID, "@") {
 				err = gallery.InstallModelFromGallery(
-					galleries, r.ID, modelPath, r.GalleryModel, utils.DisplayDownloadFunction)
+					galleries, r.ID, modelPath, r.GalleryModel, utils.DisplayDownloadFunction, installOpts...)
 			} else {
 				err = gallery.InstallModelFromGalleryByName(
-					galleries, r.ID, modelPath, r.GalleryModel, utils.DisplayDownloadFunction)
+					galleries, r.ID, modelPath, r.GalleryModel, utils.DisplayDownloadFunction, installOpts...)
 			}
 		}
+		if err != nil {
+			name := r.ID
+			if name == "" {
+				name = r.Name
+			}
+			if name == "" {
+				name = r.URL
+			}
+			errs = append(errs, fmt.Errorf("failed installing %s: %w", name, err))
+		}
 	}
-	return err
+	return errors.Join(errs...)
 }
 
-func ApplyGalleryFromFile(modelPath, s string, cm *config.ConfigLoader, galleries []gallery.Gallery) error {
+func ApplyGalleryFromFile(modelPath, s string, cm *config.ConfigLoader, galleries []gallery.Gallery, installOpts ...gallery.InstallOption) error {
 	dat, err := os.ReadFile(s)
 	if err != nil {
 		return err
@@ -177,25 +242,37 @@ func ApplyGalleryFromFile(modelPath, s string, cm *config.ConfigLoader, gallerie
 		return err
 	}
 
-	return processRequests(modelPath, s, cm, galleries, requests)
+	return processRequests(modelPath, s, cm, galleries, requests, installOpts...)
 }
 
-func ApplyGalleryFromString(modelPath, s string, cm *config.ConfigLoader, galleries []gallery.Gallery) error {
+func ApplyGalleryFromString(modelPath, s string, cm *config.ConfigLoader, galleries []gallery.Gallery, installOpts ...gallery.InstallOption) error {
 	var requests []galleryModel
 	err := json.Unmarshal([]byte(s), &requests)
 	if err != nil {
 		return err
 	}
 
-	return processRequests(modelPath, s, cm, galleries, requests)
+	return processRequests(modelPath, s, cm, galleries, requests, installOpts...)
 }
 
 /// Endpoint Service
 
 type ModelGalleryService struct {
-	galleries      []gallery.Gallery
+	sync.Mutex
+	// options hold the galleries, shared with the bootstrap and the config API
+	options        *options.Option
 	modelPath      string
 	galleryApplier *galleryApplier
+
+	// models of the galleries, refreshed periodically
+	models          []*gallery.GalleryModel
+	modelsRefreshed time.Time
+	// modelsGalleries are the galleries the models were fetched from
+	modelsGalleries []gallery.Gallery
+
+	// updates found by the last check of the galleries
+	updates   []modelUpdate
+	lastCheck time.Time
 }
 
 type GalleryModel struct {
@@ -203,14 +280,19 @@ type GalleryModel struct {
 	gallery.GalleryModel
 }
 
-func CreateModelGalleryService(galleries []gallery.Gallery, modelPath string, galleryApplier *galleryApplier) ModelGalleryService {
-	return ModelGalleryService{
-		galleries:      galleries,
-		modelPath:      modelPath,
+func CreateModelGalleryService(o *options.Option, galleryApplier *galleryApplier) *ModelGalleryService {
+	return &ModelGalleryService{
+		options:        o,
+		modelPath:      o.Loader.ModelPath,
 		galleryApplier: galleryApplier,
 	}
 }
 
+// getGalleries returns a copy of the galleries, which can be changed with the API, the bootstrap and the config API
+func (mgs *ModelGalleryService) getGalleries() []gallery.Gallery {
+	return mgs.options.GetGalleries()
+}
+
 func (mgs *ModelGalleryService) GetOpStatusEndpoint() func(c *fiber.Ctx) error {
 	return func(c *fiber.Ctx) error {
 		status := mgs.galleryApplier.getStatus(c.Params("uuid"))
@@ -243,7 +325,7 @@ func (mgs *ModelGalleryService) ApplyModelGalleryEndpoint() func(c *fiber.Ctx) e
 			req:         input.GalleryModel,
 			id:          uuid.String(),
 			galleryName: input.ID,
-			galleries:   mgs.galleries,
+			galleries:   mgs.getGalleries(),
 		}
 		return c.JSON(struct {
 			ID        string `json:"uuid"`
@@ -254,9 +336,10 @@ func (mgs *ModelGalleryService) ApplyModelGalleryEndpoint() func(c *fiber.Ctx) e
 
 func (mgs *ModelGalleryService) ListModelFromGalleryEndpoint() func(c *fiber.Ctx) error {
 	return func(c *fiber.Ctx) error {
-		log.Debug().Msgf("Listing models from galleries: %+v", mgs.galleries)
+		galleries := mgs.getGalleries()
+		log.Debug().Msgf("Listing models from galleries: %+v", galleries)
 
-		models, err := gallery.AvailableGalleryModels(mgs.galleries, mgs.modelPath)
+		models, err := gallery.AvailableGalleryModels(galleries, mgs.modelPath)
 		if err != nil {
 			return err
 		}
@@ -275,8 +358,9 @@ func (mgs *ModelGalleryService) ListModelFromGalleryEndpoint() func(c *fiber.Ctx
 // NOTE: This is different (and much simpler!) than above! This JUST lists the model galleries that have been loaded, not their contents!
 func (mgs *ModelGalleryService) ListModelGalleriesEndpoint() func(c *fiber.Ctx) error {
 	return func(c *fiber.Ctx) error {
-		log.Debug().Msgf("Listing model galleries %+v", mgs.galleries)
-		dat, err := json.Marshal(mgs.galleries)
+		galleries := mgs.getGalleries()
+		log.Debug().Msgf("Listing model galleries %+v", galleries)
+		dat, err := json.Marshal(galleries)
 		if err != nil {
 			return err
 		}
@@ -291,17 +375,14 @@ func (mgs *ModelGalleryService) AddModelGalleryEndpoint() func(c *fiber.Ctx) err
 		if err := c.BodyParser(input); err != nil {
 			return err
 		}
-		if slices.ContainsFunc(mgs.galleries, func(gallery gallery.Gallery) bool {
-			return gallery.Name == input.Name
-		}) {
-			return fmt.Errorf("%s already exists", input.Name)
-		}
-		dat, err := json.Marshal(mgs.galleries)
+		dat, err := json.Marshal(mgs.getGalleries())
 		if err != nil {
 			return err
 		}
-		log.Debug().Msgf("Adding %+v to gallery list", *input)
-		mgs.galleries = append(mgs.galleries, *input)
+		if !mgs.options.AddGallery(*input) {
+			return fmt.Errorf("%s already exists", input.Name)
+		}
+		log.Debug().Msgf("Added %+v to gallery list", *input)
 		return c.Send(dat)
 	}
 }
@@ -313,14 +394,9 @@ func (mgs *ModelGalleryService) RemoveModelGalleryEndpoint() func(c *fiber.Ctx)
 		if err := c.BodyParser(input); err != nil {
 			return err
 		}
-		if !slices.ContainsFunc(mgs.galleries, func(gallery gallery.Gallery) bool {
-			return gallery.Name == input.Name
-		}) {
+		if !mgs.options.RemoveGallery(input.Name) {
 			return fmt.Errorf("%s is not currently registered", input.Name)
 		}
-		mgs.galleries = slices.DeleteFunc(mgs.galleries, func(gallery gallery.Gallery) bool {
-			return gallery.Name == input.Name
-		})
 		return c.Send(nil)
 	}
 }
@@ -0,0 +1,102 @@
+package localai
+
+import (
+	"slices"
+	"time"
+
+	"github.com/go-skynet/LocalAI/pkg/gallery"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// modelsTTL is how long the models of the galleries are cached when they are not refreshed periodically
+const modelsTTL = time.Hour
+
+type searchModel struct {
+	*gallery.GalleryModel
+	ID           string   `json:"id"`
+	Capabilities []string `json:"capabilities"`
+	Quantization string   `json:"quantization,omitempty"`
+}
+
+type searchModelsResponse struct {
+	Object string        `json:"object"`
+	Data   []searchModel `json:"data"`
+	Total  int           `json:"total"`
+	Offset int           `json:"offset"`
+	Limit  int           `json:"limit"`
+	// RefreshedAt is when the models of the galleries were last fetched
+	RefreshedAt time.Time `json:"refreshed_at"`
+}
+
+// RefreshModels fetches the models of the galleries, and caches them for the searches
+func (mgs *ModelGalleryService) RefreshModels() ([]*gallery.GalleryModel, error) {
+	galleries := mgs.getGalleries()
+	models, err := gallery.AvailableGalleryModels(galleries, mgs.modelPath)
+	if err != nil {
+		return nil, err
+	}
+	mgs.Lock()
+	mgs.models = models
+	mgs.modelsRefreshed = time.Now()
+	mgs.modelsGalleries = galleries
+	mgs.Unlock()
+	return models, nil
+}
+
+// cachedModels returns the cached models of the galleries, fetching them if they were never fetched, are outdated or
+// the galleries changed since
+func (mgs *ModelGalleryService) cachedModels() ([]*gallery.GalleryModel, time.Time, error) {
+	mgs.Lock()
+	models, refreshed, galleries := mgs.models, mgs.modelsRefreshed, mgs.modelsGalleries
+	mgs.Unlock()
+	if models != nil && time.Since(refreshed) < modelsTTL && slices.Equal(galleries, mgs.getGalleries()) {
+		return models, refreshed, nil
+	}
+
+	models, err := mgs.RefreshModels()
+	return models, time.Now(), err
+}
+
+// SearchModelsEndpoint searches the models of the galleries by name, tag, capability, quantization and size, with pagination
+func (mgs *ModelGalleryService) SearchModelsEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		q := gallery.SearchQuery{
+			Name:         c.Query("name"),
+			Tag:          c.Query("tag"),
+			Capability:   c.Query("capability"),
+			Quantization: c.Query("quantization"),
+			MinSize:      int64(c.QueryInt("min_size")),
+			MaxSize:      int64(c.QueryInt("max_size")),
+			Offset:       c.QueryInt("offset"),
+			Limit:        c.QueryInt("limit", 20),
+		}
+		if q.Offset < 0 || q.Limit < 0 {
+			return fiber.NewError(fiber.StatusBadRequest, "offset and limit must be positive")
+		}
+
+		models, refreshed, err := mgs.cachedModels()
+		if err != nil {
+			return err
+		}
+
+		page, total := gallery.SearchGalleryModels(models, q, mgs.modelPath)
+		resp := searchModelsResponse{
+			Object:      "list",
+			Data:        make([]searchModel, 0, len(page)),
+			Total:       total,
+			Offset:      q.Offset,
+			Limit:       q.Limit,
+			RefreshedAt: refreshed,
+		}
+		for _, m := range page {
+			resp.Data = append(resp.Data, searchModel{
+				GalleryModel: m,
+				ID:           m.Gallery.Name + "@" + m.Name,
+				Capabilities: gallery.Capabilities(m),
+				Quantization: gallery.Quantization(m),
+			})
+		}
+		return c.JSON(resp)
+	}
+}
@@ -0,0 +1,141 @@
+package localai
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-skynet/LocalAI/pkg/gallery"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type modelUpdate struct {
+	gallery.ModelUpdate
+	// Job is the ID of the job installing the update, when it is installed automatically
+	Job string `json:"job,omitempty"`
+}
+
+type modelUpdates struct {
+	LastCheck *time.Time    `json:"last_check,omitempty"`
+	Updates   []modelUpdate `json:"updates"`
+}
+
+type UpdatePolicyRequest struct {
+	Name   string `json:"name"`
+	Policy string `json:"policy"`
+}
+
+// CheckUpdates refreshes the galleries to find the updates of the installed models,
+// and installs the ones allowed by the update policy of their model
+func (mgs *ModelGalleryService) CheckUpdates() ([]modelUpdate, error) {
+	galleries := mgs.getGalleries()
+	models, err := mgs.RefreshModels()
+	if err != nil {
+		return nil, err
+	}
+	installed, err := gallery.ReadInstalledModels(mgs.modelPath)
+	if err != nil {
+		return nil, err
+	}
+	found := gallery.FindUpdates(installed, models, mgs.modelPath)
+
+	updates := []modelUpdate{}
+	for _, u := range found {
+		update := modelUpdate{ModelUpdate: u}
+		if u.Automatic {
+			id, err := uuid.NewUUID()
+			if err != nil {
+				return nil, err
+			}
+			update.Job = id.String()
+			req := u.Request
+			req.Name = u.Name
+			op := galleryOp{
+				req:         req,
+				id:          update.Job,
+				galleryName: u.ID,
+				galleries:   galleries,
+			}
+			// the installations are processed one at a time, don't wait for the previous ones
+			go func() { mgs.galleryApplier.C <- op }()
+			log.Info().Msgf("Updating model %s from version %q to %q (policy %s)", u.Name, u.InstalledVersion, u.Version, u.Policy)
+		} else {
+			log.Info().Msgf("Update available for model %s: version %q (installed %q)", u.Name, u.Version, u.InstalledVersion)
+		}
+		updates = append(updates, update)
+	}
+
+	mgs.Lock()
+	mgs.updates = updates
+	mgs.lastCheck = time.Now()
+	mgs.Unlock()
+	return updates, nil
+}
+
+// StartGalleriesRefresh refreshes the galleries and checks the updates of the installed models periodically, until the context is done
+func (mgs *ModelGalleryService) StartGalleriesRefresh(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := mgs.CheckUpdates(); err != nil {
+					log.Error().Err(err).Msg("failed to check the updates of the models")
+				}
+			}
+		}
+	}()
+}
+
+// ListUpdatesEndpoint returns the updates found by the last check
+func (mgs *ModelGalleryService) ListUpdatesEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		mgs.Lock()
+		defer mgs.Unlock()
+		resp := modelUpdates{Updates: mgs.updates}
+		if resp.Updates == nil {
+			resp.Updates = []modelUpdate{}
+		}
+		if !mgs.lastCheck.IsZero() {
+			lastCheck := mgs.lastCheck
+			resp.LastCheck = &lastCheck
+		}
+		return c.JSON(resp)
+	}
+}
+
+// CheckUpdatesEndpoint checks the updates right away
+func (mgs *ModelGalleryService) CheckUpdatesEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		updates, err := mgs.CheckUpdates()
+		if err != nil {
+			return err
+		}
+		mgs.Lock()
+		lastCheck := mgs.lastCheck
+		mgs.Unlock()
+		return c.JSON(modelUpdates{LastCheck: &lastCheck, Updates: updates})
+	}
+}
+
+// UpdatePolicyEndpoint sets the update policy of an installed model
+func (mgs *ModelGalleryService) UpdatePolicyEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		input := new(UpdatePolicyRequest)
+		if err := c.BodyParser(input); err != nil {
+			return err
+		}
+		if err := gallery.SetUpdatePolicy(mgs.modelPath, input.Name, input.Policy); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		return c.JSON(input)
+	}
+}
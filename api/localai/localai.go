@@ -4,6 +4,7 @@ import (
 	"github.com/go-skynet/LocalAI/api/backend"
 	config "github.com/go-skynet/LocalAI/api/config"
 	fiberContext "github.com/go-skynet/LocalAI/api/ctx"
+	"github.com/go-skynet/LocalAI/pkg/ssml"
 	"github.com/rs/zerolog/log"
 
 	"github.com/go-skynet/LocalAI/api/options"
@@ -14,6 +15,8 @@ type TTSRequest struct {
 	Model   string `json:"model" yaml:"model"`
 	Input   string `json:"input" yaml:"input"`
 	Backend string `json:"backend" yaml:"backend"`
+	// Voice is a voice of the TTS model, the one of the model when empty
+	Voice string `json:"voice" yaml:"voice"`
 }
 
 func TTSEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx) error {
@@ -25,8 +28,13 @@ func TTSEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx)
 		if err := c.BodyParser(input); err != nil {
 			return err
 		}
+		if ssml.IsSSML(input.Input) {
+			if _, err := ssml.Parse(input.Input); err != nil {
+				return fiber.NewError(fiber.StatusBadRequest, err.Error())
+			}
+		}
 
-		modelFile, err := fiberContext.ModelFromContext(c, o.Loader, input.Model, false)
+		modelFile, err := fiberContext.ModelFromContext(c, cm, o.Loader, input.Model, false)
 		if err != nil {
 			modelFile = input.Model
 			log.Warn().Msgf("Model not found in context: %s", input.Model)
@@ -41,13 +49,22 @@ func TTSEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx)
 		log.Debug().Msgf("Request for model: %s", modelFile)
 
 		if input.Backend != "" {
-			cfg.Backend = input.Input
+			cfg.Backend = input.Backend
+		}
+		voice, err := cfg.Voice(input.Voice)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 
-		filePath, _, err := backend.ModelTTS(cfg.Backend, input.Input, modelFile, o.Loader, o, *cfg)
+		filePath, _, err := backend.ModelTTS(input.Input, voice, o.Loader, o, *cfg)
 		if err != nil {
 			return err
 		}
+		if r := fiberContext.RequestFromCtx(c); r != nil {
+			if d, err := backend.AudioDuration(filePath); err == nil {
+				r.AddAudio(d.Seconds())
+			}
+		}
 		return c.Download(filePath)
 	}
 }
@@ -0,0 +1,83 @@
+package localai
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/logstream"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+)
+
+// the interval of the comments sent to the clients while there are no logs, to find the ones which disconnected
+const logsKeepalive = 15 * time.Second
+
+// LogsEndpoint streams the logs of LocalAI and of the backends as server-sent events, starting with the last ones.
+// The logs are filtered with the minimum level, the components (comma separated) and the model of the query.
+func LogsEndpoint(o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		filter := logstream.Filter{Level: zerolog.DebugLevel, Model: c.Query("model")}
+		if l := c.Query("level"); l != "" {
+			level, err := zerolog.ParseLevel(l)
+			if err != nil {
+				return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("invalid level %q", l))
+			}
+			filter.Level = level
+		}
+		if components := c.Query("component"); components != "" {
+			filter.Components = strings.Split(components, ",")
+		}
+		tail := 100
+		if t := c.Query("tail"); t != "" {
+			n, err := strconv.Atoi(t)
+			if err != nil || n < 0 {
+				return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("invalid tail %q", t))
+			}
+			tail = n
+		}
+
+		last, entries, stop := logstream.Default.Subscribe(filter, tail)
+		c.Set("Content-Type", "text/event-stream")
+		c.Set("Cache-Control", "no-cache")
+		c.Set("Connection", "keep-alive")
+		c.Context().SetBodyStreamWriter(fasthttp.StreamWriter(func(w *bufio.Writer) {
+			defer stop()
+			send := func(e logstream.Entry) error {
+				dat, _ := json.Marshal(e)
+				if _, err := fmt.Fprintf(w, "data: %s\n\n", dat); err != nil {
+					return err
+				}
+				return w.Flush()
+			}
+			for _, e := range last {
+				if send(e) != nil {
+					return
+				}
+			}
+
+			keepalive := time.NewTicker(logsKeepalive)
+			defer keepalive.Stop()
+			for {
+				select {
+				case e := <-entries:
+					if send(e) != nil {
+						return
+					}
+				case <-keepalive.C:
+					if _, err := w.WriteString(": keepalive\n\n"); err != nil || w.Flush() != nil {
+						return
+					}
+				case <-o.Context.Done():
+					return
+				}
+			}
+		}))
+		return nil
+	}
+}
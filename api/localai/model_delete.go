@@ -0,0 +1,178 @@
+package localai
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/gallery"
+	"github.com/go-skynet/LocalAI/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type DeletedFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+type DeleteModelResponse struct {
+	Model string `json:"model"`
+	// Unloaded is true if the backend of the model was loaded, and stopped
+	Unloaded bool          `json:"unloaded"`
+	Files    []DeletedFile `json:"files"`
+	// Freed is the size of the deleted files, in bytes
+	Freed  int64 `json:"freed"`
+	DryRun bool  `json:"dry_run"`
+}
+
+// hfCacheDir returns the directory of the Hugging Face cache where the backends download the repository
+func hfCacheDir(repo string) string {
+	cache := os.Getenv("HF_HUB_CACHE")
+	if cache == "" {
+		if home := os.Getenv("HF_HOME"); home != "" {
+			cache = filepath.Join(home, "hub")
+		} else if home, err := os.UserHomeDir(); err == nil {
+			cache = filepath.Join(home, ".cache", "huggingface", "hub")
+		}
+	}
+	return filepath.Join(cache, "models--"+strings.ReplaceAll(repo, "/", "--"))
+}
+
+// modelArtifacts returns the files of the model in the models path: its weights, projectors, adapters, templates and
+// prompt cache, and the directories of the Hugging Face repositories the backends downloaded for it (e.g. tokenizers)
+func modelArtifacts(c config.Config, modelPath string) []string {
+	refs := []string{c.Model, c.MMProj, c.LoraAdapter, c.LoraBase, c.DraftModel, c.Tokenizer, c.PromptCachePath}
+	for _, f := range c.DownloadFiles {
+		refs = append(refs, f.Filename)
+	}
+	for _, t := range []string{c.TemplateConfig.Chat, c.TemplateConfig.ChatMessage, c.TemplateConfig.Completion, c.TemplateConfig.Edit, c.TemplateConfig.Functions, c.Model} {
+		if t != "" {
+			refs = append(refs, t+".tmpl")
+		}
+	}
+
+	paths := []string{}
+	for _, r := range refs {
+		if r == "" || filepath.IsAbs(r) {
+			continue
+		}
+		if utils.VerifyPath(r, modelPath) == nil {
+			if _, err := os.Stat(filepath.Join(modelPath, r)); err == nil {
+				paths = append(paths, filepath.Join(modelPath, r))
+				continue
+			}
+		}
+		// the names of Hugging Face repositories are owner/name
+		if strings.Count(r, "/") == 1 && !strings.HasPrefix(r, ".") {
+			if _, err := os.Stat(hfCacheDir(r)); err == nil {
+				paths = append(paths, hfCacheDir(r))
+			}
+		}
+	}
+	return paths
+}
+
+func diskUsage(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && !d.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// DeleteModelEndpoint deletes a model: its backend is stopped, and its config file removed. With files=true, the files
+// of the model which are not used by other models are deleted as well, and with dry_run=true nothing is changed.
+func DeleteModelEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		name := c.Params("name")
+		deleteFiles := c.QueryBool("files", false)
+		dryRun := c.QueryBool("dry_run", false)
+
+		configMu.Lock()
+		defer configMu.Unlock()
+
+		modelPath := o.Loader.ModelPath
+		cfg, configured := cm.GetConfig(name)
+		if !configured {
+			// a model file without config
+			if utils.VerifyPath(name, modelPath) != nil || !existsFile(filepath.Join(modelPath, name)) {
+				return fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("model %s not found", name))
+			}
+			cfg = config.Config{Name: name, PredictionOptions: config.PredictionOptions{Model: name}}
+		}
+
+		paths := []string{}
+		if file, ok := configFiles(modelPath)[name]; ok {
+			paths = append(paths, file)
+		} else if configured {
+			return fiber.NewError(fiber.StatusForbidden, fmt.Sprintf("the config file of %s is not in the models path %s, it cannot be deleted", name, modelPath))
+		}
+		if deleteFiles || !configured {
+			// the files used by other models are kept
+			used := map[string]bool{}
+			for _, other := range cm.GetAllConfigs() {
+				if other.Name != name {
+					for _, p := range modelArtifacts(other, modelPath) {
+						used[p] = true
+					}
+				}
+			}
+			for _, p := range modelArtifacts(cfg, modelPath) {
+				if used[p] {
+					log.Debug().Msgf("Keeping %s, as it is used by other models", p)
+					continue
+				}
+				if !slices.Contains(paths, p) {
+					paths = append(paths, p)
+				}
+			}
+		}
+		sort.Strings(paths)
+
+		resp := DeleteModelResponse{Model: name, Files: []DeletedFile{}, DryRun: dryRun}
+		for _, p := range paths {
+			f := DeletedFile{Path: p, Size: diskUsage(p)}
+			resp.Files = append(resp.Files, f)
+			resp.Freed += f.Size
+		}
+		_, resp.Unloaded = o.Loader.LoadedBackend(cfg.Model)
+		if dryRun {
+			return c.JSON(resp)
+		}
+
+		if resp.Unloaded {
+			if err := o.Loader.ShutdownModel(cfg.Model); err != nil {
+				return fmt.Errorf("failed unloading model %s: %w", name, err)
+			}
+		}
+		cm.RemoveConfig(name)
+		for _, f := range resp.Files {
+			if err := os.RemoveAll(f.Path); err != nil {
+				return fmt.Errorf("failed deleting %s: %w", f.Path, err)
+			}
+		}
+		if err := gallery.ForgetInstalledModel(modelPath, name); err != nil {
+			log.Warn().Msgf("Failed removing %s from the models installed from the galleries: %s", name, err.Error())
+		}
+		log.Info().Msgf("Deleted model %s, freeing %d bytes", name, resp.Freed)
+		return c.JSON(resp)
+	}
+}
+
+func existsFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
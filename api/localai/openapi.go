@@ -0,0 +1,84 @@
+package localai
+
+import (
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/go-skynet/LocalAI/pkg/apikeys"
+	"github.com/go-skynet/LocalAI/pkg/gallery"
+	"github.com/go-skynet/LocalAI/pkg/logstream"
+	"github.com/go-skynet/LocalAI/pkg/model"
+	"github.com/go-skynet/LocalAI/pkg/openapi"
+	"github.com/go-skynet/LocalAI/pkg/vectorstore"
+)
+
+// OpenAPIOperations documents the endpoints of LocalAI which are not part of the OpenAI API
+func OpenAPIOperations() []openapi.Operation {
+	job := struct {
+		ID        string `json:"uuid"`
+		StatusURL string `json:"status"`
+	}{}
+	message := struct {
+		Message string `json:"message"`
+	}{}
+
+	ops := []openapi.Operation{
+		{Method: "POST", Path: "/models/apply", Summary: "Install a model from a gallery", Tag: "Gallery", Request: GalleryModel{}, Response: job},
+		{Method: "GET", Path: "/models/available", Summary: "List the models of the galleries", Tag: "Gallery", Response: []gallery.GalleryModel{}},
+		{Method: "GET", Path: "/models/search", Summary: "Search the models of the galleries", Tag: "Gallery", Response: searchModelsResponse{},
+			Query: []string{"name", "tag", "capability", "quantization", "min_size", "max_size", "offset", "limit"}},
+		{Method: "GET", Path: "/models/galleries", Summary: "List the galleries", Tag: "Gallery", Response: []gallery.Gallery{}},
+		{Method: "POST", Path: "/models/galleries", Summary: "Add a gallery", Tag: "Gallery", Request: gallery.Gallery{}, Response: []gallery.Gallery{}},
+		{Method: "DELETE", Path: "/models/galleries", Summary: "Remove a gallery", Tag: "Gallery", Request: gallery.Gallery{}, Response: []gallery.Gallery{}},
+		{Method: "POST", Path: "/models/quantize", Summary: "Quantize a GGUF model to another type, as a new model", Tag: "Models", Request: QuantizeRequest{}, Response: job},
+		{Method: "GET", Path: "/models/jobs/:uuid", Summary: "Get the status of an installation or a quantization", Tag: "Gallery", Response: galleryOpStatus{}},
+		{Method: "GET", Path: "/models/jobs", Summary: "List the status of the installations and the quantizations", Tag: "Gallery", Response: map[string]galleryOpStatus{}},
+		{Method: "GET", Path: "/models/updates", Summary: "List the updates of the installed models", Tag: "Gallery", Response: modelUpdates{}},
+		{Method: "POST", Path: "/models/updates/check", Summary: "Check the updates of the installed models", Tag: "Gallery", Response: modelUpdates{}},
+		{Method: "POST", Path: "/models/updates/policy", Summary: "Set the update policy of an installed model", Tag: "Gallery", Request: UpdatePolicyRequest{}, Response: UpdatePolicyRequest{}},
+		{Method: "DELETE", Path: "/models/:name", Summary: "Delete a model, and optionally its files", Tag: "Models", Response: DeleteModelResponse{}, Query: []string{"files", "dry_run"}},
+		{Method: "POST", Path: "/models/smoke-test", Summary: "Run a smoke test of a model", Tag: "Models", Request: SmokeTestRequest{}, Response: SmokeTestResponse{}},
+		{Method: "GET", Path: "/models/smoke-test", Summary: "Get the last results of the smoke tests of the models", Tag: "Models", Response: []SmokeTestResponse{}},
+		{Method: "POST", Path: "/models/rollout", Summary: "Route an alias to a model once its smoke tests pass", Tag: "Models", Request: RolloutRequest{}, Response: RolloutResponse{}},
+
+		{Method: "POST", Path: "/bootstrap", Summary: "Apply a bootstrap manifest, in YAML or JSON", Tag: "Configuration", Request: Bootstrap{}, Response: BootstrapResponse{}},
+		{Method: "POST", Path: "/config/diff", Summary: "Compare a configuration, in YAML or JSON, with the running one", Tag: "Configuration", Request: ConfigState{}, Response: ConfigDiff{}},
+		{Method: "POST", Path: "/config/apply", Summary: "Apply a configuration, in YAML or JSON", Tag: "Configuration", Request: ConfigState{}, Response: ConfigDiff{}},
+
+		{Method: "POST", Path: "/embeddings/ensemble", Summary: "Create the embeddings of several models", Tag: "Embeddings", Request: schema.EnsembleEmbeddingsRequest{}, Response: schema.EnsembleEmbeddingsResponse{}},
+		{Method: "GET", Path: "/stores", Summary: "List the collections of the vector store", Tag: "Vector store", Response: []vectorstore.Collection{}},
+		{Method: "POST", Path: "/stores", Summary: "Create a collection of vectors", Tag: "Vector store", Request: CreateStoreRequest{}, Response: vectorstore.Collection{}},
+		{Method: "GET", Path: "/stores/:name", Summary: "Get a collection of vectors", Tag: "Vector store", Response: vectorstore.Collection{}},
+		{Method: "DELETE", Path: "/stores/:name", Summary: "Delete a collection and its vectors", Tag: "Vector store", Response: message},
+		{Method: "POST", Path: "/stores/:name/upsert", Summary: "Add or replace vectors, with their metadata", Tag: "Vector store", Request: UpsertStoreRequest{}, Response: vectorstore.Collection{}},
+		{Method: "POST", Path: "/stores/:name/query", Summary: "Find the nearest vectors matching a filter", Tag: "Vector store", Request: QueryStoreRequest{}, Response: QueryStoreResponse{}},
+		{Method: "POST", Path: "/stores/:name/delete", Summary: "Delete vectors by id or by filter", Tag: "Vector store", Request: DeleteVectorsRequest{}, Response: DeleteVectorsResponse{}},
+		{Method: "POST", Path: "/v1/tokenize", Summary: "Tokenize a text", Tag: "Tokenizer", Request: schema.TokenizeRequest{}, Response: schema.TokenizeResponse{}},
+		{Method: "POST", Path: "/v1/detokenize", Summary: "Convert tokens to text", Tag: "Tokenizer", Request: schema.DetokenizeRequest{}, Response: schema.DetokenizeResponse{}},
+		{Method: "POST", Path: "/tts", Summary: "Generate speech", Tag: "Audio", Request: TTSRequest{}, ResponseType: "audio/wav"},
+
+		{Method: "GET", Path: "/healthz/backends", Summary: "Liveness probe checking the backends of the loaded models", Tag: "Monitoring", Response: BackendHealthResponse{}, Public: true},
+		{Method: "GET", Path: "/readyz/backends", Summary: "Readiness probe checking the backends of the loaded models", Tag: "Monitoring", Response: BackendHealthResponse{}, Public: true},
+		{Method: "GET", Path: "/backend/monitor", Summary: "Get the resources used by the backend of a model", Tag: "Backends", Request: BackendMonitorRequest{}, Response: BackendMonitorResponse{}, Public: true},
+		{Method: "POST", Path: "/backend/shutdown", Summary: "Stop the backend of a model", Tag: "Backends", Request: BackendMonitorRequest{}, Public: true},
+		{Method: "POST", Path: "/backend/load", Summary: "Load a model", Tag: "Backends", Request: BackendLoadRequest{}, Response: BackendLoadResponse{}},
+		{Method: "POST", Path: "/backend/unload", Summary: "Unload a model", Tag: "Backends", Request: BackendLoadRequest{}, Response: BackendLoadResponse{}},
+		{Method: "GET", Path: "/backend/external", Summary: "List the external backends", Tag: "Backends", Response: map[string]string{}},
+		{Method: "POST", Path: "/backend/external", Summary: "Register an external backend", Tag: "Backends", Request: ExternalBackendRequest{}, Response: ExternalBackendRequest{}},
+		{Method: "DELETE", Path: "/backend/external", Summary: "Unregister an external backend", Tag: "Backends", Request: ExternalBackendRequest{}},
+		{Method: "GET", Path: "/backend/prompt-cache", Summary: "Get the statistics of the prompt caches of the models", Tag: "Backends", Response: PromptCacheResponse{}},
+		{Method: "GET", Path: "/debug/diagnostics", Summary: "Get the state of the runtime and of the model loader, with the admin key", Tag: "Monitoring", Response: DiagnosticsResponse{}},
+		{Method: "GET", Path: "/debug/crashes", Summary: "List the last crash of the backend of each model, with the admin key", Tag: "Monitoring", Response: []model.Crash{}},
+		{Method: "GET", Path: "/debug/crashes/:model", Summary: "Get the last crash of the backend of a model, with the admin key", Tag: "Monitoring", Response: model.Crash{}},
+		{Method: "GET", Path: "/debug/logs", Summary: "Stream the logs of LocalAI and of the backends, with the admin key", Tag: "Monitoring", Response: logstream.Entry{}, Stream: true,
+			Query: []string{"level", "component", "model", "tail"}},
+		{Method: "GET", Path: "/backend/throughput", Summary: "Get the token throughput and latency statistics of the models", Tag: "Backends", Response: ThroughputResponse{}},
+		{Method: "GET", Path: "/usage", Summary: "Get the usage of the API keys by model over a period", Tag: "Monitoring", Response: UsageResponse{},
+			Query: []string{"from", "to", "key", "model"}},
+		{Method: "GET", Path: "/keys", Summary: "List the API keys, with an admin key", Tag: "API keys", Response: []apikeys.Key{}},
+		{Method: "POST", Path: "/keys", Summary: "Create an API key with scopes, with an admin key", Tag: "API keys", Request: CreateAPIKeyRequest{}, Response: CreateAPIKeyResponse{}},
+		{Method: "DELETE", Path: "/keys/:id", Summary: "Revoke an API key, with an admin key", Tag: "API keys", Response: message},
+	}
+	for i := range ops {
+		ops[i].Extension = true
+	}
+	return ops
+}
@@ -0,0 +1,19 @@
+package localai
+
+import (
+	"github.com/go-skynet/LocalAI/api/backend"
+	"github.com/go-skynet/LocalAI/pkg/promptcache"
+	"github.com/gofiber/fiber/v2"
+)
+
+type PromptCacheResponse struct {
+	// Statistics of the models with prefix caching enabled, by name
+	Models map[string]promptcache.Stats `json:"models"`
+}
+
+// PromptCacheEndpoint returns the statistics of the prompt caches of the models, since the start
+func PromptCacheEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(PromptCacheResponse{Models: backend.PromptCacheStats()})
+	}
+}
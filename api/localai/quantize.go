@@ -0,0 +1,137 @@
+package localai
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/gallery"
+	"github.com/go-skynet/LocalAI/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+type QuantizeRequest struct {
+	// Model is the name of the model, or its file in the models path
+	Model string `json:"model" yaml:"model"`
+	// Type is the quantization type, e.g. Q4_K_M
+	Type string `json:"type" yaml:"type"`
+	// Name is the name of the new model, defaults to the model name with the type as suffix
+	Name string `json:"name" yaml:"name"`
+}
+
+// the quantizations are run one at a time, as they use all the CPUs
+var quantizeMu sync.Mutex
+
+// QuantizeModel quantizes the GGUF file of a model to another type, and registers the quantized file as a new model,
+// with the config of the original model. It returns the name of the new model.
+func QuantizeModel(modelPath, toolPath string, threads int, req QuantizeRequest, progress func(float64)) (string, error) {
+	if req.Model == "" || req.Type == "" {
+		return "", fmt.Errorf("the model and the quantization type are required")
+	}
+	name := req.Name
+	if name == "" {
+		name = fmt.Sprintf("%s-%s", strings.TrimSuffix(filepath.Base(req.Model), ".gguf"), strings.ToLower(req.Type))
+	}
+	if strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid model name %q", name)
+	}
+	newConfigFile := filepath.Join(modelPath, name+".yaml")
+	outputFile := name + ".gguf"
+	for _, f := range []string{newConfigFile, filepath.Join(modelPath, outputFile)} {
+		if _, err := os.Stat(f); err == nil {
+			return "", fmt.Errorf("%s already exists", f)
+		}
+	}
+
+	// the model is either configured, or a file of the models path
+	file := req.Model
+	configFile, err := config.FindConfigFile(modelPath, req.Model)
+	if err == nil {
+		c, err := config.ReadConfig(configFile)
+		if err != nil {
+			return "", err
+		}
+		file = c.Model
+	}
+	if err := utils.VerifyPath(file, modelPath); err != nil {
+		return "", err
+	}
+
+	quantizeMu.Lock()
+	defer quantizeMu.Unlock()
+	if err := gallery.QuantizeModel(toolPath, filepath.Join(modelPath, file), filepath.Join(modelPath, outputFile), req.Type, threads, progress); err != nil {
+		return "", err
+	}
+
+	if configFile == "" {
+		dat, err := yaml.Marshal(map[string]interface{}{"name": name, "parameters": map[string]interface{}{"model": outputFile}})
+		if err != nil {
+			return "", err
+		}
+		return name, os.WriteFile(newConfigFile, dat, 0644)
+	}
+
+	// a copy of the config of the original model, with the quantized file
+	dat, err := os.ReadFile(configFile)
+	if err != nil {
+		return "", err
+	}
+	raw := map[string]interface{}{}
+	if err := yaml.Unmarshal(dat, &raw); err != nil {
+		return "", err
+	}
+	parameters, _ := raw["parameters"].(map[string]interface{})
+	if parameters == nil {
+		parameters = map[string]interface{}{}
+	}
+	parameters["model"] = outputFile
+	if err := os.WriteFile(newConfigFile, dat, 0644); err != nil {
+		return "", err
+	}
+	if err := config.UpdateConfigFile(newConfigFile, "name", name); err != nil {
+		return "", err
+	}
+	return name, config.UpdateConfigFile(newConfigFile, "parameters", parameters)
+}
+
+// QuantizeModelEndpoint quantizes a model in the background, the progress is reported by the jobs of the galleries
+func (mgs *ModelGalleryService) QuantizeModelEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		input := new(QuantizeRequest)
+		if err := c.BodyParser(input); err != nil {
+			return err
+		}
+		if input.Model == "" || input.Type == "" {
+			return fiber.NewError(fiber.StatusBadRequest, "the model and the quantization type are required")
+		}
+
+		id := uuid.New().String()
+		g := mgs.galleryApplier
+		g.updateStatus(id, &galleryOpStatus{Message: "processing", Progress: 0})
+		go func() {
+			progress := func(p float64) {
+				g.updateStatus(id, &galleryOpStatus{Message: "processing", FileName: input.Model, Progress: p})
+			}
+			name, err := QuantizeModel(o.Loader.ModelPath, o.QuantizeToolPath(), o.Threads, *input, progress)
+			if err == nil {
+				err = cm.LoadConfig(filepath.Join(o.Loader.ModelPath, name+".yaml"))
+			}
+			if err != nil {
+				g.updateStatus(id, &galleryOpStatus{Error: err, Processed: true, Message: "error: " + err.Error()})
+				return
+			}
+			g.updateStatus(id, &galleryOpStatus{Processed: true, Message: "completed: " + name, Progress: 100})
+		}()
+
+		return c.JSON(struct {
+			ID        string `json:"uuid"`
+			StatusURL string `json:"status"`
+		}{ID: id, StatusURL: c.BaseURL() + "/models/jobs/" + id})
+	}
+}
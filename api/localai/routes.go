@@ -0,0 +1,25 @@
+package localai
+
+import (
+	"fmt"
+	"sync"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/routing"
+)
+
+// routesMu serializes the changes of the routes (rollouts, bootstrap aliases), which read the routes, change and save them
+var routesMu sync.Mutex
+
+// setRoutes saves the routes to the routes file, if any, and routes the requests with them. It must be called with
+// routesMu held.
+func setRoutes(cm *config.ConfigLoader, o *options.Option, routes *routing.Table) error {
+	if o.ModelRoutesFile != "" {
+		if err := routes.Save(o.ModelRoutesFile); err != nil {
+			return fmt.Errorf("failed saving the routes: %w", err)
+		}
+	}
+	cm.SetRoutes(routes)
+	return nil
+}
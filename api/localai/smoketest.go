@@ -0,0 +1,198 @@
+package localai
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-skynet/LocalAI/api/backend"
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/rs/zerolog/log"
+
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SmokeTestRequest struct {
+	Model string `json:"model" yaml:"model"`
+}
+
+type SmokeTestResponse struct {
+	Model   string                    `json:"model"`
+	Passed  bool                      `json:"passed"`
+	Time    time.Time                 `json:"time"`
+	Results []backend.SmokeTestResult `json:"results"`
+}
+
+type RolloutRequest struct {
+	// Alias is the model name of the clients, routed to the model once its smoke tests pass
+	Alias string `json:"alias"`
+	Model string `json:"model"`
+}
+
+type RolloutResponse struct {
+	Alias string `json:"alias"`
+	Model string `json:"model"`
+	// Previous is the model the alias was routed to, if any
+	Previous  string            `json:"previous,omitempty"`
+	Switched  bool              `json:"switched"`
+	SmokeTest SmokeTestResponse `json:"smoke_test"`
+}
+
+// SmokeTester runs the smoke tests of the model configs, on request and when the models are installed or their
+// configs reloaded, and keeps the last results of each model
+type SmokeTester struct {
+	cm *config.ConfigLoader
+	o  *options.Option
+
+	sync.Mutex
+	results map[string]SmokeTestResponse
+}
+
+func NewSmokeTester(cm *config.ConfigLoader, o *options.Option) *SmokeTester {
+	return &SmokeTester{cm: cm, o: o, results: map[string]SmokeTestResponse{}}
+}
+
+// Run runs the smoke tests of the model and records their results
+func (t *SmokeTester) Run(model string) (SmokeTestResponse, error) {
+	cfg, exists := t.cm.GetConfig(model)
+	if !exists {
+		return SmokeTestResponse{}, fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("model config %s not found", model))
+	}
+
+	log.Debug().Msgf("Running %d smoke tests for model: %s", len(cfg.SmokeTests), model)
+	results := backend.RunSmokeTests(cfg, t.o.Loader, t.o)
+	resp := SmokeTestResponse{Model: model, Passed: true, Time: time.Now().UTC(), Results: results}
+	for _, r := range results {
+		if !r.Passed {
+			resp.Passed = false
+			log.Warn().Msgf("Smoke test %q failed for model %s: %s", r.Name, model, r.Error)
+		}
+	}
+
+	t.Lock()
+	t.results[model] = resp
+	t.Unlock()
+	return resp, nil
+}
+
+// RunChanged runs the smoke tests of the models which define some, e.g. after they were installed or reloaded
+func (t *SmokeTester) RunChanged(models ...string) []SmokeTestResponse {
+	responses := []SmokeTestResponse{}
+	for _, m := range models {
+		cfg, exists := t.cm.GetConfig(m)
+		if !exists || len(cfg.SmokeTests) == 0 {
+			continue
+		}
+		resp, err := t.Run(m)
+		if err != nil {
+			continue
+		}
+		if resp.Passed {
+			log.Info().Msgf("The smoke tests of %s passed", m)
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+// Results returns the last results of the smoke tests of each model, sorted by model
+func (t *SmokeTester) Results() []SmokeTestResponse {
+	t.Lock()
+	defer t.Unlock()
+	list := []SmokeTestResponse{}
+	for _, r := range t.results {
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Model < list[j].Model })
+	return list
+}
+
+// Rollout routes the alias to the model if the smoke tests of the model pass, and keeps the current route otherwise:
+// the model installed next to the one serving the alias (blue) is tested (green) before it serves the clients. The
+// routes are saved to the routes file, if any.
+func (t *SmokeTester) Rollout(alias, model string) (RolloutResponse, error) {
+	if alias == "" || model == "" {
+		return RolloutResponse{}, fiber.NewError(fiber.StatusBadRequest, "an alias and a model are required")
+	}
+	if _, configured := t.cm.GetConfig(alias); configured {
+		return RolloutResponse{}, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("%s is the name of a model, not an alias", alias))
+	}
+	cfg, exists := t.cm.GetConfig(model)
+	if !exists {
+		return RolloutResponse{}, fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("model config %s not found", model))
+	}
+	if len(cfg.SmokeTests) == 0 {
+		return RolloutResponse{}, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("model %s has no smoke tests to gate the rollout", model))
+	}
+
+	routesMu.Lock()
+	defer routesMu.Unlock()
+	resp := RolloutResponse{Alias: alias, Model: model}
+	routes := t.cm.Routes()
+	if r, ok := routes.Resolve(alias); ok && r.Exact() {
+		resp.Previous = r.Model
+	}
+
+	var err error
+	if resp.SmokeTest, err = t.Run(model); err != nil {
+		return resp, err
+	}
+	if !resp.SmokeTest.Passed {
+		log.Warn().Msgf("Kept the alias %s on %q, the smoke tests of %s failed", alias, resp.Previous, model)
+		return resp, nil
+	}
+
+	switched, err := routes.WithAlias(alias, model)
+	if err != nil {
+		return resp, err
+	}
+	if err := setRoutes(t.cm, t.o, switched); err != nil {
+		return resp, err
+	}
+	resp.Switched = true
+	log.Info().Msgf("Routed the alias %s to %s, previously %q", alias, model, resp.Previous)
+	return resp, nil
+}
+
+// SmokeTestEndpoint runs the smoke tests defined in the model config and reports the results
+func (t *SmokeTester) SmokeTestEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		input := new(SmokeTestRequest)
+		if err := c.BodyParser(input); err != nil {
+			return err
+		}
+		resp, err := t.Run(input.Model)
+		if err != nil {
+			return err
+		}
+		return c.JSON(resp)
+	}
+}
+
+// SmokeTestResultsEndpoint returns the last results of the smoke tests of the models
+func (t *SmokeTester) SmokeTestResultsEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(t.Results())
+	}
+}
+
+// RolloutEndpoint routes an alias to a model once its smoke tests pass. It answers with 409 Conflict when they fail,
+// the alias being kept on its current model.
+func (t *SmokeTester) RolloutEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		input := new(RolloutRequest)
+		if err := c.BodyParser(input); err != nil {
+			return err
+		}
+		resp, err := t.Rollout(input.Alias, input.Model)
+		if err != nil {
+			return err
+		}
+		if !resp.Switched {
+			return c.Status(fiber.StatusConflict).JSON(resp)
+		}
+		return c.JSON(resp)
+	}
+}
@@ -0,0 +1,127 @@
+package localai_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/localai"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/grpc"
+	"github.com/go-skynet/LocalAI/pkg/grpc/base"
+	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
+	"github.com/go-skynet/LocalAI/pkg/model"
+	"github.com/go-skynet/LocalAI/pkg/routing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// echoBackend answers the prompts with a fixed reply
+type echoBackend struct {
+	base.Base
+	reply string
+}
+
+func (b *echoBackend) Load(*pb.ModelOptions) error { return nil }
+
+func (b *echoBackend) Predict(*pb.PredictOptions) (string, error) { return b.reply, nil }
+
+var _ = Describe("SmokeTester", func() {
+	var dir string
+	var cm *config.ConfigLoader
+	var tester *localai.SmokeTester
+	var o *options.Option
+
+	// writeModel configures a model served by a fake backend answering reply, and testing that it says hello
+	writeModel := func(name, reply string) {
+		grpc.Provide("smoke-"+name+":0", &echoBackend{reply: reply})
+		options.WithExternalBackend("smoke-"+name, "smoke-"+name+":0")(o)
+		file := filepath.Join(dir, name+".yaml")
+		Expect(os.WriteFile(file, []byte(`
+name: `+name+`
+backend: smoke-`+name+`
+parameters:
+  model: `+name+`
+smoke_tests:
+- name: greeting
+  prompt: Say hello
+  expect: "(?i)hello"
+`), 0644)).To(Succeed())
+		Expect(cm.LoadConfig(file)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		cm = config.NewConfigLoader()
+		o = options.NewOptions(
+			options.WithContext(context.Background()),
+			options.WithModelLoader(model.NewModelLoader(dir)),
+			options.WithModelRoutesFile(filepath.Join(dir, "routes.yaml")),
+		)
+		tester = localai.NewSmokeTester(cm, o)
+		routes, err := routing.New([]routing.Rule{{Match: "chat", Model: "blue"}})
+		Expect(err).ToNot(HaveOccurred())
+		cm.SetRoutes(routes)
+		writeModel("blue", "Hello!")
+	})
+
+	AfterEach(func() {
+		o.Loader.StopAllGRPC()
+	})
+
+	It("runs the smoke tests of the models which changed, and keeps their results", func() {
+		writeModel("broken", "Bye.")
+		results := tester.RunChanged("blue", "broken", "unknown")
+		Expect(results).To(HaveLen(2))
+		Expect(results[0].Passed).To(BeTrue())
+		Expect(results[1].Passed).To(BeFalse())
+		Expect(results[1].Results[0].Error).To(ContainSubstring("does not match"))
+
+		last := tester.Results()
+		Expect(last).To(HaveLen(2))
+		Expect(last[0].Model).To(Equal("blue"))
+		Expect(last[1].Model).To(Equal("broken"))
+	})
+
+	It("routes the alias to the model once its smoke tests pass", func() {
+		writeModel("green", "hello there")
+		resp, err := tester.Rollout("chat", "green")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Switched).To(BeTrue())
+		Expect(resp.Previous).To(Equal("blue"))
+		r, _ := cm.Routes().Resolve("chat")
+		Expect(r.Model).To(Equal("green"))
+
+		// the routes are saved
+		saved, err := routing.Load(o.ModelRoutesFile)
+		Expect(err).ToNot(HaveOccurred())
+		r, _ = saved.Resolve("chat")
+		Expect(r.Model).To(Equal("green"))
+	})
+
+	It("keeps the alias on its model when the smoke tests fail", func() {
+		writeModel("green", "Bye.")
+		resp, err := tester.Rollout("chat", "green")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Switched).To(BeFalse())
+		Expect(resp.SmokeTest.Passed).To(BeFalse())
+		r, _ := cm.Routes().Resolve("chat")
+		Expect(r.Model).To(Equal("blue"))
+		_, err = os.Stat(o.ModelRoutesFile)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("refuses the rollouts which can't be gated", func() {
+		_, err := tester.Rollout("chat", "unknown")
+		Expect(err).To(HaveOccurred())
+		_, err = tester.Rollout("blue", "blue")
+		Expect(err).To(MatchError(ContainSubstring("not an alias")))
+
+		file := filepath.Join(dir, "untested.yaml")
+		Expect(os.WriteFile(file, []byte("name: untested\nbackend: smoke-blue\n"), 0644)).To(Succeed())
+		Expect(cm.LoadConfig(file)).To(Succeed())
+		_, err = tester.Rollout("chat", "untested")
+		Expect(err).To(MatchError(ContainSubstring("no smoke tests")))
+	})
+})
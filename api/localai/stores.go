@@ -0,0 +1,226 @@
+package localai
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-skynet/LocalAI/api/backend"
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/vectorstore"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CreateStoreRequest struct {
+	Name       string `json:"name" yaml:"name"`
+	Dimensions int    `json:"dimensions" yaml:"dimensions"`
+	// Metric is cosine, dot or euclidean, cosine by default
+	Metric string `json:"metric" yaml:"metric"`
+	// Model embeds the texts upserted and queried without vectors
+	Model string `json:"model" yaml:"model"`
+}
+
+type StoreVector struct {
+	ID     string    `json:"id"`
+	Values []float32 `json:"values,omitempty"`
+	// Text is embedded with the model of the collection when there are no values, and kept in the "text" metadata
+	Text     string                 `json:"text,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+type UpsertStoreRequest struct {
+	Vectors []StoreVector `json:"vectors"`
+}
+
+type QueryStoreRequest struct {
+	Vector []float32 `json:"vector"`
+	// Input is embedded with the model of the collection when there is no vector
+	Input         string                 `json:"input"`
+	TopK          int                    `json:"top_k"`
+	Filter        map[string]interface{} `json:"filter"`
+	IncludeValues bool                   `json:"include_values"`
+}
+
+type QueryStoreResponse struct {
+	Matches []vectorstore.Match `json:"matches"`
+}
+
+type DeleteVectorsRequest struct {
+	IDs    []string               `json:"ids"`
+	Filter map[string]interface{} `json:"filter"`
+}
+
+type DeleteVectorsResponse struct {
+	Deleted int `json:"deleted"`
+}
+
+// the number of matches of the queries which don't set it
+const defaultTopK = 10
+
+// storeError maps the errors of the vector store to the status of the responses
+func storeError(err error) error {
+	switch {
+	case errors.Is(err, vectorstore.ErrNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, vectorstore.ErrExists):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	}
+	return fiber.NewError(fiber.StatusBadRequest, err.Error())
+}
+
+// embedTexts embeds the texts with the model of the collection
+func embedTexts(cm *config.ConfigLoader, o *options.Option, collection vectorstore.Collection, texts []string) ([][]float32, error) {
+	if collection.Model == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("collection %s has no model to embed the texts, send the vectors", collection.Name))
+	}
+	cfg, err := config.Load(collection.Model, o.Loader.ModelPath, cm, false, o.Threads, o.ContextSize, o.F16)
+	if err != nil {
+		return nil, err
+	}
+	return backend.ModelEmbeddings(texts, nil, o.Loader, *cfg, o)
+}
+
+// ListStoresEndpoint lists the collections of the vector store
+func ListStoresEndpoint(o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(o.VectorStore.List())
+	}
+}
+
+// CreateStoreEndpoint creates an empty collection
+func CreateStoreEndpoint(o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		input := new(CreateStoreRequest)
+		if err := c.BodyParser(input); err != nil {
+			return err
+		}
+		collection, err := o.VectorStore.Create(input.Name, input.Dimensions, input.Metric, input.Model)
+		if err != nil {
+			return storeError(err)
+		}
+		return c.Status(fiber.StatusCreated).JSON(collection)
+	}
+}
+
+// GetStoreEndpoint returns a collection
+func GetStoreEndpoint(o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		collection, err := o.VectorStore.Get(c.Params("name"))
+		if err != nil {
+			return storeError(err)
+		}
+		return c.JSON(collection)
+	}
+}
+
+// DeleteStoreEndpoint deletes a collection and its vectors
+func DeleteStoreEndpoint(o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		if err := o.VectorStore.Drop(c.Params("name")); err != nil {
+			return storeError(err)
+		}
+		return c.JSON(fiber.Map{"message": "collection deleted"})
+	}
+}
+
+// UpsertStoreEndpoint adds the vectors to a collection, or replaces the ones with the same IDs. The texts sent
+// without their vectors are embedded with the model of the collection.
+func UpsertStoreEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		input := new(UpsertStoreRequest)
+		if err := c.BodyParser(input); err != nil {
+			return err
+		}
+		collection, err := o.VectorStore.Get(c.Params("name"))
+		if err != nil {
+			return storeError(err)
+		}
+
+		vectors := make([]vectorstore.Vector, len(input.Vectors))
+		texts, embedded := []string{}, []int{}
+		for i, v := range input.Vectors {
+			vectors[i] = vectorstore.Vector{ID: v.ID, Values: v.Values, Metadata: v.Metadata}
+			if v.Text == "" {
+				continue
+			}
+			if vectors[i].Metadata == nil {
+				vectors[i].Metadata = map[string]interface{}{}
+			}
+			if _, ok := vectors[i].Metadata["text"]; !ok {
+				vectors[i].Metadata["text"] = v.Text
+			}
+			if len(v.Values) == 0 {
+				texts = append(texts, v.Text)
+				embedded = append(embedded, i)
+			}
+		}
+		if len(texts) > 0 {
+			values, err := embedTexts(cm, o, collection, texts)
+			if err != nil {
+				return err
+			}
+			for j, i := range embedded {
+				vectors[i].Values = values[j]
+			}
+		}
+
+		collection, err = o.VectorStore.Upsert(collection.Name, vectors)
+		if err != nil {
+			return storeError(err)
+		}
+		return c.JSON(collection)
+	}
+}
+
+// QueryStoreEndpoint returns the vectors of a collection nearest to the vector of the request, or to the embedding of
+// its input, among the ones matching its filter
+func QueryStoreEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		input := new(QueryStoreRequest)
+		if err := c.BodyParser(input); err != nil {
+			return err
+		}
+		collection, err := o.VectorStore.Get(c.Params("name"))
+		if err != nil {
+			return storeError(err)
+		}
+		if len(input.Vector) == 0 {
+			if input.Input == "" {
+				return fiber.NewError(fiber.StatusBadRequest, "a vector or an input is required")
+			}
+			values, err := embedTexts(cm, o, collection, []string{input.Input})
+			if err != nil {
+				return err
+			}
+			input.Vector = values[0]
+		}
+		if input.TopK == 0 {
+			input.TopK = defaultTopK
+		}
+
+		matches, err := o.VectorStore.Query(collection.Name, input.Vector, input.TopK, input.Filter, input.IncludeValues)
+		if err != nil {
+			return storeError(err)
+		}
+		return c.JSON(QueryStoreResponse{Matches: matches})
+	}
+}
+
+// DeleteVectorsEndpoint deletes the vectors of a collection with the IDs of the request, and the ones matching its
+// filter
+func DeleteVectorsEndpoint(o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		input := new(DeleteVectorsRequest)
+		if err := c.BodyParser(input); err != nil {
+			return err
+		}
+		if len(input.IDs) == 0 && len(input.Filter) == 0 {
+			return fiber.NewError(fiber.StatusBadRequest, "the ids or a filter of the vectors to delete are required")
+		}
+		n, err := o.VectorStore.Delete(c.Params("name"), input.IDs, input.Filter)
+		if err != nil {
+			return storeError(err)
+		}
+		return c.JSON(DeleteVectorsResponse{Deleted: n})
+	}
+}
@@ -0,0 +1,19 @@
+package localai
+
+import (
+	"github.com/go-skynet/LocalAI/api/backend"
+	"github.com/go-skynet/LocalAI/pkg/throughput"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ThroughputResponse struct {
+	// Statistics of the models with inferences in the window, by name
+	Models map[string]throughput.Stats `json:"models"`
+}
+
+// ThroughputEndpoint returns the token throughput and latency statistics of the models, over the last minutes
+func ThroughputEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(ThroughputResponse{Models: backend.ThroughputStats()})
+	}
+}
@@ -0,0 +1,77 @@
+package localai
+
+import (
+	"github.com/go-skynet/LocalAI/api/backend"
+	config "github.com/go-skynet/LocalAI/api/config"
+	fiberContext "github.com/go-skynet/LocalAI/api/ctx"
+	"github.com/go-skynet/LocalAI/api/openai"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// tokenizerConfig reads the configuration of the model whose tokenizer is requested
+func tokenizerConfig(c *fiber.Ctx, cm *config.ConfigLoader, o *options.Option, modelName string) (*config.Config, error) {
+	modelFile, err := fiberContext.ModelFromContext(c, cm, o.Loader, modelName, false)
+	if err != nil {
+		modelFile = modelName
+		log.Warn().Msgf("Model not found in context: %s", modelName)
+	}
+
+	cfg, err := config.Load(modelFile, o.Loader.ModelPath, cm, false, o.Threads, o.ContextSize, o.F16)
+	if err != nil {
+		return nil, err
+	}
+	log.Debug().Msgf("Request for model: %s", cfg.Model)
+	return cfg, nil
+}
+
+func TokenizeEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		input := new(schema.TokenizeRequest)
+
+		// Get input data from the request body
+		if err := c.BodyParser(input); err != nil {
+			return err
+		}
+
+		cfg, err := tokenizerConfig(c, cm, o, input.Model)
+		if err != nil {
+			return err
+		}
+
+		content := input.Content
+		if len(input.Messages) > 0 {
+			content = openai.ChatPrompt(cfg, o.Loader, input.Messages)
+		}
+
+		resp, err := backend.ModelTokenize(content, o.Loader, *cfg, o)
+		if err != nil {
+			return err
+		}
+		return c.JSON(resp)
+	}
+}
+
+func DetokenizeEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		input := new(schema.DetokenizeRequest)
+
+		// Get input data from the request body
+		if err := c.BodyParser(input); err != nil {
+			return err
+		}
+
+		cfg, err := tokenizerConfig(c, cm, o, input.Model)
+		if err != nil {
+			return err
+		}
+
+		resp, err := backend.ModelDetokenize(input.Tokens, o.Loader, *cfg, o)
+		if err != nil {
+			return err
+		}
+		return c.JSON(resp)
+	}
+}
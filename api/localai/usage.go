@@ -0,0 +1,54 @@
+package localai
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/usage"
+	"github.com/gofiber/fiber/v2"
+)
+
+type UsageResponse struct {
+	Usage []usage.Entry `json:"usage"`
+}
+
+// queryTime parses a bound of the period queried, as a RFC 3339 time or a date, the zero time if not set
+func queryTime(c *fiber.Ctx, param string) (time.Time, error) {
+	v := c.Query(param)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q, expected a RFC 3339 time or a date", param, v)
+	}
+	return t, nil
+}
+
+// UsageEndpoint returns the usage of each API key and model over the period queried, of a tenant if given. With API keys, the callers only
+// get the usage of their own key, identified by caller, the admins get the usage of all of them.
+func UsageEndpoint(o *options.Option, isAdmin func(c *fiber.Ctx) bool, caller func(c *fiber.Ctx) string) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		q := usage.Query{Key: c.Query("key"), Tenant: c.Query("tenant"), Model: c.Query("model")}
+		var err error
+		if q.From, err = queryTime(c, "from"); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+		}
+		if q.To, err = queryTime(c, "to"); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+		}
+		if (len(o.GetApiKeys()) > 0 || o.ApiKeyStore != nil || o.OIDC != nil) && !isAdmin(c) {
+			q.Key = caller(c)
+		}
+
+		entries, err := o.Usage.Query(q)
+		if err != nil {
+			return err
+		}
+		return c.JSON(UsageResponse{Usage: entries})
+	}
+}
@@ -0,0 +1,780 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	fiberContext "github.com/go-skynet/LocalAI/api/ctx"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/go-skynet/LocalAI/pkg/grammar"
+	"github.com/go-skynet/LocalAI/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// assistantsStore is the state of the Assistants API, persisted as a JSON file
+type assistantsStore struct {
+	Assistants map[string]*schema.Assistant       `json:"assistants"`
+	Threads    map[string]*schema.Thread          `json:"threads"`
+	Messages   map[string][]*schema.ThreadMessage `json:"messages"`
+	Runs       map[string][]*schema.Run           `json:"runs"`
+	// the tool calls of each run, with their outputs once submitted
+	ToolCalls map[string][]schema.ToolCall `json:"tool_calls"`
+}
+
+// AssistantsService implements the OpenAI Assistants API on top of the chat completions of the local models
+type AssistantsService struct {
+	sync.Mutex
+	file    string
+	cm      *config.ConfigLoader
+	o       *options.Option
+	store   assistantsStore
+	cancels map[string]context.CancelFunc
+	// the files which can be attached to the messages, nil if the Files API is disabled
+	files *FilesService
+}
+
+func NewAssistantsService(dir string, cm *config.ConfigLoader, o *options.Option, files *FilesService) *AssistantsService {
+	s := &AssistantsService{
+		file:  filepath.Join(dir, "assistants.json"),
+		cm:    cm,
+		o:     o,
+		files: files,
+		store: assistantsStore{
+			Assistants: map[string]*schema.Assistant{},
+			Threads:    map[string]*schema.Thread{},
+			Messages:   map[string][]*schema.ThreadMessage{},
+			Runs:       map[string][]*schema.Run{},
+			ToolCalls:  map[string][]schema.ToolCall{},
+		},
+		cancels: map[string]context.CancelFunc{},
+	}
+
+	dat, err := os.ReadFile(s.file)
+	if err == nil {
+		err = json.Unmarshal(dat, &s.store)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		log.Error().Msgf("failed reading the assistants from %s: %s", s.file, err.Error())
+	}
+
+	// the runs which were executing when LocalAI stopped won't complete
+	for _, runs := range s.store.Runs {
+		for _, r := range runs {
+			if r.Status == schema.RunQueued || r.Status == schema.RunInProgress {
+				s.fail(r, fmt.Errorf("LocalAI was restarted while the run was in progress"))
+			}
+		}
+	}
+
+	return s
+}
+
+// save persists the store, it must be called with the lock held
+func (s *AssistantsService) save() {
+	dat, err := json.Marshal(s.store)
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(s.file), 0755); err == nil {
+			err = os.WriteFile(s.file, dat, 0600)
+		}
+	}
+	if err != nil {
+		log.Error().Msgf("failed saving the assistants to %s: %s", s.file, err.Error())
+	}
+}
+
+func newID(prefix string) string {
+	return prefix + strings.ReplaceAll(uuid.New().String(), "-", "")
+}
+
+// list paginates the objects like the OpenAI API, with the limit, order and after query parameters
+func list[T any](c *fiber.Ctx, objects []T, id func(T) string, created func(T) int64) error {
+	sorted := append([]T{}, objects...)
+	asc := c.Query("order", "desc") == "asc"
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if asc {
+			return created(sorted[i]) < created(sorted[j])
+		}
+		return created(sorted[i]) > created(sorted[j])
+	})
+
+	if after := c.Query("after"); after != "" {
+		for i, o := range sorted {
+			if id(o) == after {
+				sorted = sorted[i+1:]
+				break
+			}
+		}
+	}
+
+	limit := c.QueryInt("limit", 20)
+	hasMore := false
+	if limit > 0 && len(sorted) > limit {
+		sorted = sorted[:limit]
+		hasMore = true
+	}
+
+	res := schema.ListResponse{Object: "list", Data: sorted, HasMore: hasMore}
+	if len(sorted) > 0 {
+		res.FirstID = id(sorted[0])
+		res.LastID = id(sorted[len(sorted)-1])
+	}
+	return c.JSON(res)
+}
+
+func (s *AssistantsService) CreateAssistantEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		req := new(schema.AssistantRequest)
+		if err := c.BodyParser(req); err != nil {
+			return err
+		}
+		if req.Model == nil || *req.Model == "" {
+			return fiber.NewError(fiber.StatusBadRequest, "model is required")
+		}
+		if _, err := s.model(c, *req.Model); err != nil {
+			return err
+		}
+
+		a := &schema.Assistant{
+			ID:        newID("asst_"),
+			Object:    "assistant",
+			CreatedAt: time.Now().Unix(),
+			Tools:     []schema.AssistantTool{},
+		}
+		updateAssistant(a, req)
+
+		s.Lock()
+		defer s.Unlock()
+		s.store.Assistants[a.ID] = a
+		s.save()
+		return c.JSON(a)
+	}
+}
+
+// model resolves the name of the model of an assistant, or of a run, in the models namespace of the caller of the
+// request: the callers of a tenant can't run the models of the other tenants through the assistants
+func (s *AssistantsService) model(c *fiber.Ctx, name string) (string, error) {
+	m, err := fiberContext.ModelFromContext(c, s.cm, s.o.Loader, name, false)
+	if err != nil {
+		return "", fiber.NewError(fiber.StatusNotFound, err.Error())
+	}
+	return m, nil
+}
+
+func updateAssistant(a *schema.Assistant, req *schema.AssistantRequest) {
+	if req.Name != nil {
+		a.Name = *req.Name
+	}
+	if req.Description != nil {
+		a.Description = *req.Description
+	}
+	if req.Model != nil {
+		a.Model = *req.Model
+	}
+	if req.Instructions != nil {
+		a.Instructions = *req.Instructions
+	}
+	if req.Tools != nil {
+		a.Tools = req.Tools
+	}
+	if req.Metadata != nil {
+		a.Metadata = req.Metadata
+	}
+}
+
+func (s *AssistantsService) ListAssistantsEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		s.Lock()
+		defer s.Unlock()
+		assistants := []*schema.Assistant{}
+		for _, a := range s.store.Assistants {
+			assistants = append(assistants, a)
+		}
+		return list(c, assistants, func(a *schema.Assistant) string { return a.ID }, func(a *schema.Assistant) int64 { return a.CreatedAt })
+	}
+}
+
+func (s *AssistantsService) GetAssistantEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		s.Lock()
+		defer s.Unlock()
+		a, ok := s.store.Assistants[c.Params("assistant_id")]
+		if !ok {
+			return fiber.NewError(fiber.StatusNotFound, "assistant not found")
+		}
+		return c.JSON(a)
+	}
+}
+
+func (s *AssistantsService) ModifyAssistantEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		req := new(schema.AssistantRequest)
+		if err := c.BodyParser(req); err != nil {
+			return err
+		}
+		if req.Model != nil {
+			if _, err := s.model(c, *req.Model); err != nil {
+				return err
+			}
+		}
+
+		s.Lock()
+		defer s.Unlock()
+		a, ok := s.store.Assistants[c.Params("assistant_id")]
+		if !ok {
+			return fiber.NewError(fiber.StatusNotFound, "assistant not found")
+		}
+		updateAssistant(a, req)
+		s.save()
+		return c.JSON(a)
+	}
+}
+
+func (s *AssistantsService) DeleteAssistantEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		id := c.Params("assistant_id")
+
+		s.Lock()
+		defer s.Unlock()
+		_, ok := s.store.Assistants[id]
+		if ok {
+			delete(s.store.Assistants, id)
+			s.save()
+		}
+		return c.JSON(schema.DeletionStatus{ID: id, Object: "assistant.deleted", Deleted: ok})
+	}
+}
+
+// createThread must be called with the lock held
+func (s *AssistantsService) createThread(c *fiber.Ctx, req *schema.ThreadRequest) (*schema.Thread, error) {
+	t := &schema.Thread{
+		ID:        newID("thread_"),
+		Object:    "thread",
+		CreatedAt: time.Now().Unix(),
+		Metadata:  req.Metadata,
+	}
+	s.store.Threads[t.ID] = t
+
+	for _, m := range req.Messages {
+		if _, err := s.addMessage(c, t.ID, &m); err != nil {
+			delete(s.store.Threads, t.ID)
+			delete(s.store.Messages, t.ID)
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// addMessage must be called with the lock held, the files attached must be available to the caller of the request
+func (s *AssistantsService) addMessage(c *fiber.Ctx, threadID string, req *schema.ThreadMessageRequest) (*schema.ThreadMessage, error) {
+	if req.Role != "user" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "only messages with the user role can be added")
+	}
+	if len(req.FileIDs) > 0 && s.files == nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "files can't be attached to the messages: the Files API is disabled")
+	}
+	for _, id := range req.FileIDs {
+		if _, _, err := s.files.Get(c, id); err != nil {
+			return nil, err
+		}
+	}
+
+	m := &schema.ThreadMessage{
+		ID:        newID("msg_"),
+		Object:    "thread.message",
+		CreatedAt: time.Now().Unix(),
+		ThreadID:  threadID,
+		Role:      req.Role,
+		Content:   []schema.ThreadMessageContent{textContent(req.Content)},
+		FileIDs:   req.FileIDs,
+		Metadata:  req.Metadata,
+	}
+	s.store.Messages[threadID] = append(s.store.Messages[threadID], m)
+	return m, nil
+}
+
+func textContent(s string) schema.ThreadMessageContent {
+	return schema.ThreadMessageContent{Type: "text", Text: schema.ThreadMessageText{Value: s, Annotations: []interface{}{}}}
+}
+
+func (s *AssistantsService) CreateThreadEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		req := new(schema.ThreadRequest)
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(req); err != nil {
+				return err
+			}
+		}
+
+		s.Lock()
+		defer s.Unlock()
+		t, err := s.createThread(c, req)
+		if err != nil {
+			return err
+		}
+		s.save()
+		return c.JSON(t)
+	}
+}
+
+func (s *AssistantsService) GetThreadEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		s.Lock()
+		defer s.Unlock()
+		t, ok := s.store.Threads[c.Params("thread_id")]
+		if !ok {
+			return fiber.NewError(fiber.StatusNotFound, "thread not found")
+		}
+		return c.JSON(t)
+	}
+}
+
+func (s *AssistantsService) ModifyThreadEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		req := new(schema.ThreadRequest)
+		if err := c.BodyParser(req); err != nil {
+			return err
+		}
+
+		s.Lock()
+		defer s.Unlock()
+		t, ok := s.store.Threads[c.Params("thread_id")]
+		if !ok {
+			return fiber.NewError(fiber.StatusNotFound, "thread not found")
+		}
+		if req.Metadata != nil {
+			t.Metadata = req.Metadata
+		}
+		s.save()
+		return c.JSON(t)
+	}
+}
+
+func (s *AssistantsService) DeleteThreadEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		id := c.Params("thread_id")
+
+		s.Lock()
+		defer s.Unlock()
+		_, ok := s.store.Threads[id]
+		if ok {
+			for _, r := range s.store.Runs[id] {
+				if cancel, ok := s.cancels[r.ID]; ok {
+					cancel()
+				}
+				delete(s.store.ToolCalls, r.ID)
+			}
+			delete(s.store.Threads, id)
+			delete(s.store.Messages, id)
+			delete(s.store.Runs, id)
+			s.save()
+		}
+		return c.JSON(schema.DeletionStatus{ID: id, Object: "thread.deleted", Deleted: ok})
+	}
+}
+
+func (s *AssistantsService) CreateMessageEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		req := new(schema.ThreadMessageRequest)
+		if err := c.BodyParser(req); err != nil {
+			return err
+		}
+
+		s.Lock()
+		defer s.Unlock()
+		threadID := c.Params("thread_id")
+		if _, ok := s.store.Threads[threadID]; !ok {
+			return fiber.NewError(fiber.StatusNotFound, "thread not found")
+		}
+		if s.activeRun(threadID) != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "can't add messages to the thread while a run is active")
+		}
+		m, err := s.addMessage(c, threadID, req)
+		if err != nil {
+			return err
+		}
+		s.save()
+		return c.JSON(m)
+	}
+}
+
+func (s *AssistantsService) ListMessagesEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		s.Lock()
+		defer s.Unlock()
+		threadID := c.Params("thread_id")
+		if _, ok := s.store.Threads[threadID]; !ok {
+			return fiber.NewError(fiber.StatusNotFound, "thread not found")
+		}
+		return list(c, s.store.Messages[threadID], func(m *schema.ThreadMessage) string { return m.ID }, func(m *schema.ThreadMessage) int64 { return m.CreatedAt })
+	}
+}
+
+func (s *AssistantsService) GetMessageEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		s.Lock()
+		defer s.Unlock()
+		for _, m := range s.store.Messages[c.Params("thread_id")] {
+			if m.ID == c.Params("message_id") {
+				return c.JSON(m)
+			}
+		}
+		return fiber.NewError(fiber.StatusNotFound, "message not found")
+	}
+}
+
+// activeRun returns the run of the thread which is not finished yet, it must be called with the lock held
+func (s *AssistantsService) activeRun(threadID string) *schema.Run {
+	for _, r := range s.store.Runs[threadID] {
+		switch r.Status {
+		case schema.RunQueued, schema.RunInProgress, schema.RunRequiresAction:
+			return r
+		}
+	}
+	return nil
+}
+
+func (s *AssistantsService) getRun(threadID, runID string) (*schema.Run, error) {
+	for _, r := range s.store.Runs[threadID] {
+		if r.ID == runID {
+			return r, nil
+		}
+	}
+	return nil, fiber.NewError(fiber.StatusNotFound, "run not found")
+}
+
+// createRun must be called with the lock held
+func (s *AssistantsService) createRun(c *fiber.Ctx, threadID string, req *schema.RunRequest) (*schema.Run, error) {
+	a, ok := s.store.Assistants[req.AssistantID]
+	if !ok {
+		return nil, fiber.NewError(fiber.StatusNotFound, "assistant not found")
+	}
+	if s.activeRun(threadID) != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "the thread already has an active run")
+	}
+
+	r := &schema.Run{
+		ID:           newID("run_"),
+		Object:       "thread.run",
+		CreatedAt:    time.Now().Unix(),
+		ThreadID:     threadID,
+		AssistantID:  a.ID,
+		Status:       schema.RunQueued,
+		Model:        a.Model,
+		Instructions: a.Instructions,
+		Tools:        a.Tools,
+		Metadata:     req.Metadata,
+	}
+	if req.Model != "" {
+		r.Model = req.Model
+	}
+	if req.Instructions != "" {
+		r.Instructions = req.Instructions
+	}
+	if req.Tools != nil {
+		r.Tools = req.Tools
+	}
+	model, err := s.model(c, r.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	s.store.Runs[threadID] = append(s.store.Runs[threadID], r)
+	s.start(r, model)
+	return r, nil
+}
+
+func (s *AssistantsService) CreateRunEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		req := new(schema.RunRequest)
+		if err := c.BodyParser(req); err != nil {
+			return err
+		}
+
+		s.Lock()
+		defer s.Unlock()
+		threadID := c.Params("thread_id")
+		if _, ok := s.store.Threads[threadID]; !ok {
+			return fiber.NewError(fiber.StatusNotFound, "thread not found")
+		}
+		r, err := s.createRun(c, threadID, req)
+		if err != nil {
+			return err
+		}
+		s.save()
+		return c.JSON(r)
+	}
+}
+
+func (s *AssistantsService) CreateThreadAndRunEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		req := new(schema.RunRequest)
+		if err := c.BodyParser(req); err != nil {
+			return err
+		}
+		if req.Thread == nil {
+			req.Thread = &schema.ThreadRequest{}
+		}
+
+		s.Lock()
+		defer s.Unlock()
+		if _, ok := s.store.Assistants[req.AssistantID]; !ok {
+			return fiber.NewError(fiber.StatusNotFound, "assistant not found")
+		}
+		t, err := s.createThread(c, req.Thread)
+		if err != nil {
+			return err
+		}
+		r, err := s.createRun(c, t.ID, req)
+		if err != nil {
+			delete(s.store.Threads, t.ID)
+			delete(s.store.Messages, t.ID)
+			return err
+		}
+		s.save()
+		return c.JSON(r)
+	}
+}
+
+func (s *AssistantsService) ListRunsEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		s.Lock()
+		defer s.Unlock()
+		threadID := c.Params("thread_id")
+		if _, ok := s.store.Threads[threadID]; !ok {
+			return fiber.NewError(fiber.StatusNotFound, "thread not found")
+		}
+		return list(c, s.store.Runs[threadID], func(r *schema.Run) string { return r.ID }, func(r *schema.Run) int64 { return r.CreatedAt })
+	}
+}
+
+func (s *AssistantsService) GetRunEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		s.Lock()
+		defer s.Unlock()
+		r, err := s.getRun(c.Params("thread_id"), c.Params("run_id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(r)
+	}
+}
+
+func (s *AssistantsService) CancelRunEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		s.Lock()
+		defer s.Unlock()
+		r, err := s.getRun(c.Params("thread_id"), c.Params("run_id"))
+		if err != nil {
+			return err
+		}
+		switch r.Status {
+		case schema.RunQueued, schema.RunInProgress, schema.RunRequiresAction:
+		default:
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("can't cancel a run with status %s", r.Status))
+		}
+
+		if cancel, ok := s.cancels[r.ID]; ok {
+			cancel()
+		}
+		r.Status = schema.RunCancelled
+		r.CancelledAt = time.Now().Unix()
+		r.RequiredAction = nil
+		s.save()
+		return c.JSON(r)
+	}
+}
+
+func (s *AssistantsService) SubmitToolOutputsEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		req := new(schema.SubmitToolOutputsRequest)
+		if err := c.BodyParser(req); err != nil {
+			return err
+		}
+
+		s.Lock()
+		defer s.Unlock()
+		r, err := s.getRun(c.Params("thread_id"), c.Params("run_id"))
+		if err != nil {
+			return err
+		}
+		if r.Status != schema.RunRequiresAction {
+			return fiber.NewError(fiber.StatusBadRequest, "the run does not require any tool output")
+		}
+		model, err := s.model(c, r.Model)
+		if err != nil {
+			return err
+		}
+
+		outputs := map[string]string{}
+		for _, o := range req.ToolOutputs {
+			outputs[o.ToolCallID] = o.Output
+		}
+		for _, call := range r.RequiredAction.SubmitToolOutputs.ToolCalls {
+			output, ok := outputs[call.ID]
+			if !ok {
+				return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("missing the output of the tool call %s", call.ID))
+			}
+			call.Function.Output = output
+			s.store.ToolCalls[r.ID] = append(s.store.ToolCalls[r.ID], call)
+		}
+
+		r.RequiredAction = nil
+		r.Status = schema.RunQueued
+		s.start(r, model)
+		s.save()
+		return c.JSON(r)
+	}
+}
+
+// start executes the run in background with the model resolved by model, it must be called with the lock held
+func (s *AssistantsService) start(r *schema.Run, model string) {
+	ctx, cancel := context.WithCancel(s.o.Context)
+	s.cancels[r.ID] = cancel
+
+	messages := append([]*schema.ThreadMessage{}, s.store.Messages[r.ThreadID]...)
+	calls := append([]schema.ToolCall{}, s.store.ToolCalls[r.ID]...)
+	run := *r
+
+	go func() {
+		defer cancel()
+
+		s.Lock()
+		if r.Status != schema.RunQueued {
+			s.Unlock()
+			return
+		}
+		r.Status = schema.RunInProgress
+		r.StartedAt = time.Now().Unix()
+		s.save()
+		s.Unlock()
+
+		reply, toolCalls, err := s.infer(ctx, &run, model, messages, calls)
+
+		s.Lock()
+		defer s.Unlock()
+		delete(s.cancels, r.ID)
+		// the run might have been cancelled, or its thread deleted, in the meantime
+		if _, ok := s.store.Threads[r.ThreadID]; !ok || r.Status != schema.RunInProgress {
+			return
+		}
+
+		switch {
+		case err != nil:
+			s.fail(r, err)
+		case len(toolCalls) > 0:
+			r.Status = schema.RunRequiresAction
+			r.RequiredAction = &schema.RequiredAction{
+				Type:              "submit_tool_outputs",
+				SubmitToolOutputs: schema.SubmitToolOutputs{ToolCalls: toolCalls},
+			}
+		default:
+			s.store.Messages[r.ThreadID] = append(s.store.Messages[r.ThreadID], &schema.ThreadMessage{
+				ID:          newID("msg_"),
+				Object:      "thread.message",
+				CreatedAt:   time.Now().Unix(),
+				ThreadID:    r.ThreadID,
+				Role:        "assistant",
+				Content:     []schema.ThreadMessageContent{textContent(reply)},
+				AssistantID: r.AssistantID,
+				RunID:       r.ID,
+			})
+			r.Status = schema.RunCompleted
+			r.CompletedAt = time.Now().Unix()
+		}
+		s.save()
+	}()
+}
+
+func (s *AssistantsService) fail(r *schema.Run, err error) {
+	r.Status = schema.RunFailed
+	r.FailedAt = time.Now().Unix()
+	r.LastError = &schema.RunError{Code: "server_error", Message: err.Error()}
+}
+
+// infer runs the model on the thread, and returns either its reply or the tools it wants to call
+func (s *AssistantsService) infer(ctx context.Context, r *schema.Run, model string, messages []*schema.ThreadMessage, calls []schema.ToolCall) (string, []schema.ToolCall, error) {
+	input := &schema.OpenAIRequest{Context: ctx}
+	input.Model = r.Model
+
+	if r.Instructions != "" {
+		input.Messages = append(input.Messages, schema.Message{Role: "system", Content: r.Instructions})
+	}
+	for _, m := range messages {
+		input.Messages = append(input.Messages, schema.Message{Role: m.Role, Content: m.Text()})
+	}
+	// the tools called during the run, with their results
+	for _, call := range calls {
+		input.Messages = append(input.Messages,
+			schema.Message{Role: "assistant", FunctionCall: map[string]interface{}{"name": call.Function.Name, "arguments": call.Function.Arguments}},
+			schema.Message{Role: "function", Content: call.Function.Output},
+		)
+	}
+
+	config, input, err := mergeRequestWithConfig(model, input, s.cm, s.o.Loader, s.o.Debug, s.o.Threads, s.o.ContextSize, s.o.F16)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed reading the model configuration: %w", err)
+	}
+
+	funcs := grammar.Functions{}
+	for _, t := range r.Tools {
+		if t.Type == "function" && t.Function != nil {
+			funcs = append(funcs, *t.Function)
+		}
+	}
+	processFunctions := len(funcs) > 0 && config.ShouldUseFunctions()
+	noActionGrammar := noAction(config)
+	if processFunctions {
+		if !config.FunctionsConfig.DisableNoAction {
+			funcs = append(funcs, noActionGrammar)
+		}
+		config.Grammar = funcs.ToJSONStructure().Grammar("")
+	}
+
+	predInput := chatPrompt(config, s.o.Loader, input.Messages, funcs, processFunctions)
+
+	result, _, err := ComputeChoices(input, predInput, config, s.o, s.o.Loader, func(s string, c *[]schema.Choice) {
+		*c = append(*c, schema.Choice{Text: s})
+	}, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(result) == 0 {
+		return "", nil, fmt.Errorf("the model returned no result")
+	}
+	reply := result[0].Text
+
+	if !processFunctions {
+		return reply, nil, nil
+	}
+
+	// The grammar defines the function name as "function", and its arguments as an object
+	ss := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(utils.EscapeNewLines(reply)), &ss); err != nil {
+		return "", nil, fmt.Errorf("failed parsing the function call of the model: %w", err)
+	}
+	name, _ := ss["function"].(string)
+	args, _ := ss["arguments"].(map[string]interface{})
+
+	if name == noActionGrammar.Name {
+		message, _ := args["message"].(string)
+		return message, nil, nil
+	}
+
+	d, _ := json.Marshal(args)
+	return "", []schema.ToolCall{{
+		ID:       newID("call_"),
+		Type:     "function",
+		Function: schema.ToolCallFunction{Name: name, Arguments: string(d)},
+	}}, nil
+}
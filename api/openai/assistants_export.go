@@ -0,0 +1,167 @@
+package openai
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/gofiber/fiber/v2"
+)
+
+// exportThread must be called with the lock held
+func (s *AssistantsService) exportThread(threadID string) (*schema.ThreadExport, error) {
+	t, ok := s.store.Threads[threadID]
+	if !ok {
+		return nil, fiber.NewError(fiber.StatusNotFound, "thread not found")
+	}
+
+	e := &schema.ThreadExport{Version: schema.ThreadExportVersion, Thread: *t, Messages: []schema.ThreadMessage{}}
+	assistants := map[string]bool{}
+	files := map[string]bool{}
+	for _, m := range s.store.Messages[threadID] {
+		e.Messages = append(e.Messages, *m)
+		if a, ok := s.store.Assistants[m.AssistantID]; ok && !assistants[a.ID] {
+			assistants[a.ID] = true
+			e.Assistants = append(e.Assistants, *a)
+		}
+		for _, id := range m.FileIDs {
+			if s.files == nil || files[id] {
+				continue
+			}
+			files[id] = true
+			// the file might have been deleted since it was attached
+			if f, _, err := s.files.get(id); err == nil {
+				e.Files = append(e.Files, *f)
+			}
+		}
+	}
+	return e, nil
+}
+
+// threadMarkdown renders an exported thread as a Markdown document, to read or archive it
+func threadMarkdown(e *schema.ThreadExport) string {
+	assistants := map[string]string{}
+	for _, a := range e.Assistants {
+		assistants[a.ID] = a.Name
+	}
+	files := map[string]string{}
+	for _, f := range e.Files {
+		files[f.ID] = f.Filename
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Thread %s\n\n", e.Thread.ID)
+	fmt.Fprintf(&sb, "- Created: %s\n", time.Unix(e.Thread.CreatedAt, 0).UTC().Format(time.RFC3339))
+	keys := make([]string, 0, len(e.Thread.Metadata))
+	for k := range e.Thread.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&sb, "- %s: %s\n", k, e.Thread.Metadata[k])
+	}
+
+	for _, m := range e.Messages {
+		author := m.Role
+		if name := assistants[m.AssistantID]; name != "" {
+			author = fmt.Sprintf("%s (%s)", m.Role, name)
+		}
+		fmt.Fprintf(&sb, "\n## %s, %s\n\n%s\n", author, time.Unix(m.CreatedAt, 0).UTC().Format(time.RFC3339), m.Text())
+		if len(m.FileIDs) > 0 {
+			sb.WriteString("\nAttachments:\n\n")
+			for _, id := range m.FileIDs {
+				if name := files[id]; name != "" {
+					fmt.Fprintf(&sb, "- %s (%s)\n", name, id)
+				} else {
+					fmt.Fprintf(&sb, "- %s\n", id)
+				}
+			}
+		}
+	}
+	return sb.String()
+}
+
+func (s *AssistantsService) ExportThreadEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		s.Lock()
+		e, err := s.exportThread(c.Params("thread_id"))
+		s.Unlock()
+		if err != nil {
+			return err
+		}
+
+		switch c.Query("format", "json") {
+		case "json":
+			c.Attachment(e.Thread.ID + ".json")
+			return c.JSON(e)
+		case "markdown", "md":
+			c.Attachment(e.Thread.ID + ".md")
+			c.Set(fiber.HeaderContentType, "text/markdown; charset=utf-8")
+			return c.SendString(threadMarkdown(e))
+		default:
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("unsupported export format %q, use json or markdown", c.Query("format")))
+		}
+	}
+}
+
+// importThread creates a new thread with the messages of an exported thread, it must be called with the lock held.
+// The assistants of the export are created if they don't exist yet, while the attached files are kept as references.
+func (s *AssistantsService) importThread(e *schema.ThreadExport) (*schema.Thread, error) {
+	if e.Version != schema.ThreadExportVersion {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("unsupported export version %d", e.Version))
+	}
+	for _, m := range e.Messages {
+		if m.Role != "user" && m.Role != "assistant" {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("invalid role %q of message %s", m.Role, m.ID))
+		}
+	}
+
+	for _, a := range e.Assistants {
+		if _, ok := s.store.Assistants[a.ID]; !ok && a.ID != "" {
+			a := a
+			s.store.Assistants[a.ID] = &a
+		}
+	}
+
+	t := &schema.Thread{
+		ID:        newID("thread_"),
+		Object:    "thread",
+		CreatedAt: e.Thread.CreatedAt,
+		Metadata:  e.Thread.Metadata,
+	}
+	if t.CreatedAt == 0 {
+		t.CreatedAt = time.Now().Unix()
+	}
+	s.store.Threads[t.ID] = t
+
+	for _, m := range e.Messages {
+		m := m
+		m.ID = newID("msg_")
+		m.Object = "thread.message"
+		m.ThreadID = t.ID
+		// the runs are not part of the export
+		m.RunID = ""
+		s.store.Messages[t.ID] = append(s.store.Messages[t.ID], &m)
+	}
+	return t, nil
+}
+
+func (s *AssistantsService) ImportThreadEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		e := new(schema.ThreadExport)
+		if err := c.BodyParser(e); err != nil {
+			return err
+		}
+
+		s.Lock()
+		defer s.Unlock()
+		t, err := s.importThread(e)
+		if err != nil {
+			return err
+		}
+		s.save()
+		return c.JSON(t)
+	}
+}
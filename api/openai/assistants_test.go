@@ -0,0 +1,79 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	fiberContext "github.com/go-skynet/LocalAI/api/ctx"
+	. "github.com/go-skynet/LocalAI/api/openai"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/go-skynet/LocalAI/pkg/model"
+	"github.com/gofiber/fiber/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AssistantsService", func() {
+	var app *fiber.App
+
+	BeforeEach(func() {
+		dir := GinkgoT().TempDir()
+		models := filepath.Join(dir, "models")
+		for _, f := range []string{"tenants/alice/model.bin", "tenants/bob/model.bin"} {
+			Expect(os.MkdirAll(filepath.Dir(filepath.Join(models, f)), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(models, f), []byte{}, 0600)).To(Succeed())
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		DeferCleanup(cancel)
+		o := &options.Option{Context: ctx, Loader: model.NewModelLoader(models)}
+		assistants := NewAssistantsService(filepath.Join(dir, "assistants"), config.NewConfigLoader(), o, nil)
+
+		// the callers of a tenant are restricted to its namespace, the others see all the models
+		app = fiber.New()
+		app.Use(func(c *fiber.Ctx) error {
+			if tenant := c.Get("X-Tenant"); tenant != "" {
+				fiberContext.SetNamespace(c, tenant, false)
+			}
+			return c.Next()
+		})
+		app.Post("/v1/assistants", assistants.CreateAssistantEndpoint())
+		app.Post("/v1/assistants/:assistant_id", assistants.ModifyAssistantEndpoint())
+		app.Post("/v1/threads/runs", assistants.CreateThreadAndRunEndpoint())
+	})
+
+	post := func(path, body, tenant string) (int, []byte) {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return call(app, req, "", tenant)
+	}
+
+	It("resolves the model of the assistants in the namespace of the caller", func() {
+		status, _ := post("/v1/assistants", `{"model": "model.bin"}`, "alice")
+		Expect(status).To(Equal(fiber.StatusOK))
+
+		status, _ = post("/v1/assistants", `{"model": "tenants/bob/model.bin"}`, "alice")
+		Expect(status).To(Equal(fiber.StatusNotFound))
+	})
+
+	It("rejects the runs of a model outside of the namespace of the caller", func() {
+		status, dat := post("/v1/assistants", `{"model": "tenants/bob/model.bin"}`, "")
+		Expect(status).To(Equal(fiber.StatusOK))
+		a := schema.Assistant{}
+		Expect(json.Unmarshal(dat, &a)).To(Succeed())
+
+		status, _ = post("/v1/threads/runs", `{"assistant_id": "`+a.ID+`"}`, "alice")
+		Expect(status).To(Equal(fiber.StatusNotFound))
+		status, _ = post("/v1/threads/runs", `{"assistant_id": "`+a.ID+`", "model": "tenants/bob/model.bin"}`, "alice")
+		Expect(status).To(Equal(fiber.StatusNotFound))
+
+		status, _ = post("/v1/assistants/"+a.ID, `{"model": "tenants/bob/model.bin"}`, "alice")
+		Expect(status).To(Equal(fiber.StatusNotFound))
+	})
+})
@@ -0,0 +1,446 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/valyala/fasthttp"
+)
+
+// endpoints which can be used in a batch
+var batchEndpoints = map[string]bool{
+	"/v1/chat/completions": true,
+	"/v1/completions":      true,
+	"/v1/embeddings":       true,
+}
+
+// maximum number of batches waiting for a worker
+const batchQueueSize = 1000
+
+// the key of the caller which created the batch, in the locals of the fiber context of its requests
+const batchCallerLocal = "batch_caller"
+
+// batchCaller is the caller which created a batch, and its tenant
+type batchCaller struct {
+	owner  string
+	tenant string
+}
+
+// BatchCaller returns the caller which created the batch of the request, and its tenant, or false if the request is
+// not one of a batch
+func BatchCaller(c *fiber.Ctx) (string, string, bool) {
+	b, ok := c.Locals(batchCallerLocal).(batchCaller)
+	return b.owner, b.tenant, ok
+}
+
+// storedBatch is a batch with the caller which created it
+type storedBatch struct {
+	schema.Batch
+	Owner  string `json:"owner"`
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// BatchService implements the OpenAI Batch API. The requests of the input file are
+// executed in the background by a pool of workers, and their results are stored with the Files API, as files of the
+// caller which created the batch.
+type BatchService struct {
+	sync.Mutex
+	file    string
+	files   *FilesService
+	handler fasthttp.RequestHandler
+	batches map[string]*storedBatch
+	cancels map[string]context.CancelFunc
+	queue   chan string
+}
+
+// NewBatchService executes the requests of the batches with handler, using the given number of workers
+func NewBatchService(ctx context.Context, files *FilesService, handler fasthttp.RequestHandler, workers int) *BatchService {
+	s := &BatchService{
+		file:    filepath.Join(files.dir, "batches.json"),
+		files:   files,
+		handler: handler,
+		batches: map[string]*storedBatch{},
+		cancels: map[string]context.CancelFunc{},
+		queue:   make(chan string, batchQueueSize),
+	}
+
+	dat, err := os.ReadFile(s.file)
+	if err == nil {
+		err = json.Unmarshal(dat, &s.batches)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		log.Error().Msgf("failed reading the batches from %s: %s", s.file, err.Error())
+	}
+
+	// the batches which were executing when LocalAI stopped are executed again from the start
+	for _, b := range s.batches {
+		switch b.Status {
+		case schema.BatchValidating, schema.BatchInProgress, schema.BatchFinalizing:
+			b.Status = schema.BatchValidating
+			b.RequestCounts = schema.BatchRequestCounts{}
+			select {
+			case s.queue <- b.ID:
+			default:
+				s.fail(b, schema.BatchError{Code: "queue_full", Message: "too many batches were queued when LocalAI was restarted"})
+			}
+		case schema.BatchCancelling:
+			b.Status = schema.BatchCancelled
+			b.CancelledAt = time.Now().Unix()
+		}
+	}
+
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		go s.worker(ctx)
+	}
+
+	return s
+}
+
+// save persists the batches, it must be called with the lock held
+func (s *BatchService) save() {
+	dat, err := json.Marshal(s.batches)
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(s.file), 0755); err == nil {
+			err = os.WriteFile(s.file, dat, 0600)
+		}
+	}
+	if err != nil {
+		log.Error().Msgf("failed saving the batches to %s: %s", s.file, err.Error())
+	}
+}
+
+// allowed returns true if the caller of the request can see the batch, like its files
+func (s *BatchService) allowed(c *fiber.Ctx, b *storedBatch) bool {
+	if b.Tenant != "" && s.files.tenant(c) == b.Tenant {
+		return true
+	}
+	return s.files.caller(c) == b.Owner
+}
+
+// fail marks the batch as failed, it must be called with the lock held
+func (s *BatchService) fail(b *storedBatch, errs ...schema.BatchError) {
+	b.Status = schema.BatchFailed
+	b.FailedAt = time.Now().Unix()
+	b.Errors = &schema.BatchErrors{Object: "list", Data: errs}
+	s.save()
+}
+
+func (s *BatchService) worker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-s.queue:
+			s.process(ctx, id)
+		}
+	}
+}
+
+// process executes all the requests of a batch and stores their results
+func (s *BatchService) process(ctx context.Context, id string) {
+	s.Lock()
+	b, ok := s.batches[id]
+	if !ok || b.Status != schema.BatchValidating {
+		// cancelled while queued
+		s.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancels[id] = cancel
+	s.Unlock()
+
+	defer func() {
+		cancel()
+		s.Lock()
+		delete(s.cancels, id)
+		s.Unlock()
+	}()
+
+	lines, errs := s.validate(b.InputFileID, b.Endpoint)
+
+	s.Lock()
+	if b.Status == schema.BatchCancelling {
+		b.Status = schema.BatchCancelled
+		b.CancelledAt = time.Now().Unix()
+		s.save()
+		s.Unlock()
+		return
+	}
+	if len(errs) > 0 {
+		s.fail(b, errs...)
+		s.Unlock()
+		return
+	}
+	b.Status = schema.BatchInProgress
+	b.InProgressAt = time.Now().Unix()
+	b.RequestCounts.Total = len(lines)
+	s.save()
+	s.Unlock()
+
+	output, errors := &bytes.Buffer{}, &bytes.Buffer{}
+	for _, l := range lines {
+		if ctx.Err() != nil {
+			break
+		}
+
+		res := s.execute(b, l)
+		dat, _ := json.Marshal(res)
+		dat = append(dat, '\n')
+
+		s.Lock()
+		if res.Error == nil && res.Response.StatusCode < 400 {
+			b.RequestCounts.Completed++
+			output.Write(dat)
+		} else {
+			b.RequestCounts.Failed++
+			errors.Write(dat)
+		}
+		s.Unlock()
+	}
+
+	s.Lock()
+	cancelled := b.Status == schema.BatchCancelling
+	if !cancelled {
+		b.Status = schema.BatchFinalizing
+		b.FinalizingAt = time.Now().Unix()
+	}
+	s.Unlock()
+
+	// the results are stored even if the batch was cancelled, as in the OpenAI API
+	outputID, errorID, err := s.store(b, output, errors)
+
+	s.Lock()
+	defer s.Unlock()
+	b.OutputFileID, b.ErrorFileID = outputID, errorID
+	switch {
+	case err != nil:
+		s.fail(b, schema.BatchError{Code: "output_failed", Message: err.Error()})
+		return
+	case cancelled:
+		b.Status = schema.BatchCancelled
+		b.CancelledAt = time.Now().Unix()
+	default:
+		b.Status = schema.BatchCompleted
+		b.CompletedAt = time.Now().Unix()
+	}
+	s.save()
+}
+
+// validate reads and checks the requests of the input file
+func (s *BatchService) validate(fileID, endpoint string) ([]schema.BatchInputLine, []schema.BatchError) {
+	_, path, err := s.files.get(fileID)
+	if err != nil {
+		return nil, []schema.BatchError{{Code: "invalid_input_file", Message: err.Error()}}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, []schema.BatchError{{Code: "invalid_input_file", Message: err.Error()}}
+	}
+	defer f.Close()
+
+	lines := []schema.BatchInputLine{}
+	errs := []schema.BatchError{}
+	ids := map[string]bool{}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		l := schema.BatchInputLine{}
+		if err := json.Unmarshal(scanner.Bytes(), &l); err != nil {
+			errs = append(errs, schema.BatchError{Code: "invalid_json_line", Message: err.Error(), Line: n})
+			continue
+		}
+		switch {
+		case l.CustomID == "":
+			errs = append(errs, schema.BatchError{Code: "missing_custom_id", Message: "the custom_id of the request is required", Line: n})
+		case ids[l.CustomID]:
+			errs = append(errs, schema.BatchError{Code: "duplicate_custom_id", Message: fmt.Sprintf("the custom_id %q is used by more requests", l.CustomID), Line: n})
+		case l.Method != fiber.MethodPost:
+			errs = append(errs, schema.BatchError{Code: "invalid_method", Message: fmt.Sprintf("unsupported method %q, only POST is supported", l.Method), Line: n})
+		case l.URL != endpoint:
+			errs = append(errs, schema.BatchError{Code: "mismatched_endpoint", Message: fmt.Sprintf("the url %q of the request differs from the endpoint %q of the batch", l.URL, endpoint), Line: n})
+		}
+		ids[l.CustomID] = true
+		lines = append(lines, l)
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, schema.BatchError{Code: "invalid_input_file", Message: err.Error()})
+	}
+	if len(errs) == 0 && len(lines) == 0 {
+		errs = append(errs, schema.BatchError{Code: "empty_file", Message: "the input file contains no requests"})
+	}
+	return lines, errs
+}
+
+// execute sends a request of the batch to the API, on behalf of the caller which created the batch
+func (s *BatchService) execute(b *storedBatch, l schema.BatchInputLine) schema.BatchOutputLine {
+	res := schema.BatchOutputLine{ID: newID("batch_req_"), CustomID: l.CustomID}
+
+	// the whole response is needed at once
+	delete(l.Body, "stream")
+	body, err := json.Marshal(l.Body)
+	if err != nil {
+		res.Error = &schema.BatchError{Code: "invalid_body", Message: err.Error()}
+		return res
+	}
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(l.Method)
+	ctx.Request.SetRequestURI(l.URL)
+	ctx.Request.Header.SetContentType(fiber.MIMEApplicationJSON)
+	ctx.Request.SetBody(body)
+	ctx.SetUserValue(batchCallerLocal, batchCaller{owner: b.Owner, tenant: b.Tenant})
+	s.handler(ctx)
+
+	var resBody interface{}
+	if err := json.Unmarshal(ctx.Response.Body(), &resBody); err != nil {
+		resBody = string(ctx.Response.Body())
+	}
+	res.Response = &schema.BatchResponse{
+		StatusCode: ctx.Response.StatusCode(),
+		RequestID:  newID("req_"),
+		Body:       resBody,
+	}
+	return res
+}
+
+// store saves the results of a batch with the Files API, and returns the IDs of the output and error files
+func (s *BatchService) store(b *storedBatch, output, errors *bytes.Buffer) (string, string, error) {
+	outputID, errorID := "", ""
+	if output.Len() > 0 {
+		f, err := s.files.Create(b.Owner, b.Tenant, b.ID+"_output.jsonl", "batch_output", output)
+		if err != nil {
+			return "", "", err
+		}
+		outputID = f.ID
+	}
+	if errors.Len() > 0 {
+		f, err := s.files.Create(b.Owner, b.Tenant, b.ID+"_error.jsonl", "batch_output", errors)
+		if err != nil {
+			return outputID, "", err
+		}
+		errorID = f.ID
+	}
+	return outputID, errorID, nil
+}
+
+func (s *BatchService) CreateBatchEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		req := new(schema.BatchRequest)
+		if err := c.BodyParser(req); err != nil {
+			return err
+		}
+		if !batchEndpoints[req.Endpoint] {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("unsupported endpoint %q", req.Endpoint))
+		}
+		if req.CompletionWindow == "" {
+			req.CompletionWindow = "24h"
+		}
+		if req.CompletionWindow != "24h" {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("unsupported completion window %q, only 24h is supported", req.CompletionWindow))
+		}
+		f, _, err := s.files.Get(c, req.InputFileID)
+		if err != nil {
+			return err
+		}
+		if f.Purpose != "batch" {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("the purpose of the input file must be batch, not %s", f.Purpose))
+		}
+
+		b := &storedBatch{
+			Batch: schema.Batch{
+				ID:               newID("batch_"),
+				Object:           "batch",
+				Endpoint:         req.Endpoint,
+				InputFileID:      req.InputFileID,
+				CompletionWindow: req.CompletionWindow,
+				Status:           schema.BatchValidating,
+				CreatedAt:        time.Now().Unix(),
+				Metadata:         req.Metadata,
+			},
+			Owner:  s.files.caller(c),
+			Tenant: s.files.tenant(c),
+		}
+
+		s.Lock()
+		defer s.Unlock()
+		select {
+		case s.queue <- b.ID:
+		default:
+			return fiber.NewError(fiber.StatusTooManyRequests, "too many batches are queued, retry later")
+		}
+		s.batches[b.ID] = b
+		s.save()
+		return c.JSON(b.Batch)
+	}
+}
+
+func (s *BatchService) ListBatchesEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		s.Lock()
+		defer s.Unlock()
+		batches := []*schema.Batch{}
+		for _, b := range s.batches {
+			if s.allowed(c, b) {
+				batches = append(batches, &b.Batch)
+			}
+		}
+		return list(c, batches, func(b *schema.Batch) string { return b.ID }, func(b *schema.Batch) int64 { return b.CreatedAt })
+	}
+}
+
+func (s *BatchService) GetBatchEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		s.Lock()
+		defer s.Unlock()
+		b, ok := s.batches[c.Params("batch_id")]
+		if !ok || !s.allowed(c, b) {
+			return fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("batch %s not found", c.Params("batch_id")))
+		}
+		return c.JSON(b.Batch)
+	}
+}
+
+func (s *BatchService) CancelBatchEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		s.Lock()
+		defer s.Unlock()
+		b, ok := s.batches[c.Params("batch_id")]
+		if !ok || !s.allowed(c, b) {
+			return fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("batch %s not found", c.Params("batch_id")))
+		}
+		if b.Status != schema.BatchValidating && b.Status != schema.BatchInProgress {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("can't cancel a batch with status %s", b.Status))
+		}
+
+		cancel, running := s.cancels[b.ID]
+		if running {
+			// the worker stores the results of the requests executed so far, then marks the batch as cancelled
+			cancel()
+			b.Status = schema.BatchCancelling
+			b.CancellingAt = time.Now().Unix()
+		} else {
+			b.Status = schema.BatchCancelled
+			b.CancelledAt = time.Now().Unix()
+		}
+		s.save()
+		return c.JSON(b.Batch)
+	}
+}
@@ -0,0 +1,97 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	fiberContext "github.com/go-skynet/LocalAI/api/ctx"
+	. "github.com/go-skynet/LocalAI/api/openai"
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/go-skynet/LocalAI/pkg/model"
+	"github.com/gofiber/fiber/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BatchService", func() {
+	var app *fiber.App
+
+	BeforeEach(func() {
+		dir := GinkgoT().TempDir()
+		models := filepath.Join(dir, "models")
+		for _, f := range []string{"shared.bin", "tenants/alice/model.bin", "tenants/bob/model.bin"} {
+			Expect(os.MkdirAll(filepath.Dir(filepath.Join(models, f)), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(models, f), []byte{}, 0600)).To(Succeed())
+		}
+		cm := config.NewConfigLoader()
+		loader := model.NewModelLoader(models)
+
+		// the requests of the batches answer with the model they resolved in the namespace of the caller
+		batchApp := fiber.New()
+		batchApp.Use(func(c *fiber.Ctx) error {
+			_, tenant, _ := BatchCaller(c)
+			fiberContext.SetNamespace(c, tenant, false)
+			return c.Next()
+		})
+		batchApp.Post("/v1/chat/completions", func(c *fiber.Ctx) error {
+			input := new(schema.OpenAIRequest)
+			if err := c.BodyParser(input); err != nil {
+				return err
+			}
+			m, err := fiberContext.ModelFromContext(c, cm, loader, input.Model, false)
+			if err != nil {
+				return fiber.NewError(fiber.StatusNotFound, err.Error())
+			}
+			return c.JSON(fiber.Map{"model": m})
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		DeferCleanup(cancel)
+		caller := func(c *fiber.Ctx) string { return c.Get("X-Caller") }
+		tenant := func(c *fiber.Ctx) string { return c.Get("X-Tenant") }
+		files := NewFilesService(filepath.Join(dir, "files"), 0, caller, tenant)
+		batches := NewBatchService(ctx, files, batchApp.Handler(), 1)
+
+		app = fiber.New()
+		app.Post("/v1/files", files.UploadFileEndpoint())
+		app.Get("/v1/files/:file_id/content", files.GetFileContentEndpoint())
+		app.Post("/v1/batches", batches.CreateBatchEndpoint())
+		app.Get("/v1/batches/:batch_id", batches.GetBatchEndpoint())
+	})
+
+	It("executes the requests in the models namespace of the caller which created the batch", func() {
+		id := upload(app, "alice", "alice", "batch", []byte(
+			`{"custom_id": "own", "method": "POST", "url": "/v1/chat/completions", "body": {"model": "model.bin"}}`+"\n"+
+				`{"custom_id": "other", "method": "POST", "url": "/v1/chat/completions", "body": {"model": "tenants/bob/model.bin"}}`+"\n"))
+
+		req := httptest.NewRequest("POST", "/v1/batches", strings.NewReader(`{"input_file_id": "`+id+`", "endpoint": "/v1/chat/completions"}`))
+		req.Header.Set("Content-Type", "application/json")
+		status, dat := call(app, req, "alice", "alice")
+		Expect(status).To(Equal(fiber.StatusOK))
+		b := schema.Batch{}
+		Expect(json.Unmarshal(dat, &b)).To(Succeed())
+
+		Eventually(func() string {
+			_, dat := call(app, httptest.NewRequest("GET", "/v1/batches/"+b.ID, nil), "alice", "alice")
+			Expect(json.Unmarshal(dat, &b)).To(Succeed())
+			return b.Status
+		}).Should(Equal(schema.BatchCompleted))
+		Expect(b.RequestCounts.Completed).To(Equal(1))
+		Expect(b.RequestCounts.Failed).To(Equal(1))
+
+		status, dat = call(app, httptest.NewRequest("GET", "/v1/files/"+b.OutputFileID+"/content", nil), "alice", "alice")
+		Expect(status).To(Equal(fiber.StatusOK))
+		Expect(string(dat)).To(ContainSubstring(`"custom_id":"own"`))
+		Expect(string(dat)).To(ContainSubstring(`"model":"tenants/alice/model.bin"`))
+
+		status, dat = call(app, httptest.NewRequest("GET", "/v1/files/"+b.ErrorFileID+"/content", nil), "alice", "alice")
+		Expect(status).To(Equal(fiber.StatusOK))
+		Expect(string(dat)).To(ContainSubstring(`"custom_id":"other"`))
+		Expect(string(dat)).To(ContainSubstring(`"status_code":404`))
+	})
+})
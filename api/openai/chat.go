@@ -10,6 +10,7 @@ import (
 
 	"github.com/go-skynet/LocalAI/api/backend"
 	config "github.com/go-skynet/LocalAI/api/config"
+	fiberContext "github.com/go-skynet/LocalAI/api/ctx"
 	"github.com/go-skynet/LocalAI/api/options"
 	"github.com/go-skynet/LocalAI/api/schema"
 	"github.com/go-skynet/LocalAI/pkg/grammar"
@@ -37,11 +38,15 @@ func ChatEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx)
 		responses <- initialMessage
 
 		ComputeChoices(req, s, config, o, loader, func(s string, c *[]schema.Choice) {}, func(s string, usage backend.TokenUsage) bool {
+			choice := schema.Choice{Delta: &schema.Message{Content: &s}, Index: 0}
+			if config.Logprobs {
+				choice.Logprobs = chatLogprobs(usage.Logprobs)
+			}
 			resp := schema.OpenAIResponse{
 				ID:      id,
 				Created: created,
 				Model:   req.Model, // we have to return what the user sent here, due to OpenAI spec.
-				Choices: []schema.Choice{{Delta: &schema.Message{Content: &s}, Index: 0}},
+				Choices: []schema.Choice{choice},
 				Object:  "chat.completion.chunk",
 				Usage: schema.OpenAIUsage{
 					PromptTokens:     usage.Prompt,
@@ -55,57 +60,121 @@ func ChatEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx)
 		})
 		close(responses)
 	}
+
+	// processFunctionCall streams the function call generated with the grammar of the functions: the name of the
+	// function first, and then fragments of its arguments. If no action is taken, the reply is streamed instead.
+	processFunctionCall := func(s string, req *schema.OpenAIRequest, config *config.Config, loader *model.ModelLoader, noActionName string, responses chan schema.OpenAIResponse, finishReason *string) {
+		defer close(responses)
+
+		chunk := func(delta *schema.Message, usage backend.TokenUsage) schema.OpenAIResponse {
+			return schema.OpenAIResponse{
+				ID:      id,
+				Created: created,
+				Model:   req.Model, // we have to return what the user sent here, due to OpenAI spec.
+				Choices: []schema.Choice{{Delta: delta, Index: 0}},
+				Object:  "chat.completion.chunk",
+				Usage: schema.OpenAIUsage{
+					PromptTokens:     usage.Prompt,
+					CompletionTokens: usage.Completion,
+					TotalTokens:      usage.Prompt + usage.Completion,
+				},
+			}
+		}
+		responses <- chunk(&schema.Message{Role: "assistant", Content: &emptyMessage}, backend.TokenUsage{})
+
+		useTools := len(req.Tools) > 0
+		callID := "call_" + uuid.New().String()
+		parser := grammar.NewFunctionCallParser()
+		_, usage, err := ComputeChoices(req, s, config, o, loader, func(s string, c *[]schema.Choice) {}, func(token string, usage backend.TokenUsage) bool {
+			name, args := parser.Write(token)
+			// the reply to send instead of the call is only known at the end
+			if parser.Name() == noActionName || (name == "" && args == "") {
+				return true
+			}
+
+			delta := &schema.Message{}
+			if useTools {
+				call := schema.ChatToolCall{Index: 0, Function: schema.ChatToolCallFunction{Arguments: args}}
+				if name != "" {
+					call.ID, call.Type, call.Function.Name = callID, "function", name
+				}
+				delta.ToolCalls = []schema.ChatToolCall{call}
+			} else {
+				call := map[string]interface{}{"arguments": args}
+				if name != "" {
+					call["name"] = name
+				}
+				delta.FunctionCall = call
+			}
+			responses <- chunk(delta, usage)
+			return true
+		})
+		if err != nil {
+			fiberContext.Logger(req.Context).Error().Msgf("inference error: %s", err.Error())
+			return
+		}
+
+		if parser.Name() != noActionName && parser.Name() != "" {
+			*finishReason = "function_call"
+			if useTools {
+				*finishReason = "tool_calls"
+			}
+			return
+		}
+
+		// If there is a message that the LLM already sends as part of the JSON reply, use it
+		arguments := map[string]interface{}{}
+		json.Unmarshal([]byte(parser.Arguments()), &arguments)
+		if message, ok := arguments["message"].(string); ok && message != "" {
+			message = backend.Finetune(*config, s, message)
+			responses <- chunk(&schema.Message{Content: &message}, usage)
+			return
+		}
+
+		fiberContext.Logger(req.Context).Debug().Msgf("No action received from LLM, without a message, streaming a reply")
+		reply := *config
+		reply.Grammar = ""
+		ComputeChoices(req, s, &reply, o, loader, func(s string, c *[]schema.Choice) {}, func(token string, usage backend.TokenUsage) bool {
+			responses <- chunk(&schema.Message{Content: &token}, usage)
+			return true
+		})
+	}
+
 	return func(c *fiber.Ctx) error {
 		processFunctions := false
 		funcs := grammar.Functions{}
-		modelFile, input, err := readRequest(c, o, true)
+		modelFile, input, err := readRequest(c, cm, o, true)
 		if err != nil {
 			return fmt.Errorf("failed reading parameters from request:%w", err)
 		}
+		logger := fiberContext.Logger(input.Context)
 
 		config, input, err := mergeRequestWithConfig(modelFile, input, cm, o.Loader, o.Debug, o.Threads, o.ContextSize, o.F16)
 		if err != nil {
 			return fmt.Errorf("failed reading parameters from request:%w", err)
 		}
-		log.Debug().Msgf("Configuration read: %+v", config)
-
-		// Allow the user to set custom actions via config file
-		// to be "embedded" in each model
-		noActionName := "answer"
-		noActionDescription := "use this action to answer without performing any action"
-
-		if config.FunctionsConfig.NoActionFunctionName != "" {
-			noActionName = config.FunctionsConfig.NoActionFunctionName
-		}
-		if config.FunctionsConfig.NoActionDescriptionName != "" {
-			noActionDescription = config.FunctionsConfig.NoActionDescriptionName
+		logger.Debug().Msgf("Configuration read: %+v", config)
+		if err := readImages(config, input.Messages, o); err != nil {
+			return err
 		}
+		queue := trackQueue(c, input)
+
+		noActionGrammar := noAction(config)
+		noActionName := noActionGrammar.Name
 
-		if input.ResponseFormat.Type == "json_object" {
-			input.Grammar = grammar.JSONBNF
+		if err := responseFormatGrammar(config, input); err != nil {
+			return err
 		}
 
 		// process functions if we have any defined or if we have a function call string
 		if len(input.Functions) > 0 && config.ShouldUseFunctions() {
-			log.Debug().Msgf("Response needs to process functions")
+			logger.Debug().Msgf("Response needs to process functions")
 
 			processFunctions = true
 
-			noActionGrammar := grammar.Function{
-				Name:        noActionName,
-				Description: noActionDescription,
-				Parameters: map[string]interface{}{
-					"properties": map[string]interface{}{
-						"message": map[string]interface{}{
-							"type":        "string",
-							"description": "The message to reply the user with",
-						}},
-				},
-			}
-
-			// Append the no action function
+			// Append the no action function, unless a tool has to be called
 			funcs = append(funcs, input.Functions...)
-			if !config.FunctionsConfig.DisableNoAction {
+			if !config.FunctionsConfig.DisableNoAction && input.ToolChoice != "required" {
 				funcs = append(funcs, noActionGrammar)
 			}
 
@@ -116,101 +185,24 @@ func ChatEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx)
 
 			// Update input grammar
 			jsStruct := funcs.ToJSONStructure()
-			config.Grammar = jsStruct.Grammar("")
+			config.Grammar = jsStruct.Grammar(grammar.FunctionCallOrder)
 		} else if input.JSONFunctionGrammarObject != nil {
 			config.Grammar = input.JSONFunctionGrammarObject.Grammar("")
 		}
 
-		// functions are not supported in stream mode (yet?)
-		toStream := input.Stream && !processFunctions
-
-		log.Debug().Msgf("Parameters: %+v", config)
+		toStream := input.Stream
 
-		var predInput string
+		logger.Debug().Msgf("Parameters: %+v", config)
 
-		suppressConfigSystemPrompt := false
-		mess := []string{}
-		for messageIndex, i := range input.Messages {
-			var content string
-			role := i.Role
-
-			// if function call, we might want to customize the role so we can display better that the "assistant called a json action"
-			// if an "assistant_function_call" role is defined, we use it, otherwise we use the role that is passed by in the request
-			if i.FunctionCall != nil && i.Role == "assistant" {
-				roleFn := "assistant_function_call"
-				r := config.Roles[roleFn]
-				if r != "" {
-					role = roleFn
-				}
-			}
-			r := config.Roles[role]
-			contentExists := i.Content != nil && i.StringContent != ""
-			// First attempt to populate content via a chat message specific template
-			if config.TemplateConfig.ChatMessage != "" {
-				chatMessageData := model.ChatMessageTemplateData{
-					SystemPrompt: config.SystemPrompt,
-					Role:         r,
-					RoleName:     role,
-					Content:      i.StringContent,
-					MessageIndex: messageIndex,
-				}
-				templatedChatMessage, err := o.Loader.EvaluateTemplateForChatMessage(config.TemplateConfig.ChatMessage, chatMessageData)
-				if err != nil {
-					log.Error().Msgf("error processing message %+v using template \"%s\": %v. Skipping!", chatMessageData, config.TemplateConfig.ChatMessage, err)
-				} else {
-					if templatedChatMessage == "" {
-						log.Warn().Msgf("template \"%s\" produced blank output for %+v. Skipping!", config.TemplateConfig.ChatMessage, chatMessageData)
-						continue // TODO: This continue is here intentionally to skip over the line `mess = append(mess, content)` below, and to prevent the sprintf
-					}
-					log.Debug().Msgf("templated message for chat: %s", templatedChatMessage)
-					content = templatedChatMessage
-				}
-			}
-			// If this model doesn't have such a template, or if that template fails to return a value, template at the message level.
-			if content == "" {
-				if r != "" {
-					if contentExists {
-						content = fmt.Sprint(r, i.StringContent)
-					}
-					if i.FunctionCall != nil {
-						j, err := json.Marshal(i.FunctionCall)
-						if err == nil {
-							if contentExists {
-								content += "\n" + fmt.Sprint(r, " ", string(j))
-							} else {
-								content = fmt.Sprint(r, " ", string(j))
-							}
-						}
-					}
-				} else {
-					if contentExists {
-						content = fmt.Sprint(i.StringContent)
-					}
-					if i.FunctionCall != nil {
-						j, err := json.Marshal(i.FunctionCall)
-						if err == nil {
-							if contentExists {
-								content += "\n" + string(j)
-							} else {
-								content = string(j)
-							}
-						}
-					}
-				}
-				// Special Handling: System. We care if it was printed at all, not the r branch, so check seperately
-				if contentExists && role == "system" {
-					suppressConfigSystemPrompt = true
-				}
-			}
+		predInput := chatPrompt(config, o.Loader, input.Messages, funcs, processFunctions)
 
-			mess = append(mess, content)
+		cached, cache := lookupResponse(c, cm, o, config, input, []string{predInput}, "chat.completion")
+		if cached != nil {
+			return c.JSON(cached)
 		}
 
-		predInput = strings.Join(mess, "\n")
-		log.Debug().Msgf("Prompt (before templating): %s", predInput)
-
 		if toStream {
-			log.Debug().Msgf("Stream request received")
+			logger.Debug().Msgf("Stream request received")
 			c.Context().SetContentType("text/event-stream")
 			//c.Response().Header.SetContentType(fiber.MIMETextHTMLCharsetUTF8)
 			//	c.Set("Content-Type", "text/event-stream")
@@ -219,63 +211,56 @@ func ChatEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx)
 			c.Set("Transfer-Encoding", "chunked")
 		}
 
-		templateFile := ""
-
-		// A model can have a "file.bin.tmpl" file associated with a prompt template prefix
-		if o.Loader.ExistsInModelPath(fmt.Sprintf("%s.tmpl", config.Model)) {
-			templateFile = config.Model
-		}
-
-		if config.TemplateConfig.Chat != "" && !processFunctions {
-			templateFile = config.TemplateConfig.Chat
-		}
-
-		if config.TemplateConfig.Functions != "" && processFunctions {
-			templateFile = config.TemplateConfig.Functions
-		}
-
-		if templateFile != "" {
-			templatedInput, err := o.Loader.EvaluateTemplateForPrompt(model.ChatPromptTemplate, templateFile, model.PromptTemplateData{
-				SystemPrompt:         config.SystemPrompt,
-				SuppressSystemPrompt: suppressConfigSystemPrompt,
-				Input:                predInput,
-				Functions:            funcs,
-			})
-			if err == nil {
-				predInput = templatedInput
-				log.Debug().Msgf("Template found, input modified to: %s", predInput)
-			} else {
-				log.Debug().Msgf("Template failed loading: %s", err.Error())
-			}
-		}
-
-		log.Debug().Msgf("Prompt (after templating): %s", predInput)
 		if processFunctions {
-			log.Debug().Msgf("Grammar: %+v", config.Grammar)
+			logger.Debug().Msgf("Grammar: %+v", config.Grammar)
 		}
 
 		if toStream {
 			responses := make(chan schema.OpenAIResponse)
+			finishReason := "stop"
 
-			go process(predInput, input, config, o.Loader, responses)
+			if processFunctions {
+				go processFunctionCall(predInput, input, config, o.Loader, noActionName, responses, &finishReason)
+			} else {
+				go process(predInput, input, config, o.Loader, responses)
+			}
 
 			c.Context().SetBodyStreamWriter(fasthttp.StreamWriter(func(w *bufio.Writer) {
 
 				usage := &schema.OpenAIUsage{}
 
-				for ev := range responses {
+			loop:
+				for {
+					var ev schema.OpenAIResponse
+					select {
+					case <-queue.Changed():
+						if err := queue.writeEvent(w); err != nil {
+							logger.Debug().Msgf("Sending queue status failed: %v", err)
+						}
+						continue
+					case r, ok := <-responses:
+						if !ok {
+							break loop
+						}
+						ev = r
+					}
 					usage = &ev.Usage // Copy a pointer to the latest usage chunk so that the stop message can reference it
 					var buf bytes.Buffer
 					enc := json.NewEncoder(&buf)
 					enc.Encode(ev)
-					log.Debug().Msgf("Sending chunk: %s", buf.String())
+					logger.Debug().Msgf("Sending chunk: %s", buf.String())
 					_, err := fmt.Fprintf(w, "data: %v\n", buf.String())
+					if err == nil {
+						err = w.Flush()
+					}
 					if err != nil {
-						log.Debug().Msgf("Sending chunk failed: %v", err)
+						logger.Debug().Msgf("Sending chunk failed: %v", err)
+						// the client disconnected: stop the generation on the backend
 						input.Cancel()
-						break
+						for range responses {
+						}
+						return
 					}
-					w.Flush()
 				}
 
 				resp := &schema.OpenAIResponse{
@@ -284,7 +269,7 @@ func ChatEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx)
 					Model:   input.Model, // we have to return what the user sent here, due to OpenAI spec.
 					Choices: []schema.Choice{
 						{
-							FinishReason: "stop",
+							FinishReason: finishReason,
 							Index:        0,
 							Delta:        &schema.Message{Content: &emptyMessage},
 						}},
@@ -302,12 +287,11 @@ func ChatEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx)
 
 		result, tokenUsage, err := ComputeChoices(input, predInput, config, o, o.Loader, func(s string, c *[]schema.Choice) {
 			if processFunctions {
-				// As we have to change the result before processing, we can't stream the answer (yet?)
 				ss := map[string]interface{}{}
 				// This prevent newlines to break JSON parsing for clients
 				s = utils.EscapeNewLines(s)
 				json.Unmarshal([]byte(s), &ss)
-				log.Debug().Msgf("Function return: %s %+v", s, ss)
+				logger.Debug().Msgf("Function return: %s %+v", s, ss)
 
 				// The grammar defines the function name as "function", while OpenAI returns "name"
 				func_name := ss["function"]
@@ -320,7 +304,7 @@ func ChatEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx)
 
 				// if do nothing, reply with a message
 				if func_name == noActionName {
-					log.Debug().Msgf("nothing to do, computing a reply")
+					logger.Debug().Msgf("nothing to do, computing a reply")
 
 					// If there is a message that the LLM already sends as part of the JSON reply, use it
 					arguments := map[string]interface{}{}
@@ -330,9 +314,9 @@ func ChatEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx)
 						switch message := m.(type) {
 						case string:
 							if message != "" {
-								log.Debug().Msgf("Reply received from LLM: %s", message)
+								logger.Debug().Msgf("Reply received from LLM: %s", message)
 								message = backend.Finetune(*config, predInput, message)
-								log.Debug().Msgf("Reply received from LLM(finetuned): %s", message)
+								logger.Debug().Msgf("Reply received from LLM(finetuned): %s", message)
 
 								*c = append(*c, schema.Choice{Message: &schema.Message{Role: "assistant", Content: &message}})
 								return
@@ -340,7 +324,7 @@ func ChatEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx)
 						}
 					}
 
-					log.Debug().Msgf("No action received from LLM, without a message, computing a reply")
+					logger.Debug().Msgf("No action received from LLM, without a message, computing a reply")
 					// Otherwise ask the LLM to understand the JSON output and the context, and return a message
 					// Note: This costs (in term of CPU) another computation
 					config.Grammar = ""
@@ -350,20 +334,30 @@ func ChatEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx)
 					}
 					predFunc, err := backend.ModelInference(input.Context, predInput, images, o.Loader, *config, o, nil)
 					if err != nil {
-						log.Error().Msgf("inference error: %s", err.Error())
+						logger.Error().Msgf("inference error: %s", err.Error())
 						return
 					}
 
 					prediction, err := predFunc()
 					if err != nil {
-						log.Error().Msgf("inference error: %s", err.Error())
+						logger.Error().Msgf("inference error: %s", err.Error())
 						return
 					}
 
 					fineTunedResponse := backend.Finetune(*config, predInput, prediction.Response)
 					*c = append(*c, schema.Choice{Message: &schema.Message{Role: "assistant", Content: &fineTunedResponse}})
+				} else if len(input.Tools) > 0 {
+					// otherwise reply with the tool call
+					*c = append(*c, schema.Choice{
+						FinishReason: "tool_calls",
+						Message: &schema.Message{Role: "assistant", ToolCalls: []schema.ChatToolCall{{
+							ID:       "call_" + uuid.New().String(),
+							Type:     "function",
+							Function: schema.ChatToolCallFunction{Name: fmt.Sprint(func_name), Arguments: string(d)},
+						}}},
+					})
 				} else {
-					// otherwise reply with the function call
+					// or with the function call
 					*c = append(*c, schema.Choice{
 						FinishReason: "function_call",
 						Message:      &schema.Message{Role: "assistant", FunctionCall: ss},
@@ -378,6 +372,19 @@ func ChatEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx)
 			return err
 		}
 
+		if !processFunctions {
+			for _, r := range result {
+				if r.Message == nil {
+					continue
+				}
+				if content, ok := r.Message.Content.(*string); ok {
+					if err := validateResponseFormat(input, *content); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
 		resp := &schema.OpenAIResponse{
 			ID:      id,
 			Created: created,
@@ -391,9 +398,173 @@ func ChatEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx)
 			},
 		}
 		respData, _ := json.Marshal(resp)
-		log.Debug().Msgf("Response: %s", respData)
+		logger.Debug().Msgf("Response: %s", respData)
+		cache.store(resp)
 
+		queue.setHeaders(c)
 		// Return the prediction in the response body
 		return c.JSON(resp)
 	}
 }
+
+// ChatPrompt renders the messages into the prompt for the model, like the chat completions without functions
+func ChatPrompt(config *config.Config, loader *model.ModelLoader, messages []schema.Message) string {
+	input := &schema.OpenAIRequest{Messages: messages}
+	// decode the content of the messages
+	updateRequestConfig(config, input)
+	return chatPrompt(config, loader, input.Messages, nil, false)
+}
+
+// chatPrompt renders the messages into the prompt for the model, with its chat message and chat (or functions) templates
+func chatPrompt(config *config.Config, loader *model.ModelLoader, messages []schema.Message, funcs grammar.Functions, processFunctions bool) string {
+	suppressConfigSystemPrompt := false
+	mess := []string{}
+	for messageIndex, i := range messages {
+		var content string
+		role := i.Role
+
+		// tool calls and their results are rendered like function calls
+		if i.FunctionCall == nil && len(i.ToolCalls) > 0 {
+			i.FunctionCall = map[string]interface{}{"name": i.ToolCalls[0].Function.Name, "arguments": i.ToolCalls[0].Function.Arguments}
+		}
+		if role == "tool" && config.Roles[role] == "" {
+			role = "function"
+		}
+
+		// if function call, we might want to customize the role so we can display better that the "assistant called a json action"
+		// if an "assistant_function_call" role is defined, we use it, otherwise we use the role that is passed by in the request
+		if i.FunctionCall != nil && i.Role == "assistant" {
+			roleFn := "assistant_function_call"
+			r := config.Roles[roleFn]
+			if r != "" {
+				role = roleFn
+			}
+		}
+		r := config.Roles[role]
+		contentExists := i.Content != nil && i.StringContent != ""
+		// First attempt to populate content via a chat message specific template
+		if config.TemplateConfig.ChatMessage != "" {
+			chatMessageData := model.ChatMessageTemplateData{
+				SystemPrompt: config.SystemPrompt,
+				Role:         r,
+				RoleName:     role,
+				Content:      i.StringContent,
+				MessageIndex: messageIndex,
+			}
+			templatedChatMessage, err := loader.EvaluateTemplateForChatMessage(config.TemplateConfig.ChatMessage, chatMessageData)
+			if err != nil {
+				log.Error().Msgf("error processing message %+v using template \"%s\": %v. Skipping!", chatMessageData, config.TemplateConfig.ChatMessage, err)
+			} else {
+				if templatedChatMessage == "" {
+					log.Warn().Msgf("template \"%s\" produced blank output for %+v. Skipping!", config.TemplateConfig.ChatMessage, chatMessageData)
+					continue // TODO: This continue is here intentionally to skip over the line `mess = append(mess, content)` below, and to prevent the sprintf
+				}
+				log.Debug().Msgf("templated message for chat: %s", templatedChatMessage)
+				content = templatedChatMessage
+			}
+		}
+		// If this model doesn't have such a template, or if that template fails to return a value, template at the message level.
+		if content == "" {
+			if r != "" {
+				if contentExists {
+					content = fmt.Sprint(r, i.StringContent)
+				}
+				if i.FunctionCall != nil {
+					j, err := json.Marshal(i.FunctionCall)
+					if err == nil {
+						if contentExists {
+							content += "\n" + fmt.Sprint(r, " ", string(j))
+						} else {
+							content = fmt.Sprint(r, " ", string(j))
+						}
+					}
+				}
+			} else {
+				if contentExists {
+					content = fmt.Sprint(i.StringContent)
+				}
+				if i.FunctionCall != nil {
+					j, err := json.Marshal(i.FunctionCall)
+					if err == nil {
+						if contentExists {
+							content += "\n" + string(j)
+						} else {
+							content = string(j)
+						}
+					}
+				}
+			}
+		}
+		// Special Handling: System. We care if it was printed at all, not the r branch, so check seperately.
+		// It also applies to the chat message templates, which render the system messages themselves
+		if contentExists && role == "system" {
+			suppressConfigSystemPrompt = true
+		}
+
+		mess = append(mess, content)
+	}
+
+	predInput := strings.Join(mess, "\n")
+	log.Debug().Msgf("Prompt (before templating): %s", predInput)
+
+	templateFile := ""
+
+	// A model can have a "file.bin.tmpl" file associated with a prompt template prefix
+	if loader.ExistsInModelPath(fmt.Sprintf("%s.tmpl", config.Model)) {
+		templateFile = config.Model
+	}
+
+	if config.TemplateConfig.Chat != "" && !processFunctions {
+		templateFile = config.TemplateConfig.Chat
+	}
+
+	if config.TemplateConfig.Functions != "" && processFunctions {
+		templateFile = config.TemplateConfig.Functions
+	}
+
+	if templateFile != "" {
+		templatedInput, err := loader.EvaluateTemplateForPrompt(model.ChatPromptTemplate, templateFile, model.PromptTemplateData{
+			SystemPrompt:         config.SystemPrompt,
+			SuppressSystemPrompt: suppressConfigSystemPrompt,
+			Input:                predInput,
+			Functions:            funcs,
+		})
+		if err == nil {
+			predInput = templatedInput
+			log.Debug().Msgf("Template found, input modified to: %s", predInput)
+		} else {
+			log.Debug().Msgf("Template failed loading: %s", err.Error())
+		}
+	}
+
+	log.Debug().Msgf("Prompt (after templating): %s", predInput)
+
+	return predInput
+}
+
+// noAction returns the function the model picks to reply with a message, instead of calling one of the functions
+func noAction(config *config.Config) grammar.Function {
+	// Allow the user to set custom actions via config file
+	// to be "embedded" in each model
+	noActionName := "answer"
+	noActionDescription := "use this action to answer without performing any action"
+
+	if config.FunctionsConfig.NoActionFunctionName != "" {
+		noActionName = config.FunctionsConfig.NoActionFunctionName
+	}
+	if config.FunctionsConfig.NoActionDescriptionName != "" {
+		noActionDescription = config.FunctionsConfig.NoActionDescriptionName
+	}
+
+	return grammar.Function{
+		Name:        noActionName,
+		Description: noActionDescription,
+		Parameters: map[string]interface{}{
+			"properties": map[string]interface{}{
+				"message": map[string]interface{}{
+					"type":        "string",
+					"description": "The message to reply the user with",
+				}},
+		},
+	}
+}
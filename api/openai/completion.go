@@ -7,16 +7,16 @@ import (
 	"errors"
 	"fmt"
 	"time"
+	"unicode/utf8"
 
 	"github.com/go-skynet/LocalAI/api/backend"
 	config "github.com/go-skynet/LocalAI/api/config"
+	fiberContext "github.com/go-skynet/LocalAI/api/ctx"
 	"github.com/go-skynet/LocalAI/api/options"
 	"github.com/go-skynet/LocalAI/api/schema"
-	"github.com/go-skynet/LocalAI/pkg/grammar"
 	model "github.com/go-skynet/LocalAI/pkg/model"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
-	"github.com/rs/zerolog/log"
 	"github.com/valyala/fasthttp"
 )
 
@@ -26,25 +26,30 @@ func CompletionEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fibe
 	created := int(time.Now().Unix())
 
 	process := func(s string, req *schema.OpenAIRequest, config *config.Config, loader *model.ModelLoader, responses chan schema.OpenAIResponse) {
+		// the offset in the generated text of the logprobs of the next chunk
+		offset := 0
 		ComputeChoices(req, s, config, o, loader, func(s string, c *[]schema.Choice) {}, func(s string, usage backend.TokenUsage) bool {
+			choice := schema.Choice{
+				Index: 0,
+				Text:  s,
+			}
+			if config.Logprobs {
+				choice.Logprobs = completionLogprobs(chatLogprobs(usage.Logprobs), offset)
+				offset += utf8.RuneCountInString(s)
+			}
 			resp := schema.OpenAIResponse{
 				ID:      id,
 				Created: created,
 				Model:   req.Model, // we have to return what the user sent here, due to OpenAI spec.
-				Choices: []schema.Choice{
-					{
-						Index: 0,
-						Text:  s,
-					},
-				},
-				Object: "text_completion",
+				Choices: []schema.Choice{choice},
+				Object:  "text_completion",
 				Usage: schema.OpenAIUsage{
 					PromptTokens:     usage.Prompt,
 					CompletionTokens: usage.Completion,
 					TotalTokens:      usage.Prompt + usage.Completion,
 				},
 			}
-			log.Debug().Msgf("Sending goroutine: %s", s)
+			fiberContext.Logger(req.Context).Debug().Msgf("Sending goroutine: %s", s)
 
 			responses <- resp
 			return true
@@ -53,26 +58,29 @@ func CompletionEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fibe
 	}
 
 	return func(c *fiber.Ctx) error {
-		modelFile, input, err := readRequest(c, o, true)
+		modelFile, input, err := readRequest(c, cm, o, true)
 		if err != nil {
 			return fmt.Errorf("failed reading parameters from request:%w", err)
 		}
+		logger := fiberContext.Logger(input.Context)
 
-		log.Debug().Msgf("`input`: %+v", input)
+		logger.Debug().Msgf("`input`: %+v", input)
 
 		config, input, err := mergeRequestWithConfig(modelFile, input, cm, o.Loader, o.Debug, o.Threads, o.ContextSize, o.F16)
 		if err != nil {
 			return fmt.Errorf("failed reading parameters from request:%w", err)
 		}
 
-		if input.ResponseFormat.Type == "json_object" {
-			input.Grammar = grammar.JSONBNF
+		if err := responseFormatGrammar(config, input); err != nil {
+			return err
 		}
 
-		log.Debug().Msgf("Parameter Config: %+v", config)
+		queue := trackQueue(c, input)
+
+		logger.Debug().Msgf("Parameter Config: %+v", config)
 
 		if input.Stream {
-			log.Debug().Msgf("Stream request received")
+			logger.Debug().Msgf("Stream request received")
 			c.Context().SetContentType("text/event-stream")
 			//c.Response().Header.SetContentType(fiber.MIMETextHTMLCharsetUTF8)
 			//c.Set("Content-Type", "text/event-stream")
@@ -92,6 +100,11 @@ func CompletionEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fibe
 			templateFile = config.TemplateConfig.Completion
 		}
 
+		cached, cache := lookupResponse(c, cm, o, config, input, config.PromptStrings, "text_completion", templateFile, config.SystemPrompt)
+		if cached != nil {
+			return c.JSON(cached)
+		}
+
 		if input.Stream {
 			if len(config.PromptStrings) > 1 {
 				return errors.New("cannot handle more than 1 `PromptStrings` when Streaming")
@@ -105,7 +118,7 @@ func CompletionEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fibe
 				})
 				if err == nil {
 					predInput = templatedInput
-					log.Debug().Msgf("Template found, input modified to: %s", predInput)
+					logger.Debug().Msgf("Template found, input modified to: %s", predInput)
 				}
 			}
 
@@ -115,14 +128,38 @@ func CompletionEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fibe
 
 			c.Context().SetBodyStreamWriter(fasthttp.StreamWriter(func(w *bufio.Writer) {
 
-				for ev := range responses {
+			loop:
+				for {
+					var ev schema.OpenAIResponse
+					select {
+					case <-queue.Changed():
+						if err := queue.writeEvent(w); err != nil {
+							logger.Debug().Msgf("Sending queue status failed: %v", err)
+						}
+						continue
+					case r, ok := <-responses:
+						if !ok {
+							break loop
+						}
+						ev = r
+					}
 					var buf bytes.Buffer
 					enc := json.NewEncoder(&buf)
 					enc.Encode(ev)
 
-					log.Debug().Msgf("Sending chunk: %s", buf.String())
-					fmt.Fprintf(w, "data: %v\n", buf.String())
-					w.Flush()
+					logger.Debug().Msgf("Sending chunk: %s", buf.String())
+					_, err := fmt.Fprintf(w, "data: %v\n", buf.String())
+					if err == nil {
+						err = w.Flush()
+					}
+					if err != nil {
+						logger.Debug().Msgf("Sending chunk failed: %v", err)
+						// the client disconnected: stop the generation on the backend
+						input.Cancel()
+						for range responses {
+						}
+						return
+					}
 				}
 
 				resp := &schema.OpenAIResponse{
@@ -159,7 +196,7 @@ func CompletionEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fibe
 				})
 				if err == nil {
 					i = templatedInput
-					log.Debug().Msgf("Template found, input modified to: %s", i)
+					logger.Debug().Msgf("Template found, input modified to: %s", i)
 				}
 			}
 
@@ -170,6 +207,12 @@ func CompletionEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fibe
 			if err != nil {
 				return err
 			}
+			for j, c := range r {
+				if err := validateResponseFormat(input, c.Text); err != nil {
+					return err
+				}
+				r[j].Logprobs = completionLogprobs(c.Logprobs, 0)
+			}
 
 			totalTokenUsage.Prompt += tokenUsage.Prompt
 			totalTokenUsage.Completion += tokenUsage.Completion
@@ -191,8 +234,10 @@ func CompletionEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fibe
 		}
 
 		jsonResult, _ := json.Marshal(resp)
-		log.Debug().Msgf("Response: %s", jsonResult)
+		logger.Debug().Msgf("Response: %s", jsonResult)
+		cache.store(resp)
 
+		queue.setHeaders(c)
 		// Return the prediction in the response body
 		return c.JSON(resp)
 	}
@@ -0,0 +1,104 @@
+package openai
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/go-skynet/LocalAI/pkg/downloader"
+	"github.com/go-skynet/LocalAI/pkg/imaging"
+	"github.com/gofiber/fiber/v2"
+)
+
+// the size the images of the chat messages are downscaled to, on their largest side, unless set by the model
+const defaultMaxImageSize = 1344
+
+// messageContent returns the text of the parts of a message content, with a [img-N] placeholder where each image is,
+// and the URLs of its images. The images are numbered from index, across the messages of the request, as the vision
+// backends number them.
+func messageContent(parts []schema.Content, index int) (string, []string) {
+	var content strings.Builder
+	images := []string{}
+	text := false
+	for _, p := range parts {
+		switch p.Type {
+		case "text":
+			if text {
+				content.WriteString("\n")
+			}
+			content.WriteString(p.Text)
+			text = true
+		case "image_url":
+			fmt.Fprintf(&content, "[img-%d]", index+len(images))
+			images = append(images, p.ImageURL.URL)
+			text = false
+		}
+	}
+	return content.String(), images
+}
+
+// readImages replaces the URLs of the images of the messages by the images, base64 encoded and downscaled to the max
+// image size of the model. The images which can't be read are refused with a 400 error.
+func readImages(cfg *config.Config, messages []schema.Message, o *options.Option) error {
+	maxSize := cfg.MaxImageSize
+	if maxSize == 0 {
+		maxSize = defaultMaxImageSize
+	}
+	limit := int64(o.UploadLimitMB) * 1024 * 1024
+	for i, m := range messages {
+		for j, u := range m.StringImages {
+			dat, err := readImage(u, limit)
+			if err == nil {
+				dat, err = imaging.Downscale(dat, maxSize)
+			}
+			if err != nil {
+				return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("cannot read the image %d of the message %d: %v", j, i, err))
+			}
+			messages[i].StringImages[j] = base64.StdEncoding.EncodeToString(dat)
+		}
+	}
+	return nil
+}
+
+// readImage returns the image of a data URI, or downloads the image of a remote URL, up to limit bytes
+func readImage(u string, limit int64) ([]byte, error) {
+	if data, ok := strings.CutPrefix(u, "data:"); ok {
+		media, encoded, ok := strings.Cut(data, ",")
+		if !ok || !strings.HasSuffix(media, ";base64") {
+			return nil, fmt.Errorf("the data URIs of the images must be base64 encoded")
+		}
+		return base64.StdEncoding.DecodeString(encoded)
+	}
+	if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+		return nil, fmt.Errorf("the image must be a data URI or an http(s) URL")
+	}
+
+	resp, err := downloader.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading %s: %s", u, resp.Status)
+	}
+	if limit > 0 && resp.ContentLength > limit {
+		return nil, fmt.Errorf("the image is larger than %d bytes", limit)
+	}
+	body := io.Reader(resp.Body)
+	if limit > 0 {
+		body = io.LimitReader(resp.Body, limit+1)
+	}
+	dat, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && int64(len(dat)) > limit {
+		return nil, fmt.Errorf("the image is larger than %d bytes", limit)
+	}
+	return dat, nil
+}
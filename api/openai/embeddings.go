@@ -7,56 +7,65 @@ import (
 
 	"github.com/go-skynet/LocalAI/api/backend"
 	config "github.com/go-skynet/LocalAI/api/config"
+	fiberContext "github.com/go-skynet/LocalAI/api/ctx"
 	"github.com/go-skynet/LocalAI/api/schema"
 	"github.com/google/uuid"
 
 	"github.com/go-skynet/LocalAI/api/options"
 	"github.com/gofiber/fiber/v2"
-	"github.com/rs/zerolog/log"
 )
 
 // https://platform.openai.com/docs/api-reference/embeddings
 func EmbeddingsEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx) error {
 	return func(c *fiber.Ctx) error {
-		model, input, err := readRequest(c, o, true)
+		model, input, err := readRequest(c, cm, o, true)
 		if err != nil {
 			return fmt.Errorf("failed reading parameters from request:%w", err)
 		}
+		logger := fiberContext.Logger(input.Context)
 
 		config, input, err := mergeRequestWithConfig(model, input, cm, o.Loader, o.Debug, o.Threads, o.ContextSize, o.F16)
 		if err != nil {
 			return fmt.Errorf("failed reading parameters from request:%w", err)
 		}
 
-		log.Debug().Msgf("Parameter Config: %+v", config)
+		logger.Debug().Msgf("Parameter Config: %+v", config)
 		items := []schema.Item{}
 
-		for i, s := range config.InputToken {
-			// get the model function to call for the result
-			embedFn, err := backend.ModelEmbedding("", s, o.Loader, *config, o)
+		if len(config.Ensemble.Models) > 0 && len(config.InputToken) > 0 {
+			return fmt.Errorf("the models of ensemble %q embed strings only, not tokens", config.Name)
+		}
+		if len(config.InputToken) > 0 {
+			vectors, err := backend.ModelEmbeddings(nil, config.InputToken, o.Loader, *config, o)
 			if err != nil {
 				return err
 			}
-
-			embeddings, err := embedFn()
-			if err != nil {
-				return err
+			for i, embeddings := range vectors {
+				items = append(items, schema.Item{Embedding: embeddings, Index: i, Object: "embedding"})
 			}
-			items = append(items, schema.Item{Embedding: embeddings, Index: i, Object: "embedding"})
 		}
 
-		for i, s := range config.InputStrings {
-			// get the model function to call for the result
-			embedFn, err := backend.ModelEmbedding(s, []int{}, o.Loader, *config, o)
-			if err != nil {
-				return err
+		// the models of an ensemble are called one by one, and their embeddings fused
+		if len(config.Ensemble.Models) > 0 {
+			for i, s := range config.InputStrings {
+				vectors, err := backend.ModelEnsembleEmbedding(s, config.Ensemble, cm, o)
+				if err != nil {
+					return err
+				}
+				embeddings, err := backend.FuseEnsembleEmbedding(config.Ensemble, vectors)
+				if err != nil {
+					return err
+				}
+				items = append(items, schema.Item{Embedding: embeddings, Index: i, Object: "embedding"})
 			}
-
-			embeddings, err := embedFn()
+		} else if len(config.InputStrings) > 0 {
+			vectors, err := backend.ModelEmbeddings(config.InputStrings, nil, o.Loader, *config, o)
 			if err != nil {
 				return err
 			}
-			items = append(items, schema.Item{Embedding: embeddings, Index: i, Object: "embedding"})
+			for i, embeddings := range vectors {
+				items = append(items, schema.Item{Embedding: embeddings, Index: i, Object: "embedding"})
+			}
 		}
 
 		id := uuid.New().String()
@@ -70,7 +79,7 @@ func EmbeddingsEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fibe
 		}
 
 		jsonResult, _ := json.Marshal(resp)
-		log.Debug().Msgf("Response: %s", jsonResult)
+		logger.Debug().Msgf("Response: %s", jsonResult)
 
 		// Return the prediction in the response body
 		return c.JSON(resp)
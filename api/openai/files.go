@@ -0,0 +1,248 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	fiberContext "github.com/go-skynet/LocalAI/api/ctx"
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// purposes of the files accepted by the Files API
+var filePurposes = map[string]bool{
+	"fine-tune":         true,
+	"fine-tune-results": true,
+	"assistants":        true,
+	"assistants_output": true,
+	"batch":             true,
+	"batch_output":      true,
+	"voice":             true,
+}
+
+// storedFile is a file with the caller which uploaded it
+type storedFile struct {
+	schema.File
+	Owner  string `json:"owner"`
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// FilesService implements the OpenAI Files API, storing the files in a local directory. The files are only
+// available to the caller which uploaded them, and to the callers of its tenant.
+type FilesService struct {
+	sync.Mutex
+	dir   string
+	quota int64
+	files map[string]*storedFile
+	// the caller of a request, and its tenant
+	caller func(c *fiber.Ctx) string
+	tenant func(c *fiber.Ctx) string
+}
+
+// NewFilesService stores the files in dir, up to quota bytes in total (0 means no limit), the caller and the tenant
+// identifying the owners of the files
+func NewFilesService(dir string, quota int64, caller, tenant func(c *fiber.Ctx) string) *FilesService {
+	s := &FilesService{dir: dir, quota: quota, files: map[string]*storedFile{}, caller: caller, tenant: tenant}
+
+	dat, err := os.ReadFile(s.index())
+	if err == nil {
+		err = json.Unmarshal(dat, &s.files)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		log.Error().Msgf("failed reading the files index %s: %s", s.index(), err.Error())
+	}
+	return s
+}
+
+func (s *FilesService) index() string {
+	return filepath.Join(s.dir, "files.json")
+}
+
+func (s *FilesService) path(id string) string {
+	return filepath.Join(s.dir, id)
+}
+
+// save persists the index of the files, it must be called with the lock held
+func (s *FilesService) save() error {
+	dat, err := json.Marshal(s.files)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.index(), dat, 0600)
+}
+
+func (s *FilesService) used() int64 {
+	var total int64
+	for _, f := range s.files {
+		total += f.Bytes
+	}
+	return total
+}
+
+// Create stores the content read from r as a new file of the owner, of the tenant
+func (s *FilesService) Create(owner, tenant, filename, purpose string, r io.Reader) (*schema.File, error) {
+	if !filePurposes[purpose] {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("invalid purpose %q", purpose))
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, err
+	}
+
+	f := &storedFile{
+		File: schema.File{
+			ID:        newID("file-"),
+			Object:    "file",
+			CreatedAt: time.Now().Unix(),
+			Filename:  filepath.Base(filename),
+			Purpose:   purpose,
+		},
+		Owner:  owner,
+		Tenant: tenant,
+	}
+
+	out, err := os.Create(s.path(f.ID))
+	if err != nil {
+		return nil, err
+	}
+	f.Bytes, err = io.Copy(out, r)
+	out.Close()
+	if err != nil {
+		os.Remove(s.path(f.ID))
+		return nil, err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	if s.quota > 0 && s.used()+f.Bytes > s.quota {
+		os.Remove(s.path(f.ID))
+		return nil, fiber.NewError(fiber.StatusRequestEntityTooLarge, fmt.Sprintf("the file exceeds the storage quota of %d bytes", s.quota))
+	}
+	s.files[f.ID] = f
+	if err := s.save(); err != nil {
+		delete(s.files, f.ID)
+		os.Remove(s.path(f.ID))
+		return nil, err
+	}
+	return &f.File, nil
+}
+
+// allowed returns true if the caller of the request can use the file
+func (s *FilesService) allowed(c *fiber.Ctx, f *storedFile) bool {
+	if f.Tenant != "" && s.tenant(c) == f.Tenant {
+		return true
+	}
+	return s.caller(c) == f.Owner
+}
+
+// get returns the file with the given id, and the path of its content, whoever owns it
+func (s *FilesService) get(id string) (*schema.File, string, error) {
+	s.Lock()
+	defer s.Unlock()
+	f, ok := s.files[id]
+	if !ok {
+		return nil, "", fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("file %s not found", id))
+	}
+	return &f.File, s.path(id), nil
+}
+
+// Get returns the file with the given id, and the path of its content, if the caller of the request can use it
+func (s *FilesService) Get(c *fiber.Ctx, id string) (*schema.File, string, error) {
+	s.Lock()
+	defer s.Unlock()
+	f, ok := s.files[id]
+	if !ok || !s.allowed(c, f) {
+		return nil, "", fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("file %s not found", id))
+	}
+	return &f.File, s.path(id), nil
+}
+
+func (s *FilesService) UploadFileEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		file, err := c.FormFile("file")
+		if err != nil {
+			return err
+		}
+		if s.quota > 0 && file.Size > s.quota {
+			return fiber.NewError(fiber.StatusRequestEntityTooLarge, fmt.Sprintf("the file exceeds the storage quota of %d bytes", s.quota))
+		}
+
+		r, err := file.Open()
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		f, err := s.Create(s.caller(c), s.tenant(c), file.Filename, c.FormValue("purpose"), r)
+		if err != nil {
+			return err
+		}
+		return c.JSON(f)
+	}
+}
+
+func (s *FilesService) ListFilesEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		purpose := c.Query("purpose")
+
+		s.Lock()
+		files := []*schema.File{}
+		for _, f := range s.files {
+			if (purpose == "" || f.Purpose == purpose) && s.allowed(c, f) {
+				files = append(files, &f.File)
+			}
+		}
+		s.Unlock()
+
+		sort.Slice(files, func(i, j int) bool { return files[i].CreatedAt > files[j].CreatedAt })
+		return c.JSON(schema.ListResponse{Object: "list", Data: files})
+	}
+}
+
+func (s *FilesService) GetFileEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		f, _, err := s.Get(c, c.Params("file_id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(f)
+	}
+}
+
+func (s *FilesService) GetFileContentEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		_, path, err := s.Get(c, c.Params("file_id"))
+		if err != nil {
+			return err
+		}
+		if notModified, err := fiberContext.ConditionalFile(c, path); notModified || err != nil {
+			return err
+		}
+		return c.SendFile(path)
+	}
+}
+
+func (s *FilesService) DeleteFileEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		id := c.Params("file_id")
+
+		s.Lock()
+		defer s.Unlock()
+		f, ok := s.files[id]
+		ok = ok && s.allowed(c, f)
+		if ok {
+			delete(s.files, id)
+			if err := s.save(); err != nil {
+				return err
+			}
+			os.Remove(s.path(id))
+		}
+		return c.JSON(schema.DeletionStatus{ID: id, Object: "file", Deleted: ok})
+	}
+}
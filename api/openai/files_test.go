@@ -0,0 +1,121 @@
+package openai_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	. "github.com/go-skynet/LocalAI/api/openai"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/gofiber/fiber/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// call sends the request to the app as the caller of the tenant, and returns the status and the body of the response
+func call(app *fiber.App, req *http.Request, caller, tenant string) (int, []byte) {
+	req.Header.Set("X-Caller", caller)
+	req.Header.Set("X-Tenant", tenant)
+	resp, err := app.Test(req)
+	Expect(err).ToNot(HaveOccurred())
+	body, err := io.ReadAll(resp.Body)
+	Expect(err).ToNot(HaveOccurred())
+	return resp.StatusCode, body
+}
+
+// upload uploads the content as a file of the caller of the tenant, and returns its ID
+func upload(app *fiber.App, caller, tenant, purpose string, content []byte) string {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	Expect(w.WriteField("purpose", purpose)).To(Succeed())
+	part, err := w.CreateFormFile("file", "sample")
+	Expect(err).ToNot(HaveOccurred())
+	_, err = part.Write(content)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(w.Close()).To(Succeed())
+
+	req := httptest.NewRequest("POST", "/v1/files", body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	status, dat := call(app, req, caller, tenant)
+	Expect(status).To(Equal(fiber.StatusOK))
+	f := schema.File{}
+	Expect(json.Unmarshal(dat, &f)).To(Succeed())
+	return f.ID
+}
+
+var _ = Describe("FilesService", func() {
+	var app *fiber.App
+
+	BeforeEach(func() {
+		dir := GinkgoT().TempDir()
+		caller := func(c *fiber.Ctx) string { return c.Get("X-Caller") }
+		tenant := func(c *fiber.Ctx) string { return c.Get("X-Tenant") }
+		files := NewFilesService(dir, 0, caller, tenant)
+		voices := NewVoicesService(dir, config.NewConfigLoader(), &options.Option{}, files, caller, tenant)
+
+		app = fiber.New()
+		app.Post("/v1/files", files.UploadFileEndpoint())
+		app.Get("/v1/files", files.ListFilesEndpoint())
+		app.Get("/v1/files/:file_id", files.GetFileEndpoint())
+		app.Get("/v1/files/:file_id/content", files.GetFileContentEndpoint())
+		app.Delete("/v1/files/:file_id", files.DeleteFileEndpoint())
+		app.Post("/v1/audio/voices", voices.CreateVoiceEndpoint())
+	})
+
+	listed := func(caller, tenant string) []string {
+		status, dat := call(app, httptest.NewRequest("GET", "/v1/files", nil), caller, tenant)
+		Expect(status).To(Equal(fiber.StatusOK))
+		resp := struct {
+			Data []schema.File `json:"data"`
+		}{}
+		Expect(json.Unmarshal(dat, &resp)).To(Succeed())
+		ids := []string{}
+		for _, f := range resp.Data {
+			ids = append(ids, f.ID)
+		}
+		return ids
+	}
+
+	It("serves the files to the caller which uploaded them only", func() {
+		id := upload(app, "alice", "", "voice", []byte("RIFF"))
+
+		Expect(listed("alice", "")).To(ConsistOf(id))
+		Expect(listed("bob", "")).To(BeEmpty())
+
+		status, _ := call(app, httptest.NewRequest("GET", "/v1/files/"+id, nil), "bob", "")
+		Expect(status).To(Equal(fiber.StatusNotFound))
+		status, _ = call(app, httptest.NewRequest("GET", "/v1/files/"+id+"/content", nil), "bob", "")
+		Expect(status).To(Equal(fiber.StatusNotFound))
+
+		status, dat := call(app, httptest.NewRequest("DELETE", "/v1/files/"+id, nil), "bob", "")
+		Expect(status).To(Equal(fiber.StatusOK))
+		Expect(string(dat)).To(ContainSubstring(`"deleted":false`))
+
+		status, dat = call(app, httptest.NewRequest("GET", "/v1/files/"+id+"/content", nil), "alice", "")
+		Expect(status).To(Equal(fiber.StatusOK))
+		Expect(string(dat)).To(Equal("RIFF"))
+	})
+
+	It("shares the files of a tenant between its callers", func() {
+		id := upload(app, "alice", "acme", "batch", []byte("RIFF"))
+
+		Expect(listed("carol", "acme")).To(ConsistOf(id))
+		Expect(listed("bob", "other")).To(BeEmpty())
+	})
+
+	It("rejects the voices cloned from the sample of another caller", func() {
+		id := upload(app, "alice", "", "voice", []byte("RIFF"))
+
+		req := httptest.NewRequest("POST", "/v1/audio/voices", strings.NewReader(`{"name": "alice", "model": "xtts", "file_id": "`+id+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		status, dat := call(app, req, "bob", "")
+		Expect(status).To(Equal(fiber.StatusNotFound))
+		Expect(string(dat)).To(ContainSubstring(id + " not found"))
+	})
+})
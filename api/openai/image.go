@@ -4,11 +4,18 @@ import (
 	"bufio"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
 	"io"
-	"net/http"
+	"math"
+	"math/rand"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -19,21 +26,26 @@ import (
 	"github.com/go-skynet/LocalAI/api/backend"
 	config "github.com/go-skynet/LocalAI/api/config"
 	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/pkg/downloader"
+	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
+	"github.com/go-skynet/LocalAI/pkg/imaging"
 	model "github.com/go-skynet/LocalAI/pkg/model"
+	"github.com/go-skynet/LocalAI/pkg/workspace"
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog/log"
+	"github.com/valyala/fasthttp"
 )
 
-func downloadFile(url string) (string, error) {
+func downloadFile(w *workspace.Workspace, url string) (string, error) {
 	// Get the data
-	resp, err := http.Get(url)
+	resp, err := downloader.Get(url)
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
 
 	// Create the file
-	out, err := os.CreateTemp("", "image")
+	out, err := w.CreateTemp("image")
 	if err != nil {
 		return "", err
 	}
@@ -61,7 +73,7 @@ func downloadFile(url string) (string, error) {
 */
 func ImageEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx) error {
 	return func(c *fiber.Ctx) error {
-		m, input, err := readRequest(c, o, false)
+		m, input, err := readRequest(c, cm, o, false)
 		if err != nil {
 			return fmt.Errorf("failed reading parameters from request:%w", err)
 		}
@@ -76,164 +88,562 @@ func ImageEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx
 			return fmt.Errorf("failed reading parameters from request:%w", err)
 		}
 
+		temp := &tempFiles{}
+		defer temp.remove()
 		src := ""
 		if input.File != "" {
+			if src, err = requestImage(o, input.File); err != nil {
+				return err
+			}
+			temp.add(src)
+		}
+		control := ""
+		if input.ControlImage != "" {
+			if input.ControlType, err = controlType(config, input.ControlType); err != nil {
+				return err
+			}
+			if control, err = requestImage(o, input.ControlImage); err != nil {
+				return err
+			}
+			temp.add(control)
+		}
 
-			fileData := []byte{}
-			// check if input.File is an URL, if so download it and save it
-			// to a temporary file
-			if strings.HasPrefix(input.File, "http://") || strings.HasPrefix(input.File, "https://") {
-				out, err := downloadFile(input.File)
-				if err != nil {
-					return fmt.Errorf("failed downloading file:%w", err)
-				}
-				defer os.RemoveAll(out)
+		return generateImages(c, config, input, src, "", control, temp, o)
+	}
+}
 
-				fileData, err = os.ReadFile(out)
-				if err != nil {
-					return fmt.Errorf("failed reading file:%w", err)
-				}
+// requestImage saves an image of a request, a URL or base64 encoded, in the workspace and returns its path
+func requestImage(o *options.Option, file string) (string, error) {
+	// check if the file is an URL, if so download it and save it to a temporary file
+	if strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://") {
+		out, err := downloadFile(o.Workspace, file)
+		if err != nil {
+			return "", fmt.Errorf("failed downloading file:%w", err)
+		}
+		return out, nil
+	}
 
-			} else {
-				// base 64 decode the file and write it somewhere
-				// that we will cleanup
-				fileData, err = base64.StdEncoding.DecodeString(input.File)
-				if err != nil {
-					return err
-				}
-			}
+	// base 64 decode the file and write it somewhere that we will cleanup
+	fileData, err := base64.StdEncoding.DecodeString(file)
+	if err != nil {
+		return "", err
+	}
+	outputFile, err := o.Workspace.CreateTemp("b64")
+	if err != nil {
+		return "", err
+	}
+	_, err = outputFile.Write(fileData)
+	outputFile.Close()
+	if err != nil {
+		os.RemoveAll(outputFile.Name())
+		return "", err
+	}
+	return outputFile.Name(), nil
+}
+
+// controlType returns the control type of the ControlNet of the model conditioning a generation, the only one of
+// the model when not requested
+func controlType(cfg *config.Config, requested string) (string, error) {
+	controlNets := cfg.Diffusers.ControlNets
+	if len(controlNets) == 0 {
+		return "", fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("the model %s has no ControlNets to condition the generations", cfg.Name))
+	}
+	if requested == "" && len(controlNets) == 1 {
+		for t := range controlNets {
+			return t, nil
+		}
+	}
+	if _, ok := controlNets[requested]; ok {
+		return requested, nil
+	}
+	types := make([]string, 0, len(controlNets))
+	for t := range controlNets {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	if requested == "" {
+		return "", fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("the control_type of the control_image is required, the model %s has the ControlNets of %v", cfg.Name, types))
+	}
+	return "", fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("the model %s has no ControlNet for the control type %q, it has the ones of %v", cfg.Name, requested, types))
+}
+
+// https://platform.openai.com/docs/api-reference/images/createEdit
+
+/*
+*
 
-			// Create a temporary file
-			outputFile, err := os.CreateTemp(o.ImageDir, "b64")
+	curl http://localhost:8080/v1/images/edits \
+	  -F image="@otter.png" \
+	  -F prompt="A cute baby sea otter wearing a beret" \
+	  -F strength=0.6
+
+*
+*/
+func ImageEditEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		m, input, err := readImageForm(c, cm, o)
+		if err != nil {
+			return err
+		}
+
+		if m == "" {
+			m = model.StableDiffusionBackend
+		}
+		log.Debug().Msgf("Loading model: %+v", m)
+
+		config, input, err := mergeRequestWithConfig(m, input, cm, o.Loader, o.Debug, 0, 0, false)
+		if err != nil {
+			return fmt.Errorf("failed reading parameters from request:%w", err)
+		}
+
+		temp := &tempFiles{}
+		defer temp.remove()
+		src, err := formImage(c, input, temp, o, "the image to edit is required")
+		if err != nil {
+			return err
+		}
+
+		// the mask is converted to the white on black masks of the inpainting pipelines
+		mask := ""
+		if _, err := c.FormFile("mask"); err == nil {
+			uploaded, err := saveFormFile(c, "mask", o)
 			if err != nil {
 				return err
 			}
-			// write the base64 result
-			writer := bufio.NewWriter(outputFile)
-			_, err = writer.Write(fileData)
-			if err != nil {
-				outputFile.Close()
+			temp.add(uploaded)
+			mask = uploaded + ".png"
+			temp.add(mask)
+			if err := imaging.WriteInpaintMask(src, uploaded, mask); err != nil {
+				if errors.Is(err, imaging.ErrMaskSize) || errors.Is(err, imaging.ErrUnsupportedImage) {
+					return fiber.NewError(fiber.StatusBadRequest, err.Error())
+				}
 				return err
 			}
-			outputFile.Close()
-			src = outputFile.Name()
-			defer os.RemoveAll(src)
 		}
 
-		log.Debug().Msgf("Parameter Config: %+v", config)
+		return generateImages(c, config, input, src, mask, "", temp, o)
+	}
+}
 
-		switch config.Backend {
-		case "stablediffusion":
-			config.Backend = model.StableDiffusionBackend
-		case "tinydream":
-			config.Backend = model.TinyDreamBackend
-		case "":
-			config.Backend = model.StableDiffusionBackend
+// https://platform.openai.com/docs/api-reference/images/createVariation
+
+/*
+*
+
+	curl http://localhost:8080/v1/images/variations \
+	  -F image="@otter.png" \
+	  -F n=2
+
+*
+*/
+func ImageVariationEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		m, input, err := readImageForm(c, cm, o)
+		if err != nil {
+			return err
 		}
 
-		sizeParts := strings.Split(input.Size, "x")
-		if len(sizeParts) != 2 {
-			return fmt.Errorf("Invalid value for 'size'")
+		if m == "" {
+			m = model.StableDiffusionBackend
+		}
+		log.Debug().Msgf("Loading model: %+v", m)
+
+		config, input, err := mergeRequestWithConfig(m, input, cm, o.Loader, o.Debug, 0, 0, false)
+		if err != nil {
+			return fmt.Errorf("failed reading parameters from request:%w", err)
 		}
-		width, err := strconv.Atoi(sizeParts[0])
+
+		temp := &tempFiles{}
+		defer temp.remove()
+		src, err := formImage(c, input, temp, o, "the image to vary is required")
 		if err != nil {
-			return fmt.Errorf("Invalid value for 'size'")
+			return err
 		}
-		height, err := strconv.Atoi(sizeParts[1])
+
+		return generateImages(c, config, input, src, "", "", temp, o)
+	}
+}
+
+// readImageForm reads the request of the image edits and variations, and the form values which are not decoded in
+// the request
+func readImageForm(c *fiber.Ctx, cm *config.ConfigLoader, o *options.Option) (string, *schema.OpenAIRequest, error) {
+	m, input, err := readRequest(c, cm, o, false)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed reading parameters from request:%w", err)
+	}
+	input.Prompt = c.FormValue("prompt")
+	input.ResponseFormat.Type = schema.ChatCompletionResponseFormatType(c.FormValue("response_format"))
+	input.NegativePrompt = c.FormValue("negative_prompt")
+	input.Scheduler = c.FormValue("scheduler")
+	if s := c.FormValue("cfg_scale"); s != "" {
+		f, err := strconv.ParseFloat(s, 32)
 		if err != nil {
-			return fmt.Errorf("Invalid value for 'size'")
+			return "", nil, fiber.NewError(fiber.StatusBadRequest, "invalid cfg_scale: "+s)
+		}
+		input.CFGScale = float32(f)
+	}
+	// the LoRAs are a JSON object of their weights by name
+	if s := c.FormValue("loras"); s != "" {
+		if err := json.Unmarshal([]byte(s), &input.Loras); err != nil {
+			return "", nil, fiber.NewError(fiber.StatusBadRequest, "invalid loras: "+s)
 		}
+	}
+	return m, input, nil
+}
 
-		b64JSON := false
-		if input.ResponseFormat.Type == "b64_json" {
-			b64JSON = true
+// formImage saves the image of the form, whose size is the one of the generated images by default
+func formImage(c *fiber.Ctx, input *schema.OpenAIRequest, temp *tempFiles, o *options.Option, missing string) (string, error) {
+	if _, err := c.FormFile("image"); err != nil {
+		return "", fiber.NewError(fiber.StatusBadRequest, missing)
+	}
+	src, err := saveFormFile(c, "image", o)
+	if err != nil {
+		return "", err
+	}
+	temp.add(src)
+
+	if input.Size == "" {
+		if input.Size, err = imageSize(src); err != nil {
+			return "", fiber.NewError(fiber.StatusBadRequest, "unsupported image: "+err.Error())
 		}
+	}
+	return src, nil
+}
+
+// tempFiles are the temporary files of an image request, removed once the request is handled, or once the images
+// are generated when they are streamed
+type tempFiles struct {
+	files []string
+	kept  bool
+}
+
+func (t *tempFiles) add(file string) {
+	t.files = append(t.files, file)
+}
+
+// keep leaves the files to the returned function, which removes them
+func (t *tempFiles) keep() func() {
+	t.kept = true
+	return func() {
+		t.kept = false
+		t.remove()
+	}
+}
+
+func (t *tempFiles) remove() {
+	if t.kept {
+		return
+	}
+	for _, f := range t.files {
+		os.RemoveAll(f)
+	}
+}
+
+// saveFormFile saves the file of a field of the form in the workspace, and returns its path
+func saveFormFile(c *fiber.Ctx, field string, o *options.Option) (string, error) {
+	file, err := c.FormFile(field)
+	if err != nil {
+		return "", err
+	}
+	f, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	out, err := o.Workspace.CreateTemp(field)
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(out, f)
+	out.Close()
+	if err != nil {
+		os.RemoveAll(out.Name())
+		return "", err
+	}
+	return out.Name(), nil
+}
+
+// imageSize returns the size of a PNG or JPEG image, as the size of the requests
+func imageSize(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	img, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%dx%d", img.Width, img.Height), nil
+}
+
+// generateImages generates the images of the prompts of the request, from the image of src if not empty, inpainting
+// the white areas of the mask if not empty, and conditioned by the control image if not empty. The streamed
+// generations keep the temporary files of the request until the images are generated.
+func generateImages(c *fiber.Ctx, config *config.Config, input *schema.OpenAIRequest, src, mask, control string, temp *tempFiles, o *options.Option) error {
+	if err := validateDiffusion(input); err != nil {
+		return err
+	}
+
+	log.Debug().Msgf("Parameter Config: %+v", config)
+
+	switch config.Backend {
+	case "stablediffusion":
+		config.Backend = model.StableDiffusionBackend
+	case "tinydream":
+		config.Backend = model.TinyDreamBackend
+	case "":
+		config.Backend = model.StableDiffusionBackend
+	}
+
+	sizeParts := strings.Split(input.Size, "x")
+	if len(sizeParts) != 2 {
+		return fmt.Errorf("Invalid value for 'size'")
+	}
+	width, err := strconv.Atoi(sizeParts[0])
+	if err != nil {
+		return fmt.Errorf("Invalid value for 'size'")
+	}
+	height, err := strconv.Atoi(sizeParts[1])
+	if err != nil {
+		return fmt.Errorf("Invalid value for 'size'")
+	}
+
+	b64JSON := false
+	if input.ResponseFormat.Type == "b64_json" {
+		b64JSON = true
+	}
+	// the LoRAs selected in the prompts are removed from them
+	prompts := make([]string, len(config.PromptStrings))
+	loras := make([]map[string]float32, len(config.PromptStrings))
+	for k, p := range config.PromptStrings {
+		if prompts[k], loras[k], err = imageLoras(config, input.Loras, p); err != nil {
+			return err
+		}
+	}
+
+	// the images of a prompt have successive seeds from the seed of the request, random when not set, which are
+	// returned with the images
+	seed := config.Seed
+	if seed == 0 {
+		seed = rand.Intn(math.MaxInt32/2) + 1
+	}
+
+	// generate generates the images, calling progress with the progress of each one when not nil
+	generate := func(baseURL string, progress func(index int, p *pb.ImageProgress) error) ([]schema.Item, error) {
 		// src and clip_skip
 		var result []schema.Item
-		for _, i := range config.PromptStrings {
+		for k, i := range prompts {
 			n := input.N
 			if input.N == 0 {
 				n = 1
 			}
-			for j := 0; j < n; j++ {
-				prompts := strings.Split(i, "|")
-				positive_prompt := prompts[0]
-				negative_prompt := ""
-				if len(prompts) > 1 {
-					negative_prompt = prompts[1]
-				}
+			prompts := strings.Split(i, "|")
+			positive_prompt := prompts[0]
+			negative_prompt := config.NegativePrompt
+			if len(prompts) > 1 {
+				negative_prompt = prompts[1]
+			}
 
-				mode := 0
-				step := config.Step
-				if step == 0 {
-					step = 15
-				}
+			mode := 0
+			step := config.Step
+			if step == 0 {
+				step = 15
+			}
 
-				if input.Mode != 0 {
-					mode = input.Mode
-				}
+			if input.Mode != 0 {
+				mode = input.Mode
+			}
 
-				if input.Step != 0 {
-					step = input.Step
-				}
+			if input.Step != 0 {
+				step = input.Step
+			}
 
-				tempDir := ""
-				if !b64JSON {
-					tempDir = o.ImageDir
+			outputs := make([]string, n)
+			for j := range outputs {
+				var err error
+				if outputs[j], err = imageOutput(o, b64JSON); err != nil {
+					return nil, err
 				}
-				// Create a temporary file
-				outputFile, err := os.CreateTemp(tempDir, "b64")
-				if err != nil {
-					return err
-				}
-				outputFile.Close()
-				output := outputFile.Name() + ".png"
-				// Rename the temporary file
-				err = os.Rename(outputFile.Name(), output)
-				if err != nil {
-					return err
+			}
+
+			// when the progress can't be sent, the client disconnected and the rest of the images are not generated
+			var report func(int, *pb.ImageProgress)
+			var reportErr error
+			if progress != nil {
+				first := len(result)
+				report = func(j int, p *pb.ImageProgress) {
+					if reportErr == nil {
+						reportErr = progress(first+j, p)
+					}
 				}
+			}
 
-				baseURL := c.BaseURL()
+			fn, err := backend.ImageGeneration(height, width, mode, step, seed, input.Strength, input.CFGScale, positive_prompt, negative_prompt, input.Scheduler, src, mask, control, input.ControlType, outputs, loras[k], input.Preview, report, o.Loader, *config, o)
+			if errors.Is(err, backend.ErrNoInpainting) {
+				return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+			}
+			if err != nil {
+				return nil, err
+			}
+			if err := fn(); err != nil {
+				return nil, err
+			}
+			if reportErr != nil {
+				return nil, reportErr
+			}
 
-				fn, err := backend.ImageGeneration(height, width, mode, step, input.Seed, positive_prompt, negative_prompt, src, output, o.Loader, *config, o)
+			for j, output := range outputs {
+				item, err := imageItem(baseURL, output, b64JSON)
 				if err != nil {
-					return err
-				}
-				if err := fn(); err != nil {
-					return err
+					return nil, err
 				}
+				item.Seed = int(int32(seed + j))
+				result = append(result, *item)
+			}
+		}
+		return result, nil
+	}
 
-				item := &schema.Item{}
+	if input.Stream {
+		streamImages(c, temp.keep(), generate)
+		return nil
+	}
 
-				if b64JSON {
-					defer os.RemoveAll(output)
-					data, err := os.ReadFile(output)
-					if err != nil {
-						return err
-					}
-					item.B64JSON = base64.StdEncoding.EncodeToString(data)
-				} else {
-					base := filepath.Base(output)
-					item.URL = baseURL + "/generated-images/" + base
-				}
+	result, err := generate(c.BaseURL(), nil)
+	if err != nil {
+		return err
+	}
 
-				result = append(result, *item)
+	id := uuid.New().String()
+	created := int(time.Now().Unix())
+	resp := &schema.OpenAIResponse{
+		ID:      id,
+		Created: created,
+		Data:    result,
+	}
+
+	jsonResult, _ := json.Marshal(resp)
+	log.Debug().Msgf("Response: %s", jsonResult)
+
+	// Return the prediction in the response body
+	return c.JSON(resp)
+}
+
+// streamImages generates the images and sends their progress as server-sent events, then the generated images. The
+// temporary files of the request are removed once done.
+func streamImages(c *fiber.Ctx, remove func(), generate func(baseURL string, progress func(int, *pb.ImageProgress) error) ([]schema.Item, error)) {
+	c.Context().SetContentType("text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("Transfer-Encoding", "chunked")
+
+	baseURL := c.BaseURL()
+	c.Context().SetBodyStreamWriter(fasthttp.StreamWriter(func(w *bufio.Writer) {
+		defer remove()
+
+		send := func(ev schema.ImageEvent) error {
+			dat, err := json.Marshal(ev)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", dat); err != nil {
+				return err
 			}
+			return w.Flush()
 		}
 
-		id := uuid.New().String()
-		created := int(time.Now().Unix())
-		resp := &schema.OpenAIResponse{
-			ID:      id,
-			Created: created,
-			Data:    result,
+		result, err := generate(baseURL, func(index int, p *pb.ImageProgress) error {
+			ev := schema.ImageEvent{Type: schema.ImageGenerationProgress, Index: index, Step: int(p.Step), Steps: int(p.Steps)}
+			if len(p.Preview) > 0 {
+				ev.Preview = base64.StdEncoding.EncodeToString(p.Preview)
+			}
+			return send(ev)
+		})
+		if err != nil {
+			log.Debug().Msgf("Streaming the image generation failed: %v", err)
+			send(schema.ImageEvent{Type: schema.ImageGenerationError, Error: err.Error()})
+			return
 		}
+		send(schema.ImageEvent{Type: schema.ImageGenerationCompleted, Created: int(time.Now().Unix()), Data: result})
+		w.WriteString("data: [DONE]\n\n")
+		w.Flush()
+	}))
+}
 
-		jsonResult, _ := json.Marshal(resp)
-		log.Debug().Msgf("Response: %s", jsonResult)
+// imageLoras removes the LoRAs selected in a prompt, and returns the weights of the LoRAs of its generations: the ones
+// selected in the prompt and in the request, or the default ones of the model
+func imageLoras(cfg *config.Config, requested map[string]float32, prompt string) (string, map[string]float32, error) {
+	prompt, selected, err := config.PromptLoras(prompt)
+	if err != nil {
+		return "", nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	for name, w := range requested {
+		if _, ok := selected[name]; !ok {
+			selected[name] = w
+		}
+	}
+	loras, err := cfg.ImageLoras(selected)
+	if err != nil {
+		return "", nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return prompt, loras, nil
+}
 
-		// Return the prediction in the response body
-		return c.JSON(resp)
+// the bounds of the parameters of the diffusion of the requests
+const (
+	maxDiffusionSteps    = 1000
+	maxDiffusionCFGScale = 50
+)
+
+// validateDiffusion checks the parameters of the diffusion of the request, the ones left out default to the ones of
+// the model
+func validateDiffusion(input *schema.OpenAIRequest) error {
+	switch {
+	case input.Strength < 0 || input.Strength > 1:
+		return fiber.NewError(fiber.StatusBadRequest, "the strength must be between 0 and 1")
+	case input.Step < 0 || input.Step > maxDiffusionSteps:
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("the step must be between 1 and %d", maxDiffusionSteps))
+	case input.CFGScale < 0 || input.CFGScale > maxDiffusionCFGScale:
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("the cfg_scale must be between 0 and %d", maxDiffusionCFGScale))
+	case input.Seed < 0:
+		return fiber.NewError(fiber.StatusBadRequest, "the seed must be positive")
+	case input.Scheduler != "" && !slices.Contains(config.DiffusersSchedulers, input.Scheduler):
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("unknown scheduler %q, it must be one of %s", input.Scheduler, strings.Join(config.DiffusersSchedulers, ", ")))
+	}
+	return nil
+}
+
+// imageOutput returns the path of a new PNG image: in the images directory, or in the workspace when returned base64
+// encoded
+func imageOutput(o *options.Option, b64JSON bool) (string, error) {
+	tempDir := o.Workspace.Dir()
+	if !b64JSON {
+		tempDir = o.ImageDir
+	}
+	// Create a temporary file
+	outputFile, err := os.CreateTemp(tempDir, "b64")
+	if err != nil {
+		return "", err
+	}
+	outputFile.Close()
+	output := outputFile.Name() + ".png"
+	// Rename the temporary file
+	return output, os.Rename(outputFile.Name(), output)
+}
+
+// imageItem returns the item of the response of an image of imageOutput, whose file is removed when base64 encoded
+func imageItem(baseURL, output string, b64JSON bool) (*schema.Item, error) {
+	item := &schema.Item{}
+	if b64JSON {
+		defer os.RemoveAll(output)
+		data, err := os.ReadFile(output)
+		if err != nil {
+			return nil, err
+		}
+		item.B64JSON = base64.StdEncoding.EncodeToString(data)
+	} else {
+		item.URL = baseURL + "/generated-images/" + filepath.Base(output)
 	}
+	return item, nil
 }
@@ -46,7 +46,13 @@ func ComputeChoices(
 		tokenUsage.Completion += prediction.Usage.Completion
 
 		finetunedResponse := backend.Finetune(*config, predInput, prediction.Response)
+		choices := len(result)
 		cb(finetunedResponse, &result)
+		if config.Logprobs {
+			for j := choices; j < len(result); j++ {
+				result[j].Logprobs = chatLogprobs(prediction.Logprobs)
+			}
+		}
 
 		//result = append(result, Choice{Text: prediction})
 
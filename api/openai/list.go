@@ -1,12 +1,18 @@
 package openai
 
 import (
+	"os"
 	"regexp"
+	"sync"
+	"time"
 
 	config "github.com/go-skynet/LocalAI/api/config"
+	fiberContext "github.com/go-skynet/LocalAI/api/ctx"
 	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/go-skynet/LocalAI/pkg/gguf"
 	model "github.com/go-skynet/LocalAI/pkg/model"
 	"github.com/gofiber/fiber/v2"
+	gopsutil "github.com/shirou/gopsutil/v3/process"
 )
 
 func ListModelsEndpoint(loader *model.ModelLoader, cm *config.ConfigLoader) func(ctx *fiber.Ctx) error {
@@ -15,6 +21,14 @@ func ListModelsEndpoint(loader *model.ModelLoader, cm *config.ConfigLoader) func
 		if err != nil {
 			return err
 		}
+		// the loose files of the namespace of the tenant, if any
+		if tenant := fiberContext.Tenant(c); tenant != "" {
+			files, err := loader.ListModelsIn(config.TenantPrefix(tenant))
+			if err != nil {
+				return err
+			}
+			models = append(models, files...)
+		}
 		var mm map[string]interface{} = map[string]interface{}{}
 
 		dataModels := []schema.OpenAIModel{}
@@ -39,22 +53,45 @@ func ListModelsEndpoint(loader *model.ModelLoader, cm *config.ConfigLoader) func
 		// By default, exclude any loose files that are already referenced by a configuration file.
 		excludeConfigured := c.QueryBool("excludeConfigured", true)
 
+		// Optionally, add the load state and the backend of each model
+		details := c.QueryBool("details", false)
+
 		// Start with the known configurations
-		for _, c := range cm.GetAllConfigs() {
+		for _, cfg := range cm.GetAllConfigs() {
 			if excludeConfigured {
-				mm[c.Model] = nil
+				mm[cfg.Model] = nil
 			}
 
-			if filterFn(c.Name) {
-				dataModels = append(dataModels, schema.OpenAIModel{ID: c.Name, Object: "model"})
+			name, visible := fiberContext.VisibleModel(c, cfg.Name)
+			if visible && filterFn(name) {
+				m := schema.OpenAIModel{ID: name, Object: "model"}
+				if details {
+					m.Details = modelDetails(loader, cfg.Model, &cfg)
+				}
+				dataModels = append(dataModels, m)
+			}
+		}
+
+		// The aliases of the routing rules are listed, as the applications might check that their model exists
+		for _, alias := range cm.Routes().Aliases() {
+			if _, visible := fiberContext.VisibleModel(c, alias); !visible {
+				continue
+			}
+			if _, configured := cm.GetConfig(alias); !configured && filterFn(alias) {
+				dataModels = append(dataModels, schema.OpenAIModel{ID: alias, Object: "model"})
 			}
 		}
 
 		// Then iterate through the loose files:
 		for _, m := range models {
 			// And only adds them if they shouldn't be skipped.
-			if _, exists := mm[m]; !exists && filterFn(m) {
-				dataModels = append(dataModels, schema.OpenAIModel{ID: m, Object: "model"})
+			name, visible := fiberContext.VisibleModel(c, m)
+			if _, exists := mm[m]; !exists && visible && filterFn(name) {
+				om := schema.OpenAIModel{ID: name, Object: "model"}
+				if details {
+					om.Details = modelDetails(loader, m, nil)
+				}
+				dataModels = append(dataModels, om)
 			}
 		}
 
@@ -67,3 +104,65 @@ func ListModelsEndpoint(loader *model.ModelLoader, cm *config.ConfigLoader) func
 		})
 	}
 }
+
+func modelDetails(loader *model.ModelLoader, file string, c *config.Config) *schema.OpenAIModelDetails {
+	d := &schema.OpenAIModelDetails{}
+	if c != nil {
+		d.Backend = c.Backend
+		d.ContextSize = c.ContextSize
+		d.Quantization = c.Quantization
+	}
+
+	if backend, loaded := loader.LoadedBackend(file); loaded {
+		d.Loaded = true
+		d.Backend = backend
+		if pid, err := loader.GetGRPCPID(file); err == nil {
+			if p, err := gopsutil.NewProcess(int32(pid)); err == nil {
+				if mem, err := p.MemoryInfo(); err == nil {
+					d.Memory = mem.RSS
+				}
+			}
+		}
+	}
+
+	if d.Quantization == "" {
+		d.Quantization = fileQuantization(loader.ModelFile(file))
+	}
+
+	return d
+}
+
+type quantization struct {
+	modTime  time.Time
+	size     int64
+	fileType string
+}
+
+var quantizationsMu sync.Mutex
+var quantizations = map[string]quantization{}
+
+// fileQuantization returns the quantization read from the GGUF metadata of the model file at path, if any.
+// The result is kept until the file changes, so the models aren't all read again on each listing.
+func fileQuantization(path string) string {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return ""
+	}
+
+	quantizationsMu.Lock()
+	q, ok := quantizations[path]
+	quantizationsMu.Unlock()
+	if ok && q.modTime.Equal(info.ModTime()) && q.size == info.Size() {
+		return q.fileType
+	}
+
+	q = quantization{modTime: info.ModTime(), size: info.Size()}
+	// the files which are not GGUF have no quantization
+	if f, err := gguf.Read(path); err == nil {
+		q.fileType = f.FileType()
+	}
+	quantizationsMu.Lock()
+	quantizations[path] = q
+	quantizationsMu.Unlock()
+	return q.fileType
+}
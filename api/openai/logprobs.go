@@ -0,0 +1,64 @@
+package openai
+
+import (
+	"unicode/utf8"
+
+	"github.com/go-skynet/LocalAI/api/schema"
+	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
+)
+
+func tokenBytes(token string) []int {
+	b := make([]int, len(token))
+	for i := range token {
+		b[i] = int(token[i])
+	}
+	return b
+}
+
+// chatLogprobs converts the logprobs returned by the backend to the format of the chat completions
+func chatLogprobs(logprobs []*pb.TokenLogprob) *schema.ChoiceLogprobs {
+	res := &schema.ChoiceLogprobs{Content: []schema.TokenLogprob{}}
+	for _, lp := range logprobs {
+		t := schema.TokenLogprob{
+			Token:       lp.Token,
+			Logprob:     lp.Logprob,
+			Bytes:       tokenBytes(lp.Token),
+			TopLogprobs: []schema.TopLogprob{},
+		}
+		for _, top := range lp.TopLogprobs {
+			t.TopLogprobs = append(t.TopLogprobs, schema.TopLogprob{
+				Token:   top.Token,
+				Logprob: top.Logprob,
+				Bytes:   tokenBytes(top.Token),
+			})
+		}
+		res.Content = append(res.Content, t)
+	}
+	return res
+}
+
+// completionLogprobs converts the logprobs of a chat completion to the legacy format of the completions,
+// with the text offsets starting at offset
+func completionLogprobs(l *schema.ChoiceLogprobs, offset int) *schema.ChoiceLogprobs {
+	if l == nil {
+		return nil
+	}
+	res := &schema.ChoiceLogprobs{
+		Tokens:        []string{},
+		TokenLogprobs: []float32{},
+		TopLogprobs:   []map[string]float32{},
+		TextOffset:    []int{},
+	}
+	for _, t := range l.Content {
+		res.Tokens = append(res.Tokens, t.Token)
+		res.TokenLogprobs = append(res.TokenLogprobs, t.Logprob)
+		top := map[string]float32{}
+		for _, tt := range t.TopLogprobs {
+			top[tt.Token] = tt.Logprob
+		}
+		res.TopLogprobs = append(res.TopLogprobs, top)
+		res.TextOffset = append(res.TextOffset, offset)
+		offset += utf8.RuneCountInString(t.Token)
+	}
+	return res
+}
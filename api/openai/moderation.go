@@ -0,0 +1,89 @@
+package openai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-skynet/LocalAI/api/backend"
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/api/schema"
+	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// the model used by the OpenAI clients which don't specify one
+const defaultModerationModel = "text-moderation-latest"
+
+// https://platform.openai.com/docs/api-reference/moderations
+func ModerationEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		model, input, err := readRequest(c, cm, o, false)
+		if err != nil {
+			return fmt.Errorf("failed reading parameters from request:%w", err)
+		}
+		if model == "" {
+			model = defaultModerationModel
+		}
+
+		config, _, err := mergeRequestWithConfig(model, input, cm, o.Loader, o.Debug, o.Threads, o.ContextSize, o.F16)
+		if err != nil {
+			return fmt.Errorf("failed reading parameters from request:%w", err)
+		}
+
+		results := []schema.ModerationResult{}
+		for _, s := range config.InputStrings {
+			labels, err := backend.ModelClassify(s, o.Loader, *config, o)
+			if err != nil {
+				return err
+			}
+			results = append(results, moderate(config.Moderation, labels))
+		}
+
+		return c.JSON(schema.ModerationResponse{
+			ID:      "modr-" + uuid.New().String(),
+			Model:   model,
+			Results: results,
+		})
+	}
+}
+
+// moderate maps the scores of the labels of the classifier to moderation categories
+func moderate(m config.Moderation, labels []*pb.ClassifyLabel) schema.ModerationResult {
+	threshold := m.Threshold
+	if threshold == 0 {
+		threshold = 0.5
+	}
+
+	res := schema.ModerationResult{
+		Categories:     map[string]bool{},
+		CategoryScores: map[string]float64{},
+	}
+	for _, c := range schema.ModerationCategories {
+		res.Categories[c] = false
+		res.CategoryScores[c] = 0
+	}
+
+	for _, l := range labels {
+		categories, ok := m.Categories[l.Label]
+		if !ok {
+			if _, known := res.CategoryScores[strings.ToLower(l.Label)]; !known {
+				continue
+			}
+			categories = []string{strings.ToLower(l.Label)}
+		}
+		for _, c := range categories {
+			// a category mapped from several labels has the highest of their scores
+			if score := float64(l.Score); score > res.CategoryScores[c] {
+				res.CategoryScores[c] = score
+			}
+		}
+	}
+
+	for c, score := range res.CategoryScores {
+		res.Categories[c] = score >= threshold
+		res.Flagged = res.Flagged || res.Categories[c]
+	}
+	return res
+}
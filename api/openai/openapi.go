@@ -0,0 +1,188 @@
+package openai
+
+import (
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/go-skynet/LocalAI/pkg/openapi"
+)
+
+// objectList is the schema.ListResponse of the objects of type T
+type objectList[T any] struct {
+	Object  string `json:"object"`
+	Data    []T    `json:"data"`
+	FirstID string `json:"first_id,omitempty"`
+	LastID  string `json:"last_id,omitempty"`
+	HasMore bool   `json:"has_more"`
+}
+
+// audioRequest is the multipart/form-data body of the transcriptions and translations
+type audioRequest struct {
+	File     openapi.Binary `json:"file"`
+	Model    string         `json:"model"`
+	Language string         `json:"language"`
+	Stream   bool           `json:"stream"`
+	// json, verbose_json, text, srt or vtt
+	ResponseFormat string `json:"response_format"`
+	// segment and/or word
+	TimestampGranularities []string `json:"timestamp_granularities[]"`
+	// diarization of the transcriptions
+	Diarize     bool `json:"diarize"`
+	NumSpeakers int  `json:"num_speakers"`
+	MinSpeakers int  `json:"min_speakers"`
+	MaxSpeakers int  `json:"max_speakers"`
+	// removal of the silences before the transcriptions, with the settings of the VAD model
+	VAD          bool    `json:"vad"`
+	Threshold    float32 `json:"threshold"`
+	MinSilenceMs int     `json:"min_silence_ms"`
+	MinSpeechMs  int     `json:"min_speech_ms"`
+}
+
+// vadRequest is the multipart/form-data body of the voice activity detection
+type vadRequest struct {
+	File         openapi.Binary `json:"file"`
+	Model        string         `json:"model"`
+	Threshold    float32        `json:"threshold"`
+	MinSilenceMs int            `json:"min_silence_ms"`
+	MinSpeechMs  int            `json:"min_speech_ms"`
+}
+
+// imageEditRequest is the multipart/form-data body of the image edits
+type imageEditRequest struct {
+	Image openapi.Binary `json:"image"`
+	// the areas to inpaint are fully transparent
+	Mask   openapi.Binary `json:"mask"`
+	Prompt string         `json:"prompt"`
+	Model  string         `json:"model"`
+	N      int            `json:"n"`
+	// the size of the image by default
+	Size string `json:"size"`
+	// url or b64_json
+	ResponseFormat string `json:"response_format"`
+	// from 0 (the image is kept) to 1 (it is replaced)
+	Strength float32 `json:"strength"`
+	// the parameters of the diffusion, the ones of the model by default
+	NegativePrompt string  `json:"negative_prompt"`
+	Scheduler      string  `json:"scheduler"`
+	CFGScale       float32 `json:"cfg_scale"`
+	Step           int     `json:"step"`
+	Seed           int     `json:"seed"`
+	// a JSON object of the weights of the LoRAs of the model by name
+	Loras string `json:"loras"`
+	// stream the progress of the generations as server-sent events, with previews of the images
+	Stream  bool `json:"stream"`
+	Preview bool `json:"preview"`
+}
+
+// imageVariationRequest is the multipart/form-data body of the image variations, which also take the parameters of
+// the diffusion of the image edits
+type imageVariationRequest struct {
+	Image openapi.Binary `json:"image"`
+	Model string         `json:"model"`
+	N     int            `json:"n"`
+	// the size of the image by default
+	Size string `json:"size"`
+	// url or b64_json
+	ResponseFormat string `json:"response_format"`
+	// an optional prompt guiding the variations
+	Prompt string `json:"prompt"`
+	// from 0 (the image is kept) to 1 (it is replaced)
+	Strength float32 `json:"strength"`
+	// the seed of the first variation, random by default
+	Seed   int  `json:"seed"`
+	Stream bool `json:"stream"`
+}
+
+// upscaleRequest is the multipart/form-data body of the image upscaling
+type upscaleRequest struct {
+	// the uploaded image, or its URL or base64 encoding
+	Image openapi.Binary `json:"image"`
+	Model string         `json:"model"`
+	// the scale factor, the one of the model by default
+	Scale float32 `json:"scale"`
+	// the size of the tiles the image is upscaled by, 0 to upscale it at once
+	Tile int `json:"tile"`
+	// url or b64_json
+	ResponseFormat string `json:"response_format"`
+}
+
+// fileRequest is the multipart/form-data body of the uploads of the Files API
+type fileRequest struct {
+	File    openapi.Binary `json:"file"`
+	Purpose string         `json:"purpose"`
+}
+
+// OpenAPIOperations documents the endpoints of the OpenAI API
+func OpenAPIOperations() []openapi.Operation {
+	paginated := []string{"limit", "order", "after"}
+	modelsList := struct {
+		Object string               `json:"object"`
+		Data   []schema.OpenAIModel `json:"data"`
+	}{}
+
+	ops := []openapi.Operation{
+		{Method: "GET", Path: "/v1/models", Summary: "List the models", Tag: "Models", Response: modelsList, Query: []string{"filter", "excludeConfigured", "details"}},
+		{Method: "GET", Path: "/models", Summary: "List the models", Tag: "Models", Response: modelsList, Query: []string{"filter", "excludeConfigured", "details"}},
+
+		{Method: "POST", Path: "/v1/chat/completions", Summary: "Create a chat completion", Tag: "Chat", Request: schema.OpenAIRequest{}, Response: schema.OpenAIResponse{}, Stream: true},
+		{Method: "POST", Path: "/chat/completions", Summary: "Create a chat completion", Tag: "Chat", Request: schema.OpenAIRequest{}, Response: schema.OpenAIResponse{}, Stream: true},
+		{Method: "POST", Path: "/v1/completions", Summary: "Create a completion", Tag: "Completions", Request: schema.OpenAIRequest{}, Response: schema.OpenAIResponse{}, Stream: true},
+		{Method: "POST", Path: "/completions", Summary: "Create a completion", Tag: "Completions", Request: schema.OpenAIRequest{}, Response: schema.OpenAIResponse{}, Stream: true},
+		{Method: "POST", Path: "/v1/engines/:model/completions", Summary: "Create a completion with the model of the path", Tag: "Completions", Request: schema.OpenAIRequest{}, Response: schema.OpenAIResponse{}, Stream: true},
+		{Method: "POST", Path: "/v1/edits", Summary: "Create an edit", Tag: "Edits", Request: schema.OpenAIRequest{}, Response: schema.OpenAIResponse{}},
+		{Method: "POST", Path: "/edits", Summary: "Create an edit", Tag: "Edits", Request: schema.OpenAIRequest{}, Response: schema.OpenAIResponse{}},
+
+		{Method: "POST", Path: "/v1/embeddings", Summary: "Create embeddings", Tag: "Embeddings", Request: schema.OpenAIRequest{}, Response: schema.OpenAIResponse{}},
+		{Method: "POST", Path: "/embeddings", Summary: "Create embeddings", Tag: "Embeddings", Request: schema.OpenAIRequest{}, Response: schema.OpenAIResponse{}},
+		{Method: "POST", Path: "/v1/engines/:model/embeddings", Summary: "Create embeddings with the model of the path", Tag: "Embeddings", Request: schema.OpenAIRequest{}, Response: schema.OpenAIResponse{}},
+
+		{Method: "POST", Path: "/v1/moderations", Summary: "Classify the input as harmful or not", Tag: "Moderations", Request: schema.OpenAIRequest{}, Response: schema.ModerationResponse{}},
+		{Method: "POST", Path: "/moderations", Summary: "Classify the input as harmful or not", Tag: "Moderations", Request: schema.OpenAIRequest{}, Response: schema.ModerationResponse{}},
+
+		{Method: "POST", Path: "/v1/audio/transcriptions", Summary: "Transcribe an audio file", Tag: "Audio", Request: audioRequest{}, Form: true, Response: schema.Result{}, Stream: true},
+		{Method: "POST", Path: "/v1/audio/translations", Summary: "Translate an audio file to English", Tag: "Audio", Request: audioRequest{}, Form: true, Response: schema.Result{}, Stream: true},
+		{Method: "POST", Path: "/v1/audio/vad", Summary: "Find the speech of an audio file", Tag: "Audio", Request: vadRequest{}, Form: true, Response: schema.VADResponse{}},
+		{Method: "POST", Path: "/v1/audio/speech", Summary: "Generate speech", Tag: "Audio", Request: schema.SpeechRequest{}, ResponseType: "audio/wav"},
+		{Method: "POST", Path: "/v1/audio/voices", Summary: "Clone a voice from a sample of the Files API", Tag: "Audio", Request: schema.VoiceRequest{}, Response: schema.Voice{}},
+		{Method: "GET", Path: "/v1/audio/voices", Summary: "List the voices", Tag: "Audio", Response: objectList[schema.Voice]{}, Query: paginated},
+		{Method: "GET", Path: "/v1/audio/voices/:voice_id", Summary: "Get a voice", Tag: "Audio", Response: schema.Voice{}},
+		{Method: "DELETE", Path: "/v1/audio/voices/:voice_id", Summary: "Delete a voice", Tag: "Audio", Response: schema.DeletionStatus{}},
+		{Method: "GET", Path: "/v1/realtime", Summary: "Open a realtime session (WebSocket)", Tag: "Realtime", Query: []string{"model"}},
+
+		{Method: "POST", Path: "/v1/images/generations", Summary: "Generate images", Tag: "Images", Request: schema.OpenAIRequest{}, Response: schema.OpenAIResponse{}, Stream: true},
+		{Method: "POST", Path: "/v1/images/edits", Summary: "Edit an image", Tag: "Images", Request: imageEditRequest{}, Form: true, Response: schema.OpenAIResponse{}, Stream: true},
+		{Method: "POST", Path: "/v1/images/variations", Summary: "Create variations of an image", Tag: "Images", Request: imageVariationRequest{}, Form: true, Response: schema.OpenAIResponse{}, Stream: true},
+		{Method: "POST", Path: "/v1/images/upscale", Summary: "Upscale an image", Tag: "Images", Request: upscaleRequest{}, Form: true, Response: schema.OpenAIResponse{}},
+
+		{Method: "POST", Path: "/v1/files", Summary: "Upload a file", Tag: "Files", Request: fileRequest{}, Form: true, Response: schema.File{}},
+		{Method: "GET", Path: "/v1/files", Summary: "List the files", Tag: "Files", Response: objectList[schema.File]{}, Query: []string{"purpose"}},
+		{Method: "GET", Path: "/v1/files/:file_id", Summary: "Get a file", Tag: "Files", Response: schema.File{}},
+		{Method: "GET", Path: "/v1/files/:file_id/content", Summary: "Download the content of a file", Tag: "Files", ResponseType: "application/octet-stream"},
+		{Method: "DELETE", Path: "/v1/files/:file_id", Summary: "Delete a file", Tag: "Files", Response: schema.DeletionStatus{}},
+
+		{Method: "POST", Path: "/v1/batches", Summary: "Create a batch", Tag: "Batches", Request: schema.BatchRequest{}, Response: schema.Batch{}},
+		{Method: "GET", Path: "/v1/batches", Summary: "List the batches", Tag: "Batches", Response: objectList[schema.Batch]{}, Query: paginated},
+		{Method: "GET", Path: "/v1/batches/:batch_id", Summary: "Get a batch", Tag: "Batches", Response: schema.Batch{}},
+		{Method: "POST", Path: "/v1/batches/:batch_id/cancel", Summary: "Cancel a batch", Tag: "Batches", Response: schema.Batch{}},
+
+		{Method: "POST", Path: "/v1/assistants", Summary: "Create an assistant", Tag: "Assistants", Request: schema.AssistantRequest{}, Response: schema.Assistant{}},
+		{Method: "GET", Path: "/v1/assistants", Summary: "List the assistants", Tag: "Assistants", Response: objectList[schema.Assistant]{}, Query: paginated},
+		{Method: "GET", Path: "/v1/assistants/:assistant_id", Summary: "Get an assistant", Tag: "Assistants", Response: schema.Assistant{}},
+		{Method: "POST", Path: "/v1/assistants/:assistant_id", Summary: "Modify an assistant", Tag: "Assistants", Request: schema.AssistantRequest{}, Response: schema.Assistant{}},
+		{Method: "DELETE", Path: "/v1/assistants/:assistant_id", Summary: "Delete an assistant", Tag: "Assistants", Response: schema.DeletionStatus{}},
+		{Method: "POST", Path: "/v1/threads", Summary: "Create a thread", Tag: "Assistants", Request: schema.ThreadRequest{}, Response: schema.Thread{}},
+		{Method: "POST", Path: "/v1/threads/runs", Summary: "Create a thread and run it", Tag: "Assistants", Request: schema.RunRequest{}, Response: schema.Run{}},
+		{Method: "POST", Path: "/v1/threads/import", Summary: "Import an exported thread", Tag: "Assistants", Request: schema.ThreadExport{}, Response: schema.Thread{}, Extension: true},
+		{Method: "GET", Path: "/v1/threads/:thread_id", Summary: "Get a thread", Tag: "Assistants", Response: schema.Thread{}},
+		{Method: "POST", Path: "/v1/threads/:thread_id", Summary: "Modify a thread", Tag: "Assistants", Request: schema.ThreadRequest{}, Response: schema.Thread{}},
+		{Method: "DELETE", Path: "/v1/threads/:thread_id", Summary: "Delete a thread", Tag: "Assistants", Response: schema.DeletionStatus{}},
+		{Method: "GET", Path: "/v1/threads/:thread_id/export", Summary: "Export a thread, in JSON or Markdown", Tag: "Assistants", Response: schema.ThreadExport{}, Query: []string{"format"}, Extension: true},
+		{Method: "POST", Path: "/v1/threads/:thread_id/messages", Summary: "Create a message", Tag: "Assistants", Request: schema.ThreadMessageRequest{}, Response: schema.ThreadMessage{}},
+		{Method: "GET", Path: "/v1/threads/:thread_id/messages", Summary: "List the messages of a thread", Tag: "Assistants", Response: objectList[schema.ThreadMessage]{}, Query: paginated},
+		{Method: "GET", Path: "/v1/threads/:thread_id/messages/:message_id", Summary: "Get a message", Tag: "Assistants", Response: schema.ThreadMessage{}},
+		{Method: "POST", Path: "/v1/threads/:thread_id/runs", Summary: "Create a run", Tag: "Assistants", Request: schema.RunRequest{}, Response: schema.Run{}},
+		{Method: "GET", Path: "/v1/threads/:thread_id/runs", Summary: "List the runs of a thread", Tag: "Assistants", Response: objectList[schema.Run]{}, Query: paginated},
+		{Method: "GET", Path: "/v1/threads/:thread_id/runs/:run_id", Summary: "Get a run", Tag: "Assistants", Response: schema.Run{}},
+		{Method: "POST", Path: "/v1/threads/:thread_id/runs/:run_id/cancel", Summary: "Cancel a run", Tag: "Assistants", Response: schema.Run{}},
+		{Method: "POST", Path: "/v1/threads/:thread_id/runs/:run_id/submit_tool_outputs", Summary: "Submit the outputs of the tool calls of a run", Tag: "Assistants", Request: schema.SubmitToolOutputsRequest{}, Response: schema.Run{}},
+	}
+	return ops
+}
@@ -0,0 +1,121 @@
+package openai
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-skynet/LocalAI/api/backend"
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/go-skynet/LocalAI/pkg/scheduler"
+	"github.com/gofiber/fiber/v2"
+)
+
+// queueStatus is the SSE event sent while a streamed request is queued, and once it starts
+type queueStatus struct {
+	Object string `json:"object"`
+	// Position is 1 for the next request to be processed, and 0 once the request started
+	Position int `json:"position"`
+	// ETA is the estimated time in seconds before the request starts, 0 if unknown
+	ETA            float64 `json:"eta"`
+	EstimatedStart int64   `json:"estimated_start"`
+}
+
+// queueTracker follows the position of a request in the queue of its model (see the scheduler setting of the models)
+type queueTracker struct {
+	mu       sync.Mutex
+	queued   bool
+	position int
+	eta      time.Duration
+	enqueued time.Time
+	waited   time.Duration
+	latest   scheduler.Status
+	// changed is signaled when the status changed, if the request streams the queue events
+	changed chan struct{}
+}
+
+// trackQueue follows the position of the request in the queue of its model.
+// The queue events are only streamed when the X-LocalAI-Queue-Status header is set, as the clients may not expect them.
+func trackQueue(c *fiber.Ctx, input *schema.OpenAIRequest) *queueTracker {
+	q := &queueTracker{}
+	if input.Stream && c.Get("X-LocalAI-Queue-Status") != "" {
+		q.changed = make(chan struct{}, 1)
+	}
+	input.Context = backend.WithQueueStatus(input.Context, q.update)
+	input.Context = backend.WithClient(input.Context, queueClient(c))
+	if class := c.Get("X-LocalAI-Priority"); class != "" {
+		input.Context = backend.WithPriority(input.Context, class)
+	}
+	return q
+}
+
+// queueClient identifies the client of the request for the fair scheduling policy, by its API key, or else by its address
+func queueClient(c *fiber.Ctx) string {
+	if key, found := strings.CutPrefix(c.Get("Authorization"), "Bearer "); found && key != "" {
+		// the key itself is not kept
+		return fmt.Sprintf("key:%x", sha256.Sum256([]byte(key)))
+	}
+	return "ip:" + c.IP()
+}
+
+func (q *queueTracker) update(st scheduler.Status) {
+	q.mu.Lock()
+	if !q.queued {
+		q.queued = true
+		q.position, q.eta = st.Position, st.ETA
+		q.enqueued = time.Now()
+	}
+	if st.Position == 0 {
+		q.waited = time.Since(q.enqueued)
+	}
+	q.latest = st
+	q.mu.Unlock()
+
+	// the inference must not wait for the client to read the events
+	select {
+	case q.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Changed is nil when the queue events are not streamed, so that selecting on it never fires
+func (q *queueTracker) Changed() <-chan struct{} {
+	return q.changed
+}
+
+// writeEvent sends the latest status of the request as an SSE event of type queue
+func (q *queueTracker) writeEvent(w *bufio.Writer) error {
+	q.mu.Lock()
+	st := q.latest
+	q.mu.Unlock()
+
+	data, err := json.Marshal(queueStatus{
+		Object:         "queue.status",
+		Position:       st.Position,
+		ETA:            st.ETA.Seconds(),
+		EstimatedStart: time.Now().Add(st.ETA).Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: queue\ndata: %s\n\n", data); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// setHeaders reports in the response headers the position at which the request was queued and how long it waited, if it was queued
+func (q *queueTracker) setHeaders(c *fiber.Ctx) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.queued {
+		return
+	}
+	c.Set("X-LocalAI-Queue-Position", fmt.Sprint(q.position))
+	c.Set("X-LocalAI-Queue-ETA", fmt.Sprintf("%.3f", q.eta.Seconds()))
+	c.Set("X-LocalAI-Queue-Time", fmt.Sprintf("%.3f", q.waited.Seconds()))
+}
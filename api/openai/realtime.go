@@ -0,0 +1,613 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-skynet/LocalAI/api/backend"
+	config "github.com/go-skynet/LocalAI/api/config"
+	fiberContext "github.com/go-skynet/LocalAI/api/ctx"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/go-skynet/LocalAI/pkg/grammar"
+	"github.com/go-skynet/LocalAI/pkg/realtime"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// sample rate of the pcm16 audio exchanged with the clients
+	realtimeRate = 24000
+	// sample rate of the audio transcribed by whisper
+	whisperRate = 16000
+	// the synthesized audio is sent in chunks of 500ms
+	realtimeAudioChunk = realtimeRate / 2
+	// audio buffers shorter than 100ms can't be committed
+	realtimeMinAudio = realtimeRate / 10
+)
+
+// realtimeSession is the state of a Realtime API connection: the input audio
+// buffer, the conversation, and the response being generated. Transcriptions
+// and responses run one at a time, in the order they were requested.
+type realtimeSession struct {
+	conn *websocket.Conn
+	o    *options.Option
+
+	transcription, llm, tts *config.Config
+
+	writeLock sync.Mutex
+
+	// the state below is protected by mu
+	mu      sync.Mutex
+	session schema.RealtimeSession
+	vad     *realtime.VAD
+	// input audio, pcm16 at 24kHz, and the sample where the speech started
+	audio       []int16
+	speechStart int
+	items       []*schema.RealtimeItem
+	// cancels the response in progress
+	cancel context.CancelFunc
+
+	jobs chan func()
+}
+
+// RealtimeEndpoint serves the Realtime API over a WebSocket, chaining the transcription,
+// language and speech models of the pipeline of the model given in the query
+// https://platform.openai.com/docs/guides/realtime
+func RealtimeEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		if !websocket.IsWebSocketUpgrade(c) {
+			return fiber.ErrUpgradeRequired
+		}
+
+		modelFile, err := fiberContext.ModelFromContext(c, cm, o.Loader, c.Query("model"), false)
+		if err != nil {
+			return err
+		}
+		cfg, err := config.Load(modelFile, o.Loader.ModelPath, cm, o.Debug, o.Threads, o.ContextSize, o.F16)
+		if err != nil {
+			return fmt.Errorf("failed reading the model configuration: %w", err)
+		}
+
+		p := cfg.Pipeline
+		if p.Transcription == "" || p.LLM == "" || p.TTS == "" {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("model %q has no pipeline, its transcription, llm and tts models are required", modelFile))
+		}
+
+		s := &realtimeSession{
+			o: o,
+			session: schema.RealtimeSession{
+				ID:                uuid.New().String(),
+				Object:            "realtime.session",
+				Model:             modelFile,
+				Modalities:        []string{"text", "audio"},
+				InputAudioFormat:  "pcm16",
+				OutputAudioFormat: "pcm16",
+				InputAudioTranscription: &schema.RealtimeTranscription{
+					Model: p.Transcription,
+				},
+				TurnDetection: &schema.RealtimeTurnDetection{
+					Type:              "server_vad",
+					Threshold:         0.02,
+					PrefixPaddingMs:   300,
+					SilenceDurationMs: 500,
+				},
+			},
+			jobs: make(chan func(), 16),
+		}
+		for name, dst := range map[string]**config.Config{p.Transcription: &s.transcription, p.LLM: &s.llm, p.TTS: &s.tts} {
+			if *dst, err = config.Load(name, o.Loader.ModelPath, cm, o.Debug, o.Threads, o.ContextSize, o.F16); err != nil {
+				return fmt.Errorf("failed reading the configuration of %q: %w", name, err)
+			}
+		}
+		s.updateVAD()
+
+		return websocket.New(func(conn *websocket.Conn) {
+			s.conn = conn
+			s.run()
+		})(c)
+	}
+}
+
+// run processes the events of the client until the connection is closed
+func (s *realtimeSession) run() {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for job := range s.jobs {
+			job()
+		}
+	}()
+	defer func() {
+		s.cancelResponse()
+		close(s.jobs)
+		<-done
+	}()
+
+	s.send(schema.RealtimeEvent{Type: schema.RealtimeSessionCreated, Session: s.sessionState()})
+
+	for {
+		_, msg, err := s.conn.ReadMessage()
+		if err != nil {
+			log.Debug().Msgf("realtime session %s closed: %s", s.session.ID, err)
+			return
+		}
+
+		event := schema.RealtimeEvent{}
+		if err := json.Unmarshal(msg, &event); err != nil {
+			s.error("invalid_request_error", "invalid_event", fmt.Sprintf("failed parsing the event: %s", err), "")
+			continue
+		}
+		s.handle(event)
+	}
+}
+
+func (s *realtimeSession) handle(event schema.RealtimeEvent) {
+	switch event.Type {
+	case schema.RealtimeSessionUpdate:
+		if event.Session == nil {
+			s.error("invalid_request_error", "missing_session", "session.update requires a session", event.EventID)
+			return
+		}
+		s.updateSession(*event.Session)
+		s.send(schema.RealtimeEvent{Type: schema.RealtimeSessionUpdated, Session: s.sessionState()})
+	case schema.RealtimeInputAudioBufferAppend:
+		pcm, err := base64.StdEncoding.DecodeString(event.Audio)
+		if err != nil {
+			s.error("invalid_request_error", "invalid_audio", fmt.Sprintf("the audio is not base64 encoded: %s", err), event.EventID)
+			return
+		}
+		s.appendAudio(realtime.DecodePCM16(pcm))
+	case schema.RealtimeInputAudioBufferCommit:
+		if err := s.commit(); err != nil {
+			s.error("invalid_request_error", "input_audio_buffer_commit_empty", err.Error(), event.EventID)
+		}
+	case schema.RealtimeInputAudioBufferClear:
+		s.mu.Lock()
+		s.audio = nil
+		s.speechStart = 0
+		if s.vad != nil {
+			s.vad.Reset()
+		}
+		s.mu.Unlock()
+		s.send(schema.RealtimeEvent{Type: schema.RealtimeInputAudioBufferCleared})
+	case schema.RealtimeConversationItemCreate:
+		if event.Item == nil || event.Item.Type != "message" {
+			s.error("invalid_request_error", "invalid_item", "only message items are supported", event.EventID)
+			return
+		}
+		item := *event.Item
+		if item.ID == "" {
+			item.ID = "item_" + uuid.New().String()
+		}
+		item.Object = "realtime.item"
+		item.Status = schema.RealtimeStatusCompleted
+		s.addItem(&item, nil)
+	case schema.RealtimeResponseCreate:
+		s.createResponse()
+	case schema.RealtimeResponseCancel:
+		s.cancelResponse()
+	default:
+		s.error("invalid_request_error", "unknown_event", fmt.Sprintf("unsupported event %q", event.Type), event.EventID)
+	}
+}
+
+// send sends an event to the client
+func (s *realtimeSession) send(event schema.RealtimeEvent) {
+	event.EventID = "event_" + uuid.New().String()
+
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+	if err := s.conn.WriteJSON(event); err != nil {
+		log.Debug().Msgf("failed sending realtime event %s: %s", event.Type, err)
+	}
+}
+
+func (s *realtimeSession) error(kind, code, message, eventID string) {
+	s.send(schema.RealtimeEvent{Type: schema.RealtimeError, Error: &schema.RealtimeErrorDetails{
+		Type:    kind,
+		Code:    code,
+		Message: message,
+		EventID: eventID,
+	}})
+}
+
+func (s *realtimeSession) sessionState() *schema.RealtimeSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := s.session
+	return &res
+}
+
+// updateSession applies the settings given by the client, the models of the pipeline can't be changed
+func (s *realtimeSession) updateSession(update schema.RealtimeSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if update.Modalities != nil {
+		s.session.Modalities = update.Modalities
+	}
+	if update.Instructions != "" {
+		s.session.Instructions = update.Instructions
+	}
+	if update.Voice != "" {
+		s.session.Voice = update.Voice
+	}
+	if update.TurnDetection != nil {
+		td := *update.TurnDetection
+		if td.Threshold == 0 {
+			td.Threshold = s.session.TurnDetection.Threshold
+		}
+		if td.SilenceDurationMs == 0 {
+			td.SilenceDurationMs = s.session.TurnDetection.SilenceDurationMs
+		}
+		s.session.TurnDetection = &td
+	}
+	if update.Temperature != 0 {
+		s.session.Temperature = update.Temperature
+	}
+	if update.MaxResponseOutputTokens != 0 {
+		s.session.MaxResponseOutputTokens = update.MaxResponseOutputTokens
+	}
+	s.updateVAD()
+}
+
+// updateVAD configures the voice activity detection from the turn detection
+// of the session, it must be called with the lock held
+func (s *realtimeSession) updateVAD() {
+	td := s.session.TurnDetection
+	if td == nil || td.Type != "server_vad" {
+		s.vad = nil
+		return
+	}
+	s.vad = realtime.NewVAD(realtimeRate, td.Threshold, time.Duration(td.SilenceDurationMs)*time.Millisecond)
+	// the audio already buffered is not analyzed again
+	s.vad.Process(make([]int16, len(s.audio)))
+}
+
+// appendAudio adds audio to the input buffer. With the server VAD, a speech
+// interrupts the response in progress, and its end commits the buffer and
+// creates a response.
+func (s *realtimeSession) appendAudio(samples []int16) {
+	s.mu.Lock()
+	s.audio = append(s.audio, samples...)
+	events := []realtime.VADEvent{}
+	if s.vad != nil {
+		events = s.vad.Process(samples)
+	}
+	s.mu.Unlock()
+
+	for _, e := range events {
+		ms := int(e.Offset.Milliseconds())
+		switch e.Type {
+		case realtime.SpeechStarted:
+			s.mu.Lock()
+			s.speechStart = int(e.Offset.Seconds() * realtimeRate)
+			s.mu.Unlock()
+			s.cancelResponse()
+			s.send(schema.RealtimeEvent{Type: schema.RealtimeSpeechStarted, AudioStartMs: ms})
+		case realtime.SpeechStopped:
+			s.send(schema.RealtimeEvent{Type: schema.RealtimeSpeechStopped, AudioEndMs: ms})
+			if err := s.commit(); err != nil {
+				log.Debug().Msgf("realtime session %s: %s", s.session.ID, err)
+				continue
+			}
+			s.createResponse()
+		}
+	}
+}
+
+// commit adds the audio buffer to the conversation, and transcribes it
+func (s *realtimeSession) commit() error {
+	s.mu.Lock()
+	start := 0
+	if s.session.TurnDetection != nil {
+		start = s.speechStart - s.session.TurnDetection.PrefixPaddingMs*realtimeRate/1000
+	}
+	if start < 0 {
+		start = 0
+	}
+	if start > len(s.audio) {
+		start = len(s.audio)
+	}
+	audio := s.audio[start:]
+	s.audio = nil
+	s.speechStart = 0
+	if s.vad != nil {
+		s.vad.Reset()
+	}
+	s.mu.Unlock()
+
+	if len(audio) < realtimeMinAudio {
+		return fmt.Errorf("the input audio buffer has less than 100ms of audio")
+	}
+
+	item := &schema.RealtimeItem{
+		ID:      "item_" + uuid.New().String(),
+		Object:  "realtime.item",
+		Type:    "message",
+		Status:  schema.RealtimeStatusCompleted,
+		Role:    "user",
+		Content: []schema.RealtimeContentPart{{Type: "input_audio"}},
+	}
+	s.addItem(item, func(previous string) {
+		s.send(schema.RealtimeEvent{Type: schema.RealtimeInputAudioBufferCommitted, ItemID: item.ID, PreviousItemID: previous})
+	})
+
+	s.jobs <- func() {
+		transcript, err := s.transcribe(audio)
+		if err != nil {
+			log.Error().Msgf("realtime transcription failed: %s", err)
+			s.send(schema.RealtimeEvent{Type: schema.RealtimeTranscriptionFailed, ItemID: item.ID, Error: &schema.RealtimeErrorDetails{
+				Type:    "transcription_error",
+				Message: err.Error(),
+			}})
+			return
+		}
+		s.mu.Lock()
+		item.Content[0].Transcript = transcript
+		s.mu.Unlock()
+		s.send(schema.RealtimeEvent{Type: schema.RealtimeTranscriptionCompleted, ItemID: item.ID, Transcript: transcript})
+	}
+	return nil
+}
+
+// addItem adds an item to the conversation and sends conversation.item.created,
+// before sends the events preceding it, given the ID of the previous item
+func (s *realtimeSession) addItem(item *schema.RealtimeItem, before func(previous string)) {
+	s.mu.Lock()
+	previous := ""
+	if len(s.items) > 0 {
+		previous = s.items[len(s.items)-1].ID
+	}
+	s.items = append(s.items, item)
+	created := *item
+	s.mu.Unlock()
+
+	if before != nil {
+		before(previous)
+	}
+	s.send(schema.RealtimeEvent{Type: schema.RealtimeConversationItemCreated, PreviousItemID: previous, Item: &created})
+}
+
+// transcribe transcribes pcm16 audio at 24kHz with the transcription model
+func (s *realtimeSession) transcribe(audio []int16) (string, error) {
+	dir, err := s.o.Workspace.MkdirTemp("realtime")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	dst := filepath.Join(dir, "input.wav")
+	f, err := os.Create(dst)
+	if err != nil {
+		return "", err
+	}
+	err = realtime.WriteWAV(f, realtime.Resample(audio, realtimeRate, whisperRate), whisperRate)
+	f.Close()
+	if err != nil {
+		return "", err
+	}
+
+	tr, err := backend.ModelTranscription(dst, "", false, false, s.o.Loader, *s.transcription, s.o)
+	if err != nil {
+		return "", err
+	}
+	return tr.Text, nil
+}
+
+// cancelResponse stops the generation of the response in progress, if any
+func (s *realtimeSession) cancelResponse() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+}
+
+// createResponse queues the generation of a response to the conversation
+func (s *realtimeSession) createResponse() {
+	ctx, cancel := context.WithCancel(s.o.Context)
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.cancel = cancel
+	s.mu.Unlock()
+
+	s.jobs <- func() {
+		defer cancel()
+		s.respond(ctx)
+	}
+}
+
+// respond generates the reply to the conversation with the language model,
+// and synthesizes it sentence by sentence while it is streamed
+func (s *realtimeSession) respond(ctx context.Context) {
+	s.mu.Lock()
+	session := s.session
+	input := &schema.OpenAIRequest{Context: ctx}
+	if session.Instructions != "" {
+		input.Messages = append(input.Messages, schema.Message{Role: "system", StringContent: session.Instructions, Content: session.Instructions})
+	}
+	for _, item := range s.items {
+		text := ""
+		for _, c := range item.Content {
+			text += c.String()
+		}
+		if text != "" {
+			input.Messages = append(input.Messages, schema.Message{Role: item.Role, StringContent: text, Content: text})
+		}
+	}
+	s.mu.Unlock()
+
+	audio := false
+	for _, m := range session.Modalities {
+		audio = audio || m == "audio"
+	}
+
+	response := &schema.RealtimeResponse{
+		ID:     "resp_" + uuid.New().String(),
+		Object: "realtime.response",
+		Status: schema.RealtimeStatusInProgress,
+		Output: []schema.RealtimeItem{},
+	}
+	s.send(schema.RealtimeEvent{Type: schema.RealtimeResponseCreated, Response: response})
+
+	item := &schema.RealtimeItem{
+		ID:      "item_" + uuid.New().String(),
+		Object:  "realtime.item",
+		Type:    "message",
+		Status:  schema.RealtimeStatusInProgress,
+		Role:    "assistant",
+		Content: []schema.RealtimeContentPart{},
+	}
+	s.send(schema.RealtimeEvent{Type: schema.RealtimeResponseOutputItemAdded, ResponseID: response.ID, Item: item})
+
+	part := schema.RealtimeContentPart{Type: "text"}
+	textDelta, textDone := schema.RealtimeResponseTextDelta, schema.RealtimeResponseTextDone
+	if audio {
+		part.Type = "audio"
+		textDelta, textDone = schema.RealtimeResponseAudioTranscriptDelta, schema.RealtimeResponseAudioTranscriptDone
+	}
+	s.send(schema.RealtimeEvent{Type: schema.RealtimeResponseContentPartAdded, ResponseID: response.ID, ItemID: item.ID, Part: &part})
+
+	// the sentences are synthesized while the next ones are generated
+	sentences := make(chan string, 16)
+	synthesized := make(chan struct{})
+	go func() {
+		defer close(synthesized)
+		for sentence := range sentences {
+			if ctx.Err() != nil {
+				continue
+			}
+			if err := s.synthesize(ctx, session.Voice, response.ID, item.ID, sentence); err != nil {
+				log.Error().Msgf("realtime speech synthesis failed: %s", err)
+			}
+		}
+	}()
+
+	splitter := &realtime.SentenceSplitter{}
+	text := ""
+	usage, err := s.generate(input, session, func(token string) {
+		if ctx.Err() != nil {
+			return
+		}
+		text += token
+		s.send(schema.RealtimeEvent{Type: textDelta, ResponseID: response.ID, ItemID: item.ID, Delta: token})
+		if audio {
+			for _, sentence := range splitter.Write(token) {
+				sentences <- sentence
+			}
+		}
+	})
+	if rest := splitter.Flush(); audio && rest != "" {
+		sentences <- rest
+	}
+	close(sentences)
+	<-synthesized
+
+	status := schema.RealtimeStatusCompleted
+	switch {
+	case ctx.Err() != nil:
+		status = schema.RealtimeStatusCancelled
+	case err != nil:
+		status = schema.RealtimeStatusFailed
+		log.Error().Msgf("realtime response failed: %s", err)
+		s.error("server_error", "response_failed", err.Error(), "")
+	}
+
+	if audio {
+		part.Transcript = text
+		s.send(schema.RealtimeEvent{Type: schema.RealtimeResponseAudioDone, ResponseID: response.ID, ItemID: item.ID})
+		s.send(schema.RealtimeEvent{Type: textDone, ResponseID: response.ID, ItemID: item.ID, Transcript: text})
+	} else {
+		part.Text = text
+		s.send(schema.RealtimeEvent{Type: textDone, ResponseID: response.ID, ItemID: item.ID, Text: text})
+	}
+	s.send(schema.RealtimeEvent{Type: schema.RealtimeResponseContentPartDone, ResponseID: response.ID, ItemID: item.ID, Part: &part})
+
+	// the conversation keeps what was generated before an interruption
+	item.Status = status
+	if status == schema.RealtimeStatusCancelled {
+		item.Status = "incomplete"
+	}
+	item.Content = []schema.RealtimeContentPart{part}
+	s.send(schema.RealtimeEvent{Type: schema.RealtimeResponseOutputItemDone, ResponseID: response.ID, Item: item})
+	if text != "" {
+		s.addItem(item, nil)
+	}
+
+	response.Status = status
+	response.Output = []schema.RealtimeItem{*item}
+	response.Usage = &schema.RealtimeUsage{
+		InputTokens:  usage.Prompt,
+		OutputTokens: usage.Completion,
+		TotalTokens:  usage.Prompt + usage.Completion,
+	}
+	s.send(schema.RealtimeEvent{Type: schema.RealtimeResponseDone, Response: response})
+}
+
+// generate streams the reply of the language model to the messages
+func (s *realtimeSession) generate(input *schema.OpenAIRequest, session schema.RealtimeSession, token func(string)) (backend.TokenUsage, error) {
+	input.Temperature = session.Temperature
+	input.Maxtokens = session.MaxResponseOutputTokens
+
+	cfg := *s.llm
+	updateRequestConfig(&cfg, input)
+	predInput := chatPrompt(&cfg, s.o.Loader, input.Messages, grammar.Functions{}, false)
+
+	_, usage, err := ComputeChoices(input, predInput, &cfg, s.o, s.o.Loader, func(string, *[]schema.Choice) {}, func(t string, _ backend.TokenUsage) bool {
+		token(t)
+		return true
+	})
+	return usage, err
+}
+
+// synthesize sends the speech of a sentence, resampled to pcm16 at 24kHz.
+// The voice, when set, is a voice of the TTS model.
+func (s *realtimeSession) synthesize(ctx context.Context, voice, responseID, itemID, sentence string) error {
+	v, err := s.tts.Voice(voice)
+	if err != nil {
+		return err
+	}
+	filePath, _, err := backend.ModelTTS(sentence, v, s.o.Loader, s.o, *s.tts)
+	if err != nil {
+		return err
+	}
+	dat, err := os.ReadFile(filePath)
+	os.Remove(filePath)
+	if err != nil {
+		return err
+	}
+
+	samples, rate, err := realtime.ReadWAV(dat)
+	if err != nil {
+		return err
+	}
+	samples = realtime.Resample(samples, rate, realtimeRate)
+
+	for len(samples) > 0 && ctx.Err() == nil {
+		n := realtimeAudioChunk
+		if n > len(samples) {
+			n = len(samples)
+		}
+		s.send(schema.RealtimeEvent{
+			Type:       schema.RealtimeResponseAudioDelta,
+			ResponseID: responseID,
+			ItemID:     itemID,
+			Delta:      base64.StdEncoding.EncodeToString(realtime.EncodePCM16(samples[:n])),
+		})
+		samples = samples[n:]
+	}
+	return nil
+}
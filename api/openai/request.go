@@ -2,25 +2,25 @@ package openai
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"strings"
 
+	"github.com/go-skynet/LocalAI/api/backend"
 	config "github.com/go-skynet/LocalAI/api/config"
 	fiberContext "github.com/go-skynet/LocalAI/api/ctx"
 	options "github.com/go-skynet/LocalAI/api/options"
 	"github.com/go-skynet/LocalAI/api/schema"
 	model "github.com/go-skynet/LocalAI/pkg/model"
 	"github.com/gofiber/fiber/v2"
-	"github.com/rs/zerolog/log"
 )
 
-func readRequest(c *fiber.Ctx, o *options.Option, firstModel bool) (string, *schema.OpenAIRequest, error) {
+func readRequest(c *fiber.Ctx, cm *config.ConfigLoader, o *options.Option, firstModel bool) (string, *schema.OpenAIRequest, error) {
 	input := new(schema.OpenAIRequest)
-	ctx, cancel := context.WithCancel(o.Context)
+	// the inferences outlive the connections of the clients, the request is carried for its ID and its access log
+	ctx, cancel := context.WithCancel(fiberContext.WithRequest(o.Context, fiberContext.RequestFromCtx(c)))
+	if c.Get("X-LocalAI-Trace") != "" {
+		ctx = backend.WithTrace(ctx)
+	}
 	input.Context = ctx
 	input.Cancel = cancel
 	// Get input data from the request body
@@ -30,44 +30,13 @@ func readRequest(c *fiber.Ctx, o *options.Option, firstModel bool) (string, *sch
 
 	received, _ := json.Marshal(input)
 
-	log.Debug().Msgf("Request received: %s", string(received))
+	fiberContext.Logger(ctx).Debug().Msgf("Request received: %s", string(received))
 
-	modelFile, err := fiberContext.ModelFromContext(c, o.Loader, input.Model, firstModel)
+	modelFile, err := fiberContext.ModelFromContext(c, cm, o.Loader, input.Model, firstModel)
 
 	return modelFile, input, err
 }
 
-// this function check if the string is an URL, if it's an URL downloads the image in memory
-// encodes it in base64 and returns the base64 string
-func getBase64Image(s string) (string, error) {
-	if strings.HasPrefix(s, "http") {
-		// download the image
-		resp, err := http.Get(s)
-		if err != nil {
-			return "", err
-		}
-		defer resp.Body.Close()
-
-		// read the image data into memory
-		data, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return "", err
-		}
-
-		// encode the image data in base64
-		encoded := base64.StdEncoding.EncodeToString(data)
-
-		// return the base64 string
-		return encoded, nil
-	}
-
-	// if the string instead is prefixed with "data:image/jpeg;base64,", drop it
-	if strings.HasPrefix(s, "data:image/jpeg;base64,") {
-		return strings.ReplaceAll(s, "data:image/jpeg;base64,", ""), nil
-	}
-	return "", fmt.Errorf("not valid string")
-}
-
 func updateRequestConfig(config *config.Config, input *schema.OpenAIRequest) {
 	if input.Echo {
 		config.Echo = input.Echo
@@ -136,7 +105,19 @@ func updateRequestConfig(config *config.Config, input *schema.OpenAIRequest) {
 		}
 	}
 
-	// Decode each request's message content
+	// Chat completions ask for the logprobs with a boolean, completions with the number of alternatives
+	switch lp := input.Logprobs.(type) {
+	case bool:
+		config.Logprobs = lp
+	case float64:
+		config.Logprobs = true
+		config.TopLogprobs = int(lp)
+	}
+	if input.TopLogprobs != 0 {
+		config.TopLogprobs = input.TopLogprobs
+	}
+
+	// Decode each request's message content, the URLs of the images are read by readImages
 	index := 0
 	for i, m := range input.Messages {
 		switch content := m.Content.(type) {
@@ -146,22 +127,8 @@ func updateRequestConfig(config *config.Config, input *schema.OpenAIRequest) {
 			dat, _ := json.Marshal(content)
 			c := []schema.Content{}
 			json.Unmarshal(dat, &c)
-			for _, pp := range c {
-				if pp.Type == "text" {
-					input.Messages[i].StringContent = pp.Text
-				} else if pp.Type == "image_url" {
-					// Detect if pp.ImageURL is an URL, if it is download the image and encode it in base64:
-					base64, err := getBase64Image(pp.ImageURL.URL)
-					if err == nil {
-						input.Messages[i].StringImages = append(input.Messages[i].StringImages, base64) // TODO: make sure that we only return base64 stuff
-						// set a placeholder for each image
-						input.Messages[i].StringContent = fmt.Sprintf("[img-%d]", index) + input.Messages[i].StringContent
-						index++
-					} else {
-						fmt.Print("Failed encoding image", err)
-					}
-				}
-			}
+			input.Messages[i].StringContent, input.Messages[i].StringImages = messageContent(c, index)
+			index += len(input.Messages[i].StringImages)
 		}
 	}
 
@@ -243,6 +210,25 @@ func updateRequestConfig(config *config.Config, input *schema.OpenAIRequest) {
 		config.SetFunctionCallNameString(name)
 	}
 
+	// Tools are functions called with tool_calls, their choice can be either a string or an object
+	for _, t := range input.Tools {
+		if t.Type == "function" {
+			input.Functions = append(input.Functions, t.Function)
+		}
+	}
+	switch tc := input.ToolChoice.(type) {
+	case string:
+		if tc != "" {
+			config.SetFunctionCallString(tc)
+		}
+	case map[string]interface{}:
+		if fn, ok := tc["function"].(map[string]interface{}); ok {
+			if name, ok := fn["name"].(string); ok {
+				config.SetFunctionCallNameString(name)
+			}
+		}
+	}
+
 	switch p := input.Prompt.(type) {
 	case string:
 		config.PromptStrings = append(config.PromptStrings, p)
@@ -0,0 +1,143 @@
+package openai
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-skynet/LocalAI/api/backend"
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/go-skynet/LocalAI/pkg/responsecache"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultResponseCacheEntries = 1000
+	defaultSemanticThreshold    = 0.95
+)
+
+type modelResponseCache struct {
+	*responsecache.Cache
+	settings config.ResponseCache
+}
+
+var responseCachesMu sync.Mutex
+var responseCaches = map[string]*modelResponseCache{}
+
+// responseCache returns the response cache of the model, a new one when its settings changed
+func responseCache(cfg *config.Config) *responsecache.Cache {
+	responseCachesMu.Lock()
+	defer responseCachesMu.Unlock()
+	c, ok := responseCaches[cfg.Name]
+	if !ok || c.settings != cfg.ResponseCache {
+		maxEntries := cfg.ResponseCache.MaxEntries
+		if maxEntries == 0 {
+			maxEntries = defaultResponseCacheEntries
+		}
+		c = &modelResponseCache{
+			Cache:    responsecache.New(maxEntries, time.Duration(cfg.ResponseCache.TTL)*time.Second),
+			settings: cfg.ResponseCache,
+		}
+		responseCaches[cfg.Name] = c
+	}
+	return c.Cache
+}
+
+// cachedResponse is the lookup of a request in the response cache of its model
+type cachedResponse struct {
+	cache *responsecache.Cache
+	key   string
+	// the hash of the parts of the key besides the prompts, which partitions the semantic cache
+	partition string
+	embedding []float32
+}
+
+// lookupResponse returns the cached response to the request, whose prompts are taken from the request. The request is
+// identified by its prompts, its parameters merged with the config of the model and the other parts of the key (e.g.
+// the type of the response), or by the similarity of its prompts with the ones of the semantic cache of the requests
+// with the same parameters and other parts of the key. The responses aren't looked up for the streamed requests, and
+// for the requests with the Cache-Control: no-cache header, which are cached once computed.
+func lookupResponse(c *fiber.Ctx, cm *config.ConfigLoader, o *options.Option, cfg *config.Config, input *schema.OpenAIRequest, prompts []string, key ...interface{}) (*schema.OpenAIResponse, *cachedResponse) {
+	if !cfg.ResponseCache.Enabled || input.Stream {
+		return nil, nil
+	}
+
+	partition := responsecache.Key(append(key, cfg.PredictionOptions, cfg.Grammar, len(input.Tools) > 0)...)
+	cr := &cachedResponse{
+		cache:     responseCache(cfg),
+		key:       responsecache.Key(partition, prompts),
+		partition: partition,
+	}
+	prompt := strings.Join(prompts, "\n")
+	lookup := c.Get(fiber.HeaderCacheControl) != "no-cache"
+
+	var data []byte
+	hit := ""
+	if lookup {
+		if d, ok := cr.cache.Get(cr.key); ok {
+			data, hit = d, "hit"
+		}
+	}
+
+	if semantic := cfg.ResponseCache.Semantic; semantic.Model != "" && hit == "" {
+		embedding, err := promptEmbedding(cm, o, semantic.Model, prompt)
+		if err != nil {
+			log.Warn().Msgf("Failed computing the embedding of the prompt for the response cache of %s: %s", cfg.Name, err.Error())
+		} else {
+			// the embedding is cached with the response
+			cr.embedding = embedding
+			threshold := semantic.Threshold
+			if threshold == 0 {
+				threshold = defaultSemanticThreshold
+			}
+			if d, score, ok := cr.cache.Similar(cr.partition, embedding, threshold); ok && lookup {
+				log.Debug().Msgf("Found a cached response for %s with a similarity of %f", cfg.Name, score)
+				data, hit = d, "semantic"
+			}
+		}
+	}
+
+	if hit == "" {
+		c.Set("X-LocalAI-Cache", "miss")
+		return nil, cr
+	}
+	resp := &schema.OpenAIResponse{}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return nil, cr
+	}
+	// a new response with the same choices
+	resp.ID = uuid.New().String()
+	resp.Created = int(time.Now().Unix())
+	resp.Model = input.Model
+	c.Set("X-LocalAI-Cache", hit)
+	return resp, nil
+}
+
+// store caches the response computed for the request
+func (cr *cachedResponse) store(resp *schema.OpenAIResponse) {
+	if cr == nil {
+		return
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	cr.cache.Put(cr.key, data, cr.partition, cr.embedding)
+}
+
+func promptEmbedding(cm *config.ConfigLoader, o *options.Option, model string, prompt string) ([]float32, error) {
+	c, err := config.Load(model, o.Loader.ModelPath, cm, o.Debug, o.Threads, o.ContextSize, o.F16)
+	if err != nil {
+		return nil, err
+	}
+	embedFn, err := backend.ModelEmbedding(prompt, []int{}, o.Loader, *c, o)
+	if err != nil {
+		return nil, err
+	}
+	return embedFn()
+}
@@ -0,0 +1,41 @@
+package openai
+
+import (
+	"fmt"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/go-skynet/LocalAI/pkg/grammar"
+	"github.com/gofiber/fiber/v2"
+)
+
+// responseFormatGrammar constrains the generation to the response format of the request:
+// any JSON object with json_object, or the JSON documents matching the schema with json_schema
+func responseFormatGrammar(config *config.Config, input *schema.OpenAIRequest) error {
+	switch input.ResponseFormat.Type {
+	case "json_object":
+		config.Grammar = grammar.JSONBNF
+	case "json_schema":
+		if input.ResponseFormat.JSONSchema == nil || input.ResponseFormat.JSONSchema.Schema == nil {
+			return fiber.NewError(fiber.StatusBadRequest, "response_format json_schema requires a schema")
+		}
+		g, err := grammar.SchemaGrammar(input.ResponseFormat.JSONSchema.Schema)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		config.Grammar = g
+	}
+	return nil
+}
+
+// validateResponseFormat checks that the response matches the JSON schema of the response format,
+// as the backends which do not support grammars generate it unconstrained
+func validateResponseFormat(input *schema.OpenAIRequest, s string) error {
+	if input.ResponseFormat.Type != "json_schema" || input.ResponseFormat.JSONSchema == nil {
+		return nil
+	}
+	if err := grammar.ValidateJSON(input.ResponseFormat.JSONSchema.Schema, []byte(s)); err != nil {
+		return fmt.Errorf("the response does not match the JSON schema %q: %w", input.ResponseFormat.JSONSchema.Name, err)
+	}
+	return nil
+}
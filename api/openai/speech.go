@@ -0,0 +1,216 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-skynet/LocalAI/api/backend"
+	config "github.com/go-skynet/LocalAI/api/config"
+	fiberContext "github.com/go-skynet/LocalAI/api/ctx"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/go-skynet/LocalAI/pkg/realtime"
+	"github.com/go-skynet/LocalAI/pkg/ssml"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/valyala/fasthttp"
+)
+
+// the sample rate of the pcm speech, the one of the OpenAI API
+const speechRate = 24000
+
+// https://platform.openai.com/docs/api-reference/audio/createSpeech
+// The voice is a voice of the model, or a cloned voice of the voices, nil if the Files API is disabled.
+func SpeechEndpoint(cm *config.ConfigLoader, o *options.Option, voices *VoicesService) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		input := new(schema.SpeechRequest)
+		if err := c.BodyParser(input); err != nil {
+			return err
+		}
+		switch input.ResponseFormat {
+		case "", "wav", "pcm":
+		default:
+			return fiber.NewError(fiber.StatusBadRequest, "unsupported response_format: "+input.ResponseFormat)
+		}
+		switch input.StreamFormat {
+		case "", "audio", "sse":
+		default:
+			return fiber.NewError(fiber.StatusBadRequest, "unsupported stream_format: "+input.StreamFormat)
+		}
+		if ssml.IsSSML(input.Input) {
+			if _, err := ssml.Parse(input.Input); err != nil {
+				return fiber.NewError(fiber.StatusBadRequest, err.Error())
+			}
+		}
+
+		cfg, voice, err := speechVoice(c, cm, o, voices, input)
+		if err != nil {
+			return err
+		}
+		if input.StreamFormat != "" {
+			return streamSpeech(c, input, voice, *cfg, o)
+		}
+
+		filePath, _, err := backend.ModelTTS(input.Input, voice, o.Loader, o, *cfg)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(filePath)
+		if r := fiberContext.RequestFromCtx(c); r != nil {
+			if d, err := backend.AudioDuration(filePath); err == nil {
+				r.AddAudio(d.Seconds())
+			}
+		}
+		dat, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+
+		if input.ResponseFormat == "pcm" {
+			samples, rate, err := realtime.ReadWAV(dat)
+			if err != nil {
+				return err
+			}
+			c.Set(fiber.HeaderContentType, "audio/pcm")
+			return c.Send(realtime.EncodePCM16(realtime.Resample(samples, rate, speechRate)))
+		}
+		c.Set(fiber.HeaderContentType, "audio/wav")
+		return c.Send(dat)
+	}
+}
+
+// speechVoice returns the TTS model and the voice of the request. The cloned voices are spoken by their own model.
+func speechVoice(c *fiber.Ctx, cm *config.ConfigLoader, o *options.Option, voices *VoicesService, input *schema.SpeechRequest) (*config.Config, config.Voice, error) {
+	if voices != nil && strings.HasPrefix(input.Voice, "voice-") {
+		return voices.Resolve(c, input.Voice)
+	}
+
+	modelFile, err := fiberContext.ModelFromContext(c, cm, o.Loader, input.Model, false)
+	if err != nil {
+		return nil, config.Voice{}, err
+	}
+	cfg, err := config.Load(modelFile, o.Loader.ModelPath, cm, false, 0, 0, false)
+	if err != nil {
+		return nil, config.Voice{}, fmt.Errorf("failed reading parameters from request:%w", err)
+	}
+	voice, err := cfg.Voice(input.Voice)
+	if err != nil {
+		return nil, config.Voice{}, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return cfg, voice, nil
+}
+
+// streamSpeech synthesizes the input sentence by sentence, and sends the audio of each sentence as soon as it is
+// synthesized. The first sentence is synthesized before the response is sent, so that its errors set the status.
+func streamSpeech(c *fiber.Ctx, input *schema.SpeechRequest, voice config.Voice, cfg config.Config, o *options.Option) error {
+	sentences := speechSentences(input.Input)
+	samples, rate, err := synthesizeSpeech(sentences[0], voice, cfg, o)
+	if err != nil {
+		return err
+	}
+	// the audio is sent at the rate of the first sentence, or at the one of the pcm format
+	out := rate
+	header := &bytes.Buffer{}
+	if input.ResponseFormat == "pcm" {
+		out = speechRate
+	} else if err := realtime.WriteWAVHeader(header, out, realtime.StreamedWAVSize); err != nil {
+		return err
+	}
+
+	sse := input.StreamFormat == "sse"
+	switch {
+	case sse:
+		c.Context().SetContentType("text/event-stream")
+		c.Set("Cache-Control", "no-cache")
+		c.Set("Connection", "keep-alive")
+	case input.ResponseFormat == "pcm":
+		c.Context().SetContentType("audio/pcm")
+	default:
+		c.Context().SetContentType("audio/wav")
+	}
+	c.Set("Transfer-Encoding", "chunked")
+
+	r := fiberContext.RequestFromCtx(c)
+	c.Context().SetBodyStreamWriter(fasthttp.StreamWriter(func(w *bufio.Writer) {
+		send := func(ev schema.SpeechEvent) error {
+			dat, err := json.Marshal(ev)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", dat); err != nil {
+				return err
+			}
+			return w.Flush()
+		}
+		write := func(audio []byte) error {
+			if sse {
+				return send(schema.SpeechEvent{Type: schema.SpeechAudioDelta, Audio: base64.StdEncoding.EncodeToString(audio)})
+			}
+			if _, err := w.Write(audio); err != nil {
+				return err
+			}
+			return w.Flush()
+		}
+
+		for i := 0; ; i++ {
+			if r != nil {
+				r.AddAudio(float64(len(samples)) / float64(rate))
+			}
+			audio := append(header.Bytes(), realtime.EncodePCM16(realtime.Resample(samples, rate, out))...)
+			header.Reset()
+			// when the client disconnected, the rest of the input is not synthesized
+			if err := write(audio); err != nil || i+1 == len(sentences) {
+				break
+			}
+			if samples, rate, err = synthesizeSpeech(sentences[i+1], voice, cfg, o); err != nil {
+				log.Debug().Msgf("Streaming the speech failed: %v", err)
+				if sse {
+					send(schema.SpeechEvent{Type: schema.SpeechError, Error: err.Error()})
+				}
+				return
+			}
+		}
+		if sse {
+			send(schema.SpeechEvent{Type: schema.SpeechAudioDone})
+			w.WriteString("data: [DONE]\n\n")
+			w.Flush()
+		}
+	}))
+	return nil
+}
+
+// speechSentences splits the input in the sentences synthesized one after the other. The SSML documents are
+// synthesized at once.
+func speechSentences(text string) []string {
+	if ssml.IsSSML(text) {
+		return []string{text}
+	}
+	splitter := realtime.SentenceSplitter{}
+	sentences := splitter.Write(text)
+	if rest := splitter.Flush(); rest != "" {
+		sentences = append(sentences, rest)
+	}
+	if len(sentences) == 0 {
+		return []string{text}
+	}
+	return sentences
+}
+
+// synthesizeSpeech returns the samples of the speech of the text, and their rate
+func synthesizeSpeech(text string, voice config.Voice, cfg config.Config, o *options.Option) ([]int16, int, error) {
+	filePath, _, err := backend.ModelTTS(text, voice, o.Loader, o, cfg)
+	if err != nil {
+		return nil, 0, err
+	}
+	dat, err := os.ReadFile(filePath)
+	os.Remove(filePath)
+	if err != nil {
+		return nil, 0, err
+	}
+	return realtime.ReadWAV(dat)
+}
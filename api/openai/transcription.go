@@ -1,25 +1,48 @@
 package openai
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-skynet/LocalAI/api/backend"
 	config "github.com/go-skynet/LocalAI/api/config"
+	fiberContext "github.com/go-skynet/LocalAI/api/ctx"
 	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/api/schema"
+	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
+	"github.com/go-skynet/LocalAI/pkg/realtime"
+	"github.com/go-skynet/LocalAI/pkg/subtitles"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog/log"
+	"github.com/valyala/fasthttp"
 )
 
-// https://platform.openai.com/docs/api-reference/audio/create
+// https://platform.openai.com/docs/api-reference/audio/createTranscription
 func TranscriptEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx) error {
+	return audioEndpoint(cm, o, false)
+}
+
+// https://platform.openai.com/docs/api-reference/audio/createTranslation
+func TranslationEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx) error {
+	return audioEndpoint(cm, o, true)
+}
+
+// audioEndpoint transcribes the uploaded audio, translating it to English if translate is set
+func audioEndpoint(cm *config.ConfigLoader, o *options.Option, translate bool) func(c *fiber.Ctx) error {
 	return func(c *fiber.Ctx) error {
-		m, input, err := readRequest(c, o, false)
+		m, input, err := readRequest(c, cm, o, false)
 		if err != nil {
 			return fmt.Errorf("failed reading parameters from request:%w", err)
 		}
@@ -28,44 +51,306 @@ func TranscriptEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fibe
 		if err != nil {
 			return fmt.Errorf("failed reading parameters from request:%w", err)
 		}
-		// retrieve the file data from the request
-		file, err := c.FormFile("file")
+		format, words, err := transcriptionFormat(c, input.Stream)
 		if err != nil {
 			return err
 		}
-		f, err := file.Open()
+		diarizer, diarize, err := diarization(c, cm, o, *config)
 		if err != nil {
 			return err
 		}
-		defer f.Close()
-
-		dir, err := os.MkdirTemp("", "whisper")
-
+		vadModel, vad, padding, err := vadFilter(c, cm, o, *config)
 		if err != nil {
 			return err
 		}
-		defer os.RemoveAll(dir)
-
-		dst := filepath.Join(dir, path.Base(file.Filename))
-		dstFile, err := os.Create(dst)
+		dir, err := o.Workspace.MkdirTemp("whisper")
 		if err != nil {
 			return err
 		}
+		// the streamed transcriptions remove the directory once sent
+		streamed := false
+		defer func() {
+			if !streamed {
+				os.RemoveAll(dir)
+			}
+		}()
 
-		if _, err := io.Copy(dstFile, f); err != nil {
-			log.Debug().Msgf("Audio file copying error %+v - %+v - err %+v", file.Filename, dst, err)
+		dst, err := saveAudio(input.Context, c, dir, o)
+		if err != nil {
 			return err
 		}
 
-		log.Debug().Msgf("Audio file copied to: %+v", dst)
+		// with a VAD model, only the speech is transcribed, and the timestamps are moved back to the ones of the audio
+		var timeline realtime.Timeline
+		if vad != nil {
+			speech := dst + ".speech.wav"
+			if timeline, err = backend.KeepSpeech(input.Context, dst, speech, vad, padding, o.Loader, *vadModel, o); err != nil {
+				return fmt.Errorf("failed to detect the speech of the audio: %w", err)
+			}
+			dst = speech
+		}
 
-		tr, err := backend.ModelTranscription(dst, input.Language, o.Loader, *config, o)
-		if err != nil {
-			return err
+		if input.Stream {
+			streamed = true
+			streamTranscription(c, dst, dir, input, translate, words, *config, diarizer, diarize, timeline, o)
+			return nil
+		}
+
+		// an audio without speech is not transcribed
+		tr := &schema.Result{Segments: []schema.Segment{}}
+		if vad == nil || len(timeline) > 0 {
+			if tr, err = backend.ModelTranscription(dst, input.Language, translate, words, o.Loader, *config, o); err != nil {
+				if errors.Is(err, backend.ErrNoTranslation) {
+					return fiber.NewError(fiber.StatusBadRequest, err.Error())
+				}
+				return err
+			}
+			if diarize != nil {
+				turns, err := backend.ModelDiarization(input.Context, dst, diarize, o.Loader, *diarizer, o)
+				if err != nil {
+					return fmt.Errorf("failed to diarize the audio: %w", err)
+				}
+				backend.LabelSpeakers(tr.Segments, turns)
+			}
+			backend.RestoreTimestamps(tr, timeline)
+		}
+		if r := fiberContext.RequestFromCtx(c); r != nil {
+			r.AddAudio(tr.Duration().Seconds())
 		}
 
 		log.Debug().Msgf("Trascribed: %+v", tr)
-		// TODO: handle different outputs here
+		switch format {
+		case "text":
+			c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+			return c.Status(http.StatusOK).SendString(strings.TrimSpace(tr.Text))
+		case "srt":
+			c.Set(fiber.HeaderContentType, "application/x-subrip; charset=utf-8")
+			return c.Status(http.StatusOK).SendString(subtitles.SRT(transcriptionCues(tr)))
+		case "vtt":
+			c.Set(fiber.HeaderContentType, "text/vtt; charset=utf-8")
+			return c.Status(http.StatusOK).SendString(subtitles.VTT(transcriptionCues(tr)))
+		}
 		return c.Status(http.StatusOK).JSON(tr)
 	}
 }
+
+// saveAudio writes the uploaded audio in the directory, converted to the 16kHz WAV the backends get whatever the
+// format of the upload (e.g. the webm of the browsers), and returns its path
+func saveAudio(ctx context.Context, c *fiber.Ctx, dir string, o *options.Option) (string, error) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return "", err
+	}
+	f, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	dst := filepath.Join(dir, path.Base(file.Filename))
+	dstFile, err := os.Create(dst)
+	if err != nil {
+		return "", err
+	}
+	defer dstFile.Close()
+
+	if _, err := io.Copy(dstFile, f); err != nil {
+		log.Debug().Msgf("Audio file copying error %+v - %+v - err %+v", file.Filename, dst, err)
+		return "", err
+	}
+
+	log.Debug().Msgf("Audio file copied to: %+v", dst)
+
+	converted := dst + ".16k.wav"
+	if err := realtime.TranscodeWAV(ctx, o.FFmpegPath(), dst, converted, backend.TranscriptionRate); err != nil {
+		if errors.Is(err, realtime.ErrUnsupportedAudio) {
+			return "", fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		return "", err
+	}
+	return converted, nil
+}
+
+// transcriptionFormat returns the response_format of the transcription, and whether the timestamps of the words are
+// requested with timestamp_granularities. The text and the subtitles formats can't be streamed.
+func transcriptionFormat(c *fiber.Ctx, stream bool) (string, bool, error) {
+	format := c.FormValue("response_format", "json")
+	switch format {
+	case "json", "verbose_json":
+	case "text", "srt", "vtt":
+		if stream {
+			return "", false, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("the response_format %s can't be streamed", format))
+		}
+	default:
+		return "", false, fiber.NewError(fiber.StatusBadRequest, "invalid response_format: "+format)
+	}
+
+	words := false
+	if form, err := c.MultipartForm(); err == nil {
+		for _, g := range append(form.Value["timestamp_granularities[]"], form.Value["timestamp_granularities"]...) {
+			switch g {
+			case "word":
+				words = true
+			case "segment":
+			default:
+				return "", false, fiber.NewError(fiber.StatusBadRequest, "invalid timestamp_granularities: "+g)
+			}
+		}
+	}
+	return format, words, nil
+}
+
+// transcriptionCues returns the subtitles of the segments of the transcription
+func transcriptionCues(tr *schema.Result) []subtitles.Cue {
+	cues := make([]subtitles.Cue, 0, len(tr.Segments))
+	for _, s := range tr.Segments {
+		cues = append(cues, subtitles.Cue{Start: s.Start, End: s.End, Text: s.Text, Speaker: s.Speaker})
+	}
+	return cues
+}
+
+// the length of the chunks of the streamed transcriptions, the window of whisper
+const transcriptionChunk = 30 * time.Second
+
+// streamTranscription transcribes the audio in chunks, and sends the segments of each chunk as a server-sent event as
+// soon as it is transcribed, then the whole text. The directory of the audio is removed once done.
+func streamTranscription(c *fiber.Ctx, audio, dir string, input *schema.OpenAIRequest, translate, words bool, config config.Config, diarizer *config.Config, diarize *pb.DiarizeRequest, timeline realtime.Timeline, o *options.Option) {
+	c.Context().SetContentType("text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("Transfer-Encoding", "chunked")
+
+	r := fiberContext.RequestFromCtx(c)
+	c.Context().SetBodyStreamWriter(fasthttp.StreamWriter(func(w *bufio.Writer) {
+		defer os.RemoveAll(dir)
+		defer input.Cancel()
+		logger := fiberContext.Logger(input.Context)
+
+		send := func(ev schema.TranscriptionEvent) error {
+			dat, err := json.Marshal(ev)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", dat); err != nil {
+				return err
+			}
+			return w.Flush()
+		}
+
+		// the speakers are found in the whole audio beforehand, to keep their labels across the chunks
+		var turns []*pb.SpeakerTurn
+		if diarize != nil {
+			var err error
+			if turns, err = backend.ModelDiarization(input.Context, audio, diarize, o.Loader, *diarizer, o); err != nil {
+				logger.Debug().Msgf("Diarizing the audio failed: %v", err)
+				send(schema.TranscriptionEvent{Type: schema.TranscriptionError, Error: fmt.Sprintf("failed to diarize the audio: %v", err)})
+				return
+			}
+		}
+
+		text := strings.Builder{}
+		var duration time.Duration
+		var language string
+		var languageProbability float32
+		err := backend.ModelTranscriptionStream(input.Context, audio, dir, input.Language, translate, words, transcriptionChunk, o.Loader, config, o, func(tr *schema.Result) error {
+			backend.LabelSpeakers(tr.Segments, turns)
+			backend.RestoreTimestamps(tr, timeline)
+			if d := tr.Duration(); d > 0 {
+				if r != nil {
+					r.AddAudio((d - duration).Seconds())
+				}
+				duration = d
+			}
+			text.WriteString(tr.Text)
+			if language == "" {
+				language, languageProbability = tr.Language, tr.LanguageProbability
+			}
+			// when the client disconnected, the rest of the audio is not transcribed
+			return send(schema.TranscriptionEvent{Type: schema.TranscriptionDelta, Delta: tr.Text, Segments: tr.Segments, Words: tr.Words, Language: tr.Language, LanguageProbability: tr.LanguageProbability})
+		})
+		if err != nil {
+			logger.Debug().Msgf("Streaming the transcription failed: %v", err)
+			send(schema.TranscriptionEvent{Type: schema.TranscriptionError, Error: err.Error()})
+			return
+		}
+		send(schema.TranscriptionEvent{Type: schema.TranscriptionDone, Text: text.String(), Language: language, LanguageProbability: languageProbability})
+		w.WriteString("data: [DONE]\n\n")
+		w.Flush()
+	}))
+}
+
+// diarization returns the diarization model of the transcription and the request of the speakers, nil when the
+// transcription is not diarized. It is requested with diarize, and the number of speakers can be given with
+// num_speakers, or their range with min_speakers and max_speakers.
+func diarization(c *fiber.Ctx, cm *config.ConfigLoader, o *options.Option, cfg config.Config) (*config.Config, *pb.DiarizeRequest, error) {
+	diarize := cfg.Diarization.Default
+	if v := c.FormValue("diarize"); v != "" {
+		var err error
+		if diarize, err = strconv.ParseBool(v); err != nil {
+			return nil, nil, fiber.NewError(fiber.StatusBadRequest, "invalid diarize: "+v)
+		}
+	}
+	if !diarize {
+		return nil, nil, nil
+	}
+	if cfg.Diarization.Model == "" {
+		return nil, nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("the model %s has no diarization model", cfg.Name))
+	}
+
+	req := &pb.DiarizeRequest{}
+	for field, dst := range map[string]*int32{"num_speakers": &req.NumSpeakers, "min_speakers": &req.MinSpeakers, "max_speakers": &req.MaxSpeakers} {
+		if v := c.FormValue(field); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return nil, nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("invalid %s: %s", field, v))
+			}
+			*dst = int32(n)
+		}
+	}
+	if req.MinSpeakers != 0 && req.MaxSpeakers != 0 && req.MinSpeakers > req.MaxSpeakers {
+		return nil, nil, fiber.NewError(fiber.StatusBadRequest, "min_speakers is greater than max_speakers")
+	}
+
+	diarizer, err := config.Load(cfg.Diarization.Model, o.Loader.ModelPath, cm, o.Debug, o.Threads, o.ContextSize, o.F16)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed reading the configuration of the diarization model %q: %w", cfg.Diarization.Model, err)
+	}
+	return diarizer, req, nil
+}
+
+// the audio kept around the speech by default, when the silences are removed before the transcriptions
+const vadPadding = 200 * time.Millisecond
+
+// vadFilter returns the VAD model of the transcription, the request of the speech and the padding of the speech, nil
+// when the silences of the audio are not removed. It is requested with vad, and the settings of the VAD model can be
+// overridden as in /v1/audio/vad.
+func vadFilter(c *fiber.Ctx, cm *config.ConfigLoader, o *options.Option, cfg config.Config) (*config.Config, *pb.VADRequest, time.Duration, error) {
+	filter := cfg.VAD.Default
+	if v := c.FormValue("vad"); v != "" {
+		var err error
+		if filter, err = strconv.ParseBool(v); err != nil {
+			return nil, nil, 0, fiber.NewError(fiber.StatusBadRequest, "invalid vad: "+v)
+		}
+	}
+	if !filter {
+		return nil, nil, 0, nil
+	}
+	if cfg.VAD.Model == "" {
+		return nil, nil, 0, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("the model %s has no VAD model", cfg.Name))
+	}
+
+	req, err := vadOptions(c, cfg.VAD)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	padding := vadPadding
+	if cfg.VAD.PaddingMs > 0 {
+		padding = time.Duration(cfg.VAD.PaddingMs) * time.Millisecond
+	}
+
+	vadModel, err := config.Load(cfg.VAD.Model, o.Loader.ModelPath, cm, o.Debug, o.Threads, o.ContextSize, o.F16)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed reading the configuration of the VAD model %q: %w", cfg.VAD.Model, err)
+	}
+	return vadModel, req, padding, nil
+}
@@ -0,0 +1,97 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-skynet/LocalAI/api/backend"
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/api/schema"
+	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// UpscaleEndpoint upscales an image with an upscaling model. The image is uploaded, or given as a URL (e.g. of a
+// generated image) or base64 encoded.
+func UpscaleEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		m, input, err := readRequest(c, cm, o, false)
+		if err != nil {
+			return fmt.Errorf("failed reading parameters from request:%w", err)
+		}
+
+		config, input, err := mergeRequestWithConfig(m, input, cm, o.Loader, o.Debug, o.Threads, 0, false)
+		if err != nil {
+			return fmt.Errorf("failed reading parameters from request:%w", err)
+		}
+		req, err := upscaleOptions(c, config.Upscale)
+		if err != nil {
+			return err
+		}
+
+		var src string
+		if _, err := c.FormFile("image"); err == nil {
+			src, err = saveFormFile(c, "image", o)
+			if err != nil {
+				return err
+			}
+		} else if image := c.FormValue("image"); image != "" {
+			if src, err = requestImage(o, image); err != nil {
+				return fiber.NewError(fiber.StatusBadRequest, "invalid image: "+err.Error())
+			}
+		} else {
+			return fiber.NewError(fiber.StatusBadRequest, "the image to upscale is required")
+		}
+		defer os.RemoveAll(src)
+
+		b64JSON := c.FormValue("response_format") == "b64_json"
+		output, err := imageOutput(o, b64JSON)
+		if err != nil {
+			return err
+		}
+		req.Src, req.Dst = src, output
+		if err := backend.ModelUpscale(input.Context, req, o.Loader, *config, o); err != nil {
+			os.RemoveAll(output)
+			if errors.Is(err, backend.ErrNoUpscaling) {
+				return fiber.NewError(fiber.StatusBadRequest, err.Error())
+			}
+			return err
+		}
+
+		item, err := imageItem(c.BaseURL(), output, b64JSON)
+		if err != nil {
+			return err
+		}
+		return c.JSON(&schema.OpenAIResponse{
+			ID:      uuid.New().String(),
+			Created: int(time.Now().Unix()),
+			Data:    []schema.Item{*item},
+		})
+	}
+}
+
+// upscaleOptions returns the request of the upscaling of an image with the settings of the model, which can be
+// overridden with scale and tile
+func upscaleOptions(c *fiber.Ctx, u config.Upscale) (*pb.UpscaleRequest, error) {
+	req := &pb.UpscaleRequest{Scale: u.Scale, Tile: int32(u.Tile)}
+	if s := c.FormValue("scale"); s != "" {
+		f, err := strconv.ParseFloat(s, 32)
+		if err != nil || f <= 0 || f > 16 {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "invalid scale: "+s)
+		}
+		req.Scale = float32(f)
+	}
+	if s := c.FormValue("tile"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "invalid tile: "+s)
+		}
+		req.Tile = int32(n)
+	}
+	return req, nil
+}
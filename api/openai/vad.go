@@ -0,0 +1,93 @@
+package openai
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-skynet/LocalAI/api/backend"
+	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/api/schema"
+	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
+	"github.com/go-skynet/LocalAI/pkg/realtime"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// VADEndpoint returns the parts of the uploaded audio with speech, found by the VAD model
+func VADEndpoint(cm *config.ConfigLoader, o *options.Option) func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		m, input, err := readRequest(c, cm, o, false)
+		if err != nil {
+			return fmt.Errorf("failed reading parameters from request:%w", err)
+		}
+
+		config, input, err := mergeRequestWithConfig(m, input, cm, o.Loader, o.Debug, o.Threads, o.ContextSize, o.F16)
+		if err != nil {
+			return fmt.Errorf("failed reading parameters from request:%w", err)
+		}
+		req, err := vadOptions(c, config.VAD)
+		if err != nil {
+			return err
+		}
+
+		dir, err := o.Workspace.MkdirTemp("vad")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+
+		dst, err := saveAudio(input.Context, c, dir, o)
+		if err != nil {
+			return err
+		}
+		dat, err := os.ReadFile(dst)
+		if err != nil {
+			return err
+		}
+		samples, rate, err := realtime.ReadWAV(dat)
+		if err != nil {
+			return err
+		}
+
+		spans, err := backend.ModelVAD(input.Context, dst, req, o.Loader, *config, o)
+		if err != nil {
+			return err
+		}
+		res := schema.VADResponse{
+			Segments: []schema.VADSegment{},
+			Duration: time.Duration(int64(len(samples)) * int64(time.Second) / int64(rate)),
+		}
+		for _, s := range spans {
+			res.Segments = append(res.Segments, schema.VADSegment{Start: s.Start, End: s.End})
+			res.Speech += s.End - s.Start
+		}
+		return c.Status(http.StatusOK).JSON(res)
+	}
+}
+
+// vadOptions returns the request of the speech of an audio with the settings of the VAD, which can be overridden
+// with threshold, min_silence_ms and min_speech_ms
+func vadOptions(c *fiber.Ctx, v config.VAD) (*pb.VADRequest, error) {
+	req := &pb.VADRequest{Threshold: v.Threshold, MinSilenceMs: int32(v.MinSilenceMs), MinSpeechMs: int32(v.MinSpeechMs)}
+	if s := c.FormValue("threshold"); s != "" {
+		f, err := strconv.ParseFloat(s, 32)
+		if err != nil || f < 0 || f > 1 {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "invalid threshold: "+s)
+		}
+		req.Threshold = float32(f)
+	}
+	for field, dst := range map[string]*int32{"min_silence_ms": &req.MinSilenceMs, "min_speech_ms": &req.MinSpeechMs} {
+		if s := c.FormValue(field); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 0 {
+				return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("invalid %s: %s", field, s))
+			}
+			*dst = int32(n)
+		}
+	}
+	return req, nil
+}
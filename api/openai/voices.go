@@ -0,0 +1,233 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	config "github.com/go-skynet/LocalAI/api/config"
+	fiberContext "github.com/go-skynet/LocalAI/api/ctx"
+	"github.com/go-skynet/LocalAI/api/options"
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// the TTS backends cloning a voice from a reference sample of the speaker
+var cloningBackends = map[string]bool{
+	"coqui": true,
+}
+
+// storedVoice is a cloned voice with the caller which cloned it
+type storedVoice struct {
+	schema.Voice
+	Owner  string `json:"owner"`
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// VoicesService clones the voices of the speakers from the reference samples uploaded with the Files API, and
+// restricts each voice to the callers allowed by its access
+type VoicesService struct {
+	sync.Mutex
+	file   string
+	cm     *config.ConfigLoader
+	o      *options.Option
+	files  *FilesService
+	voices map[string]*storedVoice
+	// the caller of a request, and its tenant
+	caller func(c *fiber.Ctx) string
+	tenant func(c *fiber.Ctx) string
+}
+
+// NewVoicesService persists the voices in dir, the caller and the tenant identifying the owners of the voices
+func NewVoicesService(dir string, cm *config.ConfigLoader, o *options.Option, files *FilesService, caller, tenant func(c *fiber.Ctx) string) *VoicesService {
+	s := &VoicesService{
+		file:   filepath.Join(dir, "voices.json"),
+		cm:     cm,
+		o:      o,
+		files:  files,
+		voices: map[string]*storedVoice{},
+		caller: caller,
+		tenant: tenant,
+	}
+
+	dat, err := os.ReadFile(s.file)
+	if err == nil {
+		err = json.Unmarshal(dat, &s.voices)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		log.Error().Msgf("failed reading the voices from %s: %s", s.file, err.Error())
+	}
+	return s
+}
+
+// save persists the voices, it must be called with the lock held
+func (s *VoicesService) save() error {
+	dat, err := json.Marshal(s.voices)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.file, dat, 0600)
+}
+
+// allowed returns true if the caller of the request can use the voice
+func (s *VoicesService) allowed(c *fiber.Ctx, v *storedVoice) bool {
+	switch v.Access {
+	case schema.VoicePublic:
+		return true
+	case schema.VoiceTenant:
+		if v.Tenant != "" && s.tenant(c) == v.Tenant {
+			return true
+		}
+	}
+	return s.caller(c) == v.Owner
+}
+
+// get returns the voice with the id, if the caller of the request can use it
+func (s *VoicesService) get(c *fiber.Ctx, id string) (*storedVoice, error) {
+	s.Lock()
+	defer s.Unlock()
+	v, ok := s.voices[id]
+	if !ok || !s.allowed(c, v) {
+		return nil, fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("voice %s not found", id))
+	}
+	return v, nil
+}
+
+// Resolve returns the TTS model of the cloned voice with the id, and its voice speaking like the reference sample
+func (s *VoicesService) Resolve(c *fiber.Ctx, id string) (*config.Config, config.Voice, error) {
+	v, err := s.get(c, id)
+	if err != nil {
+		return nil, config.Voice{}, err
+	}
+	_, sample, err := s.files.get(v.FileID)
+	if err != nil {
+		return nil, config.Voice{}, fmt.Errorf("the sample of the voice %s is missing: %w", id, err)
+	}
+	cfg, err := config.Load(v.Model, s.o.Loader.ModelPath, s.cm, false, 0, 0, false)
+	if err != nil {
+		return nil, config.Voice{}, fmt.Errorf("failed reading the configuration of the model %s of the voice %s: %w", v.Model, id, err)
+	}
+	voice := config.Voice{Backend: cfg.Backend, Model: cfg.Model, Speaker: sample, Language: v.Language, SSML: cfg.SSML}
+	return cfg, voice, nil
+}
+
+func (s *VoicesService) CreateVoiceEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		req := new(schema.VoiceRequest)
+		if err := c.BodyParser(req); err != nil {
+			return err
+		}
+		if req.Name == "" {
+			return fiber.NewError(fiber.StatusBadRequest, "the name of the voice is required")
+		}
+		if req.Access == "" {
+			req.Access = schema.VoicePrivate
+		}
+		switch req.Access {
+		case schema.VoicePrivate, schema.VoicePublic:
+		case schema.VoiceTenant:
+			if s.tenant(c) == "" {
+				return fiber.NewError(fiber.StatusBadRequest, "the voices of the callers without tenant can't have the tenant access")
+			}
+		default:
+			return fiber.NewError(fiber.StatusBadRequest, "invalid access: "+req.Access)
+		}
+
+		// the sample must be one of the files of the caller
+		f, _, err := s.files.Get(c, req.FileID)
+		if err != nil {
+			return err
+		}
+		if f.Purpose != "voice" {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("the file %s is not a voice sample", req.FileID))
+		}
+
+		modelFile, err := fiberContext.ModelFromContext(c, s.cm, s.o.Loader, req.Model, false)
+		if err != nil {
+			return err
+		}
+		cfg, exists := s.cm.GetConfig(modelFile)
+		if !exists {
+			return fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("model %s not found", req.Model))
+		}
+		if !cloningBackends[cfg.Backend] {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("the backend %s of the model %s can't clone voices", cfg.Backend, req.Model))
+		}
+
+		v := &storedVoice{
+			Voice: schema.Voice{
+				ID:        newID("voice-"),
+				Object:    "audio.voice",
+				CreatedAt: time.Now().Unix(),
+				Name:      req.Name,
+				Model:     modelFile,
+				FileID:    req.FileID,
+				Language:  req.Language,
+				Access:    req.Access,
+			},
+			Owner:  s.caller(c),
+			Tenant: s.tenant(c),
+		}
+
+		s.Lock()
+		defer s.Unlock()
+		s.voices[v.ID] = v
+		if err := s.save(); err != nil {
+			delete(s.voices, v.ID)
+			return err
+		}
+		return c.JSON(v.Voice)
+	}
+}
+
+func (s *VoicesService) ListVoicesEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		s.Lock()
+		voices := []schema.Voice{}
+		for _, v := range s.voices {
+			if s.allowed(c, v) {
+				voices = append(voices, v.Voice)
+			}
+		}
+		s.Unlock()
+
+		return list(c, voices, func(v schema.Voice) string { return v.ID }, func(v schema.Voice) int64 { return v.CreatedAt })
+	}
+}
+
+func (s *VoicesService) GetVoiceEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		v, err := s.get(c, c.Params("voice_id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(v.Voice)
+	}
+}
+
+// DeleteVoiceEndpoint deletes a voice of the caller, the callers allowed to use the voice of another caller can't
+// delete it. The sample of the voice is kept in the files.
+func (s *VoicesService) DeleteVoiceEndpoint() func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		id := c.Params("voice_id")
+
+		s.Lock()
+		defer s.Unlock()
+		v, ok := s.voices[id]
+		if ok && s.allowed(c, v) && s.caller(c) != v.Owner {
+			return fiber.NewError(fiber.StatusForbidden, fmt.Sprintf("the voice %s was cloned by another caller", id))
+		}
+		ok = ok && s.caller(c) == v.Owner
+		if ok {
+			delete(s.voices, id)
+			if err := s.save(); err != nil {
+				return err
+			}
+		}
+		return c.JSON(schema.DeletionStatus{ID: id, Object: "audio.voice", Deleted: ok})
+	}
+}
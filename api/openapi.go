@@ -0,0 +1,49 @@
+package api
+
+import (
+	"sync"
+
+	"github.com/go-skynet/LocalAI/api/localai"
+	"github.com/go-skynet/LocalAI/api/openai"
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/go-skynet/LocalAI/internal"
+	"github.com/go-skynet/LocalAI/pkg/openapi"
+	"github.com/gofiber/fiber/v2"
+)
+
+// openAPIEndpoint serves the OpenAPI document of the routes registered in app,
+// generated on the first request as the routes depend on the options
+func openAPIEndpoint(app *fiber.App) func(c *fiber.Ctx) error {
+	var once sync.Once
+	var document *openapi.Document
+
+	return func(c *fiber.Ctx) error {
+		once.Do(func() {
+			spec := openapi.New(openapi.Info{
+				Title:       "LocalAI",
+				Description: "OpenAI compatible API, with the LocalAI extensions (marked with x-localai-extension)",
+				Version:     internal.PrintableVersion(),
+			})
+			spec.SetErrorResponse(schema.ErrorResponse{})
+			spec.Add(openai.OpenAPIOperations()...)
+			spec.Add(localai.OpenAPIOperations()...)
+			spec.Add(
+				openapi.Operation{Method: "GET", Path: "/version", Summary: "Get the version of LocalAI", Tag: "Monitoring", Extension: true,
+					Response: struct {
+						Version string `json:"version"`
+					}{}},
+				openapi.Operation{Method: "GET", Path: "/healthz", Summary: "Liveness probe", Tag: "Monitoring", Public: true, Extension: true},
+				openapi.Operation{Method: "GET", Path: "/readyz", Summary: "Readiness probe", Tag: "Monitoring", Public: true, Extension: true},
+				openapi.Operation{Method: "GET", Path: "/metrics", Summary: "Prometheus metrics", Tag: "Monitoring", ResponseType: "text/plain", Public: true, Extension: true},
+				openapi.Operation{Method: "GET", Path: "/openapi.json", Summary: "Get the OpenAPI document of the API", Tag: "Monitoring", ResponseType: "application/json", Public: true, Extension: true},
+			)
+
+			routes := []openapi.Route{}
+			for _, r := range app.GetRoutes(true) {
+				routes = append(routes, openapi.Route{Method: r.Method, Path: r.Path})
+			}
+			document = spec.Document(routes)
+		})
+		return c.JSON(document)
+	}
+}
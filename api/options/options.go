@@ -4,41 +4,88 @@ import (
 	"context"
 	"embed"
 	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/go-skynet/LocalAI/metrics"
+	"github.com/go-skynet/LocalAI/pkg/apikeys"
+	"github.com/go-skynet/LocalAI/pkg/audit"
+	"github.com/go-skynet/LocalAI/pkg/contentfilter"
 	"github.com/go-skynet/LocalAI/pkg/gallery"
 	model "github.com/go-skynet/LocalAI/pkg/model"
+	"github.com/go-skynet/LocalAI/pkg/oidc"
+	"github.com/go-skynet/LocalAI/pkg/quota"
+	"github.com/go-skynet/LocalAI/pkg/sandbox"
+	"github.com/go-skynet/LocalAI/pkg/secrets"
+	"github.com/go-skynet/LocalAI/pkg/telemetry"
+	"github.com/go-skynet/LocalAI/pkg/usage"
+	"github.com/go-skynet/LocalAI/pkg/vectorstore"
+	"github.com/go-skynet/LocalAI/pkg/workspace"
 	"github.com/rs/zerolog/log"
 )
 
 type Option struct {
 	Context                             context.Context
 	ConfigFile                          string
+	ModelRoutesFile                     string
 	Loader                              *model.ModelLoader
 	UploadLimitMB, Threads, ContextSize int
 	F16                                 bool
 	Debug, DisableMessage               bool
 	ImageDir                            string
 	AudioDir                            string
+	AssistantsDir                       string
+	FilesDir                            string
+	FilesQuotaMB                        int
+	BatchWorkers                        int
+	BackendIsolation                    bool
+	BackendSandbox                      sandbox.Spec
 	CORS                                bool
 	PreloadJSONModels                   string
 	PreloadModelsFromPath               string
+	BootstrapFile                       string
+	TraceDir                            string
+	CrashDir                            string
 	CORSAllowOrigins                    string
 	ApiKeys                             []string
+	AdminKey                            string
+	ApiKeyStore                         *apikeys.Store
+	OIDC                                *oidc.Verifier
+	TenantNamespaces                    bool
+	TenantSharedModels                  bool
+	Secrets                             *secrets.Store
 	Metrics                             *metrics.Metrics
+	Telemetry                           *telemetry.Exporter
+	AuditLog                            *audit.Logger
+	Usage                               *usage.Store
+	Quotas                              *quota.Enforcer
+	ContentFilters                      *contentfilter.Registry
+	Workspace                           *workspace.Workspace
+	VectorStore                         *vectorstore.Store
 
 	ModelLibraryURL string
 
 	Galleries []gallery.Gallery
+	// GalleriesRefreshInterval is how often the galleries are checked for updates of the installed models
+	GalleriesRefreshInterval time.Duration
+	// ConfigWatchInterval is how often the config files of the models path are checked for changes
+	ConfigWatchInterval time.Duration
 
 	BackendAssets     embed.FS
 	AssetsDestination string
+	// FFmpeg is the ffmpeg binary decoding the audio, see FFmpegPath
+	FFmpeg string
 
 	ExternalGRPCBackends map[string]string
 
 	AutoloadGalleries bool
 
+	ConvertLegacyGGML bool
+	KeepLegacyGGML    bool
+
 	SingleBackend           bool
 	ParallelBackendRequests bool
 
@@ -49,6 +96,79 @@ type Option struct {
 	ModelsURL []string
 
 	WatchDogBusyTimeout, WatchDogIdleTimeout time.Duration
+
+	// mu guards ApiKeys and Galleries, which the bootstrap and the config API change while serving the requests
+	mu sync.RWMutex
+}
+
+// GetApiKeys returns a copy of the static API keys
+func (o *Option) GetApiKeys() []string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return slices.Clone(o.ApiKeys)
+}
+
+// HasApiKey returns whether the key is one of the static API keys
+func (o *Option) HasApiKey(key string) bool {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return slices.Contains(o.ApiKeys, key)
+}
+
+// AddApiKeys adds the static API keys which are missing
+func (o *Option) AddApiKeys(keys ...string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, k := range keys {
+		if !slices.Contains(o.ApiKeys, k) {
+			o.ApiKeys = append(o.ApiKeys, k)
+		}
+	}
+}
+
+// SetApiKeys replaces the static API keys
+func (o *Option) SetApiKeys(keys []string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.ApiKeys = slices.Clone(keys)
+}
+
+// GetGalleries returns a copy of the galleries
+func (o *Option) GetGalleries() []gallery.Gallery {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return slices.Clone(o.Galleries)
+}
+
+// AddGallery adds the gallery, unless one with the same name exists, and returns whether it was added
+func (o *Option) AddGallery(g gallery.Gallery) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if slices.ContainsFunc(o.Galleries, func(gallery gallery.Gallery) bool {
+		return gallery.Name == g.Name
+	}) {
+		return false
+	}
+	o.Galleries = append(o.Galleries, g)
+	return true
+}
+
+// RemoveGallery removes the gallery with the name, and returns whether it existed
+func (o *Option) RemoveGallery(name string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	n := len(o.Galleries)
+	o.Galleries = slices.DeleteFunc(o.Galleries, func(gallery gallery.Gallery) bool {
+		return gallery.Name == name
+	})
+	return len(o.Galleries) != n
+}
+
+// SetGalleries replaces the galleries
+func (o *Option) SetGalleries(galleries []gallery.Gallery) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.Galleries = slices.Clone(galleries)
 }
 
 type AppOption func(*Option)
@@ -61,6 +181,7 @@ func NewOptions(o ...AppOption) *Option {
 		ContextSize:    512,
 		Debug:          true,
 		DisableMessage: true,
+		ContentFilters: contentfilter.NewRegistry(),
 	}
 	for _, oo := range o {
 		oo(opt)
@@ -124,6 +245,13 @@ var EnableGalleriesAutoload = func(o *Option) {
 	o.AutoloadGalleries = true
 }
 
+func WithLegacyGGMLConversion(convert, keepOriginal bool) AppOption {
+	return func(o *Option) {
+		o.ConvertLegacyGGML = convert
+		o.KeepLegacyGGML = keepOriginal
+	}
+}
+
 func WithExternalBackend(name string, uri string) AppOption {
 	return func(o *Option) {
 		if o.ExternalGRPCBackends == nil {
@@ -145,6 +273,13 @@ func WithBackendAssetsOutput(out string) AppOption {
 	}
 }
 
+// WithFFmpeg sets the ffmpeg binary decoding the audio
+func WithFFmpeg(path string) AppOption {
+	return func(o *Option) {
+		o.FFmpeg = path
+	}
+}
+
 func WithBackendAssets(f embed.FS) AppOption {
 	return func(o *Option) {
 		o.BackendAssets = f
@@ -166,6 +301,20 @@ func WithStringGalleries(galls string) AppOption {
 	}
 }
 
+// WithGalleriesRefreshInterval sets how often the galleries are checked for updates of the installed models, 0 disables it
+func WithGalleriesRefreshInterval(interval time.Duration) AppOption {
+	return func(o *Option) {
+		o.GalleriesRefreshInterval = interval
+	}
+}
+
+// WithConfigWatchInterval sets how often the config files of the models path are checked for changes, 0 disables it
+func WithConfigWatchInterval(interval time.Duration) AppOption {
+	return func(o *Option) {
+		o.ConfigWatchInterval = interval
+	}
+}
+
 func WithGalleries(galleries []gallery.Gallery) AppOption {
 	return func(o *Option) {
 		o.Galleries = append(o.Galleries, galleries...)
@@ -184,6 +333,26 @@ func WithYAMLConfigPreload(configFile string) AppOption {
 	}
 }
 
+func WithBootstrapFile(file string) AppOption {
+	return func(o *Option) {
+		o.BootstrapFile = file
+	}
+}
+
+// WithTraceDir enables the recording of the inferences flagged with the X-LocalAI-Trace header into dir
+func WithTraceDir(dir string) AppOption {
+	return func(o *Option) {
+		o.TraceDir = dir
+	}
+}
+
+// WithCrashDir writes the crashes of the backends into dir, in a directory per model
+func WithCrashDir(dir string) AppOption {
+	return func(o *Option) {
+		o.CrashDir = dir
+	}
+}
+
 func WithJSONStringPreload(configFile string) AppOption {
 	return func(o *Option) {
 		o.PreloadJSONModels = configFile
@@ -195,6 +364,13 @@ func WithConfigFile(configFile string) AppOption {
 	}
 }
 
+// WithModelRoutesFile sets the YAML file of rules routing the requested model names (e.g. gpt-4) to the configured models
+func WithModelRoutesFile(file string) AppOption {
+	return func(o *Option) {
+		o.ModelRoutesFile = file
+	}
+}
+
 func WithModelLoader(loader *model.ModelLoader) AppOption {
 	return func(o *Option) {
 		o.Loader = loader
@@ -243,6 +419,39 @@ func WithAudioDir(audioDir string) AppOption {
 	}
 }
 
+func WithAssistantsDir(assistantsDir string) AppOption {
+	return func(o *Option) {
+		o.AssistantsDir = assistantsDir
+	}
+}
+
+// WithFilesDir sets where the files uploaded with the Files API are stored, up to quotaMB megabytes in total (0 means no limit)
+func WithFilesDir(filesDir string, quotaMB int) AppOption {
+	return func(o *Option) {
+		o.FilesDir = filesDir
+		o.FilesQuotaMB = quotaMB
+	}
+}
+
+// EnableBackendIsolation restricts the filesystem view of the backends of all the models
+var EnableBackendIsolation = func(o *Option) {
+	o.BackendIsolation = true
+}
+
+// WithBackendSandbox runs the backends of all the models as another user, with Landlock or a seccomp profile
+func WithBackendSandbox(spec sandbox.Spec) AppOption {
+	return func(o *Option) {
+		o.BackendSandbox = spec
+	}
+}
+
+// WithBatchWorkers sets how many batches of the Batch API are executed at the same time
+func WithBatchWorkers(workers int) AppOption {
+	return func(o *Option) {
+		o.BatchWorkers = workers
+	}
+}
+
 func WithImageDir(imageDir string) AppOption {
 	return func(o *Option) {
 		o.ImageDir = imageDir
@@ -255,8 +464,125 @@ func WithApiKeys(apiKeys []string) AppOption {
 	}
 }
 
+// WithAdminKey enables the profiling and diagnostics endpoints, protected by the given key
+func WithAdminKey(key string) AppOption {
+	return func(o *Option) {
+		o.AdminKey = key
+	}
+}
+
+// WithApiKeyStore enables the API keys with scopes, managed by the admins
+func WithApiKeyStore(s *apikeys.Store) AppOption {
+	return func(o *Option) {
+		o.ApiKeyStore = s
+	}
+}
+
+// WithOIDC accepts the tokens of an OIDC issuer, along with the API keys
+func WithOIDC(v *oidc.Verifier) AppOption {
+	return func(o *Option) {
+		o.OIDC = v
+	}
+}
+
+// WithTenantNamespaces serves the callers of a tenant with the models of its directory of the models path only, and
+// with the shared models of the models path if shared is true. The other callers only see the shared models.
+func WithTenantNamespaces(shared bool) AppOption {
+	return func(o *Option) {
+		o.TenantNamespaces = true
+		o.TenantSharedModels = shared
+	}
+}
+
+// WithSecrets sets the secrets the model configs and the gallery files reference by name, to authenticate their
+// downloads
+func WithSecrets(s *secrets.Store) AppOption {
+	return func(o *Option) {
+		o.Secrets = s
+	}
+}
+
+func WithAuditLog(l *audit.Logger) AppOption {
+	return func(o *Option) {
+		o.AuditLog = l
+	}
+}
+
+// WithVectorStore serves the collections of vectors of the store
+func WithVectorStore(s *vectorstore.Store) AppOption {
+	return func(o *Option) {
+		o.VectorStore = s
+	}
+}
+
+// WithUsage accounts the usage of each API key into the store
+func WithUsage(s *usage.Store) AppOption {
+	return func(o *Option) {
+		o.Usage = s
+	}
+}
+
+// WithQuotas enforces the token quotas of the API keys, from the usage accounted
+func WithQuotas(e *quota.Enforcer) AppOption {
+	return func(o *Option) {
+		o.Quotas = e
+	}
+}
+
+// WithContentFilter registers a filter of the prompts and responses, which the models enable by name
+func WithContentFilter(name string, f contentfilter.Filter) AppOption {
+	return func(o *Option) {
+		o.ContentFilters.Register(name, f)
+	}
+}
+
 func WithMetrics(meter *metrics.Metrics) AppOption {
 	return func(o *Option) {
 		o.Metrics = meter
 	}
 }
+
+func WithTelemetry(exporter *telemetry.Exporter) AppOption {
+	return func(o *Option) {
+		o.Telemetry = exporter
+	}
+}
+
+// WithWorkspace sets where temporary artifacts are written, and cleaned up from
+func WithWorkspace(w *workspace.Workspace) AppOption {
+	return func(o *Option) {
+		o.Workspace = w
+	}
+}
+
+// FFmpegPath returns the ffmpeg binary decoding the audio: the one set with WithFFmpeg, else the one bundled with the
+// backend assets if any, else the one of the PATH
+func (o *Option) FFmpegPath() string {
+	if o.FFmpeg != "" {
+		return o.FFmpeg
+	}
+	bundled := filepath.Join(o.AssetsDestination, "backend-assets", "util", "ffmpeg")
+	if _, err := os.Stat(bundled); err == nil {
+		return bundled
+	}
+	return "ffmpeg"
+}
+
+// QuantizeToolPath returns the path of the llama.cpp quantize tool, which is shipped with the backend assets
+func (o *Option) QuantizeToolPath() string {
+	return filepath.Join(o.AssetsDestination, "backend-assets", "util", "quantize")
+}
+
+// GalleryInstallOptions returns the options used when installing models from galleries.
+// The GGUF converter is shipped with the backend assets.
+func (o *Option) GalleryInstallOptions() []gallery.InstallOption {
+	opts := []gallery.InstallOption{}
+	if o.ConvertLegacyGGML {
+		converter := filepath.Join(o.AssetsDestination, "backend-assets", "util", "convert-llama-ggml-to-gguf.py")
+		opts = append(opts, gallery.WithLegacyGGMLConversion(converter, o.KeepLegacyGGML))
+	}
+	if o.Secrets != nil {
+		opts = append(opts, gallery.WithSecrets(o.Secrets))
+	}
+	return opts
+}
@@ -0,0 +1,189 @@
+package schema
+
+import (
+	"github.com/go-skynet/LocalAI/pkg/grammar"
+)
+
+// Types of the OpenAI Assistants API, see https://platform.openai.com/docs/api-reference/assistants
+
+type AssistantTool struct {
+	// Only "function" tools are executed, other types are accepted and ignored
+	Type     string            `json:"type"`
+	Function *grammar.Function `json:"function,omitempty"`
+}
+
+type Assistant struct {
+	ID           string            `json:"id"`
+	Object       string            `json:"object"`
+	CreatedAt    int64             `json:"created_at"`
+	Name         string            `json:"name,omitempty"`
+	Description  string            `json:"description,omitempty"`
+	Model        string            `json:"model"`
+	Instructions string            `json:"instructions,omitempty"`
+	Tools        []AssistantTool   `json:"tools"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+}
+
+type Thread struct {
+	ID        string            `json:"id"`
+	Object    string            `json:"object"`
+	CreatedAt int64             `json:"created_at"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+type ThreadMessageText struct {
+	Value       string        `json:"value"`
+	Annotations []interface{} `json:"annotations"`
+}
+
+type ThreadMessageContent struct {
+	Type string            `json:"type"`
+	Text ThreadMessageText `json:"text"`
+}
+
+type ThreadMessage struct {
+	ID          string                 `json:"id"`
+	Object      string                 `json:"object"`
+	CreatedAt   int64                  `json:"created_at"`
+	ThreadID    string                 `json:"thread_id"`
+	Role        string                 `json:"role"`
+	Content     []ThreadMessageContent `json:"content"`
+	AssistantID string                 `json:"assistant_id,omitempty"`
+	RunID       string                 `json:"run_id,omitempty"`
+	// the files of the Files API attached to the message
+	FileIDs  []string          `json:"file_ids,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Text returns the text of the message
+func (m ThreadMessage) Text() string {
+	s := ""
+	for _, c := range m.Content {
+		s += c.Text.Value
+	}
+	return s
+}
+
+type ToolCallFunction struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+	// Output is set once the output of the tool was submitted
+	Output string `json:"output,omitempty"`
+}
+
+type ToolCall struct {
+	ID       string           `json:"id"`
+	Type     string           `json:"type"`
+	Function ToolCallFunction `json:"function"`
+}
+
+type SubmitToolOutputs struct {
+	ToolCalls []ToolCall `json:"tool_calls"`
+}
+
+type RequiredAction struct {
+	Type              string            `json:"type"`
+	SubmitToolOutputs SubmitToolOutputs `json:"submit_tool_outputs"`
+}
+
+type RunError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Statuses of a run
+const (
+	RunQueued         = "queued"
+	RunInProgress     = "in_progress"
+	RunRequiresAction = "requires_action"
+	RunCancelled      = "cancelled"
+	RunFailed         = "failed"
+	RunCompleted      = "completed"
+)
+
+type Run struct {
+	ID             string            `json:"id"`
+	Object         string            `json:"object"`
+	CreatedAt      int64             `json:"created_at"`
+	ThreadID       string            `json:"thread_id"`
+	AssistantID    string            `json:"assistant_id"`
+	Status         string            `json:"status"`
+	RequiredAction *RequiredAction   `json:"required_action"`
+	LastError      *RunError         `json:"last_error"`
+	StartedAt      int64             `json:"started_at,omitempty"`
+	CompletedAt    int64             `json:"completed_at,omitempty"`
+	CancelledAt    int64             `json:"cancelled_at,omitempty"`
+	FailedAt       int64             `json:"failed_at,omitempty"`
+	Model          string            `json:"model"`
+	Instructions   string            `json:"instructions"`
+	Tools          []AssistantTool   `json:"tools"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+}
+
+type AssistantRequest struct {
+	Name         *string           `json:"name"`
+	Description  *string           `json:"description"`
+	Model        *string           `json:"model"`
+	Instructions *string           `json:"instructions"`
+	Tools        []AssistantTool   `json:"tools"`
+	Metadata     map[string]string `json:"metadata"`
+}
+
+type ThreadMessageRequest struct {
+	Role     string            `json:"role"`
+	Content  string            `json:"content"`
+	FileIDs  []string          `json:"file_ids"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+type ThreadRequest struct {
+	Messages []ThreadMessageRequest `json:"messages"`
+	Metadata map[string]string      `json:"metadata"`
+}
+
+type RunRequest struct {
+	AssistantID  string            `json:"assistant_id"`
+	Model        string            `json:"model"`
+	Instructions string            `json:"instructions"`
+	Tools        []AssistantTool   `json:"tools"`
+	Metadata     map[string]string `json:"metadata"`
+	// only used when creating the thread and the run at once
+	Thread *ThreadRequest `json:"thread,omitempty"`
+}
+
+type ToolOutput struct {
+	ToolCallID string `json:"tool_call_id"`
+	Output     string `json:"output"`
+}
+
+type SubmitToolOutputsRequest struct {
+	ToolOutputs []ToolOutput `json:"tool_outputs"`
+}
+
+// ThreadExportVersion is the version of the format of the exported threads
+const ThreadExportVersion = 1
+
+// ThreadExport is a thread with its messages, in a portable format to archive it or import it on another instance
+type ThreadExport struct {
+	Version  int             `json:"version"`
+	Thread   Thread          `json:"thread"`
+	Messages []ThreadMessage `json:"messages"`
+	// the assistants which replied in the thread
+	Assistants []Assistant `json:"assistants,omitempty"`
+	// the files attached to the messages: only their references are exported, not their content
+	Files []File `json:"files,omitempty"`
+}
+
+type ListResponse struct {
+	Object  string      `json:"object"`
+	Data    interface{} `json:"data"`
+	FirstID string      `json:"first_id,omitempty"`
+	LastID  string      `json:"last_id,omitempty"`
+	HasMore bool        `json:"has_more"`
+}
+
+type DeletionStatus struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Deleted bool   `json:"deleted"`
+}
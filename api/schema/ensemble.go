@@ -0,0 +1,33 @@
+package schema
+
+import config "github.com/go-skynet/LocalAI/api/config"
+
+type EnsembleEmbeddingsRequest struct {
+	// Model is an ensemble model, or the models are given in the request
+	Model  string                 `json:"model"`
+	Models []config.EnsembleModel `json:"models"`
+	// Fusion overrides the fusion of the ensemble model: concat, weighted or none
+	Fusion string `json:"fusion"`
+	// A string or a list of strings
+	Input interface{} `json:"input"`
+}
+
+type EnsembleModelEmbedding struct {
+	Model     string    `json:"model"`
+	Embedding []float32 `json:"embedding"`
+}
+
+type EnsembleEmbedding struct {
+	Object string `json:"object"`
+	Index  int    `json:"index"`
+	// The fused embedding, unless the fusion is none
+	Embedding  []float32                `json:"embedding,omitempty"`
+	Embeddings []EnsembleModelEmbedding `json:"embeddings"`
+}
+
+type EnsembleEmbeddingsResponse struct {
+	Object string              `json:"object"`
+	Model  string              `json:"model,omitempty"`
+	Fusion string              `json:"fusion"`
+	Data   []EnsembleEmbedding `json:"data"`
+}
@@ -0,0 +1,23 @@
+package schema
+
+// the types of the events of the streamed image generations
+const (
+	ImageGenerationProgress  = "image_generation.progress"
+	ImageGenerationCompleted = "image_generation.completed"
+	ImageGenerationError     = "error"
+)
+
+// ImageEvent is an event of the streamed image generations: the progress of each image after each step of its
+// diffusion, when the backend reports it, then the generated images
+type ImageEvent struct {
+	Type string `json:"type"`
+	// Index of the image among the images of the request
+	Index int `json:"index"`
+	Step  int `json:"step,omitempty"`
+	Steps int `json:"steps,omitempty"`
+	// Preview is a PNG thumbnail of the image being generated, base64 encoded
+	Preview string `json:"preview,omitempty"`
+	Created int    `json:"created,omitempty"`
+	Data    []Item `json:"data,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
@@ -0,0 +1,28 @@
+package schema
+
+// ModerationCategories are the categories of the OpenAI moderation models
+var ModerationCategories = []string{
+	"sexual",
+	"sexual/minors",
+	"harassment",
+	"harassment/threatening",
+	"hate",
+	"hate/threatening",
+	"self-harm",
+	"self-harm/intent",
+	"self-harm/instructions",
+	"violence",
+	"violence/graphic",
+}
+
+type ModerationResult struct {
+	Flagged        bool               `json:"flagged"`
+	Categories     map[string]bool    `json:"categories"`
+	CategoryScores map[string]float64 `json:"category_scores"`
+}
+
+type ModerationResponse struct {
+	ID      string             `json:"id"`
+	Model   string             `json:"model"`
+	Results []ModerationResult `json:"results"`
+}
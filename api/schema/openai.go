@@ -34,6 +34,8 @@ type Item struct {
 	// Images
 	URL     string `json:"url,omitempty"`
 	B64JSON string `json:"b64_json,omitempty"`
+	// Seed of the generation of the image
+	Seed int `json:"seed,omitempty"`
 }
 
 type OpenAIResponse struct {
@@ -53,6 +55,34 @@ type Choice struct {
 	Message      *Message `json:"message,omitempty"`
 	Delta        *Message `json:"delta,omitempty"`
 	Text         string   `json:"text,omitempty"`
+
+	Logprobs *ChoiceLogprobs `json:"logprobs,omitempty"`
+}
+
+// ChoiceLogprobs are the log probabilities of the tokens of a choice: in Content for chat completions,
+// and in the legacy format of the other fields for completions.
+type ChoiceLogprobs struct {
+	Content []TokenLogprob `json:"content,omitempty"`
+
+	Tokens        []string             `json:"tokens,omitempty"`
+	TokenLogprobs []float32            `json:"token_logprobs,omitempty"`
+	TopLogprobs   []map[string]float32 `json:"top_logprobs,omitempty"`
+	// the offsets are in characters from the beginning of the generated text
+	TextOffset []int `json:"text_offset,omitempty"`
+}
+
+type TokenLogprob struct {
+	Token   string  `json:"token"`
+	Logprob float32 `json:"logprob"`
+	// the UTF-8 bytes of the token, as integers
+	Bytes       []int        `json:"bytes"`
+	TopLogprobs []TopLogprob `json:"top_logprobs"`
+}
+
+type TopLogprob struct {
+	Token   string  `json:"token"`
+	Logprob float32 `json:"logprob"`
+	Bytes   []int   `json:"bytes"`
 }
 
 type Content struct {
@@ -76,24 +106,70 @@ type Message struct {
 
 	// A result of a function call
 	FunctionCall interface{} `json:"function_call,omitempty" yaml:"function_call,omitempty"`
+
+	// The tools called by the assistant, and the call answered by a tool message
+	ToolCalls  []ChatToolCall `json:"tool_calls,omitempty" yaml:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty" yaml:"tool_call_id,omitempty"`
+}
+
+type ChatTool struct {
+	// Only "function" tools are supported
+	Type     string           `json:"type"`
+	Function grammar.Function `json:"function"`
+}
+
+type ChatToolCallFunction struct {
+	Name string `json:"name,omitempty"`
+	// The arguments as a JSON string, streamed in fragments
+	Arguments string `json:"arguments"`
+}
+
+// ChatToolCall is a tool call of the assistant. When streamed, the first delta of
+// a call has its ID, type and function name, and the next ones fragments of its arguments.
+type ChatToolCall struct {
+	Index    int                  `json:"index"`
+	ID       string               `json:"id,omitempty"`
+	Type     string               `json:"type,omitempty"`
+	Function ChatToolCallFunction `json:"function"`
 }
 
 type OpenAIModel struct {
 	ID     string `json:"id"`
 	Object string `json:"object"`
+
+	// LocalAI specific, only returned when requested with ?details=true
+	Details *OpenAIModelDetails `json:"details,omitempty"`
+}
+
+type OpenAIModelDetails struct {
+	Loaded       bool   `json:"loaded"`
+	Backend      string `json:"backend,omitempty"`
+	ContextSize  int    `json:"context_size,omitempty"`
+	Quantization string `json:"quantization,omitempty"`
+	// resident memory of the backend process, in bytes
+	Memory uint64 `json:"memory,omitempty"`
 }
 
 type ChatCompletionResponseFormatType string
 
 type ChatCompletionResponseFormat struct {
 	Type ChatCompletionResponseFormatType `json:"type,omitempty"`
+	// only with the json_schema type
+	JSONSchema *ChatCompletionJSONSchema `json:"json_schema,omitempty"`
+}
+
+type ChatCompletionJSONSchema struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Schema      map[string]interface{} `json:"schema"`
+	Strict      bool                   `json:"strict,omitempty"`
 }
 
 type OpenAIRequest struct {
 	config.PredictionOptions
 
-	Context context.Context
-	Cancel  context.CancelFunc
+	Context context.Context    `json:"-"`
+	Cancel  context.CancelFunc `json:"-"`
 
 	// whisper
 	File string `json:"file" validate:"required"`
@@ -101,6 +177,19 @@ type OpenAIRequest struct {
 	ResponseFormat ChatCompletionResponseFormat `json:"response_format"`
 	// image
 	Size string `json:"size"`
+	// Strength is how much the image of File is transformed by the image generation, from 0 (kept) to 1 (replaced)
+	Strength float32 `json:"strength"`
+	// ControlImage (a URL or base64 encoded) conditions the image generation with the ControlNet of ControlType
+	ControlImage string `json:"control_image"`
+	ControlType  string `json:"control_type"`
+	// Scheduler (or sampler) and CFGScale of the diffusion, the ones of the model by default
+	Scheduler string  `json:"scheduler"`
+	CFGScale  float32 `json:"cfg_scale"`
+	// Loras are the weights of the LoRA adapters of the model applied to the image generation by name, the weights
+	// of the model when 0
+	Loras map[string]float32 `json:"loras"`
+	// Preview sends thumbnails of the images being generated with the progress of the streamed image generations
+	Preview bool `json:"preview"`
 	// Prompt is read only by completion/image API calls
 	Prompt interface{} `json:"prompt" yaml:"prompt"`
 
@@ -117,8 +206,16 @@ type OpenAIRequest struct {
 	Functions    []grammar.Function `json:"functions" yaml:"functions"`
 	FunctionCall interface{}        `json:"function_call" yaml:"function_call"` // might be a string or an object
 
+	// Tools are the functions of the request, called with tool_calls instead of function_call
+	Tools      []ChatTool  `json:"tools" yaml:"tools"`
+	ToolChoice interface{} `json:"tool_choice" yaml:"tool_choice"` // might be a string or an object
+
 	Stream bool `json:"stream"`
 
+	// Logprobs is a boolean for chat completions, and the number of alternatives to return for completions
+	Logprobs    interface{} `json:"logprobs" yaml:"logprobs"`
+	TopLogprobs int         `json:"top_logprobs" yaml:"top_logprobs"`
+
 	// Image (not supported by OpenAI)
 	Mode int `json:"mode"`
 	Step int `json:"step"`
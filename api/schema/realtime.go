@@ -0,0 +1,147 @@
+package schema
+
+// Events of the Realtime API, sent by the client
+const (
+	RealtimeSessionUpdate          = "session.update"
+	RealtimeInputAudioBufferAppend = "input_audio_buffer.append"
+	RealtimeInputAudioBufferCommit = "input_audio_buffer.commit"
+	RealtimeInputAudioBufferClear  = "input_audio_buffer.clear"
+	RealtimeConversationItemCreate = "conversation.item.create"
+	RealtimeResponseCreate         = "response.create"
+	RealtimeResponseCancel         = "response.cancel"
+)
+
+// Events of the Realtime API, sent by the server
+const (
+	RealtimeError                        = "error"
+	RealtimeSessionCreated               = "session.created"
+	RealtimeSessionUpdated               = "session.updated"
+	RealtimeSpeechStarted                = "input_audio_buffer.speech_started"
+	RealtimeSpeechStopped                = "input_audio_buffer.speech_stopped"
+	RealtimeInputAudioBufferCommitted    = "input_audio_buffer.committed"
+	RealtimeInputAudioBufferCleared      = "input_audio_buffer.cleared"
+	RealtimeConversationItemCreated      = "conversation.item.created"
+	RealtimeTranscriptionCompleted       = "conversation.item.input_audio_transcription.completed"
+	RealtimeTranscriptionFailed          = "conversation.item.input_audio_transcription.failed"
+	RealtimeResponseCreated              = "response.created"
+	RealtimeResponseOutputItemAdded      = "response.output_item.added"
+	RealtimeResponseOutputItemDone       = "response.output_item.done"
+	RealtimeResponseContentPartAdded     = "response.content_part.added"
+	RealtimeResponseContentPartDone      = "response.content_part.done"
+	RealtimeResponseTextDelta            = "response.text.delta"
+	RealtimeResponseTextDone             = "response.text.done"
+	RealtimeResponseAudioTranscriptDelta = "response.audio_transcript.delta"
+	RealtimeResponseAudioTranscriptDone  = "response.audio_transcript.done"
+	RealtimeResponseAudioDelta           = "response.audio.delta"
+	RealtimeResponseAudioDone            = "response.audio.done"
+	RealtimeResponseDone                 = "response.done"
+)
+
+// Status of the realtime items and responses
+const (
+	RealtimeStatusInProgress = "in_progress"
+	RealtimeStatusCompleted  = "completed"
+	RealtimeStatusCancelled  = "cancelled"
+	RealtimeStatusFailed     = "failed"
+)
+
+type RealtimeTurnDetection struct {
+	// server_vad, or none to commit the audio and create the responses manually
+	Type string `json:"type"`
+	// RMS energy of the audio, between 0 and 1, above which the user is speaking
+	Threshold         float64 `json:"threshold,omitempty"`
+	PrefixPaddingMs   int     `json:"prefix_padding_ms,omitempty"`
+	SilenceDurationMs int     `json:"silence_duration_ms,omitempty"`
+}
+
+type RealtimeTranscription struct {
+	Model string `json:"model,omitempty"`
+}
+
+type RealtimeSession struct {
+	ID                      string                 `json:"id,omitempty"`
+	Object                  string                 `json:"object,omitempty"`
+	Model                   string                 `json:"model,omitempty"`
+	Modalities              []string               `json:"modalities,omitempty"`
+	Instructions            string                 `json:"instructions,omitempty"`
+	Voice                   string                 `json:"voice,omitempty"`
+	InputAudioFormat        string                 `json:"input_audio_format,omitempty"`
+	OutputAudioFormat       string                 `json:"output_audio_format,omitempty"`
+	InputAudioTranscription *RealtimeTranscription `json:"input_audio_transcription,omitempty"`
+	TurnDetection           *RealtimeTurnDetection `json:"turn_detection,omitempty"`
+	Temperature             float64                `json:"temperature,omitempty"`
+	MaxResponseOutputTokens int                    `json:"max_response_output_tokens,omitempty"`
+}
+
+type RealtimeContentPart struct {
+	// input_text, input_audio, text or audio
+	Type       string `json:"type"`
+	Text       string `json:"text,omitempty"`
+	Audio      string `json:"audio,omitempty"`
+	Transcript string `json:"transcript,omitempty"`
+}
+
+// String returns the text of the content, or the transcript of its audio
+func (p RealtimeContentPart) String() string {
+	if p.Text != "" {
+		return p.Text
+	}
+	return p.Transcript
+}
+
+type RealtimeItem struct {
+	ID      string                `json:"id,omitempty"`
+	Object  string                `json:"object,omitempty"`
+	Type    string                `json:"type"`
+	Status  string                `json:"status,omitempty"`
+	Role    string                `json:"role,omitempty"`
+	Content []RealtimeContentPart `json:"content"`
+}
+
+type RealtimeUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
+type RealtimeResponse struct {
+	ID     string         `json:"id"`
+	Object string         `json:"object"`
+	Status string         `json:"status"`
+	Output []RealtimeItem `json:"output"`
+	Usage  *RealtimeUsage `json:"usage,omitempty"`
+}
+
+type RealtimeErrorDetails struct {
+	Type    string `json:"type"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+	EventID string `json:"event_id,omitempty"`
+}
+
+// RealtimeEvent is an event sent by the client or the server, its fields depend on its type
+type RealtimeEvent struct {
+	EventID string `json:"event_id,omitempty"`
+	Type    string `json:"type"`
+
+	Session  *RealtimeSession      `json:"session,omitempty"`
+	Item     *RealtimeItem         `json:"item,omitempty"`
+	Part     *RealtimeContentPart  `json:"part,omitempty"`
+	Response *RealtimeResponse     `json:"response,omitempty"`
+	Error    *RealtimeErrorDetails `json:"error,omitempty"`
+
+	// base64 encoded audio of input_audio_buffer.append
+	Audio string `json:"audio,omitempty"`
+	// text or base64 encoded audio of the deltas
+	Delta      string `json:"delta,omitempty"`
+	Text       string `json:"text,omitempty"`
+	Transcript string `json:"transcript,omitempty"`
+
+	ItemID         string `json:"item_id,omitempty"`
+	PreviousItemID string `json:"previous_item_id,omitempty"`
+	ResponseID     string `json:"response_id,omitempty"`
+	OutputIndex    int    `json:"output_index,omitempty"`
+	ContentIndex   int    `json:"content_index,omitempty"`
+	AudioStartMs   int    `json:"audio_start_ms,omitempty"`
+	AudioEndMs     int    `json:"audio_end_ms,omitempty"`
+}
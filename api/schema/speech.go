@@ -0,0 +1,61 @@
+package schema
+
+// SpeechRequest is the request of the speech of a text, https://platform.openai.com/docs/api-reference/audio/createSpeech
+type SpeechRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+	// Voice is a voice of the TTS model, the one of the model when empty
+	Voice string `json:"voice"`
+	// ResponseFormat is wav (the default) or pcm, raw 16 bit samples at 24kHz
+	ResponseFormat string `json:"response_format"`
+	// StreamFormat streams the audio sentence by sentence as it is synthesized: audio sends the chunks of the audio,
+	// sse sends them base64 encoded in server-sent events. The whole audio is sent at once when empty.
+	StreamFormat string `json:"stream_format"`
+}
+
+// the types of the events of the speech streamed with the sse stream_format
+const (
+	SpeechAudioDelta = "speech.audio.delta"
+	SpeechAudioDone  = "speech.audio.done"
+	SpeechError      = "error"
+)
+
+// SpeechEvent is an event of the speech streamed with the sse stream_format
+type SpeechEvent struct {
+	Type string `json:"type"`
+	// Audio is the next chunk of the audio, base64 encoded
+	Audio string `json:"audio,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// the access of the cloned voices
+const (
+	// VoicePrivate voices are only used by the caller which cloned them
+	VoicePrivate = "private"
+	// VoiceTenant voices are used by the callers of the tenant of the caller which cloned them
+	VoiceTenant = "tenant"
+	// VoicePublic voices are used by all the callers
+	VoicePublic = "public"
+)
+
+// Voice is a voice cloned from a reference sample of the speaker, uploaded with the Files API
+type Voice struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	CreatedAt int64  `json:"created_at"`
+	Name      string `json:"name"`
+	// Model is the TTS model synthesizing the speech in the voice
+	Model    string `json:"model"`
+	FileID   string `json:"file_id"`
+	Language string `json:"language,omitempty"`
+	Access   string `json:"access"`
+}
+
+// VoiceRequest is the request cloning a voice
+type VoiceRequest struct {
+	Name     string `json:"name"`
+	Model    string `json:"model"`
+	FileID   string `json:"file_id"`
+	Language string `json:"language"`
+	Access   string `json:"access"`
+}
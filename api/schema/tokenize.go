@@ -0,0 +1,22 @@
+package schema
+
+type TokenizeRequest struct {
+	Content string `json:"content"`
+	// Messages are rendered with the chat templates of the model, like in the chat completions, before being tokenized
+	Messages []Message `json:"messages"`
+	Model    string    `json:"model"`
+}
+
+type TokenizeResponse struct {
+	Tokens []int32 `json:"tokens"`
+	Count  int     `json:"count"`
+}
+
+type DetokenizeRequest struct {
+	Tokens []int32 `json:"tokens"`
+	Model  string  `json:"model"`
+}
+
+type DetokenizeResponse struct {
+	Content string `json:"content"`
+}
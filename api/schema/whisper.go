@@ -8,9 +8,67 @@ type Segment struct {
 	End    time.Duration `json:"end"`
 	Text   string        `json:"text"`
 	Tokens []int         `json:"tokens"`
+	// Speaker is the label of the speaker of the segment, with diarization
+	Speaker string `json:"speaker,omitempty"`
+	// NoSpeechProb is the probability that the segment has no speech, unset when the backend can't tell
+	NoSpeechProb *float32 `json:"no_speech_prob,omitempty"`
+}
+
+// Word is a word of the audio, with timestamp_granularities=word
+type Word struct {
+	Word  string        `json:"word"`
+	Start time.Duration `json:"start"`
+	End   time.Duration `json:"end"`
 }
 
 type Result struct {
 	Segments []Segment `json:"segments"`
 	Text     string    `json:"text"`
+	Words    []Word    `json:"words,omitempty"`
+	// Language of the audio, detected when the request has none, and the probability of the detection
+	Language            string  `json:"language,omitempty"`
+	LanguageProbability float32 `json:"language_probability,omitempty"`
+}
+
+// Duration is the duration of the audio transcribed, up to the end of the last segment
+func (r Result) Duration() time.Duration {
+	if len(r.Segments) == 0 {
+		return 0
+	}
+	return r.Segments[len(r.Segments)-1].End
+}
+
+// the types of the events of the streamed transcriptions
+const (
+	TranscriptionDelta = "transcript.text.delta"
+	TranscriptionDone  = "transcript.text.done"
+	TranscriptionError = "error"
+)
+
+// TranscriptionEvent is an event of the streamed transcriptions: the text and the segments of a part of the audio as
+// soon as it is transcribed, then the whole text
+type TranscriptionEvent struct {
+	Type     string    `json:"type"`
+	Delta    string    `json:"delta,omitempty"`
+	Segments []Segment `json:"segments,omitempty"`
+	Words    []Word    `json:"words,omitempty"`
+	Text     string    `json:"text,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	// Language detected in the chunk of the delta, and in the first chunk for the whole text
+	Language            string  `json:"language,omitempty"`
+	LanguageProbability float32 `json:"language_probability,omitempty"`
+}
+
+// VADSegment is a part of the audio with speech
+type VADSegment struct {
+	Start time.Duration `json:"start"`
+	End   time.Duration `json:"end"`
+}
+
+// VADResponse is the speech found in an audio by a VAD model
+type VADResponse struct {
+	Segments []VADSegment `json:"segments"`
+	// Duration of the audio, and of its speech
+	Duration time.Duration `json:"duration"`
+	Speech   time.Duration `json:"speech"`
 }
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"time"
+
+	fiberContext "github.com/go-skynet/LocalAI/api/ctx"
+	"github.com/go-skynet/LocalAI/pkg/usage"
+	"github.com/gofiber/fiber/v2"
+)
+
+// usageMiddleware accounts the requests served with a model to the API key of the caller, and to its tenant. It must run after the
+// request middleware.
+func usageMiddleware(s *usage.Store, skip ...string) fiber.Handler {
+	skipped := map[string]bool{}
+	for _, p := range skip {
+		skipped[p] = true
+	}
+
+	return func(c *fiber.Ctx) error {
+		if skipped[c.Path()] {
+			return c.Next()
+		}
+
+		start := time.Now()
+		err := c.Next()
+
+		model := fiberContext.RequestModel(c)
+		if model == "" || fiberContext.ResponseStatus(c, err) >= fiber.StatusBadRequest {
+			return err
+		}
+		rec := usage.Record{
+			Time:   start,
+			Key:    callerFingerprint(c),
+			Tenant: callerTenant(c),
+			Model:  model,
+		}
+		if r := fiberContext.RequestFromCtx(c); r != nil {
+			served := r.Served()
+			rec.PromptTokens = served.PromptTokens
+			rec.CompletionTokens = served.CompletionTokens
+			rec.AudioSeconds = served.AudioSeconds
+		}
+		s.Add(rec)
+		return err
+	}
+}
@@ -32,6 +32,7 @@
 #include <grpcpp/grpcpp.h>
 #include <grpcpp/health_check_service_interface.h>
 #include <atomic>
+#include <cmath>
 
 using grpc::Server;
 using grpc::ServerBuilder;
@@ -153,6 +154,46 @@ static json probs_vector_to_json(const llama_context *ctx, const std::vector<com
     return out;
 }
 
+// add the log probabilities of the tokens of a result to a reply, with up to top_logprobs alternatives
+static void set_reply_logprobs(backend::Reply* reply, const json &result, int top_logprobs)
+{
+    if (!result.contains("completion_probabilities"))
+    {
+        return;
+    }
+    for (const auto &prob : result["completion_probabilities"])
+    {
+        backend::TokenLogprob* logprob = reply->add_logprobs();
+        const std::string token = prob.value("content", "");
+        logprob->set_token(token);
+        // the generated token is not always one of the most likely ones
+        logprob->set_logprob(-9999.0f);
+        int n = 0;
+        for (const auto &p : prob["probs"])
+        {
+            const std::string tok_str = p.value("tok_str", "");
+            const float lp = std::log(std::max(p.value("prob", 0.0f), 1e-30f));
+            if (tok_str == token)
+            {
+                logprob->set_logprob(lp);
+            }
+            if (n++ < top_logprobs)
+            {
+                backend::TokenProbability* top = logprob->add_top_logprobs();
+                top->set_token(tok_str);
+                top->set_logprob(lp);
+            }
+        }
+    }
+}
+
+// add the tokens of the prompt reused from the KV cache of the slot, and the ones evaluated, to the last reply
+static void set_reply_cache_stats(backend::Reply* reply, const json &result)
+{
+    reply->set_tokens_cached(result.value("prompt_tokens_cached", 0));
+    reply->set_tokens_evaluated(result.value("prompt_tokens_processed", 0));
+}
+
 struct llama_client_slot
 {
     int id;
@@ -1094,6 +1135,8 @@ struct llama_server_context
             {"stopped_limit",       slot.stopped_limit},
             {"stopping_word",       slot.stopping_word},
             {"tokens_cached",       slot.n_past},
+            {"prompt_tokens_cached",    slot.num_prompt_tokens - slot.num_prompt_tokens_processed},
+            {"prompt_tokens_processed", slot.num_prompt_tokens_processed},
             {"timings",             slot.get_formated_timings()}
         };
 
@@ -1335,6 +1378,31 @@ struct llama_server_context
             case TASK_TYPE_NEXT_RESPONSE: {
                 // do nothing
             } break;
+            case TASK_TYPE_RECONFIGURE: {
+                // the slots are created again, which drops their KV cache
+                for (llama_client_slot &slot : slots)
+                {
+                    if (slot.is_processing())
+                    {
+                        send_error(task, "the slots can only be reconfigured when all of them are idle");
+                        return;
+                    }
+                }
+                params.n_parallel = json_value(task.data, "n_parallel", params.n_parallel);
+                params.cont_batching = params.cont_batching || params.n_parallel > 1;
+                slots.clear();
+                llama_batch_free(batch);
+                kv_cache_clear();
+                initialize();
+
+                task_result res;
+                res.id = task.id;
+                res.multitask_id = task.multitask_id;
+                res.stop = true;
+                res.error = false;
+                res.result_json = json{ { "n_parallel", params.n_parallel } };
+                queue_results.send(res);
+            } break;
         }
     }
 
@@ -1923,7 +1991,14 @@ json parse_options(bool streaming, const backend::PredictOptions* predict, llama
     }
 
     data["stop"] = predict->stopprompts();
-    // data["n_probs"] = predict->nprobs();
+    // the slot is sent plus one, 0 lets the server pick the least recently used slot
+    if (predict->slot() > 0) {
+        data["slot_id"] = predict->slot() - 1;
+    }
+    if (predict->logprobs()) {
+        // the probabilities of the most likely tokens are needed to find the one of the generated token
+        data["n_probs"] = std::max(predict->toplogprobs(), 1);
+    }
     //TODO: images,
 
     return data;
@@ -2016,7 +2091,10 @@ static void params_parse(const backend::ModelOptions* request,
     // Set params.n_parallel by environment variable (LLAMA_PARALLEL), defaults to 1
     //params.n_parallel = 1;
     const char *env_parallel = std::getenv("LLAMACPP_PARALLEL");
-    if (env_parallel != NULL) {
+    if (request->parallel() > 0) {
+        params.n_parallel = request->parallel();
+        params.cont_batching = true;
+    } else if (env_parallel != NULL) {
         params.n_parallel = std::stoi(env_parallel);
         params.cont_batching = true;
     } else {
@@ -2059,6 +2137,7 @@ static void params_parse(const backend::ModelOptions* request,
     }
     params.use_mlock = request->mlock();
     params.use_mmap = request->mmap();
+    params.numa = request->numa();
     params.embedding = request->embeddings();
 
     if (request->ropescaling() == "none")   { params.rope_scaling_type = LLAMA_ROPE_SCALING_NONE; }
@@ -2094,6 +2173,50 @@ public:
     return Status::OK;
   }
 
+  grpc::Status Capabilities(ServerContext* context, const backend::HealthMessage* request, backend::CapabilitiesResponse* response) {
+    // keep in sync with ProtocolVersion in pkg/grpc/version.go
+    response->set_protocol_version(22);
+    response->add_capabilities("predict");
+    response->add_capabilities("predict_stream");
+    response->add_capabilities("tokenize");
+    response->add_capabilities("detokenize");
+    response->add_capabilities("reconfigure");
+    // the images of the prompts are read with the multimodal projector
+    if (llama.clp_ctx != nullptr) {
+      response->add_capabilities("vision");
+    }
+    return Status::OK;
+  }
+
+  grpc::Status Reconfigure(ServerContext* context, const backend::ReconfigureRequest* request, backend::Result* result) {
+    if (request->parallel() < 1) {
+        result->set_message("the number of parallel slots must be at least 1");
+        result->set_success(false);
+        return Status::OK;
+    }
+
+    // the slots are reconfigured by the loop processing the tasks, between two batches
+    const int task_id = llama.queue_tasks.get_new_id();
+    llama.queue_results.add_waiting_task_id(task_id);
+    task_server task;
+    task.id = task_id;
+    task.target_id = -1;
+    task.type = TASK_TYPE_RECONFIGURE;
+    task.data = json{ { "n_parallel", request->parallel() } };
+    llama.queue_tasks.post(task);
+
+    task_result res = llama.queue_results.recv(task_id);
+    llama.queue_results.remove_waiting_task_id(task_id);
+    if (res.error) {
+        result->set_message(res.result_json.value("content", ""));
+        result->set_success(false);
+        return Status::OK;
+    }
+    result->set_message("Reconfigured the slots");
+    result->set_success(true);
+    return Status::OK;
+  }
+
   grpc::Status LoadModel(ServerContext* context, const backend::ModelOptions* request, backend::Result* result) {
     // Implement LoadModel RPC
     gpt_params params;
@@ -2136,9 +2259,21 @@ public:
                 std::string completion_text = result.result_json.value("content", "");
 
                 reply.set_message(completion_text);
+                // the final result repeats the probabilities of all the tokens already sent
+                if (request->logprobs() && !result.stop) {
+                    set_reply_logprobs(&reply, result.result_json, request->toplogprobs());
+                }
+                if (result.stop) {
+                    set_reply_cache_stats(&reply, result.result_json);
+                }
 
-                // Send the reply
-                writer->Write(reply);
+                // Send the reply, and stop generating if the client went away
+                if (context->IsCancelled() || !writer->Write(reply)) {
+                    LOG_VERBOSE("request cancelled", {{"task_id", task_id}});
+                    llama.request_cancel(task_id);
+                    llama.queue_results.remove_waiting_task_id(task_id);
+                    return grpc::Status::CANCELLED;
+                }
 
                 if (result.stop) {
                     break;
@@ -2162,6 +2297,10 @@ public:
         if (!result.error && result.stop) {
             completion_text = result.result_json.value("content", "");
             reply->set_message(completion_text);
+            if (request->logprobs()) {
+                set_reply_logprobs(reply, result.result_json, request->toplogprobs());
+            }
+            set_reply_cache_stats(reply, result.result_json);
         }
         else
         {
@@ -2170,6 +2309,20 @@ public:
 
         return grpc::Status::OK;
     }
+
+    grpc::Status TokenizeString(ServerContext* context, const backend::PredictOptions* request, backend::TokenizationResponse* response) {
+        const std::vector<llama_token> tokens = ::llama_tokenize(llama.ctx, request->prompt(), llama.add_bos_token);
+        for (const auto &token : tokens) {
+            response->add_tokens(token);
+        }
+        response->set_length(tokens.size());
+        return grpc::Status::OK;
+    }
+
+    grpc::Status Detokenize(ServerContext* context, const backend::DetokenizationRequest* request, backend::DetokenizationResponse* response) {
+        response->set_content(tokens_to_str(llama.ctx, request->tokens().begin(), request->tokens().end()));
+        return grpc::Status::OK;
+    }
 };
 
 void RunServer(const std::string& server_address) {
@@ -2177,6 +2330,9 @@ void RunServer(const std::string& server_address) {
 
   ServerBuilder builder;
   builder.AddListeningPort(server_address, grpc::InsecureServerCredentials());
+  // Allow LocalAI to send keepalive pings often
+  builder.AddChannelArgument(GRPC_ARG_HTTP2_MIN_RECV_PING_INTERVAL_WITHOUT_DATA_MS, 5000);
+  builder.AddChannelArgument(GRPC_ARG_KEEPALIVE_PERMIT_WITHOUT_CALLS, 1);
   builder.RegisterService(&service);
 
   std::unique_ptr<Server> server(builder.BuildAndStart());
@@ -29,5 +29,6 @@ func (image *Image) GenerateImage(opts *pb.GenerateImageRequest) error {
 		int(opts.Seed),
 		opts.PositivePrompt,
 		opts.NegativePrompt,
+		opts.Src,
 		opts.Dst)
 }
@@ -75,6 +75,14 @@ func (llm *LLM) PredictStream(opts *pb.PredictOptions, results chan string) erro
 	return nil
 }
 
+func (llm *LLM) Detokenize(opts *pb.DetokenizationRequest) (string, error) {
+	tokens := make([]int, len(opts.Tokens))
+	for i, t := range opts.Tokens {
+		tokens[i] = int(t)
+	}
+	return rwkv.DeTokenise(*llm.rwkv.Tokenizer, tokens), nil
+}
+
 func (llm *LLM) TokenizeString(opts *pb.PredictOptions) (pb.TokenizationResponse, error) {
 	tokens, err := llm.rwkv.Tokenizer.Encode(opts.Prompt)
 	if err != nil {
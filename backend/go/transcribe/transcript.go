@@ -5,7 +5,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 
+	whisperlib "github.com/ggerganov/whisper.cpp/bindings/go"
 	"github.com/ggerganov/whisper.cpp/bindings/go/pkg/whisper"
 	"github.com/go-audio/wav"
 	"github.com/go-skynet/LocalAI/api/schema"
@@ -29,7 +31,19 @@ func audioToWav(src, dst string) error {
 	return nil
 }
 
-func Transcript(model whisper.Model, audiopath, language string, threads uint) (schema.Result, error) {
+// isWhisperWav returns true if the audio is already a 16kHz mono 16 bit PCM WAV, which LocalAI sends once it
+// transcoded the uploads
+func isWhisperWav(file string) bool {
+	fh, err := os.Open(file)
+	if err != nil {
+		return false
+	}
+	defer fh.Close()
+	d := wav.NewDecoder(fh)
+	return d.IsValidFile() && d.WavAudioFormat == 1 && d.SampleRate == 16000 && d.NumChans == 1 && d.BitDepth == 16
+}
+
+func Transcript(model whisper.Model, audiopath, language string, translate, wordTimestamps bool, threads uint) (schema.Result, error) {
 	res := schema.Result{}
 
 	dir, err := os.MkdirTemp("", "whisper")
@@ -40,7 +54,9 @@ func Transcript(model whisper.Model, audiopath, language string, threads uint) (
 
 	convertedPath := filepath.Join(dir, "converted.wav")
 
-	if err := audioToWav(audiopath, convertedPath); err != nil {
+	if isWhisperWav(audiopath) {
+		convertedPath = audiopath
+	} else if err := audioToWav(audiopath, convertedPath); err != nil {
 		return res, err
 	}
 
@@ -69,16 +85,31 @@ func Transcript(model whisper.Model, audiopath, language string, threads uint) (
 
 	context.SetThreads(threads)
 
+	if translate {
+		if !context.IsMultilingual() {
+			return res, fmt.Errorf("the model can't translate, as it is not multilingual")
+		}
+		context.SetTranslate(true)
+	}
+
 	if language != "" {
 		context.SetLanguage(language)
 	} else {
 		context.SetLanguage("auto")
 	}
 
+	context.SetTokenTimestamps(wordTimestamps)
+
 	if err := context.Process(data, nil, nil); err != nil {
 		return res, err
 	}
 
+	if language != "" {
+		res.Language = language
+	} else {
+		res.Language, res.LanguageProbability = detectLanguage(context, threads)
+	}
+
 	for {
 		s, err := context.NextSegment()
 		if err != nil {
@@ -88,6 +119,16 @@ func Transcript(model whisper.Model, audiopath, language string, threads uint) (
 		var tokens []int
 		for _, t := range s.Tokens {
 			tokens = append(tokens, t.Id)
+			if !wordTimestamps || !context.IsText(t) {
+				continue
+			}
+			// the tokens starting with a space start a new word, the others continue the last one
+			if n := len(res.Words); n > 0 && !strings.HasPrefix(t.Text, " ") {
+				res.Words[n-1].Word += t.Text
+				res.Words[n-1].End = t.End
+			} else if word := strings.TrimSpace(t.Text); word != "" {
+				res.Words = append(res.Words, schema.Word{Word: word, Start: t.Start, End: t.End})
+			}
 		}
 
 		segment := schema.Segment{Id: s.Num, Text: s.Text, Start: s.Start, End: s.End, Tokens: tokens}
@@ -98,3 +139,28 @@ func Transcript(model whisper.Model, audiopath, language string, threads uint) (
 
 	return res, nil
 }
+
+// langDetector is implemented by the contexts of the bindings detecting the language of the audio
+type langDetector interface {
+	WhisperLangAutoDetect(offsetMs int, threads int) ([]float32, error)
+}
+
+// detectLanguage returns the most probable language of the start of the audio processed by the context, and its
+// probability. The segments don't tell whether they have speech with whisper.cpp, their no_speech_prob is unset.
+func detectLanguage(context whisper.Context, threads uint) (string, float32) {
+	d, ok := context.(langDetector)
+	if !ok {
+		return "", 0
+	}
+	probs, err := d.WhisperLangAutoDetect(0, int(threads))
+	if err != nil || len(probs) == 0 {
+		return "", 0
+	}
+	best := 0
+	for i, p := range probs {
+		if p > probs[best] {
+			best = i
+		}
+	}
+	return whisperlib.Whisper_lang_str(best), probs[best]
+}
@@ -80,7 +80,14 @@ class BackendServicer(backend_pb2_grpc.BackendServicer):
 
 
 def serve(address):
-    server = grpc.server(futures.ThreadPoolExecutor(max_workers=MAX_WORKERS))
+    # accept the keepalive pings of LocalAI down to every 5 seconds, as the Go and C++ backends do
+    server = grpc.server(
+        futures.ThreadPoolExecutor(max_workers=MAX_WORKERS),
+        options=[
+            ('grpc.http2.min_ping_interval_without_data_ms', 5000),
+            ('grpc.keepalive_permit_without_calls', 1),
+        ],
+    )
     backend_pb2_grpc.add_BackendServicer_to_server(BackendServicer(), server)
     server.add_insecure_port(address)
     server.start()
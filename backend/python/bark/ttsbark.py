@@ -42,7 +42,8 @@ class BackendServicer(backend_pb2_grpc.BackendServicer):
         return backend_pb2.Result(message="Model loaded successfully", success=True)
 
     def TTS(self, request, context):
-        model = request.model
+        # the voice is the history prompt of the speaker, e.g. v2/en_speaker_6
+        model = request.voice if request.voice != "" else request.model
         print(request, file=sys.stderr)
         try:
             audio_array = None
@@ -61,7 +62,14 @@ class BackendServicer(backend_pb2_grpc.BackendServicer):
         return backend_pb2.Result(success=True)
 
 def serve(address):
-    server = grpc.server(futures.ThreadPoolExecutor(max_workers=MAX_WORKERS))
+    # accept the keepalive pings of LocalAI down to every 5 seconds, as the Go and C++ backends do
+    server = grpc.server(
+        futures.ThreadPoolExecutor(max_workers=MAX_WORKERS),
+        options=[
+            ('grpc.http2.min_ping_interval_without_data_ms', 5000),
+            ('grpc.keepalive_permit_without_calls', 1),
+        ],
+    )
     backend_pb2_grpc.add_BackendServicer_to_server(BackendServicer(), server)
     server.add_insecure_port(address)
     server.start()
@@ -65,14 +65,32 @@ class BackendServicer(backend_pb2_grpc.BackendServicer):
         return backend_pb2.Result(message="Model loaded successfully", success=True)
 
     def TTS(self, request, context):
+        # the voice is the reference audio of the speaker (XTTS), or the name of a speaker of the model
+        speaker_wav = self.AudioPath
+        speaker = None
+        if request.voice != "":
+            if os.path.isfile(request.voice):
+                speaker_wav = request.voice
+            else:
+                speaker = request.voice
+        language = request.language if request.language != "" else COQUI_LANGUAGE
+        if not self.tts.is_multi_lingual:
+            language = None
         try:
-            self.tts.tts_to_file(text=request.text, speaker_wav=self.AudioPath, language=COQUI_LANGUAGE, file_path=request.dst)
+            self.tts.tts_to_file(text=request.text, speaker_wav=speaker_wav, speaker=speaker, language=language, file_path=request.dst)
         except Exception as err:
             return backend_pb2.Result(success=False, message=f"Unexpected {err=}, {type(err)=}")
         return backend_pb2.Result(success=True)
 
 def serve(address):
-    server = grpc.server(futures.ThreadPoolExecutor(max_workers=MAX_WORKERS))
+    # accept the keepalive pings of LocalAI down to every 5 seconds, as the Go and C++ backends do
+    server = grpc.server(
+        futures.ThreadPoolExecutor(max_workers=MAX_WORKERS),
+        options=[
+            ('grpc.http2.min_ping_interval_without_data_ms', 5000),
+            ('grpc.keepalive_permit_without_calls', 1),
+        ],
+    )
     backend_pb2_grpc.add_BackendServicer_to_server(BackendServicer(), server)
     server.add_insecure_port(address)
     server.start()
@@ -4,8 +4,12 @@ from concurrent import futures
 import argparse
 from collections import defaultdict
 from enum import Enum
+import inspect
+import io
+import queue
 import signal
 import sys
+import threading
 import time
 import os
 
@@ -19,6 +23,7 @@ import grpc
 
 from diffusers import StableDiffusionXLPipeline, StableDiffusionDepth2ImgPipeline, DPMSolverMultistepScheduler, StableDiffusionPipeline, DiffusionPipeline, EulerAncestralDiscreteScheduler
 from diffusers import StableDiffusionImg2ImgPipeline, AutoPipelineForText2Image, ControlNetModel, StableVideoDiffusionPipeline
+from diffusers import StableDiffusionInpaintPipeline
 from diffusers.pipelines.stable_diffusion import safety_checker
 from diffusers.utils import load_image,export_to_video
 from compel import Compel
@@ -35,10 +40,31 @@ CHUNK_SIZE=os.environ.get("CHUNK_SIZE", "8")
 FPS=os.environ.get("FPS", "7")
 DISABLE_CPU_OFFLOAD=os.environ.get("DISABLE_CPU_OFFLOAD", "0") == "1"
 FRAMES=os.environ.get("FRAMES", "64")
+# the version of backend.proto spoken by the backend, ProtocolVersion of pkg/grpc/version.go
+PROTOCOL_VERSION=22
 
 # If MAX_WORKERS are specified in the environment use it, otherwise default to 1
 MAX_WORKERS = int(os.environ.get('PYTHON_GRPC_MAX_WORKERS', '1'))
 
+# the approximate RGB of the 4 channels of the latents of Stable Diffusion, to preview the images being generated
+# without decoding them with the VAE
+LATENT_RGB_FACTORS = [
+    [0.298, 0.207, 0.208],
+    [0.187, 0.286, 0.173],
+    [-0.158, 0.189, 0.264],
+    [-0.184, -0.271, -0.473],
+]
+
+def latents_preview(latents):
+    # a PNG of a pixel per latent of an image, 1/8 of the size of the image
+    if latents.ndim != 3 or latents.shape[0] != len(LATENT_RGB_FACTORS):
+        return b""
+    rgb = torch.einsum("chw,cr->hwr", latents.float().cpu(), torch.tensor(LATENT_RGB_FACTORS))
+    rgb = ((rgb + 1) / 2).clamp(0, 1).mul(255).byte().numpy()
+    buf = io.BytesIO()
+    Image.fromarray(rgb).save(buf, format="PNG")
+    return buf.getvalue()
+
 # https://github.com/CompVis/stable-diffusion/issues/239#issuecomment-1627615287
 def sc(self, clip_input, images) : return images, [False for i in images]
 # edit the StableDiffusionSafetyChecker class so that, when called, it just returns the images and an array of True values
@@ -133,8 +159,23 @@ def get_scheduler(name: str, config: dict = {}):
 
 # Implement the BackendServicer class with the service methods
 class BackendServicer(backend_pb2_grpc.BackendServicer):
+    inpaint = False
+    progress = False
+    batch = False
+    loras = []
+
     def Health(self, request, context):
         return backend_pb2.Reply(message=bytes("OK", 'utf-8'))
+    def Capabilities(self, request, context):
+        # the inpainting depends on the pipeline of the loaded model
+        capabilities = ["image"]
+        if self.inpaint:
+            capabilities.append("inpaint")
+        if self.progress:
+            capabilities.append("image_progress")
+        if self.batch:
+            capabilities.append("image_batch")
+        return backend_pb2.CapabilitiesResponse(protocol_version=PROTOCOL_VERSION, capabilities=capabilities)
     def LoadModel(self, request, context):
         try:
             print(f"Loading model {request.Model}...", file=sys.stderr)
@@ -169,6 +210,7 @@ class BackendServicer(backend_pb2_grpc.BackendServicer):
             fromSingleFile = request.Model.startswith("http") or request.Model.startswith("/") or local
             self.img2vid=False
             self.txt2vid=False
+            self.inpaint=False
             ## img2img
             if (request.PipelineType == "StableDiffusionImg2ImgPipeline") or (request.IMG2IMG and request.PipelineType == ""):
                 if fromSingleFile:
@@ -178,6 +220,16 @@ class BackendServicer(backend_pb2_grpc.BackendServicer):
                     self.pipe = StableDiffusionImg2ImgPipeline.from_pretrained(request.Model,
                                 torch_dtype=torchType)
 
+            ## inpainting
+            elif request.PipelineType == "StableDiffusionInpaintPipeline":
+                self.inpaint=True
+                if fromSingleFile:
+                    self.pipe = StableDiffusionInpaintPipeline.from_single_file(modelFile,
+                                torch_dtype=torchType)
+                else:
+                    self.pipe = StableDiffusionInpaintPipeline.from_pretrained(request.Model,
+                                torch_dtype=torchType)
+
             elif request.PipelineType == "StableDiffusionDepth2ImgPipeline":
                 self.pipe = StableDiffusionDepth2ImgPipeline.from_pretrained(request.Model,
                             torch_dtype=torchType)
@@ -243,10 +295,24 @@ class BackendServicer(backend_pb2_grpc.BackendServicer):
             else:
                 self.controlnet = None
 
+            # the ControlNets of the control types, used by the generations with a control image
+            self.controlnets = {}
+            for control_type, controlnet in request.ControlNets.items():
+                self.controlnets[control_type] = ControlNetModel.from_pretrained(
+                    controlnet, torch_dtype=torchType, variant=variant
+                )
+
             if request.CUDA:
                 self.pipe.to('cuda')
                 if self.controlnet:
                     self.controlnet.to('cuda')
+                for controlnet in self.controlnets.values():
+                    controlnet.to('cuda')
+
+            # the image pipelines call back at the end of each step, with the progress of the generations
+            self.progress = not self.img2vid and not self.txt2vid and "callback_on_step_end" in inspect.signature(self.pipe.__call__).parameters
+            # the image pipelines generate several images of a prompt at once
+            self.batch = not self.img2vid and not self.txt2vid
             # Assume directory from request.ModelFile.
             # Only if request.LoraAdapter it's not an absolute path
             if request.LoraAdapter and request.ModelFile != "" and not os.path.isabs(request.LoraAdapter) and request.LoraAdapter:
@@ -263,6 +329,11 @@ class BackendServicer(backend_pb2_grpc.BackendServicer):
                 else:
                     self.pipe.unet.load_attn_procs(request.LoraAdapter)
 
+            # the LoRA adapters which the generations select, with their weights
+            self.loras = list(request.Loras.keys())
+            for name, path in request.Loras.items():
+                self.pipe.load_lora_weights(os.path.dirname(path), weight_name=os.path.basename(path), adapter_name=name)
+
         except Exception as err:
             return backend_pb2.Result(success=False, message=f"Unexpected {err=}, {type(err)=}")
         # Implement your logic here for the LoadModel service
@@ -325,6 +396,37 @@ class BackendServicer(backend_pb2_grpc.BackendServicer):
                 curr_layer.weight.data += multiplier * alpha * torch.mm(weight_up, weight_down)
 
     def GenerateImage(self, request, context):
+        return self.generate(request, context)
+
+    def GenerateImageStream(self, request, context):
+        # the images are generated in a thread, and the progress of each step is sent as soon as the step ends
+        progress = queue.Queue()
+        def on_step_end(pipe, step, timestep, callback_kwargs):
+            if not context.is_active():
+                raise RuntimeError("the generation was cancelled")
+            steps = getattr(pipe, "num_timesteps", None) or request.step or 1
+            latents = callback_kwargs["latents"]
+            for i in range(max(len(request.dsts), 1)):
+                preview = latents_preview(latents[i]) if request.preview and i < len(latents) else b""
+                progress.put(backend_pb2.ImageProgress(step=step + 1, steps=steps, preview=preview, index=i))
+            return callback_kwargs
+
+        errors = []
+        def generate():
+            try:
+                self.generate(request, context, on_step_end if self.progress else None)
+            except Exception as err:
+                errors.append(err)
+            finally:
+                progress.put(None)
+        threading.Thread(target=generate).start()
+
+        while (p := progress.get()) is not None:
+            yield p
+        if errors:
+            raise errors[0]
+
+    def generate(self, request, context, on_step_end=None):
 
         prompt = request.positive_prompt
 
@@ -363,12 +465,56 @@ class BackendServicer(backend_pb2_grpc.BackendServicer):
         # create a dictionary of parameters by using the keys from EnableParameters and the values from defaults
         kwargs = {key: options[key] for key in keys}
 
+        # how much the image is transformed by the image to image pipelines
+        if request.strength > 0 and "image" in kwargs and not self.controlnet:
+            kwargs["strength"] = request.strength
+
+        # the inpainting pipelines repaint the white areas of the mask of the image
+        if self.inpaint and request.mask != "":
+            kwargs["image"] = Image.open(request.src)
+            kwargs["mask_image"] = Image.open(request.mask)
+
+        # the ControlNet of the control type conditions the generation with the control image, in a pipeline sharing
+        # the components of the model
+        pipe = self.pipe
+        if request.control_image != "":
+            controlnet = self.controlnets.get(request.control_type)
+            if controlnet is None:
+                context.abort(grpc.StatusCode.INVALID_ARGUMENT, f"no ControlNet for the control type {request.control_type}")
+            pipe = AutoPipelineForText2Image.from_pipe(self.pipe, controlnet=controlnet)
+            kwargs["image"] = load_image(request.control_image)
+
+        # the scheduler of the request replaces the one of the model, in a pipeline sharing its other components
+        if request.scheduler != "":
+            scheduler = get_scheduler(request.scheduler, dict(pipe.scheduler.config))
+            pipe = pipe.__class__(**{**pipe.components, "scheduler": scheduler})
+        cfg_scale = request.cfg_scale or self.cfg_scale
+
+        # the LoRAs of the generation, before the prompt is encoded by the text encoder they adapt too
+        if self.loras:
+            if request.loras:
+                pipe.enable_lora()
+                pipe.set_adapters(list(request.loras.keys()), adapter_weights=list(request.loras.values()))
+            else:
+                pipe.disable_lora()
+
+        if on_step_end is not None:
+            kwargs["callback_on_step_end"] = on_step_end
+
         # Set seed
         if request.seed > 0:
             kwargs["generator"] = torch.Generator(device=self.device).manual_seed(
                 request.seed
             )
 
+        # the images of the batch are generated at once, each with its own seed so that it is the image generated
+        # alone with the seed
+        dsts = list(request.dsts) or [request.dst]
+        if len(dsts) > 1 and not self.img2vid and not self.txt2vid:
+            kwargs["num_images_per_prompt"] = len(dsts)
+            if request.seed > 0:
+                kwargs["generator"] = [torch.Generator(device=self.device).manual_seed(request.seed + i) for i in range(len(dsts))]
+
         if self.img2vid:
             # Load the conditioning image
             image = load_image(request.src)
@@ -384,30 +530,38 @@ class BackendServicer(backend_pb2_grpc.BackendServicer):
             export_to_video(video_frames, request.dst)
             return backend_pb2.Result(message="Media generated successfully", success=True)
 
-        image = {}
+        images = []
         if COMPEL:
             conditioning = self.compel.build_conditioning_tensor(prompt)
             kwargs["prompt_embeds"]= conditioning
-            # pass the kwargs dictionary to the self.pipe method
-            image = self.pipe(
-                guidance_scale=self.cfg_scale,
+            # pass the kwargs dictionary to the pipe method
+            images = pipe(
+                guidance_scale=cfg_scale,
                 **kwargs
-                ).images[0] 
+                ).images
         else:
-            # pass the kwargs dictionary to the self.pipe method
-            image = self.pipe(
+            # pass the kwargs dictionary to the pipe method
+            images = pipe(
                 prompt,
-                guidance_scale=self.cfg_scale,
+                guidance_scale=cfg_scale,
                 **kwargs
-                ).images[0]
+                ).images
 
         # save the result
-        image.save(request.dst)
+        for dst, image in zip(dsts, images):
+            image.save(dst)
 
         return backend_pb2.Result(message="Media generated", success=True)
 
 def serve(address):
-    server = grpc.server(futures.ThreadPoolExecutor(max_workers=MAX_WORKERS))
+    # accept the keepalive pings of LocalAI down to every 5 seconds, as the Go and C++ backends do
+    server = grpc.server(
+        futures.ThreadPoolExecutor(max_workers=MAX_WORKERS),
+        options=[
+            ('grpc.http2.min_ping_interval_without_data_ms', 5000),
+            ('grpc.keepalive_permit_without_calls', 1),
+        ],
+    )
     backend_pb2_grpc.add_BackendServicer_to_server(BackendServicer(), server)
     server.add_insecure_port(address)
     server.start()
@@ -4,6 +4,7 @@ A test script to test the gRPC service
 import unittest
 import subprocess
 import time
+import os
 import backend_pb2
 import backend_pb2_grpc
 
@@ -81,4 +82,47 @@ class TestBackendServicer(unittest.TestCase):
             print(err)
             self.fail("Image gen service failed")
         finally:
-            self.tearDown()
\ No newline at end of file
+            self.tearDown()
+
+    def test_stream(self):
+        """
+        This method tests if the backend streams the progress of the generations
+        """
+        time.sleep(10)
+        try:
+            self.setUp()
+            with grpc.insecure_channel("localhost:50051") as channel:
+                stub = backend_pb2_grpc.BackendStub(channel)
+                response = stub.LoadModel(backend_pb2.ModelOptions(Model="runwayml/stable-diffusion-v1-5"))
+                self.assertTrue(response.success)
+                image_req = backend_pb2.GenerateImageRequest(positive_prompt="cat", width=16, height=16, step=3, preview=True, dst="test.jpg")
+                progress = list(stub.GenerateImageStream(image_req))
+                self.assertEqual([p.step for p in progress], [1, 2, 3])
+                self.assertTrue(all(p.steps == 3 and p.preview for p in progress))
+        except Exception as err:
+            print(err)
+            self.fail("Image stream service failed")
+        finally:
+            self.tearDown()
+
+    def test_batch(self):
+        """
+        This method tests if the backend generates the images of a batch
+        """
+        time.sleep(10)
+        try:
+            self.setUp()
+            with grpc.insecure_channel("localhost:50051") as channel:
+                stub = backend_pb2_grpc.BackendStub(channel)
+                response = stub.LoadModel(backend_pb2.ModelOptions(Model="runwayml/stable-diffusion-v1-5"))
+                self.assertTrue(response.success)
+                self.assertIn("image_batch", stub.Capabilities(backend_pb2.HealthMessage()).capabilities)
+                image_req = backend_pb2.GenerateImageRequest(positive_prompt="cat", width=16, height=16, seed=7, dsts=["test-0.jpg", "test-1.jpg"])
+                re = stub.GenerateImage(image_req)
+                self.assertTrue(re.success)
+                self.assertTrue(os.path.exists("test-0.jpg") and os.path.exists("test-1.jpg"))
+        except Exception as err:
+            print(err)
+            self.fail("Image batch service failed")
+        finally:
+            self.tearDown()
@@ -59,11 +59,56 @@ class BackendStub(object):
                 request_serializer=backend__pb2.PredictOptions.SerializeToString,
                 response_deserializer=backend__pb2.TokenizationResponse.FromString,
                 )
+        self.Detokenize = channel.unary_unary(
+                '/backend.Backend/Detokenize',
+                request_serializer=backend__pb2.DetokenizationRequest.SerializeToString,
+                response_deserializer=backend__pb2.DetokenizationResponse.FromString,
+                )
         self.Status = channel.unary_unary(
                 '/backend.Backend/Status',
                 request_serializer=backend__pb2.HealthMessage.SerializeToString,
                 response_deserializer=backend__pb2.StatusResponse.FromString,
                 )
+        self.Capabilities = channel.unary_unary(
+                '/backend.Backend/Capabilities',
+                request_serializer=backend__pb2.HealthMessage.SerializeToString,
+                response_deserializer=backend__pb2.CapabilitiesResponse.FromString,
+                )
+        self.Classify = channel.unary_unary(
+                '/backend.Backend/Classify',
+                request_serializer=backend__pb2.ClassifyRequest.SerializeToString,
+                response_deserializer=backend__pb2.ClassifyResult.FromString,
+                )
+        self.Reconfigure = channel.unary_unary(
+                '/backend.Backend/Reconfigure',
+                request_serializer=backend__pb2.ReconfigureRequest.SerializeToString,
+                response_deserializer=backend__pb2.Result.FromString,
+                )
+        self.EmbeddingBatch = channel.unary_stream(
+                '/backend.Backend/EmbeddingBatch',
+                request_serializer=backend__pb2.EmbeddingBatchRequest.SerializeToString,
+                response_deserializer=backend__pb2.EmbeddingBatchResult.FromString,
+                )
+        self.Diarize = channel.unary_unary(
+                '/backend.Backend/Diarize',
+                request_serializer=backend__pb2.DiarizeRequest.SerializeToString,
+                response_deserializer=backend__pb2.DiarizeResult.FromString,
+                )
+        self.VAD = channel.unary_unary(
+                '/backend.Backend/VAD',
+                request_serializer=backend__pb2.VADRequest.SerializeToString,
+                response_deserializer=backend__pb2.VADResult.FromString,
+                )
+        self.Upscale = channel.unary_unary(
+                '/backend.Backend/Upscale',
+                request_serializer=backend__pb2.UpscaleRequest.SerializeToString,
+                response_deserializer=backend__pb2.Result.FromString,
+                )
+        self.GenerateImageStream = channel.unary_stream(
+                '/backend.Backend/GenerateImageStream',
+                request_serializer=backend__pb2.GenerateImageRequest.SerializeToString,
+                response_deserializer=backend__pb2.ImageProgress.FromString,
+                )
 
 
 class BackendServicer(object):
@@ -123,12 +168,66 @@ class BackendServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def Detokenize(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
     def Status(self, request, context):
         """Missing associated documentation comment in .proto file."""
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def Capabilities(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def Classify(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def Reconfigure(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def EmbeddingBatch(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def Diarize(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def VAD(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def Upscale(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GenerateImageStream(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
 
 def add_BackendServicer_to_server(servicer, server):
     rpc_method_handlers = {
@@ -177,11 +276,56 @@ def add_BackendServicer_to_server(servicer, server):
                     request_deserializer=backend__pb2.PredictOptions.FromString,
                     response_serializer=backend__pb2.TokenizationResponse.SerializeToString,
             ),
+            'Detokenize': grpc.unary_unary_rpc_method_handler(
+                    servicer.Detokenize,
+                    request_deserializer=backend__pb2.DetokenizationRequest.FromString,
+                    response_serializer=backend__pb2.DetokenizationResponse.SerializeToString,
+            ),
             'Status': grpc.unary_unary_rpc_method_handler(
                     servicer.Status,
                     request_deserializer=backend__pb2.HealthMessage.FromString,
                     response_serializer=backend__pb2.StatusResponse.SerializeToString,
             ),
+            'Capabilities': grpc.unary_unary_rpc_method_handler(
+                    servicer.Capabilities,
+                    request_deserializer=backend__pb2.HealthMessage.FromString,
+                    response_serializer=backend__pb2.CapabilitiesResponse.SerializeToString,
+            ),
+            'Classify': grpc.unary_unary_rpc_method_handler(
+                    servicer.Classify,
+                    request_deserializer=backend__pb2.ClassifyRequest.FromString,
+                    response_serializer=backend__pb2.ClassifyResult.SerializeToString,
+            ),
+            'Reconfigure': grpc.unary_unary_rpc_method_handler(
+                    servicer.Reconfigure,
+                    request_deserializer=backend__pb2.ReconfigureRequest.FromString,
+                    response_serializer=backend__pb2.Result.SerializeToString,
+            ),
+            'EmbeddingBatch': grpc.unary_stream_rpc_method_handler(
+                    servicer.EmbeddingBatch,
+                    request_deserializer=backend__pb2.EmbeddingBatchRequest.FromString,
+                    response_serializer=backend__pb2.EmbeddingBatchResult.SerializeToString,
+            ),
+            'Diarize': grpc.unary_unary_rpc_method_handler(
+                    servicer.Diarize,
+                    request_deserializer=backend__pb2.DiarizeRequest.FromString,
+                    response_serializer=backend__pb2.DiarizeResult.SerializeToString,
+            ),
+            'VAD': grpc.unary_unary_rpc_method_handler(
+                    servicer.VAD,
+                    request_deserializer=backend__pb2.VADRequest.FromString,
+                    response_serializer=backend__pb2.VADResult.SerializeToString,
+            ),
+            'Upscale': grpc.unary_unary_rpc_method_handler(
+                    servicer.Upscale,
+                    request_deserializer=backend__pb2.UpscaleRequest.FromString,
+                    response_serializer=backend__pb2.Result.SerializeToString,
+            ),
+            'GenerateImageStream': grpc.unary_stream_rpc_method_handler(
+                    servicer.GenerateImageStream,
+                    request_deserializer=backend__pb2.GenerateImageRequest.FromString,
+                    response_serializer=backend__pb2.ImageProgress.SerializeToString,
+            ),
     }
     generic_handler = grpc.method_handlers_generic_handler(
             'backend.Backend', rpc_method_handlers)
@@ -345,6 +489,23 @@ class Backend(object):
             options, channel_credentials,
             insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
 
+    @staticmethod
+    def Detokenize(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/backend.Backend/Detokenize',
+            backend__pb2.DetokenizationRequest.SerializeToString,
+            backend__pb2.DetokenizationResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
     @staticmethod
     def Status(request,
             target,
@@ -361,3 +522,139 @@ class Backend(object):
             backend__pb2.StatusResponse.FromString,
             options, channel_credentials,
             insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def Capabilities(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/backend.Backend/Capabilities',
+            backend__pb2.HealthMessage.SerializeToString,
+            backend__pb2.CapabilitiesResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def Classify(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/backend.Backend/Classify',
+            backend__pb2.ClassifyRequest.SerializeToString,
+            backend__pb2.ClassifyResult.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def Reconfigure(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/backend.Backend/Reconfigure',
+            backend__pb2.ReconfigureRequest.SerializeToString,
+            backend__pb2.Result.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def EmbeddingBatch(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_stream(request, target, '/backend.Backend/EmbeddingBatch',
+            backend__pb2.EmbeddingBatchRequest.SerializeToString,
+            backend__pb2.EmbeddingBatchResult.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def Diarize(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/backend.Backend/Diarize',
+            backend__pb2.DiarizeRequest.SerializeToString,
+            backend__pb2.DiarizeResult.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def VAD(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/backend.Backend/VAD',
+            backend__pb2.VADRequest.SerializeToString,
+            backend__pb2.VADResult.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def Upscale(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/backend.Backend/Upscale',
+            backend__pb2.UpscaleRequest.SerializeToString,
+            backend__pb2.Result.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def GenerateImageStream(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_stream(request, target, '/backend.Backend/GenerateImageStream',
+            backend__pb2.GenerateImageRequest.SerializeToString,
+            backend__pb2.ImageProgress.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
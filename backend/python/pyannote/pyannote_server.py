@@ -0,0 +1,128 @@
+#!/usr/bin/env python3
+"""
+Extra gRPC server of LocalAI finding the speakers of an audio with the diarization pipelines of pyannote.
+"""
+from concurrent import futures
+
+import argparse
+import signal
+import sys
+import os
+
+import time
+import backend_pb2
+import backend_pb2_grpc
+
+import grpc
+import torch
+from pyannote.audio import Pipeline
+
+_ONE_DAY_IN_SECONDS = 60 * 60 * 24
+
+# If MAX_WORKERS are specified in the environment use it, otherwise default to 1
+MAX_WORKERS = int(os.environ.get('PYTHON_GRPC_MAX_WORKERS', '1'))
+
+# the turns are sent in nanoseconds, as the segments of the transcriptions
+_NANOSECONDS = 1e9
+
+
+# Implement the BackendServicer class with the service methods
+class BackendServicer(backend_pb2_grpc.BackendServicer):
+    """
+    A gRPC servicer diarizing the audio with a pyannote pipeline.
+    """
+    def Health(self, request, context):
+        """
+        Returns the health status of the backend service.
+        """
+        return backend_pb2.Reply(message=bytes("OK", 'utf-8'))
+
+    def LoadModel(self, request, context):
+        """
+        Loads the diarization pipeline, by name on HuggingFace (e.g. pyannote/speaker-diarization-3.1) or from a local
+        config file.
+
+        Args:
+            request: The model options.
+            context: The gRPC context.
+
+        Returns:
+            backend_pb2.Result: The result of the loading of the pipeline.
+        """
+        try:
+            self.pipeline = Pipeline.from_pretrained(request.Model, use_auth_token=os.environ.get("HF_TOKEN"))
+            if self.pipeline is None:
+                return backend_pb2.Result(success=False, message=f"Cannot load the pipeline {request.Model}")
+            if request.CUDA and not torch.cuda.is_available():
+                return backend_pb2.Result(success=False, message="CUDA is not available")
+            if torch.cuda.is_available():
+                self.pipeline.to(torch.device("cuda"))
+        except Exception as err:
+            return backend_pb2.Result(success=False, message=f"Unexpected {err=}, {type(err)=}")
+        return backend_pb2.Result(message="Model loaded successfully", success=True)
+
+    def Diarize(self, request, context):
+        """
+        Finds the turns of the speakers of the audio.
+
+        Args:
+            request: The diarize request, with the audio file and the number of speakers if known.
+            context: The gRPC context.
+
+        Returns:
+            backend_pb2.DiarizeResult: The turns of the speakers, in order.
+        """
+        options = {}
+        if request.num_speakers > 0:
+            options["num_speakers"] = request.num_speakers
+        if request.min_speakers > 0:
+            options["min_speakers"] = request.min_speakers
+        if request.max_speakers > 0:
+            options["max_speakers"] = request.max_speakers
+
+        diarization = self.pipeline(request.dst, **options)
+        turns = [
+            backend_pb2.SpeakerTurn(start=int(turn.start * _NANOSECONDS), end=int(turn.end * _NANOSECONDS), speaker=speaker)
+            for turn, _, speaker in diarization.itertracks(yield_label=True)
+        ]
+        return backend_pb2.DiarizeResult(turns=turns)
+
+
+def serve(address):
+    # accept the keepalive pings of LocalAI down to every 5 seconds, as the Go and C++ backends do
+    server = grpc.server(
+        futures.ThreadPoolExecutor(max_workers=MAX_WORKERS),
+        options=[
+            ('grpc.http2.min_ping_interval_without_data_ms', 5000),
+            ('grpc.keepalive_permit_without_calls', 1),
+        ],
+    )
+    backend_pb2_grpc.add_BackendServicer_to_server(BackendServicer(), server)
+    server.add_insecure_port(address)
+    server.start()
+    print("Server started. Listening on: " + address, file=sys.stderr)
+
+    # Define the signal handler function
+    def signal_handler(sig, frame):
+        print("Received termination signal. Shutting down...")
+        server.stop(0)
+        sys.exit(0)
+
+    # Set the signal handlers for SIGINT and SIGTERM
+    signal.signal(signal.SIGINT, signal_handler)
+    signal.signal(signal.SIGTERM, signal_handler)
+
+    try:
+        while True:
+            time.sleep(_ONE_DAY_IN_SECONDS)
+    except KeyboardInterrupt:
+        server.stop(0)
+
+if __name__ == "__main__":
+    parser = argparse.ArgumentParser(description="Run the gRPC server.")
+    parser.add_argument(
+        "--addr", default="localhost:50051", help="The address to bind the server to."
+    )
+    args = parser.parse_args()
+
+    serve(args.addr)
@@ -0,0 +1,154 @@
+#!/usr/bin/env python3
+"""
+Extra gRPC server of LocalAI upscaling the images with the ESRGAN networks of Real-ESRGAN.
+"""
+from concurrent import futures
+
+import argparse
+import signal
+import sys
+import os
+
+import time
+import backend_pb2
+import backend_pb2_grpc
+
+import cv2
+import grpc
+import torch
+from basicsr.archs.rrdbnet_arch import RRDBNet
+from realesrgan import RealESRGANer
+
+_ONE_DAY_IN_SECONDS = 60 * 60 * 24
+
+# If MAX_WORKERS are specified in the environment use it, otherwise default to 1
+MAX_WORKERS = int(os.environ.get('PYTHON_GRPC_MAX_WORKERS', '1'))
+
+# the version of backend.proto spoken by the backend, ProtocolVersion of pkg/grpc/version.go
+PROTOCOL_VERSION = 22
+
+# the scale of the networks by the number of their input channels, the images are pixel unshuffled by the networks
+# of the scales below 4
+_SCALES = {3: 4, 12: 2, 48: 1}
+
+
+def network(weights):
+    """
+    Returns the RRDBNet network of the weights, and its scale.
+    """
+    state = torch.load(weights, map_location="cpu")
+    state = state.get("params_ema", state.get("params", state))
+    conv_first = state["conv_first.weight"]
+    scale = _SCALES[conv_first.shape[1]]
+    num_block = len({key.split(".")[1] for key in state if key.startswith("body.")})
+    model = RRDBNet(num_in_ch=3, num_out_ch=3, num_feat=conv_first.shape[0], num_block=num_block, num_grow_ch=32, scale=scale)
+    return model, scale
+
+
+# Implement the BackendServicer class with the service methods
+class BackendServicer(backend_pb2_grpc.BackendServicer):
+    """
+    A gRPC servicer upscaling the images with Real-ESRGAN.
+    """
+    def Health(self, request, context):
+        """
+        Returns the health status of the backend service.
+        """
+        return backend_pb2.Reply(message=bytes("OK", 'utf-8'))
+
+    def Capabilities(self, request, context):
+        """
+        Returns the protocol version of the backend, and the images upscaling.
+        """
+        return backend_pb2.CapabilitiesResponse(protocol_version=PROTOCOL_VERSION, capabilities=["upscale"])
+
+    def LoadModel(self, request, context):
+        """
+        Loads the weights of the network of the model.
+
+        Args:
+            request: The model options.
+            context: The gRPC context.
+
+        Returns:
+            backend_pb2.Result: The result of the loading of the model.
+        """
+        try:
+            weights = request.ModelFile if os.path.isfile(request.ModelFile) else request.Model
+            model, scale = network(weights)
+            if request.Threads > 0:
+                torch.set_num_threads(request.Threads)
+            cuda = request.CUDA and torch.cuda.is_available()
+            self.upsampler = RealESRGANer(
+                scale=scale,
+                model_path=weights,
+                model=model,
+                half=cuda and request.F16Memory,
+                device=torch.device("cuda" if cuda else "cpu"),
+            )
+        except Exception as err:
+            return backend_pb2.Result(success=False, message=f"Unexpected {err=}, {type(err)=}")
+        return backend_pb2.Result(message="Model loaded successfully", success=True)
+
+    def Upscale(self, request, context):
+        """
+        Upscales the image of src to dst.
+
+        Args:
+            request: The upscale request, with the scale of the image (the one of the network by default) and the
+                size of the tiles it is upscaled by (0 to upscale it at once).
+            context: The gRPC context.
+
+        Returns:
+            backend_pb2.Result: The result of the upscaling.
+        """
+        img = cv2.imread(request.src, cv2.IMREAD_UNCHANGED)
+        if img is None:
+            return backend_pb2.Result(success=False, message="cannot read the image")
+        self.upsampler.tile_size = request.tile
+        try:
+            output, _ = self.upsampler.enhance(img, outscale=request.scale or None)
+        except RuntimeError as err:
+            return backend_pb2.Result(success=False, message=f"cannot upscale the image, set a tile size to use less memory: {err}")
+        cv2.imwrite(request.dst, output)
+        return backend_pb2.Result(message="Image upscaled", success=True)
+
+
+def serve(address):
+    # accept the keepalive pings of LocalAI down to every 5 seconds, as the Go and C++ backends do
+    server = grpc.server(
+        futures.ThreadPoolExecutor(max_workers=MAX_WORKERS),
+        options=[
+            ('grpc.http2.min_ping_interval_without_data_ms', 5000),
+            ('grpc.keepalive_permit_without_calls', 1),
+        ],
+    )
+    backend_pb2_grpc.add_BackendServicer_to_server(BackendServicer(), server)
+    server.add_insecure_port(address)
+    server.start()
+    print("Server started. Listening on: " + address, file=sys.stderr)
+
+    # Define the signal handler function
+    def signal_handler(sig, frame):
+        print("Received termination signal. Shutting down...")
+        server.stop(0)
+        sys.exit(0)
+
+    # Set the signal handlers for SIGINT and SIGTERM
+    signal.signal(signal.SIGINT, signal_handler)
+    signal.signal(signal.SIGTERM, signal_handler)
+
+    try:
+        while True:
+            time.sleep(_ONE_DAY_IN_SECONDS)
+    except KeyboardInterrupt:
+        server.stop(0)
+
+if __name__ == "__main__":
+    parser = argparse.ArgumentParser(description="Run the gRPC server.")
+    parser.add_argument(
+        "--addr", default="localhost:50051", help="The address to bind the server to."
+    )
+    args = parser.parse_args()
+
+    serve(args.addr)
@@ -77,12 +77,49 @@ class BackendServicer(backend_pb2_grpc.BackendServicer):
         # Implement your logic here for the Embedding service
         # Replace this with your desired response
         print("Calculated embeddings for: " + request.Embeddings, file=sys.stderr)
+        if request.TokenEmbeddings:
+            # the embeddings of the tokens are pooled by LocalAI
+            token_embeddings = self.model.encode(request.Embeddings, output_value="token_embeddings").cpu().numpy()
+            return backend_pb2.EmbeddingResult(embeddings=token_embeddings.flatten(), tokens=token_embeddings.shape[0])
         sentence_embeddings = self.model.encode(request.Embeddings)
         return backend_pb2.EmbeddingResult(embeddings=sentence_embeddings)
 
+    def EmbeddingBatch(self, request, context):
+        """
+        A gRPC method that calculates the embeddings of several sentences at once, streamed as they are calculated.
+
+        Args:
+            request: An EmbeddingBatchRequest object that contains the sentences and the request parameters.
+            context: A grpc.ServicerContext object that provides information about the RPC.
+
+        Yields:
+            EmbeddingBatchResult objects with the index of each sentence and its embeddings.
+        """
+        if len(request.Tokens) > 0:
+            context.abort(grpc.StatusCode.UNIMPLEMENTED, "the embeddings of tokens are not supported")
+        batch_size = 32
+        for start in range(0, len(request.Inputs), batch_size):
+            sentences = list(request.Inputs[start:start + batch_size])
+            if request.Options.TokenEmbeddings:
+                # the embeddings of the tokens are pooled by LocalAI
+                for i, token_embeddings in enumerate(self.model.encode(sentences, output_value="token_embeddings")):
+                    token_embeddings = token_embeddings.cpu().numpy()
+                    result = backend_pb2.EmbeddingResult(embeddings=token_embeddings.flatten(), tokens=token_embeddings.shape[0])
+                    yield backend_pb2.EmbeddingBatchResult(Index=start + i, Result=result)
+                continue
+            for i, embeddings in enumerate(self.model.encode(sentences, batch_size=batch_size)):
+                yield backend_pb2.EmbeddingBatchResult(Index=start + i, Result=backend_pb2.EmbeddingResult(embeddings=embeddings))
+
 
 def serve(address):
-    server = grpc.server(futures.ThreadPoolExecutor(max_workers=MAX_WORKERS))
+    # accept the keepalive pings of LocalAI down to every 5 seconds, as the Go and C++ backends do
+    server = grpc.server(
+        futures.ThreadPoolExecutor(max_workers=MAX_WORKERS),
+        options=[
+            ('grpc.http2.min_ping_interval_without_data_ms', 5000),
+            ('grpc.keepalive_permit_without_calls', 1),
+        ],
+    )
     backend_pb2_grpc.add_BackendServicer_to_server(BackendServicer(), server)
     server.add_insecure_port(address)
     server.start()
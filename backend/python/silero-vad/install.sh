@@ -0,0 +1,19 @@
+#!/bin/bash
+
+##
+## A bash script installs the required dependencies of silero-vad and generates the gRPC code of the backend
+export PATH=$PATH:/opt/conda/bin
+
+# Activate conda environment
+source activate transformers
+
+# get the directory where the bash script is located
+DIR="$( cd "$( dirname "${BASH_SOURCE[0]}" )" >/dev/null 2>&1 && pwd )"
+
+pip install grpcio-tools==1.59.0
+
+python -m grpc_tools.protoc -I$DIR/../.. --python_out=$DIR --grpc_python_out=$DIR backend.proto
+
+if [ "$PIP_CACHE_PURGE" = true ] ; then
+    pip cache purge
+fi
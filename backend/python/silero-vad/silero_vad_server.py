@@ -0,0 +1,132 @@
+#!/usr/bin/env python3
+"""
+Extra gRPC server of LocalAI finding the speech of an audio with the voice activity detection model of silero.
+"""
+from concurrent import futures
+
+import argparse
+import signal
+import sys
+import os
+
+import time
+import backend_pb2
+import backend_pb2_grpc
+
+import grpc
+import torch
+
+_ONE_DAY_IN_SECONDS = 60 * 60 * 24
+
+# If MAX_WORKERS are specified in the environment use it, otherwise default to 1
+MAX_WORKERS = int(os.environ.get('PYTHON_GRPC_MAX_WORKERS', '1'))
+
+# the segments are sent in nanoseconds, as the segments of the transcriptions
+_NANOSECONDS = 1e9
+
+# the sample rate of the audio sent by LocalAI, one of the rates supported by silero
+_SAMPLING_RATE = 16000
+
+
+# Implement the BackendServicer class with the service methods
+class BackendServicer(backend_pb2_grpc.BackendServicer):
+    """
+    A gRPC servicer finding the speech of the audio with silero.
+    """
+    def Health(self, request, context):
+        """
+        Returns the health status of the backend service.
+        """
+        return backend_pb2.Reply(message=bytes("OK", 'utf-8'))
+
+    def LoadModel(self, request, context):
+        """
+        Loads the VAD model, from a local clone of the silero-vad repository or from GitHub
+        (snakers4/silero-vad by default).
+
+        Args:
+            request: The model options.
+            context: The gRPC context.
+
+        Returns:
+            backend_pb2.Result: The result of the loading of the model.
+        """
+        try:
+            if os.path.isdir(request.ModelFile):
+                repo, source = request.ModelFile, "local"
+            else:
+                repo, source = request.Model or "snakers4/silero-vad", "github"
+            self.model, utils = torch.hub.load(repo_or_dir=repo, model="silero_vad", source=source, trust_repo=True)
+            self.get_speech_timestamps, _, self.read_audio, _, _ = utils
+        except Exception as err:
+            return backend_pb2.Result(success=False, message=f"Unexpected {err=}, {type(err)=}")
+        return backend_pb2.Result(message="Model loaded successfully", success=True)
+
+    def VAD(self, request, context):
+        """
+        Finds the parts of the audio with speech.
+
+        Args:
+            request: The VAD request, with the audio file and the settings overriding the defaults of silero.
+            context: The gRPC context.
+
+        Returns:
+            backend_pb2.VADResult: The segments with speech, in order.
+        """
+        if request.threads > 0:
+            torch.set_num_threads(request.threads)
+        options = {}
+        if request.threshold > 0:
+            options["threshold"] = request.threshold
+        if request.min_silence_ms > 0:
+            options["min_silence_duration_ms"] = request.min_silence_ms
+        if request.min_speech_ms > 0:
+            options["min_speech_duration_ms"] = request.min_speech_ms
+
+        wav = self.read_audio(request.dst, sampling_rate=_SAMPLING_RATE)
+        timestamps = self.get_speech_timestamps(wav, self.model, sampling_rate=_SAMPLING_RATE, **options)
+        segments = [
+            backend_pb2.VADSegment(start=int(t["start"] * _NANOSECONDS / _SAMPLING_RATE), end=int(t["end"] * _NANOSECONDS / _SAMPLING_RATE))
+            for t in timestamps
+        ]
+        return backend_pb2.VADResult(segments=segments)
+
+
+def serve(address):
+    # accept the keepalive pings of LocalAI down to every 5 seconds, as the Go and C++ backends do
+    server = grpc.server(
+        futures.ThreadPoolExecutor(max_workers=MAX_WORKERS),
+        options=[
+            ('grpc.http2.min_ping_interval_without_data_ms', 5000),
+            ('grpc.keepalive_permit_without_calls', 1),
+        ],
+    )
+    backend_pb2_grpc.add_BackendServicer_to_server(BackendServicer(), server)
+    server.add_insecure_port(address)
+    server.start()
+    print("Server started. Listening on: " + address, file=sys.stderr)
+
+    # Define the signal handler function
+    def signal_handler(sig, frame):
+        print("Received termination signal. Shutting down...")
+        server.stop(0)
+        sys.exit(0)
+
+    # Set the signal handlers for SIGINT and SIGTERM
+    signal.signal(signal.SIGINT, signal_handler)
+    signal.signal(signal.SIGTERM, signal_handler)
+
+    try:
+        while True:
+            time.sleep(_ONE_DAY_IN_SECONDS)
+    except KeyboardInterrupt:
+        server.stop(0)
+
+if __name__ == "__main__":
+    parser = argparse.ArgumentParser(description="Run the gRPC server.")
+    parser.add_argument(
+        "--addr", default="localhost:50051", help="The address to bind the server to."
+    )
+    args = parser.parse_args()
+
+    serve(args.addr)
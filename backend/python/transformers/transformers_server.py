@@ -16,7 +16,7 @@ import backend_pb2_grpc
 import grpc
 import torch
 import torch.cuda
-from transformers import AutoTokenizer, AutoModel, AutoModelForCausalLM, set_seed
+from transformers import AutoTokenizer, AutoModel, AutoModelForCausalLM, AutoModelForSequenceClassification, set_seed
 
 _ONE_DAY_IN_SECONDS = 60 * 60 * 24
 
@@ -70,6 +70,8 @@ class BackendServicer(backend_pb2_grpc.BackendServicer):
         try:
             if request.Type == "AutoModelForCausalLM":
                 self.model = AutoModelForCausalLM.from_pretrained(model_name, trust_remote_code=True)
+            elif request.Type == "AutoModelForSequenceClassification":
+                self.model = AutoModelForSequenceClassification.from_pretrained(model_name, trust_remote_code=True)
             else:
                 self.model = AutoModel.from_pretrained(model_name, trust_remote_code=True)
 
@@ -111,6 +113,12 @@ class BackendServicer(backend_pb2_grpc.BackendServicer):
         # Create word embeddings
         model_output = self.model(**encoded_input)
 
+        if request.TokenEmbeddings:
+            # the embeddings of the tokens are pooled by LocalAI
+            mask = encoded_input['attention_mask'][0].bool()
+            token_embeddings = model_output[0][0][mask].detach().cpu().numpy()
+            return backend_pb2.EmbeddingResult(embeddings=token_embeddings.flatten(), tokens=token_embeddings.shape[0])
+
         # Pool to get sentence embeddings; i.e. generate one 1024 vector for the entire sentence
         sentence_embeddings = mean_pooling(model_output, encoded_input['attention_mask']).detach().numpy()
         print("Calculated embeddings for: " + request.Embeddings, file=sys.stderr)
@@ -162,9 +170,43 @@ class BackendServicer(backend_pb2_grpc.BackendServicer):
         """
         yield self.Predict(request, context)
 
+    def Classify(self, request, context):
+        """
+        Classifies the text with a sequence classification model.
+
+        Args:
+            request: The classify request.
+            context: The gRPC context.
+
+        Returns:
+            backend_pb2.ClassifyResult: The scores of all the labels of the model.
+        """
+        inputs = self.tokenizer(request.text, truncation=True, return_tensors="pt")
+        if self.CUDA:
+            inputs = inputs.to("cuda")
+
+        with torch.no_grad():
+            logits = self.model(**inputs).logits[0]
+
+        # multi-label models score each label independently
+        if self.model.config.problem_type == "multi_label_classification":
+            scores = torch.sigmoid(logits)
+        else:
+            scores = torch.softmax(logits, dim=-1)
+
+        labels = [backend_pb2.ClassifyLabel(label=self.model.config.id2label[i], score=float(score)) for i, score in enumerate(scores)]
+        return backend_pb2.ClassifyResult(labels=labels)
+
 
 def serve(address):
-    server = grpc.server(futures.ThreadPoolExecutor(max_workers=MAX_WORKERS))
+    # accept the keepalive pings of LocalAI down to every 5 seconds, as the Go and C++ backends do
+    server = grpc.server(
+        futures.ThreadPoolExecutor(max_workers=MAX_WORKERS),
+        options=[
+            ('grpc.http2.min_ping_interval_without_data_ms', 5000),
+            ('grpc.keepalive_permit_without_calls', 1),
+        ],
+    )
     backend_pb2_grpc.add_BackendServicer_to_server(BackendServicer(), server)
     server.add_insecure_port(address)
     server.start()
@@ -13,7 +13,7 @@ _sym_db = _symbol_database.Default()
 
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\rbackend.proto\x12\x07\x62\x61\x63kend\"\x0f\n\rHealthMessage\"\xa6\x06\n\x0ePredictOptions\x12\x0e\n\x06Prompt\x18\x01 \x01(\t\x12\x0c\n\x04Seed\x18\x02 \x01(\x05\x12\x0f\n\x07Threads\x18\x03 \x01(\x05\x12\x0e\n\x06Tokens\x18\x04 \x01(\x05\x12\x0c\n\x04TopK\x18\x05 \x01(\x05\x12\x0e\n\x06Repeat\x18\x06 \x01(\x05\x12\r\n\x05\x42\x61tch\x18\x07 \x01(\x05\x12\r\n\x05NKeep\x18\x08 \x01(\x05\x12\x13\n\x0bTemperature\x18\t \x01(\x02\x12\x0f\n\x07Penalty\x18\n \x01(\x02\x12\r\n\x05\x46\x31\x36KV\x18\x0b \x01(\x08\x12\x11\n\tDebugMode\x18\x0c \x01(\x08\x12\x13\n\x0bStopPrompts\x18\r \x03(\t\x12\x11\n\tIgnoreEOS\x18\x0e \x01(\x08\x12\x19\n\x11TailFreeSamplingZ\x18\x0f \x01(\x02\x12\x10\n\x08TypicalP\x18\x10 \x01(\x02\x12\x18\n\x10\x46requencyPenalty\x18\x11 \x01(\x02\x12\x17\n\x0fPresencePenalty\x18\x12 \x01(\x02\x12\x10\n\x08Mirostat\x18\x13 \x01(\x05\x12\x13\n\x0bMirostatETA\x18\x14 \x01(\x02\x12\x13\n\x0bMirostatTAU\x18\x15 \x01(\x02\x12\x12\n\nPenalizeNL\x18\x16 \x01(\x08\x12\x11\n\tLogitBias\x18\x17 \x01(\t\x12\r\n\x05MLock\x18\x19 \x01(\x08\x12\x0c\n\x04MMap\x18\x1a \x01(\x08\x12\x16\n\x0ePromptCacheAll\x18\x1b \x01(\x08\x12\x15\n\rPromptCacheRO\x18\x1c \x01(\x08\x12\x0f\n\x07Grammar\x18\x1d \x01(\t\x12\x0f\n\x07MainGPU\x18\x1e \x01(\t\x12\x13\n\x0bTensorSplit\x18\x1f \x01(\t\x12\x0c\n\x04TopP\x18  \x01(\x02\x12\x17\n\x0fPromptCachePath\x18! \x01(\t\x12\r\n\x05\x44\x65\x62ug\x18\" \x01(\x08\x12\x17\n\x0f\x45mbeddingTokens\x18# \x03(\x05\x12\x12\n\nEmbeddings\x18$ \x01(\t\x12\x14\n\x0cRopeFreqBase\x18% \x01(\x02\x12\x15\n\rRopeFreqScale\x18& \x01(\x02\x12\x1b\n\x13NegativePromptScale\x18\' \x01(\x02\x12\x16\n\x0eNegativePrompt\x18( \x01(\t\x12\x0e\n\x06NDraft\x18) \x01(\x05\x12\x0e\n\x06Images\x18* \x03(\t\"\x18\n\x05Reply\x12\x0f\n\x07message\x18\x01 \x01(\x0c\"\xbb\x07\n\x0cModelOptions\x12\r\n\x05Model\x18\x01 \x01(\t\x12\x13\n\x0b\x43ontextSize\x18\x02 \x01(\x05\x12\x0c\n\x04Seed\x18\x03 \x01(\x05\x12\x0e\n\x06NBatch\x18\x04 \x01(\x05\x12\x11\n\tF16Memory\x18\x05 \x01(\x08\x12\r\n\x05MLock\x18\x06 \x01(\x08\x12\x0c\n\x04MMap\x18\x07 \x01(\x08\x12\x11\n\tVocabOnly\x18\x08 \x01(\x08\x12\x0f\n\x07LowVRAM\x18\t \x01(\x08\x12\x12\n\nEmbeddings\x18\n \x01(\x08\x12\x0c\n\x04NUMA\x18\x0b \x01(\x08\x12\x12\n\nNGPULayers\x18\x0c \x01(\x05\x12\x0f\n\x07MainGPU\x18\r \x01(\t\x12\x13\n\x0bTensorSplit\x18\x0e \x01(\t\x12\x0f\n\x07Threads\x18\x0f \x01(\x05\x12\x19\n\x11LibrarySearchPath\x18\x10 \x01(\t\x12\x14\n\x0cRopeFreqBase\x18\x11 \x01(\x02\x12\x15\n\rRopeFreqScale\x18\x12 \x01(\x02\x12\x12\n\nRMSNormEps\x18\x13 \x01(\x02\x12\x0c\n\x04NGQA\x18\x14 \x01(\x05\x12\x11\n\tModelFile\x18\x15 \x01(\t\x12\x0e\n\x06\x44\x65vice\x18\x16 \x01(\t\x12\x11\n\tUseTriton\x18\x17 \x01(\x08\x12\x15\n\rModelBaseName\x18\x18 \x01(\t\x12\x18\n\x10UseFastTokenizer\x18\x19 \x01(\x08\x12\x14\n\x0cPipelineType\x18\x1a \x01(\t\x12\x15\n\rSchedulerType\x18\x1b \x01(\t\x12\x0c\n\x04\x43UDA\x18\x1c \x01(\x08\x12\x10\n\x08\x43\x46GScale\x18\x1d \x01(\x02\x12\x0f\n\x07IMG2IMG\x18\x1e \x01(\x08\x12\x11\n\tCLIPModel\x18\x1f \x01(\t\x12\x15\n\rCLIPSubfolder\x18  \x01(\t\x12\x10\n\x08\x43LIPSkip\x18! \x01(\x05\x12\x12\n\nControlNet\x18\x30 \x01(\t\x12\x11\n\tTokenizer\x18\" \x01(\t\x12\x10\n\x08LoraBase\x18# \x01(\t\x12\x13\n\x0bLoraAdapter\x18$ \x01(\t\x12\x11\n\tLoraScale\x18* \x01(\x02\x12\x11\n\tNoMulMatQ\x18% \x01(\x08\x12\x12\n\nDraftModel\x18\' \x01(\t\x12\x11\n\tAudioPath\x18& \x01(\t\x12\x14\n\x0cQuantization\x18( \x01(\t\x12\x0e\n\x06MMProj\x18) \x01(\t\x12\x13\n\x0bRopeScaling\x18+ \x01(\t\x12\x15\n\rYarnExtFactor\x18, \x01(\x02\x12\x16\n\x0eYarnAttnFactor\x18- \x01(\x02\x12\x14\n\x0cYarnBetaFast\x18. \x01(\x02\x12\x14\n\x0cYarnBetaSlow\x18/ \x01(\x02\x12\x0c\n\x04Type\x18\x31 \x01(\t\"*\n\x06Result\x12\x0f\n\x07message\x18\x01 \x01(\t\x12\x0f\n\x07success\x18\x02 \x01(\x08\"%\n\x0f\x45mbeddingResult\x12\x12\n\nembeddings\x18\x01 \x03(\x02\"C\n\x11TranscriptRequest\x12\x0b\n\x03\x64st\x18\x02 \x01(\t\x12\x10\n\x08language\x18\x03 \x01(\t\x12\x0f\n\x07threads\x18\x04 \x01(\r\"N\n\x10TranscriptResult\x12,\n\x08segments\x18\x01 \x03(\x0b\x32\x1a.backend.TranscriptSegment\x12\x0c\n\x04text\x18\x02 \x01(\t\"Y\n\x11TranscriptSegment\x12\n\n\x02id\x18\x01 \x01(\x05\x12\r\n\x05start\x18\x02 \x01(\x03\x12\x0b\n\x03\x65nd\x18\x03 \x01(\x03\x12\x0c\n\x04text\x18\x04 \x01(\t\x12\x0e\n\x06tokens\x18\x05 \x03(\x05\"\xd7\x01\n\x14GenerateImageRequest\x12\x0e\n\x06height\x18\x01 \x01(\x05\x12\r\n\x05width\x18\x02 \x01(\x05\x12\x0c\n\x04mode\x18\x03 \x01(\x05\x12\x0c\n\x04step\x18\x04 \x01(\x05\x12\x0c\n\x04seed\x18\x05 \x01(\x05\x12\x17\n\x0fpositive_prompt\x18\x06 \x01(\t\x12\x17\n\x0fnegative_prompt\x18\x07 \x01(\t\x12\x0b\n\x03\x64st\x18\x08 \x01(\t\x12\x0b\n\x03src\x18\t \x01(\t\x12\x18\n\x10\x45nableParameters\x18\n \x01(\t\x12\x10\n\x08\x43LIPSkip\x18\x0b \x01(\x05\"6\n\nTTSRequest\x12\x0c\n\x04text\x18\x01 \x01(\t\x12\r\n\x05model\x18\x02 \x01(\t\x12\x0b\n\x03\x64st\x18\x03 \x01(\t\"6\n\x14TokenizationResponse\x12\x0e\n\x06length\x18\x01 \x01(\x05\x12\x0e\n\x06tokens\x18\x02 \x03(\x05\"\x8e\x01\n\x0fMemoryUsageData\x12\r\n\x05total\x18\x01 \x01(\x04\x12:\n\tbreakdown\x18\x02 \x03(\x0b\x32\'.backend.MemoryUsageData.BreakdownEntry\x1a\x30\n\x0e\x42reakdownEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x04:\x02\x38\x01\"\xad\x01\n\x0eStatusResponse\x12,\n\x05state\x18\x01 \x01(\x0e\x32\x1d.backend.StatusResponse.State\x12(\n\x06memory\x18\x02 \x01(\x0b\x32\x18.backend.MemoryUsageData\"C\n\x05State\x12\x11\n\rUNINITIALIZED\x10\x00\x12\x08\n\x04\x42USY\x10\x01\x12\t\n\x05READY\x10\x02\x12\x12\n\x05\x45RROR\x10\xff\xff\xff\xff\xff\xff\xff\xff\xff\x01\x32\xf4\x04\n\x07\x42\x61\x63kend\x12\x32\n\x06Health\x12\x16.backend.HealthMessage\x1a\x0e.backend.Reply\"\x00\x12\x34\n\x07Predict\x12\x17.backend.PredictOptions\x1a\x0e.backend.Reply\"\x00\x12\x35\n\tLoadModel\x12\x15.backend.ModelOptions\x1a\x0f.backend.Result\"\x00\x12<\n\rPredictStream\x12\x17.backend.PredictOptions\x1a\x0e.backend.Reply\"\x00\x30\x01\x12@\n\tEmbedding\x12\x17.backend.PredictOptions\x1a\x18.backend.EmbeddingResult\"\x00\x12\x41\n\rGenerateImage\x12\x1d.backend.GenerateImageRequest\x1a\x0f.backend.Result\"\x00\x12M\n\x12\x41udioTranscription\x12\x1a.backend.TranscriptRequest\x1a\x19.backend.TranscriptResult\"\x00\x12-\n\x03TTS\x12\x13.backend.TTSRequest\x1a\x0f.backend.Result\"\x00\x12J\n\x0eTokenizeString\x12\x17.backend.PredictOptions\x1a\x1d.backend.TokenizationResponse\"\x00\x12;\n\x06Status\x12\x16.backend.HealthMessage\x1a\x17.backend.StatusResponse\"\x00\x42Z\n\x19io.skynet.localai.backendB\x0eLocalAIBackendP\x01Z+github.com/go-skynet/LocalAI/pkg/grpc/protob\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\rbackend.proto\x12\x07\x62\x61\x63kend\"\x0f\n\rHealthMessage\"\xf4\x06\n\x0ePredictOptions\x12\x0e\n\x06Prompt\x18\x01 \x01(\t\x12\x0c\n\x04Seed\x18\x02 \x01(\x05\x12\x0f\n\x07Threads\x18\x03 \x01(\x05\x12\x0e\n\x06Tokens\x18\x04 \x01(\x05\x12\x0c\n\x04TopK\x18\x05 \x01(\x05\x12\x0e\n\x06Repeat\x18\x06 \x01(\x05\x12\r\n\x05\x42\x61tch\x18\x07 \x01(\x05\x12\r\n\x05NKeep\x18\x08 \x01(\x05\x12\x13\n\x0bTemperature\x18\t \x01(\x02\x12\x0f\n\x07Penalty\x18\n \x01(\x02\x12\r\n\x05\x46\x31\x36KV\x18\x0b \x01(\x08\x12\x11\n\tDebugMode\x18\x0c \x01(\x08\x12\x13\n\x0bStopPrompts\x18\r \x03(\t\x12\x11\n\tIgnoreEOS\x18\x0e \x01(\x08\x12\x19\n\x11TailFreeSamplingZ\x18\x0f \x01(\x02\x12\x10\n\x08TypicalP\x18\x10 \x01(\x02\x12\x18\n\x10\x46requencyPenalty\x18\x11 \x01(\x02\x12\x17\n\x0fPresencePenalty\x18\x12 \x01(\x02\x12\x10\n\x08Mirostat\x18\x13 \x01(\x05\x12\x13\n\x0bMirostatETA\x18\x14 \x01(\x02\x12\x13\n\x0bMirostatTAU\x18\x15 \x01(\x02\x12\x12\n\nPenalizeNL\x18\x16 \x01(\x08\x12\x11\n\tLogitBias\x18\x17 \x01(\t\x12\r\n\x05MLock\x18\x19 \x01(\x08\x12\x0c\n\x04MMap\x18\x1a \x01(\x08\x12\x16\n\x0ePromptCacheAll\x18\x1b \x01(\x08\x12\x15\n\rPromptCacheRO\x18\x1c \x01(\x08\x12\x0f\n\x07Grammar\x18\x1d \x01(\t\x12\x0f\n\x07MainGPU\x18\x1e \x01(\t\x12\x13\n\x0bTensorSplit\x18\x1f \x01(\t\x12\x0c\n\x04TopP\x18  \x01(\x02\x12\x17\n\x0fPromptCachePath\x18! \x01(\t\x12\r\n\x05\x44\x65\x62ug\x18\" \x01(\x08\x12\x17\n\x0f\x45mbeddingTokens\x18# \x03(\x05\x12\x12\n\nEmbeddings\x18$ \x01(\t\x12\x14\n\x0cRopeFreqBase\x18% \x01(\x02\x12\x15\n\rRopeFreqScale\x18& \x01(\x02\x12\x1b\n\x13NegativePromptScale\x18\' \x01(\x02\x12\x16\n\x0eNegativePrompt\x18( \x01(\t\x12\x0e\n\x06NDraft\x18) \x01(\x05\x12\x0e\n\x06Images\x18* \x03(\t\x12\x10\n\x08Logprobs\x18+ \x01(\x08\x12\x13\n\x0bTopLogprobs\x18, \x01(\x05\x12\x17\n\x0fTokenEmbeddings\x18- \x01(\x08\x12\x0c\n\x04Slot\x18. \x01(\x05\"2\n\x10TokenProbability\x12\r\n\x05token\x18\x01 \x01(\t\x12\x0f\n\x07logprob\x18\x02 \x01(\x02\"_\n\x0cTokenLogprob\x12\r\n\x05token\x18\x01 \x01(\t\x12\x0f\n\x07logprob\x18\x02 \x01(\x02\x12/\n\x0ctop_logprobs\x18\x03 \x03(\x0b\x32\x19.backend.TokenProbability\"r\n\x05Reply\x12\x0f\n\x07message\x18\x01 \x01(\x0c\x12\'\n\x08logprobs\x18\x02 \x03(\x0b\x32\x15.backend.TokenLogprob\x12\x15\n\rtokens_cached\x18\x03 \x01(\x05\x12\x18\n\x10tokens_evaluated\x18\x04 \x01(\x05\"\x9d\t\n\x0cModelOptions\x12\r\n\x05Model\x18\x01 \x01(\t\x12\x13\n\x0b\x43ontextSize\x18\x02 \x01(\x05\x12\x0c\n\x04Seed\x18\x03 \x01(\x05\x12\x0e\n\x06NBatch\x18\x04 \x01(\x05\x12\x11\n\tF16Memory\x18\x05 \x01(\x08\x12\r\n\x05MLock\x18\x06 \x01(\x08\x12\x0c\n\x04MMap\x18\x07 \x01(\x08\x12\x11\n\tVocabOnly\x18\x08 \x01(\x08\x12\x0f\n\x07LowVRAM\x18\t \x01(\x08\x12\x12\n\nEmbeddings\x18\n \x01(\x08\x12\x0c\n\x04NUMA\x18\x0b \x01(\x08\x12\x12\n\nNGPULayers\x18\x0c \x01(\x05\x12\x0f\n\x07MainGPU\x18\r \x01(\t\x12\x13\n\x0bTensorSplit\x18\x0e \x01(\t\x12\x0f\n\x07Threads\x18\x0f \x01(\x05\x12\x19\n\x11LibrarySearchPath\x18\x10 \x01(\t\x12\x14\n\x0cRopeFreqBase\x18\x11 \x01(\x02\x12\x15\n\rRopeFreqScale\x18\x12 \x01(\x02\x12\x12\n\nRMSNormEps\x18\x13 \x01(\x02\x12\x0c\n\x04NGQA\x18\x14 \x01(\x05\x12\x11\n\tModelFile\x18\x15 \x01(\t\x12\x0e\n\x06\x44\x65vice\x18\x16 \x01(\t\x12\x11\n\tUseTriton\x18\x17 \x01(\x08\x12\x15\n\rModelBaseName\x18\x18 \x01(\t\x12\x18\n\x10UseFastTokenizer\x18\x19 \x01(\x08\x12\x14\n\x0cPipelineType\x18\x1a \x01(\t\x12\x15\n\rSchedulerType\x18\x1b \x01(\t\x12\x0c\n\x04\x43UDA\x18\x1c \x01(\x08\x12\x10\n\x08\x43\x46GScale\x18\x1d \x01(\x02\x12\x0f\n\x07IMG2IMG\x18\x1e \x01(\x08\x12\x11\n\tCLIPModel\x18\x1f \x01(\t\x12\x15\n\rCLIPSubfolder\x18  \x01(\t\x12\x10\n\x08\x43LIPSkip\x18! \x01(\x05\x12\x12\n\nControlNet\x18\x30 \x01(\t\x12\x11\n\tTokenizer\x18\" \x01(\t\x12\x10\n\x08LoraBase\x18# \x01(\t\x12\x13\n\x0bLoraAdapter\x18$ \x01(\t\x12\x11\n\tLoraScale\x18* \x01(\x02\x12\x11\n\tNoMulMatQ\x18% \x01(\x08\x12\x12\n\nDraftModel\x18\' \x01(\t\x12\x11\n\tAudioPath\x18& \x01(\t\x12\x14\n\x0cQuantization\x18( \x01(\t\x12\x0e\n\x06MMProj\x18) \x01(\t\x12\x13\n\x0bRopeScaling\x18+ \x01(\t\x12\x15\n\rYarnExtFactor\x18, \x01(\x02\x12\x16\n\x0eYarnAttnFactor\x18- \x01(\x02\x12\x14\n\x0cYarnBetaFast\x18. \x01(\x02\x12\x14\n\x0cYarnBetaSlow\x18/ \x01(\x02\x12\x0c\n\x04Type\x18\x31 \x01(\t\x12\x10\n\x08Parallel\x18\x32 \x01(\x05\x12;\n\x0b\x43ontrolNets\x18\x33 \x03(\x0b\x32&.backend.ModelOptions.ControlNetsEntry\x12/\n\x05Loras\x18\x34 \x03(\x0b\x32 .backend.ModelOptions.LorasEntry\x1a\x32\n\x10\x43ontrolNetsEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\x1a,\n\nLorasEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"*\n\x06Result\x12\x0f\n\x07message\x18\x01 \x01(\t\x12\x0f\n\x07success\x18\x02 \x01(\x08\"&\n\x12ReconfigureRequest\x12\x10\n\x08Parallel\x18\x01 \x01(\x05\"5\n\x0f\x45mbeddingResult\x12\x12\n\nembeddings\x18\x01 \x03(\x02\x12\x0e\n\x06tokens\x18\x02 \x01(\x05\"!\n\x0f\x45mbeddingTokens\x12\x0e\n\x06Tokens\x18\x01 \x03(\x05\"{\n\x15\x45mbeddingBatchRequest\x12(\n\x07Options\x18\x01 \x01(\x0b\x32\x17.backend.PredictOptions\x12\x0e\n\x06Inputs\x18\x02 \x03(\t\x12(\n\x06Tokens\x18\x03 \x03(\x0b\x32\x18.backend.EmbeddingTokens\"O\n\x14\x45mbeddingBatchResult\x12\r\n\x05Index\x18\x01 \x01(\x05\x12(\n\x06Result\x18\x02 \x01(\x0b\x32\x18.backend.EmbeddingResult\"o\n\x11TranscriptRequest\x12\x0b\n\x03\x64st\x18\x02 \x01(\t\x12\x10\n\x08language\x18\x03 \x01(\t\x12\x0f\n\x07threads\x18\x04 \x01(\r\x12\x11\n\ttranslate\x18\x05 \x01(\x08\x12\x17\n\x0fword_timestamps\x18\x06 \x01(\x08\"\xa6\x01\n\x10TranscriptResult\x12,\n\x08segments\x18\x01 \x03(\x0b\x32\x1a.backend.TranscriptSegment\x12\x0c\n\x04text\x18\x02 \x01(\t\x12&\n\x05words\x18\x03 \x03(\x0b\x32\x17.backend.TranscriptWord\x12\x10\n\x08language\x18\x04 \x01(\t\x12\x1c\n\x14language_probability\x18\x05 \x01(\x02\":\n\x0eTranscriptWord\x12\x0c\n\x04word\x18\x01 \x01(\t\x12\r\n\x05start\x18\x02 \x01(\x03\x12\x0b\n\x03\x65nd\x18\x03 \x01(\x03\"\x89\x01\n\x11TranscriptSegment\x12\n\n\x02id\x18\x01 \x01(\x05\x12\r\n\x05start\x18\x02 \x01(\x03\x12\x0b\n\x03\x65nd\x18\x03 \x01(\x03\x12\x0c\n\x04text\x18\x04 \x01(\t\x12\x0e\n\x06tokens\x18\x05 \x03(\x05\x12\x1b\n\x0eno_speech_prob\x18\x06 \x01(\x02H\x00\x88\x01\x01\x42\x11\n\x0f_no_speech_prob\"p\n\x0e\x44iarizeRequest\x12\x0b\n\x03\x64st\x18\x01 \x01(\t\x12\x14\n\x0cnum_speakers\x18\x02 \x01(\x05\x12\x14\n\x0cmin_speakers\x18\x03 \x01(\x05\x12\x14\n\x0cmax_speakers\x18\x04 \x01(\x05\x12\x0f\n\x07threads\x18\x05 \x01(\r\":\n\x0bSpeakerTurn\x12\r\n\x05start\x18\x01 \x01(\x03\x12\x0b\n\x03\x65nd\x18\x02 \x01(\x03\x12\x0f\n\x07speaker\x18\x03 \x01(\t\"4\n\rDiarizeResult\x12#\n\x05turns\x18\x01 \x03(\x0b\x32\x14.backend.SpeakerTurn\"l\n\nVADRequest\x12\x0b\n\x03\x64st\x18\x01 \x01(\t\x12\x11\n\tthreshold\x18\x02 \x01(\x02\x12\x16\n\x0emin_silence_ms\x18\x03 \x01(\x05\x12\x15\n\rmin_speech_ms\x18\x04 \x01(\x05\x12\x0f\n\x07threads\x18\x05 \x01(\r\"(\n\nVADSegment\x12\r\n\x05start\x18\x01 \x01(\x03\x12\x0b\n\x03\x65nd\x18\x02 \x01(\x03\"2\n\tVADResult\x12%\n\x08segments\x18\x01 \x03(\x0b\x32\x13.backend.VADSegment\"\xd0\x03\n\x14GenerateImageRequest\x12\x0e\n\x06height\x18\x01 \x01(\x05\x12\r\n\x05width\x18\x02 \x01(\x05\x12\x0c\n\x04mode\x18\x03 \x01(\x05\x12\x0c\n\x04step\x18\x04 \x01(\x05\x12\x0c\n\x04seed\x18\x05 \x01(\x05\x12\x17\n\x0fpositive_prompt\x18\x06 \x01(\t\x12\x17\n\x0fnegative_prompt\x18\x07 \x01(\t\x12\x0b\n\x03\x64st\x18\x08 \x01(\t\x12\x0b\n\x03src\x18\t \x01(\t\x12\x18\n\x10\x45nableParameters\x18\n \x01(\t\x12\x10\n\x08\x43LIPSkip\x18\x0b \x01(\x05\x12\x10\n\x08strength\x18\x0c \x01(\x02\x12\x0c\n\x04mask\x18\r \x01(\t\x12\x15\n\rcontrol_image\x18\x0e \x01(\t\x12\x14\n\x0c\x63ontrol_type\x18\x0f \x01(\t\x12\x11\n\tscheduler\x18\x10 \x01(\t\x12\x11\n\tcfg_scale\x18\x11 \x01(\x02\x12\x0f\n\x07preview\x18\x12 \x01(\x08\x12\x37\n\x05loras\x18\x13 \x03(\x0b\x32(.backend.GenerateImageRequest.LorasEntry\x12\x0c\n\x04\x64sts\x18\x14 \x03(\t\x1a,\n\nLorasEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x02:\x02\x38\x01\"L\n\rImageProgress\x12\x0c\n\x04step\x18\x01 \x01(\x05\x12\r\n\x05steps\x18\x02 \x01(\x05\x12\x0f\n\x07preview\x18\x03 \x01(\x0c\x12\r\n\x05index\x18\x04 \x01(\x05\"G\n\x0eUpscaleRequest\x12\x0b\n\x03src\x18\x01 \x01(\t\x12\x0b\n\x03\x64st\x18\x02 \x01(\t\x12\r\n\x05scale\x18\x03 \x01(\x02\x12\x0c\n\x04tile\x18\x04 \x01(\x05\"W\n\nTTSRequest\x12\x0c\n\x04text\x18\x01 \x01(\t\x12\r\n\x05model\x18\x02 \x01(\t\x12\x0b\n\x03\x64st\x18\x03 \x01(\t\x12\r\n\x05voice\x18\x04 \x01(\t\x12\x10\n\x08language\x18\x05 \x01(\t\"6\n\x14TokenizationResponse\x12\x0e\n\x06length\x18\x01 \x01(\x05\x12\x0e\n\x06tokens\x18\x02 \x03(\x05\"\'\n\x15\x44\x65tokenizationRequest\x12\x0e\n\x06tokens\x18\x01 \x03(\x05\")\n\x16\x44\x65tokenizationResponse\x12\x0f\n\x07\x63ontent\x18\x01 \x01(\t\"\x8e\x01\n\x0fMemoryUsageData\x12\r\n\x05total\x18\x01 \x01(\x04\x12:\n\tbreakdown\x18\x02 \x03(\x0b\x32\'.backend.MemoryUsageData.BreakdownEntry\x1a\x30\n\x0e\x42reakdownEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x04:\x02\x38\x01\"\xad\x01\n\x0eStatusResponse\x12,\n\x05state\x18\x01 \x01(\x0e\x32\x1d.backend.StatusResponse.State\x12(\n\x06memory\x18\x02 \x01(\x0b\x32\x18.backend.MemoryUsageData\"C\n\x05State\x12\x11\n\rUNINITIALIZED\x10\x00\x12\x08\n\x04\x42USY\x10\x01\x12\t\n\x05READY\x10\x02\x12\x12\n\x05\x45RROR\x10\xff\xff\xff\xff\xff\xff\xff\xff\xff\x01\"F\n\x14\x43\x61pabilitiesResponse\x12\x18\n\x10protocol_version\x18\x01 \x01(\x05\x12\x14\n\x0c\x63\x61pabilities\x18\x02 \x03(\t\"\x1f\n\x0f\x43lassifyRequest\x12\x0c\n\x04text\x18\x01 \x01(\t\"-\n\rClassifyLabel\x12\r\n\x05label\x18\x01 \x01(\t\x12\r\n\x05score\x18\x02 \x01(\x02\"8\n\x0e\x43lassifyResult\x12&\n\x06labels\x18\x01 \x03(\x0b\x32\x16.backend.ClassifyLabel2\xdc\t\n\x07\x42\x61\x63kend\x12\x32\n\x06Health\x12\x16.backend.HealthMessage\x1a\x0e.backend.Reply\"\x00\x12\x34\n\x07Predict\x12\x17.backend.PredictOptions\x1a\x0e.backend.Reply\"\x00\x12\x35\n\tLoadModel\x12\x15.backend.ModelOptions\x1a\x0f.backend.Result\"\x00\x12<\n\rPredictStream\x12\x17.backend.PredictOptions\x1a\x0e.backend.Reply\"\x00\x30\x01\x12@\n\tEmbedding\x12\x17.backend.PredictOptions\x1a\x18.backend.EmbeddingResult\"\x00\x12\x41\n\rGenerateImage\x12\x1d.backend.GenerateImageRequest\x1a\x0f.backend.Result\"\x00\x12M\n\x12\x41udioTranscription\x12\x1a.backend.TranscriptRequest\x1a\x19.backend.TranscriptResult\"\x00\x12-\n\x03TTS\x12\x13.backend.TTSRequest\x1a\x0f.backend.Result\"\x00\x12J\n\x0eTokenizeString\x12\x17.backend.PredictOptions\x1a\x1d.backend.TokenizationResponse\"\x00\x12O\n\nDetokenize\x12\x1e.backend.DetokenizationRequest\x1a\x1f.backend.DetokenizationResponse\"\x00\x12;\n\x06Status\x12\x16.backend.HealthMessage\x1a\x17.backend.StatusResponse\"\x00\x12G\n\x0c\x43\x61pabilities\x12\x16.backend.HealthMessage\x1a\x1d.backend.CapabilitiesResponse\"\x00\x12?\n\x08\x43lassify\x12\x18.backend.ClassifyRequest\x1a\x17.backend.ClassifyResult\"\x00\x12=\n\x0bReconfigure\x12\x1b.backend.ReconfigureRequest\x1a\x0f.backend.Result\"\x00\x12S\n\x0e\x45mbeddingBatch\x12\x1e.backend.EmbeddingBatchRequest\x1a\x1d.backend.EmbeddingBatchResult\"\x00\x30\x01\x12<\n\x07\x44iarize\x12\x17.backend.DiarizeRequest\x1a\x16.backend.DiarizeResult\"\x00\x12\x30\n\x03VAD\x12\x13.backend.VADRequest\x1a\x12.backend.VADResult\"\x00\x12\x35\n\x07Upscale\x12\x17.backend.UpscaleRequest\x1a\x0f.backend.Result\"\x00\x12P\n\x13GenerateImageStream\x12\x1d.backend.GenerateImageRequest\x1a\x16.backend.ImageProgress\"\x00\x30\x01\x42Z\n\x19io.skynet.localai.backendB\x0eLocalAIBackendP\x01Z+github.com/go-skynet/LocalAI/pkg/grpc/protob\x06proto3')
 
 _globals = globals()
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
@@ -22,40 +22,94 @@ if _descriptor._USE_C_DESCRIPTORS == False:
 
   DESCRIPTOR._options = None
   DESCRIPTOR._serialized_options = b'\n\031io.skynet.localai.backendB\016LocalAIBackendP\001Z+github.com/go-skynet/LocalAI/pkg/grpc/proto'
+  _MODELOPTIONS_CONTROLNETSENTRY._options = None
+  _MODELOPTIONS_CONTROLNETSENTRY._serialized_options = b'8\001'
+  _MODELOPTIONS_LORASENTRY._options = None
+  _MODELOPTIONS_LORASENTRY._serialized_options = b'8\001'
+  _GENERATEIMAGEREQUEST_LORASENTRY._options = None
+  _GENERATEIMAGEREQUEST_LORASENTRY._serialized_options = b'8\001'
   _MEMORYUSAGEDATA_BREAKDOWNENTRY._options = None
   _MEMORYUSAGEDATA_BREAKDOWNENTRY._serialized_options = b'8\001'
   _globals['_HEALTHMESSAGE']._serialized_start=26
   _globals['_HEALTHMESSAGE']._serialized_end=41
   _globals['_PREDICTOPTIONS']._serialized_start=44
-  _globals['_PREDICTOPTIONS']._serialized_end=850
-  _globals['_REPLY']._serialized_start=852
-  _globals['_REPLY']._serialized_end=876
-  _globals['_MODELOPTIONS']._serialized_start=879
-  _globals['_MODELOPTIONS']._serialized_end=1834
-  _globals['_RESULT']._serialized_start=1836
-  _globals['_RESULT']._serialized_end=1878
-  _globals['_EMBEDDINGRESULT']._serialized_start=1880
-  _globals['_EMBEDDINGRESULT']._serialized_end=1917
-  _globals['_TRANSCRIPTREQUEST']._serialized_start=1919
-  _globals['_TRANSCRIPTREQUEST']._serialized_end=1986
-  _globals['_TRANSCRIPTRESULT']._serialized_start=1988
-  _globals['_TRANSCRIPTRESULT']._serialized_end=2066
-  _globals['_TRANSCRIPTSEGMENT']._serialized_start=2068
-  _globals['_TRANSCRIPTSEGMENT']._serialized_end=2157
-  _globals['_GENERATEIMAGEREQUEST']._serialized_start=2160
-  _globals['_GENERATEIMAGEREQUEST']._serialized_end=2375
-  _globals['_TTSREQUEST']._serialized_start=2377
-  _globals['_TTSREQUEST']._serialized_end=2431
-  _globals['_TOKENIZATIONRESPONSE']._serialized_start=2433
-  _globals['_TOKENIZATIONRESPONSE']._serialized_end=2487
-  _globals['_MEMORYUSAGEDATA']._serialized_start=2490
-  _globals['_MEMORYUSAGEDATA']._serialized_end=2632
-  _globals['_MEMORYUSAGEDATA_BREAKDOWNENTRY']._serialized_start=2584
-  _globals['_MEMORYUSAGEDATA_BREAKDOWNENTRY']._serialized_end=2632
-  _globals['_STATUSRESPONSE']._serialized_start=2635
-  _globals['_STATUSRESPONSE']._serialized_end=2808
-  _globals['_STATUSRESPONSE_STATE']._serialized_start=2741
-  _globals['_STATUSRESPONSE_STATE']._serialized_end=2808
-  _globals['_BACKEND']._serialized_start=2811
-  _globals['_BACKEND']._serialized_end=3439
+  _globals['_PREDICTOPTIONS']._serialized_end=928
+  _globals['_TOKENPROBABILITY']._serialized_start=930
+  _globals['_TOKENPROBABILITY']._serialized_end=980
+  _globals['_TOKENLOGPROB']._serialized_start=982
+  _globals['_TOKENLOGPROB']._serialized_end=1077
+  _globals['_REPLY']._serialized_start=1079
+  _globals['_REPLY']._serialized_end=1193
+  _globals['_MODELOPTIONS']._serialized_start=1196
+  _globals['_MODELOPTIONS']._serialized_end=2377
+  _globals['_MODELOPTIONS_CONTROLNETSENTRY']._serialized_start=2281
+  _globals['_MODELOPTIONS_CONTROLNETSENTRY']._serialized_end=2331
+  _globals['_MODELOPTIONS_LORASENTRY']._serialized_start=2333
+  _globals['_MODELOPTIONS_LORASENTRY']._serialized_end=2377
+  _globals['_RESULT']._serialized_start=2379
+  _globals['_RESULT']._serialized_end=2421
+  _globals['_RECONFIGUREREQUEST']._serialized_start=2423
+  _globals['_RECONFIGUREREQUEST']._serialized_end=2461
+  _globals['_EMBEDDINGRESULT']._serialized_start=2463
+  _globals['_EMBEDDINGRESULT']._serialized_end=2516
+  _globals['_EMBEDDINGTOKENS']._serialized_start=2518
+  _globals['_EMBEDDINGTOKENS']._serialized_end=2551
+  _globals['_EMBEDDINGBATCHREQUEST']._serialized_start=2553
+  _globals['_EMBEDDINGBATCHREQUEST']._serialized_end=2676
+  _globals['_EMBEDDINGBATCHRESULT']._serialized_start=2678
+  _globals['_EMBEDDINGBATCHRESULT']._serialized_end=2757
+  _globals['_TRANSCRIPTREQUEST']._serialized_start=2759
+  _globals['_TRANSCRIPTREQUEST']._serialized_end=2870
+  _globals['_TRANSCRIPTRESULT']._serialized_start=2873
+  _globals['_TRANSCRIPTRESULT']._serialized_end=3039
+  _globals['_TRANSCRIPTWORD']._serialized_start=3041
+  _globals['_TRANSCRIPTWORD']._serialized_end=3099
+  _globals['_TRANSCRIPTSEGMENT']._serialized_start=3102
+  _globals['_TRANSCRIPTSEGMENT']._serialized_end=3239
+  _globals['_DIARIZEREQUEST']._serialized_start=3241
+  _globals['_DIARIZEREQUEST']._serialized_end=3353
+  _globals['_SPEAKERTURN']._serialized_start=3355
+  _globals['_SPEAKERTURN']._serialized_end=3413
+  _globals['_DIARIZERESULT']._serialized_start=3415
+  _globals['_DIARIZERESULT']._serialized_end=3467
+  _globals['_VADREQUEST']._serialized_start=3469
+  _globals['_VADREQUEST']._serialized_end=3577
+  _globals['_VADSEGMENT']._serialized_start=3579
+  _globals['_VADSEGMENT']._serialized_end=3619
+  _globals['_VADRESULT']._serialized_start=3621
+  _globals['_VADRESULT']._serialized_end=3671
+  _globals['_GENERATEIMAGEREQUEST']._serialized_start=3674
+  _globals['_GENERATEIMAGEREQUEST']._serialized_end=4138
+  _globals['_GENERATEIMAGEREQUEST_LORASENTRY']._serialized_start=4094
+  _globals['_GENERATEIMAGEREQUEST_LORASENTRY']._serialized_end=4138
+  _globals['_IMAGEPROGRESS']._serialized_start=4140
+  _globals['_IMAGEPROGRESS']._serialized_end=4216
+  _globals['_UPSCALEREQUEST']._serialized_start=4218
+  _globals['_UPSCALEREQUEST']._serialized_end=4289
+  _globals['_TTSREQUEST']._serialized_start=4291
+  _globals['_TTSREQUEST']._serialized_end=4378
+  _globals['_TOKENIZATIONRESPONSE']._serialized_start=4380
+  _globals['_TOKENIZATIONRESPONSE']._serialized_end=4434
+  _globals['_DETOKENIZATIONREQUEST']._serialized_start=4436
+  _globals['_DETOKENIZATIONREQUEST']._serialized_end=4475
+  _globals['_DETOKENIZATIONRESPONSE']._serialized_start=4477
+  _globals['_DETOKENIZATIONRESPONSE']._serialized_end=4518
+  _globals['_MEMORYUSAGEDATA']._serialized_start=4521
+  _globals['_MEMORYUSAGEDATA']._serialized_end=4663
+  _globals['_MEMORYUSAGEDATA_BREAKDOWNENTRY']._serialized_start=4615
+  _globals['_MEMORYUSAGEDATA_BREAKDOWNENTRY']._serialized_end=4663
+  _globals['_STATUSRESPONSE']._serialized_start=4666
+  _globals['_STATUSRESPONSE']._serialized_end=4839
+  _globals['_STATUSRESPONSE_STATE']._serialized_start=4772
+  _globals['_STATUSRESPONSE_STATE']._serialized_end=4839
+  _globals['_CAPABILITIESRESPONSE']._serialized_start=4841
+  _globals['_CAPABILITIESRESPONSE']._serialized_end=4911
+  _globals['_CLASSIFYREQUEST']._serialized_start=4913
+  _globals['_CLASSIFYREQUEST']._serialized_end=4944
+  _globals['_CLASSIFYLABEL']._serialized_start=4946
+  _globals['_CLASSIFYLABEL']._serialized_end=4991
+  _globals['_CLASSIFYRESULT']._serialized_start=4993
+  _globals['_CLASSIFYRESULT']._serialized_end=5049
+  _globals['_BACKEND']._serialized_start=5052
+  _globals['_BACKEND']._serialized_end=6296
 # @@protoc_insertion_point(module_scope)
@@ -2,9 +2,11 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -15,11 +17,29 @@ import (
 	api "github.com/go-skynet/LocalAI/api"
 	"github.com/go-skynet/LocalAI/api/backend"
 	config "github.com/go-skynet/LocalAI/api/config"
+	"github.com/go-skynet/LocalAI/api/localai"
 	"github.com/go-skynet/LocalAI/api/options"
 	"github.com/go-skynet/LocalAI/internal"
 	"github.com/go-skynet/LocalAI/metrics"
+	"github.com/go-skynet/LocalAI/pkg/apikeys"
+	"github.com/go-skynet/LocalAI/pkg/audit"
+	"github.com/go-skynet/LocalAI/pkg/client"
+	"github.com/go-skynet/LocalAI/pkg/contentfilter"
+	"github.com/go-skynet/LocalAI/pkg/downloader"
 	"github.com/go-skynet/LocalAI/pkg/gallery"
+	"github.com/go-skynet/LocalAI/pkg/grpc"
+	"github.com/go-skynet/LocalAI/pkg/logstream"
 	model "github.com/go-skynet/LocalAI/pkg/model"
+	"github.com/go-skynet/LocalAI/pkg/oidc"
+	"github.com/go-skynet/LocalAI/pkg/quota"
+	"github.com/go-skynet/LocalAI/pkg/sandbox"
+	"github.com/go-skynet/LocalAI/pkg/secrets"
+	"github.com/go-skynet/LocalAI/pkg/storage"
+	"github.com/go-skynet/LocalAI/pkg/telemetry"
+	"github.com/go-skynet/LocalAI/pkg/tlscert"
+	"github.com/go-skynet/LocalAI/pkg/usage"
+	"github.com/go-skynet/LocalAI/pkg/vectorstore"
+	"github.com/go-skynet/LocalAI/pkg/workspace"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	progressbar "github.com/schollz/progressbar/v3"
@@ -31,7 +51,8 @@ const (
 )
 
 func main() {
-	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+	// the logs are also streamed to the admins watching them
+	log.Logger = log.Output(zerolog.MultiLevelWriter(zerolog.ConsoleWriter{Out: os.Stderr}, logstream.Default))
 	// clean up process
 	go func() {
 		c := make(chan os.Signal, 1) // we need to reserve to buffer size 1, so the notifier are not blocked
@@ -59,6 +80,16 @@ func main() {
 				Name:    "autoload-galleries",
 				EnvVars: []string{"AUTOLOAD_GALLERIES"},
 			},
+			&cli.BoolFlag{
+				Name:    "convert-legacy-ggml",
+				Usage:   "Automatically convert legacy ggml models to GGUF when installing them from galleries.",
+				EnvVars: []string{"CONVERT_LEGACY_GGML"},
+			},
+			&cli.BoolFlag{
+				Name:    "keep-legacy-ggml",
+				Usage:   "Keep the original legacy ggml file (with a .ggml suffix) after converting it to GGUF.",
+				EnvVars: []string{"KEEP_LEGACY_GGML"},
+			},
 			&cli.BoolFlag{
 				Name:    "debug",
 				EnvVars: []string{"DEBUG"},
@@ -68,6 +99,26 @@ func main() {
 				EnvVars: []string{"SINGLE_ACTIVE_BACKEND"},
 				Usage:   "Allow only one backend to be running.",
 			},
+			&cli.BoolFlag{
+				Name:    "backend-isolation",
+				EnvVars: []string{"BACKEND_ISOLATION"},
+				Usage:   "Restrict the filesystem view of the backends to their model and assets (requires bubblewrap).",
+			},
+			&cli.StringFlag{
+				Name:    "backend-user",
+				EnvVars: []string{"BACKEND_USER"},
+				Usage:   "User the backends run as, by name or as uid[:gid]. LocalAI must run as root.",
+			},
+			&cli.BoolFlag{
+				Name:    "backend-landlock",
+				EnvVars: []string{"BACKEND_LANDLOCK"},
+				Usage:   "Restrict the filesystem of the backends to their model and assets with Landlock (requires Linux 5.13).",
+			},
+			&cli.StringFlag{
+				Name:    "backend-seccomp-profile",
+				EnvVars: []string{"BACKEND_SECCOMP_PROFILE"},
+				Usage:   "File of the seccomp profile filtering the system calls of the backends.",
+			},
 			&cli.BoolFlag{
 				Name:    "parallel-requests",
 				EnvVars: []string{"PARALLEL_REQUESTS"},
@@ -89,15 +140,47 @@ func main() {
 			},
 			&cli.StringFlag{
 				Name:    "models-path",
-				Usage:   "Path containing models used for inferencing",
+				Usage:   "Path containing models used for inferencing. A comma separated list of directories is searched in order, the read-only ones end with :ro, and the models are downloaded to the first writable one",
 				EnvVars: []string{"MODELS_PATH"},
 				Value:   filepath.Join(path, "models"),
 			},
+			&cli.StringFlag{
+				Name:    "models-storage",
+				Usage:   "Object storage backing the models path (s3://<bucket>/<prefix>, gs://<bucket>/<prefix> or az://<container>/<prefix>). The models are fetched on first use and cached in the models path",
+				EnvVars: []string{"MODELS_STORAGE"},
+			},
+			&cli.IntFlag{
+				Name:    "models-cache-size",
+				Usage:   "Maximum size of the models fetched from the models storage, in MB. The least recently used models are evicted first (0 means no limit)",
+				EnvVars: []string{"MODELS_CACHE_SIZE"},
+			},
+			&cli.StringFlag{
+				Name:    "models-storage-secret",
+				Usage:   "Name of the secret of --secrets-file with the credentials of the models storage, instead of the environment",
+				EnvVars: []string{"MODELS_STORAGE_SECRET"},
+			},
+			&cli.StringFlag{
+				Name:    "secrets-file",
+				Usage:   "YAML file of the secrets (HuggingFace tokens, OCI registry credentials, S3 keys) the model configs reference by name to download their files",
+				EnvVars: []string{"SECRETS_FILE"},
+			},
 			&cli.StringFlag{
 				Name:    "galleries",
 				Usage:   "JSON list of galleries",
 				EnvVars: []string{"GALLERIES"},
 			},
+			&cli.StringFlag{
+				Name:    "galleries-refresh-interval",
+				Usage:   "How often the galleries are refreshed to find the updates of the installed models (0 means never)",
+				EnvVars: []string{"GALLERIES_REFRESH_INTERVAL"},
+				Value:   "0",
+			},
+			&cli.StringFlag{
+				Name:    "config-watch-interval",
+				Usage:   "How often the config files of the models path are checked for changes, which are applied without restarting (0 means only on SIGHUP)",
+				EnvVars: []string{"CONFIG_WATCH_INTERVAL"},
+				Value:   "5s",
+			},
 			&cli.StringFlag{
 				Name:    "remote-library",
 				Usage:   "A LocalAI remote library URL",
@@ -119,17 +202,76 @@ func main() {
 				Usage:   "A List of models to apply at startup. Path to a YAML config file",
 				EnvVars: []string{"PRELOAD_MODELS_CONFIG"},
 			},
+			&cli.StringFlag{
+				Name:    "bootstrap-file",
+				Usage:   "A declarative manifest of the models, API keys, external backends, vector stores and aliases to set up at startup. Path to a YAML file",
+				EnvVars: []string{"BOOTSTRAP_FILE"},
+			},
+			&cli.StringFlag{
+				Name:    "trace-dir",
+				Usage:   "Directory where the inferences of the requests with the X-LocalAI-Trace header are recorded, to be replayed with 'local-ai replay'",
+				EnvVars: []string{"TRACE_DIR"},
+			},
+			&cli.StringFlag{
+				Name:    "crash-dir",
+				Usage:   "Directory where the exit code, the end of the stderr and the core dump path of the backends which crashed are written, in a directory per model",
+				EnvVars: []string{"CRASH_DIR"},
+			},
 			&cli.StringFlag{
 				Name:    "config-file",
 				Usage:   "Config file",
 				EnvVars: []string{"CONFIG_FILE"},
 			},
+			&cli.StringFlag{
+				Name:    "model-routes",
+				Usage:   "YAML file of rules routing the requested model names (e.g. gpt-4) to the configured models",
+				EnvVars: []string{"MODEL_ROUTES"},
+			},
 			&cli.StringFlag{
 				Name:    "address",
 				Usage:   "Bind address for the API server.",
 				EnvVars: []string{"ADDRESS"},
 				Value:   ":8080",
 			},
+			&cli.StringFlag{
+				Name:    "tls-cert",
+				Usage:   "Certificate (PEM) of the API server, which is served over HTTPS when set. The certificate and its key are reloaded when their files change, and on SIGHUP.",
+				EnvVars: []string{"TLS_CERT"},
+			},
+			&cli.StringFlag{
+				Name:    "tls-key",
+				Usage:   "Private key (PEM) of the certificate of the API server",
+				EnvVars: []string{"TLS_KEY"},
+			},
+			&cli.StringFlag{
+				Name:    "assistants-path",
+				Usage:   "Directory where the assistants, threads and runs of the Assistants API are stored",
+				EnvVars: []string{"ASSISTANTS_PATH"},
+				Value:   "/tmp/localai/assistants",
+			},
+			&cli.StringFlag{
+				Name:    "files-path",
+				Usage:   "Directory where the files uploaded with the Files API are stored",
+				EnvVars: []string{"FILES_PATH"},
+				Value:   "/tmp/localai/files",
+			},
+			&cli.IntFlag{
+				Name:    "files-quota",
+				Usage:   "Maximum size of all the files uploaded with the Files API, in MB (0 means no limit)",
+				EnvVars: []string{"FILES_QUOTA"},
+			},
+			&cli.IntFlag{
+				Name:    "batch-workers",
+				Usage:   "Number of batches of the Batch API executed at the same time",
+				EnvVars: []string{"BATCH_WORKERS"},
+				Value:   1,
+			},
+			&cli.StringFlag{
+				Name:    "vector-stores-path",
+				Usage:   "Directory where the collections of vectors of the /stores endpoints are stored. The endpoints are disabled when empty",
+				EnvVars: []string{"VECTOR_STORES_PATH"},
+				Value:   "/tmp/localai/stores",
+			},
 			&cli.StringFlag{
 				Name:    "image-path",
 				Usage:   "Image directory",
@@ -142,17 +284,80 @@ func main() {
 				EnvVars: []string{"AUDIO_PATH"},
 				Value:   "/tmp/generated/audio",
 			},
+			&cli.StringFlag{
+				Name:    "proxy",
+				Usage:   "HTTP(S) proxy used to download models and galleries (by default, HTTP_PROXY and HTTPS_PROXY are used)",
+				EnvVars: []string{"DOWNLOAD_PROXY"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "mirror",
+				Usage:   "Download the URLs starting with a prefix from a mirror instead, in the <url>=<mirror url> form (e.g. https://huggingface.co=https://mirror.example.com/huggingface)",
+				EnvVars: []string{"MIRRORS"},
+			},
+			&cli.BoolFlag{
+				Name:    "offline",
+				Usage:   "Fail the downloads which are not served by a mirror, for air-gapped setups",
+				EnvVars: []string{"OFFLINE"},
+			},
+			&cli.IntFlag{
+				Name:    "download-concurrency",
+				Usage:   "How many segments of a model are downloaded in parallel, when the server supports range requests",
+				EnvVars: []string{"DOWNLOAD_CONCURRENCY"},
+				Value:   4,
+			},
+			&cli.StringFlag{
+				Name:    "workspace-path",
+				Usage:   "Directory where temporary files (uploaded audio, images, backends scratch files) are written",
+				EnvVars: []string{"WORKSPACE_PATH"},
+				Value:   "/tmp/localai/workspace",
+			},
+			&cli.IntFlag{
+				Name:    "workspace-quota",
+				Usage:   "Maximum size of the workspace and of the generated images and audio, in MB. The oldest files are removed first (0 means no limit)",
+				EnvVars: []string{"WORKSPACE_QUOTA"},
+			},
+			&cli.StringFlag{
+				Name:    "workspace-max-age",
+				Usage:   "Remove the files of the workspace and the generated images and audio older than this (0 means never)",
+				EnvVars: []string{"WORKSPACE_MAX_AGE"},
+				Value:   "24h",
+			},
 			&cli.StringFlag{
 				Name:    "backend-assets-path",
 				Usage:   "Path used to extract libraries that are required by some of the backends in runtime.",
 				EnvVars: []string{"BACKEND_ASSETS_PATH"},
 				Value:   "/tmp/localai/backend_data",
 			},
+			&cli.StringFlag{
+				Name:    "ffmpeg",
+				Usage:   "The ffmpeg binary decoding the uploaded audio. Defaults to the one bundled with the backend assets, or the one of the PATH.",
+				EnvVars: []string{"FFMPEG_PATH"},
+			},
 			&cli.StringSliceFlag{
 				Name:    "external-grpc-backends",
 				Usage:   "A list of external grpc backends",
 				EnvVars: []string{"EXTERNAL_GRPC_BACKENDS"},
 			},
+			&cli.StringFlag{
+				Name:    "backend-tls-ca",
+				Usage:   "CA verifying the certificates of the external gRPC backends given by address",
+				EnvVars: []string{"BACKEND_TLS_CA"},
+			},
+			&cli.StringFlag{
+				Name:    "backend-tls-cert",
+				Usage:   "Client certificate presented to the external gRPC backends given by address, for mutual TLS",
+				EnvVars: []string{"BACKEND_TLS_CERT"},
+			},
+			&cli.StringFlag{
+				Name:    "backend-tls-key",
+				Usage:   "Key of the client certificate presented to the external gRPC backends",
+				EnvVars: []string{"BACKEND_TLS_KEY"},
+			},
+			&cli.StringFlag{
+				Name:    "backend-token",
+				Usage:   "Shared token sent to the external gRPC backends given by address, on every call",
+				EnvVars: []string{"BACKEND_TOKEN"},
+			},
 			&cli.IntFlag{
 				Name:    "context-size",
 				Usage:   "Default context size of the model",
@@ -170,6 +375,52 @@ func main() {
 				Usage:   "List of API Keys to enable API authentication. When this is set, all the requests must be authenticated with one of these API keys.",
 				EnvVars: []string{"API_KEY"},
 			},
+			&cli.StringFlag{
+				Name:    "admin-key",
+				Usage:   "Key enabling the profiling (/debug/pprof), diagnostics (/debug/diagnostics), crashes (/debug/crashes) and logs (/debug/logs) endpoints, which must be requested with it. The endpoints are disabled when not set.",
+				EnvVars: []string{"ADMIN_KEY"},
+			},
+			&cli.StringFlag{
+				Name:    "api-keys-store",
+				Usage:   "File where the API keys with scopes, created and revoked at /keys, are stored hashed. The keys with scopes are disabled when not set.",
+				EnvVars: []string{"API_KEYS_STORE"},
+			},
+			&cli.StringFlag{
+				Name:    "oidc-issuer",
+				Usage:   "URL of an OIDC issuer whose JWT tokens are accepted as bearer tokens, along with the API keys. Its keys are discovered at startup.",
+				EnvVars: []string{"OIDC_ISSUER"},
+			},
+			&cli.StringFlag{
+				Name:    "oidc-audience",
+				Usage:   "Audience the OIDC tokens must have, e.g. the client ID of LocalAI at the issuer. Required with --oidc-issuer.",
+				EnvVars: []string{"OIDC_AUDIENCE"},
+			},
+			&cli.StringFlag{
+				Name:    "oidc-scopes-claim",
+				Usage:   "Claim of the OIDC tokens with their scopes, or groups, as a space separated string or a list.",
+				EnvVars: []string{"OIDC_SCOPES_CLAIM"},
+				Value:   "scope",
+			},
+			&cli.StringSliceFlag{
+				Name:    "oidc-scope-map",
+				Usage:   "Maps a value of the scopes claim to scopes of LocalAI, e.g. ml-users=chat,embeddings. The values not mapped grant no scope.",
+				EnvVars: []string{"OIDC_SCOPE_MAP"},
+			},
+			&cli.StringFlag{
+				Name:    "oidc-tenant-claim",
+				Usage:   "Claim of the OIDC tokens with the tenant of the caller, for the quotas and the model namespaces.",
+				EnvVars: []string{"OIDC_TENANT_CLAIM"},
+			},
+			&cli.BoolFlag{
+				Name:    "tenant-namespaces",
+				Usage:   "Serve the callers of a tenant with the models of its directory of the models path only (tenants/<tenant>). The other callers don't see the models of the tenants.",
+				EnvVars: []string{"TENANT_NAMESPACES"},
+			},
+			&cli.BoolFlag{
+				Name:    "tenant-shared-models",
+				Usage:   "With --tenant-namespaces, the tenants also see the models of the models path, after their own ones.",
+				EnvVars: []string{"TENANT_SHARED_MODELS"},
+			},
 			&cli.BoolFlag{
 				Name:    "enable-watchdog-idle",
 				Usage:   "Enable watchdog for stopping idle backends. This will stop the backends if are in idle state for too long.",
@@ -194,6 +445,69 @@ func main() {
 				EnvVars: []string{"WATCHDOG_IDLE_TIMEOUT"},
 				Value:   "15m",
 			},
+			&cli.StringFlag{
+				Name:    "telemetry-endpoint",
+				Usage:   "URL of a collector to send usage and health metrics to. Telemetry is disabled when not set.",
+				EnvVars: []string{"TELEMETRY_ENDPOINT"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "telemetry-contents",
+				Usage:   "Kinds of telemetry events to send (usage, health).",
+				EnvVars: []string{"TELEMETRY_CONTENTS"},
+				Value:   cli.NewStringSlice(telemetry.KindUsage, telemetry.KindHealth),
+			},
+			&cli.StringFlag{
+				Name:    "telemetry-interval",
+				Usage:   "How often telemetry events are sent to the collector.",
+				EnvVars: []string{"TELEMETRY_INTERVAL"},
+				Value:   "5m",
+			},
+			&cli.StringFlag{
+				Name:    "telemetry-buffer-file",
+				Usage:   "File where telemetry events are kept while the collector is not reachable.",
+				EnvVars: []string{"TELEMETRY_BUFFER_FILE"},
+			},
+			&cli.StringFlag{
+				Name:    "telemetry-node-name",
+				Usage:   "Name identifying this node in the telemetry events.",
+				EnvVars: []string{"TELEMETRY_NODE_NAME"},
+			},
+			&cli.StringFlag{
+				Name:    "audit-log",
+				Usage:   "File where the API calls are recorded for auditing, or \"syslog\" to send them to the local syslog daemon. The audit log is disabled when not set.",
+				EnvVars: []string{"AUDIT_LOG"},
+			},
+			&cli.BoolFlag{
+				Name:    "audit-log-prompts",
+				Usage:   "Record the contents of the prompts in the audit log. By default only their hashes are recorded.",
+				EnvVars: []string{"AUDIT_LOG_PROMPTS"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "audit-log-redact",
+				Usage:   "Regular expressions of the contents of the prompts replaced with [REDACTED] in the audit log.",
+				EnvVars: []string{"AUDIT_LOG_REDACT"},
+			},
+			&cli.StringFlag{
+				Name:    "usage-db",
+				Usage:   "File of the database where the requests, tokens and audio served to each API key are accounted, queried at /usage. The accounting is disabled when not set.",
+				EnvVars: []string{"USAGE_DB"},
+			},
+			&cli.StringFlag{
+				Name:    "quotas",
+				Usage:   "YAML file of the daily or monthly token quotas of the API keys and tenants, enforced from the usage accounted with --usage-db.",
+				EnvVars: []string{"QUOTAS"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "content-filter",
+				Usage:   "External content filter, as <name>=<url>, called with the prompts and the responses of the models enabling it by name in their content_filters.",
+				EnvVars: []string{"CONTENT_FILTERS"},
+			},
+			&cli.StringFlag{
+				Name:    "content-filter-timeout",
+				Usage:   "Time after which the requests fail when an external content filter does not answer.",
+				EnvVars: []string{"CONTENT_FILTER_TIMEOUT"},
+				Value:   "10s",
+			},
 			&cli.BoolFlag{
 				Name:    "preload-backend-only",
 				Usage:   "If set, the api is NOT launched, and only the preloaded models / backends are started. This is intended for multi-node setups.",
@@ -217,15 +531,73 @@ For a list of compatible model, check out: https://localai.io/model-compatibilit
 `,
 		UsageText: `local-ai [options]`,
 		Copyright: "Ettore Di Giacinto",
+		// the network settings apply to the downloads of all the commands
+		Before: func(ctx *cli.Context) error {
+			if err := downloader.SetProxy(ctx.String("proxy")); err != nil {
+				return err
+			}
+			mirrors, err := downloader.ParseMirrors(ctx.StringSlice("mirror"))
+			if err != nil {
+				return err
+			}
+			downloader.SetMirrors(mirrors)
+			downloader.SetOffline(ctx.Bool("offline"))
+			downloader.SetConcurrency(ctx.Int("download-concurrency"))
+			return nil
+		},
 		Action: func(ctx *cli.Context) error {
+			loader, err := newModelLoader(ctx)
+			if err != nil {
+				return err
+			}
+			secretStore, err := loadSecrets(ctx)
+			if err != nil {
+				return err
+			}
+			if uri := ctx.String("models-storage"); uri != "" {
+				var s storage.Storage
+				if name := ctx.String("models-storage-secret"); name != "" {
+					secret, err := secretStore.Get(name)
+					if err != nil {
+						return fmt.Errorf("models storage: %w", err)
+					}
+					s, err = storage.NewWithSecret(uri, secret)
+					if err != nil {
+						return err
+					}
+				} else if s, err = storage.New(uri); err != nil {
+					return err
+				}
+				cache := storage.NewCache(loader.ModelPath, s, int64(ctx.Int("models-cache-size"))*1024*1024)
+				// the configurations of the models are needed at startup
+				if err := cache.Sync(ctx.Context); err != nil {
+					return err
+				}
+				loader.SetStorage(cache)
+			}
+			if ca, cert, token := ctx.String("backend-tls-ca"), ctx.String("backend-tls-cert"), ctx.String("backend-token"); ca != "" || cert != "" || token != "" {
+				creds, err := grpc.NewClientCredentials(ca, cert, ctx.String("backend-tls-key"), token)
+				if err != nil {
+					return err
+				}
+				loader.SetRemoteCredentials(creds)
+			}
+
 			opts := []options.AppOption{
 				options.WithConfigFile(ctx.String("config-file")),
+				options.WithModelRoutesFile(ctx.String("model-routes")),
 				options.WithJSONStringPreload(ctx.String("preload-models")),
 				options.WithYAMLConfigPreload(ctx.String("preload-models-config")),
-				options.WithModelLoader(model.NewModelLoader(ctx.String("models-path"))),
+				options.WithBootstrapFile(ctx.String("bootstrap-file")),
+				options.WithTraceDir(ctx.String("trace-dir")),
+				options.WithCrashDir(ctx.String("crash-dir")),
+				options.WithModelLoader(loader),
 				options.WithContextSize(ctx.Int("context-size")),
 				options.WithDebug(ctx.Bool("debug")),
 				options.WithImageDir(ctx.String("image-path")),
+				options.WithAssistantsDir(ctx.String("assistants-path")),
+				options.WithFilesDir(ctx.String("files-path"), ctx.Int("files-quota")),
+				options.WithBatchWorkers(ctx.Int("batch-workers")),
 				options.WithAudioDir(ctx.String("audio-path")),
 				options.WithF16(ctx.Bool("f16")),
 				options.WithStringGalleries(ctx.String("galleries")),
@@ -236,8 +608,11 @@ For a list of compatible model, check out: https://localai.io/model-compatibilit
 				options.WithThreads(ctx.Int("threads")),
 				options.WithBackendAssets(backendAssets),
 				options.WithBackendAssetsOutput(ctx.String("backend-assets-path")),
+				options.WithFFmpeg(ctx.String("ffmpeg")),
 				options.WithUploadLimitMB(ctx.Int("upload-limit")),
 				options.WithApiKeys(ctx.StringSlice("api-keys")),
+				options.WithAdminKey(ctx.String("admin-key")),
+				options.WithLegacyGGMLConversion(ctx.Bool("convert-legacy-ggml"), ctx.Bool("keep-legacy-ggml")),
 				options.WithModelsURL(append(ctx.StringSlice("models"), ctx.Args().Slice()...)...),
 			}
 
@@ -268,6 +643,14 @@ For a list of compatible model, check out: https://localai.io/model-compatibilit
 			if ctx.Bool("single-active-backend") {
 				opts = append(opts, options.EnableSingleBackend)
 			}
+			if ctx.Bool("backend-isolation") {
+				opts = append(opts, options.EnableBackendIsolation)
+			}
+			opts = append(opts, options.WithBackendSandbox(sandbox.Spec{
+				User:     ctx.String("backend-user"),
+				Landlock: ctx.Bool("backend-landlock"),
+				Seccomp:  ctx.String("backend-seccomp-profile"),
+			}))
 
 			externalgRPC := ctx.StringSlice("external-grpc-backends")
 			// split ":" to get backend name and the uri
@@ -281,6 +664,137 @@ For a list of compatible model, check out: https://localai.io/model-compatibilit
 				opts = append(opts, options.EnableGalleriesAutoload)
 			}
 
+			refreshInterval, err := time.ParseDuration(ctx.String("galleries-refresh-interval"))
+			if err != nil {
+				return err
+			}
+			opts = append(opts, options.WithGalleriesRefreshInterval(refreshInterval))
+
+			configWatchInterval, err := time.ParseDuration(ctx.String("config-watch-interval"))
+			if err != nil {
+				return err
+			}
+			opts = append(opts, options.WithConfigWatchInterval(configWatchInterval))
+
+			maxAge, err := time.ParseDuration(ctx.String("workspace-max-age"))
+			if err != nil {
+				return err
+			}
+			ws, err := workspace.New(ctx.String("workspace-path"),
+				workspace.WithQuota(int64(ctx.Int("workspace-quota"))*1024*1024),
+				workspace.WithMaxAge(maxAge),
+				workspace.WithManagedDirs(ctx.String("image-path"), ctx.String("audio-path")),
+			)
+			if err != nil {
+				return err
+			}
+			opts = append(opts, options.WithWorkspace(ws))
+
+			if endpoint := ctx.String("telemetry-endpoint"); endpoint != "" {
+				interval, err := time.ParseDuration(ctx.String("telemetry-interval"))
+				if err != nil {
+					return err
+				}
+				exporter, err := telemetry.NewExporter(endpoint,
+					telemetry.WithInterval(interval),
+					telemetry.WithKinds(ctx.StringSlice("telemetry-contents")...),
+					telemetry.WithBufferFile(ctx.String("telemetry-buffer-file")),
+					telemetry.WithNodeName(ctx.String("telemetry-node-name")),
+				)
+				if err != nil {
+					return err
+				}
+				opts = append(opts, options.WithTelemetry(exporter))
+			}
+
+			if filters := ctx.StringSlice("content-filter"); len(filters) > 0 {
+				timeout, err := time.ParseDuration(ctx.String("content-filter-timeout"))
+				if err != nil {
+					return err
+				}
+				httpFilters, err := contentfilter.ParseHTTPFilters(filters, timeout)
+				if err != nil {
+					return err
+				}
+				for name, f := range httpFilters {
+					opts = append(opts, options.WithContentFilter(name, f))
+				}
+			}
+
+			if destination := ctx.String("audit-log"); destination != "" {
+				auditOpts := []audit.Option{audit.WithRedactedPatterns(ctx.StringSlice("audit-log-redact")...)}
+				if ctx.Bool("audit-log-prompts") {
+					auditOpts = append(auditOpts, audit.WithPrompts())
+				}
+				auditLog, err := audit.New(destination, auditOpts...)
+				if err != nil {
+					return err
+				}
+				opts = append(opts, options.WithAuditLog(auditLog))
+			}
+
+			var keyStore *apikeys.Store
+			if file := ctx.String("api-keys-store"); file != "" {
+				var err error
+				keyStore, err = apikeys.Open(file)
+				if err != nil {
+					return err
+				}
+				opts = append(opts, options.WithApiKeyStore(keyStore))
+			}
+
+			if dir := ctx.String("vector-stores-path"); dir != "" {
+				vectorStore, err := vectorstore.Open(dir)
+				if err != nil {
+					return err
+				}
+				opts = append(opts, options.WithVectorStore(vectorStore))
+			}
+
+			if issuer := ctx.String("oidc-issuer"); issuer != "" {
+				mapping, err := oidc.ParseScopeMapping(ctx.StringSlice("oidc-scope-map"))
+				if err != nil {
+					return err
+				}
+				verifier, err := oidc.New(context.Background(), oidc.Config{
+					Issuer:       issuer,
+					Audience:     ctx.String("oidc-audience"),
+					ScopesClaim:  ctx.String("oidc-scopes-claim"),
+					ScopeMapping: mapping,
+					TenantClaim:  ctx.String("oidc-tenant-claim"),
+				})
+				if err != nil {
+					return err
+				}
+				opts = append(opts, options.WithOIDC(verifier))
+			}
+
+			if secretStore != nil {
+				opts = append(opts, options.WithSecrets(secretStore))
+			}
+
+			if ctx.Bool("tenant-namespaces") {
+				opts = append(opts, options.WithTenantNamespaces(ctx.Bool("tenant-shared-models")))
+			}
+
+			if db := ctx.String("usage-db"); db != "" {
+				store, err := usage.Open(db)
+				if err != nil {
+					return fmt.Errorf("failed opening the usage database: %w", err)
+				}
+				opts = append(opts, options.WithUsage(store))
+
+				if file := ctx.String("quotas"); file != "" {
+					quotas, err := quota.Load(file)
+					if err != nil {
+						return err
+					}
+					opts = append(opts, options.WithQuotas(quota.NewEnforcer(quotas, store)))
+				}
+			} else if ctx.String("quotas") != "" {
+				return fmt.Errorf("the quotas need the usage accounting of --usage-db")
+			}
+
 			if ctx.Bool("preload-backend-only") {
 				_, _, err := api.Startup(opts...)
 				return err
@@ -297,12 +811,41 @@ For a list of compatible model, check out: https://localai.io/model-compatibilit
 				return err
 			}
 
-			return app.Listen(ctx.String("address"))
+			cert, key := ctx.String("tls-cert"), ctx.String("tls-key")
+			if cert == "" && key == "" {
+				return app.Listen(ctx.String("address"))
+			}
+			if cert == "" || key == "" {
+				return fmt.Errorf("both --tls-cert and --tls-key are required to serve over HTTPS")
+			}
+			certs, err := tlscert.NewReloader(cert, key)
+			if err != nil {
+				return err
+			}
+			// the renewed certificates are picked up without restarting
+			hup := make(chan os.Signal, 1)
+			signal.Notify(hup, syscall.SIGHUP)
+			go certs.Run(context.Background(), time.Minute, hup)
+
+			ln, err := net.Listen("tcp", ctx.String("address"))
+			if err != nil {
+				return err
+			}
+			return app.Listener(tls.NewListener(ln, certs.Config()))
 		},
 		Commands: []*cli.Command{
+			{
+				// started by LocalAI to execute the backends in their sandbox
+				Name:            sandbox.Command,
+				Hidden:          true,
+				SkipFlagParsing: true,
+				Action: func(ctx *cli.Context) error {
+					return sandbox.Exec(ctx.Args().Slice())
+				},
+			},
 			{
 				Name:  "models",
-				Usage: "List or install models",
+				Usage: "List, install or quantize models",
 				Subcommands: []*cli.Command{
 					{
 						Name:  "list",
@@ -313,7 +856,11 @@ For a list of compatible model, check out: https://localai.io/model-compatibilit
 								log.Error().Msgf("unable to load galleries: %s", err.Error())
 							}
 
-							models, err := gallery.AvailableGalleryModels(galleries, ctx.String("models-path"))
+							loader, err := newModelLoader(ctx)
+							if err != nil {
+								return err
+							}
+							models, err := gallery.AvailableGalleryModels(galleries, loader.ModelPath)
 							if err != nil {
 								return err
 							}
@@ -347,10 +894,118 @@ For a list of compatible model, check out: https://localai.io/model-compatibilit
 							progressCallback := func(fileName string, current string, total string, percentage float64) {
 								progressBar.Set(int(percentage * 10))
 							}
-							err = gallery.InstallModelFromGallery(galleries, modelName, ctx.String("models-path"), gallery.GalleryModel{}, progressCallback)
+							loader, err := newModelLoader(ctx)
+							if err != nil {
+								return err
+							}
+							secretStore, err := loadSecrets(ctx)
+							if err != nil {
+								return err
+							}
+							err = gallery.InstallModelFromGallery(galleries, modelName, loader.ModelPath, gallery.GalleryModel{}, progressCallback, gallery.WithSecrets(secretStore))
+							if err != nil {
+								return err
+							}
+							return nil
+						},
+					},
+					{
+						Name:      "smoke-test",
+						Usage:     "Run the smoke tests of models on a running instance, optionally routing an alias to the model once they pass",
+						ArgsUsage: "<model>...",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "url",
+								Usage:   "URL of the LocalAI instance",
+								EnvVars: []string{"LOCALAI_URL"},
+								Value:   "http://localhost:8080",
+							},
+							&cli.StringFlag{
+								Name:    "key",
+								Usage:   "API key of the instance, with the admin scope",
+								EnvVars: []string{"LOCALAI_API_KEY"},
+							},
+							&cli.StringFlag{
+								Name:  "rollout",
+								Usage: "Alias routed to the model if its smoke tests pass, the alias keeps its current model otherwise",
+							},
+						},
+						Action: func(ctx *cli.Context) error {
+							models := ctx.Args().Slice()
+							if len(models) == 0 || (ctx.String("rollout") != "" && len(models) != 1) {
+								return fmt.Errorf("a model is required, and a single one with --rollout")
+							}
+							c := client.New(ctx.String("url"), client.WithAPIKey(ctx.String("key")))
+
+							failed := false
+							report := func(r client.SmokeTestResponse) {
+								for _, res := range r.Results {
+									status := "PASS"
+									if !res.Passed {
+										status, failed = "FAIL", true
+									}
+									fmt.Printf("%s %s/%s (%dms) %s\n", status, r.Model, res.Name, res.Latency, res.Error)
+								}
+							}
+							if alias := ctx.String("rollout"); alias != "" {
+								resp, err := c.Rollout(ctx.Context, alias, models[0])
+								report(resp.SmokeTest)
+								if resp.Switched {
+									fmt.Printf("Routed %s to %s\n", alias, models[0])
+									return nil
+								}
+								if err != nil && len(resp.SmokeTest.Results) == 0 {
+									return err
+								}
+								return cli.Exit(fmt.Sprintf("%s keeps %q, the smoke tests of %s failed", alias, resp.Previous, models[0]), 1)
+							}
+							for _, m := range models {
+								resp, err := c.SmokeTest(ctx.Context, m)
+								if err != nil {
+									return err
+								}
+								report(*resp)
+							}
+							if failed {
+								return cli.Exit("smoke tests failed", 1)
+							}
+							return nil
+						},
+					},
+					{
+						Name:      "quantize",
+						Usage:     "Quantize a GGUF model to another type, as a new model",
+						ArgsUsage: "<model> <type>",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "name",
+								Usage: "Name of the new model, defaults to the model name with the type as suffix",
+							},
+						},
+						Action: func(ctx *cli.Context) error {
+							if ctx.Args().Len() != 2 {
+								return fmt.Errorf("a model and a quantization type (%s) are required", strings.Join(gallery.QuantizationTypes, ", "))
+							}
+							req := localai.QuantizeRequest{Model: ctx.Args().Get(0), Type: ctx.Args().Get(1), Name: ctx.String("name")}
+
+							progressBar := progressbar.NewOptions(
+								1000,
+								progressbar.OptionSetDescription(fmt.Sprintf("quantizing model %s to %s", req.Model, req.Type)),
+								progressbar.OptionShowBytes(false),
+								progressbar.OptionClearOnFinish(),
+							)
+							loader, err := newModelLoader(ctx)
 							if err != nil {
 								return err
 							}
+							opts := &options.Option{AssetsDestination: ctx.String("backend-assets-path")}
+							name, err := localai.QuantizeModel(loader.ModelPath, opts.QuantizeToolPath(), ctx.Int("threads"), req, func(p float64) {
+								progressBar.Set(int(p * 10))
+							})
+							if err != nil {
+								return err
+							}
+							fmt.Printf("Model %s created\n", name)
 							return nil
 						},
 					},
@@ -395,8 +1050,12 @@ For a list of compatible model, check out: https://localai.io/model-compatibilit
 
 					text := strings.Join(ctx.Args().Slice(), " ")
 
+					loader, err := newModelLoader(ctx)
+					if err != nil {
+						return err
+					}
 					opts := &options.Option{
-						Loader:            model.NewModelLoader(ctx.String("models-path")),
+						Loader:            loader,
 						Context:           context.Background(),
 						AudioDir:          outputDir,
 						AssetsDestination: ctx.String("backend-assets-path"),
@@ -404,7 +1063,7 @@ For a list of compatible model, check out: https://localai.io/model-compatibilit
 
 					defer opts.Loader.StopAllGRPC()
 
-					filePath, _, err := backend.ModelTTS(backendOption, text, modelOption, opts.Loader, opts, config.Config{})
+					filePath, _, err := backend.ModelTTS(text, config.Voice{Backend: backendOption, Model: modelOption}, opts.Loader, opts, config.Config{})
 					if err != nil {
 						return err
 					}
@@ -419,6 +1078,46 @@ For a list of compatible model, check out: https://localai.io/model-compatibilit
 					return nil
 				},
 			},
+			{
+				Name:      "replay",
+				Usage:     "Replay an inference trace and show the differences with the recorded output",
+				ArgsUsage: "<trace file>",
+				Action: func(ctx *cli.Context) error {
+					if ctx.Args().Len() != 1 {
+						return errors.New("a trace file is required")
+					}
+
+					trace, err := backend.LoadTrace(ctx.Args().First())
+					if err != nil {
+						return err
+					}
+
+					loader, err := newModelLoader(ctx)
+					if err != nil {
+						return err
+					}
+					opts := &options.Option{
+						Loader:            loader,
+						Context:           context.Background(),
+						AssetsDestination: ctx.String("backend-assets-path"),
+					}
+
+					defer opts.Loader.StopAllGRPC()
+
+					replay, err := backend.ReplayTrace(trace, opts.Loader, opts)
+					if err != nil {
+						return err
+					}
+
+					diff := trace.Diff(replay)
+					if diff == "" {
+						fmt.Println("The replay matches the recorded output")
+						return nil
+					}
+					fmt.Print(diff)
+					return errors.New("the replay differs from the recorded output")
+				},
+			},
 			{
 				Name:  "transcript",
 				Usage: "Convert audio to text",
@@ -439,6 +1138,10 @@ For a list of compatible model, check out: https://localai.io/model-compatibilit
 						Aliases: []string{"l"},
 						Usage:   "Language of the audio file",
 					},
+					&cli.BoolFlag{
+						Name:  "translate",
+						Usage: "Translate the audio to English",
+					},
 					&cli.IntFlag{
 						Name:    "threads",
 						Aliases: []string{"t"},
@@ -457,14 +1160,18 @@ For a list of compatible model, check out: https://localai.io/model-compatibilit
 					language := ctx.String("language")
 					threads := ctx.Int("threads")
 
+					loader, err := newModelLoader(ctx)
+					if err != nil {
+						return err
+					}
 					opts := &options.Option{
-						Loader:            model.NewModelLoader(ctx.String("models-path")),
+						Loader:            loader,
 						Context:           context.Background(),
 						AssetsDestination: ctx.String("backend-assets-path"),
 					}
 
 					cl := config.NewConfigLoader()
-					if err := cl.LoadConfigs(ctx.String("models-path")); err != nil {
+					if err := loadConfigs(cl, loader); err != nil {
 						return err
 					}
 
@@ -477,7 +1184,7 @@ For a list of compatible model, check out: https://localai.io/model-compatibilit
 
 					defer opts.Loader.StopAllGRPC()
 
-					tr, err := backend.ModelTranscription(filename, language, opts.Loader, c, opts)
+					tr, err := backend.ModelTranscription(filename, language, ctx.Bool("translate"), false, opts.Loader, c, opts)
 					if err != nil {
 						return err
 					}
@@ -487,6 +1194,102 @@ For a list of compatible model, check out: https://localai.io/model-compatibilit
 					return nil
 				},
 			},
+			{
+				Name:  "calibrate",
+				Usage: "Fit the temperature scaling of the scores of a model on a labeled dataset",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "model",
+						Aliases:  []string{"m"},
+						Usage:    "Model name to calibrate",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "dataset",
+						Aliases:  []string{"d"},
+						Usage:    "JSONL file of the labeled inputs, e.g. {\"input\": \"...\", \"label\": true}",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "label",
+						Usage: "Label of a classification model to calibrate the score of",
+					},
+					&cli.Float64Flag{
+						Name:  "scale",
+						Usage: "Highest score generated by the model",
+					},
+					&cli.BoolFlag{
+						Name:  "write",
+						Usage: "Store the calibration in the config file of the model",
+					},
+				},
+				Action: func(ctx *cli.Context) error {
+					modelOption := ctx.String("model")
+
+					f, err := os.ReadFile(ctx.String("dataset"))
+					if err != nil {
+						return err
+					}
+					samples := []backend.CalibrationSample{}
+					for i, line := range strings.Split(string(f), "\n") {
+						if strings.TrimSpace(line) == "" {
+							continue
+						}
+						var sample backend.CalibrationSample
+						if err := json.Unmarshal([]byte(line), &sample); err != nil {
+							return fmt.Errorf("invalid sample at line %d: %w", i+1, err)
+						}
+						samples = append(samples, sample)
+					}
+
+					loader, err := newModelLoader(ctx)
+					if err != nil {
+						return err
+					}
+					opts := &options.Option{
+						Loader:            loader,
+						Context:           context.Background(),
+						AssetsDestination: ctx.String("backend-assets-path"),
+					}
+
+					cl := config.NewConfigLoader()
+					if err := loadConfigs(cl, loader); err != nil {
+						return err
+					}
+
+					c, exists := cl.GetConfig(modelOption)
+					if !exists {
+						return errors.New("model not found")
+					}
+					if ctx.IsSet("scale") {
+						c.Calibration.Scale = ctx.Float64("scale")
+					}
+
+					defer opts.Loader.StopAllGRPC()
+
+					report, err := backend.CalibrateModel(samples, ctx.String("label"), opts.Loader, c, opts)
+					if err != nil {
+						return err
+					}
+					fmt.Printf("Calibrated on %d samples (%d without a score)\n", report.Samples, report.Skipped)
+					fmt.Printf("Log loss: %.4f -> %.4f\n", report.LogLoss, report.CalibratedLogLoss)
+					fmt.Printf("Expected calibration error: %.4f -> %.4f\n", report.ECE, report.CalibratedECE)
+					fmt.Printf("calibration:\n  temperature: %g\n  bias: %g\n", report.Calibration.Temperature, report.Calibration.Bias)
+
+					if !ctx.Bool("write") {
+						return nil
+					}
+					file, err := config.FindConfigFile(loader.ModelPath, modelOption)
+					if err != nil {
+						return err
+					}
+					if err := config.UpdateConfigFile(file, "calibration", report.Calibration); err != nil {
+						return err
+					}
+					fmt.Printf("Updated %s\n", file)
+					return nil
+				},
+			},
 		},
 	}
 
@@ -496,3 +1299,32 @@ For a list of compatible model, check out: https://localai.io/model-compatibilit
 		os.Exit(1)
 	}
 }
+
+// loadSecrets returns the secrets of --secrets-file, nil when not set
+func loadSecrets(ctx *cli.Context) (*secrets.Store, error) {
+	file := ctx.String("secrets-file")
+	if file == "" {
+		return nil, nil
+	}
+	return secrets.Load(file)
+}
+
+// newModelLoader returns the loader of the models directories of --models-path
+func newModelLoader(ctx *cli.Context) (*model.ModelLoader, error) {
+	loader := model.NewModelLoader("")
+	if err := loader.SetModelDirs(model.ParseModelDirs(ctx.String("models-path"))); err != nil {
+		return nil, err
+	}
+	return loader, nil
+}
+
+// loadConfigs loads the configurations of the models directories, the ones of the first directories take precedence
+func loadConfigs(cl *config.ConfigLoader, loader *model.ModelLoader) error {
+	dirs := loader.ModelDirs()
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := cl.LoadConfigs(dirs[i].Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
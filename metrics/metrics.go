@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	fiberContext "github.com/go-skynet/LocalAI/api/ctx"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -16,6 +17,14 @@ import (
 type Metrics struct {
 	meter         api.Meter
 	apiTimeMetric api.Float64Histogram
+	queueLength   api.Int64UpDownCounter
+	queueWait     api.Float64Histogram
+	queueRejected api.Int64Counter
+
+	tokensGenerated  api.Int64Counter
+	tokensPerSecond  api.Float64Histogram
+	backendLoad      api.Float64Histogram
+	backendLoadFails api.Int64Counter
 }
 
 // setupOTelSDK bootstraps the OpenTelemetry pipeline.
@@ -33,9 +42,51 @@ func SetupMetrics() (*Metrics, error) {
 		return nil, err
 	}
 
+	queueLength, err := meter.Int64UpDownCounter("queue_length", api.WithDescription("requests waiting for a model"))
+	if err != nil {
+		return nil, err
+	}
+
+	queueWait, err := meter.Float64Histogram("queue_wait", api.WithDescription("time the requests waited for a model"), api.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+
+	queueRejected, err := meter.Int64Counter("queue_rejected", api.WithDescription("requests rejected as the queue of the model was full"))
+	if err != nil {
+		return nil, err
+	}
+
+	tokensGenerated, err := meter.Int64Counter("tokens_generated", api.WithDescription("tokens generated by the models"))
+	if err != nil {
+		return nil, err
+	}
+
+	tokensPerSecond, err := meter.Float64Histogram("tokens_per_second", api.WithDescription("tokens generated per second by the inferences"))
+	if err != nil {
+		return nil, err
+	}
+
+	backendLoad, err := meter.Float64Histogram("backend_load", api.WithDescription("time the backends took to load the models"), api.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+
+	backendLoadFails, err := meter.Int64Counter("backend_load_failures", api.WithDescription("backends which failed loading a model"))
+	if err != nil {
+		return nil, err
+	}
+
 	return &Metrics{
-		meter:         meter,
-		apiTimeMetric: apiTimeMetric,
+		meter:            meter,
+		apiTimeMetric:    apiTimeMetric,
+		queueLength:      queueLength,
+		queueWait:        queueWait,
+		queueRejected:    queueRejected,
+		tokensGenerated:  tokensGenerated,
+		tokensPerSecond:  tokensPerSecond,
+		backendLoad:      backendLoad,
+		backendLoadFails: backendLoadFails,
 	}, nil
 }
 
@@ -69,15 +120,71 @@ func APIMiddleware(metrics *Metrics) fiber.Handler {
 		start := time.Now()
 		err := c.Next()
 		elapsed := float64(time.Since(start)) / float64(time.Second)
-		cfg.metrics.ObserveAPICall(method, path, elapsed)
+		cfg.metrics.ObserveAPICall(method, path, fiberContext.RequestModel(c), fiberContext.ResponseStatus(c, err), elapsed)
 		return err
 	}
 }
 
-func (m *Metrics) ObserveAPICall(method string, path string, duration float64) {
+// ObserveAPICall records an API call, with the model it was for if any, which took the given time in seconds
+func (m *Metrics) ObserveAPICall(method, path, model string, status int, duration float64) {
 	opts := api.WithAttributes(
 		attribute.String("method", method),
 		attribute.String("path", path),
+		attribute.String("model", model),
+		attribute.Int("status", status),
 	)
 	m.apiTimeMetric.Record(context.Background(), duration, opts)
 }
+
+// ObserveQueued records a request queued for the model
+func (m *Metrics) ObserveQueued(model string) {
+	m.queueLength.Add(context.Background(), 1, api.WithAttributes(attribute.String("model", model)))
+}
+
+// ObserveDequeued records a request which left the queue of the model after the given time in seconds, once it
+// started, or when it was canceled or dropped
+func (m *Metrics) ObserveDequeued(model string, wait float64) {
+	opts := api.WithAttributes(attribute.String("model", model))
+	m.queueLength.Add(context.Background(), -1, opts)
+	m.queueWait.Record(context.Background(), wait, opts)
+}
+
+// ObserveRejected records a request rejected, or dropped, as the queue of the model was full
+func (m *Metrics) ObserveRejected(model string) {
+	m.queueRejected.Add(context.Background(), 1, api.WithAttributes(attribute.String("model", model)))
+}
+
+// ObserveTokens records the tokens generated by an inference of the model in the given time in seconds
+func (m *Metrics) ObserveTokens(model string, tokens int, duration float64) {
+	opts := api.WithAttributes(attribute.String("model", model))
+	m.tokensGenerated.Add(context.Background(), int64(tokens), opts)
+	if duration > 0 {
+		m.tokensPerSecond.Record(context.Background(), float64(tokens)/duration, opts)
+	}
+}
+
+// ObserveBackendLoad records a backend which loaded the model in the given time, or failed to with err
+func (m *Metrics) ObserveBackendLoad(backend, model string, duration time.Duration, err error) {
+	opts := api.WithAttributes(
+		attribute.String("backend", backend),
+		attribute.String("model", model),
+	)
+	if err != nil {
+		m.backendLoadFails.Add(context.Background(), 1, opts)
+		return
+	}
+	m.backendLoad.Record(context.Background(), duration.Seconds(), opts)
+}
+
+// ObserveBackendProcesses reports the backends running, by backend, with the counts returned by processes
+func (m *Metrics) ObserveBackendProcesses(processes func() map[string]int) error {
+	_, err := m.meter.Int64ObservableGauge("backend_processes",
+		api.WithDescription("backends running, serving a model"),
+		api.WithInt64Callback(func(_ context.Context, o api.Int64Observer) error {
+			for backend, n := range processes() {
+				o.Observe(int64(n), api.WithAttributes(attribute.String("backend", backend)))
+			}
+			return nil
+		}))
+	return err
+}
@@ -0,0 +1,217 @@
+// Package apikeys manages the API keys created by the admins, each allowed a set of scopes. The keys are stored on
+// disk as SHA-256 hashes only: they are only known to the caller of Create.
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-skynet/LocalAI/pkg/audit"
+)
+
+// The scopes of the keys. The admin scope allows everything, including the management of the keys.
+const (
+	ScopeChat       = "chat"
+	ScopeEmbeddings = "embeddings"
+	ScopeAudio      = "audio"
+	ScopeImages     = "images"
+	ScopeAdmin      = "admin"
+)
+
+var Scopes = []string{ScopeChat, ScopeEmbeddings, ScopeAudio, ScopeImages, ScopeAdmin}
+
+var ErrNotFound = errors.New("API key not found")
+
+type Key struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Tenant groups the keys of a team, e.g. to share a quota
+	Tenant string   `json:"tenant,omitempty"`
+	Scopes []string `json:"scopes"`
+	// Fingerprint identifies the key in the audit log and the usage
+	Fingerprint string    `json:"fingerprint"`
+	Created     time.Time `json:"created"`
+}
+
+// Allows returns true if the key is allowed the scope. Any key is allowed the empty scope.
+func (k Key) Allows(scope string) bool {
+	if scope == "" {
+		return true
+	}
+	for _, s := range k.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+type storedKey struct {
+	Key
+	Hash string `json:"hash"`
+}
+
+type Store struct {
+	path string
+
+	mu     sync.RWMutex
+	keys   []storedKey
+	byHash map[string]storedKey
+}
+
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Open loads the keys stored in the file at path, if it exists
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, byHash: map[string]storedKey{}}
+	dat, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(dat, &s.keys); err != nil {
+		return nil, fmt.Errorf("failed parsing the API keys of %s: %w", path, err)
+	}
+	for _, k := range s.keys {
+		s.byHash[k.Hash] = k
+	}
+	return s, nil
+}
+
+// save writes the keys to a temporary file first, to never leave a truncated file
+func (s *Store) save(keys []storedKey) error {
+	dat, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, dat, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Create creates a key of the tenant allowed the scopes, and returns it along with its secret, which is not stored
+func (s *Store) Create(name, tenant string, scopes []string) (Key, string, error) {
+	if len(scopes) == 0 {
+		return Key{}, "", fmt.Errorf("an API key needs at least one scope, of %v", Scopes)
+	}
+	for _, sc := range scopes {
+		known := false
+		for _, k := range Scopes {
+			known = known || sc == k
+		}
+		if !known {
+			return Key{}, "", fmt.Errorf("unknown scope %q, expected one of %v", sc, Scopes)
+		}
+	}
+
+	// the ID is listed, it is drawn apart from the secret so that it tells nothing about it
+	random := make([]byte, 32+6)
+	if _, err := rand.Read(random); err != nil {
+		return Key{}, "", err
+	}
+	secret := "sk-" + hex.EncodeToString(random[:32])
+	k := storedKey{
+		Key: Key{
+			ID:          hex.EncodeToString(random[32:]),
+			Name:        name,
+			Tenant:      tenant,
+			Scopes:      scopes,
+			Fingerprint: audit.Fingerprint(secret),
+			Created:     time.Now().UTC(),
+		},
+		Hash: hash(secret),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := append(append([]storedKey{}, s.keys...), k)
+	if err := s.save(keys); err != nil {
+		return Key{}, "", fmt.Errorf("failed storing the API key: %w", err)
+	}
+	s.keys = keys
+	s.byHash[k.Hash] = k
+	return k.Key, secret, nil
+}
+
+// Revoke deletes the key with the given ID
+func (s *Store) Revoke(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := []storedKey{}
+	var revoked *storedKey
+	for i, k := range s.keys {
+		if k.ID == id {
+			revoked = &s.keys[i]
+			continue
+		}
+		keys = append(keys, k)
+	}
+	if revoked == nil {
+		return ErrNotFound
+	}
+	if err := s.save(keys); err != nil {
+		return fmt.Errorf("failed revoking the API key: %w", err)
+	}
+	delete(s.byHash, revoked.Hash)
+	s.keys = keys
+	return nil
+}
+
+// List returns the keys, sorted by creation
+func (s *Store) List() []Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]Key, 0, len(s.keys))
+	for _, k := range s.keys {
+		keys = append(keys, k.Key)
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].Created.Before(keys[j].Created) })
+	return keys
+}
+
+// Lookup returns the key of the secret, if it was not revoked
+func (s *Store) Lookup(secret string) (Key, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	k, ok := s.byHash[hash(secret)]
+	return k.Key, ok
+}
+
+// Fingerprints returns the fingerprints of the keys of the tenant
+func (s *Store) Fingerprints(tenant string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fingerprints := []string{}
+	for _, k := range s.keys {
+		if k.Tenant == tenant {
+			fingerprints = append(fingerprints, k.Fingerprint)
+		}
+	}
+	return fingerprints
+}
+
+// Empty returns true if there are no keys
+func (s *Store) Empty() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.keys) == 0
+}
@@ -0,0 +1,102 @@
+package apikeys_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/go-skynet/LocalAI/pkg/apikeys"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("API keys", func() {
+	var tempdir, file string
+
+	BeforeEach(func() {
+		var err error
+		tempdir, err = os.MkdirTemp("", "apikeys")
+		Expect(err).ToNot(HaveOccurred())
+		file = filepath.Join(tempdir, "keys.json")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempdir)
+	})
+
+	It("stores the keys hashed", func() {
+		s, err := apikeys.Open(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.Empty()).To(BeTrue())
+
+		k, secret, err := s.Create("team-a", "", []string{apikeys.ScopeChat})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(secret).To(HavePrefix("sk-"))
+		Expect(secret).ToNot(ContainSubstring(k.ID))
+
+		dat, err := os.ReadFile(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(dat)).ToNot(ContainSubstring(secret))
+		Expect(string(dat)).To(ContainSubstring("team-a"))
+
+		s, err = apikeys.Open(file)
+		Expect(err).ToNot(HaveOccurred())
+		found, ok := s.Lookup(secret)
+		Expect(ok).To(BeTrue())
+		Expect(found.ID).To(Equal(k.ID))
+		Expect(s.List()).To(HaveLen(1))
+
+		_, ok = s.Lookup("sk-wrong")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("allows the scopes of the keys", func() {
+		s, err := apikeys.Open(file)
+		Expect(err).ToNot(HaveOccurred())
+
+		chat, _, err := s.Create("chat", "", []string{apikeys.ScopeChat})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(chat.Allows(apikeys.ScopeChat)).To(BeTrue())
+		Expect(chat.Allows("")).To(BeTrue())
+		Expect(chat.Allows(apikeys.ScopeEmbeddings)).To(BeFalse())
+		Expect(chat.Allows(apikeys.ScopeAdmin)).To(BeFalse())
+
+		admin, _, err := s.Create("admin", "", []string{apikeys.ScopeAdmin})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(admin.Allows(apikeys.ScopeEmbeddings)).To(BeTrue())
+
+		_, _, err = s.Create("none", "", nil)
+		Expect(err).To(HaveOccurred())
+		_, _, err = s.Create("unknown", "", []string{"everything"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("lists the keys of a tenant", func() {
+		s, err := apikeys.Open(file)
+		Expect(err).ToNot(HaveOccurred())
+		a1, _, err := s.Create("a1", "team-a", []string{apikeys.ScopeChat})
+		Expect(err).ToNot(HaveOccurred())
+		a2, _, err := s.Create("a2", "team-a", []string{apikeys.ScopeEmbeddings})
+		Expect(err).ToNot(HaveOccurred())
+		_, _, err = s.Create("b", "team-b", []string{apikeys.ScopeChat})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(s.Fingerprints("team-a")).To(ConsistOf(a1.Fingerprint, a2.Fingerprint))
+		Expect(s.Fingerprints("team-c")).To(BeEmpty())
+	})
+
+	It("revokes the keys", func() {
+		s, err := apikeys.Open(file)
+		Expect(err).ToNot(HaveOccurred())
+		k, secret, err := s.Create("team-a", "", []string{apikeys.ScopeChat})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(s.Revoke(k.ID)).To(Succeed())
+		_, ok := s.Lookup(secret)
+		Expect(ok).To(BeFalse())
+		Expect(s.Revoke(k.ID)).To(MatchError(apikeys.ErrNotFound))
+
+		s, err = apikeys.Open(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.Empty()).To(BeTrue())
+	})
+})
@@ -0,0 +1,159 @@
+// Package audit records who called which endpoint of LocalAI, with which model, for compliance.
+// It is disabled unless a destination is configured. The prompts are only recorded as hashes,
+// unless their contents are enabled, and the entries go through the redactors before being written.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// Syslog is the destination writing the entries to the local syslog daemon
+const Syslog = "syslog"
+
+type Entry struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"request_id,omitempty"`
+	// Caller is the fingerprint of the API key of the caller, if any
+	Caller   string `json:"caller,omitempty"`
+	RemoteIP string `json:"remote_ip"`
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Status   int    `json:"status"`
+	Model    string `json:"model,omitempty"`
+	// PromptHashes are the SHA-256 of the prompts sent to the model
+	PromptHashes []string `json:"prompt_hashes,omitempty"`
+	// Prompts are the contents of the prompts, only recorded when enabled
+	Prompts          []string `json:"prompts,omitempty"`
+	PromptTokens     int      `json:"prompt_tokens,omitempty"`
+	CompletionTokens int      `json:"completion_tokens,omitempty"`
+	DurationMS       int64    `json:"duration_ms"`
+}
+
+// Redactor changes the entries before they are written, e.g. to mask the personal data of the prompts
+type Redactor func(e *Entry)
+
+type Logger struct {
+	prompts   bool
+	patterns  []string
+	redactors []Redactor
+
+	sync.Mutex
+	out io.WriteCloser
+}
+
+type Option func(*Logger)
+
+// WithPrompts records the contents of the prompts, in addition to their hashes
+func WithPrompts() Option {
+	return func(l *Logger) {
+		l.prompts = true
+	}
+}
+
+// WithRedactor adds a redactor, run on the entries in the order they were added
+func WithRedactor(r Redactor) Option {
+	return func(l *Logger) {
+		l.redactors = append(l.redactors, r)
+	}
+}
+
+// WithRedactedPatterns replaces the matches of the regular expressions in the prompts with [REDACTED]
+func WithRedactedPatterns(patterns ...string) Option {
+	return func(l *Logger) {
+		l.patterns = append(l.patterns, patterns...)
+	}
+}
+
+// redactPattern returns the redactor of the matches of the regular expression
+func redactPattern(pattern string) (Redactor, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+	}
+	return func(e *Entry) {
+		for i := range e.Prompts {
+			e.Prompts[i] = re.ReplaceAllString(e.Prompts[i], "[REDACTED]")
+		}
+	}, nil
+}
+
+// New returns a logger writing the entries as JSON lines to the file at destination, appended to, or to the local
+// syslog daemon when destination is Syslog
+func New(destination string, opts ...Option) (*Logger, error) {
+	l := &Logger{}
+	for _, o := range opts {
+		o(l)
+	}
+	// the patterns are redacted first
+	redactors := []Redactor{}
+	for _, p := range l.patterns {
+		r, err := redactPattern(p)
+		if err != nil {
+			return nil, err
+		}
+		redactors = append(redactors, r)
+	}
+	l.redactors = append(redactors, l.redactors...)
+
+	if destination == Syslog {
+		out, err := newSyslogWriter()
+		if err != nil {
+			return nil, fmt.Errorf("failed connecting to syslog: %w", err)
+		}
+		l.out = out
+		return l, nil
+	}
+	f, err := os.OpenFile(destination, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed opening the audit log: %w", err)
+	}
+	l.out = f
+	return l, nil
+}
+
+// Fingerprint identifies an API key in the entries, without recording it
+func Fingerprint(key string) string {
+	if key == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(key))
+	return "sha256:" + hex.EncodeToString(sum[:])[:16]
+}
+
+// Record hashes the prompts of the entry, dropping their contents unless enabled, runs the redactors and writes it
+func (l *Logger) Record(e Entry) error {
+	e.PromptHashes = nil
+	for _, p := range e.Prompts {
+		sum := sha256.Sum256([]byte(p))
+		e.PromptHashes = append(e.PromptHashes, hex.EncodeToString(sum[:]))
+	}
+	if !l.prompts {
+		e.Prompts = nil
+	}
+	for _, r := range l.redactors {
+		r(&e)
+	}
+
+	dat, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	l.Lock()
+	defer l.Unlock()
+	_, err = l.out.Write(append(dat, '\n'))
+	return err
+}
+
+func (l *Logger) Close() error {
+	l.Lock()
+	defer l.Unlock()
+	return l.out.Close()
+}
@@ -0,0 +1,100 @@
+package audit_test
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/go-skynet/LocalAI/pkg/audit"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func hashOf(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func readEntries(file string) []map[string]interface{} {
+	f, err := os.Open(file)
+	Expect(err).ToNot(HaveOccurred())
+	defer f.Close()
+	entries := []map[string]interface{}{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		e := map[string]interface{}{}
+		Expect(json.Unmarshal(scanner.Bytes(), &e)).To(Succeed())
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+var _ = Describe("Audit log", func() {
+	var tempdir, file string
+
+	BeforeEach(func() {
+		var err error
+		tempdir, err = os.MkdirTemp("", "audit")
+		Expect(err).ToNot(HaveOccurred())
+		file = filepath.Join(tempdir, "audit.log")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempdir)
+	})
+
+	It("records the hashes of the prompts, not their contents", func() {
+		l, err := audit.New(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(l.Record(audit.Entry{Method: "POST", Path: "/v1/chat/completions", Status: 200, Model: "mistral", Caller: audit.Fingerprint("key"), Prompts: []string{"my secret"}, CompletionTokens: 12})).To(Succeed())
+		Expect(l.Record(audit.Entry{Method: "GET", Path: "/v1/models", Status: 200})).To(Succeed())
+		Expect(l.Close()).To(Succeed())
+
+		dat, err := os.ReadFile(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(dat)).ToNot(ContainSubstring("my secret"))
+		Expect(string(dat)).ToNot(ContainSubstring(`"key"`))
+
+		entries := readEntries(file)
+		Expect(entries).To(HaveLen(2))
+		Expect(entries[0]["model"]).To(Equal("mistral"))
+		Expect(entries[0]["caller"]).To(HavePrefix("sha256:"))
+		Expect(entries[0]["completion_tokens"]).To(Equal(12.0))
+		Expect(entries[0]["prompt_hashes"]).To(Equal([]interface{}{hashOf("my secret")}))
+		Expect(entries[1]).ToNot(HaveKey("prompt_hashes"))
+	})
+
+	It("appends to the file", func() {
+		for i := 0; i < 2; i++ {
+			l, err := audit.New(file)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(l.Record(audit.Entry{Path: "/v1/models"})).To(Succeed())
+			Expect(l.Close()).To(Succeed())
+		}
+		Expect(readEntries(file)).To(HaveLen(2))
+	})
+
+	It("records the redacted contents of the prompts when enabled", func() {
+		l, err := audit.New(file, audit.WithPrompts(), audit.WithRedactedPatterns(`\b\d{4}-\d{4}-\d{4}-\d{4}\b`), audit.WithRedactor(func(e *audit.Entry) {
+			e.RemoteIP = ""
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(l.Record(audit.Entry{RemoteIP: "10.0.0.1", Prompts: []string{"my card is 1234-5678-9012-3456"}})).To(Succeed())
+		Expect(l.Close()).To(Succeed())
+
+		entries := readEntries(file)
+		Expect(entries[0]["prompts"]).To(Equal([]interface{}{"my card is [REDACTED]"}))
+		// the hashes are of the original prompts
+		Expect(entries[0]["prompt_hashes"]).To(Equal([]interface{}{hashOf("my card is 1234-5678-9012-3456")}))
+		Expect(entries[0]["remote_ip"]).To(BeEmpty())
+	})
+
+	It("rejects the invalid patterns", func() {
+		_, err := audit.New(file, audit.WithRedactedPatterns(`(`))
+		Expect(err).To(MatchError(ContainSubstring("invalid redaction pattern")))
+		Expect(file).ToNot(BeAnExistingFile())
+	})
+})
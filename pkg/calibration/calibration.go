@@ -0,0 +1,158 @@
+// Package calibration fits and applies the temperature scaling of the scores generated by a model,
+// so they can be used as probabilities: the calibrated probability of a score p is
+// sigmoid(logit(p) / temperature + bias).
+package calibration
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const epsilon = 1e-6
+
+// Apply returns the calibrated probability of the score p, between 0 and 1
+func Apply(p, temperature, bias float64) float64 {
+	if temperature <= 0 {
+		temperature = 1
+	}
+	return sigmoid(logit(p)/temperature + bias)
+}
+
+// Fit returns the temperature and the bias minimizing the negative log likelihood
+// of the labels given the scores, with the Newton's method
+func Fit(scores []float64, labels []bool) (temperature, bias float64, err error) {
+	if len(scores) != len(labels) {
+		return 0, 0, fmt.Errorf("%d scores for %d labels", len(scores), len(labels))
+	}
+	positives := 0
+	for _, l := range labels {
+		if l {
+			positives++
+		}
+	}
+	if positives == 0 || positives == len(labels) {
+		return 0, 0, fmt.Errorf("both positive and negative labels are required")
+	}
+
+	// logistic regression of the labels on the logits of the scores: sigmoid(a*x + b), with a = 1/temperature.
+	// A small L2 penalty keeps the parameters finite when the scores separate the labels.
+	const penalty = 1e-3
+	a, b := 1.0, 0.0
+	for i := 0; i < 100; i++ {
+		var ga, gb, haa, hab, hbb float64
+		for j, s := range scores {
+			x := logit(s)
+			p := sigmoid(a*x + b)
+			y := 0.0
+			if labels[j] {
+				y = 1
+			}
+			ga += (p - y) * x
+			gb += p - y
+			w := p * (1 - p)
+			haa += w * x * x
+			hab += w * x
+			hbb += w
+		}
+		ga += penalty * (a - 1)
+		gb += penalty * b
+		haa += penalty
+		hbb += penalty
+
+		det := haa*hbb - hab*hab
+		if det <= 0 {
+			break
+		}
+		da := (hbb*ga - hab*gb) / det
+		db := (haa*gb - hab*ga) / det
+		a -= da
+		b -= db
+		if math.Abs(da) < 1e-9 && math.Abs(db) < 1e-9 {
+			break
+		}
+	}
+
+	if a <= 0 || math.IsNaN(a) || math.IsNaN(b) {
+		return 0, 0, fmt.Errorf("the scores are not correlated with the labels")
+	}
+	return 1 / a, b, nil
+}
+
+// LogLoss returns the mean negative log likelihood of the labels given the probabilities
+func LogLoss(probabilities []float64, labels []bool) float64 {
+	loss := 0.0
+	for i, p := range probabilities {
+		p = clamp(p)
+		if labels[i] {
+			loss -= math.Log(p)
+		} else {
+			loss -= math.Log(1 - p)
+		}
+	}
+	return loss / float64(len(probabilities))
+}
+
+// ExpectedCalibrationError returns the mean difference, weighted by the number of samples,
+// between the probabilities and the frequency of the positive labels in each of the bins
+func ExpectedCalibrationError(probabilities []float64, labels []bool, bins int) float64 {
+	count := make([]int, bins)
+	sum := make([]float64, bins)
+	positives := make([]int, bins)
+	for i, p := range probabilities {
+		bin := int(p * float64(bins))
+		if bin >= bins {
+			bin = bins - 1
+		}
+		count[bin]++
+		sum[bin] += p
+		if labels[i] {
+			positives[bin]++
+		}
+	}
+
+	ece := 0.0
+	for i := range count {
+		if count[i] == 0 {
+			continue
+		}
+		n := float64(count[i])
+		ece += n / float64(len(probabilities)) * math.Abs(sum[i]/n-float64(positives[i])/n)
+	}
+	return ece
+}
+
+// ParseScore returns the score of the output of a model, between 0 and scale,
+// as a probability between 0 and 1. The output must only contain the score.
+func ParseScore(s string, scale float64) (float64, bool) {
+	if scale <= 0 {
+		scale = 1
+	}
+	s = strings.TrimSpace(s)
+	percent := strings.HasSuffix(s, "%")
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil || math.IsNaN(v) {
+		return 0, false
+	}
+	if percent {
+		v = v / 100 * scale
+	}
+	if v < 0 || v > scale {
+		return 0, false
+	}
+	return v / scale, true
+}
+
+func logit(p float64) float64 {
+	p = clamp(p)
+	return math.Log(p / (1 - p))
+}
+
+func sigmoid(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
+
+func clamp(p float64) float64 {
+	return math.Min(math.Max(p, epsilon), 1-epsilon)
+}
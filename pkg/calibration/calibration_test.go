@@ -0,0 +1,75 @@
+package calibration_test
+
+import (
+	"math"
+	"math/rand"
+
+	. "github.com/go-skynet/LocalAI/pkg/calibration"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// overconfident generates the scores of a model whose logits are twice the calibrated ones
+func overconfident(n int) ([]float64, []bool) {
+	r := rand.New(rand.NewSource(42))
+	scores := make([]float64, n)
+	labels := make([]bool, n)
+	for i := range scores {
+		x := r.NormFloat64() * 2
+		p := 1 / (1 + math.Exp(-x))
+		labels[i] = r.Float64() < p
+		scores[i] = 1 / (1 + math.Exp(-2*x))
+	}
+	return scores, labels
+}
+
+var _ = Describe("Calibration", func() {
+	It("fits the temperature of overconfident scores", func() {
+		scores, labels := overconfident(5000)
+		temperature, bias, err := Fit(scores, labels)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(temperature).To(BeNumerically("~", 2, 0.2))
+		Expect(bias).To(BeNumerically("~", 0, 0.1))
+
+		calibrated := make([]float64, len(scores))
+		for i, s := range scores {
+			calibrated[i] = Apply(s, temperature, bias)
+		}
+		Expect(LogLoss(calibrated, labels)).To(BeNumerically("<", LogLoss(scores, labels)))
+		Expect(ExpectedCalibrationError(calibrated, labels, 10)).To(BeNumerically("<", ExpectedCalibrationError(scores, labels, 10)))
+	})
+
+	It("keeps the scores with a temperature of 1 and no bias", func() {
+		Expect(Apply(0.8, 1, 0)).To(BeNumerically("~", 0.8, 1e-9))
+		Expect(Apply(0.8, 0, 0)).To(BeNumerically("~", 0.8, 1e-9))
+		Expect(Apply(0.8, 2, 0)).To(BeNumerically("<", 0.8))
+	})
+
+	It("requires both labels", func() {
+		_, _, err := Fit([]float64{0.2, 0.8}, []bool{true, true})
+		Expect(err).To(HaveOccurred())
+		_, _, err = Fit([]float64{0.2}, []bool{true, false})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("parses the scores", func() {
+		for _, t := range []struct {
+			output   string
+			scale    float64
+			expected float64
+		}{
+			{"0.25", 1, 0.25},
+			{" 1\n", 0, 1},
+			{"7", 10, 0.7},
+			{"80%", 10, 0.8},
+		} {
+			score, ok := ParseScore(t.output, t.scale)
+			Expect(ok).To(BeTrue(), t.output)
+			Expect(score).To(BeNumerically("~", t.expected, 1e-9), t.output)
+		}
+		for _, s := range []string{"yes", "11", "-1", "0.5 because"} {
+			_, ok := ParseScore(s, 10)
+			Expect(ok).To(BeFalse(), s)
+		}
+	})
+})
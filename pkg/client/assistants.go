@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-skynet/LocalAI/api/schema"
+)
+
+// CreateAssistant creates an assistant (/v1/assistants)
+func (c *Client) CreateAssistant(ctx context.Context, req schema.AssistantRequest) (*schema.Assistant, error) {
+	resp := &schema.Assistant{}
+	return resp, c.call(ctx, http.MethodPost, "/v1/assistants", req, resp)
+}
+
+func (c *Client) ListAssistants(ctx context.Context, opts ListOptions) (*List[schema.Assistant], error) {
+	resp := &List[schema.Assistant]{}
+	return resp, c.call(ctx, http.MethodGet, "/v1/assistants"+opts.query(), nil, resp)
+}
+
+func (c *Client) GetAssistant(ctx context.Context, id string) (*schema.Assistant, error) {
+	resp := &schema.Assistant{}
+	return resp, c.call(ctx, http.MethodGet, "/v1/assistants/"+url.PathEscape(id), nil, resp)
+}
+
+// ModifyAssistant changes the fields of the assistant set in req
+func (c *Client) ModifyAssistant(ctx context.Context, id string, req schema.AssistantRequest) (*schema.Assistant, error) {
+	resp := &schema.Assistant{}
+	return resp, c.call(ctx, http.MethodPost, "/v1/assistants/"+url.PathEscape(id), req, resp)
+}
+
+func (c *Client) DeleteAssistant(ctx context.Context, id string) (*schema.DeletionStatus, error) {
+	resp := &schema.DeletionStatus{}
+	return resp, c.call(ctx, http.MethodDelete, "/v1/assistants/"+url.PathEscape(id), nil, resp)
+}
+
+// CreateThread creates a thread with the messages of req (/v1/threads)
+func (c *Client) CreateThread(ctx context.Context, req schema.ThreadRequest) (*schema.Thread, error) {
+	resp := &schema.Thread{}
+	return resp, c.call(ctx, http.MethodPost, "/v1/threads", req, resp)
+}
+
+func (c *Client) GetThread(ctx context.Context, id string) (*schema.Thread, error) {
+	resp := &schema.Thread{}
+	return resp, c.call(ctx, http.MethodGet, "/v1/threads/"+url.PathEscape(id), nil, resp)
+}
+
+// ModifyThread replaces the metadata of the thread
+func (c *Client) ModifyThread(ctx context.Context, id string, metadata map[string]string) (*schema.Thread, error) {
+	resp := &schema.Thread{}
+	return resp, c.call(ctx, http.MethodPost, "/v1/threads/"+url.PathEscape(id), schema.ThreadRequest{Metadata: metadata}, resp)
+}
+
+func (c *Client) DeleteThread(ctx context.Context, id string) (*schema.DeletionStatus, error) {
+	resp := &schema.DeletionStatus{}
+	return resp, c.call(ctx, http.MethodDelete, "/v1/threads/"+url.PathEscape(id), nil, resp)
+}
+
+// ExportThread returns the thread with its messages, to archive it or import it on another instance
+func (c *Client) ExportThread(ctx context.Context, id string) (*schema.ThreadExport, error) {
+	resp := &schema.ThreadExport{}
+	return resp, c.call(ctx, http.MethodGet, "/v1/threads/"+url.PathEscape(id)+"/export", nil, resp)
+}
+
+// ImportThread creates a thread from an export
+func (c *Client) ImportThread(ctx context.Context, export schema.ThreadExport) (*schema.Thread, error) {
+	resp := &schema.Thread{}
+	return resp, c.call(ctx, http.MethodPost, "/v1/threads/import", export, resp)
+}
+
+// CreateMessage adds a message to a thread
+func (c *Client) CreateMessage(ctx context.Context, threadID string, req schema.ThreadMessageRequest) (*schema.ThreadMessage, error) {
+	resp := &schema.ThreadMessage{}
+	return resp, c.call(ctx, http.MethodPost, "/v1/threads/"+url.PathEscape(threadID)+"/messages", req, resp)
+}
+
+func (c *Client) ListMessages(ctx context.Context, threadID string, opts ListOptions) (*List[schema.ThreadMessage], error) {
+	resp := &List[schema.ThreadMessage]{}
+	return resp, c.call(ctx, http.MethodGet, "/v1/threads/"+url.PathEscape(threadID)+"/messages"+opts.query(), nil, resp)
+}
+
+func (c *Client) GetMessage(ctx context.Context, threadID, messageID string) (*schema.ThreadMessage, error) {
+	resp := &schema.ThreadMessage{}
+	return resp, c.call(ctx, http.MethodGet, "/v1/threads/"+url.PathEscape(threadID)+"/messages/"+url.PathEscape(messageID), nil, resp)
+}
+
+// CreateRun runs an assistant on a thread, the run is followed with GetRun or WaitForRun
+func (c *Client) CreateRun(ctx context.Context, threadID string, req schema.RunRequest) (*schema.Run, error) {
+	resp := &schema.Run{}
+	return resp, c.call(ctx, http.MethodPost, "/v1/threads/"+url.PathEscape(threadID)+"/runs", req, resp)
+}
+
+// CreateThreadAndRun creates the thread of req.Thread and runs the assistant on it
+func (c *Client) CreateThreadAndRun(ctx context.Context, req schema.RunRequest) (*schema.Run, error) {
+	resp := &schema.Run{}
+	return resp, c.call(ctx, http.MethodPost, "/v1/threads/runs", req, resp)
+}
+
+func (c *Client) ListRuns(ctx context.Context, threadID string, opts ListOptions) (*List[schema.Run], error) {
+	resp := &List[schema.Run]{}
+	return resp, c.call(ctx, http.MethodGet, "/v1/threads/"+url.PathEscape(threadID)+"/runs"+opts.query(), nil, resp)
+}
+
+func (c *Client) GetRun(ctx context.Context, threadID, runID string) (*schema.Run, error) {
+	resp := &schema.Run{}
+	return resp, c.call(ctx, http.MethodGet, c.runPath(threadID, runID), nil, resp)
+}
+
+func (c *Client) CancelRun(ctx context.Context, threadID, runID string) (*schema.Run, error) {
+	resp := &schema.Run{}
+	return resp, c.call(ctx, http.MethodPost, c.runPath(threadID, runID)+"/cancel", nil, resp)
+}
+
+// WaitForRun polls the run every interval until it completes, fails, is cancelled or requires action
+func (c *Client) WaitForRun(ctx context.Context, threadID, runID string, interval time.Duration) (*schema.Run, error) {
+	for {
+		run, err := c.GetRun(ctx, threadID, runID)
+		if err != nil {
+			return nil, err
+		}
+		if run.Status != schema.RunQueued && run.Status != schema.RunInProgress {
+			return run, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return run, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// SubmitToolOutputs sends the outputs of the tool calls of a run which requires action
+func (c *Client) SubmitToolOutputs(ctx context.Context, threadID, runID string, outputs []schema.ToolOutput) (*schema.Run, error) {
+	resp := &schema.Run{}
+	return resp, c.call(ctx, http.MethodPost, c.runPath(threadID, runID)+"/submit_tool_outputs", schema.SubmitToolOutputsRequest{ToolOutputs: outputs}, resp)
+}
+
+func (c *Client) runPath(threadID, runID string) string {
+	return "/v1/threads/" + url.PathEscape(threadID) + "/runs/" + url.PathEscape(runID)
+}
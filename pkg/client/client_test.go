@@ -0,0 +1,203 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/go-skynet/LocalAI/api/schema"
+	. "github.com/go-skynet/LocalAI/pkg/client"
+	"github.com/go-skynet/LocalAI/pkg/logstream"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client", func() {
+	It("sends chat requests with the API key", func() {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/v1/chat/completions"))
+			Expect(r.Header.Get("Authorization")).To(Equal("Bearer secret"))
+			req := schema.OpenAIRequest{}
+			Expect(json.NewDecoder(r.Body).Decode(&req)).To(Succeed())
+			Expect(req.Model).To(Equal("test"))
+			content := "hello"
+			json.NewEncoder(w).Encode(schema.OpenAIResponse{
+				Model:   req.Model,
+				Choices: []schema.Choice{{Message: &schema.Message{Role: "assistant", Content: content}}},
+			})
+		}))
+		defer srv.Close()
+
+		c := New(srv.URL, WithAPIKey("secret"))
+		req := schema.OpenAIRequest{Messages: []schema.Message{{Role: "user", Content: "hi"}}}
+		req.Model = "test"
+		resp, err := c.Chat(context.Background(), req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Choices).To(HaveLen(1))
+		Expect(resp.Choices[0].Message.Content).To(Equal("hello"))
+	})
+
+	It("reads streamed chunks", func() {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, s := range []string{"hel", "lo"} {
+				s := s
+				dat, _ := json.Marshal(schema.OpenAIResponse{Choices: []schema.Choice{{Delta: &schema.Message{Content: &s}}}})
+				fmt.Fprintf(w, "data: %s\n\n", dat)
+			}
+			fmt.Fprint(w, "data: [DONE]\n\n")
+		}))
+		defer srv.Close()
+
+		result := ""
+		err := New(srv.URL).ChatStream(context.Background(), schema.OpenAIRequest{}, func(r schema.OpenAIResponse) error {
+			result += r.Choices[0].Delta.Content.(string)
+			return nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal("hello"))
+	})
+
+	It("retries temporary failures", func() {
+		calls := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprint(w, `{"version": "v1.0.0"}`)
+		}))
+		defer srv.Close()
+
+		v, err := New(srv.URL, WithRetries(3, time.Millisecond)).Version(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(v).To(Equal("v1.0.0"))
+		Expect(calls).To(Equal(3))
+	})
+
+	It("does not retry client errors", func() {
+		calls := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer srv.Close()
+
+		_, err := New(srv.URL, WithRetries(3, time.Millisecond)).ListModels(context.Background())
+		Expect(err).To(HaveOccurred())
+		apiErr, ok := err.(*Error)
+		Expect(ok).To(BeTrue())
+		Expect(apiErr.StatusCode).To(Equal(http.StatusUnauthorized))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("returns the smoke tests of the refused rollouts", func() {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/models/rollout"))
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `{"alias": "chat", "model": "green", "previous": "blue", "switched": false, "smoke_test": {"model": "green", "passed": false}}`)
+		}))
+		defer srv.Close()
+
+		resp, err := New(srv.URL).Rollout(context.Background(), "chat", "green")
+		Expect(err).To(HaveOccurred())
+		Expect(resp.Switched).To(BeFalse())
+		Expect(resp.Previous).To(Equal("blue"))
+		Expect(resp.SmokeTest.Model).To(Equal("green"))
+	})
+
+	It("uploads files as multipart forms", func() {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/v1/files"))
+			Expect(r.FormValue("purpose")).To(Equal("batch"))
+			f, h, err := r.FormFile("file")
+			Expect(err).ToNot(HaveOccurred())
+			content, _ := io.ReadAll(f)
+			json.NewEncoder(w).Encode(schema.File{ID: "file-1", Filename: h.Filename, Bytes: int64(len(content)), Purpose: "batch"})
+		}))
+		defer srv.Close()
+
+		f, err := New(srv.URL).UploadFile(context.Background(), "requests.jsonl", "batch", []byte("{}\n"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(f.ID).To(Equal("file-1"))
+		Expect(f.Filename).To(Equal("requests.jsonl"))
+		Expect(f.Bytes).To(Equal(int64(3)))
+	})
+
+	It("paginates the lists", func() {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/v1/threads/thread_1/runs"))
+			Expect(r.URL.Query().Get("limit")).To(Equal("1"))
+			Expect(r.URL.Query().Get("after")).To(Equal("run_1"))
+			fmt.Fprint(w, `{"object": "list", "data": [{"id": "run_2", "status": "completed"}], "first_id": "run_2", "last_id": "run_2", "has_more": true}`)
+		}))
+		defer srv.Close()
+
+		runs, err := New(srv.URL).ListRuns(context.Background(), "thread_1", ListOptions{Limit: 1, After: "run_1"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(runs.Data).To(HaveLen(1))
+		Expect(runs.Data[0].Status).To(Equal(schema.RunCompleted))
+		Expect(runs.HasMore).To(BeTrue())
+	})
+
+	It("queries the vector stores", func() {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/stores/docs/query"))
+			q := StoreQuery{}
+			Expect(json.NewDecoder(r.Body).Decode(&q)).To(Succeed())
+			Expect(q.Input).To(Equal("hello"))
+			Expect(q.TopK).To(Equal(2))
+			fmt.Fprint(w, `{"matches": [{"id": "a", "metadata": {"text": "hello world"}, "score": 0.9}]}`)
+		}))
+		defer srv.Close()
+
+		matches, err := New(srv.URL).QueryStore(context.Background(), "docs", StoreQuery{Input: "hello", TopK: 2})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(matches).To(HaveLen(1))
+		Expect(matches[0].ID).To(Equal("a"))
+		Expect(matches[0].Score).To(Equal(0.9))
+	})
+
+	It("returns the diff of the refused configurations", func() {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/config/apply"))
+			body, _ := io.ReadAll(r.Body)
+			Expect(string(body)).To(Equal("models: []\n"))
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error": "invalid configuration", "diff": {"changes": [], "errors": ["model foo: no backend"], "applied": false}}`)
+		}))
+		defer srv.Close()
+
+		d, err := New(srv.URL).ConfigApply(context.Background(), []byte("models: []\n"))
+		Expect(err).To(HaveOccurred())
+		Expect(d.Errors).To(ConsistOf("model foo: no backend"))
+		Expect(d.Applied).To(BeFalse())
+	})
+
+	It("streams the logs until the context is canceled", func() {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/debug/logs"))
+			Expect(r.URL.Query().Get("component")).To(Equal("backend,api"))
+			fmt.Fprint(w, ": keepalive\n\n")
+			fmt.Fprint(w, `data: {"level": "info", "component": "backend", "message": "loaded"}`+"\n\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}))
+		defer srv.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		entries := []logstream.Entry{}
+		err := New(srv.URL).Logs(ctx, LogsQuery{Components: []string{"backend", "api"}}, func(e logstream.Entry) error {
+			entries = append(entries, e)
+			cancel()
+			return nil
+		})
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Message).To(Equal("loaded"))
+	})
+})
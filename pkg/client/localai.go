@@ -0,0 +1,365 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-skynet/LocalAI/api/schema"
+	"github.com/go-skynet/LocalAI/pkg/apikeys"
+	"github.com/go-skynet/LocalAI/pkg/gallery"
+	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
+	"github.com/go-skynet/LocalAI/pkg/logstream"
+	"github.com/go-skynet/LocalAI/pkg/usage"
+)
+
+// ApplyModelRequest installs a model from a gallery: either ID (gallery@name) or
+// the URL of a model config has to be set.
+type ApplyModelRequest struct {
+	ID string `json:"id,omitempty"`
+	gallery.GalleryModel
+}
+
+type ApplyModelResponse struct {
+	ID        string `json:"uuid"`
+	StatusURL string `json:"status"`
+}
+
+type JobStatus struct {
+	FileName           string      `json:"file_name"`
+	Error              interface{} `json:"error"`
+	Processed          bool        `json:"processed"`
+	Message            string      `json:"message"`
+	Progress           float64     `json:"progress"`
+	TotalFileSize      string      `json:"file_size"`
+	DownloadedFileSize string      `json:"downloaded_size"`
+}
+
+type SmokeTestResult struct {
+	Name     string `json:"name"`
+	Passed   bool   `json:"passed"`
+	Response string `json:"response,omitempty"`
+	Latency  int64  `json:"latency_ms"`
+	Error    string `json:"error,omitempty"`
+}
+
+type SmokeTestResponse struct {
+	Model   string            `json:"model"`
+	Passed  bool              `json:"passed"`
+	Time    time.Time         `json:"time"`
+	Results []SmokeTestResult `json:"results"`
+}
+
+type RolloutResponse struct {
+	Alias     string            `json:"alias"`
+	Model     string            `json:"model"`
+	Previous  string            `json:"previous,omitempty"`
+	Switched  bool              `json:"switched"`
+	SmokeTest SmokeTestResponse `json:"smoke_test"`
+}
+
+// APIKey is a key created with CreateAPIKey, along with its secret which is only returned then
+type APIKey struct {
+	apikeys.Key
+	Secret string `json:"key"`
+}
+
+// UsageQuery selects the usage of the period from From to To, of a key, tenant and model if set
+type UsageQuery struct {
+	From   time.Time
+	To     time.Time
+	Key    string
+	Tenant string
+	Model  string
+}
+
+type ConfigFieldChange struct {
+	Path string      `json:"path"`
+	Old  interface{} `json:"old"`
+	New  interface{} `json:"new"`
+}
+
+type ConfigChange struct {
+	// model, api_key, gallery or external_backend
+	Kind   string              `json:"kind"`
+	Name   string              `json:"name"`
+	Action string              `json:"action"`
+	Fields []ConfigFieldChange `json:"fields,omitempty"`
+}
+
+type ConfigDiff struct {
+	Changes  []ConfigChange `json:"changes"`
+	Errors   []string       `json:"errors,omitempty"`
+	Warnings []string       `json:"warnings,omitempty"`
+	Applied  bool           `json:"applied"`
+}
+
+// LogsQuery filters the logs: the entries of the minimum Level (debug by default), of the Components and of the Model
+// if set, starting with the Tail last ones (100 by default)
+type LogsQuery struct {
+	Level      string
+	Components []string
+	Model      string
+	Tail       *int
+}
+
+type modelRequest struct {
+	Model string `json:"model"`
+}
+
+// Version returns the version of the LocalAI instance
+func (c *Client) Version(ctx context.Context) (string, error) {
+	resp := struct {
+		Version string `json:"version"`
+	}{}
+	if err := c.call(ctx, http.MethodGet, "/version", nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.Version, nil
+}
+
+// TTS generates audio from input, returning the audio file content
+func (c *Client) TTS(ctx context.Context, model, backend, input string) ([]byte, error) {
+	resp, err := c.send(ctx, http.MethodPost, "/tts", struct {
+		Model   string `json:"model"`
+		Input   string `json:"input"`
+		Backend string `json:"backend,omitempty"`
+	}{Model: model, Input: input, Backend: backend})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// Tokenize returns the tokens of content for the given model
+func (c *Client) Tokenize(ctx context.Context, model, content string) (*schema.TokenizeResponse, error) {
+	resp := &schema.TokenizeResponse{}
+	return resp, c.call(ctx, http.MethodPost, "/v1/tokenize", schema.TokenizeRequest{Model: model, Content: content}, resp)
+}
+
+// ApplyModel starts the installation of a model, returning the job to follow its progress
+func (c *Client) ApplyModel(ctx context.Context, req ApplyModelRequest) (*ApplyModelResponse, error) {
+	resp := &ApplyModelResponse{}
+	return resp, c.call(ctx, http.MethodPost, "/models/apply", req, resp)
+}
+
+// JobStatus returns the status of a model installation job
+func (c *Client) JobStatus(ctx context.Context, uuid string) (*JobStatus, error) {
+	resp := &JobStatus{}
+	return resp, c.call(ctx, http.MethodGet, "/models/jobs/"+uuid, nil, resp)
+}
+
+// Jobs returns the status of all the model installation jobs
+func (c *Client) Jobs(ctx context.Context) (map[string]*JobStatus, error) {
+	resp := map[string]*JobStatus{}
+	if err := c.call(ctx, http.MethodGet, "/models/jobs", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// WaitForJob polls the status of a job every interval until it is processed
+func (c *Client) WaitForJob(ctx context.Context, uuid string, interval time.Duration) (*JobStatus, error) {
+	for {
+		status, err := c.JobStatus(ctx, uuid)
+		if err != nil {
+			return nil, err
+		}
+		if status.Processed {
+			if status.Error != nil {
+				return status, fmt.Errorf("job %s failed: %v", uuid, status.Error)
+			}
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// AvailableModels lists the models available in the configured galleries
+func (c *Client) AvailableModels(ctx context.Context) ([]gallery.GalleryModel, error) {
+	resp := []gallery.GalleryModel{}
+	if err := c.call(ctx, http.MethodGet, "/models/available", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Galleries lists the configured galleries
+func (c *Client) Galleries(ctx context.Context) ([]gallery.Gallery, error) {
+	resp := []gallery.Gallery{}
+	if err := c.call(ctx, http.MethodGet, "/models/galleries", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) AddGallery(ctx context.Context, g gallery.Gallery) error {
+	return c.call(ctx, http.MethodPost, "/models/galleries", g, nil)
+}
+
+func (c *Client) RemoveGallery(ctx context.Context, name string) error {
+	return c.call(ctx, http.MethodDelete, "/models/galleries", gallery.Gallery{Name: name}, nil)
+}
+
+// SmokeTest runs the smoke tests defined in the model config
+func (c *Client) SmokeTest(ctx context.Context, model string) (*SmokeTestResponse, error) {
+	resp := &SmokeTestResponse{}
+	return resp, c.call(ctx, http.MethodPost, "/models/smoke-test", modelRequest{Model: model}, resp)
+}
+
+// SmokeTestResults returns the last results of the smoke tests of each model
+func (c *Client) SmokeTestResults(ctx context.Context) ([]SmokeTestResponse, error) {
+	resp := []SmokeTestResponse{}
+	return resp, c.call(ctx, http.MethodGet, "/models/smoke-test", nil, &resp)
+}
+
+// Rollout routes the alias to the model if its smoke tests pass. When they fail, the alias keeps its model and the
+// response is returned with Switched false, along with an *Error with the 409 status code.
+func (c *Client) Rollout(ctx context.Context, alias, model string) (*RolloutResponse, error) {
+	resp := &RolloutResponse{}
+	err := c.call(ctx, http.MethodPost, "/models/rollout", struct {
+		Alias string `json:"alias"`
+		Model string `json:"model"`
+	}{alias, model}, resp)
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict {
+		json.Unmarshal([]byte(apiErr.Body), resp)
+	}
+	return resp, err
+}
+
+// BackendMonitor returns the status of the backend serving model
+func (c *Client) BackendMonitor(ctx context.Context, model string) (*pb.StatusResponse, error) {
+	resp := &pb.StatusResponse{}
+	return resp, c.call(ctx, http.MethodGet, "/backend/monitor", modelRequest{Model: model}, resp)
+}
+
+// BackendShutdown stops the backend serving model
+func (c *Client) BackendShutdown(ctx context.Context, model string) error {
+	return c.call(ctx, http.MethodPost, "/backend/shutdown", modelRequest{Model: model}, nil)
+}
+
+// ListAPIKeys lists the API keys, without their secrets
+func (c *Client) ListAPIKeys(ctx context.Context) ([]apikeys.Key, error) {
+	resp := []apikeys.Key{}
+	return resp, c.call(ctx, http.MethodGet, "/keys", nil, &resp)
+}
+
+// CreateAPIKey creates an API key allowed the scopes, the secret of the key is only returned here
+func (c *Client) CreateAPIKey(ctx context.Context, name, tenant string, scopes []string) (*APIKey, error) {
+	resp := &APIKey{}
+	return resp, c.call(ctx, http.MethodPost, "/keys", struct {
+		Name   string   `json:"name"`
+		Tenant string   `json:"tenant"`
+		Scopes []string `json:"scopes"`
+	}{name, tenant, scopes}, resp)
+}
+
+func (c *Client) RevokeAPIKey(ctx context.Context, id string) error {
+	return c.call(ctx, http.MethodDelete, "/keys/"+url.PathEscape(id), nil, nil)
+}
+
+// Usage returns the usage of each API key and model over the period queried. The callers which are not admins only
+// get the usage of their own key.
+func (c *Client) Usage(ctx context.Context, q UsageQuery) ([]usage.Entry, error) {
+	v := url.Values{}
+	if !q.From.IsZero() {
+		v.Set("from", q.From.Format(time.RFC3339))
+	}
+	if !q.To.IsZero() {
+		v.Set("to", q.To.Format(time.RFC3339))
+	}
+	for k, s := range map[string]string{"key": q.Key, "tenant": q.Tenant, "model": q.Model} {
+		if s != "" {
+			v.Set(k, s)
+		}
+	}
+	path := "/usage"
+	if len(v) > 0 {
+		path += "?" + v.Encode()
+	}
+
+	resp := struct {
+		Usage []usage.Entry `json:"usage"`
+	}{}
+	if err := c.call(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Usage, nil
+}
+
+// ConfigDiff returns the differences between the configuration state, in YAML or JSON, and the running configuration
+func (c *Client) ConfigDiff(ctx context.Context, state []byte) (*ConfigDiff, error) {
+	return c.config(ctx, "/config/diff", state)
+}
+
+// ConfigApply validates and applies the configuration state, in YAML or JSON. When it is refused, the diff with the
+// errors is returned along with an *Error.
+func (c *Client) ConfigApply(ctx context.Context, state []byte) (*ConfigDiff, error) {
+	return c.config(ctx, "/config/apply", state)
+}
+
+func (c *Client) config(ctx context.Context, path string, state []byte) (*ConfigDiff, error) {
+	resp, err := c.sendBody(ctx, http.MethodPost, path, "application/yaml", state)
+	d := &ConfigDiff{}
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		refused := struct {
+			Diff *ConfigDiff `json:"diff"`
+		}{Diff: d}
+		json.Unmarshal([]byte(apiErr.Body), &refused)
+		return d, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return d, json.NewDecoder(resp.Body).Decode(d)
+}
+
+// Logs streams the logs of the instance and of its backends, calling fn with every entry until the context is
+// canceled or fn returns an error. It requires the admin key.
+func (c *Client) Logs(ctx context.Context, q LogsQuery, fn func(logstream.Entry) error) error {
+	v := url.Values{}
+	if q.Level != "" {
+		v.Set("level", q.Level)
+	}
+	if len(q.Components) > 0 {
+		v.Set("component", strings.Join(q.Components, ","))
+	}
+	if q.Model != "" {
+		v.Set("model", q.Model)
+	}
+	if q.Tail != nil {
+		v.Set("tail", strconv.Itoa(*q.Tail))
+	}
+	path := "/debug/logs"
+	if len(v) > 0 {
+		path += "?" + v.Encode()
+	}
+
+	err := c.stream(ctx, http.MethodGet, path, nil, func(data []byte) error {
+		e := logstream.Entry{}
+		if err := json.Unmarshal(data, &e); err != nil {
+			return err
+		}
+		return fn(e)
+	})
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
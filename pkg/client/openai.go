@@ -0,0 +1,183 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/go-skynet/LocalAI/api/schema"
+)
+
+// Chat sends a chat completion request (/v1/chat/completions)
+func (c *Client) Chat(ctx context.Context, req schema.OpenAIRequest) (*schema.OpenAIResponse, error) {
+	req.Stream = false
+	resp := &schema.OpenAIResponse{}
+	return resp, c.call(ctx, http.MethodPost, "/v1/chat/completions", req, resp)
+}
+
+// ChatStream sends a streaming chat completion request, calling fn for every chunk received
+func (c *Client) ChatStream(ctx context.Context, req schema.OpenAIRequest, fn func(schema.OpenAIResponse) error) error {
+	req.Stream = true
+	return c.stream(ctx, http.MethodPost, "/v1/chat/completions", req, decodeChunk(fn))
+}
+
+// Completion sends a completion request (/v1/completions)
+func (c *Client) Completion(ctx context.Context, req schema.OpenAIRequest) (*schema.OpenAIResponse, error) {
+	req.Stream = false
+	resp := &schema.OpenAIResponse{}
+	return resp, c.call(ctx, http.MethodPost, "/v1/completions", req, resp)
+}
+
+// CompletionStream sends a streaming completion request, calling fn for every chunk received
+func (c *Client) CompletionStream(ctx context.Context, req schema.OpenAIRequest, fn func(schema.OpenAIResponse) error) error {
+	req.Stream = true
+	return c.stream(ctx, http.MethodPost, "/v1/completions", req, decodeChunk(fn))
+}
+
+// Edit sends an edit request (/v1/edits)
+func (c *Client) Edit(ctx context.Context, req schema.OpenAIRequest) (*schema.OpenAIResponse, error) {
+	resp := &schema.OpenAIResponse{}
+	return resp, c.call(ctx, http.MethodPost, "/v1/edits", req, resp)
+}
+
+// Embeddings computes the embeddings of req.Input (/v1/embeddings)
+func (c *Client) Embeddings(ctx context.Context, req schema.OpenAIRequest) (*schema.OpenAIResponse, error) {
+	resp := &schema.OpenAIResponse{}
+	return resp, c.call(ctx, http.MethodPost, "/v1/embeddings", req, resp)
+}
+
+// ImageGeneration generates images from req.Prompt (/v1/images/generations)
+func (c *Client) ImageGeneration(ctx context.Context, req schema.OpenAIRequest) (*schema.OpenAIResponse, error) {
+	resp := &schema.OpenAIResponse{}
+	return resp, c.call(ctx, http.MethodPost, "/v1/images/generations", req, resp)
+}
+
+// Transcription transcribes the audio file at path with the given model (/v1/audio/transcriptions)
+func (c *Client) Transcription(ctx context.Context, model, path string) (*schema.Result, error) {
+	audio, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	result := &schema.Result{}
+	return result, c.postFile(ctx, "/v1/audio/transcriptions", map[string]string{"model": model}, filepath.Base(path), audio, result)
+}
+
+// ListModels returns the models available in the instance (/v1/models)
+func (c *Client) ListModels(ctx context.Context) ([]schema.OpenAIModel, error) {
+	resp := struct {
+		Data []schema.OpenAIModel `json:"data"`
+	}{}
+	if err := c.call(ctx, http.MethodGet, "/v1/models", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// List is a page of the objects listed by the OpenAI API
+type List[T any] struct {
+	Object  string `json:"object"`
+	Data    []T    `json:"data"`
+	FirstID string `json:"first_id,omitempty"`
+	LastID  string `json:"last_id,omitempty"`
+	HasMore bool   `json:"has_more"`
+}
+
+// ListOptions paginates the lists: Limit objects (20 by default) after the object with the ID After, ordered by
+// creation time in Order (asc or desc, the default)
+type ListOptions struct {
+	Limit int
+	Order string
+	After string
+}
+
+func (o ListOptions) query() string {
+	q := url.Values{}
+	if o.Limit > 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Order != "" {
+		q.Set("order", o.Order)
+	}
+	if o.After != "" {
+		q.Set("after", o.After)
+	}
+	if len(q) == 0 {
+		return ""
+	}
+	return "?" + q.Encode()
+}
+
+// UploadFile uploads the content as a file with the given purpose, e.g. batch or assistants (/v1/files)
+func (c *Client) UploadFile(ctx context.Context, filename, purpose string, content []byte) (*schema.File, error) {
+	resp := &schema.File{}
+	return resp, c.postFile(ctx, "/v1/files", map[string]string{"purpose": purpose}, filename, content, resp)
+}
+
+// ListFiles lists the files with the given purpose, all of them if empty
+func (c *Client) ListFiles(ctx context.Context, purpose string) ([]schema.File, error) {
+	path := "/v1/files"
+	if purpose != "" {
+		path += "?purpose=" + url.QueryEscape(purpose)
+	}
+	resp := &List[schema.File]{}
+	if err := c.call(ctx, http.MethodGet, path, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+func (c *Client) GetFile(ctx context.Context, id string) (*schema.File, error) {
+	resp := &schema.File{}
+	return resp, c.call(ctx, http.MethodGet, "/v1/files/"+url.PathEscape(id), nil, resp)
+}
+
+// FileContent returns the content of a file, e.g. the output of a batch
+func (c *Client) FileContent(ctx context.Context, id string) ([]byte, error) {
+	resp, err := c.send(ctx, http.MethodGet, "/v1/files/"+url.PathEscape(id)+"/content", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+func (c *Client) DeleteFile(ctx context.Context, id string) (*schema.DeletionStatus, error) {
+	resp := &schema.DeletionStatus{}
+	return resp, c.call(ctx, http.MethodDelete, "/v1/files/"+url.PathEscape(id), nil, resp)
+}
+
+// CreateBatch runs the requests of an uploaded file in the background (/v1/batches)
+func (c *Client) CreateBatch(ctx context.Context, req schema.BatchRequest) (*schema.Batch, error) {
+	resp := &schema.Batch{}
+	return resp, c.call(ctx, http.MethodPost, "/v1/batches", req, resp)
+}
+
+func (c *Client) ListBatches(ctx context.Context, opts ListOptions) (*List[schema.Batch], error) {
+	resp := &List[schema.Batch]{}
+	return resp, c.call(ctx, http.MethodGet, "/v1/batches"+opts.query(), nil, resp)
+}
+
+func (c *Client) GetBatch(ctx context.Context, id string) (*schema.Batch, error) {
+	resp := &schema.Batch{}
+	return resp, c.call(ctx, http.MethodGet, "/v1/batches/"+url.PathEscape(id), nil, resp)
+}
+
+func (c *Client) CancelBatch(ctx context.Context, id string) (*schema.Batch, error) {
+	resp := &schema.Batch{}
+	return resp, c.call(ctx, http.MethodPost, "/v1/batches/"+url.PathEscape(id)+"/cancel", nil, resp)
+}
+
+func decodeChunk(fn func(schema.OpenAIResponse) error) func([]byte) error {
+	return func(data []byte) error {
+		chunk := schema.OpenAIResponse{}
+		if err := json.Unmarshal(data, &chunk); err != nil && err != io.EOF {
+			return err
+		}
+		return fn(chunk)
+	}
+}
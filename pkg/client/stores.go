@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/go-skynet/LocalAI/pkg/vectorstore"
+)
+
+type CreateStoreRequest struct {
+	Name       string `json:"name"`
+	Dimensions int    `json:"dimensions"`
+	// Metric is cosine, dot or euclidean, cosine by default
+	Metric string `json:"metric,omitempty"`
+	// Model embeds the texts upserted and queried without vectors
+	Model string `json:"model,omitempty"`
+}
+
+// StoreVector is a vector to upsert: the Text is embedded with the model of the collection when there are no Values
+type StoreVector struct {
+	ID       string                 `json:"id"`
+	Values   []float32              `json:"values,omitempty"`
+	Text     string                 `json:"text,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// StoreQuery selects the TopK vectors (10 by default) nearest to the Vector, or to the embedding of the Input, among
+// the ones whose metadata match the Filter
+type StoreQuery struct {
+	Vector        []float32              `json:"vector,omitempty"`
+	Input         string                 `json:"input,omitempty"`
+	TopK          int                    `json:"top_k,omitempty"`
+	Filter        map[string]interface{} `json:"filter,omitempty"`
+	IncludeValues bool                   `json:"include_values,omitempty"`
+}
+
+func storePath(name string) string {
+	return "/stores/" + url.PathEscape(name)
+}
+
+// ListStores lists the collections of the vector store (/stores)
+func (c *Client) ListStores(ctx context.Context) ([]vectorstore.Collection, error) {
+	resp := []vectorstore.Collection{}
+	return resp, c.call(ctx, http.MethodGet, "/stores", nil, &resp)
+}
+
+func (c *Client) CreateStore(ctx context.Context, req CreateStoreRequest) (*vectorstore.Collection, error) {
+	resp := &vectorstore.Collection{}
+	return resp, c.call(ctx, http.MethodPost, "/stores", req, resp)
+}
+
+func (c *Client) GetStore(ctx context.Context, name string) (*vectorstore.Collection, error) {
+	resp := &vectorstore.Collection{}
+	return resp, c.call(ctx, http.MethodGet, storePath(name), nil, resp)
+}
+
+// DeleteStore deletes a collection and its vectors
+func (c *Client) DeleteStore(ctx context.Context, name string) error {
+	return c.call(ctx, http.MethodDelete, storePath(name), nil, nil)
+}
+
+// UpsertStore adds the vectors to a collection, replacing the ones with the same IDs
+func (c *Client) UpsertStore(ctx context.Context, name string, vectors []StoreVector) (*vectorstore.Collection, error) {
+	resp := &vectorstore.Collection{}
+	return resp, c.call(ctx, http.MethodPost, storePath(name)+"/upsert", struct {
+		Vectors []StoreVector `json:"vectors"`
+	}{vectors}, resp)
+}
+
+// QueryStore returns the vectors of a collection nearest to the query, by decreasing score
+func (c *Client) QueryStore(ctx context.Context, name string, q StoreQuery) ([]vectorstore.Match, error) {
+	resp := struct {
+		Matches []vectorstore.Match `json:"matches"`
+	}{}
+	if err := c.call(ctx, http.MethodPost, storePath(name)+"/query", q, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Matches, nil
+}
+
+// DeleteVectors deletes the vectors of a collection with the IDs, and the ones whose metadata match the filter,
+// returning the number of vectors deleted
+func (c *Client) DeleteVectors(ctx context.Context, name string, ids []string, filter map[string]interface{}) (int, error) {
+	resp := struct {
+		Deleted int `json:"deleted"`
+	}{}
+	err := c.call(ctx, http.MethodPost, storePath(name)+"/delete", struct {
+		IDs    []string               `json:"ids,omitempty"`
+		Filter map[string]interface{} `json:"filter,omitempty"`
+	}{ids, filter}, &resp)
+	return resp.Deleted, err
+}
@@ -0,0 +1,160 @@
+// Package compression decodes compressed request bodies and compresses the responses
+// for the clients which accept it, with gzip or zstd.
+package compression
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/klauspost/compress/flate"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	Gzip    = "gzip"
+	Deflate = "deflate"
+	Zstd    = "zstd"
+)
+
+// minSize is the size under which responses are not worth compressing
+const minSize = 1024
+
+// encoder is safe for concurrent use with EncodeAll
+var encoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+
+// RequestMiddleware decodes the request bodies compressed with gzip, deflate or zstd (as per the Content-Encoding header),
+// so the handlers read them as usual. Bodies larger than limit bytes once decompressed are rejected.
+func RequestMiddleware(limit int) fiber.Handler {
+	// the decoder is safe for concurrent use with DecodeAll
+	decoder, _ := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(uint64(limit)), zstd.WithDecoderConcurrency(0))
+
+	return func(c *fiber.Ctx) error {
+		encoding := strings.ToLower(strings.TrimSpace(c.Get(fiber.HeaderContentEncoding)))
+		if encoding == "" || encoding == "identity" {
+			return c.Next()
+		}
+
+		body := c.Request().Body()
+		var decoded []byte
+		var err error
+		switch encoding {
+		case Gzip, "x-gzip":
+			var r *gzip.Reader
+			if r, err = gzip.NewReader(bytes.NewReader(body)); err == nil {
+				decoded, err = readLimited(r, limit)
+			}
+		case Deflate:
+			decoded, err = readLimited(flate.NewReader(bytes.NewReader(body)), limit)
+		case Zstd:
+			decoded, err = decoder.DecodeAll(body, nil)
+		default:
+			return fiber.NewError(fiber.StatusUnsupportedMediaType, fmt.Sprintf("unsupported content encoding %q, supported encodings are %s, %s and %s", encoding, Gzip, Deflate, Zstd))
+		}
+		if err == errTooLarge || err == zstd.ErrDecoderSizeExceeded || err == zstd.ErrWindowSizeExceeded {
+			return fiber.ErrRequestEntityTooLarge
+		}
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("could not decode the %s request body: %s", encoding, err.Error()))
+		}
+
+		c.Request().SetBodyRaw(decoded)
+		c.Request().Header.Del(fiber.HeaderContentEncoding)
+		c.Request().Header.SetContentLength(len(decoded))
+		return c.Next()
+	}
+}
+
+var errTooLarge = fmt.Errorf("request body too large")
+
+func readLimited(r io.Reader, limit int) ([]byte, error) {
+	dat, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(dat) > limit {
+		return nil, errTooLarge
+	}
+	return dat, nil
+}
+
+// ResponseMiddleware compresses the responses with gzip or zstd, if the client accepts them (as per the Accept-Encoding header).
+// It buffers the whole response, so it must not be used on the endpoints streaming their responses.
+func ResponseMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		encoding := Negotiate(c.Get(fiber.HeaderAcceptEncoding))
+		if err := c.Next(); err != nil {
+			return err
+		}
+		c.Vary(fiber.HeaderAcceptEncoding)
+
+		resp := c.Response()
+		if encoding == "" || c.Method() == fiber.MethodHead || resp.StatusCode() == fiber.StatusPartialContent ||
+			len(resp.Header.Peek(fiber.HeaderContentEncoding)) > 0 {
+			return nil
+		}
+
+		// this reads the body streams too (e.g. the files sent with SendFile)
+		body := resp.Body()
+		if len(body) < minSize {
+			return nil
+		}
+
+		var compressed []byte
+		switch encoding {
+		case Zstd:
+			compressed = encoder.EncodeAll(body, make([]byte, 0, len(body)/2))
+		case Gzip:
+			var buf bytes.Buffer
+			w := gzip.NewWriter(&buf)
+			if _, err := w.Write(body); err != nil {
+				return err
+			}
+			if err := w.Close(); err != nil {
+				return err
+			}
+			compressed = buf.Bytes()
+		}
+
+		resp.SetBodyRaw(compressed)
+		resp.Header.Set(fiber.HeaderContentEncoding, encoding)
+		resp.Header.SetContentLength(len(compressed))
+		return nil
+	}
+}
+
+// Negotiate returns the encoding to compress a response with given the Accept-Encoding header of the request,
+// or an empty string if the response should not be compressed. zstd is preferred when both are accepted.
+func Negotiate(acceptEncoding string) string {
+	accepted := map[string]float64{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		q := 1.0
+		for _, param := range fields[1:] {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		accepted[name] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, encoding := range []string{Zstd, Gzip} {
+		q, ok := accepted[encoding]
+		if !ok {
+			// the encodings which are not listed are accepted as the wildcard
+			q = accepted["*"]
+		}
+		if q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
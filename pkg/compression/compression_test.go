@@ -0,0 +1,98 @@
+package compression_test
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/go-skynet/LocalAI/pkg/compression"
+	"github.com/gofiber/fiber/v2"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Compression", func() {
+	var app *fiber.App
+	text := strings.Repeat("the quick brown fox jumps over the lazy dog ", 100)
+
+	BeforeEach(func() {
+		app = fiber.New()
+		app.Use(RequestMiddleware(1024 * 1024))
+		app.Post("/echo", ResponseMiddleware(), func(c *fiber.Ctx) error {
+			return c.Send(c.Body())
+		})
+	})
+
+	gzipped := func(dat []byte) []byte {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		w.Write(dat)
+		w.Close()
+		return buf.Bytes()
+	}
+
+	It("negotiates the encoding of the responses", func() {
+		Expect(Negotiate("")).To(Equal(""))
+		Expect(Negotiate("gzip, deflate, br")).To(Equal(Gzip))
+		Expect(Negotiate("gzip, zstd")).To(Equal(Zstd))
+		Expect(Negotiate("gzip;q=1.0, zstd;q=0.5")).To(Equal(Gzip))
+		Expect(Negotiate("*")).To(Equal(Zstd))
+		Expect(Negotiate("*, zstd;q=0")).To(Equal(Gzip))
+		Expect(Negotiate("identity")).To(Equal(""))
+	})
+
+	It("decodes gzip request bodies and compresses the responses with zstd", func() {
+		req := httptest.NewRequest("POST", "/echo", bytes.NewReader(gzipped([]byte(text))))
+		req.Header.Set("Content-Encoding", "gzip")
+		req.Header.Set("Accept-Encoding", "zstd")
+		resp, err := app.Test(req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(200))
+		Expect(resp.Header.Get("Content-Encoding")).To(Equal(Zstd))
+
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(body)).To(BeNumerically("<", len(text)))
+		decoder, err := zstd.NewReader(nil)
+		Expect(err).ToNot(HaveOccurred())
+		decoded, err := decoder.DecodeAll(body, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(decoded)).To(Equal(text))
+	})
+
+	It("decodes zstd request bodies and leaves the responses uncompressed when not accepted", func() {
+		encoder, err := zstd.NewWriter(nil)
+		Expect(err).ToNot(HaveOccurred())
+		req := httptest.NewRequest("POST", "/echo", bytes.NewReader(encoder.EncodeAll([]byte(text), nil)))
+		req.Header.Set("Content-Encoding", "zstd")
+		resp, err := app.Test(req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Header.Get("Content-Encoding")).To(BeEmpty())
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(body)).To(Equal(text))
+	})
+
+	It("rejects invalid, unsupported and too large bodies", func() {
+		req := httptest.NewRequest("POST", "/echo", strings.NewReader("not gzip"))
+		req.Header.Set("Content-Encoding", "gzip")
+		resp, err := app.Test(req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(fiber.StatusBadRequest))
+
+		req = httptest.NewRequest("POST", "/echo", strings.NewReader(text))
+		req.Header.Set("Content-Encoding", "compress")
+		resp, err = app.Test(req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(fiber.StatusUnsupportedMediaType))
+
+		req = httptest.NewRequest("POST", "/echo", bytes.NewReader(gzipped(make([]byte, 2*1024*1024))))
+		req.Header.Set("Content-Encoding", "gzip")
+		resp, err = app.Test(req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(fiber.StatusRequestEntityTooLarge))
+	})
+})
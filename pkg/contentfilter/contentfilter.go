@@ -0,0 +1,165 @@
+// Package contentfilter runs the prompts sent to the models, and the responses they generate, through filters which
+// can rewrite them (e.g. to redact the personal data) or reject them (e.g. to enforce a policy). The filters are Go
+// functions registered by the programs embedding LocalAI, or external HTTP services called for each content.
+package contentfilter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stage is when the content is filtered
+type Stage string
+
+const (
+	// Prompt is the prompt before the inference
+	Prompt Stage = "prompt"
+	// Response is the response of the model before it is returned
+	Response Stage = "response"
+)
+
+// Content is a prompt or a response of a model
+type Content struct {
+	Model string `json:"model"`
+	Stage Stage  `json:"stage"`
+	Text  string `json:"text"`
+}
+
+// Filter returns the content, rewritten or not, or a RejectedError to refuse it
+type Filter interface {
+	Filter(ctx context.Context, c Content) (string, error)
+}
+
+// FilterFunc is a function used as a filter
+type FilterFunc func(ctx context.Context, c Content) (string, error)
+
+func (f FilterFunc) Filter(ctx context.Context, c Content) (string, error) {
+	return f(ctx, c)
+}
+
+// RejectedError is returned for the contents a filter refused
+type RejectedError struct {
+	Filter string
+	Stage  Stage
+	Reason string
+}
+
+func (e *RejectedError) Error() string {
+	msg := fmt.Sprintf("the %s was rejected by the content filter %s", e.Stage, e.Filter)
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg
+}
+
+// Registry holds the filters by name, the models enabling them by name in their config
+type Registry struct {
+	mu      sync.RWMutex
+	filters map[string]Filter
+}
+
+func NewRegistry() *Registry {
+	return &Registry{filters: map[string]Filter{}}
+}
+
+// Register adds the filter, replacing the filter of the same name
+func (r *Registry) Register(name string, f Filter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.filters[name] = f
+}
+
+// Apply runs the content through the named filters in order, each getting the text of the previous one. The unknown
+// filters fail the content, not to serve it unfiltered.
+func (r *Registry) Apply(ctx context.Context, names []string, c Content) (string, error) {
+	for _, name := range names {
+		r.mu.RLock()
+		f, ok := r.filters[name]
+		r.mu.RUnlock()
+		if !ok {
+			return "", fmt.Errorf("unknown content filter %q", name)
+		}
+		text, err := f.Filter(ctx, c)
+		if err != nil {
+			var rejected *RejectedError
+			if errors.As(err, &rejected) && rejected.Filter == "" {
+				rejected.Filter = name
+			}
+			return "", err
+		}
+		c.Text = text
+	}
+	return c.Text, nil
+}
+
+// HTTPFilter posts the contents as JSON to an external service, which answers with the text to use, or rejects them:
+//
+//	{"text": "...", "rejected": false, "reason": ""}
+type HTTPFilter struct {
+	URL    string
+	client *http.Client
+}
+
+// NewHTTPFilter returns a filter calling the URL, failing the contents when it does not answer within the timeout
+func NewHTTPFilter(url string, timeout time.Duration) *HTTPFilter {
+	return &HTTPFilter{URL: url, client: &http.Client{Timeout: timeout}}
+}
+
+type httpResponse struct {
+	Text     *string `json:"text"`
+	Rejected bool    `json:"rejected"`
+	Reason   string  `json:"reason"`
+}
+
+func (f *HTTPFilter) Filter(ctx context.Context, c Content) (string, error) {
+	body, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("content filter %s failed: %w", f.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("content filter %s answered %s", f.URL, resp.Status)
+	}
+
+	res := httpResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return "", fmt.Errorf("invalid answer of the content filter %s: %w", f.URL, err)
+	}
+	if res.Rejected {
+		return "", &RejectedError{Stage: c.Stage, Reason: res.Reason}
+	}
+	// the content is kept as is when the filter has no text to replace it with
+	if res.Text == nil {
+		return c.Text, nil
+	}
+	return *res.Text, nil
+}
+
+// ParseHTTPFilters parses the external filters, formatted as <name>=<url>
+func ParseHTTPFilters(filters []string, timeout time.Duration) (map[string]Filter, error) {
+	res := map[string]Filter{}
+	for _, f := range filters {
+		name, url, ok := strings.Cut(f, "=")
+		if !ok || name == "" || url == "" {
+			return nil, fmt.Errorf("invalid content filter %q, the format is <name>=<url>", f)
+		}
+		res[name] = NewHTTPFilter(url, timeout)
+	}
+	return res, nil
+}
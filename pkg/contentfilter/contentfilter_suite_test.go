@@ -0,0 +1,13 @@
+package contentfilter_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestContentFilter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Content filter test suite")
+}
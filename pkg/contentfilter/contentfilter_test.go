@@ -0,0 +1,78 @@
+package contentfilter_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-skynet/LocalAI/pkg/contentfilter"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Content filters", func() {
+	email := regexp.MustCompile(`\S+@\S+`)
+	redact := contentfilter.FilterFunc(func(ctx context.Context, c contentfilter.Content) (string, error) {
+		return email.ReplaceAllString(c.Text, "[EMAIL]"), nil
+	})
+
+	It("runs the filters in order", func() {
+		r := contentfilter.NewRegistry()
+		r.Register("redact", redact)
+		r.Register("upper", contentfilter.FilterFunc(func(ctx context.Context, c contentfilter.Content) (string, error) {
+			return strings.ToUpper(c.Text), nil
+		}))
+
+		text, err := r.Apply(context.Background(), []string{"redact", "upper"}, contentfilter.Content{Stage: contentfilter.Prompt, Text: "write to bob@example.com"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(text).To(Equal("WRITE TO [EMAIL]"))
+
+		_, err = r.Apply(context.Background(), []string{"unknown"}, contentfilter.Content{Text: "hello"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("calls the external filters", func() {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c := contentfilter.Content{}
+			Expect(json.NewDecoder(r.Body).Decode(&c)).To(Succeed())
+			switch {
+			case strings.Contains(c.Text, "secret"):
+				json.NewEncoder(w).Encode(map[string]interface{}{"rejected": true, "reason": "confidential"})
+			case c.Stage == contentfilter.Response:
+				json.NewEncoder(w).Encode(map[string]interface{}{"text": c.Model + ": " + c.Text})
+			default:
+				json.NewEncoder(w).Encode(map[string]interface{}{})
+			}
+		}))
+		defer srv.Close()
+
+		filters, err := contentfilter.ParseHTTPFilters([]string{"policy=" + srv.URL}, time.Second)
+		Expect(err).ToNot(HaveOccurred())
+		r := contentfilter.NewRegistry()
+		for name, f := range filters {
+			r.Register(name, f)
+		}
+
+		text, err := r.Apply(context.Background(), []string{"policy"}, contentfilter.Content{Model: "mistral", Stage: contentfilter.Prompt, Text: "hello"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(text).To(Equal("hello"))
+
+		text, err = r.Apply(context.Background(), []string{"policy"}, contentfilter.Content{Model: "mistral", Stage: contentfilter.Response, Text: "hi"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(text).To(Equal("mistral: hi"))
+
+		_, err = r.Apply(context.Background(), []string{"policy"}, contentfilter.Content{Stage: contentfilter.Prompt, Text: "the secret plan"})
+		var rejected *contentfilter.RejectedError
+		Expect(errors.As(err, &rejected)).To(BeTrue())
+		Expect(rejected.Filter).To(Equal("policy"))
+		Expect(rejected.Reason).To(Equal("confidential"))
+
+		_, err = contentfilter.ParseHTTPFilters([]string{"policy"}, time.Second)
+		Expect(err).To(HaveOccurred())
+	})
+})
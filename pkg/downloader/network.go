@@ -0,0 +1,213 @@
+package downloader
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// network settings applied to all the downloads
+var network = struct {
+	sync.RWMutex
+	client  *http.Client
+	proxy   string
+	mirrors map[string]string
+	offline bool
+	// concurrency is the number of segments of a file downloaded at the same time
+	concurrency int
+	// authorizations are the Authorization headers sent with the requests to the URLs starting with their key
+	authorizations map[string]string
+}{
+	client:      http.DefaultClient,
+	concurrency: 4,
+}
+
+// SetProxy sends the downloads through the given HTTP(S) proxy. When empty, the
+// proxy is taken from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+func SetProxy(proxy string) error {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxy != "" {
+		u, err := url.Parse(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid proxy %q: %w", proxy, err)
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+
+	network.Lock()
+	defer network.Unlock()
+	network.proxy = proxy
+	network.client = &http.Client{Transport: transport}
+	return nil
+}
+
+// SetMirrors rewrites the URLs starting with the keys of mirrors (e.g. https://huggingface.co),
+// to start with the corresponding values instead (e.g. https://mirror.example.com/huggingface)
+func SetMirrors(mirrors map[string]string) {
+	network.Lock()
+	defer network.Unlock()
+	network.mirrors = map[string]string{}
+	for from, to := range mirrors {
+		network.mirrors[strings.TrimSuffix(from, "/")] = strings.TrimSuffix(to, "/")
+	}
+}
+
+// ParseMirrors parses mirrors in the from=to form
+func ParseMirrors(mirrors []string) (map[string]string, error) {
+	res := map[string]string{}
+	for _, m := range mirrors {
+		from, to, ok := strings.Cut(m, "=")
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("invalid mirror %q, the format is <url>=<mirror url>", m)
+		}
+		res[from] = to
+	}
+	return res, nil
+}
+
+// SetOffline makes the downloads fail, unless they are served by a mirror
+func SetOffline(offline bool) {
+	network.Lock()
+	defer network.Unlock()
+	network.offline = offline
+}
+
+// SetConcurrency sets how many segments of a file are downloaded in parallel, when the server supports range requests
+func SetConcurrency(concurrency int) {
+	network.Lock()
+	defer network.Unlock()
+	network.concurrency = max(concurrency, 1)
+}
+
+// setAuthorization sends the Authorization header with the requests to the URLs starting with prefix,
+// e.g. with the downloads of the layers of an OCI registry
+func setAuthorization(prefix, authorization string) {
+	network.Lock()
+	defer network.Unlock()
+	if network.authorizations == nil {
+		network.authorizations = map[string]string{}
+	}
+	if authorization == "" {
+		delete(network.authorizations, prefix)
+		return
+	}
+	network.authorizations[prefix] = authorization
+}
+
+func authorization(u string) string {
+	network.RLock()
+	defer network.RUnlock()
+	for prefix, a := range network.authorizations {
+		if strings.HasPrefix(u, prefix) {
+			return a
+		}
+	}
+	return ""
+}
+
+func downloadConcurrency() int {
+	network.RLock()
+	defer network.RUnlock()
+	return network.concurrency
+}
+
+// Offline returns true if the downloads are disabled
+func Offline() bool {
+	network.RLock()
+	defer network.RUnlock()
+	return network.offline
+}
+
+// mirror returns the URL rewritten to its mirror, and if a mirror was found
+func mirror(u string) (string, bool) {
+	network.RLock()
+	defer network.RUnlock()
+
+	// the longest prefix wins
+	prefixes := []string{}
+	for from := range network.mirrors {
+		prefixes = append(prefixes, from)
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+
+	for _, from := range prefixes {
+		if u == from || strings.HasPrefix(u, from+"/") {
+			return network.mirrors[from] + strings.TrimPrefix(u, from), true
+		}
+	}
+	return u, false
+}
+
+// Mirror returns the URL rewritten to its mirror, if any
+func Mirror(u string) string {
+	m, _ := mirror(u)
+	return m
+}
+
+// Get sends a GET request with the network settings: the URL is rewritten to its mirror,
+// and it fails without any network access in offline mode (unless the URL is mirrored)
+func Get(u string) (*http.Response, error) {
+	return GetWithHeaders(u, nil)
+}
+
+// GetWithHeaders sends a GET request with the given headers (e.g. Range), and the network settings like Get
+func GetWithHeaders(u string, headers map[string]string) (*http.Response, error) {
+	m, mirrored := mirror(u)
+	if Offline() && !mirrored {
+		return nil, fmt.Errorf("can't download %q: LocalAI is running in offline mode", u)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, m, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	// the authorization of the downloads, e.g. from a secret, takes precedence over the token of the environment
+	if a := authorization(u); a != "" && !mirrored && req.Header.Get("Authorization") == "" {
+		req.Header.Set("Authorization", a)
+	}
+	// the token is only sent to HuggingFace: it is dropped by the client on the redirections to the CDN
+	if token := HuggingFaceToken(); token != "" && !mirrored && strings.HasPrefix(u, huggingFaceEndpoint+"/") && req.Header.Get("Authorization") == "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	network.RLock()
+	client := network.client
+	network.RUnlock()
+	return client.Do(req)
+}
+
+const huggingFaceEndpoint = "https://huggingface.co"
+
+// HuggingFaceToken returns the token used to download the gated and private models of HuggingFace,
+// from the HF_TOKEN (or HUGGING_FACE_HUB_TOKEN) environment variable
+func HuggingFaceToken() string {
+	if token := os.Getenv("HF_TOKEN"); token != "" {
+		return token
+	}
+	return os.Getenv("HUGGING_FACE_HUB_TOKEN")
+}
+
+// BackendEnvironment returns the environment variables which apply the network settings
+// to the backends downloading files by themselves (e.g. from HuggingFace)
+func BackendEnvironment() []string {
+	network.RLock()
+	defer network.RUnlock()
+
+	env := []string{}
+	if network.proxy != "" {
+		env = append(env, "HTTP_PROXY="+network.proxy, "HTTPS_PROXY="+network.proxy)
+	}
+	if m, ok := network.mirrors[huggingFaceEndpoint]; ok {
+		env = append(env, "HF_ENDPOINT="+m)
+	} else if network.offline {
+		env = append(env, "HF_HUB_OFFLINE=1", "TRANSFORMERS_OFFLINE=1")
+	}
+	return env
+}
@@ -0,0 +1,79 @@
+package downloader
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-skynet/LocalAI/pkg/secrets"
+	"github.com/go-skynet/LocalAI/pkg/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// the prefixes of the objects of S3, Google Cloud Storage and Azure Blob Storage, e.g. s3://bucket/models/phi-2.gguf
+const (
+	S3Prefix    = "s3://"
+	GCSPrefix   = "gs://"
+	AzurePrefix = "az://"
+)
+
+func isObjectURI(s string) bool {
+	return strings.HasPrefix(s, S3Prefix) || strings.HasPrefix(s, GCSPrefix) || strings.HasPrefix(s, AzurePrefix)
+}
+
+// downloadObject downloads an object of a storage, with the credentials of the environment or of the secret
+func downloadObject(uri, filePath, sha string, secret *secrets.Secret, downloadStatus func(string, string, string, float64)) error {
+	if Offline() {
+		return fmt.Errorf("can't download %q: LocalAI is running in offline mode", uri)
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return fmt.Errorf("invalid URI %q: %w", uri, err)
+	}
+	name := strings.TrimPrefix(u.Path, "/")
+	if name == "" {
+		return fmt.Errorf("invalid URI %q: the object is missing", uri)
+	}
+	bucket := u.Scheme + "://" + u.Host
+	var st storage.Storage
+	if secret != nil {
+		st, err = storage.NewWithSecret(bucket, *secret)
+	} else {
+		st, err = storage.New(bucket)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msgf("Downloading %q", uri)
+	r, err := st.Open(context.Background(), name)
+	if err != nil {
+		return fmt.Errorf("failed to download file %q: %w", filePath, err)
+	}
+	defer r.Close()
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create parent directory for file %q: %v", filePath, err)
+	}
+	tmpFilePath := filePath + ".partial"
+	outFile, err := os.Create(tmpFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %q: %v", tmpFilePath, err)
+	}
+	defer outFile.Close()
+
+	progress := &progressWriter{
+		fileName:       tmpFilePath,
+		hash:           sha256.New(),
+		downloadStatus: downloadStatus,
+	}
+	if _, err := io.Copy(io.MultiWriter(outFile, progress), r); err != nil {
+		return fmt.Errorf("failed to write file %q: %v", filePath, err)
+	}
+	return finishDownload(tmpFilePath, filePath, sha, fmt.Sprintf("%x", progress.hash.Sum(nil)))
+}
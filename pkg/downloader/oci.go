@@ -0,0 +1,394 @@
+package downloader
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/go-skynet/LocalAI/pkg/secrets"
+)
+
+const OCIPrefix = "oci://"
+
+const (
+	dockerHubRegistry = "docker.io"
+	// the registry serving the images of docker.io, and the key of its credentials in the docker config
+	dockerHubEndpoint = "registry-1.docker.io"
+	dockerHubAuthKey  = "https://index.docker.io/v1/"
+)
+
+// the manifests accepted from the registries: the OCI ones, and the Docker ones which some registries still serve
+var ociManifestTypes = []string{
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+}
+
+// ociReference is an artifact of a registry, e.g. oci://registry.example.com/models/phi-2:q8_0
+type ociReference struct {
+	Registry   string
+	Repository string
+	// Reference is a tag, or a digest (e.g. sha256:...)
+	Reference string
+}
+
+// parseOCIReference parses an oci:// URI like the docker CLI parses the image names: without a registry,
+// the artifact is on Docker Hub, and without a tag or a digest, its tag is latest
+func parseOCIReference(uri string) (ociReference, error) {
+	name := strings.TrimPrefix(uri, OCIPrefix)
+	ref := ociReference{Reference: "latest"}
+
+	if n, digest, ok := strings.Cut(name, "@"); ok {
+		if !strings.HasPrefix(digest, "sha256:") {
+			return ref, fmt.Errorf("invalid OCI reference %q: only sha256 digests are supported", uri)
+		}
+		name, ref.Reference = n, digest
+	} else if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ref.Reference = name[:i], name[i+1:]
+	}
+
+	registry, repository, ok := strings.Cut(name, "/")
+	if !ok || (!strings.ContainsAny(registry, ".:") && registry != "localhost") {
+		registry, repository = dockerHubRegistry, name
+	}
+	if registry == dockerHubRegistry && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	if repository == "" || ref.Reference == "" {
+		return ref, fmt.Errorf("invalid OCI reference %q, the format is oci://registry/repository:tag", uri)
+	}
+	ref.Registry, ref.Repository = registry, repository
+	return ref, nil
+}
+
+// endpoint returns the base URL of the registry, which is only accessed without TLS on the local host
+func (r ociReference) endpoint() string {
+	if r.Registry == dockerHubRegistry {
+		return "https://" + dockerHubEndpoint
+	}
+	host := r.Registry
+	if h, _, ok := strings.Cut(host, ":"); ok {
+		host = h
+	}
+	if host == "localhost" || host == "127.0.0.1" {
+		return "http://" + r.Registry
+	}
+	return "https://" + r.Registry
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Platform    *struct {
+		OS           string `json:"os"`
+		Architecture string `json:"architecture"`
+	} `json:"platform,omitempty"`
+}
+
+type ociManifest struct {
+	MediaType string `json:"mediaType"`
+	// Manifests are set for the indexes, Layers for the manifests
+	Manifests []ociDescriptor `json:"manifests"`
+	Layers    []ociDescriptor `json:"layers"`
+}
+
+// ociBlob is the layer of an artifact holding the model, with what is needed to download and verify it
+type ociBlob struct {
+	URL           string
+	Authorization string
+	// SHA is the SHA256 of the layer, from its digest
+	SHA  string
+	Name string
+}
+
+type ociClient struct {
+	ref           ociReference
+	authorization string
+	// secret authenticates to the registry instead of the docker configuration, if set
+	secret *secrets.Secret
+}
+
+// get sends a request to the registry, and authenticates when the registry asks for it
+func (c *ociClient) get(u string, headers map[string]string) (*http.Response, error) {
+	send := func() (*http.Response, error) {
+		h := map[string]string{}
+		for k, v := range headers {
+			h[k] = v
+		}
+		if c.authorization != "" {
+			h["Authorization"] = c.authorization
+		}
+		return GetWithHeaders(u, h)
+	}
+
+	resp, err := send()
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || c.authorization != "" {
+		return resp, err
+	}
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close()
+	if err := c.authenticate(challenge); err != nil {
+		return nil, err
+	}
+	return send()
+}
+
+// authenticate answers the challenge of the registry, with the credentials of the secret or of the docker
+// configuration if any
+func (c *ociClient) authenticate(challenge string) error {
+	username, secret, err := c.credentials()
+	if err != nil {
+		return err
+	}
+
+	scheme, params := parseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if username == "" && secret == "" {
+			return fmt.Errorf("the registry %s requires credentials, log in with docker login or set a credential helper", c.ref.Registry)
+		}
+		c.authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+secret))
+		return nil
+	case "bearer":
+		realm, err := url.Parse(params["realm"])
+		if err != nil || params["realm"] == "" {
+			return fmt.Errorf("the registry %s sent an invalid challenge %q", c.ref.Registry, challenge)
+		}
+		q := realm.Query()
+		if params["service"] != "" {
+			q.Set("service", params["service"])
+		}
+		q.Set("scope", fmt.Sprintf("repository:%s:pull", c.ref.Repository))
+		realm.RawQuery = q.Encode()
+
+		headers := map[string]string{}
+		if username != "" || secret != "" {
+			headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+secret))
+		}
+		resp, err := GetWithHeaders(realm.String(), headers)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("the registry %s denied the access to %s (status %d), log in with docker login or set a credential helper", c.ref.Registry, c.ref.Repository, resp.StatusCode)
+		}
+		token := struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}{}
+		if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+			return fmt.Errorf("invalid token from %s: %w", realm.Host, err)
+		}
+		if token.Token == "" {
+			token.Token = token.AccessToken
+		}
+		c.authorization = "Bearer " + token.Token
+		return nil
+	default:
+		return fmt.Errorf("the registry %s requires an unsupported authentication %q", c.ref.Registry, scheme)
+	}
+}
+
+// parseChallenge parses a WWW-Authenticate header, e.g. Bearer realm="https://auth.example.com/token",service="registry"
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := map[string]string{}
+	for rest != "" {
+		var key, value string
+		key, rest, _ = strings.Cut(strings.TrimLeft(rest, ", "), "=")
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+	return scheme, params
+}
+
+// manifest fetches the manifest of the reference, and verifies it against the digest of the reference if it is one
+func (c *ociClient) manifest(reference string) (*ociManifest, error) {
+	u := fmt.Sprintf("%s/v2/%s/manifests/%s", c.ref.endpoint(), c.ref.Repository, reference)
+	resp, err := c.get(u, map[string]string{"Accept": strings.Join(ociManifestTypes, ", ")})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", u, resp.StatusCode)
+	}
+	// the manifests are small, but the registry must not make LocalAI read anything
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4*1024*1024))
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(reference, "sha256:") {
+		if digest := fmt.Sprintf("sha256:%x", sha256.Sum256(body)); digest != reference {
+			return nil, fmt.Errorf("the manifest of %s has the digest %s instead of %s", c.ref.Repository, digest, reference)
+		}
+	}
+
+	m := &ociManifest{}
+	if err := json.Unmarshal(body, m); err != nil {
+		return nil, fmt.Errorf("invalid manifest from %s: %w", u, err)
+	}
+	return m, nil
+}
+
+// credentials returns the username and the password, or the token, of the registry
+func (c *ociClient) credentials() (string, string, error) {
+	if c.secret == nil {
+		return dockerCredentials(c.ref.Registry)
+	}
+	if c.secret.Password == "" && c.secret.Token != "" {
+		username := c.secret.Username
+		if username == "" {
+			username = "<token>"
+		}
+		return username, c.secret.Token, nil
+	}
+	return c.secret.Username, c.secret.Password, nil
+}
+
+// resolveOCIBlob finds the layer of the artifact holding the model: the only layer, or the largest one
+// for the artifacts with several files (e.g. a README along with the weights)
+func resolveOCIBlob(uri string, secret *secrets.Secret) (*ociBlob, error) {
+	ref, err := parseOCIReference(uri)
+	if err != nil {
+		return nil, err
+	}
+	c := &ociClient{ref: ref, secret: secret}
+
+	m, err := c.manifest(ref.Reference)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Manifests) > 0 {
+		// an index: the artifacts of the platform of LocalAI, or without platform, are preferred
+		chosen := m.Manifests[0]
+		for _, d := range m.Manifests {
+			if d.Platform == nil || (d.Platform.OS == runtime.GOOS && d.Platform.Architecture == runtime.GOARCH) {
+				chosen = d
+				break
+			}
+		}
+		if m, err = c.manifest(chosen.Digest); err != nil {
+			return nil, err
+		}
+	}
+	if len(m.Layers) == 0 {
+		return nil, fmt.Errorf("the artifact %s has no layers", uri)
+	}
+
+	layer := m.Layers[0]
+	for _, l := range m.Layers[1:] {
+		if l.Size > layer.Size {
+			layer = l
+		}
+	}
+	sha, ok := strings.CutPrefix(layer.Digest, "sha256:")
+	if !ok {
+		return nil, fmt.Errorf("the layer of %s has the unsupported digest %q", uri, layer.Digest)
+	}
+	return &ociBlob{
+		URL:           fmt.Sprintf("%s/v2/%s/blobs/%s", ref.endpoint(), ref.Repository, layer.Digest),
+		Authorization: c.authorization,
+		SHA:           sha,
+		Name:          layer.Annotations["org.opencontainers.image.title"],
+	}, nil
+}
+
+type dockerConfig struct {
+	Auths map[string]struct {
+		Auth          string `json:"auth"`
+		IdentityToken string `json:"identitytoken"`
+	} `json:"auths"`
+	CredHelpers map[string]string `json:"credHelpers"`
+	CredsStore  string            `json:"credsStore"`
+}
+
+// dockerCredentials returns the credentials of the registry from the docker configuration
+// ($DOCKER_CONFIG/config.json or ~/.docker/config.json): from its credential helper, or stored in the file.
+// They are empty if the registry has none.
+func dockerCredentials(registry string) (string, string, error) {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", nil
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+	dat, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return "", "", nil
+	}
+	cfg := dockerConfig{}
+	if err := json.Unmarshal(dat, &cfg); err != nil {
+		return "", "", fmt.Errorf("invalid docker configuration %s: %w", filepath.Join(dir, "config.json"), err)
+	}
+
+	key := registry
+	if registry == dockerHubRegistry {
+		key = dockerHubAuthKey
+	}
+	if helper, ok := cfg.CredHelpers[registry]; ok {
+		return credentialHelper(helper, key)
+	}
+	for k, a := range cfg.Auths {
+		if k != key && strings.TrimPrefix(strings.TrimPrefix(k, "https://"), "http://") != registry {
+			continue
+		}
+		if a.IdentityToken != "" {
+			return "<token>", a.IdentityToken, nil
+		}
+		dec, err := base64.StdEncoding.DecodeString(a.Auth)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid credentials of %s in the docker configuration: %w", registry, err)
+		}
+		username, secret, _ := strings.Cut(string(dec), ":")
+		return username, secret, nil
+	}
+	if cfg.CredsStore != "" {
+		return credentialHelper(cfg.CredsStore, key)
+	}
+	return "", "", nil
+}
+
+// credentialHelper gets the credentials of the server from a docker credential helper (docker-credential-<helper>)
+func credentialHelper(helper, server string) (string, string, error) {
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(server)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		// the helpers fail when they have no credentials for the server
+		if strings.Contains(string(out)+stderr.String(), "credentials not found") {
+			return "", "", nil
+		}
+		return "", "", fmt.Errorf("the credential helper docker-credential-%s failed: %w: %s", helper, err, strings.TrimSpace(stderr.String()))
+	}
+	creds := struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}{}
+	if err := json.Unmarshal(out, &creds); err != nil {
+		return "", "", fmt.Errorf("invalid output of the credential helper docker-credential-%s: %w", helper, err)
+	}
+	return creds.Username, creds.Secret, nil
+}
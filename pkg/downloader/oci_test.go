@@ -0,0 +1,161 @@
+package downloader_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/go-skynet/LocalAI/pkg/downloader"
+	"github.com/go-skynet/LocalAI/pkg/secrets"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OCI registries", func() {
+	var server *httptest.Server
+	var blobs map[string][]byte
+	var manifests map[string][]byte
+	var dir string
+
+	noStatus := func(string, string, string, float64) {}
+
+	digest := func(b []byte) string {
+		return fmt.Sprintf("sha256:%x", sha256.Sum256(b))
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		blobs = map[string][]byte{}
+		manifests = map[string][]byte{}
+
+		weights := []byte(strings.Repeat("weights", 1000))
+		readme := []byte("# phi-2")
+		blobs[digest(weights)] = weights
+		blobs[digest(readme)] = readme
+
+		manifest, err := json.Marshal(map[string]interface{}{
+			"schemaVersion": 2,
+			"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+			"layers": []map[string]interface{}{
+				{"mediaType": "text/markdown", "digest": digest(readme), "size": len(readme)},
+				{"mediaType": "application/octet-stream", "digest": digest(weights), "size": len(weights),
+					"annotations": map[string]string{"org.opencontainers.image.title": "phi-2.Q8_0.gguf"}},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		index, err := json.Marshal(map[string]interface{}{
+			"schemaVersion": 2,
+			"mediaType":     "application/vnd.oci.image.index.v1+json",
+			"manifests": []map[string]interface{}{
+				{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": digest(manifest), "size": len(manifest)},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		manifests["q8_0"] = manifest
+		manifests["index"] = index
+		manifests[digest(manifest)] = manifest
+
+		// a registry requiring a token, which is given to the user of the docker configuration
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/token" {
+				user, password, ok := r.BasicAuth()
+				if !ok || user != "user" || password != "password" || r.URL.Query().Get("scope") != "repository:models/phi-2:pull" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				json.NewEncoder(w).Encode(map[string]string{"token": "secret-token"})
+				return
+			}
+			if r.Header.Get("Authorization") != "Bearer secret-token" {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="http://%s/token",service="registry"`, r.Host))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			switch {
+			case strings.HasPrefix(r.URL.Path, "/v2/models/phi-2/manifests/"):
+				m, ok := manifests[strings.TrimPrefix(r.URL.Path, "/v2/models/phi-2/manifests/")]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Write(m)
+			case strings.HasPrefix(r.URL.Path, "/v2/models/phi-2/blobs/"):
+				b, ok := blobs[strings.TrimPrefix(r.URL.Path, "/v2/models/phi-2/blobs/")]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Write(b)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+
+		config := map[string]interface{}{
+			"auths": map[string]interface{}{
+				strings.TrimPrefix(server.URL, "http://"): map[string]string{"auth": base64.StdEncoding.EncodeToString([]byte("user:password"))},
+			},
+		}
+		dat, err := json.Marshal(config)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(dir, "config.json"), dat, 0600)).To(Succeed())
+		GinkgoT().Setenv("DOCKER_CONFIG", dir)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	registry := func() string {
+		return OCIPrefix + strings.TrimPrefix(server.URL, "http://")
+	}
+
+	It("downloads the largest layer of the artifact", func() {
+		path := filepath.Join(dir, "model.gguf")
+		Expect(DownloadFile(registry()+"/models/phi-2:q8_0", path, "", noStatus)).To(Succeed())
+		dat, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(dat)).To(Equal(strings.Repeat("weights", 1000)))
+	})
+
+	It("resolves the indexes and the digests", func() {
+		path := filepath.Join(dir, "index.gguf")
+		Expect(DownloadFile(registry()+"/models/phi-2:index", path, "", noStatus)).To(Succeed())
+		Expect(path).To(BeARegularFile())
+
+		path = filepath.Join(dir, "digest.gguf")
+		Expect(DownloadFile(registry()+"/models/phi-2@"+digest(manifests["q8_0"]), path, "", noStatus)).To(Succeed())
+		Expect(path).To(BeARegularFile())
+	})
+
+	It("verifies the content of the layers", func() {
+		for d := range blobs {
+			blobs[d] = []byte("tampered")
+		}
+		path := filepath.Join(dir, "model.gguf")
+		Expect(DownloadFile(registry()+"/models/phi-2:q8_0", path, "", noStatus)).To(MatchError(ContainSubstring("SHA mismatch")))
+		Expect(path).ToNot(BeAnExistingFile())
+
+		Expect(DownloadFile(registry()+"/models/phi-2@sha256:0123", path, "", noStatus)).ToNot(Succeed())
+	})
+
+	It("fails without credentials", func() {
+		Expect(os.Remove(filepath.Join(dir, "config.json"))).To(Succeed())
+		err := DownloadFile(registry()+"/models/phi-2:q8_0", filepath.Join(dir, "model.gguf"), "", noStatus)
+		Expect(err).To(MatchError(ContainSubstring("docker login")))
+	})
+
+	It("authenticates with a secret", func() {
+		Expect(os.Remove(filepath.Join(dir, "config.json"))).To(Succeed())
+		path := filepath.Join(dir, "model.gguf")
+		secret := &secrets.Secret{Username: "user", Password: "password"}
+		Expect(DownloadFileWithSecret(registry()+"/models/phi-2:q8_0", path, "", secret, noStatus)).To(Succeed())
+		Expect(path).To(BeARegularFile())
+	})
+})
@@ -0,0 +1,227 @@
+package downloader
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// minSegmentSize is the minimum size of the segments of a file downloaded in parallel
+const minSegmentSize = 16 * 1024 * 1024
+
+// errChanged is returned when the file changed on the server while it was downloaded
+var errChanged = errors.New("the file changed on the server")
+
+// segment is a range of a file, of which the first Done bytes are downloaded
+type segment struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+	Done  int64 `json:"done"`
+}
+
+func (s segment) complete() bool {
+	return s.Start+s.Done > s.End
+}
+
+// partialDownload is the bookkeeping of a download, saved next to the partial file so it can be resumed
+type partialDownload struct {
+	URL          string    `json:"url"`
+	Size         int64     `json:"size"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Segments     []segment `json:"segments"`
+}
+
+func readPartialDownload(path string) *partialDownload {
+	dat, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	p := &partialDownload{}
+	if err := json.Unmarshal(dat, p); err != nil {
+		return nil
+	}
+	return p
+}
+
+func (p *partialDownload) save(path string) error {
+	dat, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, dat, 0644)
+}
+
+func (p *partialDownload) written() int64 {
+	var n int64
+	for _, s := range p.Segments {
+		n += s.Done
+	}
+	return n
+}
+
+// ifRange returns the validator sent in If-Range, so the server sends the whole file if it changed
+func (p *partialDownload) ifRange() string {
+	if p.ETag != "" && !strings.HasPrefix(p.ETag, "W/") {
+		return p.ETag
+	}
+	return p.LastModified
+}
+
+func newSegments(size int64, concurrency int) []segment {
+	n := max(min(int64(concurrency), size/minSegmentSize), 1)
+	segmentSize := size / n
+	segments := []segment{}
+	for i := int64(0); i < n; i++ {
+		s := segment{Start: i * segmentSize, End: (i+1)*segmentSize - 1}
+		if i == n-1 {
+			s.End = size - 1
+		}
+		segments = append(segments, s)
+	}
+	return segments
+}
+
+// contentRangeSize returns the size of the file from a Content-Range header (e.g. bytes 0-0/1234), or -1 if unknown
+func contentRangeSize(contentRange string) int64 {
+	_, total, ok := strings.Cut(contentRange, "/")
+	if !ok {
+		return -1
+	}
+	size, err := strconv.ParseInt(total, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return size
+}
+
+type segmentWriter struct {
+	file   *os.File
+	offset int64
+	report func(int64)
+}
+
+func (w *segmentWriter) Write(p []byte) (int, error) {
+	n, err := w.file.WriteAt(p, w.offset)
+	w.offset += int64(n)
+	w.report(int64(n))
+	return n, err
+}
+
+func downloadSegment(url string, s segment, ifRange string, w io.Writer) error {
+	start := s.Start + s.Done
+	headers := map[string]string{"Range": fmt.Sprintf("bytes=%d-%d", start, s.End)}
+	if ifRange != "" {
+		headers["If-Range"] = ifRange
+	}
+	resp, err := GetWithHeaders(url, headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("could not download the bytes %d-%d of %q (status %d): %w", start, s.End, url, resp.StatusCode, errChanged)
+	}
+
+	n, err := io.Copy(w, io.LimitReader(resp.Body, s.End-start+1))
+	if err != nil {
+		return err
+	}
+	if n != s.End-start+1 {
+		return fmt.Errorf("could not download the bytes %d-%d of %q: %w", start, s.End, url, io.ErrUnexpectedEOF)
+	}
+	return nil
+}
+
+// downloadRanges downloads the file at url in tmpFilePath with range requests, with several segments in parallel.
+// The progress is saved next to the file, so an interrupted download is resumed if the file did not change on the server.
+func downloadRanges(url, tmpFilePath string, size int64, etag, lastModified string, downloadStatus func(string, string, string, float64)) error {
+	stateFile := tmpFilePath + ".json"
+	state := readPartialDownload(stateFile)
+	info, err := os.Stat(tmpFilePath)
+	if state != nil && err == nil && info.Size() == size &&
+		state.URL == url && state.Size == size && state.ETag == etag && state.LastModified == lastModified {
+		log.Info().Msgf("Resuming the download of %q from %s", url, formatBytes(state.written()))
+	} else {
+		if err := removePartialFile(tmpFilePath); err != nil {
+			return err
+		}
+		f, err := os.Create(tmpFilePath)
+		if err != nil {
+			return fmt.Errorf("failed to create file %q: %v", tmpFilePath, err)
+		}
+		err = f.Truncate(size)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to allocate file %q: %v", tmpFilePath, err)
+		}
+		state = &partialDownload{URL: url, Size: size, ETag: etag, LastModified: lastModified, Segments: newSegments(size, downloadConcurrency())}
+		if err := state.save(stateFile); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(tmpFilePath, os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open file %q: %v", tmpFilePath, err)
+	}
+	defer f.Close()
+
+	var mu sync.Mutex
+	written := state.written()
+	lastSave := time.Now()
+	report := func(i int, n int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		state.Segments[i].Done += n
+		written += n
+		downloadStatus(tmpFilePath, formatBytes(written), formatBytes(size), float64(written)/float64(size)*100)
+		if time.Since(lastSave) > time.Second {
+			if err := state.save(stateFile); err != nil {
+				log.Warn().Msgf("could not save the progress of the download of %q: %s", url, err.Error())
+			}
+			lastSave = time.Now()
+		}
+	}
+
+	sem := make(chan struct{}, downloadConcurrency())
+	errs := make(chan error, len(state.Segments))
+	var wg sync.WaitGroup
+	for i, s := range state.Segments {
+		if s.complete() {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, s segment) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			w := &segmentWriter{file: f, offset: s.Start + s.Done, report: func(n int64) { report(i, n) }}
+			if err := downloadSegment(url, s, state.ifRange(), w); err != nil {
+				errs <- err
+			}
+		}(i, s)
+	}
+	wg.Wait()
+	close(errs)
+
+	if err := <-errs; err != nil {
+		if errors.Is(err, errChanged) {
+			// the next download starts over
+			os.Remove(stateFile)
+		} else if saveErr := state.save(stateFile); saveErr != nil {
+			log.Warn().Msgf("could not save the progress of the download of %q: %s", url, saveErr.Error())
+		}
+		return err
+	}
+	return os.Remove(stateFile)
+}
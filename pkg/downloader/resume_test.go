@@ -0,0 +1,135 @@
+package downloader_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	. "github.com/go-skynet/LocalAI/pkg/downloader"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// abortingWriter aborts the response after limit bytes, like a broken connection
+type abortingWriter struct {
+	http.ResponseWriter
+	limit int64
+}
+
+func (w *abortingWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > w.limit {
+		w.ResponseWriter.Write(p[:w.limit])
+		panic(http.ErrAbortHandler)
+	}
+	w.limit -= int64(len(p))
+	return w.ResponseWriter.Write(p)
+}
+
+var _ = Describe("Resumable downloads", func() {
+	var content []byte
+	var sha string
+	var served atomic.Int64
+	var failAfter atomic.Int64
+	var ranges bool
+	var server *httptest.Server
+	var dir string
+
+	noStatus := func(string, string, string, float64) {}
+
+	BeforeEach(func() {
+		content = make([]byte, 40*1024*1024)
+		rand.New(rand.NewSource(1)).Read(content)
+		sha = fmt.Sprintf("%x", sha256.Sum256(content))
+		served.Store(0)
+		failAfter.Store(0)
+		ranges = true
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !ranges {
+				r.Header.Del("Range")
+			}
+			w.Header().Set("ETag", `"v1"`)
+			counting := &countingWriter{ResponseWriter: w, served: &served}
+			var rw http.ResponseWriter = counting
+			// the first byte is requested first to probe the range support
+			if r.Header.Get("Range") == "bytes=0-0" {
+				http.ServeContent(rw, r, "model.bin", time.Unix(0, 0), bytes.NewReader(content))
+				return
+			}
+			if limit := failAfter.Swap(0); limit > 0 {
+				rw = &abortingWriter{ResponseWriter: counting, limit: limit}
+			}
+			http.ServeContent(rw, r, "model.bin", time.Unix(0, 0), bytes.NewReader(content))
+		}))
+
+		var err error
+		dir, err = os.MkdirTemp("", "downloader")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(dir)
+	})
+
+	It("downloads the file in parallel segments and verifies it", func() {
+		path := filepath.Join(dir, "model.bin")
+		Expect(DownloadFile(server.URL, path, sha, noStatus)).To(Succeed())
+		dat, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bytes.Equal(dat, content)).To(BeTrue())
+		_, err = os.Stat(path + ".partial")
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("resumes an interrupted download", func() {
+		path := filepath.Join(dir, "model.bin")
+		failAfter.Store(5 * 1024 * 1024)
+		Expect(DownloadFile(server.URL, path, sha, noStatus)).ToNot(Succeed())
+		_, err := os.Stat(path + ".partial.json")
+		Expect(err).ToNot(HaveOccurred())
+
+		served.Store(0)
+		Expect(DownloadFile(server.URL, path, sha, noStatus)).To(Succeed())
+		Expect(served.Load()).To(BeNumerically("<", len(content)))
+		dat, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bytes.Equal(dat, content)).To(BeTrue())
+	})
+
+	It("downloads from servers without range support", func() {
+		ranges = false
+		path := filepath.Join(dir, "model.bin")
+		Expect(DownloadFile(server.URL, path, sha, noStatus)).To(Succeed())
+		dat, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bytes.Equal(dat, content)).To(BeTrue())
+	})
+
+	It("rejects files with a wrong checksum", func() {
+		path := filepath.Join(dir, "model.bin")
+		Expect(DownloadFile(server.URL, path, "0000", noStatus)).To(MatchError(ContainSubstring("SHA mismatch")))
+		_, err := os.Stat(path)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+		_, err = os.Stat(path + ".partial")
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+})
+
+type countingWriter struct {
+	http.ResponseWriter
+	served *atomic.Int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.served.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,68 @@
+package downloader_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/go-skynet/LocalAI/pkg/downloader"
+	"github.com/go-skynet/LocalAI/pkg/secrets"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Downloads with secrets", func() {
+	var server *httptest.Server
+	var dir string
+	var authorizations []string
+
+	noStatus := func(string, string, string, float64) {}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		authorizations = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			a := r.Header.Get("Authorization")
+			authorizations = append(authorizations, a)
+			switch {
+			case r.URL.Path == "/private/model.gguf" && a == "Bearer hf_private":
+				w.Write([]byte("weights"))
+			case r.URL.Path == "/bucket/models/model.gguf" && strings.HasPrefix(a, "AWS4-HMAC-SHA256 Credential=access/"):
+				w.Write([]byte("weights"))
+			default:
+				w.WriteHeader(http.StatusForbidden)
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("sends the token of the secret", func() {
+		path := filepath.Join(dir, "model.gguf")
+		Expect(DownloadFile(server.URL+"/private/model.gguf", path, "", noStatus)).ToNot(Succeed())
+
+		Expect(DownloadFileWithSecret(server.URL+"/private/model.gguf", path, "", &secrets.Secret{Token: "hf_private"}, noStatus)).To(Succeed())
+		dat, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(dat)).To(Equal("weights"))
+
+		// the token is only sent with the downloads of the secret
+		Expect(DownloadFile(server.URL+"/other.gguf", filepath.Join(dir, "other.gguf"), "", noStatus)).ToNot(Succeed())
+		Expect(authorizations[len(authorizations)-1]).To(BeEmpty())
+	})
+
+	It("downloads the objects of S3 with the keys of the secret", func() {
+		path := filepath.Join(dir, "model.gguf")
+		secret := &secrets.Secret{AccessKeyID: "access", SecretAccessKey: "secret", Region: "eu-west-1", Endpoint: server.URL}
+		Expect(DownloadFileWithSecret("s3://bucket/models/model.gguf", path, "", secret, noStatus)).To(Succeed())
+		dat, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(dat)).To(Equal("weights"))
+
+		Expect(DownloadFileWithSecret("s3://bucket/models/missing.gguf", filepath.Join(dir, "missing.gguf"), "", secret, noStatus)).ToNot(Succeed())
+	})
+})
@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 
+	"github.com/go-skynet/LocalAI/pkg/secrets"
 	"github.com/go-skynet/LocalAI/pkg/utils"
 	"github.com/rs/zerolog/log"
 )
@@ -44,7 +46,7 @@ func GetURI(url string, f func(url string, i []byte) error) error {
 	}
 
 	// Send a GET request to the URL
-	response, err := http.Get(url)
+	response, err := Get(url)
 	if err != nil {
 		return err
 	}
@@ -65,7 +67,9 @@ func LooksLikeURL(s string) bool {
 		strings.HasPrefix(s, HTTPSPrefix) ||
 		strings.HasPrefix(s, HuggingFacePrefix) ||
 		strings.HasPrefix(s, GithubURI) ||
-		strings.HasPrefix(s, GithubURI2)
+		strings.HasPrefix(s, GithubURI2) ||
+		strings.HasPrefix(s, OCIPrefix) ||
+		isObjectURI(s)
 }
 
 func ConvertURL(s string) string {
@@ -102,26 +106,33 @@ func ConvertURL(s string) string {
 
 		return fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/%s", org, project, branch, projectPath)
 	case strings.HasPrefix(s, HuggingFacePrefix):
-		repository := strings.Replace(s, HuggingFacePrefix, "", 1)
-		// convert repository to a full URL.
-		// e.g. TheBloke/Mixtral-8x7B-v0.1-GGUF/mixtral-8x7b-v0.1.Q2_K.gguf@main -> https://huggingface.co/TheBloke/Mixtral-8x7B-v0.1-GGUF/resolve/main/mixtral-8x7b-v0.1.Q2_K.gguf
-		owner := strings.Split(repository, "/")[0]
-		repo := strings.Split(repository, "/")[1]
-		branch := "main"
-		if strings.Contains(repo, "@") {
-			branch = strings.Split(repository, "@")[1]
-		}
-		filepath := strings.Split(repository, "/")[2]
-		if strings.Contains(filepath, "@") {
-			filepath = strings.Split(filepath, "@")[0]
-		}
-
-		return fmt.Sprintf("https://huggingface.co/%s/%s/resolve/%s/%s", owner, repo, branch, filepath)
+		return huggingFaceURL(strings.TrimPrefix(s, HuggingFacePrefix))
 	}
 
 	return s
 }
 
+// huggingFaceURL converts a HuggingFace repository file to the URL resolving it with the HuggingFace Hub API.
+// The revision (a branch, tag or commit, "main" by default) follows either the repository or the file:
+// e.g. TheBloke/Mixtral-8x7B-v0.1-GGUF/mixtral-8x7b-v0.1.Q2_K.gguf@main or TheBloke/Mixtral-8x7B-v0.1-GGUF@main/mixtral-8x7b-v0.1.Q2_K.gguf
+// -> https://huggingface.co/TheBloke/Mixtral-8x7B-v0.1-GGUF/resolve/main/mixtral-8x7b-v0.1.Q2_K.gguf
+func huggingFaceURL(repository string) string {
+	parts := strings.SplitN(repository, "/", 3)
+	if len(parts) < 3 {
+		return HuggingFacePrefix + repository
+	}
+	owner, repo, file := parts[0], parts[1], parts[2]
+
+	revision := "main"
+	if r, rev, ok := strings.Cut(repo, "@"); ok {
+		repo, revision = r, rev
+	} else if i := strings.LastIndex(file, "@"); i >= 0 {
+		file, revision = file[:i], file[i+1:]
+	}
+
+	return fmt.Sprintf("%s/%s/%s/resolve/%s/%s", huggingFaceEndpoint, owner, repo, url.PathEscape(revision), file)
+}
+
 func removePartialFile(tmpFilePath string) error {
 	_, err := os.Stat(tmpFilePath)
 	if err == nil {
@@ -137,6 +148,12 @@ func removePartialFile(tmpFilePath string) error {
 }
 
 func DownloadFile(url string, filePath, sha string, downloadStatus func(string, string, string, float64)) error {
+	return DownloadFileWithSecret(url, filePath, sha, nil, downloadStatus)
+}
+
+// DownloadFileWithSecret downloads the file like DownloadFile, authenticated with the secret instead of the
+// environment when it is not nil
+func DownloadFileWithSecret(url string, filePath, sha string, secret *secrets.Secret, downloadStatus func(string, string, string, float64)) error {
 	url = ConvertURL(url)
 	// Check if the file already exists
 	_, err := os.Stat(filePath)
@@ -144,7 +161,7 @@ func DownloadFile(url string, filePath, sha string, downloadStatus func(string,
 		// File exists, check SHA
 		if sha != "" {
 			// Verify SHA
-			calculatedSHA, err := calculateSHA(filePath)
+			calculatedSHA, err := CalculateSHA(filePath)
 			if err != nil {
 				return fmt.Errorf("failed to calculate SHA for file %q: %v", filePath, err)
 			}
@@ -170,14 +187,29 @@ func DownloadFile(url string, filePath, sha string, downloadStatus func(string,
 		return fmt.Errorf("failed to check file %q existence: %v", filePath, err)
 	}
 
-	log.Info().Msgf("Downloading %q", url)
+	if isObjectURI(url) {
+		return downloadObject(url, filePath, sha, secret, downloadStatus)
+	}
 
-	// Download file
-	resp, err := http.Get(url)
-	if err != nil {
-		return fmt.Errorf("failed to download file %q: %v", filePath, err)
+	if strings.HasPrefix(url, OCIPrefix) {
+		blob, err := resolveOCIBlob(url, secret)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %q: %w", url, err)
+		}
+		// the layer is verified against its digest
+		if sha != "" && sha != blob.SHA {
+			return fmt.Errorf("SHA mismatch for file %q ( digest: %s != metadata: %s )", filePath, blob.SHA, sha)
+		}
+		log.Debug().Msgf("Resolved %q to the layer %s (%s)", url, blob.SHA, blob.Name)
+		url, sha = blob.URL, blob.SHA
+		setAuthorization(blob.URL, blob.Authorization)
+		defer setAuthorization(blob.URL, "")
+	} else if secret != nil && secret.Authorization() != "" {
+		setAuthorization(url, secret.Authorization())
+		defer setAuthorization(url, "")
 	}
-	defer resp.Body.Close()
+
+	log.Info().Msgf("Downloading %q", url)
 
 	// Create parent directory
 	err = os.MkdirAll(filepath.Dir(filePath), 0755)
@@ -188,46 +220,92 @@ func DownloadFile(url string, filePath, sha string, downloadStatus func(string,
 	// save partial download to dedicated file
 	tmpFilePath := filePath + ".partial"
 
-	// remove tmp file
-	err = removePartialFile(tmpFilePath)
+	// the first byte is requested to know if the server supports range requests, to resume the download
+	resp, err := GetWithHeaders(url, map[string]string{"Range": "bytes=0-0"})
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to download file %q: %v", filePath, err)
 	}
+	defer resp.Body.Close()
 
-	// Create and write file content
-	outFile, err := os.Create(tmpFilePath)
-	if err != nil {
-		return fmt.Errorf("failed to create file %q: %v", tmpFilePath, err)
-	}
-	defer outFile.Close()
+	var calculatedSHA string
+	if size := contentRangeSize(resp.Header.Get("Content-Range")); resp.StatusCode == http.StatusPartialContent && size > 0 {
+		resp.Body.Close()
+		if err := downloadRanges(url, tmpFilePath, size, resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"), downloadStatus); err != nil {
+			return fmt.Errorf("failed to download file %q: %v", filePath, err)
+		}
+		if sha != "" {
+			calculatedSHA, err = CalculateSHA(tmpFilePath)
+			if err != nil {
+				return fmt.Errorf("failed to calculate SHA for file %q: %v", tmpFilePath, err)
+			}
+		}
+	} else {
+		if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+			// e.g. empty files
+			resp.Body.Close()
+			resp, err = Get(url)
+			if err != nil {
+				return fmt.Errorf("failed to download file %q: %v", filePath, err)
+			}
+			defer resp.Body.Close()
+		}
+		if (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) && strings.HasPrefix(url, huggingFaceEndpoint+"/") {
+			return fmt.Errorf("failed to download file %q: %s returned status %d, set HF_TOKEN to a token with access to the repository", filePath, url, resp.StatusCode)
+		}
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("failed to download file %q: %s returned status %d", filePath, url, resp.StatusCode)
+		}
 
-	progress := &progressWriter{
-		fileName:       tmpFilePath,
-		total:          resp.ContentLength,
-		hash:           sha256.New(),
-		downloadStatus: downloadStatus,
-	}
-	_, err = io.Copy(io.MultiWriter(outFile, progress), resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to write file %q: %v", filePath, err)
-	}
+		// the server doesn't support range requests, the download starts over
+		err = removePartialFile(tmpFilePath)
+		if err != nil {
+			return err
+		}
+		os.Remove(tmpFilePath + ".json")
 
-	err = os.Rename(tmpFilePath, filePath)
-	if err != nil {
-		return fmt.Errorf("failed to rename temporary file %s -> %s: %v", tmpFilePath, filePath, err)
+		// Create and write file content
+		outFile, err := os.Create(tmpFilePath)
+		if err != nil {
+			return fmt.Errorf("failed to create file %q: %v", tmpFilePath, err)
+		}
+		defer outFile.Close()
+
+		progress := &progressWriter{
+			fileName:       tmpFilePath,
+			total:          resp.ContentLength,
+			hash:           sha256.New(),
+			downloadStatus: downloadStatus,
+		}
+		_, err = io.Copy(io.MultiWriter(outFile, progress), resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to write file %q: %v", filePath, err)
+		}
+		calculatedSHA = fmt.Sprintf("%x", progress.hash.Sum(nil))
 	}
 
+	return finishDownload(tmpFilePath, filePath, sha, calculatedSHA)
+}
+
+// finishDownload verifies the SHA of the file downloaded to tmpFilePath, moves it to filePath and extracts it if it is
+// an archive
+func finishDownload(tmpFilePath, filePath, sha, calculatedSHA string) error {
 	if sha != "" {
 		// Verify SHA
-		calculatedSHA := fmt.Sprintf("%x", progress.hash.Sum(nil))
 		if calculatedSHA != sha {
 			log.Debug().Msgf("SHA mismatch for file %q ( calculated: %s != metadata: %s )", filePath, calculatedSHA, sha)
+			// the download starts over the next time
+			removePartialFile(tmpFilePath)
 			return fmt.Errorf("SHA mismatch for file %q ( calculated: %s != metadata: %s )", filePath, calculatedSHA, sha)
 		}
 	} else {
 		log.Debug().Msgf("SHA missing for %q. Skipping validation", filePath)
 	}
 
+	err := os.Rename(tmpFilePath, filePath)
+	if err != nil {
+		return fmt.Errorf("failed to rename temporary file %s -> %s: %v", tmpFilePath, filePath, err)
+	}
+
 	log.Info().Msgf("File %q downloaded and verified", filePath)
 	if utils.IsArchive(filePath) {
 		basePath := filepath.Dir(filePath)
@@ -246,7 +324,7 @@ func DownloadFile(url string, filePath, sha string, downloadStatus func(string,
 func GetBase64Image(s string) (string, error) {
 	if strings.HasPrefix(s, "http") {
 		// download the image
-		resp, err := http.Get(s)
+		resp, err := Get(s)
 		if err != nil {
 			return "", err
 		}
@@ -285,7 +363,8 @@ func formatBytes(bytes int64) string {
 	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
-func calculateSHA(filePath string) (string, error) {
+// CalculateSHA returns the hex encoded SHA256 of the file
+func CalculateSHA(filePath string) (string, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return "", err
@@ -33,4 +33,13 @@ var _ = Describe("Gallery API tests", func() {
 			).ToNot(HaveOccurred())
 		})
 	})
+	Context("HuggingFace", func() {
+		It("converts the repository files to URLs", func() {
+			Expect(ConvertURL("huggingface://TheBloke/phi-2-GGUF/phi-2.Q8_0.gguf")).To(Equal("https://huggingface.co/TheBloke/phi-2-GGUF/resolve/main/phi-2.Q8_0.gguf"))
+			Expect(ConvertURL("huggingface://TheBloke/phi-2-GGUF/phi-2.Q8_0.gguf@v1.0")).To(Equal("https://huggingface.co/TheBloke/phi-2-GGUF/resolve/v1.0/phi-2.Q8_0.gguf"))
+			Expect(ConvertURL("huggingface://org/repo@dev/sub/dir/model.gguf")).To(Equal("https://huggingface.co/org/repo/resolve/dev/sub/dir/model.gguf"))
+			Expect(ConvertURL("huggingface://org/repo/model.gguf@refs/pr/1")).To(Equal("https://huggingface.co/org/repo/resolve/refs%2Fpr%2F1/model.gguf"))
+			Expect(ConvertURL("huggingface://org/repo/sub/dir/model.gguf@abc123")).To(Equal("https://huggingface.co/org/repo/resolve/abc123/sub/dir/model.gguf"))
+		})
+	})
 })
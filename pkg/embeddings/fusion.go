@@ -0,0 +1,66 @@
+// Package embeddings post-processes the embeddings computed by the backends: it pools the embeddings of the tokens,
+// normalizes them, and fuses the embeddings of the same input computed by several models.
+package embeddings
+
+import "fmt"
+
+// Fusion methods
+const (
+	// Concat concatenates the normalized vectors, each multiplied by its weight
+	Concat = "concat"
+	// Weighted averages the normalized vectors with their weights, they must have the same dimensions
+	Weighted = "weighted"
+	// None returns the vectors of all the models without fusing them
+	None = "none"
+)
+
+// Normalize returns the vector scaled to a unit length
+func Normalize(v []float32) []float32 {
+	res := make([]float32, len(v))
+	copy(res, v)
+	normalizeInPlace(res)
+	return res
+}
+
+// Fuse fuses the vectors of the models with the given method. The weights default to 1 when nil.
+func Fuse(method string, vectors [][]float32, weights []float64) ([]float32, error) {
+	if len(vectors) == 0 {
+		return nil, fmt.Errorf("no embeddings to fuse")
+	}
+	weight := func(i int) float64 {
+		if i < len(weights) && weights[i] != 0 {
+			return weights[i]
+		}
+		return 1
+	}
+
+	switch method {
+	case Concat, "":
+		res := []float32{}
+		for i, v := range vectors {
+			for _, x := range Normalize(v) {
+				res = append(res, float32(float64(x)*weight(i)))
+			}
+		}
+		return res, nil
+	case Weighted:
+		res := make([]float64, len(vectors[0]))
+		total := 0.0
+		for i, v := range vectors {
+			if len(v) != len(res) {
+				return nil, fmt.Errorf("the weighted fusion requires embeddings of the same dimensions, got %d and %d", len(res), len(v))
+			}
+			for j, x := range Normalize(v) {
+				res[j] += float64(x) * weight(i)
+			}
+			total += weight(i)
+		}
+		fused := make([]float32, len(res))
+		for j, x := range res {
+			fused[j] = float32(x / total)
+		}
+		return fused, nil
+	default:
+		return nil, fmt.Errorf("unknown fusion %q, it must be %s, %s or %s", method, Concat, Weighted, None)
+	}
+}
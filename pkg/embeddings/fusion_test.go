@@ -0,0 +1,39 @@
+package embeddings_test
+
+import (
+	. "github.com/go-skynet/LocalAI/pkg/embeddings"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fusion", func() {
+	It("normalizes", func() {
+		Expect(Normalize([]float32{3, 4})).To(Equal([]float32{0.6, 0.8}))
+		Expect(Normalize([]float32{0, 0})).To(Equal([]float32{0, 0}))
+	})
+
+	It("concatenates the weighted vectors", func() {
+		res, err := Fuse(Concat, [][]float32{{3, 4}, {2}}, []float64{1, 0.5})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal([]float32{0.6, 0.8, 0.5}))
+	})
+
+	It("averages the weighted vectors", func() {
+		res, err := Fuse(Weighted, [][]float32{{1, 0}, {0, 2}}, []float64{3, 1})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal([]float32{0.75, 0.25}))
+
+		res, err = Fuse(Weighted, [][]float32{{1, 0}, {0, 2}}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal([]float32{0.5, 0.5}))
+	})
+
+	It("fails with invalid inputs", func() {
+		_, err := Fuse(Weighted, [][]float32{{1, 0}, {1}}, nil)
+		Expect(err).To(HaveOccurred())
+		_, err = Fuse("unknown", [][]float32{{1}}, nil)
+		Expect(err).To(HaveOccurred())
+		_, err = Fuse(Concat, nil, nil)
+		Expect(err).To(HaveOccurred())
+	})
+})
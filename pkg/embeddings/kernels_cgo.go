@@ -0,0 +1,82 @@
+//go:build cgo
+
+package embeddings
+
+// The loops are written so that the compiler vectorizes them (SSE/AVX on x86, NEON on ARM),
+// and the vectors are processed by a single call to amortize the cost of the cgo calls.
+
+/*
+#cgo CFLAGS: -O3 -fopenmp-simd
+#cgo LDFLAGS: -lm
+#include <math.h>
+#include <stddef.h>
+
+static float sum_squares(const float *v, size_t n) {
+	float sum = 0;
+	#pragma omp simd reduction(+:sum)
+	for (size_t i = 0; i < n; i++) {
+		sum += v[i] * v[i];
+	}
+	return sum;
+}
+
+static void normalize(float *v, size_t n) {
+	float sum = sum_squares(v, n);
+	if (sum == 0) {
+		return;
+	}
+	float norm = sqrtf(sum);
+	#pragma omp simd
+	for (size_t i = 0; i < n; i++) {
+		v[i] /= norm;
+	}
+}
+
+static void mean_pool(const float *restrict data, size_t tokens, size_t dim, float *restrict out) {
+	for (size_t j = 0; j < dim; j++) {
+		out[j] = 0;
+	}
+	for (size_t t = 0; t < tokens; t++) {
+		const float *restrict row = data + t * dim;
+		#pragma omp simd
+		for (size_t j = 0; j < dim; j++) {
+			out[j] += row[j];
+		}
+	}
+	#pragma omp simd
+	for (size_t j = 0; j < dim; j++) {
+		out[j] /= (float)tokens;
+	}
+}
+
+static void max_pool(const float *restrict data, size_t tokens, size_t dim, float *restrict out) {
+	for (size_t j = 0; j < dim; j++) {
+		out[j] = data[j];
+	}
+	for (size_t t = 1; t < tokens; t++) {
+		const float *restrict row = data + t * dim;
+		#pragma omp simd
+		for (size_t j = 0; j < dim; j++) {
+			out[j] = row[j] > out[j] ? row[j] : out[j];
+		}
+	}
+}
+*/
+import "C"
+
+import "unsafe"
+
+func normalizeInPlace(v []float32) {
+	if len(v) == 0 {
+		return
+	}
+	C.normalize((*C.float)(unsafe.Pointer(&v[0])), C.size_t(len(v)))
+}
+
+func meanPool(data []float32, tokens, dim int, out []float32) {
+	C.mean_pool((*C.float)(unsafe.Pointer(&data[0])), C.size_t(tokens), C.size_t(dim), (*C.float)(unsafe.Pointer(&out[0])))
+}
+
+func maxPool(data []float32, tokens, dim int, out []float32) {
+	C.max_pool((*C.float)(unsafe.Pointer(&data[0])), C.size_t(tokens), C.size_t(dim), (*C.float)(unsafe.Pointer(&out[0])))
+}
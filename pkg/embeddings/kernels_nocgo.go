@@ -0,0 +1,55 @@
+//go:build !cgo
+
+package embeddings
+
+import "math"
+
+// The loops are unrolled with independent accumulators, for the builds without cgo where the vectorized kernels are not available
+
+func normalizeInPlace(v []float32) {
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+4 <= len(v); i += 4 {
+		x := v[i : i+4 : i+4]
+		s0 += x[0] * x[0]
+		s1 += x[1] * x[1]
+		s2 += x[2] * x[2]
+		s3 += x[3] * x[3]
+	}
+	for ; i < len(v); i++ {
+		s0 += v[i] * v[i]
+	}
+	sum := s0 + s1 + s2 + s3
+	if sum == 0 {
+		return
+	}
+	norm := float32(math.Sqrt(float64(sum)))
+	for i := range v {
+		v[i] /= norm
+	}
+}
+
+func meanPool(data []float32, tokens, dim int, out []float32) {
+	out = out[:dim]
+	clear(out)
+	for t := 0; t < tokens; t++ {
+		row := data[t*dim : (t+1)*dim]
+		for j, x := range row {
+			out[j] += x
+		}
+	}
+	for j := range out {
+		out[j] /= float32(tokens)
+	}
+}
+
+func maxPool(data []float32, tokens, dim int, out []float32) {
+	out = out[:dim]
+	copy(out, data[:dim])
+	for t := 1; t < tokens; t++ {
+		row := data[t*dim : (t+1)*dim]
+		for j, x := range row {
+			out[j] = max(out[j], x)
+		}
+	}
+}
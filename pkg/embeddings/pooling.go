@@ -0,0 +1,109 @@
+package embeddings
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// Pooling methods of the embeddings of the tokens
+const (
+	// Mean averages the embeddings of the tokens
+	Mean = "mean"
+	// CLS takes the embedding of the first token
+	CLS = "cls"
+	// Last takes the embedding of the last token
+	Last = "last"
+	// Max takes the maximum of each dimension over the tokens
+	Max = "max"
+)
+
+// Output is the embedding of an input computed by a backend: the embedding of the input, or the embeddings
+// of its Tokens one after the other, when the backend was asked not to pool them
+type Output struct {
+	Data   []float32
+	Tokens int
+}
+
+// Pool pools the embeddings of the tokens of the output into the embedding of the input.
+// The outputs which are pooled already are returned as is.
+func Pool(method string, o Output) ([]float32, error) {
+	if o.Tokens <= 0 {
+		return o.Data, nil
+	}
+	if len(o.Data)%o.Tokens != 0 {
+		return nil, fmt.Errorf("the backend returned %d values for %d tokens", len(o.Data), o.Tokens)
+	}
+	dim := len(o.Data) / o.Tokens
+	if dim == 0 {
+		return []float32{}, nil
+	}
+
+	res := make([]float32, dim)
+	switch method {
+	case Mean, "":
+		meanPool(o.Data, o.Tokens, dim, res)
+	case CLS:
+		copy(res, o.Data[:dim])
+	case Last:
+		copy(res, o.Data[len(o.Data)-dim:])
+	case Max:
+		maxPool(o.Data, o.Tokens, dim, res)
+	default:
+		return nil, fmt.Errorf("unknown pooling %q, it must be %s, %s, %s or %s", method, Mean, CLS, Last, Max)
+	}
+	return res, nil
+}
+
+// Process pools the outputs, and normalizes them to a unit length if normalize is set.
+// The outputs of a batch are processed in parallel on all the cores.
+func Process(outputs []Output, pooling string, normalize bool) ([][]float32, error) {
+	res := make([][]float32, len(outputs))
+	errs := make([]error, len(outputs))
+	process := func(i int) {
+		v, err := Pool(pooling, outputs[i])
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		if normalize {
+			// the pooled outputs are new vectors, the others are copied not to change the output of the backend
+			if outputs[i].Tokens <= 0 {
+				v = append([]float32(nil), v...)
+			}
+			normalizeInPlace(v)
+		}
+		res[i] = v
+	}
+
+	workers := min(runtime.GOMAXPROCS(0), len(outputs))
+	if workers <= 1 {
+		for i := range outputs {
+			process(i)
+		}
+	} else {
+		next := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range next {
+					process(i)
+				}
+			}()
+		}
+		for i := range outputs {
+			next <- i
+		}
+		close(next)
+		wg.Wait()
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,70 @@
+package embeddings_test
+
+import (
+	"fmt"
+
+	. "github.com/go-skynet/LocalAI/pkg/embeddings"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Pooling", func() {
+	tokens := Output{Data: []float32{1, 2, 3, 3, 6, 9, 2, 1, 0}, Tokens: 3}
+
+	It("pools the embeddings of the tokens", func() {
+		res, err := Pool(Mean, tokens)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal([]float32{2, 3, 4}))
+
+		res, err = Pool(CLS, tokens)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal([]float32{1, 2, 3}))
+
+		res, err = Pool(Last, tokens)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal([]float32{2, 1, 0}))
+
+		res, err = Pool(Max, tokens)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal([]float32{3, 6, 9}))
+	})
+
+	It("returns the pooled embeddings as is", func() {
+		res, err := Pool(Mean, Output{Data: []float32{1, 2}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal([]float32{1, 2}))
+	})
+
+	It("fails with invalid outputs", func() {
+		_, err := Pool(Mean, Output{Data: []float32{1, 2, 3}, Tokens: 2})
+		Expect(err).To(HaveOccurred())
+		_, err = Pool("unknown", tokens)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("processes batches in parallel", func() {
+		outputs := []Output{}
+		for i := 0; i < 100; i++ {
+			// vectors longer than the vector registers, with a remainder
+			data := make([]float32, 2*37)
+			data[i%37] = 3
+			data[37+i%37] = 5
+			outputs = append(outputs, Output{Data: data, Tokens: 2})
+		}
+		outputs = append(outputs, Output{Data: []float32{3, 4}})
+
+		res, err := Process(outputs, Mean, true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(HaveLen(101))
+		for i, v := range res[:100] {
+			Expect(v).To(HaveLen(37), fmt.Sprint(i))
+			Expect(v[i%37]).To(BeNumerically("~", 1, 1e-6), fmt.Sprint(i))
+		}
+		Expect(res[100]).To(Equal([]float32{0.6, 0.8}))
+		// the outputs of the backend are not changed
+		Expect(outputs[100].Data).To(Equal([]float32{3, 4}))
+
+		_, err = Process([]Output{tokens, {Data: []float32{1}, Tokens: 2}}, Mean, false)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,185 @@
+package gallery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/go-skynet/LocalAI/pkg/downloader"
+	"github.com/go-skynet/LocalAI/pkg/secrets"
+	"github.com/rs/zerolog/log"
+)
+
+// Magic numbers of the model formats used by llama.cpp before GGUF was introduced.
+// See https://github.com/ggerganov/llama.cpp/blob/master/convert-llama-ggml-to-gguf.py
+var legacyGGMLMagics = [][]byte{
+	[]byte("lmgg"), // ggml (unversioned)
+	[]byte("fmgg"), // ggmf
+	[]byte("tjgg"), // ggjt
+}
+
+var ggufMagic = []byte("GGUF")
+
+// conversionSuffix is the suffix of the file recording the checksums of a converted file, which no longer matches
+// the checksum of the gallery
+const conversionSuffix = ".gguf.json"
+
+type conversion struct {
+	// Source is the SHA256 of the legacy ggml file, as set in the gallery
+	Source string `json:"source"`
+	// Converted is the SHA256 of the GGUF file
+	Converted string `json:"converted"`
+}
+
+type InstallOptions struct {
+	convertLegacyGGML bool
+	keepLegacyGGML    bool
+	converterPath     string
+	pythonPath        string
+	secrets           *secrets.Store
+}
+
+type InstallOption func(*InstallOptions)
+
+// WithLegacyGGMLConversion enables the automatic conversion of legacy ggml model files to GGUF
+// using the converter script found at converterPath. If keepOriginal is set, the original file
+// is kept next to the converted one with a ".ggml" suffix.
+func WithLegacyGGMLConversion(converterPath string, keepOriginal bool) InstallOption {
+	return func(o *InstallOptions) {
+		o.convertLegacyGGML = true
+		o.converterPath = converterPath
+		o.keepLegacyGGML = keepOriginal
+	}
+}
+
+func WithPythonPath(python string) InstallOption {
+	return func(o *InstallOptions) {
+		o.pythonPath = python
+	}
+}
+
+// WithSecrets authenticates the downloads of the files with the secrets they reference by name
+func WithSecrets(s *secrets.Store) InstallOption {
+	return func(o *InstallOptions) {
+		o.secrets = s
+	}
+}
+
+func NewInstallOptions(opts ...InstallOption) *InstallOptions {
+	o := &InstallOptions{
+		pythonPath: "python3",
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// IsLegacyGGML returns true if the file at path is a model in one of the pre-GGUF ggml formats
+func IsLegacyGGML(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	magic := make([]byte, 4)
+	if n, err := f.Read(magic); err != nil || n < len(magic) {
+		// too small to be a model, nothing to convert
+		return false, nil
+	}
+
+	if bytes.Equal(magic, ggufMagic) {
+		return false, nil
+	}
+
+	for _, m := range legacyGGMLMagics {
+		if bytes.Equal(magic, m) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isConverted returns true if the file at filePath was converted to GGUF from the file with the SHA256 sha, and was
+// not changed since
+func isConverted(filePath, sha string) bool {
+	if sha == "" {
+		return false
+	}
+	dat, err := os.ReadFile(filePath + conversionSuffix)
+	if err != nil {
+		return false
+	}
+	c := conversion{}
+	if err := json.Unmarshal(dat, &c); err != nil || c.Source != sha {
+		return false
+	}
+	converted, err := downloader.CalculateSHA(filePath)
+	return err == nil && converted == c.Converted
+}
+
+// recordConversion records the checksums of the file converted from the file with the SHA256 sha
+func recordConversion(filePath, sha string) error {
+	converted, err := downloader.CalculateSHA(filePath)
+	if err != nil {
+		return err
+	}
+	dat, err := json.Marshal(conversion{Source: sha, Converted: converted})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath+conversionSuffix, dat, 0644)
+}
+
+// convertLegacyGGML converts the legacy ggml file at filePath, with the SHA256 sha, to GGUF in place, and records the
+// checksums of the conversion. It returns true if the file was converted.
+func convertLegacyGGML(filePath, sha string, o *InstallOptions) (bool, error) {
+	legacy, err := IsLegacyGGML(filePath)
+	if err != nil || !legacy {
+		return false, err
+	}
+
+	log.Warn().Msgf("%q is a legacy ggml model: support for this format is deprecated and might be removed in future releases", filepath.Base(filePath))
+
+	if !o.convertLegacyGGML {
+		log.Warn().Msgf("Automatic conversion to GGUF is disabled, the model will be loaded with the llama-ggml backend")
+		return false, nil
+	}
+
+	if _, err := os.Stat(o.converterPath); err != nil {
+		log.Warn().Msgf("GGUF converter not found at %q, skipping conversion of %q", o.converterPath, filePath)
+		return false, nil
+	}
+
+	log.Info().Msgf("Converting %q to GGUF", filePath)
+
+	convertedPath := filePath + ".gguf.partial"
+	cmd := exec.Command(o.pythonPath, o.converterPath, "--input", filePath, "--output", convertedPath)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		os.Remove(convertedPath)
+		return false, fmt.Errorf("failed converting %q to GGUF: %w (output: %s)", filePath, err, string(out))
+	}
+	log.Debug().Msgf("GGUF converter output: %s", string(out))
+
+	if o.keepLegacyGGML {
+		if err := os.Rename(filePath, filePath+".ggml"); err != nil {
+			return false, fmt.Errorf("failed to keep the original file %q: %w", filePath, err)
+		}
+		log.Info().Msgf("Original ggml file kept at %q", filePath+".ggml")
+	}
+
+	if err := os.Rename(convertedPath, filePath); err != nil {
+		return false, fmt.Errorf("failed to rename converted file %s -> %s: %w", convertedPath, filePath, err)
+	}
+	if err := recordConversion(filePath, sha); err != nil {
+		return false, fmt.Errorf("failed recording the conversion of %q: %w", filePath, err)
+	}
+
+	log.Info().Msgf("%q converted to GGUF", filePath)
+	return true, nil
+}
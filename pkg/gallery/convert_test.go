@@ -0,0 +1,83 @@
+package gallery_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	. "github.com/go-skynet/LocalAI/pkg/gallery"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+)
+
+var _ = Describe("Legacy ggml detection", func() {
+	It("detects legacy ggml files by their magic", func() {
+		tempdir, err := os.MkdirTemp("", "test")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tempdir)
+
+		for content, expected := range map[string]bool{
+			"tjgg\x03\x00\x00\x00": true,
+			"fmgg\x01\x00\x00\x00": true,
+			"lmgg\x00\x00\x00\x00": true,
+			"GGUF\x03\x00\x00\x00": false,
+			"not a model":          false,
+			"tj":                   false,
+		} {
+			p := filepath.Join(tempdir, "model")
+			Expect(os.WriteFile(p, []byte(content), 0600)).To(Succeed())
+			legacy, err := IsLegacyGGML(p)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(legacy).To(Equal(expected), content)
+		}
+	})
+})
+
+var _ = Describe("Legacy ggml conversion", func() {
+	It("doesn't download nor convert again the converted files when reinstalling", func() {
+		tempdir := GinkgoT().TempDir()
+		legacy := []byte("tjgg\x03\x00\x00\x00legacy weights")
+
+		var downloads atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			downloads.Add(1)
+			w.Write(legacy)
+		}))
+		defer srv.Close()
+
+		converter := filepath.Join(tempdir, "convert.sh")
+		Expect(os.WriteFile(converter, []byte("printf 'GGUF\\003\\000\\000\\000' > \"$4\"\n"), 0755)).To(Succeed())
+		opts := []InstallOption{WithLegacyGGMLConversion(converter, false), WithPythonPath("sh")}
+
+		models := filepath.Join(tempdir, "models")
+		c := &Config{
+			Name:       "legacy",
+			ConfigFile: "backend: llama-ggml\nparameters:\n  model: legacy.bin\n",
+			Files:      []File{{Filename: "legacy.bin", URI: srv.URL + "/legacy.bin", SHA256: fmt.Sprintf("%x", sha256.Sum256(legacy))}},
+		}
+		for i := 0; i < 2; i++ {
+			Expect(InstallModel(models, "", c, nil, func(string, string, string, float64) {}, opts...)).To(Succeed())
+
+			converted, err := IsLegacyGGML(filepath.Join(models, "legacy.bin"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(converted).To(BeFalse())
+
+			content := map[string]interface{}{}
+			dat, err := os.ReadFile(filepath.Join(models, "legacy.yaml"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(yaml.Unmarshal(dat, content)).To(Succeed())
+			Expect(content["backend"]).To(Equal("llama-cpp"))
+		}
+		Expect(downloads.Load()).To(Equal(int32(1)))
+
+		// a changed file is downloaded and converted again
+		Expect(os.WriteFile(filepath.Join(models, "legacy.bin"), []byte("GGUF\x03\x00\x00\x00changed"), 0644)).To(Succeed())
+		Expect(InstallModel(models, "", c, nil, func(string, string, string, float64) {}, opts...)).To(Succeed())
+		Expect(downloads.Load()).To(Equal(int32(2)))
+	})
+})
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/go-skynet/LocalAI/pkg/downloader"
 	"github.com/imdario/mergo"
@@ -18,7 +19,7 @@ type Gallery struct {
 }
 
 // Installs a model from the gallery (galleryname@modelname)
-func InstallModelFromGallery(galleries []Gallery, name string, basePath string, req GalleryModel, downloadStatus func(string, string, string, float64)) error {
+func InstallModelFromGallery(galleries []Gallery, name string, basePath string, req GalleryModel, downloadStatus func(string, string, string, float64), opts ...InstallOption) error {
 	applyModel := func(model *GalleryModel) error {
 		name = strings.ReplaceAll(name, string(os.PathSeparator), "__")
 
@@ -62,11 +63,18 @@ func InstallModelFromGallery(galleries []Gallery, name string, basePath string,
 			return err
 		}
 
-		if err := InstallModel(basePath, installName, &config, model.Overrides, downloadStatus); err != nil {
+		if err := InstallModel(basePath, installName, &config, model.Overrides, downloadStatus, opts...); err != nil {
 			return err
 		}
 
-		return nil
+		return recordInstalledModel(basePath, InstalledModel{
+			Name:        installName,
+			Gallery:     model.Gallery.Name,
+			Model:       model.Name,
+			Version:     model.Version,
+			InstalledAt: time.Now(),
+			Request:     req,
+		})
 	}
 
 	models, err := AvailableGalleryModels(galleries, basePath)
@@ -99,7 +107,7 @@ func FindGallery(models []*GalleryModel, name string) (*GalleryModel, error) {
 }
 
 // InstallModelFromGalleryByName loads a model from the gallery by specifying only the name (first match wins)
-func InstallModelFromGalleryByName(galleries []Gallery, name string, basePath string, req GalleryModel, downloadStatus func(string, string, string, float64)) error {
+func InstallModelFromGalleryByName(galleries []Gallery, name string, basePath string, req GalleryModel, downloadStatus func(string, string, string, float64), opts ...InstallOption) error {
 	models, err := AvailableGalleryModels(galleries, basePath)
 	if err != nil {
 		return err
@@ -117,7 +125,7 @@ func InstallModelFromGalleryByName(galleries []Gallery, name string, basePath st
 		return fmt.Errorf("no model found with name %q", name)
 	}
 
-	return InstallModelFromGallery(galleries, fmt.Sprintf("%s@%s", model.Gallery.Name, model.Name), basePath, req, downloadStatus)
+	return InstallModelFromGallery(galleries, fmt.Sprintf("%s@%s", model.Gallery.Name, model.Name), basePath, req, downloadStatus, opts...)
 }
 
 // List available models
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 
 	"github.com/go-skynet/LocalAI/pkg/downloader"
+	"github.com/go-skynet/LocalAI/pkg/secrets"
 	"github.com/go-skynet/LocalAI/pkg/utils"
 	"github.com/imdario/mergo"
 	"github.com/rs/zerolog/log"
@@ -54,6 +55,8 @@ type File struct {
 	Filename string `yaml:"filename" json:"filename"`
 	SHA256   string `yaml:"sha256" json:"sha256"`
 	URI      string `yaml:"uri" json:"uri"`
+	// Secret is the name of the secret authenticating the download
+	Secret string `yaml:"secret" json:"secret,omitempty"`
 }
 
 type PromptTemplate struct {
@@ -90,7 +93,9 @@ func ReadConfigFile(filePath string) (*Config, error) {
 	return &config, nil
 }
 
-func InstallModel(basePath, nameOverride string, config *Config, configOverrides map[string]interface{}, downloadStatus func(string, string, string, float64)) error {
+func InstallModel(basePath, nameOverride string, config *Config, configOverrides map[string]interface{}, downloadStatus func(string, string, string, float64), opts ...InstallOption) error {
+	o := NewInstallOptions(opts...)
+
 	// Create base path if it doesn't exist
 	err := os.MkdirAll(basePath, 0755)
 	if err != nil {
@@ -101,6 +106,8 @@ func InstallModel(basePath, nameOverride string, config *Config, configOverrides
 		log.Debug().Msgf("Config overrides %+v", configOverrides)
 	}
 
+	converted := false
+
 	// Download files and verify their SHA
 	for _, file := range config.Files {
 		log.Debug().Msgf("Checking %q exists and matches SHA", file.Filename)
@@ -111,9 +118,30 @@ func InstallModel(basePath, nameOverride string, config *Config, configOverrides
 		// Create file path
 		filePath := filepath.Join(basePath, file.Filename)
 
-		if err := downloader.DownloadFile(file.URI, filePath, file.SHA256, downloadStatus); err != nil {
+		var secret *secrets.Secret
+		if file.Secret != "" {
+			s, err := o.secrets.ForURL(file.Secret, downloader.ConvertURL(file.URI))
+			if err != nil {
+				return fmt.Errorf("file %s: %w", file.Filename, err)
+			}
+			secret = &s
+		}
+		// the converted files don't match the SHA of the gallery anymore, they are checked against the one recorded
+		// when converting them
+		if isConverted(filePath, file.SHA256) {
+			log.Debug().Msgf("File %q was already converted to GGUF. Skipping download", filePath)
+			converted = true
+			continue
+		}
+		if err := downloader.DownloadFileWithSecret(file.URI, filePath, file.SHA256, secret, downloadStatus); err != nil {
+			return err
+		}
+
+		c, err := convertLegacyGGML(filePath, file.SHA256, o)
+		if err != nil {
 			return err
 		}
+		converted = converted || c
 	}
 
 	// Write prompt template contents to separate files
@@ -164,6 +192,12 @@ func InstallModel(basePath, nameOverride string, config *Config, configOverrides
 			return err
 		}
 
+		// The converted model can't be loaded anymore by the legacy backend
+		if backend, ok := configMap["backend"].(string); converted && ok && backend == "llama-ggml" {
+			log.Info().Msgf("Switching backend of %q from llama-ggml to llama-cpp after GGUF conversion", name)
+			configMap["backend"] = "llama-cpp"
+		}
+
 		// Write updated config file
 		updatedConfigYAML, err := yaml.Marshal(configMap)
 		if err != nil {
@@ -0,0 +1,111 @@
+package gallery
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// QuantizationTypes are the quantization types of the llama.cpp quantize tool
+var QuantizationTypes = []string{
+	"Q4_0", "Q4_1", "Q5_0", "Q5_1", "Q8_0",
+	"Q2_K", "Q3_K_S", "Q3_K_M", "Q3_K_L", "Q4_K_S", "Q4_K_M", "Q5_K_S", "Q5_K_M", "Q6_K",
+	"F16", "F32",
+}
+
+// the quantize tool reports the tensors it processed as "[  12/ 291]"
+var quantizeProgress = regexp.MustCompile(`^\[\s*(\d+)/\s*(\d+)\]`)
+
+// IsGGUF returns true if the file at path is a GGUF model
+func IsGGUF(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	magic := make([]byte, len(ggufMagic))
+	if _, err := io.ReadFull(f, magic); err != nil {
+		return false, nil
+	}
+	return bytes.Equal(magic, ggufMagic), nil
+}
+
+// QuantizeModel writes to outputPath the GGUF model at inputPath quantized to qtype, which can already be quantized,
+// with the llama.cpp quantize tool at toolPath. progress is called with the percentage of the tensors processed.
+func QuantizeModel(toolPath, inputPath, outputPath, qtype string, threads int, progress func(float64)) error {
+	qtype = strings.ToUpper(qtype)
+	if !slices.Contains(QuantizationTypes, qtype) {
+		return fmt.Errorf("unknown quantization type %q, expected one of %s", qtype, strings.Join(QuantizationTypes, ", "))
+	}
+	gguf, err := IsGGUF(inputPath)
+	if err != nil {
+		return err
+	}
+	if !gguf {
+		return fmt.Errorf("%q is not a GGUF model", inputPath)
+	}
+	if _, err := os.Stat(outputPath); err == nil {
+		return fmt.Errorf("%q already exists", outputPath)
+	}
+	if _, err := os.Stat(toolPath); err != nil {
+		return fmt.Errorf("quantize tool not found at %q: %w", toolPath, err)
+	}
+
+	log.Info().Msgf("Quantizing %q to %s", inputPath, qtype)
+
+	partialPath := outputPath + ".partial"
+	args := []string{"--allow-requantize", inputPath, partialPath, qtype}
+	if threads > 0 {
+		args = append(args, strconv.Itoa(threads))
+	}
+	cmd := exec.Command(toolPath, args...)
+	output, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	// llama.cpp logs to stderr
+	cmd.Stderr = cmd.Stdout
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	// the last lines of the output, which explain the failures
+	var tail []string
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if tail = append(tail, line); len(tail) > 20 {
+			tail = tail[1:]
+		}
+		m := quantizeProgress.FindStringSubmatch(line)
+		if m == nil || progress == nil {
+			continue
+		}
+		done, _ := strconv.Atoi(m[1])
+		total, _ := strconv.Atoi(m[2])
+		if total > 0 {
+			progress(float64(done) / float64(total) * 100)
+		}
+	}
+
+	if err := cmd.Wait(); err != nil {
+		os.Remove(partialPath)
+		return fmt.Errorf("failed quantizing %q to %s: %w (output: %s)", inputPath, qtype, err, strings.Join(tail, "\n"))
+	}
+	if err := os.Rename(partialPath, outputPath); err != nil {
+		return fmt.Errorf("failed to rename quantized file %s -> %s: %w", partialPath, outputPath, err)
+	}
+
+	log.Info().Msgf("%q quantized to %s in %q", inputPath, qtype, outputPath)
+	return nil
+}
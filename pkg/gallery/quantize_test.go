@@ -0,0 +1,57 @@
+package gallery_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/go-skynet/LocalAI/pkg/gallery"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GGUF quantization", func() {
+	var tempdir, tool, model string
+
+	BeforeEach(func() {
+		var err error
+		tempdir, err = os.MkdirTemp("", "test")
+		Expect(err).ToNot(HaveOccurred())
+
+		// a fake quantize tool, copying the model and logging its progress like llama.cpp
+		tool = filepath.Join(tempdir, "quantize")
+		Expect(os.WriteFile(tool, []byte(`#!/bin/sh
+[ "$1" = "--allow-requantize" ] || exit 1
+echo "[   1/   2] token_embd.weight" >&2
+echo "[   2/   2] output.weight" >&2
+cp "$2" "$3"
+`), 0755)).To(Succeed())
+
+		model = filepath.Join(tempdir, "model.gguf")
+		Expect(os.WriteFile(model, []byte("GGUF\x03\x00\x00\x00"), 0600)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempdir)
+	})
+
+	It("quantizes a GGUF model and reports the progress", func() {
+		output := filepath.Join(tempdir, "model-q4_k_m.gguf")
+		var progress []float64
+		Expect(QuantizeModel(tool, model, output, "q4_k_m", 4, func(p float64) {
+			progress = append(progress, p)
+		})).To(Succeed())
+		Expect(progress).To(Equal([]float64{50, 100}))
+		Expect(output).To(BeAnExistingFile())
+		Expect(output + ".partial").ToNot(BeAnExistingFile())
+	})
+
+	It("rejects the unknown types and the models which are not GGUF", func() {
+		output := filepath.Join(tempdir, "out.gguf")
+		Expect(QuantizeModel(tool, model, output, "Q7", 0, nil)).To(MatchError(ContainSubstring("unknown quantization type")))
+
+		legacy := filepath.Join(tempdir, "legacy.bin")
+		Expect(os.WriteFile(legacy, []byte("tjgg\x03\x00\x00\x00"), 0600)).To(Succeed())
+		Expect(QuantizeModel(tool, legacy, output, "Q4_0", 0, nil)).To(MatchError(ContainSubstring("not a GGUF model")))
+		Expect(output).ToNot(BeAnExistingFile())
+	})
+})
@@ -11,6 +11,10 @@ type GalleryModel struct {
 	URLs        []string `json:"urls,omitempty" yaml:"urls,omitempty"`
 	Icon        string   `json:"icon,omitempty" yaml:"icon,omitempty"`
 	Tags        []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Version of the model in the gallery, used to detect the updates of the installed models
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// Size of the files of the model in bytes, used to search the models
+	Size int64 `json:"size,omitempty" yaml:"size,omitempty"`
 	// config_file is read in the situation where URL is blank - and therefore this is a base config.
 	ConfigFile map[string]interface{} `json:"config_file,omitempty" yaml:"config_file,omitempty"`
 	// Overrides are used to override the configuration of the model located at URL
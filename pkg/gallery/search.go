@@ -0,0 +1,141 @@
+package gallery
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// SearchQuery filters the models of the galleries. The empty fields match all the models.
+type SearchQuery struct {
+	// Name matches the models whose name or description contains it, case insensitively
+	Name         string
+	Tag          string
+	Capability   string
+	Quantization string
+	// MinSize and MaxSize bound the size of the models in bytes. The models with no size only match when they are not set.
+	MinSize, MaxSize int64
+	Offset, Limit    int
+}
+
+// capabilities of the backends, used when the models don't declare them in their tags
+var backendCapabilities = map[string]string{
+	"stablediffusion":       "image-generation",
+	"tinydream":             "image-generation",
+	"diffusers":             "image-generation",
+	"whisper":               "transcription",
+	"piper":                 "tts",
+	"bark":                  "tts",
+	"coqui":                 "tts",
+	"vall-e-x":              "tts",
+	"transformers-musicgen": "tts",
+	"bert-embeddings":       "embeddings",
+	"sentencetransformers":  "embeddings",
+}
+
+var quantizationRegexp = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])((?:i?q[1-8](?:_[a-z0-9]+)*)|bf16|f16|f32|fp16|fp32)(?:[^a-z0-9]|$)`)
+
+// configValue returns a setting of the model, from its overrides or its config file
+func configValue(m *GalleryModel, key string) interface{} {
+	if v, ok := m.Overrides[key]; ok {
+		return v
+	}
+	return m.ConfigFile[key]
+}
+
+// Capabilities returns what the model can be used for: its tags, and what is inferred from its backend
+// (e.g. embeddings, image-generation, transcription, tts, text-generation)
+func Capabilities(m *GalleryModel) []string {
+	capabilities := []string{}
+	for _, t := range m.Tags {
+		capabilities = append(capabilities, strings.ToLower(t))
+	}
+
+	backend, _ := configValue(m, "backend").(string)
+	capability, ok := backendCapabilities[backend]
+	switch {
+	case ok:
+	case configValue(m, "embeddings") == true:
+		capability = "embeddings"
+	default:
+		capability = "text-generation"
+	}
+	if !slices.Contains(capabilities, capability) {
+		capabilities = append(capabilities, capability)
+	}
+	return capabilities
+}
+
+// Quantization returns the quantization of the model found in its name or the names of its files (e.g. Q4_K_M), if any
+func Quantization(m *GalleryModel) string {
+	candidates := []string{m.Name, m.URL}
+	for _, f := range m.AdditionalFiles {
+		candidates = append(candidates, f.Filename)
+	}
+	if parameters, ok := configValue(m, "parameters").(map[interface{}]interface{}); ok {
+		candidates = append(candidates, fmt.Sprint(parameters["model"]))
+	}
+	for _, c := range candidates {
+		if match := quantizationRegexp.FindStringSubmatch(filepath.Base(c)); match != nil {
+			return strings.ToUpper(match[1])
+		}
+	}
+	return ""
+}
+
+func (q SearchQuery) matches(m *GalleryModel) bool {
+	if q.Name != "" {
+		name := strings.ToLower(q.Name)
+		if !strings.Contains(strings.ToLower(m.Name), name) && !strings.Contains(strings.ToLower(m.Description), name) {
+			return false
+		}
+	}
+	if q.Tag != "" && !slices.ContainsFunc(m.Tags, func(t string) bool { return strings.EqualFold(t, q.Tag) }) {
+		return false
+	}
+	if q.Capability != "" && !slices.Contains(Capabilities(m), strings.ToLower(q.Capability)) {
+		return false
+	}
+	if q.Quantization != "" && !strings.EqualFold(Quantization(m), q.Quantization) {
+		return false
+	}
+	if q.MinSize > 0 && m.Size < q.MinSize {
+		return false
+	}
+	if q.MaxSize > 0 && (m.Size == 0 || m.Size > q.MaxSize) {
+		return false
+	}
+	return true
+}
+
+// SearchGalleryModels returns the page of the models matching the query, and the total number of matching models.
+// Whether the models are installed is checked again in basePath.
+func SearchGalleryModels(models []*GalleryModel, q SearchQuery, basePath string) ([]*GalleryModel, int) {
+	matching := []*GalleryModel{}
+	for _, m := range models {
+		if q.matches(m) {
+			matching = append(matching, m)
+		}
+	}
+	total := len(matching)
+
+	if q.Offset > 0 {
+		matching = matching[min(q.Offset, len(matching)):]
+	}
+	if q.Limit > 0 && len(matching) > q.Limit {
+		matching = matching[:q.Limit]
+	}
+
+	page := make([]*GalleryModel, 0, len(matching))
+	for _, m := range matching {
+		// copies the model, as the listed models may be shared
+		model := *m
+		_, err := os.Stat(filepath.Join(basePath, fmt.Sprintf("%s.yaml", model.Name)))
+		model.Installed = err == nil
+		page = append(page, &model)
+	}
+	return page, total
+}
@@ -0,0 +1,64 @@
+package gallery_test
+
+import (
+	. "github.com/go-skynet/LocalAI/pkg/gallery"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Gallery search", func() {
+	models := []*GalleryModel{
+		{Name: "mistral-7b-instruct.Q4_K_M", Description: "Instruction tuned", Tags: []string{"llm", "Mistral"}, Size: 4368439584},
+		{Name: "phi-2", AdditionalFiles: []File{{Filename: "phi-2.Q8_0.gguf"}}, Size: 2961010816},
+		{Name: "bert", Overrides: map[string]interface{}{"backend": "bert-embeddings"}},
+		{Name: "stablediffusion", ConfigFile: map[string]interface{}{"backend": "stablediffusion"}},
+	}
+
+	names := func(models []*GalleryModel) []string {
+		n := []string{}
+		for _, m := range models {
+			n = append(n, m.Name)
+		}
+		return n
+	}
+
+	It("infers the capabilities and quantization of the models", func() {
+		Expect(Capabilities(models[0])).To(Equal([]string{"llm", "mistral", "text-generation"}))
+		Expect(Capabilities(models[2])).To(Equal([]string{"embeddings"}))
+		Expect(Capabilities(models[3])).To(Equal([]string{"image-generation"}))
+		Expect(Quantization(models[0])).To(Equal("Q4_K_M"))
+		Expect(Quantization(models[1])).To(Equal("Q8_0"))
+		Expect(Quantization(models[2])).To(BeEmpty())
+	})
+
+	It("filters the models", func() {
+		page, total := SearchGalleryModels(models, SearchQuery{Name: "INSTRUCTION"}, "")
+		Expect(total).To(Equal(1))
+		Expect(names(page)).To(Equal([]string{"mistral-7b-instruct.Q4_K_M"}))
+
+		page, _ = SearchGalleryModels(models, SearchQuery{Tag: "mistral"}, "")
+		Expect(names(page)).To(Equal([]string{"mistral-7b-instruct.Q4_K_M"}))
+
+		page, _ = SearchGalleryModels(models, SearchQuery{Capability: "embeddings"}, "")
+		Expect(names(page)).To(Equal([]string{"bert"}))
+
+		page, _ = SearchGalleryModels(models, SearchQuery{Quantization: "q8_0"}, "")
+		Expect(names(page)).To(Equal([]string{"phi-2"}))
+
+		page, _ = SearchGalleryModels(models, SearchQuery{MaxSize: 3000000000}, "")
+		Expect(names(page)).To(Equal([]string{"phi-2"}))
+
+		page, _ = SearchGalleryModels(models, SearchQuery{MinSize: 3000000000}, "")
+		Expect(names(page)).To(Equal([]string{"mistral-7b-instruct.Q4_K_M"}))
+	})
+
+	It("paginates the results", func() {
+		page, total := SearchGalleryModels(models, SearchQuery{Offset: 1, Limit: 2}, "")
+		Expect(total).To(Equal(4))
+		Expect(names(page)).To(Equal([]string{"phi-2", "bert"}))
+
+		page, total = SearchGalleryModels(models, SearchQuery{Offset: 10}, "")
+		Expect(total).To(Equal(4))
+		Expect(page).To(BeEmpty())
+	})
+})
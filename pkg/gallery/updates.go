@@ -0,0 +1,232 @@
+package gallery
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Policies to update the models installed from a gallery when a new version is available
+const (
+	// UpdateManual only reports the updates, it is the default
+	UpdateManual = "manual"
+	// UpdatePinned ignores the updates
+	UpdatePinned = "pinned"
+	// UpdateMinor installs the updates which don't change the major version
+	UpdateMinor = "minor"
+	// UpdateAlways installs all the updates
+	UpdateAlways = "always"
+)
+
+var updatePolicies = map[string]bool{UpdateManual: true, UpdatePinned: true, UpdateMinor: true, UpdateAlways: true}
+
+// installedModelsFile records the models installed from the galleries, in the models path
+const installedModelsFile = ".gallery-installed.json"
+
+// InstalledModel is a model installed from a gallery, recorded to detect its updates
+type InstalledModel struct {
+	// Name is the name the model was installed with
+	Name        string    `json:"name"`
+	Gallery     string    `json:"gallery"`
+	Model       string    `json:"model"`
+	Version     string    `json:"version,omitempty"`
+	InstalledAt time.Time `json:"installed_at"`
+	Policy      string    `json:"policy,omitempty"`
+	// Request is the installation request, to install the updates with the same overrides
+	Request GalleryModel `json:"request"`
+}
+
+// ModelUpdate is a newer version of an installed model, available in its gallery
+type ModelUpdate struct {
+	Name             string `json:"name"`
+	ID               string `json:"id"`
+	InstalledVersion string `json:"installed_version,omitempty"`
+	Version          string `json:"version"`
+	Policy           string `json:"policy"`
+	// Automatic is true if the policy of the model allows installing the update without the user
+	Automatic bool `json:"automatic"`
+	// Request is the installation request of the installed model
+	Request GalleryModel `json:"-"`
+}
+
+var installedMu sync.Mutex
+
+// ReadInstalledModels returns the models installed from the galleries in basePath, by their name
+func ReadInstalledModels(basePath string) (map[string]InstalledModel, error) {
+	installedMu.Lock()
+	defer installedMu.Unlock()
+	return readInstalledModels(basePath)
+}
+
+func readInstalledModels(basePath string) (map[string]InstalledModel, error) {
+	models := map[string]InstalledModel{}
+	dat, err := os.ReadFile(filepath.Join(basePath, installedModelsFile))
+	if os.IsNotExist(err) {
+		return models, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return models, json.Unmarshal(dat, &models)
+}
+
+func writeInstalledModels(basePath string, models map[string]InstalledModel) error {
+	dat, err := json.MarshalIndent(models, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(basePath, installedModelsFile), dat, 0644)
+}
+
+// recordInstalledModel records the installation of a gallery model, keeping the update policy of the previous installation
+func recordInstalledModel(basePath string, m InstalledModel) error {
+	installedMu.Lock()
+	defer installedMu.Unlock()
+	models, err := readInstalledModels(basePath)
+	if err != nil {
+		return err
+	}
+	if m.Policy == "" {
+		m.Policy = models[m.Name].Policy
+	}
+	models[m.Name] = m
+	return writeInstalledModels(basePath, models)
+}
+
+// ForgetInstalledModel removes the record of a model installed from a gallery, when it is deleted
+func ForgetInstalledModel(basePath, name string) error {
+	installedMu.Lock()
+	defer installedMu.Unlock()
+	models, err := readInstalledModels(basePath)
+	if err != nil {
+		return err
+	}
+	if _, ok := models[name]; !ok {
+		return nil
+	}
+	delete(models, name)
+	return writeInstalledModels(basePath, models)
+}
+
+// SetUpdatePolicy sets the update policy of a model installed from a gallery
+func SetUpdatePolicy(basePath, name, policy string) error {
+	if !updatePolicies[policy] {
+		return fmt.Errorf("invalid update policy %q, valid policies are %s, %s, %s and %s", policy, UpdateManual, UpdatePinned, UpdateMinor, UpdateAlways)
+	}
+
+	installedMu.Lock()
+	defer installedMu.Unlock()
+	models, err := readInstalledModels(basePath)
+	if err != nil {
+		return err
+	}
+	m, ok := models[name]
+	if !ok {
+		return fmt.Errorf("model %q was not installed from a gallery", name)
+	}
+	m.Policy = policy
+	models[name] = m
+	return writeInstalledModels(basePath, models)
+}
+
+// CompareVersions compares two versions made of dot separated numbers (e.g. v1.2.0), and returns -1, 0 or 1.
+// The parts which aren't numbers are compared as strings.
+func CompareVersions(a, b string) int {
+	pa := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	pb := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var sa, sb string
+		if i < len(pa) {
+			sa = pa[i]
+		}
+		if i < len(pb) {
+			sb = pb[i]
+		}
+		na, errA := strconv.Atoi(sa)
+		nb, errB := strconv.Atoi(sb)
+		switch {
+		case errA == nil && errB == nil && na != nb:
+			if na < nb {
+				return -1
+			}
+			return 1
+		case (errA != nil || errB != nil) && sa != sb:
+			// a missing part is older than any other
+			if sa < sb {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func majorVersion(v string) string {
+	return strings.SplitN(strings.TrimPrefix(v, "v"), ".", 2)[0]
+}
+
+// automaticUpdate returns true if the policy allows to update the model from a version to the other without the user
+func automaticUpdate(policy, from, to string) bool {
+	switch policy {
+	case UpdateAlways:
+		return true
+	case UpdateMinor:
+		return from != "" && majorVersion(from) == majorVersion(to)
+	}
+	return false
+}
+
+// FindUpdates returns the updates of the installed models, among the models available in the galleries.
+// The models whose config file was removed, and the pinned ones, are ignored.
+func FindUpdates(installed map[string]InstalledModel, available []*GalleryModel, basePath string) []ModelUpdate {
+	updates := []ModelUpdate{}
+	for _, m := range installed {
+		if m.Policy == UpdatePinned {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(basePath, m.Name+".yaml")); err != nil {
+			continue
+		}
+		for _, a := range available {
+			if a.Gallery.Name != m.Gallery || a.Name != m.Model || a.Version == "" || CompareVersions(a.Version, m.Version) <= 0 {
+				continue
+			}
+			policy := m.Policy
+			if policy == "" {
+				policy = UpdateManual
+			}
+			updates = append(updates, ModelUpdate{
+				Name:             m.Name,
+				ID:               fmt.Sprintf("%s@%s", m.Gallery, m.Model),
+				InstalledVersion: m.Version,
+				Version:          a.Version,
+				Policy:           policy,
+				Automatic:        automaticUpdate(policy, m.Version, a.Version),
+				Request:          m.Request,
+			})
+		}
+	}
+	return updates
+}
+
+// AvailableUpdates refreshes the galleries, and returns the updates of the models installed from them in basePath
+func AvailableUpdates(galleries []Gallery, basePath string) ([]ModelUpdate, error) {
+	installed, err := ReadInstalledModels(basePath)
+	if err != nil {
+		return nil, err
+	}
+	if len(installed) == 0 {
+		return []ModelUpdate{}, nil
+	}
+
+	available, err := AvailableGalleryModels(galleries, basePath)
+	if err != nil {
+		return nil, err
+	}
+	return FindUpdates(installed, available, basePath), nil
+}
@@ -0,0 +1,94 @@
+package gallery_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/go-skynet/LocalAI/pkg/gallery"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Model updates", func() {
+	It("compares versions", func() {
+		Expect(CompareVersions("1.2.0", "1.10.0")).To(Equal(-1))
+		Expect(CompareVersions("v2.0", "1.9.9")).To(Equal(1))
+		Expect(CompareVersions("v1.0", "1.0")).To(Equal(0))
+		Expect(CompareVersions("1.0.1", "1.0")).To(Equal(1))
+		Expect(CompareVersions("1", "")).To(Equal(1))
+	})
+
+	Context("with a local gallery", func() {
+		var tempdir string
+		var galleries []Gallery
+
+		writeGallery := func(version string) {
+			index := `- name: tiny
+  version: "` + version + `"
+  config_file:
+    backend: llama
+    parameters:
+      model: tiny.gguf
+`
+			Expect(os.WriteFile(filepath.Join(tempdir, "index.yaml"), []byte(index), 0644)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			var err error
+			tempdir, err = os.MkdirTemp("", "updates")
+			Expect(err).ToNot(HaveOccurred())
+			galleries = []Gallery{{Name: "local", URL: "file://" + filepath.Join(tempdir, "index.yaml")}}
+			writeGallery("1.0.0")
+
+			err = InstallModelFromGallery(galleries, "local@tiny", tempdir, GalleryModel{}, func(string, string, string, float64) {})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tempdir)
+		})
+
+		It("records the installed models", func() {
+			installed, err := ReadInstalledModels(tempdir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(installed).To(HaveKey("tiny"))
+			Expect(installed["tiny"].Gallery).To(Equal("local"))
+			Expect(installed["tiny"].Version).To(Equal("1.0.0"))
+		})
+
+		It("finds the updates according to the policy", func() {
+			updates, err := AvailableUpdates(galleries, tempdir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(updates).To(BeEmpty())
+
+			writeGallery("1.1.0")
+			updates, err = AvailableUpdates(galleries, tempdir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(updates).To(HaveLen(1))
+			Expect(updates[0].ID).To(Equal("local@tiny"))
+			Expect(updates[0].Version).To(Equal("1.1.0"))
+			Expect(updates[0].Policy).To(Equal(UpdateManual))
+			Expect(updates[0].Automatic).To(BeFalse())
+
+			Expect(SetUpdatePolicy(tempdir, "tiny", UpdateMinor)).To(Succeed())
+			updates, err = AvailableUpdates(galleries, tempdir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(updates[0].Automatic).To(BeTrue())
+
+			writeGallery("2.0.0")
+			updates, err = AvailableUpdates(galleries, tempdir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(updates[0].Automatic).To(BeFalse())
+
+			Expect(SetUpdatePolicy(tempdir, "tiny", UpdatePinned)).To(Succeed())
+			updates, err = AvailableUpdates(galleries, tempdir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(updates).To(BeEmpty())
+		})
+
+		It("rejects unknown policies and models", func() {
+			Expect(SetUpdatePolicy(tempdir, "tiny", "sometimes")).ToNot(Succeed())
+			Expect(SetUpdatePolicy(tempdir, "other", UpdateAlways)).ToNot(Succeed())
+		})
+	})
+})
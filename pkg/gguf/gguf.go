@@ -0,0 +1,396 @@
+// Package gguf reads the metadata of GGUF model files.
+// See https://github.com/ggerganov/ggml/blob/master/docs/gguf.md
+package gguf
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const magic = "GGUF"
+
+const defaultAlignment = 32
+
+// value types of the metadata
+const (
+	typeUint8 uint32 = iota
+	typeInt8
+	typeUint16
+	typeInt16
+	typeUint32
+	typeInt32
+	typeFloat32
+	typeBool
+	typeString
+	typeArray
+	typeUint64
+	typeInt64
+	typeFloat64
+)
+
+// minimum size in bytes of the values of each type, bounding the counts read from the file
+var minSizes = map[uint32]uint64{
+	typeUint8:   1,
+	typeInt8:    1,
+	typeUint16:  2,
+	typeInt16:   2,
+	typeUint32:  4,
+	typeInt32:   4,
+	typeFloat32: 4,
+	typeBool:    1,
+	// the length of the string
+	typeString: 8,
+	// the type and the count of the items
+	typeArray:   12,
+	typeUint64:  8,
+	typeInt64:   8,
+	typeFloat64: 8,
+}
+
+const (
+	// minimum size of a metadata key-value pair: the length of the key, the type and a one byte value
+	minKVSize = 8 + 4 + 1
+	// minimum size of a tensor info: the length of the name, the number of dimensions, the type and the offset
+	minTensorSize = 8 + 4 + 4 + 8
+)
+
+type Tensor struct {
+	Name   string
+	Offset uint64
+	// Size of the tensor data, in bytes
+	Size uint64
+}
+
+type File struct {
+	Version uint32
+	// Metadata holds the scalar and string metadata values, arrays are skipped
+	Metadata map[string]interface{}
+	// Arrays holds the array metadata values requested with ReadArrays
+	Arrays  map[string][]interface{}
+	Tensors []Tensor
+}
+
+// Layers returns the number of blocks (layers) of the model, as declared in the metadata
+func (f *File) Layers() int {
+	arch, _ := f.Metadata["general.architecture"].(string)
+	switch v := f.Metadata[arch+".block_count"].(type) {
+	case uint32:
+		return int(v)
+	case uint64:
+		return int(v)
+	case int32:
+		return int(v)
+	}
+	return 0
+}
+
+// names of the llama.cpp file types (general.file_type), which tell how the model is quantized
+var fileTypes = map[uint32]string{
+	0:  "F32",
+	1:  "F16",
+	2:  "Q4_0",
+	3:  "Q4_1",
+	4:  "Q4_1_SOME_F16",
+	7:  "Q8_0",
+	8:  "Q5_0",
+	9:  "Q5_1",
+	10: "Q2_K",
+	11: "Q3_K_S",
+	12: "Q3_K_M",
+	13: "Q3_K_L",
+	14: "Q4_K_S",
+	15: "Q4_K_M",
+	16: "Q5_K_S",
+	17: "Q5_K_M",
+	18: "Q6_K",
+}
+
+// FileType returns the quantization of the model, empty if unknown
+func (f *File) FileType() string {
+	t, ok := f.Metadata["general.file_type"].(uint32)
+	if !ok {
+		return ""
+	}
+	if name, ok := fileTypes[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("type %d", t)
+}
+
+// LayerSizes returns the size in bytes of each layer, and of the tensors which don't belong to any layer (embeddings, output)
+func (f *File) LayerSizes() (layers []uint64, other uint64) {
+	layers = make([]uint64, f.Layers())
+	for _, t := range f.Tensors {
+		if rest, ok := strings.CutPrefix(t.Name, "blk."); ok {
+			n, _, _ := strings.Cut(rest, ".")
+			if i, err := strconv.Atoi(n); err == nil {
+				for i >= len(layers) {
+					layers = append(layers, 0)
+				}
+				layers[i] += t.Size
+				continue
+			}
+		}
+		other += t.Size
+	}
+	return layers, other
+}
+
+// Read reads the metadata and the tensor infos of the GGUF file at path
+func Read(path string) (*File, error) {
+	return read(path, nil)
+}
+
+// ReadArrays reads the GGUF file at path like Read, along with the values of the arrays of the given keys, e.g. the
+// vocabulary of the tokenizer
+func ReadArrays(path string, keys ...string) (*File, error) {
+	arrays := map[string]bool{}
+	for _, k := range keys {
+		arrays[k] = true
+	}
+	return read(path, arrays)
+}
+
+func read(path string, arrays map[string]bool) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &reader{r: bufio.NewReader(f), size: uint64(stat.Size())}
+
+	m := make([]byte, 4)
+	if _, err := io.ReadFull(r, m); err != nil {
+		return nil, err
+	}
+	if string(m) != magic {
+		return nil, fmt.Errorf("%s is not a GGUF file", path)
+	}
+
+	file := &File{Metadata: map[string]interface{}{}, Arrays: map[string][]interface{}{}}
+	file.Version = r.uint32()
+	if file.Version < 2 {
+		return nil, fmt.Errorf("unsupported GGUF version %d", file.Version)
+	}
+
+	tensorCount := r.uint64()
+	kvCount := r.uint64()
+	r.bound(tensorCount, minTensorSize, "tensors")
+	r.bound(kvCount, minKVSize, "metadata values")
+
+	for i := uint64(0); i < kvCount && r.err == nil; i++ {
+		key := r.string()
+		t := r.uint32()
+		if t == typeArray && arrays[key] {
+			file.Arrays[key] = r.array()
+			continue
+		}
+		v := r.value(t)
+		if v != nil {
+			file.Metadata[key] = v
+		}
+	}
+
+	for i := uint64(0); i < tensorCount && r.err == nil; i++ {
+		name := r.string()
+		dims := r.uint32()
+		r.bound(uint64(dims), 8, "tensor dimensions")
+		for d := uint32(0); d < dims && r.err == nil; d++ {
+			r.uint64()
+		}
+		r.uint32() // type
+		file.Tensors = append(file.Tensors, Tensor{Name: name, Offset: r.uint64()})
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("failed reading GGUF file %s: %w", path, r.err)
+	}
+
+	alignment := uint64(defaultAlignment)
+	if a, ok := file.Metadata["general.alignment"].(uint32); ok && a != 0 {
+		alignment = uint64(a)
+	}
+	dataStart := (r.pos + alignment - 1) / alignment * alignment
+	dataSize := uint64(0)
+	// files without tensors might end before the padding of the data section
+	if dataStart < r.size {
+		dataSize = r.size - dataStart
+	}
+
+	// the size of each tensor is the distance to the next one in the data section
+	sorted := make([]int, len(file.Tensors))
+	for i := range sorted {
+		sorted[i] = i
+	}
+	sort.Slice(sorted, func(a, b int) bool { return file.Tensors[sorted[a]].Offset < file.Tensors[sorted[b]].Offset })
+	for i, idx := range sorted {
+		end := dataSize
+		if i+1 < len(sorted) {
+			end = file.Tensors[sorted[i+1]].Offset
+		}
+		if end > file.Tensors[idx].Offset {
+			file.Tensors[idx].Size = end - file.Tensors[idx].Offset
+		}
+	}
+
+	return file, nil
+}
+
+// reader keeps track of the position and of the first error, to keep the parsing code linear
+type reader struct {
+	r io.Reader
+	// size of the file, the lengths and counts read from it can't exceed what remains of it
+	size uint64
+	pos  uint64
+	err  error
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := io.ReadFull(r.r, p)
+	r.pos += uint64(n)
+	return n, err
+}
+
+func (r *reader) read(v interface{}) {
+	if r.err != nil {
+		return
+	}
+	r.err = binary.Read(r, binary.LittleEndian, v)
+}
+
+// bound fails when the n items of at least itemSize bytes can't fit in the rest of the file, so that malformed files
+// don't make the reader allocate (or loop over) more than the file holds
+func (r *reader) bound(n, itemSize uint64, what string) bool {
+	if r.err != nil {
+		return false
+	}
+	remaining := uint64(0)
+	if r.size > r.pos {
+		remaining = r.size - r.pos
+	}
+	if n > remaining/itemSize {
+		r.err = fmt.Errorf("%d %s exceed the %d bytes left in the file", n, what, remaining)
+		return false
+	}
+	return true
+}
+
+func (r *reader) uint32() uint32 {
+	var v uint32
+	r.read(&v)
+	return v
+}
+
+func (r *reader) uint64() uint64 {
+	var v uint64
+	r.read(&v)
+	return v
+}
+
+func (r *reader) string() string {
+	n := r.uint64()
+	if !r.bound(n, 1, "string bytes") {
+		return ""
+	}
+	b := make([]byte, n)
+	r.read(b)
+	return string(b)
+}
+
+// array reads the values of an array
+func (r *reader) array() []interface{} {
+	itemType, count := r.arrayHeader()
+	values := []interface{}{}
+	if r.err == nil {
+		values = make([]interface{}, 0, count)
+	}
+	for i := uint64(0); i < count && r.err == nil; i++ {
+		values = append(values, r.value(itemType))
+	}
+	return values
+}
+
+// arrayHeader reads the type and the count of the items of an array
+func (r *reader) arrayHeader() (uint32, uint64) {
+	itemType := r.uint32()
+	count := r.uint64()
+	if r.err != nil {
+		return itemType, 0
+	}
+	size, ok := minSizes[itemType]
+	if !ok {
+		r.err = fmt.Errorf("unknown metadata value type %d", itemType)
+		return itemType, 0
+	}
+	r.bound(count, size, "array items")
+	return itemType, count
+}
+
+// value reads a metadata value of type t. Arrays are skipped, returning nil.
+func (r *reader) value(t uint32) interface{} {
+	switch t {
+	case typeUint8:
+		var v uint8
+		r.read(&v)
+		return v
+	case typeInt8:
+		var v int8
+		r.read(&v)
+		return v
+	case typeUint16:
+		var v uint16
+		r.read(&v)
+		return v
+	case typeInt16:
+		var v int16
+		r.read(&v)
+		return v
+	case typeUint32:
+		return r.uint32()
+	case typeInt32:
+		var v int32
+		r.read(&v)
+		return v
+	case typeFloat32:
+		var v float32
+		r.read(&v)
+		return v
+	case typeBool:
+		var v uint8
+		r.read(&v)
+		return v != 0
+	case typeString:
+		return r.string()
+	case typeArray:
+		itemType, count := r.arrayHeader()
+		for i := uint64(0); i < count && r.err == nil; i++ {
+			r.value(itemType)
+		}
+		return nil
+	case typeUint64:
+		return r.uint64()
+	case typeInt64:
+		var v int64
+		r.read(&v)
+		return v
+	case typeFloat64:
+		var v float64
+		r.read(&v)
+		return v
+	}
+	if r.err == nil {
+		r.err = fmt.Errorf("unknown metadata value type %d", t)
+	}
+	return nil
+}
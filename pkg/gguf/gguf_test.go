@@ -0,0 +1,188 @@
+package gguf_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+
+	. "github.com/go-skynet/LocalAI/pkg/gguf"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type tensor struct {
+	name   string
+	offset uint64
+}
+
+// writeGGUF writes a minimal GGUF v3 file with a llama architecture, the given tensors and dataSize bytes of data
+func writeGGUF(path string, blocks uint32, tensors []tensor, dataSize int) {
+	b := &bytes.Buffer{}
+	w := func(v interface{}) { binary.Write(b, binary.LittleEndian, v) }
+	str := func(s string) {
+		w(uint64(len(s)))
+		b.WriteString(s)
+	}
+
+	b.WriteString("GGUF")
+	w(uint32(3))
+	w(uint64(len(tensors)))
+	w(uint64(3))
+
+	str("general.architecture")
+	w(uint32(8))
+	str("llama")
+
+	// arrays are skipped
+	str("tokenizer.ggml.tokens")
+	w(uint32(9))
+	w(uint32(8))
+	w(uint64(2))
+	str("a")
+	str("b")
+
+	str("llama.block_count")
+	w(uint32(4))
+	w(blocks)
+
+	for _, t := range tensors {
+		str(t.name)
+		w(uint32(1))
+		w(uint64(t.offset))
+		w(uint32(0))
+		w(t.offset)
+	}
+
+	for b.Len()%32 != 0 {
+		b.WriteByte(0)
+	}
+	b.Write(make([]byte, dataSize))
+
+	Expect(os.WriteFile(path, b.Bytes(), 0600)).To(Succeed())
+}
+
+var _ = Describe("GGUF", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "gguf")
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(os.RemoveAll, dir)
+	})
+
+	It("reads the layers and the tensor sizes", func() {
+		path := filepath.Join(dir, "model.gguf")
+		writeGGUF(path, 2, []tensor{
+			{"token_embd.weight", 0},
+			{"blk.0.attn_q.weight", 100},
+			{"blk.0.ffn_up.weight", 150},
+			{"blk.1.attn_q.weight", 300},
+			{"output.weight", 500},
+		}, 600)
+
+		f, err := Read(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(f.Version).To(Equal(uint32(3)))
+		Expect(f.Metadata["general.architecture"]).To(Equal("llama"))
+		Expect(f.Metadata).ToNot(HaveKey("tokenizer.ggml.tokens"))
+		Expect(f.Layers()).To(Equal(2))
+
+		layers, other := f.LayerSizes()
+		Expect(layers).To(Equal([]uint64{200, 200}))
+		Expect(other).To(Equal(uint64(200)))
+	})
+
+	It("reads the requested arrays", func() {
+		path := filepath.Join(dir, "model.gguf")
+		writeGGUF(path, 1, []tensor{{"token_embd.weight", 0}}, 100)
+
+		f, err := ReadArrays(path, "tokenizer.ggml.tokens")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(f.Arrays["tokenizer.ggml.tokens"]).To(Equal([]interface{}{"a", "b"}))
+		Expect(f.Layers()).To(Equal(1))
+	})
+
+	DescribeTable("fails on malformed files without allocating the lengths they declare",
+		func(body func(w func(v interface{}))) {
+			b := &bytes.Buffer{}
+			w := func(v interface{}) {
+				if s, ok := v.(string); ok {
+					b.WriteString(s)
+					return
+				}
+				binary.Write(b, binary.LittleEndian, v)
+			}
+			w("GGUF")
+			w(uint32(3))
+			body(w)
+			path := filepath.Join(dir, "model.gguf")
+			Expect(os.WriteFile(path, b.Bytes(), 0600)).To(Succeed())
+
+			_, err := ReadArrays(path, "tokenizer.ggml.tokens")
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("a huge number of tensors", func(w func(v interface{})) {
+			w(uint64(1) << 60)
+			w(uint64(0))
+		}),
+		Entry("a huge number of metadata values", func(w func(v interface{})) {
+			w(uint64(0))
+			w(^uint64(0))
+		}),
+		Entry("a huge key", func(w func(v interface{})) {
+			w(uint64(0))
+			w(uint64(1))
+			w(^uint64(0) - 1)
+			w("general")
+		}),
+		Entry("a huge array", func(w func(v interface{})) {
+			w(uint64(0))
+			w(uint64(1))
+			w(uint64(len("tokenizer.ggml.tokens")))
+			w("tokenizer.ggml.tokens")
+			w(uint32(9))
+			w(uint32(8))
+			w(uint64(1) << 62)
+		}),
+		Entry("a huge string in an array", func(w func(v interface{})) {
+			w(uint64(0))
+			w(uint64(1))
+			w(uint64(len("tokenizer.ggml.tokens")))
+			w("tokenizer.ggml.tokens")
+			w(uint32(9))
+			w(uint32(8))
+			w(uint64(1))
+			w(uint64(1) << 40)
+			w("a")
+		}),
+		Entry("an array of unknown values", func(w func(v interface{})) {
+			w(uint64(0))
+			w(uint64(1))
+			w(uint64(len("general.list")))
+			w("general.list")
+			w(uint32(9))
+			w(uint32(42))
+			w(uint64(1))
+		}),
+		Entry("a huge number of tensor dimensions", func(w func(v interface{})) {
+			w(uint64(1))
+			w(uint64(0))
+			w(uint64(1))
+			w("t")
+			w(^uint32(0))
+		}),
+		Entry("a truncated header", func(w func(v interface{})) {
+			w(uint32(1))
+		}),
+	)
+
+	It("fails on files which are not GGUF", func() {
+		path := filepath.Join(dir, "model.bin")
+		Expect(os.WriteFile(path, []byte("ggjt\x01\x00\x00\x00"), 0600)).To(Succeed())
+
+		_, err := Read(path)
+		Expect(err).To(HaveOccurred())
+	})
+})
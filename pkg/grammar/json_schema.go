@@ -69,7 +69,9 @@ func NewJSONSchemaConverter(propOrder string) *JSONSchemaConverter {
 	propOrderSlice := strings.Split(propOrder, ",")
 	propOrderMap := make(map[string]int)
 	for idx, name := range propOrderSlice {
-		propOrderMap[name] = idx
+		if name != "" {
+			propOrderMap[name] = idx
+		}
 	}
 
 	rules := make(map[string]string)
@@ -171,12 +173,16 @@ func (sc *JSONSchemaConverter) visit(schema map[string]interface{}, name string,
 			}{propName: propName, propSchema: propSchema.(map[string]interface{})})
 		}
 
+		// the properties in propOrder come first, in that order, then the others alphabetically
 		sort.Slice(propPairs, func(i, j int) bool {
-			iOrder := propOrder[propPairs[i].propName]
-			jOrder := propOrder[propPairs[j].propName]
-			if iOrder != 0 && jOrder != 0 {
+			iOrder, iOk := propOrder[propPairs[i].propName]
+			jOrder, jOk := propOrder[propPairs[j].propName]
+			if iOk && jOk {
 				return iOrder < jOrder
 			}
+			if iOk != jOk {
+				return iOk
+			}
 			return propPairs[i].propName < propPairs[j].propName
 		})
 
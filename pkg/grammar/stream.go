@@ -0,0 +1,191 @@
+package grammar
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// FunctionCallOrder is the order of the properties of the function calls in the grammar,
+// the name of the function is generated first so it can be streamed before its arguments
+const FunctionCallOrder = "function,arguments"
+
+// FunctionCallParser parses a function call, as generated with the grammar of the functions,
+// while it is streamed: the name of the function is returned as soon as it is complete,
+// and then the arguments fragment by fragment.
+type FunctionCallParser struct {
+	name string
+	// the fragment of the arguments which was not returned yet, and all the arguments
+	pending   strings.Builder
+	arguments strings.Builder
+
+	// state of the function call object
+	depth     int
+	inString  bool
+	escaped   bool
+	str       strings.Builder
+	key       string
+	expectKey bool
+	// the next non space character starts the arguments
+	awaitingArguments bool
+
+	// state of the arguments
+	inArguments       bool
+	argumentsDepth    int
+	argumentsInString bool
+	argumentsEscaped  bool
+}
+
+func NewFunctionCallParser() *FunctionCallParser {
+	return &FunctionCallParser{}
+}
+
+// Write parses the next characters of the function call. It returns the name of the function
+// when it was completed by these characters (and an empty string otherwise), and the fragment of
+// the arguments to send. The arguments are held back until the name of the function is known.
+func (p *FunctionCallParser) Write(s string) (name, arguments string) {
+	nameKnown := p.name != ""
+	for _, r := range s {
+		p.parse(r)
+	}
+
+	if p.name != "" && !nameKnown {
+		name = p.name
+	}
+	if p.name != "" {
+		arguments = p.pending.String()
+		p.pending.Reset()
+	}
+	return
+}
+
+// Name returns the name of the function, empty until it is complete
+func (p *FunctionCallParser) Name() string {
+	return p.name
+}
+
+// Arguments returns all the arguments parsed so far
+func (p *FunctionCallParser) Arguments() string {
+	return p.arguments.String()
+}
+
+func (p *FunctionCallParser) argument(s string) {
+	p.pending.WriteString(s)
+	p.arguments.WriteString(s)
+}
+
+func (p *FunctionCallParser) parse(r rune) {
+	if p.inArguments && p.parseArguments(r) {
+		return
+	}
+	if p.awaitingArguments && !isSpace(r) {
+		p.awaitingArguments = false
+		p.inArguments = true
+		p.argumentsDepth = 0
+		p.parseArguments(r)
+		return
+	}
+
+	if p.inString {
+		switch {
+		case p.escaped:
+			p.escaped = false
+		case r == '\\':
+			p.escaped = true
+		case r == '"':
+			p.inString = false
+			if p.depth == 1 {
+				p.endString()
+			}
+			return
+		}
+		if p.depth == 1 {
+			p.str.WriteRune(r)
+		}
+		return
+	}
+
+	switch r {
+	case '"':
+		p.inString = true
+		p.str.Reset()
+	case '{', '[':
+		p.depth++
+		if p.depth == 1 {
+			p.expectKey = true
+		}
+	case '}', ']':
+		p.depth--
+	case ':':
+		if p.depth == 1 {
+			p.expectKey = false
+			p.awaitingArguments = p.key == "arguments"
+		}
+	case ',':
+		if p.depth == 1 {
+			p.expectKey = true
+		}
+	}
+}
+
+// parseArguments parses a character of the arguments, and returns false
+// if it is not part of them anymore but of the function call object
+func (p *FunctionCallParser) parseArguments(r rune) bool {
+	if p.argumentsInString {
+		switch {
+		case p.argumentsEscaped:
+			p.argumentsEscaped = false
+		case r == '\\':
+			p.argumentsEscaped = true
+		case r == '"':
+			p.argumentsInString = false
+		}
+		// the new lines are escaped to keep the JSON valid
+		if r == '\n' {
+			p.argument(`\n`)
+		} else {
+			p.argument(string(r))
+		}
+		return true
+	}
+
+	switch r {
+	case '"':
+		p.argumentsInString = true
+	case '{', '[':
+		p.argumentsDepth++
+	case '}', ']':
+		if p.argumentsDepth == 0 {
+			p.inArguments = false
+			return false
+		}
+		p.argumentsDepth--
+	case ',':
+		if p.argumentsDepth == 0 {
+			p.inArguments = false
+			return false
+		}
+	}
+	if p.argumentsDepth > 0 || !isSpace(r) {
+		p.argument(string(r))
+	}
+	return true
+}
+
+func (p *FunctionCallParser) endString() {
+	raw := `"` + p.str.String() + `"`
+	p.str.Reset()
+
+	var s string
+	json.Unmarshal([]byte(raw), &s)
+
+	switch {
+	case p.expectKey:
+		p.key = s
+	case p.key == "function" && p.name == "":
+		p.name = s
+	}
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
@@ -0,0 +1,72 @@
+package grammar_test
+
+import (
+	"encoding/json"
+
+	. "github.com/go-skynet/LocalAI/pkg/grammar"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// stream writes the function call character by character, and returns the names and fragments of arguments
+func stream(call string) ([]string, string) {
+	p := NewFunctionCallParser()
+	names := []string{}
+	arguments := ""
+	for _, r := range call {
+		name, args := p.Write(string(r))
+		if name != "" {
+			names = append(names, name)
+		}
+		arguments += args
+	}
+	Expect(p.Arguments()).To(Equal(arguments))
+	return names, arguments
+}
+
+var _ = Describe("FunctionCallParser", func() {
+	It("returns the name of the function first, then its arguments", func() {
+		p := NewFunctionCallParser()
+		name, args := p.Write(`{"function": "sea`)
+		Expect(name).To(BeEmpty())
+		Expect(args).To(BeEmpty())
+
+		name, args = p.Write(`rch", "arguments": {"query": "new`)
+		Expect(name).To(Equal("search"))
+		Expect(args).To(Equal(`{"query": "new`))
+
+		name, args = p.Write(` york", "limit": 3}}`)
+		Expect(name).To(BeEmpty())
+		Expect(args).To(Equal(` york", "limit": 3}`))
+		Expect(p.Name()).To(Equal("search"))
+		Expect(p.Arguments()).To(Equal(`{"query": "new york", "limit": 3}`))
+	})
+
+	It("holds the arguments back until the name is known", func() {
+		names, args := stream(`{"arguments": {"nested": {"a": [1, 2]}, "b": "}"}, "function": "create_event"}`)
+		Expect(names).To(Equal([]string{"create_event"}))
+		Expect(args).To(Equal(`{"nested": {"a": [1, 2]}, "b": "}"}`))
+	})
+
+	It("parses escaped strings", func() {
+		names, args := stream(`{"function": "say_\"hi\"", "arguments": {"text": "a \"quoted\" \\ text"}}`)
+		Expect(names).To(Equal([]string{`say_"hi"`}))
+		Expect(args).To(Equal(`{"text": "a \"quoted\" \\ text"}`))
+	})
+
+	It("escapes the new lines in the strings of the arguments", func() {
+		_, args := stream("{\"function\": \"note\", \"arguments\": {\n  \"text\": \"line 1\nline 2\"\n}\n}")
+		res := map[string]string{}
+		Expect(json.Unmarshal([]byte(args), &res)).To(Succeed())
+		Expect(res["text"]).To(Equal("line 1\nline 2"))
+	})
+
+	It("generates the function name first with the function call order", func() {
+		functions := Functions{{Name: "search", Parameters: map[string]interface{}{
+			"properties": map[string]interface{}{"query": map[string]interface{}{"type": "string"}},
+		}}}
+		grammar := functions.ToJSONStructure().Grammar(FunctionCallOrder)
+		Expect(grammar).To(ContainSubstring(`"\"function\"" space ":" space`))
+		Expect(grammar).To(MatchRegexp(`"\\"function\\"".*"\\"arguments\\""`))
+	})
+})
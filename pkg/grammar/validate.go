@@ -0,0 +1,248 @@
+package grammar
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SchemaGrammar converts a JSON schema to a grammar, and returns an error
+// instead of panicking when the schema is not supported by the converter
+func SchemaGrammar(schema map[string]interface{}) (g string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("unsupported JSON schema: %v", r)
+		}
+	}()
+	return NewJSONSchemaConverter("").Grammar(schema), nil
+}
+
+// ValidateJSON checks that the JSON document matches the schema. It supports the
+// keywords the grammars are generated from, and the usual constraints on the values.
+func ValidateJSON(schema map[string]interface{}, data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(data))), &v); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return validate(schema, v, "$", schema)
+}
+
+func validate(schema map[string]interface{}, v interface{}, path string, root map[string]interface{}) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		def, err := definition(ref, root)
+		if err != nil {
+			return err
+		}
+		return validate(def, v, path, root)
+	}
+
+	if c, ok := schema["const"]; ok && !equal(c, v) {
+		return fmt.Errorf("%s: must be %s", path, jsonString(c))
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if equal(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: must be one of %s", path, jsonString(enum))
+		}
+	}
+
+	if all, ok := schema["allOf"].([]interface{}); ok {
+		for _, s := range all {
+			if err := validate(asSchema(s), v, path, root); err != nil {
+				return err
+			}
+		}
+	}
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		alternatives, ok := schema[keyword].([]interface{})
+		if !ok {
+			continue
+		}
+		matches := 0
+		var lastErr error
+		for _, s := range alternatives {
+			if err := validate(asSchema(s), v, path, root); err != nil {
+				lastErr = err
+			} else {
+				matches++
+			}
+		}
+		if matches == 0 {
+			return fmt.Errorf("%s: does not match any of the schemas of %s (%v)", path, keyword, lastErr)
+		}
+		if keyword == "oneOf" && matches > 1 {
+			return fmt.Errorf("%s: matches more than one of the schemas of oneOf", path)
+		}
+	}
+
+	if t, ok := schema["type"]; ok {
+		types := []string{}
+		switch t := t.(type) {
+		case string:
+			types = append(types, t)
+		case []interface{}:
+			for _, s := range t {
+				types = append(types, fmt.Sprint(s))
+			}
+		}
+		matches := false
+		for _, t := range types {
+			if isType(t, v) {
+				matches = true
+				break
+			}
+		}
+		if !matches {
+			return fmt.Errorf("%s: must be of type %s", path, strings.Join(types, " or "))
+		}
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return validateObject(schema, v, path, root)
+	case []interface{}:
+		if min, ok := number(schema["minItems"]); ok && float64(len(v)) < min {
+			return fmt.Errorf("%s: must have at least %v items", path, min)
+		}
+		if max, ok := number(schema["maxItems"]); ok && float64(len(v)) > max {
+			return fmt.Errorf("%s: must have at most %v items", path, max)
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				if err := validate(items, item, fmt.Sprintf("%s[%d]", path, i), root); err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		length := float64(len([]rune(v)))
+		if min, ok := number(schema["minLength"]); ok && length < min {
+			return fmt.Errorf("%s: must have at least %v characters", path, min)
+		}
+		if max, ok := number(schema["maxLength"]); ok && length > max {
+			return fmt.Errorf("%s: must have at most %v characters", path, max)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("%s: invalid pattern %q: %w", path, pattern, err)
+			}
+			if !re.MatchString(v) {
+				return fmt.Errorf("%s: must match the pattern %q", path, pattern)
+			}
+		}
+	case float64:
+		if min, ok := number(schema["minimum"]); ok && v < min {
+			return fmt.Errorf("%s: must be at least %v", path, min)
+		}
+		if max, ok := number(schema["maximum"]); ok && v > max {
+			return fmt.Errorf("%s: must be at most %v", path, max)
+		}
+	}
+	return nil
+}
+
+func validateObject(schema map[string]interface{}, v map[string]interface{}, path string, root map[string]interface{}) error {
+	properties, _ := schema["properties"].(map[string]interface{})
+
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, r := range required {
+			if _, exists := v[fmt.Sprint(r)]; !exists {
+				return fmt.Errorf("%s: missing required property %q", path, r)
+			}
+		}
+	}
+
+	// the keys are sorted to always report the same error
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		propPath := path + "." + k
+		if propSchema, ok := properties[k]; ok {
+			if err := validate(asSchema(propSchema), v[k], propPath, root); err != nil {
+				return err
+			}
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				return fmt.Errorf("%s: unexpected property", propPath)
+			}
+		case map[string]interface{}:
+			if err := validate(additional, v[k], propPath, root); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func definition(ref string, root map[string]interface{}) (map[string]interface{}, error) {
+	for _, prefix := range []string{"#/$defs/", "#/definitions/"} {
+		if !strings.HasPrefix(ref, prefix) {
+			continue
+		}
+		defs, _ := root[strings.TrimSuffix(strings.TrimPrefix(prefix, "#/"), "/")].(map[string]interface{})
+		if def, ok := defs[strings.TrimPrefix(ref, prefix)].(map[string]interface{}); ok {
+			return def, nil
+		}
+	}
+	if ref == "#" {
+		return root, nil
+	}
+	return nil, fmt.Errorf("definition not found: %s", ref)
+}
+
+func asSchema(s interface{}) map[string]interface{} {
+	schema, _ := s.(map[string]interface{})
+	return schema
+}
+
+func isType(t string, v interface{}) bool {
+	switch t {
+	case "object":
+		_, ok := v.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := v.([]interface{})
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "null":
+		return v == nil
+	}
+	return false
+}
+
+func number(v interface{}) (float64, bool) {
+	f, ok := v.(float64)
+	return f, ok
+}
+
+func equal(a, b interface{}) bool {
+	return jsonString(a) == jsonString(b)
+}
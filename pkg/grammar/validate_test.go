@@ -0,0 +1,80 @@
+package grammar_test
+
+import (
+	"encoding/json"
+
+	. "github.com/go-skynet/LocalAI/pkg/grammar"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const testSchema = `{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string", "minLength": 1},
+		"age": {"type": "integer", "minimum": 0},
+		"role": {"enum": ["admin", "user"]},
+		"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2},
+		"address": {"$ref": "#/$defs/address"}
+	},
+	"required": ["name", "age"],
+	"additionalProperties": false,
+	"$defs": {
+		"address": {
+			"type": "object",
+			"properties": {"city": {"type": "string"}},
+			"required": ["city"]
+		}
+	}
+}`
+
+var _ = Describe("JSON schema validation", func() {
+	var schema map[string]interface{}
+
+	BeforeEach(func() {
+		Expect(json.Unmarshal([]byte(testSchema), &schema)).To(Succeed())
+	})
+
+	It("accepts the documents matching the schema", func() {
+		Expect(ValidateJSON(schema, []byte(`{"name": "Ada", "age": 36, "role": "admin", "tags": ["a"], "address": {"city": "London"}}`))).To(Succeed())
+		Expect(ValidateJSON(schema, []byte(` {"name": "Ada", "age": 36}
+`))).To(Succeed())
+	})
+
+	It("rejects the documents not matching the schema", func() {
+		for doc, msg := range map[string]string{
+			`{"name": "Ada"`:                                   "invalid JSON",
+			`{"name": "Ada"}`:                                  `$: missing required property "age"`,
+			`{"name": "", "age": 1}`:                           "$.name: must have at least 1 characters",
+			`{"name": "Ada", "age": 1.5}`:                      "$.age: must be of type integer",
+			`{"name": "Ada", "age": -1}`:                       "$.age: must be at least 0",
+			`{"name": "Ada", "age": 1, "role": "root"}`:        `$.role: must be one of ["admin","user"]`,
+			`{"name": "Ada", "age": 1, "tags": [1]}`:           "$.tags[0]: must be of type string",
+			`{"name": "Ada", "age": 1, "tags": ["a","b","c"]}`: "$.tags: must have at most 2 items",
+			`{"name": "Ada", "age": 1, "address": {}}`:         `$.address: missing required property "city"`,
+			`{"name": "Ada", "age": 1, "email": "a@b.c"}`:      "$.email: unexpected property",
+			`[]`: "$: must be of type object",
+		} {
+			err := ValidateJSON(schema, []byte(doc))
+			Expect(err).To(HaveOccurred(), doc)
+			Expect(err.Error()).To(ContainSubstring(msg), doc)
+		}
+	})
+
+	It("validates the alternatives", func() {
+		s := map[string]interface{}{}
+		Expect(json.Unmarshal([]byte(`{"oneOf": [{"type": "string"}, {"type": "number"}]}`), &s)).To(Succeed())
+		Expect(ValidateJSON(s, []byte(`"a"`))).To(Succeed())
+		Expect(ValidateJSON(s, []byte(`1`))).To(Succeed())
+		Expect(ValidateJSON(s, []byte(`true`))).ToNot(Succeed())
+	})
+
+	It("converts the schemas to grammars without panicking", func() {
+		g, err := SchemaGrammar(schema)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(g).To(ContainSubstring("root ::="))
+
+		_, err = SchemaGrammar(map[string]interface{}{"type": "unknown"})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -2,9 +2,12 @@ package grpc
 
 import (
 	"context"
+	"time"
+
 	"github.com/go-skynet/LocalAI/api/schema"
 	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
 )
 
 var embeds = map[string]*embedBackend{}
@@ -13,34 +16,76 @@ func Provide(addr string, llm LLM) {
 	embeds[addr] = &embedBackend{s: &server{llm: llm}}
 }
 
-func NewClient(address string, parallel bool, wd WatchDog, enableWatchDog bool) Backend {
+type ClientOption func(*Client)
+
+// MinKeepaliveTime is the shortest interval of the keepalive pings the backends accept: gRPC servers close the
+// connections of the clients pinging more often
+const MinKeepaliveTime = 5 * time.Second
+
+// WithKeepalive pings the backend every interval while a call is in progress, and fails the
+// call if the backend does not answer within timeout. The interval is at least MinKeepaliveTime.
+func WithKeepalive(interval, timeout time.Duration) ClientOption {
+	if interval < MinKeepaliveTime {
+		interval = MinKeepaliveTime
+	}
+	return func(c *Client) {
+		c.keepalive = &keepalive.ClientParameters{
+			Time:                interval,
+			Timeout:             timeout,
+			PermitWithoutStream: true,
+		}
+	}
+}
+
+// WithCallTimeout sets a deadline to every call made to the backend, except health checks, model loading and the
+// streamed predictions, which end with the request
+func WithCallTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.callTimeout = timeout
+	}
+}
+
+func NewClient(address string, parallel bool, wd WatchDog, enableWatchDog bool, opts ...ClientOption) Backend {
 	if bc, ok := embeds[address]; ok {
 		return bc
 	}
-	return NewGrpcClient(address, parallel, wd, enableWatchDog)
+	return NewGrpcClient(address, parallel, wd, enableWatchDog, opts...)
 }
 
-func NewGrpcClient(address string, parallel bool, wd WatchDog, enableWatchDog bool) Backend {
+func NewGrpcClient(address string, parallel bool, wd WatchDog, enableWatchDog bool, opts ...ClientOption) Backend {
 	if !enableWatchDog {
 		wd = nil
 	}
-	return &Client{
+	c := &Client{
 		address:  address,
 		parallel: parallel,
 		wd:       wd,
 	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
 }
 
 type Backend interface {
 	IsBusy() bool
 	HealthCheck(ctx context.Context) (bool, error)
 	Embeddings(ctx context.Context, in *pb.PredictOptions, opts ...grpc.CallOption) (*pb.EmbeddingResult, error)
+	EmbeddingBatch(ctx context.Context, in *pb.EmbeddingBatchRequest, f func(*pb.EmbeddingBatchResult), opts ...grpc.CallOption) error
 	Predict(ctx context.Context, in *pb.PredictOptions, opts ...grpc.CallOption) (*pb.Reply, error)
 	LoadModel(ctx context.Context, in *pb.ModelOptions, opts ...grpc.CallOption) (*pb.Result, error)
-	PredictStream(ctx context.Context, in *pb.PredictOptions, f func(s []byte), opts ...grpc.CallOption) error
+	PredictStream(ctx context.Context, in *pb.PredictOptions, f func(reply *pb.Reply), opts ...grpc.CallOption) error
 	GenerateImage(ctx context.Context, in *pb.GenerateImageRequest, opts ...grpc.CallOption) (*pb.Result, error)
+	GenerateImageStream(ctx context.Context, in *pb.GenerateImageRequest, f func(*pb.ImageProgress), opts ...grpc.CallOption) error
 	TTS(ctx context.Context, in *pb.TTSRequest, opts ...grpc.CallOption) (*pb.Result, error)
 	AudioTranscription(ctx context.Context, in *pb.TranscriptRequest, opts ...grpc.CallOption) (*schema.Result, error)
 	TokenizeString(ctx context.Context, in *pb.PredictOptions, opts ...grpc.CallOption) (*pb.TokenizationResponse, error)
+	Detokenize(ctx context.Context, in *pb.DetokenizationRequest, opts ...grpc.CallOption) (*pb.DetokenizationResponse, error)
 	Status(ctx context.Context) (*pb.StatusResponse, error)
+	Capabilities(ctx context.Context) (*pb.CapabilitiesResponse, error)
+	Classify(ctx context.Context, in *pb.ClassifyRequest, opts ...grpc.CallOption) (*pb.ClassifyResult, error)
+	Diarize(ctx context.Context, in *pb.DiarizeRequest, opts ...grpc.CallOption) (*pb.DiarizeResult, error)
+	VAD(ctx context.Context, in *pb.VADRequest, opts ...grpc.CallOption) (*pb.VADResult, error)
+	Upscale(ctx context.Context, in *pb.UpscaleRequest, opts ...grpc.CallOption) (*pb.Result, error)
+	Reconfigure(ctx context.Context, in *pb.ReconfigureRequest, opts ...grpc.CallOption) (*pb.Result, error)
 }
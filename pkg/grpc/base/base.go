@@ -65,6 +65,30 @@ func (llm *Base) TokenizeString(opts *pb.PredictOptions) (pb.TokenizationRespons
 	return pb.TokenizationResponse{}, fmt.Errorf("unimplemented")
 }
 
+func (llm *Base) Detokenize(*pb.DetokenizationRequest) (string, error) {
+	return "", fmt.Errorf("unimplemented")
+}
+
+func (llm *Base) Classify(*pb.ClassifyRequest) (pb.ClassifyResult, error) {
+	return pb.ClassifyResult{}, fmt.Errorf("unimplemented")
+}
+
+func (llm *Base) Diarize(*pb.DiarizeRequest) (pb.DiarizeResult, error) {
+	return pb.DiarizeResult{}, fmt.Errorf("unimplemented")
+}
+
+func (llm *Base) VAD(*pb.VADRequest) (pb.VADResult, error) {
+	return pb.VADResult{}, fmt.Errorf("unimplemented")
+}
+
+func (llm *Base) Upscale(*pb.UpscaleRequest) error {
+	return fmt.Errorf("unimplemented")
+}
+
+func (llm *Base) Reconfigure(*pb.ReconfigureRequest) error {
+	return fmt.Errorf("unimplemented")
+}
+
 // backends may wish to call this to capture the gopsutil info, then enhance with additional memory usage details?
 func (llm *Base) Status() (pb.StatusResponse, error) {
 	return pb.StatusResponse{
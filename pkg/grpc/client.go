@@ -11,6 +11,7 @@ import (
 	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
 )
 
 type Client struct {
@@ -20,6 +21,9 @@ type Client struct {
 	sync.Mutex
 	opMutex sync.Mutex
 	wd      WatchDog
+
+	keepalive   *keepalive.ClientParameters
+	callTimeout time.Duration
 }
 
 type WatchDog interface {
@@ -39,6 +43,25 @@ func (c *Client) setBusy(v bool) {
 	c.Unlock()
 }
 
+func (c *Client) dial() (*grpc.ClientConn, error) {
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if creds := credentialsOf(c.address); creds != nil {
+		opts = append(opts, creds.dialOptions()...)
+	}
+	if c.keepalive != nil {
+		opts = append(opts, grpc.WithKeepaliveParams(*c.keepalive))
+	}
+	return grpc.Dial(c.address, opts...)
+}
+
+// callContext applies the per-call deadline, if any, to ctx
+func (c *Client) callContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.callTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.callTimeout)
+}
+
 func (c *Client) HealthCheck(ctx context.Context) (bool, error) {
 	if !c.parallel {
 		c.opMutex.Lock()
@@ -46,7 +69,7 @@ func (c *Client) HealthCheck(ctx context.Context) (bool, error) {
 	}
 	c.setBusy(true)
 	defer c.setBusy(false)
-	conn, err := grpc.Dial(c.address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := c.dial()
 	if err != nil {
 		return false, err
 	}
@@ -80,13 +103,16 @@ func (c *Client) Embeddings(ctx context.Context, in *pb.PredictOptions, opts ...
 		c.wd.Mark(c.address)
 		defer c.wd.UnMark(c.address)
 	}
-	conn, err := grpc.Dial(c.address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := c.dial()
 	if err != nil {
 		return nil, err
 	}
 	defer conn.Close()
 	client := pb.NewBackendClient(conn)
 
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
+
 	return client.Embedding(ctx, in, opts...)
 }
 
@@ -101,13 +127,16 @@ func (c *Client) Predict(ctx context.Context, in *pb.PredictOptions, opts ...grp
 		c.wd.Mark(c.address)
 		defer c.wd.UnMark(c.address)
 	}
-	conn, err := grpc.Dial(c.address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := c.dial()
 	if err != nil {
 		return nil, err
 	}
 	defer conn.Close()
 	client := pb.NewBackendClient(conn)
 
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
+
 	return client.Predict(ctx, in, opts...)
 }
 
@@ -122,7 +151,7 @@ func (c *Client) LoadModel(ctx context.Context, in *pb.ModelOptions, opts ...grp
 		c.wd.Mark(c.address)
 		defer c.wd.UnMark(c.address)
 	}
-	conn, err := grpc.Dial(c.address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := c.dial()
 	if err != nil {
 		return nil, err
 	}
@@ -131,7 +160,7 @@ func (c *Client) LoadModel(ctx context.Context, in *pb.ModelOptions, opts ...grp
 	return client.LoadModel(ctx, in, opts...)
 }
 
-func (c *Client) PredictStream(ctx context.Context, in *pb.PredictOptions, f func(s []byte), opts ...grpc.CallOption) error {
+func (c *Client) PredictStream(ctx context.Context, in *pb.PredictOptions, f func(reply *pb.Reply), opts ...grpc.CallOption) error {
 	if !c.parallel {
 		c.opMutex.Lock()
 		defer c.opMutex.Unlock()
@@ -142,13 +171,14 @@ func (c *Client) PredictStream(ctx context.Context, in *pb.PredictOptions, f fun
 		c.wd.Mark(c.address)
 		defer c.wd.UnMark(c.address)
 	}
-	conn, err := grpc.Dial(c.address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := c.dial()
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 	client := pb.NewBackendClient(conn)
 
+	// the streams can last longer than the call timeout, they are stopped by the client going away
 	stream, err := client.PredictStream(ctx, in, opts...)
 	if err != nil {
 		return err
@@ -160,16 +190,62 @@ func (c *Client) PredictStream(ctx context.Context, in *pb.PredictOptions, f fun
 			break
 		}
 		if err != nil {
+			if ctx.Err() != nil {
+				// the request was cancelled, the backend stops generating on its own
+				return ctx.Err()
+			}
 			fmt.Println("Error", err)
 
 			return err
 		}
-		f(feature.GetMessage())
+		f(feature)
 	}
 
 	return nil
 }
 
+// EmbeddingBatch computes the embeddings of several inputs, f is called with each one as soon as it is computed
+func (c *Client) EmbeddingBatch(ctx context.Context, in *pb.EmbeddingBatchRequest, f func(*pb.EmbeddingBatchResult), opts ...grpc.CallOption) error {
+	if !c.parallel {
+		c.opMutex.Lock()
+		defer c.opMutex.Unlock()
+	}
+	c.setBusy(true)
+	defer c.setBusy(false)
+	if c.wd != nil {
+		c.wd.Mark(c.address)
+		defer c.wd.UnMark(c.address)
+	}
+	conn, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	client := pb.NewBackendClient(conn)
+
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
+
+	stream, err := client.EmbeddingBatch(ctx, in, opts...)
+	if err != nil {
+		return err
+	}
+
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		f(res)
+	}
+}
+
 func (c *Client) GenerateImage(ctx context.Context, in *pb.GenerateImageRequest, opts ...grpc.CallOption) (*pb.Result, error) {
 	if !c.parallel {
 		c.opMutex.Lock()
@@ -181,15 +257,60 @@ func (c *Client) GenerateImage(ctx context.Context, in *pb.GenerateImageRequest,
 		c.wd.Mark(c.address)
 		defer c.wd.UnMark(c.address)
 	}
-	conn, err := grpc.Dial(c.address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := c.dial()
 	if err != nil {
 		return nil, err
 	}
 	defer conn.Close()
 	client := pb.NewBackendClient(conn)
+
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
 	return client.GenerateImage(ctx, in, opts...)
 }
 
+// GenerateImageStream generates an image, f is called with the progress of the generation after each step
+func (c *Client) GenerateImageStream(ctx context.Context, in *pb.GenerateImageRequest, f func(*pb.ImageProgress), opts ...grpc.CallOption) error {
+	if !c.parallel {
+		c.opMutex.Lock()
+		defer c.opMutex.Unlock()
+	}
+	c.setBusy(true)
+	defer c.setBusy(false)
+	if c.wd != nil {
+		c.wd.Mark(c.address)
+		defer c.wd.UnMark(c.address)
+	}
+	conn, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	client := pb.NewBackendClient(conn)
+
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
+
+	stream, err := client.GenerateImageStream(ctx, in, opts...)
+	if err != nil {
+		return err
+	}
+
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		f(res)
+	}
+}
+
 func (c *Client) TTS(ctx context.Context, in *pb.TTSRequest, opts ...grpc.CallOption) (*pb.Result, error) {
 	if !c.parallel {
 		c.opMutex.Lock()
@@ -201,12 +322,15 @@ func (c *Client) TTS(ctx context.Context, in *pb.TTSRequest, opts ...grpc.CallOp
 		c.wd.Mark(c.address)
 		defer c.wd.UnMark(c.address)
 	}
-	conn, err := grpc.Dial(c.address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := c.dial()
 	if err != nil {
 		return nil, err
 	}
 	defer conn.Close()
 	client := pb.NewBackendClient(conn)
+
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
 	return client.TTS(ctx, in, opts...)
 }
 
@@ -221,12 +345,15 @@ func (c *Client) AudioTranscription(ctx context.Context, in *pb.TranscriptReques
 		c.wd.Mark(c.address)
 		defer c.wd.UnMark(c.address)
 	}
-	conn, err := grpc.Dial(c.address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := c.dial()
 	if err != nil {
 		return nil, err
 	}
 	defer conn.Close()
 	client := pb.NewBackendClient(conn)
+
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
 	res, err := client.AudioTranscription(ctx, in, opts...)
 	if err != nil {
 		return nil, err
@@ -239,14 +366,20 @@ func (c *Client) AudioTranscription(ctx context.Context, in *pb.TranscriptReques
 		}
 		tresult.Segments = append(tresult.Segments,
 			schema.Segment{
-				Text:   s.Text,
-				Id:     int(s.Id),
-				Start:  time.Duration(s.Start),
-				End:    time.Duration(s.End),
-				Tokens: tks,
+				Text:         s.Text,
+				Id:           int(s.Id),
+				Start:        time.Duration(s.Start),
+				End:          time.Duration(s.End),
+				Tokens:       tks,
+				NoSpeechProb: s.NoSpeechProb,
 			})
 	}
+	for _, w := range res.Words {
+		tresult.Words = append(tresult.Words, schema.Word{Word: w.Word, Start: time.Duration(w.Start), End: time.Duration(w.End)})
+	}
 	tresult.Text = res.Text
+	tresult.Language = res.Language
+	tresult.LanguageProbability = res.LanguageProbability
 	return tresult, err
 }
 
@@ -261,13 +394,16 @@ func (c *Client) TokenizeString(ctx context.Context, in *pb.PredictOptions, opts
 		c.wd.Mark(c.address)
 		defer c.wd.UnMark(c.address)
 	}
-	conn, err := grpc.Dial(c.address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := c.dial()
 	if err != nil {
 		return nil, err
 	}
 	defer conn.Close()
 	client := pb.NewBackendClient(conn)
 
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
+
 	res, err := client.TokenizeString(ctx, in, opts...)
 
 	if err != nil {
@@ -276,6 +412,30 @@ func (c *Client) TokenizeString(ctx context.Context, in *pb.PredictOptions, opts
 	return res, nil
 }
 
+func (c *Client) Detokenize(ctx context.Context, in *pb.DetokenizationRequest, opts ...grpc.CallOption) (*pb.DetokenizationResponse, error) {
+	if !c.parallel {
+		c.opMutex.Lock()
+		defer c.opMutex.Unlock()
+	}
+	c.setBusy(true)
+	defer c.setBusy(false)
+	if c.wd != nil {
+		c.wd.Mark(c.address)
+		defer c.wd.UnMark(c.address)
+	}
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	client := pb.NewBackendClient(conn)
+
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
+
+	return client.Detokenize(ctx, in, opts...)
+}
+
 func (c *Client) Status(ctx context.Context) (*pb.StatusResponse, error) {
 	if !c.parallel {
 		c.opMutex.Lock()
@@ -283,11 +443,149 @@ func (c *Client) Status(ctx context.Context) (*pb.StatusResponse, error) {
 	}
 	c.setBusy(true)
 	defer c.setBusy(false)
-	conn, err := grpc.Dial(c.address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := c.dial()
 	if err != nil {
 		return nil, err
 	}
 	defer conn.Close()
 	client := pb.NewBackendClient(conn)
+
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
 	return client.Status(ctx, &pb.HealthMessage{})
 }
+
+func (c *Client) Capabilities(ctx context.Context) (*pb.CapabilitiesResponse, error) {
+	if !c.parallel {
+		c.opMutex.Lock()
+		defer c.opMutex.Unlock()
+	}
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	client := pb.NewBackendClient(conn)
+
+	// like the health check, the handshake shouldn't take long time
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	return client.Capabilities(ctx, &pb.HealthMessage{})
+}
+
+func (c *Client) Classify(ctx context.Context, in *pb.ClassifyRequest, opts ...grpc.CallOption) (*pb.ClassifyResult, error) {
+	if !c.parallel {
+		c.opMutex.Lock()
+		defer c.opMutex.Unlock()
+	}
+	c.setBusy(true)
+	defer c.setBusy(false)
+	if c.wd != nil {
+		c.wd.Mark(c.address)
+		defer c.wd.UnMark(c.address)
+	}
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	client := pb.NewBackendClient(conn)
+
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
+	return client.Classify(ctx, in, opts...)
+}
+
+func (c *Client) Diarize(ctx context.Context, in *pb.DiarizeRequest, opts ...grpc.CallOption) (*pb.DiarizeResult, error) {
+	if !c.parallel {
+		c.opMutex.Lock()
+		defer c.opMutex.Unlock()
+	}
+	c.setBusy(true)
+	defer c.setBusy(false)
+	if c.wd != nil {
+		c.wd.Mark(c.address)
+		defer c.wd.UnMark(c.address)
+	}
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	client := pb.NewBackendClient(conn)
+
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
+	return client.Diarize(ctx, in, opts...)
+}
+
+func (c *Client) VAD(ctx context.Context, in *pb.VADRequest, opts ...grpc.CallOption) (*pb.VADResult, error) {
+	if !c.parallel {
+		c.opMutex.Lock()
+		defer c.opMutex.Unlock()
+	}
+	c.setBusy(true)
+	defer c.setBusy(false)
+	if c.wd != nil {
+		c.wd.Mark(c.address)
+		defer c.wd.UnMark(c.address)
+	}
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	client := pb.NewBackendClient(conn)
+
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
+	return client.VAD(ctx, in, opts...)
+}
+
+func (c *Client) Upscale(ctx context.Context, in *pb.UpscaleRequest, opts ...grpc.CallOption) (*pb.Result, error) {
+	if !c.parallel {
+		c.opMutex.Lock()
+		defer c.opMutex.Unlock()
+	}
+	c.setBusy(true)
+	defer c.setBusy(false)
+	if c.wd != nil {
+		c.wd.Mark(c.address)
+		defer c.wd.UnMark(c.address)
+	}
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	client := pb.NewBackendClient(conn)
+
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
+	return client.Upscale(ctx, in, opts...)
+}
+
+// Reconfigure changes the settings of the loaded model, the backend must be idle
+
+func (c *Client) Reconfigure(ctx context.Context, in *pb.ReconfigureRequest, opts ...grpc.CallOption) (*pb.Result, error) {
+	if !c.parallel {
+		c.opMutex.Lock()
+		defer c.opMutex.Unlock()
+	}
+	c.setBusy(true)
+	defer c.setBusy(false)
+	if c.wd != nil {
+		c.wd.Mark(c.address)
+		defer c.wd.UnMark(c.address)
+	}
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	client := pb.NewBackendClient(conn)
+
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
+	return client.Reconfigure(ctx, in, opts...)
+}
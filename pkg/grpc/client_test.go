@@ -0,0 +1,70 @@
+package grpc_test
+
+import (
+	"context"
+	"net"
+	"time"
+
+	. "github.com/go-skynet/LocalAI/pkg/grpc"
+	"github.com/go-skynet/LocalAI/pkg/grpc/base"
+	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// slow takes delay to predict, and to stream each token
+type slow struct {
+	base.Base
+	delay time.Duration
+}
+
+func (s *slow) Predict(*pb.PredictOptions) (string, error) {
+	time.Sleep(s.delay)
+	return "done", nil
+}
+
+func (s *slow) PredictStream(_ *pb.PredictOptions, results chan string) error {
+	defer close(results)
+	for _, t := range []string{"one", "two", "three"} {
+		time.Sleep(s.delay)
+		results <- t
+	}
+	return nil
+}
+
+var _ = Describe("call timeout", func() {
+	var address string
+
+	BeforeEach(func() {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		address = lis.Addr().String()
+		Expect(lis.Close()).To(Succeed())
+
+		go func() {
+			defer GinkgoRecover()
+			_ = StartServer(address, &slow{delay: 200 * time.Millisecond})
+		}()
+		Eventually(func() error {
+			conn, err := net.Dial("tcp", address)
+			if err == nil {
+				conn.Close()
+			}
+			return err
+		}).Should(Succeed())
+	})
+
+	It("fails the calls lasting longer", func() {
+		_, err := NewGrpcClient(address, false, nil, false, WithCallTimeout(100*time.Millisecond)).Predict(context.Background(), &pb.PredictOptions{})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("doesn't limit the streamed predictions", func() {
+		tokens := []string{}
+		err := NewGrpcClient(address, false, nil, false, WithCallTimeout(300*time.Millisecond)).PredictStream(context.Background(), &pb.PredictOptions{}, func(r *pb.Reply) {
+			tokens = append(tokens, string(r.Message))
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tokens).To(Equal([]string{"one", "two", "three"}))
+	})
+})
@@ -0,0 +1,177 @@
+package grpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// the environment variables of the credentials of the backends serving remote LocalAI instances
+const (
+	EnvServerCA    = "LOCALAI_BACKEND_TLS_CA"
+	EnvServerCert  = "LOCALAI_BACKEND_TLS_CERT"
+	EnvServerKey   = "LOCALAI_BACKEND_TLS_KEY"
+	EnvServerToken = "LOCALAI_BACKEND_TOKEN"
+)
+
+// Credentials authenticate the connections between LocalAI and a backend: with mutual TLS, both sides present a
+// certificate signed by the CA of the other, and the backend checks the shared token on every call.
+type Credentials struct {
+	tls   *tls.Config
+	token string
+}
+
+// NewClientCredentials returns the credentials of LocalAI connecting to remote backends. The certificate is presented
+// to the backends, whose certificate is verified with the CA (or the system CAs).
+func NewClientCredentials(ca, cert, key, token string) (*Credentials, error) {
+	c := &Credentials{token: token}
+	if ca == "" && cert == "" {
+		return c, nil
+	}
+	c.tls = &tls.Config{MinVersion: tls.VersionTLS12}
+	if err := c.load(ca, cert, key); err != nil {
+		return nil, err
+	}
+	c.tls.RootCAs = c.tls.ClientCAs
+	c.tls.ClientCAs = nil
+	return c, nil
+}
+
+// NewServerCredentials returns the credentials of a backend: it serves with the certificate, and with the CA only
+// accepts the clients presenting a certificate it signed.
+func NewServerCredentials(ca, cert, key, token string) (*Credentials, error) {
+	c := &Credentials{token: token}
+	if cert == "" {
+		if ca != "" {
+			return nil, fmt.Errorf("the certificate of the backend is required to verify the clients")
+		}
+		return c, nil
+	}
+	c.tls = &tls.Config{MinVersion: tls.VersionTLS12}
+	if err := c.load(ca, cert, key); err != nil {
+		return nil, err
+	}
+	if ca != "" {
+		c.tls.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return c, nil
+}
+
+// ServerCredentialsFromEnv returns the credentials of a backend given in its environment, nil without any
+func ServerCredentialsFromEnv() (*Credentials, error) {
+	ca, cert, key, token := os.Getenv(EnvServerCA), os.Getenv(EnvServerCert), os.Getenv(EnvServerKey), os.Getenv(EnvServerToken)
+	if ca == "" && cert == "" && token == "" {
+		return nil, nil
+	}
+	return NewServerCredentials(ca, cert, key, token)
+}
+
+// load reads the key pair and the CA, which is kept in ClientCAs
+func (c *Credentials) load(ca, cert, key string) error {
+	if cert != "" {
+		pair, err := tls.LoadX509KeyPair(cert, key)
+		if err != nil {
+			return fmt.Errorf("cannot load the certificate of the backends: %w", err)
+		}
+		c.tls.Certificates = []tls.Certificate{pair}
+	}
+	if ca != "" {
+		dat, err := os.ReadFile(ca)
+		if err != nil {
+			return fmt.Errorf("cannot read the CA of the backends: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(dat) {
+			return fmt.Errorf("no certificate found in the CA of the backends %s", ca)
+		}
+		c.tls.ClientCAs = pool
+	}
+	return nil
+}
+
+func (c *Credentials) dialOptions() []grpc.DialOption {
+	opts := []grpc.DialOption{}
+	if c.tls != nil {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(c.tls)))
+	}
+	if c.token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(tokenCredentials{token: c.token, secure: c.tls != nil}))
+	}
+	return opts
+}
+
+func (c *Credentials) serverOptions() []grpc.ServerOption {
+	opts := []grpc.ServerOption{}
+	if c.tls != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(c.tls)))
+	}
+	if c.token != "" {
+		opts = append(opts,
+			grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+				if err := c.checkToken(ctx); err != nil {
+					return nil, err
+				}
+				return handler(ctx, req)
+			}),
+			grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+				if err := c.checkToken(ss.Context()); err != nil {
+					return err
+				}
+				return handler(srv, ss)
+			}),
+		)
+	}
+	return opts
+}
+
+func (c *Credentials) checkToken(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		if subtle.ConstantTimeCompare([]byte(v), []byte("Bearer "+c.token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "invalid backend token")
+}
+
+// tokenCredentials sends the shared token with every call
+type tokenCredentials struct {
+	token  string
+	secure bool
+}
+
+func (t tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + t.token}, nil
+}
+
+func (t tokenCredentials) RequireTransportSecurity() bool {
+	return t.secure
+}
+
+var (
+	secured   = map[string]*Credentials{}
+	securedMu sync.Mutex
+)
+
+// Secure authenticates the connections of the clients of the backend at the address with the credentials
+func Secure(address string, c *Credentials) {
+	securedMu.Lock()
+	defer securedMu.Unlock()
+	secured[address] = c
+}
+
+func credentialsOf(address string) *Credentials {
+	securedMu.Lock()
+	defer securedMu.Unlock()
+	return secured[address]
+}
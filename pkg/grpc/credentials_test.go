@@ -0,0 +1,71 @@
+package grpc_test
+
+import (
+	"context"
+	"net"
+
+	. "github.com/go-skynet/LocalAI/pkg/grpc"
+	"github.com/go-skynet/LocalAI/pkg/grpc/base"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type healthy struct {
+	base.Base
+}
+
+var _ = Describe("backend credentials", func() {
+	var address string
+
+	BeforeEach(func() {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		address = lis.Addr().String()
+		Expect(lis.Close()).To(Succeed())
+
+		GinkgoT().Setenv(EnvServerToken, "right")
+		go func() {
+			defer GinkgoRecover()
+			_ = StartServer(address, &healthy{})
+		}()
+		Eventually(func() error {
+			conn, err := net.Dial("tcp", address)
+			if err == nil {
+				conn.Close()
+			}
+			return err
+		}).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		Secure(address, nil)
+	})
+
+	It("accepts the calls with the token of the backend", func() {
+		c, err := NewClientCredentials("", "", "", "right")
+		Expect(err).ToNot(HaveOccurred())
+		Secure(address, c)
+
+		ok, err := NewGrpcClient(address, false, nil, false).HealthCheck(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+	})
+
+	It("rejects the calls with another token", func() {
+		c, err := NewClientCredentials("", "", "", "wrong")
+		Expect(err).ToNot(HaveOccurred())
+		Secure(address, c)
+
+		ok, err := NewGrpcClient(address, false, nil, false).HealthCheck(context.Background())
+		Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+		Expect(ok).To(BeFalse())
+	})
+
+	It("rejects the calls without a token", func() {
+		ok, err := NewGrpcClient(address, false, nil, false).HealthCheck(context.Background())
+		Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+		Expect(ok).To(BeFalse())
+	})
+})
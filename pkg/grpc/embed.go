@@ -11,6 +11,8 @@ import (
 
 var _ Backend = new(embedBackend)
 var _ pb.Backend_PredictStreamServer = new(embedBackendServerStream)
+var _ pb.Backend_EmbeddingBatchServer = new(embedBackendBatchStream)
+var _ pb.Backend_GenerateImageStreamServer = new(embedBackendImageStream)
 
 type embedBackend struct {
 	s *server
@@ -28,6 +30,10 @@ func (e *embedBackend) Embeddings(ctx context.Context, in *pb.PredictOptions, op
 	return e.s.Embedding(ctx, in)
 }
 
+func (e *embedBackend) EmbeddingBatch(ctx context.Context, in *pb.EmbeddingBatchRequest, f func(*pb.EmbeddingBatchResult), opts ...grpc.CallOption) error {
+	return e.s.EmbeddingBatch(in, &embedBackendBatchStream{embedBackendServerStream: embedBackendServerStream{ctx: ctx}, fn: f})
+}
+
 func (e *embedBackend) Predict(ctx context.Context, in *pb.PredictOptions, opts ...grpc.CallOption) (*pb.Reply, error) {
 	return e.s.Predict(ctx, in)
 }
@@ -36,7 +42,7 @@ func (e *embedBackend) LoadModel(ctx context.Context, in *pb.ModelOptions, opts
 	return e.s.LoadModel(ctx, in)
 }
 
-func (e *embedBackend) PredictStream(ctx context.Context, in *pb.PredictOptions, f func(s []byte), opts ...grpc.CallOption) error {
+func (e *embedBackend) PredictStream(ctx context.Context, in *pb.PredictOptions, f func(reply *pb.Reply), opts ...grpc.CallOption) error {
 	bs := &embedBackendServerStream{
 		ctx: ctx,
 		fn:  f,
@@ -48,6 +54,10 @@ func (e *embedBackend) GenerateImage(ctx context.Context, in *pb.GenerateImageRe
 	return e.s.GenerateImage(ctx, in)
 }
 
+func (e *embedBackend) GenerateImageStream(ctx context.Context, in *pb.GenerateImageRequest, f func(*pb.ImageProgress), opts ...grpc.CallOption) error {
+	return e.s.GenerateImageStream(in, &embedBackendImageStream{embedBackendServerStream: embedBackendServerStream{ctx: ctx}, fn: f})
+}
+
 func (e *embedBackend) TTS(ctx context.Context, in *pb.TTSRequest, opts ...grpc.CallOption) (*pb.Result, error) {
 	return e.s.TTS(ctx, in)
 }
@@ -65,14 +75,20 @@ func (e *embedBackend) AudioTranscription(ctx context.Context, in *pb.Transcript
 		}
 		tr.Segments = append(tr.Segments,
 			schema.Segment{
-				Text:   s.Text,
-				Id:     int(s.Id),
-				Start:  time.Duration(s.Start),
-				End:    time.Duration(s.End),
-				Tokens: tks,
+				Text:         s.Text,
+				Id:           int(s.Id),
+				Start:        time.Duration(s.Start),
+				End:          time.Duration(s.End),
+				Tokens:       tks,
+				NoSpeechProb: s.NoSpeechProb,
 			})
 	}
+	for _, w := range r.Words {
+		tr.Words = append(tr.Words, schema.Word{Word: w.Word, Start: time.Duration(w.Start), End: time.Duration(w.End)})
+	}
 	tr.Text = r.Text
+	tr.Language = r.Language
+	tr.LanguageProbability = r.LanguageProbability
 	return tr, err
 }
 
@@ -80,17 +96,45 @@ func (e *embedBackend) TokenizeString(ctx context.Context, in *pb.PredictOptions
 	return e.s.TokenizeString(ctx, in)
 }
 
+func (e *embedBackend) Detokenize(ctx context.Context, in *pb.DetokenizationRequest, opts ...grpc.CallOption) (*pb.DetokenizationResponse, error) {
+	return e.s.Detokenize(ctx, in)
+}
+
 func (e *embedBackend) Status(ctx context.Context) (*pb.StatusResponse, error) {
 	return e.s.Status(ctx, &pb.HealthMessage{})
 }
 
+func (e *embedBackend) Capabilities(ctx context.Context) (*pb.CapabilitiesResponse, error) {
+	return e.s.Capabilities(ctx, &pb.HealthMessage{})
+}
+
+func (e *embedBackend) Classify(ctx context.Context, in *pb.ClassifyRequest, opts ...grpc.CallOption) (*pb.ClassifyResult, error) {
+	return e.s.Classify(ctx, in)
+}
+
+func (e *embedBackend) Diarize(ctx context.Context, in *pb.DiarizeRequest, opts ...grpc.CallOption) (*pb.DiarizeResult, error) {
+	return e.s.Diarize(ctx, in)
+}
+
+func (e *embedBackend) VAD(ctx context.Context, in *pb.VADRequest, opts ...grpc.CallOption) (*pb.VADResult, error) {
+	return e.s.VAD(ctx, in)
+}
+
+func (e *embedBackend) Upscale(ctx context.Context, in *pb.UpscaleRequest, opts ...grpc.CallOption) (*pb.Result, error) {
+	return e.s.Upscale(ctx, in)
+}
+
+func (e *embedBackend) Reconfigure(ctx context.Context, in *pb.ReconfigureRequest, opts ...grpc.CallOption) (*pb.Result, error) {
+	return e.s.Reconfigure(ctx, in)
+}
+
 type embedBackendServerStream struct {
 	ctx context.Context
-	fn  func(s []byte)
+	fn  func(reply *pb.Reply)
 }
 
 func (e *embedBackendServerStream) Send(reply *pb.Reply) error {
-	e.fn(reply.GetMessage())
+	e.fn(reply)
 	return nil
 }
 
@@ -119,3 +163,37 @@ func (e *embedBackendServerStream) SendMsg(m any) error {
 func (e *embedBackendServerStream) RecvMsg(m any) error {
 	return nil
 }
+
+type embedBackendBatchStream struct {
+	embedBackendServerStream
+	fn func(*pb.EmbeddingBatchResult)
+}
+
+func (e *embedBackendBatchStream) Send(res *pb.EmbeddingBatchResult) error {
+	e.fn(res)
+	return nil
+}
+
+func (e *embedBackendBatchStream) SendMsg(m any) error {
+	if x, ok := m.(*pb.EmbeddingBatchResult); ok {
+		return e.Send(x)
+	}
+	return nil
+}
+
+type embedBackendImageStream struct {
+	embedBackendServerStream
+	fn func(*pb.ImageProgress)
+}
+
+func (e *embedBackendImageStream) Send(res *pb.ImageProgress) error {
+	e.fn(res)
+	return nil
+}
+
+func (e *embedBackendImageStream) SendMsg(m any) error {
+	if x, ok := m.(*pb.ImageProgress); ok {
+		return e.Send(x)
+	}
+	return nil
+}
@@ -18,9 +18,21 @@ type LLM interface {
 	AudioTranscription(*pb.TranscriptRequest) (schema.Result, error)
 	TTS(*pb.TTSRequest) error
 	TokenizeString(*pb.PredictOptions) (pb.TokenizationResponse, error)
+	Detokenize(*pb.DetokenizationRequest) (string, error)
+	Classify(*pb.ClassifyRequest) (pb.ClassifyResult, error)
+	Diarize(*pb.DiarizeRequest) (pb.DiarizeResult, error)
+	VAD(*pb.VADRequest) (pb.VADResult, error)
+	Upscale(*pb.UpscaleRequest) error
+	Reconfigure(*pb.ReconfigureRequest) error
 	Status() (pb.StatusResponse, error)
 }
 
+// ImageProgressProvider can be implemented by an LLM to report the progress of the image generations, progress is
+// called after each step of the generation
+type ImageProgressProvider interface {
+	GenerateImageProgress(in *pb.GenerateImageRequest, progress func(*pb.ImageProgress) error) error
+}
+
 func newReply(s string) *pb.Reply {
 	return &pb.Reply{Message: []byte(s)}
 }
@@ -31,7 +31,16 @@ type BackendClient interface {
 	AudioTranscription(ctx context.Context, in *TranscriptRequest, opts ...grpc.CallOption) (*TranscriptResult, error)
 	TTS(ctx context.Context, in *TTSRequest, opts ...grpc.CallOption) (*Result, error)
 	TokenizeString(ctx context.Context, in *PredictOptions, opts ...grpc.CallOption) (*TokenizationResponse, error)
+	Detokenize(ctx context.Context, in *DetokenizationRequest, opts ...grpc.CallOption) (*DetokenizationResponse, error)
 	Status(ctx context.Context, in *HealthMessage, opts ...grpc.CallOption) (*StatusResponse, error)
+	Capabilities(ctx context.Context, in *HealthMessage, opts ...grpc.CallOption) (*CapabilitiesResponse, error)
+	Classify(ctx context.Context, in *ClassifyRequest, opts ...grpc.CallOption) (*ClassifyResult, error)
+	Reconfigure(ctx context.Context, in *ReconfigureRequest, opts ...grpc.CallOption) (*Result, error)
+	EmbeddingBatch(ctx context.Context, in *EmbeddingBatchRequest, opts ...grpc.CallOption) (Backend_EmbeddingBatchClient, error)
+	Diarize(ctx context.Context, in *DiarizeRequest, opts ...grpc.CallOption) (*DiarizeResult, error)
+	VAD(ctx context.Context, in *VADRequest, opts ...grpc.CallOption) (*VADResult, error)
+	Upscale(ctx context.Context, in *UpscaleRequest, opts ...grpc.CallOption) (*Result, error)
+	GenerateImageStream(ctx context.Context, in *GenerateImageRequest, opts ...grpc.CallOption) (Backend_GenerateImageStreamClient, error)
 }
 
 type backendClient struct {
@@ -146,6 +155,15 @@ func (c *backendClient) TokenizeString(ctx context.Context, in *PredictOptions,
 	return out, nil
 }
 
+func (c *backendClient) Detokenize(ctx context.Context, in *DetokenizationRequest, opts ...grpc.CallOption) (*DetokenizationResponse, error) {
+	out := new(DetokenizationResponse)
+	err := c.cc.Invoke(ctx, "/backend.Backend/Detokenize", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *backendClient) Status(ctx context.Context, in *HealthMessage, opts ...grpc.CallOption) (*StatusResponse, error) {
 	out := new(StatusResponse)
 	err := c.cc.Invoke(ctx, "/backend.Backend/Status", in, out, opts...)
@@ -155,6 +173,124 @@ func (c *backendClient) Status(ctx context.Context, in *HealthMessage, opts ...g
 	return out, nil
 }
 
+func (c *backendClient) Capabilities(ctx context.Context, in *HealthMessage, opts ...grpc.CallOption) (*CapabilitiesResponse, error) {
+	out := new(CapabilitiesResponse)
+	err := c.cc.Invoke(ctx, "/backend.Backend/Capabilities", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backendClient) Classify(ctx context.Context, in *ClassifyRequest, opts ...grpc.CallOption) (*ClassifyResult, error) {
+	out := new(ClassifyResult)
+	err := c.cc.Invoke(ctx, "/backend.Backend/Classify", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backendClient) Reconfigure(ctx context.Context, in *ReconfigureRequest, opts ...grpc.CallOption) (*Result, error) {
+	out := new(Result)
+	err := c.cc.Invoke(ctx, "/backend.Backend/Reconfigure", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backendClient) EmbeddingBatch(ctx context.Context, in *EmbeddingBatchRequest, opts ...grpc.CallOption) (Backend_EmbeddingBatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Backend_ServiceDesc.Streams[1], "/backend.Backend/EmbeddingBatch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &backendEmbeddingBatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Backend_EmbeddingBatchClient interface {
+	Recv() (*EmbeddingBatchResult, error)
+	grpc.ClientStream
+}
+
+type backendEmbeddingBatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *backendEmbeddingBatchClient) Recv() (*EmbeddingBatchResult, error) {
+	m := new(EmbeddingBatchResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *backendClient) Diarize(ctx context.Context, in *DiarizeRequest, opts ...grpc.CallOption) (*DiarizeResult, error) {
+	out := new(DiarizeResult)
+	err := c.cc.Invoke(ctx, "/backend.Backend/Diarize", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backendClient) VAD(ctx context.Context, in *VADRequest, opts ...grpc.CallOption) (*VADResult, error) {
+	out := new(VADResult)
+	err := c.cc.Invoke(ctx, "/backend.Backend/VAD", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backendClient) Upscale(ctx context.Context, in *UpscaleRequest, opts ...grpc.CallOption) (*Result, error) {
+	out := new(Result)
+	err := c.cc.Invoke(ctx, "/backend.Backend/Upscale", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backendClient) GenerateImageStream(ctx context.Context, in *GenerateImageRequest, opts ...grpc.CallOption) (Backend_GenerateImageStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Backend_ServiceDesc.Streams[2], "/backend.Backend/GenerateImageStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &backendGenerateImageStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Backend_GenerateImageStreamClient interface {
+	Recv() (*ImageProgress, error)
+	grpc.ClientStream
+}
+
+type backendGenerateImageStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *backendGenerateImageStreamClient) Recv() (*ImageProgress, error) {
+	m := new(ImageProgress)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // BackendServer is the server API for Backend service.
 // All implementations must embed UnimplementedBackendServer
 // for forward compatibility
@@ -168,7 +304,16 @@ type BackendServer interface {
 	AudioTranscription(context.Context, *TranscriptRequest) (*TranscriptResult, error)
 	TTS(context.Context, *TTSRequest) (*Result, error)
 	TokenizeString(context.Context, *PredictOptions) (*TokenizationResponse, error)
+	Detokenize(context.Context, *DetokenizationRequest) (*DetokenizationResponse, error)
 	Status(context.Context, *HealthMessage) (*StatusResponse, error)
+	Capabilities(context.Context, *HealthMessage) (*CapabilitiesResponse, error)
+	Classify(context.Context, *ClassifyRequest) (*ClassifyResult, error)
+	Reconfigure(context.Context, *ReconfigureRequest) (*Result, error)
+	EmbeddingBatch(*EmbeddingBatchRequest, Backend_EmbeddingBatchServer) error
+	Diarize(context.Context, *DiarizeRequest) (*DiarizeResult, error)
+	VAD(context.Context, *VADRequest) (*VADResult, error)
+	Upscale(context.Context, *UpscaleRequest) (*Result, error)
+	GenerateImageStream(*GenerateImageRequest, Backend_GenerateImageStreamServer) error
 	mustEmbedUnimplementedBackendServer()
 }
 
@@ -203,9 +348,36 @@ func (UnimplementedBackendServer) TTS(context.Context, *TTSRequest) (*Result, er
 func (UnimplementedBackendServer) TokenizeString(context.Context, *PredictOptions) (*TokenizationResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method TokenizeString not implemented")
 }
+func (UnimplementedBackendServer) Detokenize(context.Context, *DetokenizationRequest) (*DetokenizationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Detokenize not implemented")
+}
 func (UnimplementedBackendServer) Status(context.Context, *HealthMessage) (*StatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
 }
+func (UnimplementedBackendServer) Capabilities(context.Context, *HealthMessage) (*CapabilitiesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Capabilities not implemented")
+}
+func (UnimplementedBackendServer) Classify(context.Context, *ClassifyRequest) (*ClassifyResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Classify not implemented")
+}
+func (UnimplementedBackendServer) Reconfigure(context.Context, *ReconfigureRequest) (*Result, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reconfigure not implemented")
+}
+func (UnimplementedBackendServer) EmbeddingBatch(*EmbeddingBatchRequest, Backend_EmbeddingBatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method EmbeddingBatch not implemented")
+}
+func (UnimplementedBackendServer) Diarize(context.Context, *DiarizeRequest) (*DiarizeResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Diarize not implemented")
+}
+func (UnimplementedBackendServer) VAD(context.Context, *VADRequest) (*VADResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VAD not implemented")
+}
+func (UnimplementedBackendServer) Upscale(context.Context, *UpscaleRequest) (*Result, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Upscale not implemented")
+}
+func (UnimplementedBackendServer) GenerateImageStream(*GenerateImageRequest, Backend_GenerateImageStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method GenerateImageStream not implemented")
+}
 func (UnimplementedBackendServer) mustEmbedUnimplementedBackendServer() {}
 
 // UnsafeBackendServer may be embedded to opt out of forward compatibility for this service.
@@ -384,6 +556,24 @@ func _Backend_TokenizeString_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Backend_Detokenize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DetokenizationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).Detokenize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/backend.Backend/Detokenize",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackendServer).Detokenize(ctx, req.(*DetokenizationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Backend_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(HealthMessage)
 	if err := dec(in); err != nil {
@@ -402,6 +592,156 @@ func _Backend_Status_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Backend_Capabilities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).Capabilities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/backend.Backend/Capabilities",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackendServer).Capabilities(ctx, req.(*HealthMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Backend_Classify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClassifyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).Classify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/backend.Backend/Classify",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackendServer).Classify(ctx, req.(*ClassifyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Backend_Reconfigure_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReconfigureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).Reconfigure(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/backend.Backend/Reconfigure",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackendServer).Reconfigure(ctx, req.(*ReconfigureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Backend_EmbeddingBatch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(EmbeddingBatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BackendServer).EmbeddingBatch(m, &backendEmbeddingBatchServer{stream})
+}
+
+type Backend_EmbeddingBatchServer interface {
+	Send(*EmbeddingBatchResult) error
+	grpc.ServerStream
+}
+
+type backendEmbeddingBatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *backendEmbeddingBatchServer) Send(m *EmbeddingBatchResult) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Backend_Diarize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiarizeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).Diarize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/backend.Backend/Diarize",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackendServer).Diarize(ctx, req.(*DiarizeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Backend_VAD_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VADRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).VAD(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/backend.Backend/VAD",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackendServer).VAD(ctx, req.(*VADRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Backend_Upscale_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpscaleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).Upscale(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/backend.Backend/Upscale",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackendServer).Upscale(ctx, req.(*UpscaleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Backend_GenerateImageStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GenerateImageRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BackendServer).GenerateImageStream(m, &backendGenerateImageStreamServer{stream})
+}
+
+type Backend_GenerateImageStreamServer interface {
+	Send(*ImageProgress) error
+	grpc.ServerStream
+}
+
+type backendGenerateImageStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *backendGenerateImageStreamServer) Send(m *ImageProgress) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // Backend_ServiceDesc is the grpc.ServiceDesc for Backend service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -441,10 +781,38 @@ var Backend_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "TokenizeString",
 			Handler:    _Backend_TokenizeString_Handler,
 		},
+		{
+			MethodName: "Detokenize",
+			Handler:    _Backend_Detokenize_Handler,
+		},
 		{
 			MethodName: "Status",
 			Handler:    _Backend_Status_Handler,
 		},
+		{
+			MethodName: "Capabilities",
+			Handler:    _Backend_Capabilities_Handler,
+		},
+		{
+			MethodName: "Classify",
+			Handler:    _Backend_Classify_Handler,
+		},
+		{
+			MethodName: "Reconfigure",
+			Handler:    _Backend_Reconfigure_Handler,
+		},
+		{
+			MethodName: "Diarize",
+			Handler:    _Backend_Diarize_Handler,
+		},
+		{
+			MethodName: "VAD",
+			Handler:    _Backend_VAD_Handler,
+		},
+		{
+			MethodName: "Upscale",
+			Handler:    _Backend_Upscale_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -452,6 +820,16 @@ var Backend_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Backend_PredictStream_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "EmbeddingBatch",
+			Handler:       _Backend_EmbeddingBatch_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GenerateImageStream",
+			Handler:       _Backend_GenerateImageStream_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "backend.proto",
 }
@@ -8,6 +8,8 @@ import (
 
 	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/protobuf/proto"
 )
 
 // A GRPC Server that allows to run LLM inference.
@@ -75,6 +77,21 @@ func (s *server) GenerateImage(ctx context.Context, in *pb.GenerateImageRequest)
 	return &pb.Result{Message: "Image generated", Success: true}, nil
 }
 
+// GenerateImageStream generates an image and sends its progress after each step, if the LLM reports it. The stream
+// ends once the image is generated.
+func (s *server) GenerateImageStream(in *pb.GenerateImageRequest, stream pb.Backend_GenerateImageStreamServer) error {
+	p, ok := s.llm.(ImageProgressProvider)
+	if !ok {
+		_, err := s.GenerateImage(stream.Context(), in)
+		return err
+	}
+	if s.llm.Locking() {
+		s.llm.Lock()
+		defer s.llm.Unlock()
+	}
+	return p.GenerateImageProgress(in, stream.Send)
+}
+
 func (s *server) TTS(ctx context.Context, in *pb.TTSRequest) (*pb.Result, error) {
 	if s.llm.Locking() {
 		s.llm.Lock()
@@ -104,15 +121,21 @@ func (s *server) AudioTranscription(ctx context.Context, in *pb.TranscriptReques
 		}
 		tresult.Segments = append(tresult.Segments,
 			&pb.TranscriptSegment{
-				Text:   s.Text,
-				Id:     int32(s.Id),
-				Start:  int64(s.Start),
-				End:    int64(s.End),
-				Tokens: tks,
+				Text:         s.Text,
+				Id:           int32(s.Id),
+				Start:        int64(s.Start),
+				End:          int64(s.End),
+				Tokens:       tks,
+				NoSpeechProb: s.NoSpeechProb,
 			})
 	}
+	for _, w := range result.Words {
+		tresult.Words = append(tresult.Words, &pb.TranscriptWord{Word: w.Word, Start: int64(w.Start), End: int64(w.End)})
+	}
 
 	tresult.Text = result.Text
+	tresult.Language = result.Language
+	tresult.LanguageProbability = result.LanguageProbability
 	return tresult, nil
 }
 
@@ -126,6 +149,11 @@ func (s *server) PredictStream(in *pb.PredictOptions, stream pb.Backend_PredictS
 	done := make(chan bool)
 	go func() {
 		for result := range resultChan {
+			// once the client is gone there is nobody to send to, but the
+			// channel still has to be drained until the backend finishes
+			if stream.Context().Err() != nil {
+				continue
+			}
 			stream.Send(newReply(result))
 		}
 		done <- true
@@ -158,6 +186,115 @@ func (s *server) TokenizeString(ctx context.Context, in *pb.PredictOptions) (*pb
 	}, err
 }
 
+func (s *server) Detokenize(ctx context.Context, in *pb.DetokenizationRequest) (*pb.DetokenizationResponse, error) {
+	if s.llm.Locking() {
+		s.llm.Lock()
+		defer s.llm.Unlock()
+	}
+	content, err := s.llm.Detokenize(in)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.DetokenizationResponse{Content: content}, nil
+}
+
+func (s *server) Classify(ctx context.Context, in *pb.ClassifyRequest) (*pb.ClassifyResult, error) {
+	if s.llm.Locking() {
+		s.llm.Lock()
+		defer s.llm.Unlock()
+	}
+	res, err := s.llm.Classify(in)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (s *server) Diarize(ctx context.Context, in *pb.DiarizeRequest) (*pb.DiarizeResult, error) {
+	if s.llm.Locking() {
+		s.llm.Lock()
+		defer s.llm.Unlock()
+	}
+	res, err := s.llm.Diarize(in)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (s *server) VAD(ctx context.Context, in *pb.VADRequest) (*pb.VADResult, error) {
+	if s.llm.Locking() {
+		s.llm.Lock()
+		defer s.llm.Unlock()
+	}
+	res, err := s.llm.VAD(in)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (s *server) Upscale(ctx context.Context, in *pb.UpscaleRequest) (*pb.Result, error) {
+	if s.llm.Locking() {
+		s.llm.Lock()
+		defer s.llm.Unlock()
+	}
+	err := s.llm.Upscale(in)
+	if err != nil {
+		return &pb.Result{Message: fmt.Sprintf("Error upscaling image: %s", err.Error()), Success: false}, err
+	}
+	return &pb.Result{Message: "Image upscaled", Success: true}, nil
+}
+
+func (s *server) Reconfigure(ctx context.Context, in *pb.ReconfigureRequest) (*pb.Result, error) {
+	if s.llm.Locking() {
+		s.llm.Lock()
+		defer s.llm.Unlock()
+	}
+	if err := s.llm.Reconfigure(in); err != nil {
+		return &pb.Result{Message: err.Error(), Success: false}, err
+	}
+	return &pb.Result{Message: "Reconfigured", Success: true}, nil
+}
+
+// EmbeddingBatch computes the embeddings of the inputs one after the other, and sends each one once computed
+func (s *server) EmbeddingBatch(in *pb.EmbeddingBatchRequest, stream pb.Backend_EmbeddingBatchServer) error {
+	embed := func(index int, opts *pb.PredictOptions) error {
+		if err := stream.Context().Err(); err != nil {
+			return err
+		}
+		res, err := s.Embedding(stream.Context(), opts)
+		if err != nil {
+			return err
+		}
+		return stream.Send(&pb.EmbeddingBatchResult{Index: int32(index), Result: res})
+	}
+
+	for i, input := range in.Inputs {
+		opts := s.batchOptions(in)
+		opts.Embeddings = input
+		if err := embed(i, opts); err != nil {
+			return err
+		}
+	}
+	for i, tokens := range in.Tokens {
+		opts := s.batchOptions(in)
+		opts.EmbeddingTokens = tokens.Tokens
+		if err := embed(len(in.Inputs)+i, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// batchOptions returns a copy of the options of the batch, for one of its inputs
+func (s *server) batchOptions(in *pb.EmbeddingBatchRequest) *pb.PredictOptions {
+	if in.Options == nil {
+		return &pb.PredictOptions{}
+	}
+	return proto.Clone(in.Options).(*pb.PredictOptions)
+}
+
 func (s *server) Status(ctx context.Context, in *pb.HealthMessage) (*pb.StatusResponse, error) {
 	res, err := s.llm.Status()
 	if err != nil {
@@ -167,12 +304,42 @@ func (s *server) Status(ctx context.Context, in *pb.HealthMessage) (*pb.StatusRe
 	return &res, nil
 }
 
+func (s *server) Capabilities(ctx context.Context, in *pb.HealthMessage) (*pb.CapabilitiesResponse, error) {
+	res := &pb.CapabilitiesResponse{ProtocolVersion: ProtocolVersion}
+	if p, ok := s.llm.(CapabilitiesProvider); ok {
+		res.Capabilities = p.Capabilities()
+	}
+	return res, nil
+}
+
+func serverOptions() ([]grpc.ServerOption, error) {
+	// allow the clients to send keepalive pings often, see WithKeepalive
+	opts := []grpc.ServerOption{
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             MinKeepaliveTime,
+			PermitWithoutStream: true,
+		}),
+	}
+	creds, err := ServerCredentialsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if creds != nil {
+		opts = append(opts, creds.serverOptions()...)
+	}
+	return opts, nil
+}
+
 func StartServer(address string, model LLM) error {
+	opts, err := serverOptions()
+	if err != nil {
+		return err
+	}
 	lis, err := net.Listen("tcp", address)
 	if err != nil {
 		return err
 	}
-	s := grpc.NewServer()
+	s := grpc.NewServer(opts...)
 	pb.RegisterBackendServer(s, &server{llm: model})
 	log.Printf("gRPC Server listening at %v", lis.Addr())
 	if err := s.Serve(lis); err != nil {
@@ -183,11 +350,15 @@ func StartServer(address string, model LLM) error {
 }
 
 func RunServer(address string, model LLM) (func() error, error) {
+	opts, err := serverOptions()
+	if err != nil {
+		return nil, err
+	}
 	lis, err := net.Listen("tcp", address)
 	if err != nil {
 		return nil, err
 	}
-	s := grpc.NewServer()
+	s := grpc.NewServer(opts...)
 	pb.RegisterBackendServer(s, &server{llm: model})
 	log.Printf("gRPC Server listening at %v", lis.Addr())
 	if err = s.Serve(lis); err != nil {
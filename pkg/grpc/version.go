@@ -0,0 +1,91 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ProtocolVersion is the version of backend.proto spoken by LocalAI and by the backends built along with it.
+// It must be bumped on every change of the protocol which LocalAI relies on.
+const ProtocolVersion = 22
+
+// TranslationProtocolVersion is the protocol version which added the translation of the transcriptions: the older
+// backends ignore it and transcribe in the language of the audio
+const TranslationProtocolVersion = 22
+
+// MinProtocolVersion is the oldest protocol version of the backends which LocalAI can still talk to.
+// Backends which predate the Capabilities RPC speak version 0.
+const MinProtocolVersion = 0
+
+// Names of the capabilities reported by the backends
+const (
+	CapabilityPredict        = "predict"
+	CapabilityPredictStream  = "predict_stream"
+	CapabilityEmbeddings     = "embeddings"
+	CapabilityImage          = "image"
+	CapabilityTranscription  = "transcription"
+	CapabilityTTS            = "tts"
+	CapabilityTokenize       = "tokenize"
+	CapabilityDetokenize     = "detokenize"
+	CapabilityStatus         = "status"
+	CapabilityClassify       = "classify"
+	CapabilityReconfigure    = "reconfigure"
+	CapabilityEmbeddingBatch = "embedding_batch"
+	CapabilityDiarize        = "diarize"
+	CapabilityVAD            = "vad"
+	CapabilityUpscale        = "upscale"
+	// CapabilityInpaint is reported by the image backends whose model inpaints the masks of the images
+	CapabilityInpaint = "inpaint"
+	// CapabilityImageProgress is reported by the image backends which stream the progress of the generations
+	CapabilityImageProgress = "image_progress"
+	// CapabilityImageBatch is reported by the image backends which generate the images of the dsts of the requests
+	// at once
+	CapabilityImageBatch = "image_batch"
+	// CapabilityVision is reported by the backends whose model reads the images of the prompts
+	CapabilityVision = "vision"
+)
+
+// CapabilitiesProvider can be implemented by an LLM to report the capabilities of the backend during the handshake
+type CapabilitiesProvider interface {
+	Capabilities() []string
+}
+
+// Negotiate asks the backend which protocol version it speaks, and returns an error if LocalAI can't talk to it
+func Negotiate(ctx context.Context, b Backend) (*pb.CapabilitiesResponse, error) {
+	res, err := b.Capabilities(ctx)
+	if status.Code(err) == codes.Unimplemented {
+		// the backend was built before the handshake was introduced
+		res, err = &pb.CapabilitiesResponse{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed the protocol handshake with the backend: %w", err)
+	}
+
+	if res.ProtocolVersion < MinProtocolVersion {
+		return nil, fmt.Errorf("the backend speaks protocol version %d, but LocalAI requires at least version %d: the backend assets are older than LocalAI, rebuild or update them", res.ProtocolVersion, MinProtocolVersion)
+	}
+	return res, nil
+}
+
+// HasCapability returns true if the backend reports the capability, or if it doesn't report its capabilities at all
+func HasCapability(res *pb.CapabilitiesResponse, capability string) bool {
+	if res == nil || len(res.Capabilities) == 0 {
+		return true
+	}
+	for _, c := range res.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// ReportsCapability returns true only if the backend reports the capability, for the capabilities which the backends
+// support only when they say so
+func ReportsCapability(res *pb.CapabilitiesResponse, capability string) bool {
+	return res != nil && len(res.Capabilities) > 0 && HasCapability(res, capability)
+}
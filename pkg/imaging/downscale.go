@@ -0,0 +1,65 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+)
+
+// Downscale returns the image encoded in dat downscaled so that its largest side is at most maxSize pixels, keeping
+// its aspect ratio. The smaller images are returned unchanged, the downscaled ones are encoded as PNG images when
+// they were PNG or GIF images, as JPEG images otherwise.
+func Downscale(dat []byte, maxSize int) ([]byte, error) {
+	c, format, err := image.DecodeConfig(bytes.NewReader(dat))
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot decode the image: %v", ErrUnsupportedImage, err)
+	}
+	if maxSize <= 0 || max(c.Width, c.Height) <= maxSize {
+		return dat, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(dat))
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot decode the image: %v", ErrUnsupportedImage, err)
+	}
+	width, height := maxSize, max(c.Height*maxSize/c.Width, 1)
+	if c.Height > c.Width {
+		width, height = max(c.Width*maxSize/c.Height, 1), maxSize
+	}
+	resized := resize(img, width, height)
+
+	buf := &bytes.Buffer{}
+	if format == "png" || format == "gif" {
+		err = png.Encode(buf, resized)
+	} else {
+		err = jpeg.Encode(buf, resized, &jpeg.Options{Quality: 90})
+	}
+	return buf.Bytes(), err
+}
+
+// resize returns the image scaled down to width x height, each pixel being the average of the pixels of the image it
+// covers
+func resize(img image.Image, width, height int) *image.NRGBA {
+	b := img.Bounds()
+	res := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := b.Min.Y+y*b.Dy()/height, b.Min.Y+max((y+1)*b.Dy()/height, y*b.Dy()/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := b.Min.X+x*b.Dx()/width, b.Min.X+max((x+1)*b.Dx()/width, x*b.Dx()/width+1)
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBAModel.Convert(img.At(sx, sy)).(color.NRGBA)
+					r, g, bl, a = r+uint64(c.R), g+uint64(c.G), bl+uint64(c.B), a+uint64(c.A)
+					n++
+				}
+			}
+			res.SetNRGBA(x, y, color.NRGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(bl / n), A: uint8(a / n)})
+		}
+	}
+	return res
+}
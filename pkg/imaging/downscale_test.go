@@ -0,0 +1,66 @@
+package imaging_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+
+	. "github.com/go-skynet/LocalAI/pkg/imaging"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Downscale", func() {
+	encode := func(img image.Image, format string) []byte {
+		buf := &bytes.Buffer{}
+		if format == "png" {
+			Expect(png.Encode(buf, img)).To(Succeed())
+		} else {
+			Expect(jpeg.Encode(buf, img, nil)).To(Succeed())
+		}
+		return buf.Bytes()
+	}
+
+	It("keeps the images small enough", func() {
+		dat := encode(image.NewNRGBA(image.Rect(0, 0, 8, 4)), "png")
+		res, err := Downscale(dat, 8)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(dat))
+	})
+
+	It("downscales the largest side, keeping the aspect ratio and the format", func() {
+		img := image.NewNRGBA(image.Rect(0, 0, 4, 8))
+		for y := 0; y < 8; y++ {
+			for x := 0; x < 4; x++ {
+				if x%2 == 0 {
+					img.Set(x, y, color.NRGBA{R: 200, A: 255})
+				} else {
+					img.Set(x, y, color.NRGBA{R: 100, A: 255})
+				}
+			}
+		}
+		res, err := Downscale(encode(img, "png"), 4)
+		Expect(err).ToNot(HaveOccurred())
+		scaled, format, err := image.Decode(bytes.NewReader(res))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(format).To(Equal("png"))
+		Expect(scaled.Bounds().Dx()).To(Equal(2))
+		Expect(scaled.Bounds().Dy()).To(Equal(4))
+		// the pixels are averaged
+		r, _, _, _ := scaled.At(0, 0).RGBA()
+		Expect(r >> 8).To(Equal(uint32(150)))
+
+		res, err = Downscale(encode(img, "jpeg"), 4)
+		Expect(err).ToNot(HaveOccurred())
+		_, format, err = image.Decode(bytes.NewReader(res))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(format).To(Equal("jpeg"))
+	})
+
+	It("refuses what is not an image", func() {
+		_, err := Downscale([]byte("not an image"), 4)
+		Expect(err).To(MatchError(ErrUnsupportedImage))
+	})
+})
@@ -0,0 +1,94 @@
+package imaging
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"image/png"
+	"os"
+)
+
+var (
+	// ErrMaskSize is returned when the mask and the image don't have the same size
+	ErrMaskSize = errors.New("the mask must have the size of the image")
+	// ErrUnsupportedImage is returned when an image or a mask can't be decoded
+	ErrUnsupportedImage = errors.New("unsupported image")
+)
+
+// InpaintMask converts a mask of the OpenAI image edits, whose fully transparent pixels are the areas to edit, to a
+// mask of the inpainting pipelines: a grayscale image, white where the image is repainted. The masks without
+// transparency are taken as grayscale masks already.
+func InpaintMask(mask image.Image) *image.Gray {
+	b := mask.Bounds()
+	transparent := false
+	for y := b.Min.Y; y < b.Max.Y && !transparent; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := mask.At(x, y).RGBA(); a < 0xffff {
+				transparent = true
+				break
+			}
+		}
+	}
+
+	res := image.NewGray(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := mask.At(x, y)
+			if transparent {
+				if _, _, _, a := c.RGBA(); a == 0 {
+					c = color.White
+				} else {
+					c = color.Black
+				}
+			}
+			res.Set(x-b.Min.X, y-b.Min.Y, c)
+		}
+	}
+	return res
+}
+
+// WriteInpaintMask writes the inpainting mask of the mask file of the image file to dst, as a PNG image
+func WriteInpaintMask(imageFile, maskFile, dst string) error {
+	size, err := decodeSize(imageFile)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(maskFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	mask, _, err := image.Decode(f)
+	if err != nil {
+		return fmt.Errorf("%w: cannot decode the mask: %v", ErrUnsupportedImage, err)
+	}
+	if b := mask.Bounds(); b.Dx() != size.X || b.Dy() != size.Y {
+		return fmt.Errorf("%w: the mask is %dx%d, the image %dx%d", ErrMaskSize, b.Dx(), b.Dy(), size.X, size.Y)
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	err = png.Encode(out, InpaintMask(mask))
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// decodeSize returns the size of an image file
+func decodeSize(file string) (image.Point, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return image.Point{}, err
+	}
+	defer f.Close()
+	c, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return image.Point{}, fmt.Errorf("%w: cannot decode the image: %v", ErrUnsupportedImage, err)
+	}
+	return image.Point{X: c.Width, Y: c.Height}, nil
+}
@@ -0,0 +1,82 @@
+package imaging_test
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	. "github.com/go-skynet/LocalAI/pkg/imaging"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Mask", func() {
+	var dir string
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	writePNG := func(name string, img image.Image) string {
+		file := filepath.Join(dir, name)
+		f, err := os.Create(file)
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+		Expect(png.Encode(f, img)).To(Succeed())
+		return file
+	}
+
+	It("repaints the transparent areas of the masks", func() {
+		mask := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+		for x := 0; x < 4; x++ {
+			mask.Set(x, 0, color.NRGBA{R: 10, A: 255})
+			mask.Set(x, 1, color.NRGBA{R: 10, A: 128})
+		}
+		mask.Set(1, 0, color.NRGBA{})
+
+		gray := InpaintMask(mask)
+		Expect(gray.GrayAt(1, 0).Y).To(Equal(uint8(255)))
+		Expect(gray.GrayAt(0, 0).Y).To(Equal(uint8(0)))
+		// only the fully transparent pixels are repainted
+		Expect(gray.GrayAt(1, 1).Y).To(Equal(uint8(0)))
+	})
+
+	It("keeps the masks without transparency", func() {
+		mask := image.NewRGBA(image.Rect(0, 0, 2, 1))
+		mask.Set(0, 0, color.White)
+		mask.Set(1, 0, color.Black)
+
+		gray := InpaintMask(mask)
+		Expect(gray.GrayAt(0, 0).Y).To(Equal(uint8(255)))
+		Expect(gray.GrayAt(1, 0).Y).To(Equal(uint8(0)))
+	})
+
+	It("writes the masks of the size of the image", func() {
+		img := writePNG("image.png", image.NewRGBA(image.Rect(0, 0, 8, 4)))
+		mask := writePNG("mask.png", image.NewNRGBA(image.Rect(0, 0, 8, 4)))
+		dst := filepath.Join(dir, "inpaint.png")
+		Expect(WriteInpaintMask(img, mask, dst)).To(Succeed())
+
+		f, err := os.Open(dst)
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+		written, err := png.Decode(f)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(written.Bounds()).To(Equal(image.Rect(0, 0, 8, 4)))
+		Expect(written.(*image.Gray).GrayAt(3, 3).Y).To(Equal(uint8(255)))
+	})
+
+	It("refuses the masks of another size", func() {
+		img := writePNG("image.png", image.NewRGBA(image.Rect(0, 0, 8, 4)))
+		mask := writePNG("mask.png", image.NewNRGBA(image.Rect(0, 0, 4, 4)))
+		Expect(WriteInpaintMask(img, mask, filepath.Join(dir, "inpaint.png"))).To(MatchError(ErrMaskSize))
+	})
+
+	It("refuses the masks which are not images", func() {
+		img := writePNG("image.png", image.NewRGBA(image.Rect(0, 0, 8, 4)))
+		mask := filepath.Join(dir, "mask.png")
+		Expect(os.WriteFile(mask, []byte("garbage"), 0600)).To(Succeed())
+		Expect(WriteInpaintMask(img, mask, filepath.Join(dir, "inpaint.png"))).To(MatchError(ErrUnsupportedImage))
+	})
+})
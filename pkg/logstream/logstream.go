@@ -0,0 +1,138 @@
+// Package logstream broadcasts the logs of LocalAI and of the processes of its backends to the clients watching them,
+// e.g. to follow the load of a model or the errors of the generations without a shell in the container. The last
+// entries are kept, for the clients to see what happened before they subscribed.
+package logstream
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// ComponentLocalAI is the component of the logs of LocalAI which don't set theirs
+	ComponentLocalAI = "localai"
+	// ComponentBackend is the component of the output of the processes of the backends
+	ComponentBackend = "backend"
+)
+
+// the entries buffered for each subscriber, the entries of the subscribers which can't keep up are dropped
+const subscriberBuffer = 256
+
+// Entry is a line of log
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Level     string    `json:"level"`
+	Component string    `json:"component"`
+	// Model is the model the entry is about, e.g. the one of the backend which wrote it
+	Model   string `json:"model,omitempty"`
+	Message string `json:"message"`
+}
+
+// Filter selects the entries sent to a subscriber
+type Filter struct {
+	// Level is the minimum level of the entries
+	Level zerolog.Level
+	// Components are the components of the entries, all when empty
+	Components []string
+	// Model is the model of the entries, all when empty
+	Model string
+}
+
+// Match returns true if the filter selects the entry
+func (f Filter) Match(e Entry) bool {
+	if l, err := zerolog.ParseLevel(e.Level); err == nil && l < f.Level {
+		return false
+	}
+	if f.Model != "" && e.Model != f.Model {
+		return false
+	}
+	if len(f.Components) == 0 {
+		return true
+	}
+	for _, c := range f.Components {
+		if c == e.Component {
+			return true
+		}
+	}
+	return false
+}
+
+// Hub broadcasts the entries to the subscribers, and keeps the last ones
+type Hub struct {
+	sync.Mutex
+	size        int
+	history     []Entry
+	subscribers map[chan Entry]Filter
+}
+
+// Default is the hub of the logs of LocalAI
+var Default = NewHub(1000)
+
+// NewHub returns a hub keeping the last size entries
+func NewHub(size int) *Hub {
+	return &Hub{size: size, subscribers: map[chan Entry]Filter{}}
+}
+
+// Publish sends the entry to the subscribers it matches
+func (h *Hub) Publish(e Entry) {
+	h.Lock()
+	defer h.Unlock()
+	h.history = append(h.history, e)
+	if len(h.history) > h.size {
+		h.history = h.history[len(h.history)-h.size:]
+	}
+	for ch, f := range h.subscribers {
+		if !f.Match(e) {
+			continue
+		}
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Write publishes an event of zerolog, so that the hub can be an output of the loggers
+func (h *Hub) Write(p []byte) (int, error) {
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(p, &fields); err != nil {
+		h.Publish(Entry{Time: time.Now(), Level: zerolog.NoLevel.String(), Component: ComponentLocalAI, Message: string(p)})
+		return len(p), nil
+	}
+	str := func(key string) string {
+		s, _ := fields[key].(string)
+		return s
+	}
+	e := Entry{Time: time.Now(), Level: str(zerolog.LevelFieldName), Component: str("component"), Model: str("model"), Message: str(zerolog.MessageFieldName)}
+	if t, err := time.Parse(zerolog.TimeFieldFormat, str(zerolog.TimestampFieldName)); err == nil {
+		e.Time = t
+	}
+	if e.Component == "" {
+		e.Component = ComponentLocalAI
+	}
+	h.Publish(e)
+	return len(p), nil
+}
+
+// Subscribe returns the last tail entries matching the filter, and the channel of the next ones. The subscription
+// ends with the function returned.
+func (h *Hub) Subscribe(f Filter, tail int) ([]Entry, <-chan Entry, func()) {
+	h.Lock()
+	defer h.Unlock()
+	last := []Entry{}
+	for i := len(h.history) - 1; i >= 0 && len(last) < tail; i-- {
+		if f.Match(h.history[i]) {
+			last = append([]Entry{h.history[i]}, last...)
+		}
+	}
+	ch := make(chan Entry, subscriberBuffer)
+	h.subscribers[ch] = f
+	return last, ch, func() {
+		h.Lock()
+		defer h.Unlock()
+		delete(h.subscribers, ch)
+	}
+}
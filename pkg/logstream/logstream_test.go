@@ -0,0 +1,65 @@
+package logstream_test
+
+import (
+	"github.com/go-skynet/LocalAI/pkg/logstream"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rs/zerolog"
+)
+
+var _ = Describe("Hub", func() {
+	It("publishes the events of zerolog", func() {
+		hub := logstream.NewHub(10)
+		_, ch, stop := hub.Subscribe(logstream.Filter{Level: zerolog.DebugLevel}, 0)
+		defer stop()
+
+		logger := zerolog.New(hub)
+		logger.Info().Msg("started")
+		logger.Warn().Str("component", "gallery").Str("model", "phi").Msg("slow download")
+
+		e := <-ch
+		Expect(e.Level).To(Equal("info"))
+		Expect(e.Component).To(Equal(logstream.ComponentLocalAI))
+		Expect(e.Message).To(Equal("started"))
+		e = <-ch
+		Expect(e.Component).To(Equal("gallery"))
+		Expect(e.Model).To(Equal("phi"))
+	})
+
+	It("filters the entries by level, component and model", func() {
+		hub := logstream.NewHub(10)
+		_, ch, stop := hub.Subscribe(logstream.Filter{Level: zerolog.InfoLevel, Components: []string{logstream.ComponentBackend}, Model: "phi"}, 0)
+		defer stop()
+
+		hub.Publish(logstream.Entry{Level: "debug", Component: logstream.ComponentBackend, Model: "phi", Message: "debug"})
+		hub.Publish(logstream.Entry{Level: "info", Component: logstream.ComponentLocalAI, Model: "phi", Message: "localai"})
+		hub.Publish(logstream.Entry{Level: "info", Component: logstream.ComponentBackend, Model: "llava", Message: "other model"})
+		hub.Publish(logstream.Entry{Level: "error", Component: logstream.ComponentBackend, Model: "phi", Message: "failed"})
+		Expect((<-ch).Message).To(Equal("failed"))
+		Expect(ch).To(BeEmpty())
+	})
+
+	It("returns the last entries to the new subscribers", func() {
+		hub := logstream.NewHub(3)
+		for _, m := range []string{"1", "2", "3", "4"} {
+			hub.Publish(logstream.Entry{Level: "info", Component: logstream.ComponentLocalAI, Message: m})
+		}
+		last, _, stop := hub.Subscribe(logstream.Filter{}, 2)
+		defer stop()
+		Expect(last).To(HaveLen(2))
+		Expect(last[0].Message).To(Equal("3"))
+		Expect(last[1].Message).To(Equal("4"))
+
+		last, _, stop = hub.Subscribe(logstream.Filter{}, 10)
+		defer stop()
+		Expect(last).To(HaveLen(3))
+	})
+
+	It("stops sending the entries once unsubscribed", func() {
+		hub := logstream.NewHub(3)
+		_, ch, stop := hub.Subscribe(logstream.Filter{}, 0)
+		stop()
+		hub.Publish(logstream.Entry{Level: "info", Message: "after"})
+		Expect(ch).To(BeEmpty())
+	})
+})
@@ -0,0 +1,270 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	process "github.com/mudler/go-processmanager"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// the lines of the stderr of the backend kept in the crashes
+	crashStderrLines = 100
+	// the end of the stderr read to find them, to not read the whole log of the long running backends
+	crashStderrBytes = 64 * 1024
+	// how often the processes are checked for crashes
+	crashPollInterval = time.Second
+)
+
+var otherCoreSpecifiers = regexp.MustCompile(`%[^pe%]`)
+
+// Crash is the evidence collected when the process of a backend died without being stopped by LocalAI
+type Crash struct {
+	Model   string    `json:"model"`
+	Backend string    `json:"backend"`
+	PID     int       `json:"pid"`
+	Time    time.Time `json:"time"`
+	// ExitCode is -1 if the process was killed by a signal, or the code could not be read
+	ExitCode int `json:"exit_code"`
+	// Stderr are the last lines the process wrote to its stderr
+	Stderr []string `json:"stderr"`
+	// CoreDump is the path of the core dump of the process, if the kernel wrote one where it could be found
+	CoreDump string `json:"core_dump,omitempty"`
+	// Report is the file the crash was written to, if a crash directory is set
+	Report string `json:"report,omitempty"`
+}
+
+func (c Crash) String() string {
+	s := fmt.Sprintf("backend %s of model %s (pid %d) exited with code %d", c.Backend, c.Model, c.PID, c.ExitCode)
+	if len(c.Stderr) > 0 {
+		s += ": " + c.Stderr[len(c.Stderr)-1]
+	}
+	return s
+}
+
+// processWatch follows the process of a backend, to collect its crash once it dies
+type processWatch struct {
+	model, backend, executable string
+	p                          *process.Process
+
+	mu      sync.Mutex
+	stopped bool
+
+	once  sync.Once
+	crash *Crash
+}
+
+// stop marks the process as stopped by LocalAI, to not report its exit as a crash
+func (w *processWatch) stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stopped = true
+}
+
+func (w *processWatch) isStopped() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stopped
+}
+
+// SetCrashDir sets the directory the crashes of the backends are written to, in a directory per model
+func (ml *ModelLoader) SetCrashDir(dir string) {
+	ml.crashDir = dir
+}
+
+// watchProcess starts collecting the crash of the process of the backend serving the model
+func (ml *ModelLoader) watchProcess(model, executable string, p *process.Process) {
+	w := &processWatch{model: model, backend: filepath.Base(executable), executable: executable, p: p}
+
+	ml.crashMu.Lock()
+	if old, ok := ml.watches[model]; ok {
+		old.stop()
+	}
+	ml.watches[model] = w
+	ml.crashMu.Unlock()
+
+	go func() {
+		t := time.NewTicker(crashPollInterval)
+		defer t.Stop()
+		for range t.C {
+			if w.isStopped() {
+				return
+			}
+			// the process manager writes the exit code once the process exited
+			if _, err := p.ExitCode(); err == nil {
+				ml.collectCrash(w)
+				return
+			}
+		}
+	}()
+}
+
+// stopWatch is called before LocalAI stops the process of the model on purpose
+func (ml *ModelLoader) stopWatch(model string) {
+	ml.crashMu.Lock()
+	defer ml.crashMu.Unlock()
+	if w, ok := ml.watches[model]; ok {
+		w.stop()
+		delete(ml.watches, model)
+	}
+}
+
+// backendCrash returns the crash of the process of the model if it died, nil otherwise
+func (ml *ModelLoader) backendCrash(model string) *Crash {
+	ml.crashMu.Lock()
+	w, ok := ml.watches[model]
+	ml.crashMu.Unlock()
+	if !ok || w.isStopped() || w.p.IsAlive() {
+		return nil
+	}
+	return ml.collectCrash(w)
+}
+
+// collectCrash collects the crash of the process once, keeps it as the last crash of the model and writes it to the
+// crash directory
+func (ml *ModelLoader) collectCrash(w *processWatch) *Crash {
+	w.once.Do(func() {
+		c := &Crash{Model: w.model, Backend: w.backend, Time: time.Now(), ExitCode: -1}
+		c.PID, _ = strconv.Atoi(w.p.PID)
+		if code, err := w.p.ExitCode(); err == nil {
+			if i, err := strconv.Atoi(strings.TrimSpace(code)); err == nil {
+				c.ExitCode = i
+			}
+		}
+		c.Stderr = tailLines(w.p.StderrPath(), crashStderrLines)
+		c.CoreDump = findCoreDump(c.PID, filepath.Base(w.executable))
+
+		if ml.crashDir != "" {
+			report, err := writeCrash(ml.crashDir, c)
+			if err != nil {
+				log.Error().Msgf("Could not write the crash of %s: %s", w.model, err.Error())
+			}
+			c.Report = report
+		}
+		log.Error().Msgf("GRPC(%s): %s", w.model, c)
+
+		ml.crashMu.Lock()
+		ml.crashes[w.model] = *c
+		ml.crashMu.Unlock()
+		w.crash = c
+	})
+	return w.crash
+}
+
+// LastCrash returns the last crash of the backend of the model, if any
+func (ml *ModelLoader) LastCrash(model string) (Crash, bool) {
+	ml.crashMu.Lock()
+	defer ml.crashMu.Unlock()
+	c, ok := ml.crashes[model]
+	return c, ok
+}
+
+// Crashes returns the last crash of the backend of each model, sorted by model
+func (ml *ModelLoader) Crashes() []Crash {
+	ml.crashMu.Lock()
+	defer ml.crashMu.Unlock()
+	crashes := make([]Crash, 0, len(ml.crashes))
+	for _, c := range ml.crashes {
+		crashes = append(crashes, c)
+	}
+	sort.Slice(crashes, func(i, j int) bool { return crashes[i].Model < crashes[j].Model })
+	return crashes
+}
+
+// writeCrash writes the crash as JSON to the directory of its model in dir, and returns the path of the file
+func writeCrash(dir string, c *Crash) (string, error) {
+	modelDir := filepath.Join(dir, strings.ReplaceAll(c.Model, string(filepath.Separator), "_"))
+	if err := os.MkdirAll(modelDir, 0750); err != nil {
+		return "", err
+	}
+	dat, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	report := filepath.Join(modelDir, c.Time.UTC().Format("20060102T150405.000Z")+".json")
+	return report, os.WriteFile(report, dat, 0640)
+}
+
+// tailLines returns the last n lines of the file, read from its end only
+func tailLines(path string, n int) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return nil
+	}
+	offset := st.Size() - crashStderrBytes
+	if offset < 0 {
+		offset = 0
+	}
+	dat, err := io.ReadAll(io.NewSectionReader(f, offset, st.Size()-offset))
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(strings.TrimRight(string(dat), "\n"), "\n")
+	if offset > 0 {
+		// the first line is likely cut
+		lines = lines[1:]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	if len(lines) == 1 && lines[0] == "" {
+		return nil
+	}
+	return lines
+}
+
+// findCoreDump looks for the core dump of the process where the kernel core_pattern writes them, or in the working
+// directory. The patterns piping the core dumps to a program (e.g. systemd-coredump) are not supported.
+func findCoreDump(pid int, executable string) string {
+	pattern := "core"
+	if dat, err := os.ReadFile("/proc/sys/kernel/core_pattern"); err == nil {
+		pattern = strings.TrimSpace(string(dat))
+	}
+	if pattern == "" || strings.HasPrefix(pattern, "|") {
+		return ""
+	}
+
+	candidates := []string{}
+	if !strings.Contains(pattern, "%p") {
+		// the kernel appends the PID when core_uses_pid is set
+		candidates = append(candidates, pattern+"."+strconv.Itoa(pid))
+	}
+	candidates = append(candidates, pattern)
+
+	// the kernel truncates the name of the executable
+	if len(executable) > 15 {
+		executable = executable[:15]
+	}
+	for _, c := range candidates {
+		// the specifiers other than the PID and the executable (time, hostname, signal, ...) are matched by any value
+		c = otherCoreSpecifiers.ReplaceAllString(c, "*")
+		c = strings.NewReplacer("%p", strconv.Itoa(pid), "%e", executable, "%%", "%").Replace(c)
+		matches, _ := filepath.Glob(c)
+		for i := len(matches) - 1; i >= 0; i-- {
+			// not the core dump of an older crash
+			if st, err := os.Stat(matches[i]); err != nil || time.Since(st.ModTime()) > time.Minute {
+				continue
+			}
+			if abs, err := filepath.Abs(matches[i]); err == nil {
+				return abs
+			}
+			return matches[i]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"sort"
+	"strconv"
+)
+
+// ModelDiagnostics is the state of the loader about a loaded model
+type ModelDiagnostics struct {
+	Model   string `json:"model"`
+	Backend string `json:"backend"`
+	Address string `json:"address"`
+	// PID is the process of the backend, zero for the external backends or while the loader is locked
+	PID   int  `json:"pid,omitempty"`
+	Alive bool `json:"alive"`
+	// External is true for the models served by an external backend, not started by LocalAI
+	External bool `json:"external,omitempty"`
+	// Busy is true while the shared client of the model is serving a request
+	Busy bool `json:"busy"`
+}
+
+// Diagnostics is the internal state of the loader
+type Diagnostics struct {
+	// Locked is true while the loader is loading a model (or is stuck doing so): the state of the processes is then
+	// not available, only the models and their addresses
+	Locked           bool               `json:"locked"`
+	Models           []ModelDiagnostics `json:"models"`
+	ExternalBackends map[string]string  `json:"external_backends"`
+}
+
+// Diagnostics returns the state of the loader, without waiting for the models being loaded
+func (ml *ModelLoader) Diagnostics() Diagnostics {
+	d := Diagnostics{ExternalBackends: ml.ListExternalBackends()}
+
+	ml.backendsMu.Lock()
+	for m, addr := range ml.addresses {
+		d.Models = append(d.Models, ModelDiagnostics{Model: m, Backend: ml.backends[m], Address: string(addr)})
+	}
+	ml.backendsMu.Unlock()
+	sort.Slice(d.Models, func(i, j int) bool { return d.Models[i].Model < d.Models[j].Model })
+
+	if !ml.mu.TryLock() {
+		d.Locked = true
+		return d
+	}
+	defer ml.mu.Unlock()
+	for i := range d.Models {
+		m := &d.Models[i]
+		if p, ok := ml.grpcProcess(m.Model); ok {
+			m.PID, _ = strconv.Atoi(p.PID)
+			m.Alive = p.IsAlive()
+		} else {
+			m.External = true
+		}
+		if c, ok := ml.grpcClients[m.Address]; ok {
+			m.Busy = c.IsBusy()
+		}
+	}
+	return d
+}
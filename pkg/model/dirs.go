@@ -0,0 +1,104 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ModelDir is a directory searched for the models. The read-only directories (e.g. a network share of the weights)
+// are only searched, the downloads and the configurations written at runtime go to the first writable directory.
+type ModelDir struct {
+	Path     string
+	ReadOnly bool
+}
+
+// ParseModelDirs parses a comma separated list of directories, in the order they are searched.
+// The read-only directories end with :ro, e.g. /ssd/models,/mnt/nfs/models:ro
+func ParseModelDirs(s string) []ModelDir {
+	dirs := []ModelDir{}
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		d := ModelDir{Path: p}
+		if strings.HasSuffix(p, ":ro") {
+			d = ModelDir{Path: strings.TrimSuffix(p, ":ro"), ReadOnly: true}
+		}
+		dirs = append(dirs, d)
+	}
+	return dirs
+}
+
+// SetModelDirs sets the directories searched for the models, in order. The models path becomes the first writable directory.
+func (ml *ModelLoader) SetModelDirs(dirs []ModelDir) error {
+	for _, d := range dirs {
+		if !d.ReadOnly {
+			ml.ModelPath = d.Path
+			ml.dirs = dirs
+			return nil
+		}
+	}
+	return fmt.Errorf("none of the models directories is writable")
+}
+
+// ModelDirs returns the directories searched for the models, in order
+func (ml *ModelLoader) ModelDirs() []ModelDir {
+	if len(ml.dirs) == 0 {
+		return []ModelDir{{Path: ml.ModelPath}}
+	}
+	return append([]ModelDir{}, ml.dirs...)
+}
+
+// ModelFile returns the path of the file in the first directory which has it,
+// or in the models path if none has it (e.g. before it is downloaded)
+func (ml *ModelLoader) ModelFile(name string) string {
+	for _, d := range ml.ModelDirs() {
+		if existsInPath(d.Path, name) {
+			return filepath.Join(d.Path, name)
+		}
+	}
+	return filepath.Join(ml.ModelPath, name)
+}
+
+func (ml *ModelLoader) existsInDirs(name string) bool {
+	for _, d := range ml.ModelDirs() {
+		if existsInPath(d.Path, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// readModelDirs returns the entries of the subdirectory of all the directories, the ones of the first directories hiding
+// the others of the same name. The directories which cannot be read (e.g. an unmounted share) are skipped, except the
+// models path, and the missing subdirectories are skipped.
+func (ml *ModelLoader) readModelDirs(subdir string) ([]os.DirEntry, error) {
+	entries := []os.DirEntry{}
+	seen := map[string]bool{}
+	for _, d := range ml.ModelDirs() {
+		files, err := os.ReadDir(filepath.Join(d.Path, subdir))
+		if err != nil {
+			if subdir != "" && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if d.Path == ml.ModelPath {
+				return nil, err
+			}
+			log.Warn().Msgf("Skipping the models directory %s: %s", d.Path, err.Error())
+			continue
+		}
+		for _, f := range files {
+			if !seen[f.Name()] {
+				seen[f.Name()] = true
+				entries = append(entries, f)
+			}
+		}
+	}
+	return entries, nil
+}
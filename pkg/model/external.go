@@ -0,0 +1,50 @@
+package model
+
+import (
+	"fmt"
+)
+
+// RegisterExternalBackend makes an external backend available at runtime, in addition to
+// the ones given with WithExternalBackend. As for those, uri is either the address of a
+// running gRPC server or the path of an executable to start.
+// A backend registered with the same name as an existing one replaces it.
+func (ml *ModelLoader) RegisterExternalBackend(name, uri string) error {
+	if name == "" || uri == "" {
+		return fmt.Errorf("both the name and the uri of the backend are required")
+	}
+
+	ml.externalMu.Lock()
+	defer ml.externalMu.Unlock()
+	ml.externalBackends[name] = uri
+	return nil
+}
+
+// UnregisterExternalBackend removes a backend registered with RegisterExternalBackend.
+// Models already loaded with it are not stopped.
+func (ml *ModelLoader) UnregisterExternalBackend(name string) error {
+	ml.externalMu.Lock()
+	defer ml.externalMu.Unlock()
+	if _, ok := ml.externalBackends[name]; !ok {
+		return fmt.Errorf("external backend %s is not registered", name)
+	}
+	delete(ml.externalBackends, name)
+	return nil
+}
+
+// ListExternalBackends returns the backends registered with RegisterExternalBackend
+func (ml *ModelLoader) ListExternalBackends() map[string]string {
+	ml.externalMu.Lock()
+	defer ml.externalMu.Unlock()
+	backends := make(map[string]string, len(ml.externalBackends))
+	for k, v := range ml.externalBackends {
+		backends[k] = v
+	}
+	return backends
+}
+
+// addExternalBackends adds the backends registered at runtime to the options
+func (ml *ModelLoader) addExternalBackends(o *Options) {
+	for name, uri := range ml.ListExternalBackends() {
+		WithExternalBackend(name, uri)(o)
+	}
+}
@@ -0,0 +1,112 @@
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/go-skynet/LocalAI/pkg/gguf"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// fraction of the free VRAM which is kept for the KV cache, scratch buffers and other processes
+	gpuLayersSafetyMargin = 0.15
+	// memory (in bytes) reserved for the CUDA context, in any case
+	gpuLayersReserved = 512 * 1024 * 1024
+)
+
+// freeVRAM returns the free memory (in bytes) of the given NVIDIA GPUs, or of all the GPUs of the system, as the layers are split between all of them
+var freeVRAM = func(gpus []int) (uint64, error) {
+	args := []string{"--query-gpu=memory.free", "--format=csv,noheader,nounits"}
+	if len(gpus) > 0 {
+		ids := make([]string, len(gpus))
+		for i, id := range gpus {
+			ids[i] = strconv.Itoa(id)
+		}
+		args = append(args, "--id="+strings.Join(ids, ","))
+	}
+	out, err := exec.Command("nvidia-smi", args...).Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed querying the GPU memory: %w", err)
+	}
+
+	var free uint64
+	for _, line := range bytes.Split(bytes.TrimSpace(out), []byte("\n")) {
+		mb, err := strconv.ParseUint(strings.TrimSpace(string(line)), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed parsing the GPU memory %q: %w", line, err)
+		}
+		free += mb * 1024 * 1024
+	}
+	return free, nil
+}
+
+// estimateGPULayers returns the number of layers of the GGUF model which fit into the given amount of VRAM.
+// When the whole model fits, the returned value also offloads the non-repeating layers (output).
+func estimateGPULayers(modelFile string, vram uint64) (int, error) {
+	f, err := gguf.Read(modelFile)
+	if err != nil {
+		return 0, err
+	}
+
+	layers, other := f.LayerSizes()
+	if len(layers) == 0 {
+		return 0, fmt.Errorf("no layers found in %s", modelFile)
+	}
+
+	budget := uint64(float64(vram) * (1 - gpuLayersSafetyMargin))
+	if budget <= gpuLayersReserved {
+		return 0, nil
+	}
+	budget -= gpuLayersReserved
+
+	var used uint64
+	for i, size := range layers {
+		if used+size > budget {
+			return i, nil
+		}
+		used += size
+	}
+
+	if used+other > budget {
+		return len(layers), nil
+	}
+	return len(layers) + 1, nil
+}
+
+// autoGPULayers computes the number of layers to offload for the model found at modelFile, on the given GPUs (all if empty)
+func autoGPULayers(modelFile string, gpus []int) int {
+	vram, err := freeVRAM(gpus)
+	if err != nil {
+		log.Debug().Msgf("Not offloading layers automatically: %s", err.Error())
+		return 0
+	}
+
+	n, err := estimateGPULayers(modelFile, vram)
+	if err != nil {
+		log.Debug().Msgf("Not offloading layers automatically: %s", err.Error())
+		return 0
+	}
+
+	log.Info().Msgf("Offloading %d layers of %s to the GPU (%d MiB of VRAM free)", n, modelFile, vram/1024/1024)
+	return n
+}
+
+// isOutOfMemory returns true if the error returned by the backend looks like an allocation failure
+func isOutOfMemory(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"out of memory", "cudamalloc", "failed to allocate"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// fewerGPULayers returns the number of layers to try after a failure with n layers
+func fewerGPULayers(n int) int {
+	return n * 3 / 4
+}
@@ -0,0 +1,149 @@
+package model
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/go-skynet/LocalAI/pkg/grpc"
+	"github.com/go-skynet/LocalAI/pkg/grpc/base"
+	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const mib = 1024 * 1024
+
+// writeLayeredGGUF writes a sparse GGUF file with 100 MiB of embeddings and two layers of 200 MiB each
+func writeLayeredGGUF(path string) {
+	b := &bytes.Buffer{}
+	w := func(v interface{}) { binary.Write(b, binary.LittleEndian, v) }
+	str := func(s string) {
+		w(uint64(len(s)))
+		b.WriteString(s)
+	}
+
+	tensors := []struct {
+		name   string
+		offset uint64
+	}{{"token_embd.weight", 0}, {"blk.0.ffn_up.weight", 100 * mib}, {"blk.1.ffn_up.weight", 300 * mib}}
+
+	b.WriteString("GGUF")
+	w(uint32(3))
+	w(uint64(len(tensors)))
+	w(uint64(2))
+	str("general.architecture")
+	w(uint32(8))
+	str("llama")
+	str("llama.block_count")
+	w(uint32(4))
+	w(uint32(2))
+	for _, t := range tensors {
+		str(t.name)
+		w(uint32(1))
+		w(uint64(1))
+		w(uint32(0))
+		w(t.offset)
+	}
+	for b.Len()%32 != 0 {
+		b.WriteByte(0)
+	}
+
+	Expect(os.WriteFile(path, b.Bytes(), 0600)).To(Succeed())
+	Expect(os.Truncate(path, int64(b.Len())+500*mib)).To(Succeed())
+}
+
+// vramFor returns the VRAM leaving budget bytes for the layers, once the safety margin and the reserved memory are
+// taken
+func vramFor(budget uint64) uint64 {
+	return uint64(float64(budget+gpuLayersReserved)/(1-gpuLayersSafetyMargin)) + mib
+}
+
+// oomBackend fails to load the models with any layer on the GPU, and records the layers of each attempt
+type oomBackend struct {
+	base.Base
+	attempts []int32
+}
+
+func (b *oomBackend) Load(opts *pb.ModelOptions) error {
+	b.attempts = append(b.attempts, opts.NGPULayers)
+	return errors.New("CUDA error: out of memory")
+}
+
+var _ = Describe("GPU layers", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	DescribeTable("estimates the layers which fit into the VRAM",
+		func(vram uint64, expected int) {
+			path := filepath.Join(dir, "model.gguf")
+			writeLayeredGGUF(path)
+			n, err := estimateGPULayers(path, vram)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(expected))
+		},
+		Entry("without VRAM beyond the reserved memory", uint64(gpuLayersReserved), 0),
+		Entry("with less VRAM than a layer", vramFor(150*mib), 0),
+		Entry("with the VRAM of one layer", vramFor(250*mib), 1),
+		Entry("with the VRAM of the layers but not of the output", vramFor(450*mib), 2),
+		Entry("with the VRAM of the whole model", vramFor(550*mib), 3),
+	)
+
+	It("fails to estimate the layers of the files which are not GGUF", func() {
+		path := filepath.Join(dir, "model.bin")
+		Expect(os.WriteFile(path, []byte("tjgg\x03\x00\x00\x00"), 0600)).To(Succeed())
+		_, err := estimateGPULayers(path, vramFor(550*mib))
+		Expect(err).To(HaveOccurred())
+	})
+
+	DescribeTable("reduces the layers after a failure",
+		func(n, expected int) {
+			Expect(fewerGPULayers(n)).To(Equal(expected))
+		},
+		Entry("of a large model", 40, 30),
+		Entry("of a small model", 4, 3),
+		Entry("down to the CPU", 1, 0),
+	)
+
+	It("reaches the CPU after a few failures", func() {
+		n, attempts := 80, 0
+		for n > 0 {
+			n = fewerGPULayers(n)
+			attempts++
+			Expect(attempts).To(BeNumerically("<", 20))
+		}
+	})
+
+	DescribeTable("recognizes the allocation failures",
+		func(msg string, expected bool) {
+			Expect(isOutOfMemory(errors.New(msg))).To(Equal(expected))
+		},
+		Entry("of CUDA", "CUDA error: out of memory", true),
+		Entry("of cudaMalloc", "cudaMalloc failed: out of memory", true),
+		Entry("of the ggml buffers", "ggml_backend_cuda_buffer_type_alloc_buffer: Failed to allocate 4096 MiB", true),
+		Entry("of a missing model", "could not load model: open model.gguf: no such file or directory", false),
+		Entry("of an invalid model", "invalid magic number", false),
+	)
+
+	It("retries with fewer layers until the model is loaded on the CPU, then gives up", func() {
+		b := &oomBackend{}
+		grpc.Provide("oom:0", b)
+		ml := NewModelLoader(dir)
+		o := NewOptions(
+			WithModel("model.gguf"),
+			WithExternalBackend("oom", "oom:0"),
+			WithAutoGPULayers(),
+			WithLoadGRPCLoadModelOpts(&pb.ModelOptions{NGPULayers: 8}),
+			WithGRPCAttempts(1),
+		)
+
+		_, err := ml.grpcModel("oom", o)("model.gguf", filepath.Join(dir, "model.gguf"))
+		Expect(err).To(MatchError(ContainSubstring("out of memory")))
+		Expect(b.attempts).To(Equal([]int32{8, 6, 4, 3, 2, 1, 0}))
+	})
+})
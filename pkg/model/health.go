@@ -0,0 +1,75 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// BackendHealthError is a failed health check of the backend of a model
+type BackendHealthError struct {
+	Error string    `json:"error"`
+	Time  time.Time `json:"time"`
+}
+
+// BackendHealth is the health of the backend serving a loaded model
+type BackendHealth struct {
+	Model   string `json:"model"`
+	Backend string `json:"backend"`
+	Address string `json:"address"`
+	Healthy bool   `json:"healthy"`
+	// Error is the reason of the failure of the check, if it failed
+	Error string `json:"error,omitempty"`
+	// LastError is the last failed check, kept once the backend recovered
+	LastError *BackendHealthError `json:"last_error,omitempty"`
+}
+
+// CheckBackends runs the health check of the backends of all the loaded models at once, each waiting at most timeout,
+// and returns their health sorted by model. The models being loaded are not checked.
+func (ml *ModelLoader) CheckBackends(ctx context.Context, timeout time.Duration) []BackendHealth {
+	ml.backendsMu.Lock()
+	health := make([]BackendHealth, 0, len(ml.addresses))
+	for m, addr := range ml.addresses {
+		health = append(health, BackendHealth{Model: m, Backend: ml.backends[m], Address: string(addr)})
+	}
+	ml.backendsMu.Unlock()
+
+	var wg sync.WaitGroup
+	for i := range health {
+		wg.Add(1)
+		go func(h *BackendHealth) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			// a client of its own, which does not wait for the requests being served by the backend
+			alive, err := ModelAddress(h.Address).GRPC(true, nil).HealthCheck(ctx)
+			h.Healthy = alive && err == nil
+			if !h.Healthy {
+				if err == nil {
+					err = fmt.Errorf("backend not alive")
+				}
+				h.Error = err.Error()
+			}
+		}(&health[i])
+	}
+	wg.Wait()
+
+	ml.backendsMu.Lock()
+	defer ml.backendsMu.Unlock()
+	for i, h := range health {
+		if _, loaded := ml.addresses[h.Model]; !loaded {
+			continue
+		}
+		if !h.Healthy {
+			ml.healthErrors[h.Model] = BackendHealthError{Error: h.Error, Time: time.Now()}
+		}
+		if e, ok := ml.healthErrors[h.Model]; ok {
+			health[i].LastError = &e
+		}
+	}
+
+	sort.Slice(health, func(i, j int) bool { return health[i].Model < health[j].Model })
+	return health
+}
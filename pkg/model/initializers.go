@@ -9,9 +9,9 @@ import (
 	"time"
 
 	grpc "github.com/go-skynet/LocalAI/pkg/grpc"
+	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
 	"github.com/hashicorp/go-multierror"
 	"github.com/phayes/freeport"
-	"github.com/rs/zerolog/log"
 )
 
 var Aliases map[string]string = map[string]string{
@@ -57,128 +57,211 @@ var AutoLoadBackends []string = []string{
 // It also loads the model
 func (ml *ModelLoader) grpcModel(backend string, o *Options) func(string, string) (ModelAddress, error) {
 	return func(modelName, modelFile string) (ModelAddress, error) {
-		log.Debug().Msgf("Loading Model %s with gRPC (file: %s) (backend: %s): %+v", modelName, modelFile, backend, *o)
+		o.logger().Debug().Msgf("Loading Model %s with gRPC (file: %s) (backend: %s): %+v", modelName, modelFile, backend, *o)
 
-		var client ModelAddress
+		options := *o.gRPCOptions
+		options.Model = modelName
+		options.ModelFile = modelFile
 
-		getFreeAddress := func() (string, error) {
-			port, err := freeport.GetFreePort()
-			if err != nil {
-				return "", fmt.Errorf("failed allocating free ports: %s", err.Error())
-			}
-			return fmt.Sprintf("127.0.0.1:%d", port), nil
+		if o.autoGPULayers && options.NGPULayers == 0 {
+			options.NGPULayers = int32(autoGPULayers(modelFile, o.gpus))
 		}
 
-		// Check if the backend is provided as external
-		if uri, ok := o.externalBackends[backend]; ok {
-			log.Debug().Msgf("Loading external backend: %s", uri)
-			// check if uri is a file or a address
-			if _, err := os.Stat(uri); err == nil {
-				serverAddress, err := getFreeAddress()
-				if err != nil {
-					return "", fmt.Errorf("failed allocating free ports: %s", err.Error())
-				}
-				// Make sure the process is executable
-				if err := ml.startProcess(uri, o.model, serverAddress); err != nil {
-					return "", err
-				}
-
-				log.Debug().Msgf("GRPC Service Started")
-
-				client = ModelAddress(serverAddress)
-			} else {
-				// address
-				client = ModelAddress(uri)
+		for {
+			client, err := ml.startGRPCModel(backend, o, &options)
+			if err == nil || !o.autoGPULayers || options.NGPULayers == 0 || !(isOutOfMemory(err) || ml.processExited(o.model)) {
+				return client, err
 			}
-		} else {
-			grpcProcess := filepath.Join(o.assetDir, "backend-assets", "grpc", backend)
-			// Check if the file exists
-			if _, err := os.Stat(grpcProcess); os.IsNotExist(err) {
-				return "", fmt.Errorf("grpc process not found: %s. some backends(stablediffusion, tts) require LocalAI compiled with GO_TAGS", grpcProcess)
+
+			// the backend could not allocate the layers (or crashed trying): start over with fewer of them
+			layers := fewerGPULayers(int(options.NGPULayers))
+			o.logger().Warn().Msgf("Failed loading model %s with %d GPU layers, retrying with %d: %s", modelName, options.NGPULayers, layers, err.Error())
+			if _, ok := ml.grpcProcess(o.model); ok {
+				ml.deleteProcess(o.model)
 			}
+			options.NGPULayers = int32(layers)
+		}
+	}
+}
+
+// startGRPCModel starts (or connects to) the gRPC service of the backend, and loads the model with the given options
+func (ml *ModelLoader) startGRPCModel(backend string, o *Options, options *pb.ModelOptions) (ModelAddress, error) {
+	modelName := options.Model
+	var client ModelAddress
 
+	getFreeAddress := func() (string, error) {
+		port, err := freeport.GetFreePort()
+		if err != nil {
+			return "", fmt.Errorf("failed allocating free ports: %s", err.Error())
+		}
+		return fmt.Sprintf("127.0.0.1:%d", port), nil
+	}
+
+	// Check if the backend is provided as external
+	if uri, ok := o.externalBackends[backend]; ok {
+		o.logger().Debug().Msgf("Loading external backend: %s", uri)
+		// check if uri is a file or a address
+		if _, err := os.Stat(uri); err == nil {
 			serverAddress, err := getFreeAddress()
 			if err != nil {
 				return "", fmt.Errorf("failed allocating free ports: %s", err.Error())
 			}
-
 			// Make sure the process is executable
-			if err := ml.startProcess(grpcProcess, o.model, serverAddress); err != nil {
+			if err := ml.startProcess(uri, o.model, serverAddress, o); err != nil {
 				return "", err
 			}
 
-			log.Debug().Msgf("GRPC Service Started")
+			o.logger().Debug().Msgf("GRPC Service Started")
 
 			client = ModelAddress(serverAddress)
+		} else {
+			// address
+			client = ModelAddress(uri)
+			if ml.remoteCredentials != nil {
+				grpc.Secure(uri, ml.remoteCredentials)
+			}
+		}
+	} else {
+		grpcProcess := filepath.Join(o.assetDir, "backend-assets", "grpc", backend)
+		// Check if the file exists
+		if _, err := os.Stat(grpcProcess); os.IsNotExist(err) {
+			return "", fmt.Errorf("grpc process not found: %s. some backends(stablediffusion, tts) require LocalAI compiled with GO_TAGS", grpcProcess)
 		}
 
-		// Wait for the service to start up
-		ready := false
-		for i := 0; i < o.grpcAttempts; i++ {
-			alive, err := client.GRPC(o.parallelRequests, ml.wd).HealthCheck(context.Background())
-			if alive {
-				log.Debug().Msgf("GRPC Service Ready")
-				ready = true
-				break
-			}
-			if err != nil && i == o.grpcAttempts-1 {
-				log.Error().Msgf("Failed starting/connecting to the gRPC service: %s", err.Error())
-			}
-			time.Sleep(time.Duration(o.grpcAttemptsDelay) * time.Second)
+		serverAddress, err := getFreeAddress()
+		if err != nil {
+			return "", fmt.Errorf("failed allocating free ports: %s", err.Error())
 		}
 
-		if !ready {
-			log.Debug().Msgf("GRPC Service NOT ready")
-			return "", fmt.Errorf("grpc service not ready")
+		// Make sure the process is executable
+		if err := ml.startProcess(grpcProcess, o.model, serverAddress, o); err != nil {
+			return "", err
 		}
 
-		options := *o.gRPCOptions
-		options.Model = modelName
-		options.ModelFile = modelFile
+		o.logger().Debug().Msgf("GRPC Service Started")
 
-		log.Debug().Msgf("GRPC: Loading model with options: %+v", options)
+		client = ModelAddress(serverAddress)
+	}
+
+	retry := o.retryPolicy()
+
+	// Wait for the service to start up
+	err := retry.do(o.context, func() (bool, error) {
+		alive, err := client.GRPC(o.parallelRequests, ml.wd, o.grpcClientOptions...).HealthCheck(context.Background())
+		if alive {
+			return false, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("service not alive")
+		}
+		o.logger().Debug().Msgf("GRPC Service not ready yet: %s", err.Error())
+		// dial errors and failing health checks are expected while the backend starts up
+		return true, err
+	})
+	if err != nil {
+		o.logger().Error().Msgf("Failed starting/connecting to the gRPC service: %s", err.Error())
+		o.logger().Debug().Msgf("GRPC Service NOT ready")
+		if crash := ml.backendCrash(o.model); crash != nil {
+			return "", fmt.Errorf("grpc service not ready: %s", crash)
+		}
+		return "", fmt.Errorf("grpc service not ready")
+	}
+	o.logger().Debug().Msgf("GRPC Service Ready")
 
-		res, err := client.GRPC(o.parallelRequests, ml.wd).LoadModel(o.context, &options)
+	caps, err := grpc.Negotiate(o.context, client.GRPC(o.parallelRequests, ml.wd, o.grpcClientOptions...))
+	if err != nil {
+		return "", err
+	}
+	if caps.ProtocolVersion != grpc.ProtocolVersion {
+		o.logger().Warn().Msgf("GRPC: backend %s speaks protocol version %d, LocalAI version %d", backend, caps.ProtocolVersion, grpc.ProtocolVersion)
+	}
+
+	o.logger().Debug().Msgf("GRPC: Loading model with options: %+v", options)
+
+	err = retry.do(o.context, func() (bool, error) {
+		res, err := client.GRPC(o.parallelRequests, ml.wd, o.grpcClientOptions...).LoadModel(o.context, options)
 		if err != nil {
-			return "", fmt.Errorf("could not load model: %w", err)
+			if ml.processExited(o.model) {
+				if crash := ml.backendCrash(o.model); crash != nil {
+					return false, fmt.Errorf("backend process exited while loading the model: %s: %w", crash, err)
+				}
+				return false, fmt.Errorf("backend process exited while loading the model: %w", err)
+			}
+			if isTransient(err) {
+				o.logger().Debug().Msgf("GRPC: transient error while loading the model, retrying: %s", err.Error())
+				return true, fmt.Errorf("could not load model: %w", err)
+			}
+			return false, fmt.Errorf("could not load model: %w", err)
 		}
 		if !res.Success {
-			return "", fmt.Errorf("could not load model (no success): %s", res.Message)
+			return false, fmt.Errorf("could not load model (no success): %s", res.Message)
+		}
+		return false, nil
+	})
+	if err != nil {
+		if caps.ProtocolVersion < grpc.ProtocolVersion {
+			return "", fmt.Errorf("%w (the backend speaks the older protocol version %d, its assets might be outdated)", err, caps.ProtocolVersion)
 		}
+		return "", err
+	}
 
-		return client, nil
+	if o.warmup != nil && grpc.HasCapability(caps, grpc.CapabilityPredict) {
+		o.logger().Debug().Msgf("GRPC: Warming up model %s", modelName)
+		start := time.Now()
+		// failures are not fatal: not all the backends support predictions
+		if _, err := client.GRPC(o.parallelRequests, ml.wd, o.grpcClientOptions...).Predict(o.context, o.warmup); err != nil {
+			o.logger().Warn().Msgf("GRPC: Warm-up of model %s failed: %s", modelName, err.Error())
+		} else {
+			o.logger().Debug().Msgf("GRPC: Model %s warmed up in %s", modelName, time.Since(start))
+		}
 	}
+
+	return client, nil
 }
 
-func (ml *ModelLoader) resolveAddress(addr ModelAddress, parallel bool) (grpc.Backend, error) {
+// observeLoad reports the loads of the models by the backend to the load observer, if any
+func (ml *ModelLoader) observeLoad(backend string, loader func(string, string) (ModelAddress, error)) func(string, string) (ModelAddress, error) {
+	if ml.loadObserver == nil {
+		return loader
+	}
+	return func(modelName, modelFile string) (ModelAddress, error) {
+		start := time.Now()
+		addr, err := loader(modelName, modelFile)
+		ml.loadObserver(backend, modelName, time.Since(start), err)
+		return addr, err
+	}
+}
+
+func (ml *ModelLoader) resolveAddress(addr ModelAddress, parallel bool, opts ...grpc.ClientOption) (grpc.Backend, error) {
 	if parallel {
-		return addr.GRPC(parallel, ml.wd), nil
+		return addr.GRPC(parallel, ml.wd, opts...), nil
 	}
 
 	if _, ok := ml.grpcClients[string(addr)]; !ok {
-		ml.grpcClients[string(addr)] = addr.GRPC(parallel, ml.wd)
+		ml.grpcClients[string(addr)] = addr.GRPC(parallel, ml.wd, opts...)
 	}
 	return ml.grpcClients[string(addr)], nil
 }
 
 func (ml *ModelLoader) BackendLoader(opts ...Option) (client grpc.Backend, err error) {
 	o := NewOptions(opts...)
+	ml.addExternalBackends(o)
 
 	if o.model != "" {
-		log.Info().Msgf("Loading model '%s' with backend %s", o.model, o.backendString)
+		o.logger().Info().Msgf("Loading model '%s' with backend %s", o.model, o.backendString)
 	} else {
-		log.Info().Msgf("Loading model with backend %s", o.backendString)
+		o.logger().Info().Msgf("Loading model with backend %s", o.backendString)
 	}
 
 	backend := strings.ToLower(o.backendString)
 	if realBackend, exists := Aliases[backend]; exists {
 		backend = realBackend
-		log.Debug().Msgf("%s is an alias of %s", backend, realBackend)
+		o.logger().Debug().Msgf("%s is an alias of %s", backend, realBackend)
 	}
 
 	if o.singleActiveBackend {
 		ml.mu.Lock()
-		log.Debug().Msgf("Stopping all backends except '%s'", o.model)
+		o.logger().Debug().Msgf("Stopping all backends except '%s'", o.model)
 		ml.StopAllExcept(o.model)
 		ml.mu.Unlock()
 	}
@@ -196,29 +279,33 @@ func (ml *ModelLoader) BackendLoader(opts ...Option) (client grpc.Backend, err e
 		backendToConsume = backend
 	}
 
-	addr, err := ml.LoadModel(o.model, ml.grpcModel(backendToConsume, o))
+	addr, err := ml.LoadModel(o.model, ml.observeLoad(backendToConsume, ml.grpcModel(backendToConsume, o)))
 	if err != nil {
 		return nil, err
 	}
+	if _, loaded := ml.LoadedBackend(o.model); !loaded {
+		ml.setLoadedBackend(o.model, backendToConsume, addr)
+	}
 
-	return ml.resolveAddress(addr, o.parallelRequests)
+	return ml.resolveAddress(addr, o.parallelRequests, o.grpcClientOptions...)
 }
 
 func (ml *ModelLoader) GreedyLoader(opts ...Option) (grpc.Backend, error) {
 	o := NewOptions(opts...)
+	ml.addExternalBackends(o)
 
 	ml.mu.Lock()
 	// Return earlier if we have a model already loaded
 	// (avoid looping through all the backends)
 	if m := ml.CheckIsLoaded(o.model); m != "" {
-		log.Debug().Msgf("Model '%s' already loaded", o.model)
+		o.logger().Debug().Msgf("Model '%s' already loaded", o.model)
 		ml.mu.Unlock()
 
-		return ml.resolveAddress(m, o.parallelRequests)
+		return ml.resolveAddress(m, o.parallelRequests, o.grpcClientOptions...)
 	}
 	// If we can have only one backend active, kill all the others (except external backends)
 	if o.singleActiveBackend {
-		log.Debug().Msgf("Stopping all backends except '%s'", o.model)
+		o.logger().Debug().Msgf("Stopping all backends except '%s'", o.model)
 		ml.StopAllExcept(o.model)
 	}
 	ml.mu.Unlock()
@@ -228,38 +315,43 @@ func (ml *ModelLoader) GreedyLoader(opts ...Option) (grpc.Backend, error) {
 	// autoload also external backends
 	allBackendsToAutoLoad := []string{}
 	allBackendsToAutoLoad = append(allBackendsToAutoLoad, AutoLoadBackends...)
-	for _, b := range o.externalBackends {
+	for b := range o.externalBackends {
 		allBackendsToAutoLoad = append(allBackendsToAutoLoad, b)
 	}
 
 	if o.model != "" {
-		log.Info().Msgf("Trying to load the model '%s' with all the available backends: %s", o.model, strings.Join(allBackendsToAutoLoad, ", "))
+		o.logger().Info().Msgf("Trying to load the model '%s' with all the available backends: %s", o.model, strings.Join(allBackendsToAutoLoad, ", "))
 	}
 
 	for _, b := range allBackendsToAutoLoad {
-		log.Info().Msgf("[%s] Attempting to load", b)
+		o.logger().Info().Msgf("[%s] Attempting to load", b)
 		options := []Option{
 			WithBackendString(b),
 			WithModel(o.model),
 			WithLoadGRPCLoadModelOpts(o.gRPCOptions),
 			WithThreads(o.threads),
 			WithAssetDir(o.assetDir),
+			WithLogger(o.log),
 		}
 
 		for k, v := range o.externalBackends {
 			options = append(options, WithExternalBackend(k, v))
 		}
 
+		if o.autoGPULayers {
+			options = append(options, WithAutoGPULayers())
+		}
+
 		model, modelerr := ml.BackendLoader(options...)
 		if modelerr == nil && model != nil {
-			log.Info().Msgf("[%s] Loads OK", b)
+			o.logger().Info().Msgf("[%s] Loads OK", b)
 			return model, nil
 		} else if modelerr != nil {
 			err = multierror.Append(err, modelerr)
-			log.Info().Msgf("[%s] Fails: %s", b, modelerr.Error())
+			o.logger().Info().Msgf("[%s] Fails: %s", b, modelerr.Error())
 		} else if model == nil {
 			err = multierror.Append(err, fmt.Errorf("backend returned no usable model"))
-			log.Info().Msgf("[%s] Fails: %s", b, "backend returned no usable model")
+			o.logger().Info().Msgf("[%s] Fails: %s", b, "backend returned no usable model")
 		}
 	}
 
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// Isolation restricts the filesystem view of a backend process started by the loader to
+// the system directories, the backend, the model and the given paths, so a compromised
+// backend can't read the other models or the configuration of the server.
+// It requires bubblewrap (https://github.com/containers/bubblewrap) and is only supported on Linux.
+type Isolation struct {
+	// ReadOnly are additional paths the backend can read (e.g. files referenced by the model)
+	ReadOnly []string
+	// ReadWrite are the paths the backend can write to (e.g. where generated images are written)
+	ReadWrite []string
+}
+
+// system directories visible to the isolated backends, when they exist
+var isolationSystemPaths = []string{"/usr", "/bin", "/sbin", "/lib", "/lib32", "/lib64", "/etc"}
+
+// command returns the command running the backend, started with name and the given arguments, in the sandbox
+func (i *Isolation) command(backend string, readOnly []string, name string, args ...string) (string, []string, error) {
+	if runtime.GOOS != "linux" {
+		return "", nil, fmt.Errorf("filesystem isolation of the backends is only supported on Linux")
+	}
+	bwrap, err := exec.LookPath("bwrap")
+	if err != nil {
+		return "", nil, fmt.Errorf("filesystem isolation of the backends requires bubblewrap (bwrap): %w", err)
+	}
+
+	wrapped := []string{
+		"--die-with-parent",
+		"--proc", "/proc",
+		// devices are needed to access the GPUs
+		"--dev-bind", "/dev", "/dev",
+		"--ro-bind-try", "/sys", "/sys",
+		"--tmpfs", "/tmp",
+	}
+	paths := append([]string{}, isolationSystemPaths...)
+	for _, p := range append(append(paths, readOnly...), i.ReadOnly...) {
+		if p == "" {
+			continue
+		}
+		p, _ = filepath.Abs(p)
+		wrapped = append(wrapped, "--ro-bind-try", p, p)
+	}
+	// mounted last, so they can be inside the read-only paths (or /tmp)
+	for _, p := range i.ReadWrite {
+		if p == "" {
+			continue
+		}
+		p, _ = filepath.Abs(p)
+		wrapped = append(wrapped, "--bind-try", p, p)
+	}
+	wrapped = append(wrapped, "--chdir", filepath.Dir(backend), "--", name)
+
+	return bwrap, append(wrapped, args...), nil
+}
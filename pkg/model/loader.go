@@ -5,13 +5,16 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
 	"text/template"
+	"time"
 
 	grammar "github.com/go-skynet/LocalAI/pkg/grammar"
 	"github.com/go-skynet/LocalAI/pkg/grpc"
+	"github.com/go-skynet/LocalAI/pkg/storage"
 	process "github.com/mudler/go-processmanager"
 	"github.com/rs/zerolog/log"
 )
@@ -56,24 +59,55 @@ const (
 
 // TODO: Split ModelLoader and TemplateLoader? Just to keep things more organized. Left together to share a mutex until I look into that. Would split if we seperate directories for .bin/.yaml and .tmpl
 type ModelLoader struct {
+	// ModelPath is the writable directory of the models, where they are downloaded
 	ModelPath string
-	mu        sync.Mutex
+	// dirs are all the directories searched for the models, in order
+	dirs []ModelDir
+	mu   sync.Mutex
 	// TODO: this needs generics
-	grpcClients   map[string]grpc.Backend
-	models        map[string]ModelAddress
+	grpcClients map[string]grpc.Backend
+	models      map[string]ModelAddress
+	templates   map[TemplateType]map[string]*template.Template
+	wd          *WatchDog
+
+	// processes of the backends started by LocalAI, kept apart from models to be readable while a model is loading
+	processesMu   sync.Mutex
 	grpcProcesses map[string]*process.Process
-	templates     map[TemplateType]map[string]*template.Template
-	wd            *WatchDog
+
+	externalMu       sync.Mutex
+	externalBackends map[string]string
+
+	// backends serving the loaded models, kept apart from models to be readable while a model is loading
+	backendsMu sync.Mutex
+	backends   map[string]string
+	addresses  map[string]ModelAddress
+	// the last failed health check of the backends, by model
+	healthErrors map[string]BackendHealthError
+
+	// storage backing the model path, if any
+	storage *storage.Cache
+
+	// credentials of the connections to the remote backends, if any
+	remoteCredentials *grpc.Credentials
+
+	// called after each backend tried to load a model
+	loadObserver func(backend, model string, duration time.Duration, err error)
+
+	// the processes followed for crashes, and the last crash, by model
+	crashMu  sync.Mutex
+	watches  map[string]*processWatch
+	crashes  map[string]Crash
+	crashDir string
 }
 
 type ModelAddress string
 
-func (m ModelAddress) GRPC(parallel bool, wd *WatchDog) grpc.Backend {
+func (m ModelAddress) GRPC(parallel bool, wd *WatchDog, opts ...grpc.ClientOption) grpc.Backend {
 	enableWD := false
 	if wd != nil {
 		enableWD = true
 	}
-	return grpc.NewClient(string(m), parallel, wd, enableWD)
+	return grpc.NewClient(string(m), parallel, wd, enableWD, opts...)
 }
 
 func NewModelLoader(modelPath string) *ModelLoader {
@@ -83,6 +117,13 @@ func NewModelLoader(modelPath string) *ModelLoader {
 		models:        make(map[string]ModelAddress),
 		templates:     make(map[TemplateType]map[string]*template.Template),
 		grpcProcesses: make(map[string]*process.Process),
+
+		externalBackends: make(map[string]string),
+		backends:         make(map[string]string),
+		addresses:        make(map[string]ModelAddress),
+		healthErrors:     make(map[string]BackendHealthError),
+		watches:          make(map[string]*processWatch),
+		crashes:          make(map[string]Crash),
 	}
 
 	nml.initializeTemplateMap()
@@ -93,16 +134,55 @@ func (ml *ModelLoader) SetWatchDog(wd *WatchDog) {
 	ml.wd = wd
 }
 
+// SetStorage backs the model path with object storage: the models missing in the model path are fetched from it when loaded
+func (ml *ModelLoader) SetStorage(c *storage.Cache) {
+	ml.storage = c
+}
+
+// SetRemoteCredentials authenticates the connections to the external backends given by address with the credentials
+func (ml *ModelLoader) SetRemoteCredentials(c *grpc.Credentials) {
+	ml.remoteCredentials = c
+}
+
+// SetLoadObserver sets f to be called each time a backend loaded a model, or failed to, with the time it took
+func (ml *ModelLoader) SetLoadObserver(f func(backend, model string, duration time.Duration, err error)) {
+	ml.loadObserver = f
+}
+
 func (ml *ModelLoader) ExistsInModelPath(s string) bool {
-	return existsInPath(ml.ModelPath, s)
+	return ml.existsInDirs(s) || (ml.storage != nil && ml.storage.Exists(s))
 }
 
 func (ml *ModelLoader) ListModels() ([]string, error) {
-	files, err := os.ReadDir(ml.ModelPath)
+	models, err := ml.listModels("")
 	if err != nil {
 		return []string{}, err
 	}
 
+	// the models of the storage which are not cached yet
+	if ml.storage != nil {
+		for _, m := range ml.storage.Models() {
+			if !strings.Contains(m, "/") && !ml.existsInDirs(m) {
+				models = append(models, m)
+			}
+		}
+	}
+
+	return models, nil
+}
+
+// ListModelsIn returns the models of a subdirectory of the models directories, named by their path relative to the
+// models directories
+func (ml *ModelLoader) ListModelsIn(subdir string) ([]string, error) {
+	return ml.listModels(subdir)
+}
+
+func (ml *ModelLoader) listModels(subdir string) ([]string, error) {
+	files, err := ml.readModelDirs(subdir)
+	if err != nil {
+		return nil, err
+	}
+
 	models := []string{}
 	for _, file := range files {
 		// Skip templates, YAML, .keep, .json, and .DS_Store files - TODO: as this list grows, is there a more efficient method?
@@ -110,9 +190,8 @@ func (ml *ModelLoader) ListModels() ([]string, error) {
 			continue
 		}
 
-		models = append(models, file.Name())
+		models = append(models, path.Join(subdir, file.Name()))
 	}
-
 	return models, nil
 }
 
@@ -125,12 +204,23 @@ func (ml *ModelLoader) LoadModel(modelName string, loader func(string, string) (
 		return model, nil
 	}
 
+	if ml.storage != nil && ml.storage.Exists(modelName) {
+		if _, err := ml.storage.Fetch(context.Background(), modelName); err != nil {
+			return "", err
+		}
+		// keep the model in the cache while it is loaded
+		ml.storage.Acquire(modelName)
+	}
+
 	// Load the model and keep it in memory for later use
-	modelFile := filepath.Join(ml.ModelPath, modelName)
+	modelFile := ml.ModelFile(modelName)
 	log.Debug().Msgf("Loading model in memory from file: %s", modelFile)
 
 	model, err := loader(modelName, modelFile)
 	if err != nil {
+		if ml.storage != nil && ml.storage.Exists(modelName) {
+			ml.storage.Release(modelName)
+		}
 		return "", err
 	}
 
@@ -146,6 +236,46 @@ func (ml *ModelLoader) LoadModel(modelName string, loader func(string, string) (
 	return model, nil
 }
 
+// LoadedBackend returns the backend serving the model, and false if the model is not loaded
+func (ml *ModelLoader) LoadedBackend(modelName string) (string, bool) {
+	ml.backendsMu.Lock()
+	defer ml.backendsMu.Unlock()
+	b, ok := ml.backends[modelName]
+	return b, ok
+}
+
+// LoadedAddress returns the address of the backend serving the model, and false if the model is not loaded
+func (ml *ModelLoader) LoadedAddress(modelName string) (ModelAddress, bool) {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+	addr, ok := ml.models[modelName]
+	return addr, ok
+}
+
+// LoadedBackends returns the number of loaded models served by each backend
+func (ml *ModelLoader) LoadedBackends() map[string]int {
+	ml.backendsMu.Lock()
+	defer ml.backendsMu.Unlock()
+	counts := map[string]int{}
+	for _, b := range ml.backends {
+		counts[b]++
+	}
+	return counts
+}
+
+func (ml *ModelLoader) setLoadedBackend(modelName, backend string, addr ModelAddress) {
+	ml.backendsMu.Lock()
+	defer ml.backendsMu.Unlock()
+	if backend == "" {
+		delete(ml.backends, modelName)
+		delete(ml.addresses, modelName)
+		delete(ml.healthErrors, modelName)
+		return
+	}
+	ml.backends[modelName] = backend
+	ml.addresses[modelName] = addr
+}
+
 func (ml *ModelLoader) ShutdownModel(modelName string) error {
 	ml.mu.Lock()
 	defer ml.mu.Unlock()
@@ -175,8 +305,10 @@ func (ml *ModelLoader) CheckIsLoaded(s string) ModelAddress {
 		if !alive {
 			log.Warn().Msgf("GRPC Model not responding: %s", err.Error())
 			log.Warn().Msgf("Deleting the process in order to recreate it")
-			if !ml.grpcProcesses[s].IsAlive() {
+			if p, ok := ml.grpcProcess(s); ok && !p.IsAlive() {
 				log.Debug().Msgf("GRPC Process is not responding: %s", s)
+				// collect the crash before the process is deleted as stopped
+				ml.backendCrash(s)
 				// stop and delete the process, this forces to re-load the model and re-create again the service
 				ml.deleteProcess(s)
 				return ""
@@ -250,7 +382,7 @@ func (ml *ModelLoader) loadTemplateIfExists(templateType TemplateType, templateN
 
 	dat := ""
 	if ml.ExistsInModelPath(modelTemplateFile) {
-		d, err := os.ReadFile(filepath.Join(ml.ModelPath, modelTemplateFile))
+		d, err := os.ReadFile(ml.ModelFile(modelTemplateFile))
 		if err != nil {
 			return err
 		}
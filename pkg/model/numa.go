@@ -0,0 +1,37 @@
+package model
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// numaCommand returns the command running the backend with the given arguments under the NUMA memory policy, which
+// is either interleave (the memory is spread over all the nodes) or node:N (the backend runs and allocates on node N).
+// It requires numactl and is only supported on Linux.
+func numaCommand(policy string, backend string, args ...string) (string, []string, error) {
+	var numa []string
+	switch {
+	case policy == "interleave":
+		numa = []string{"--interleave=all"}
+	case strings.HasPrefix(policy, "node:"):
+		node, err := strconv.Atoi(strings.TrimPrefix(policy, "node:"))
+		if err != nil || node < 0 {
+			return "", nil, fmt.Errorf("invalid NUMA node in %q", policy)
+		}
+		numa = []string{fmt.Sprintf("--cpunodebind=%d", node), fmt.Sprintf("--membind=%d", node)}
+	default:
+		return "", nil, fmt.Errorf("unknown NUMA policy %q", policy)
+	}
+
+	if runtime.GOOS != "linux" {
+		return "", nil, fmt.Errorf("NUMA policies of the backends are only supported on Linux")
+	}
+	numactl, err := exec.LookPath("numactl")
+	if err != nil {
+		return "", nil, fmt.Errorf("the NUMA policy %q requires numactl: %w", policy, err)
+	}
+	return numactl, append(append(numa, "--", backend), args...), nil
+}
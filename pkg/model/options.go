@@ -2,8 +2,14 @@ package model
 
 import (
 	"context"
+	"strconv"
+	"strings"
 
+	grpc "github.com/go-skynet/LocalAI/pkg/grpc"
 	pb "github.com/go-skynet/LocalAI/pkg/grpc/proto"
+	"github.com/go-skynet/LocalAI/pkg/sandbox"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
 )
 
 type Options struct {
@@ -17,10 +23,24 @@ type Options struct {
 
 	externalBackends map[string]string
 
-	grpcAttempts        int
-	grpcAttemptsDelay   int
-	singleActiveBackend bool
-	parallelRequests    bool
+	grpcAttempts          int
+	grpcAttemptsDelay     int
+	grpcMaxDelay          int
+	grpcBackoffMultiplier float64
+	grpcJitter            float64
+	grpcDeadline          int
+	grpcClientOptions     []grpc.ClientOption
+	processLimits         ProcessLimits
+	isolation             *Isolation
+	sandbox               *sandbox.Spec
+	numaPolicy            string
+	warmup                *pb.PredictOptions
+	environment           []string
+	autoGPULayers         bool
+	gpus                  []int
+	singleActiveBackend   bool
+	parallelRequests      bool
+	log                   *zerolog.Logger
 }
 
 type Option func(*Options)
@@ -50,6 +70,106 @@ func WithGRPCAttemptsDelay(delay int) Option {
 	}
 }
 
+// WithGRPCBackoff sets the exponential backoff applied between gRPC attempts:
+// the delay is multiplied by multiplier after each failed attempt, up to maxDelay seconds (0 means no limit).
+func WithGRPCBackoff(multiplier float64, maxDelay int) Option {
+	return func(o *Options) {
+		o.grpcBackoffMultiplier = multiplier
+		o.grpcMaxDelay = maxDelay
+	}
+}
+
+// WithGRPCJitter randomizes the delay between gRPC attempts by +/- the given fraction (e.g. 0.1 for 10%)
+func WithGRPCJitter(jitter float64) Option {
+	return func(o *Options) {
+		o.grpcJitter = jitter
+	}
+}
+
+// WithGRPCDeadline sets an overall deadline, in seconds, after which no more gRPC attempts are made
+func WithGRPCDeadline(deadline int) Option {
+	return func(o *Options) {
+		o.grpcDeadline = deadline
+	}
+}
+
+// WithGRPCClientOptions sets the options used to create the gRPC clients of the backend (keepalive, call deadlines)
+func WithGRPCClientOptions(opts ...grpc.ClientOption) Option {
+	return func(o *Options) {
+		o.grpcClientOptions = append(o.grpcClientOptions, opts...)
+	}
+}
+
+// WithProcessLimits constrains the resources of the backend process, when it is started by LocalAI
+func WithProcessLimits(limits ProcessLimits) Option {
+	return func(o *Options) {
+		o.processLimits = limits
+	}
+}
+
+// WithSandbox runs the backend process as another user, with Landlock or a seccomp profile, when it is started by
+// LocalAI
+func WithSandbox(spec sandbox.Spec) Option {
+	return func(o *Options) {
+		o.sandbox = &spec
+	}
+}
+
+// WithIsolation restricts the filesystem view of the backend process, when it is started by LocalAI
+func WithIsolation(isolation Isolation) Option {
+	return func(o *Options) {
+		o.isolation = &isolation
+	}
+}
+
+// WithNUMAPolicy runs the backend process under the NUMA memory policy (interleave or node:N), when it is started by LocalAI
+func WithNUMAPolicy(policy string) Option {
+	return func(o *Options) {
+		o.numaPolicy = policy
+	}
+}
+
+// WithWarmup runs a prediction with the given options once the model is loaded, before it is
+// used for the first request, so that GPU kernels are compiled (and graphs captured, with the
+// backends supporting it) ahead of time.
+func WithWarmup(opts *pb.PredictOptions) Option {
+	return func(o *Options) {
+		o.warmup = opts
+	}
+}
+
+// WithEnvironment sets environment variables (in the KEY=VALUE form) for the backend process only
+func WithEnvironment(env ...string) Option {
+	return func(o *Options) {
+		o.environment = append(o.environment, env...)
+	}
+}
+
+// WithGPUs restricts the backend process to the given GPUs, which it numbers from 0 in this order
+// (e.g. for the main GPU and the tensor split)
+func WithGPUs(ids ...int) Option {
+	return func(o *Options) {
+		o.gpus = ids
+		devices := make([]string, len(ids))
+		for i, id := range ids {
+			devices[i] = strconv.Itoa(id)
+		}
+		// NVIDIA (CUDA) and AMD (ROCm) GPUs
+		o.environment = append(o.environment,
+			"CUDA_VISIBLE_DEVICES="+strings.Join(devices, ","),
+			"HIP_VISIBLE_DEVICES="+strings.Join(devices, ","),
+		)
+	}
+}
+
+// WithAutoGPULayers computes the number of layers to offload to the GPU from the free VRAM and the size of
+// the layers of the model, when not set explicitly, and retries with fewer layers if the backend runs out of memory
+func WithAutoGPULayers() Option {
+	return func(o *Options) {
+		o.autoGPULayers = true
+	}
+}
+
 func WithBackendString(backend string) Option {
 	return func(o *Options) {
 		o.backendString = backend
@@ -86,6 +206,20 @@ func WithContext(ctx context.Context) Option {
 	}
 }
 
+// WithLogger logs the loading of the model with l, e.g. to log the ID of the request which loads it
+func WithLogger(l *zerolog.Logger) Option {
+	return func(o *Options) {
+		o.log = l
+	}
+}
+
+func (o *Options) logger() *zerolog.Logger {
+	if o.log == nil {
+		return &log.Logger
+	}
+	return o.log
+}
+
 func WithSingleActiveBackend() Option {
 	return func(o *Options) {
 		o.singleActiveBackend = true
@@ -94,10 +228,11 @@ func WithSingleActiveBackend() Option {
 
 func NewOptions(opts ...Option) *Options {
 	o := &Options{
-		gRPCOptions:       &pb.ModelOptions{},
-		context:           context.Background(),
-		grpcAttempts:      20,
-		grpcAttemptsDelay: 2,
+		gRPCOptions:           &pb.ModelOptions{},
+		context:               context.Background(),
+		grpcAttempts:          20,
+		grpcAttemptsDelay:     2,
+		grpcBackoffMultiplier: 1,
 	}
 	for _, opt := range opts {
 		opt(o)
@@ -4,13 +4,16 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/go-skynet/LocalAI/pkg/logstream"
 	"github.com/hpcloud/tail"
 	process "github.com/mudler/go-processmanager"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
@@ -28,15 +31,38 @@ func (ml *ModelLoader) StopAllExcept(s string) {
 	})
 }
 
+// grpcProcess returns the process of the backend started by LocalAI for the model with the given id
+func (ml *ModelLoader) grpcProcess(id string) (*process.Process, bool) {
+	ml.processesMu.Lock()
+	defer ml.processesMu.Unlock()
+	p, ok := ml.grpcProcesses[id]
+	return p, ok
+}
+
 func (ml *ModelLoader) deleteProcess(s string) error {
-	if err := ml.grpcProcesses[s].Stop(); err != nil {
-		return err
+	ml.stopWatch(s)
+	if p, ok := ml.grpcProcess(s); ok {
+		if err := p.Stop(); err != nil {
+			return err
+		}
 	}
+	ml.processesMu.Lock()
 	delete(ml.grpcProcesses, s)
+	ml.processesMu.Unlock()
+	if _, ok := ml.models[s]; ok && ml.storage != nil && ml.storage.Exists(s) {
+		ml.storage.Release(s)
+	}
 	delete(ml.models, s)
+	ml.setLoadedBackend(s, "", "")
 	return nil
 }
 
+// processExited returns true if the backend process with the given id was started by LocalAI and is not running anymore
+func (ml *ModelLoader) processExited(id string) bool {
+	p, ok := ml.grpcProcess(id)
+	return ok && !p.IsAlive()
+}
+
 type GRPCProcessFilter = func(id string, p *process.Process) bool
 
 func includeAllProcesses(_ string, _ *process.Process) bool {
@@ -44,11 +70,18 @@ func includeAllProcesses(_ string, _ *process.Process) bool {
 }
 
 func (ml *ModelLoader) StopGRPC(filter GRPCProcessFilter) {
+	ml.processesMu.Lock()
+	stop := []string{}
 	for k, p := range ml.grpcProcesses {
 		if filter(k, p) {
-			ml.deleteProcess(k)
+			stop = append(stop, k)
 		}
 	}
+	ml.processesMu.Unlock()
+
+	for _, k := range stop {
+		ml.deleteProcess(k)
+	}
 }
 
 func (ml *ModelLoader) StopAllGRPC() {
@@ -56,28 +89,102 @@ func (ml *ModelLoader) StopAllGRPC() {
 }
 
 func (ml *ModelLoader) GetGRPCPID(id string) (int, error) {
-	p, exists := ml.grpcProcesses[id]
+	p, exists := ml.grpcProcess(id)
 	if !exists {
 		return -1, fmt.Errorf("no grpc backend found for %s", id)
 	}
 	return strconv.Atoi(p.PID)
 }
 
-func (ml *ModelLoader) startProcess(grpcProcess, id string, serverAddress string) error {
+// mergeEnv returns base with the variables in overrides added, replacing the ones with the same name. When a variable
+// is set several times in overrides, the last value wins.
+func mergeEnv(base, overrides []string) []string {
+	if len(overrides) == 0 {
+		return base
+	}
+
+	last := map[string]int{}
+	for i, kv := range overrides {
+		name, _, _ := strings.Cut(kv, "=")
+		last[name] = i
+	}
+
+	env := []string{}
+	for _, kv := range base {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, overridden := last[name]; !overridden {
+			env = append(env, kv)
+		}
+	}
+	for i, kv := range overrides {
+		name, _, _ := strings.Cut(kv, "=")
+		if last[name] == i {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
+
+func (ml *ModelLoader) startProcess(grpcProcess, id string, serverAddress string, o *Options) error {
 	// Make sure the process is executable
 	if err := os.Chmod(grpcProcess, 0755); err != nil {
 		return err
 	}
 
-	log.Debug().Msgf("Loading GRPC Process: %s", grpcProcess)
+	o.logger().Debug().Msgf("Loading GRPC Process: %s", grpcProcess)
+
+	o.logger().Debug().Msgf("GRPC Service for %s will be running at: '%s'", id, serverAddress)
 
-	log.Debug().Msgf("GRPC Service for %s will be running at: '%s'", id, serverAddress)
+	name, args := grpcProcess, []string{"--addr", serverAddress}
+	// the backend can read its own directory (e.g. the python sources), the assets and the model
+	readOnly := []string{filepath.Dir(grpcProcess), o.assetDir, ml.ModelFile(id)}
+	if o.sandbox != nil {
+		spec := *o.sandbox
+		if spec.Landlock {
+			spec.ReadOnly = append(append([]string{}, readOnly...), spec.ReadOnly...)
+		}
+		var err error
+		name, args, err = spec.Command(grpcProcess, args...)
+		if err != nil {
+			return err
+		}
+		// LocalAI starts the backend in the sandbox, from the filesystem view of the isolation
+		readOnly = append(readOnly, name)
+		o.logger().Debug().Msgf("GRPC Service for %s sandboxed with: %s %s", id, name, strings.Join(args, " "))
+	}
+	if o.isolation != nil {
+		var err error
+		name, args, err = o.isolation.command(grpcProcess, readOnly, name, args...)
+		if err != nil {
+			return err
+		}
+		o.logger().Debug().Msgf("GRPC Service for %s isolated with: %s %s", id, name, strings.Join(args, " "))
+	}
+	if o.numaPolicy != "" {
+		var err error
+		// the sandbox inherits the memory policy
+		name, args, err = numaCommand(o.numaPolicy, name, args...)
+		if err != nil {
+			return err
+		}
+		o.logger().Debug().Msgf("GRPC Service for %s started with the NUMA policy %s", id, o.numaPolicy)
+	}
+
+	if limits := o.processLimits; !limits.empty() {
+		var err error
+		// the limits are set before the backend (or the sandbox) starts, and inherited by all its threads
+		name, args, err = limitsCommand(limits, name, args...)
+		if err != nil {
+			return fmt.Errorf("could not apply resource limits to %s: %w", grpcProcess, err)
+		}
+		o.logger().Debug().Msgf("GRPC Service resource limits: %+v", limits)
+	}
 
 	grpcControlProcess := process.New(
 		process.WithTemporaryStateDir(),
-		process.WithName(grpcProcess),
-		process.WithArgs("--addr", serverAddress),
-		process.WithEnvironment(os.Environ()...),
+		process.WithName(name),
+		process.WithArgs(args...),
+		process.WithEnvironment(mergeEnv(os.Environ(), o.environment)...),
 	)
 
 	if ml.wd != nil {
@@ -85,18 +192,22 @@ func (ml *ModelLoader) startProcess(grpcProcess, id string, serverAddress string
 		ml.wd.AddAddressModelMap(serverAddress, id)
 	}
 
+	ml.processesMu.Lock()
 	ml.grpcProcesses[id] = grpcControlProcess
+	ml.processesMu.Unlock()
 
 	if err := grpcControlProcess.Run(); err != nil {
 		return err
 	}
 
-	log.Debug().Msgf("GRPC Service state dir: %s", grpcControlProcess.StateDir())
+	o.logger().Debug().Msgf("GRPC Service state dir: %s", grpcControlProcess.StateDir())
+	ml.watchProcess(id, grpcProcess, grpcControlProcess)
 	// clean up process
 	go func() {
 		c := make(chan os.Signal, 1)
 		signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 		<-c
+		ml.stopWatch(id)
 		grpcControlProcess.Stop()
 	}()
 
@@ -107,6 +218,7 @@ func (ml *ModelLoader) startProcess(grpcProcess, id string, serverAddress string
 		}
 		for line := range t.Lines {
 			log.Debug().Msgf("GRPC(%s): stderr %s", strings.Join([]string{id, serverAddress}, "-"), line.Text)
+			logstream.Default.Publish(logstream.Entry{Time: line.Time, Level: zerolog.InfoLevel.String(), Component: logstream.ComponentBackend, Model: id, Message: line.Text})
 		}
 	}()
 	go func() {
@@ -116,6 +228,7 @@ func (ml *ModelLoader) startProcess(grpcProcess, id string, serverAddress string
 		}
 		for line := range t.Lines {
 			log.Debug().Msgf("GRPC(%s): stdout %s", strings.Join([]string{id, serverAddress}, "-"), line.Text)
+			logstream.Default.Publish(logstream.Entry{Time: line.Time, Level: zerolog.InfoLevel.String(), Component: logstream.ComponentBackend, Model: id, Message: line.Text})
 		}
 	}()
 
@@ -0,0 +1,18 @@
+package model
+
+// ProcessLimits constrains the resources of a backend process started by the loader.
+// Zero values mean no limit.
+type ProcessLimits struct {
+	// Nice is the scheduling priority of the process (-20 to 19)
+	Nice int
+	// CPUs is the list of CPUs the process is allowed to run on
+	CPUs []int
+	// MaxMemory is the maximum size, in bytes, of the data memory of the process
+	MaxMemory uint64
+	// MaxOpenFiles is the maximum number of files the process can open
+	MaxOpenFiles uint64
+}
+
+func (l ProcessLimits) empty() bool {
+	return l.Nice == 0 && len(l.CPUs) == 0 && l.MaxMemory == 0 && l.MaxOpenFiles == 0
+}
@@ -0,0 +1,70 @@
+//go:build linux
+// +build linux
+
+package model
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// limitsCommand returns the command running name with the given arguments under the resource limits. The limits are
+// set by prlimit, taskset and nice before they exec the backend, so they apply to all its threads and are inherited
+// by the processes it starts (e.g. in the sandbox).
+func limitsCommand(l ProcessLimits, name string, args ...string) (string, []string, error) {
+	command := append([]string{name}, args...)
+
+	if l.Nice != 0 {
+		command = append([]string{"-n", strconv.Itoa(l.Nice), "--"}, command...)
+		var err error
+		if command, err = wrapCommand("nice", command); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if len(l.CPUs) > 0 {
+		cpus := make([]string, len(l.CPUs))
+		for i, c := range l.CPUs {
+			if c < 0 {
+				return "", nil, fmt.Errorf("invalid CPU %d", c)
+			}
+			cpus[i] = strconv.Itoa(c)
+		}
+		// taskset parses its options up to the command, and doesn't accept --
+		command = append([]string{"--cpu-list", strings.Join(cpus, ",")}, command...)
+		var err error
+		if command, err = wrapCommand("taskset", command); err != nil {
+			return "", nil, err
+		}
+	}
+
+	// RLIMIT_DATA is used rather than RLIMIT_AS, as the latter also accounts for the
+	// memory mapped model files and the address space reserved by GPU drivers
+	var rlimits []string
+	if l.MaxMemory != 0 {
+		rlimits = append(rlimits, fmt.Sprintf("--data=%d:%d", l.MaxMemory, l.MaxMemory))
+	}
+	if l.MaxOpenFiles != 0 {
+		rlimits = append(rlimits, fmt.Sprintf("--nofile=%d:%d", l.MaxOpenFiles, l.MaxOpenFiles))
+	}
+	if len(rlimits) > 0 {
+		command = append(append(rlimits, "--"), command...)
+		var err error
+		if command, err = wrapCommand("prlimit", command); err != nil {
+			return "", nil, err
+		}
+	}
+
+	return command[0], command[1:], nil
+}
+
+// wrapCommand prepends the tool, looked up in the PATH, to the command
+func wrapCommand(tool string, command []string) ([]string, error) {
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		return nil, fmt.Errorf("the resource limits require %s: %w", tool, err)
+	}
+	return append([]string{path}, command...), nil
+}
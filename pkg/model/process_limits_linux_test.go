@@ -0,0 +1,45 @@
+//go:build linux
+// +build linux
+
+package model
+
+import (
+	"os/exec"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("limitsCommand", func() {
+	BeforeEach(func() {
+		for _, tool := range []string{"nice", "taskset", "prlimit"} {
+			if _, err := exec.LookPath(tool); err != nil {
+				Skip(tool + " is not installed")
+			}
+		}
+	})
+
+	It("starts the command with the limits already applied", func() {
+		name, args, err := limitsCommand(ProcessLimits{Nice: 5, CPUs: []int{0}, MaxMemory: 1 << 40, MaxOpenFiles: 128},
+			"/bin/sh", "-c", `echo "$(ulimit -n) $(ulimit -d) $(grep Cpus_allowed_list /proc/self/status | cut -f2) $(cut -d' ' -f19 /proc/self/stat)"`)
+		Expect(err).ToNot(HaveOccurred())
+
+		out, err := exec.Command(name, args...).Output()
+		Expect(err).ToNot(HaveOccurred())
+		fields := strings.Fields(string(out))
+		Expect(fields).To(HaveLen(4))
+		Expect(fields[0]).To(Equal("128"))
+		// ulimit reports kilobytes
+		Expect(fields[1]).To(Equal("1073741824"))
+		Expect(fields[2]).To(Equal("0"))
+		Expect(fields[3]).To(Equal("5"))
+	})
+
+	It("returns the command unchanged without limits", func() {
+		name, args, err := limitsCommand(ProcessLimits{}, "/backend", "--addr", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(name).To(Equal("/backend"))
+		Expect(args).To(Equal([]string{"--addr", "127.0.0.1:0"}))
+	})
+})
@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package model
+
+import "github.com/rs/zerolog/log"
+
+func limitsCommand(l ProcessLimits, name string, args ...string) (string, []string, error) {
+	log.Warn().Msgf("Resource limits for backend processes are only supported on Linux, ignoring them")
+	return name, args, nil
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("mergeEnv", func() {
+	base := []string{"PATH=/usr/bin", "HOME=/root", "CUDA_VISIBLE_DEVICES=0,1"}
+
+	DescribeTable("merges the environment of the backend into the one of LocalAI",
+		func(overrides, expected []string) {
+			Expect(mergeEnv(base, overrides)).To(Equal(expected))
+		},
+		Entry("without overrides", nil, base),
+		Entry("adding the new variables", []string{"OMP_NUM_THREADS=4"},
+			[]string{"PATH=/usr/bin", "HOME=/root", "CUDA_VISIBLE_DEVICES=0,1", "OMP_NUM_THREADS=4"}),
+		Entry("replacing the variables of LocalAI", []string{"CUDA_VISIBLE_DEVICES=2"},
+			[]string{"PATH=/usr/bin", "HOME=/root", "CUDA_VISIBLE_DEVICES=2"}),
+		Entry("keeping the last value of the variables set twice", []string{"CUDA_VISIBLE_DEVICES=2", "OMP_NUM_THREADS=4", "CUDA_VISIBLE_DEVICES=3"},
+			[]string{"PATH=/usr/bin", "HOME=/root", "OMP_NUM_THREADS=4", "CUDA_VISIBLE_DEVICES=3"}),
+		Entry("setting the variables without value", []string{"HOME"},
+			[]string{"PATH=/usr/bin", "CUDA_VISIBLE_DEVICES=0,1", "HOME"}),
+	)
+
+	It("doesn't change the environment of LocalAI", func() {
+		env := append([]string{}, base...)
+		mergeEnv(env, []string{"HOME=/tmp"})
+		Expect(env).To(Equal(base))
+	})
+})
@@ -0,0 +1,95 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// retryPolicy describes how the loader retries operations against a gRPC backend
+// (health checks while the service starts up, and loading the model).
+// The defaults reproduce a fixed delay between attempts.
+type retryPolicy struct {
+	attempts   int
+	delay      time.Duration
+	maxDelay   time.Duration
+	multiplier float64
+	jitter     float64
+	deadline   time.Time
+}
+
+func (o *Options) retryPolicy() retryPolicy {
+	p := retryPolicy{
+		attempts:   o.grpcAttempts,
+		delay:      time.Duration(o.grpcAttemptsDelay) * time.Second,
+		maxDelay:   time.Duration(o.grpcMaxDelay) * time.Second,
+		multiplier: o.grpcBackoffMultiplier,
+		jitter:     o.grpcJitter,
+	}
+	if p.attempts <= 0 {
+		p.attempts = 1
+	}
+	if p.multiplier < 1 {
+		p.multiplier = 1
+	}
+	if o.grpcDeadline > 0 {
+		p.deadline = time.Now().Add(time.Duration(o.grpcDeadline) * time.Second)
+	}
+	return p
+}
+
+// backoff returns the time to wait after the given (0-indexed) attempt failed
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	d := time.Duration(float64(p.delay) * math.Pow(p.multiplier, float64(attempt)))
+	if p.maxDelay > 0 && d > p.maxDelay {
+		d = p.maxDelay
+	}
+	if p.jitter > 0 {
+		// spread the delay by +/- jitter
+		d += time.Duration(float64(d) * p.jitter * (2*rand.Float64() - 1))
+	}
+	if d < 0 {
+		d = 0
+	}
+	return d
+}
+
+// do calls fn until it succeeds, it returns a non-retryable error,
+// the attempts are exhausted or the deadline is reached.
+func (p retryPolicy) do(ctx context.Context, fn func() (retry bool, err error)) error {
+	var err error
+	for i := 0; i < p.attempts; i++ {
+		var retry bool
+		retry, err = fn()
+		if err == nil || !retry || i == p.attempts-1 {
+			return err
+		}
+
+		wait := p.backoff(i)
+		if !p.deadline.IsZero() && time.Now().Add(wait).After(p.deadline) {
+			return fmt.Errorf("retry deadline exceeded: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	return err
+}
+
+// isTransient returns true for errors that are worth retrying, such as
+// dial errors or a backend that is temporarily unavailable.
+func isTransient(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("retryPolicy", func() {
+	errTransient := errors.New("unavailable")
+
+	Context("backoff", func() {
+		It("grows the delay by the multiplier up to the maximum", func() {
+			p := retryPolicy{delay: time.Second, maxDelay: 5 * time.Second, multiplier: 2}
+			Expect(p.backoff(0)).To(Equal(time.Second))
+			Expect(p.backoff(1)).To(Equal(2 * time.Second))
+			Expect(p.backoff(2)).To(Equal(4 * time.Second))
+			Expect(p.backoff(3)).To(Equal(5 * time.Second))
+		})
+
+		It("spreads the delay within the jitter", func() {
+			p := retryPolicy{delay: time.Second, maxDelay: 5 * time.Second, multiplier: 2, jitter: 0.25}
+			for attempt, base := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second} {
+				low, high := base*3/4, base*5/4
+				distinct := map[time.Duration]bool{}
+				for i := 0; i < 200; i++ {
+					d := p.backoff(attempt)
+					Expect(d).To(BeNumerically(">=", low))
+					Expect(d).To(BeNumerically("<=", high))
+					distinct[d] = true
+				}
+				Expect(len(distinct)).To(BeNumerically(">", 1))
+			}
+		})
+
+		It("never returns a negative delay", func() {
+			p := retryPolicy{delay: time.Second, multiplier: 1, jitter: 2}
+			for i := 0; i < 200; i++ {
+				Expect(p.backoff(0)).To(BeNumerically(">=", 0))
+			}
+		})
+	})
+
+	Context("do", func() {
+		It("retries the transient errors until the attempts are exhausted", func() {
+			p := retryPolicy{attempts: 3, delay: time.Millisecond, multiplier: 1}
+			calls := 0
+			err := p.do(context.Background(), func() (bool, error) {
+				calls++
+				return true, errTransient
+			})
+			Expect(err).To(MatchError(errTransient))
+			Expect(calls).To(Equal(3))
+		})
+
+		It("stops at the first success or non-retryable error", func() {
+			p := retryPolicy{attempts: 5, delay: time.Millisecond, multiplier: 1}
+			calls := 0
+			Expect(p.do(context.Background(), func() (bool, error) {
+				calls++
+				if calls < 2 {
+					return true, errTransient
+				}
+				return false, nil
+			})).To(Succeed())
+			Expect(calls).To(Equal(2))
+
+			calls = 0
+			errFatal := errors.New("invalid model")
+			Expect(p.do(context.Background(), func() (bool, error) {
+				calls++
+				return false, errFatal
+			})).To(MatchError(errFatal))
+			Expect(calls).To(Equal(1))
+		})
+
+		It("gives up before waiting past the deadline", func() {
+			p := retryPolicy{attempts: 10, delay: time.Second, multiplier: 1, deadline: time.Now().Add(100 * time.Millisecond)}
+			calls := 0
+			start := time.Now()
+			err := p.do(context.Background(), func() (bool, error) {
+				calls++
+				return true, errTransient
+			})
+			Expect(err).To(MatchError(ContainSubstring("retry deadline exceeded")))
+			Expect(errors.Is(err, errTransient)).To(BeTrue())
+			Expect(calls).To(Equal(1))
+			Expect(time.Since(start)).To(BeNumerically("<", 100*time.Millisecond))
+		})
+
+		It("retries while the deadline allows it", func() {
+			p := retryPolicy{attempts: 10, delay: 20 * time.Millisecond, multiplier: 1, deadline: time.Now().Add(150 * time.Millisecond)}
+			calls := 0
+			err := p.do(context.Background(), func() (bool, error) {
+				calls++
+				return true, errTransient
+			})
+			Expect(err).To(MatchError(ContainSubstring("retry deadline exceeded")))
+			Expect(calls).To(BeNumerically(">", 1))
+			Expect(calls).To(BeNumerically("<", 10))
+		})
+
+		It("stops when the context is canceled", func() {
+			p := retryPolicy{attempts: 10, delay: time.Minute, multiplier: 1}
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			Expect(p.do(ctx, func() (bool, error) {
+				return true, errTransient
+			})).To(MatchError(context.Canceled))
+		})
+	})
+})
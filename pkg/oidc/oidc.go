@@ -0,0 +1,279 @@
+// Package oidc authenticates the requests with the JWT bearer tokens of an OIDC issuer, e.g. the SSO of a company,
+// and maps their claims to the scopes of the API keys.
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-skynet/LocalAI/pkg/apikeys"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// the keys of the issuer are fetched again at most this often, when a token is signed by an unknown key
+const jwksMinRefresh = time.Minute
+
+// the signing methods accepted, the asymmetric ones only
+var validMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+type Config struct {
+	Issuer string
+	// Audience the tokens must have, e.g. the client ID of LocalAI at the issuer. It is required, not to accept the
+	// tokens issued to the other clients of the issuer.
+	Audience string
+	// ScopesClaim is the claim with the scopes of the token, a space separated string or a list, "scope" by default
+	ScopesClaim string
+	// ScopeMapping maps the values of the scopes claim to the scopes of LocalAI, the other values grant no scope: a
+	// group of the issuer named like a scope, e.g. admin, isn't granted it unless mapped.
+	ScopeMapping map[string][]string
+	// TenantClaim is the claim with the tenant of the caller, for the quotas
+	TenantClaim string
+}
+
+type Verifier struct {
+	cfg     Config
+	client  *http.Client
+	jwksURI string
+
+	mu      sync.Mutex
+	keys    map[string]interface{}
+	fetched time.Time
+}
+
+// New discovers the keys of the issuer from its OpenID configuration
+func New(ctx context.Context, cfg Config) (*Verifier, error) {
+	if cfg.Audience == "" {
+		return nil, fmt.Errorf("the audience of the tokens of the OIDC issuer %s is required", cfg.Issuer)
+	}
+	if cfg.ScopesClaim == "" {
+		cfg.ScopesClaim = "scope"
+	}
+	v := &Verifier{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}}
+
+	discovery := struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}{}
+	if err := v.get(ctx, strings.TrimSuffix(cfg.Issuer, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("failed discovering the OIDC issuer %s: %w", cfg.Issuer, err)
+	}
+	if discovery.Issuer != cfg.Issuer {
+		return nil, fmt.Errorf("the OIDC issuer %s identifies as %s", cfg.Issuer, discovery.Issuer)
+	}
+	v.jwksURI = discovery.JWKSURI
+
+	if err := v.fetchKeys(ctx); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (v *Verifier) get(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s of %s", resp.Status, url)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// publicKey returns the RSA or EC public key of the JWK
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+}
+
+// fetchKeys replaces the keys with the signing keys of the issuer
+func (v *Verifier) fetchKeys(ctx context.Context) error {
+	set := struct {
+		Keys []jwk `json:"keys"`
+	}{}
+	if err := v.get(ctx, v.jwksURI, &set); err != nil {
+		return fmt.Errorf("failed fetching the keys of the OIDC issuer: %w", err)
+	}
+	keys := map[string]interface{}{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			// the keys of the other types are not used to sign the tokens
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.keys = keys
+	v.fetched = time.Now()
+	return nil
+}
+
+// key returns the key of the kid, fetching the keys again when it is unknown, e.g. after a rotation
+func (v *Verifier) key(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	v.mu.Lock()
+	k, ok := v.keys[kid]
+	refresh := !ok && time.Since(v.fetched) > jwksMinRefresh
+	v.mu.Unlock()
+	if ok {
+		return k, nil
+	}
+	if refresh {
+		if err := v.fetchKeys(context.Background()); err != nil {
+			return nil, err
+		}
+		v.mu.Lock()
+		k, ok = v.keys[kid]
+		v.mu.Unlock()
+		if ok {
+			return k, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// ParseScopeMapping parses the mappings of the values of the scopes claim to the scopes of LocalAI, formatted as
+// <value>=<scope>[,<scope>...]
+func ParseScopeMapping(mappings []string) (map[string][]string, error) {
+	res := map[string][]string{}
+	for _, m := range mappings {
+		value, scopes, ok := strings.Cut(m, "=")
+		if !ok || value == "" || scopes == "" {
+			return nil, fmt.Errorf("invalid scope mapping %q, the format is <claim value>=<scope>[,<scope>...]", m)
+		}
+		for _, sc := range strings.Split(scopes, ",") {
+			known := false
+			for _, k := range apikeys.Scopes {
+				known = known || sc == k
+			}
+			if !known {
+				return nil, fmt.Errorf("unknown scope %q in the scope mapping %q, expected one of %v", sc, m, apikeys.Scopes)
+			}
+			res[value] = append(res[value], sc)
+		}
+	}
+	return res, nil
+}
+
+// IsJWT returns true if the bearer token looks like a JWT, rather than an API key
+func IsJWT(token string) bool {
+	return strings.Count(token, ".") == 2 && strings.HasPrefix(token, "ey")
+}
+
+// Verify checks the signature, the issuer, the audience and the expiration of the token, and returns the caller as an
+// API key with the scopes of its claims. Its fingerprint identifies the subject of the token.
+func (v *Verifier) Verify(token string) (apikeys.Key, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(validMethods),
+		jwt.WithIssuer(v.cfg.Issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+		jwt.WithAudience(v.cfg.Audience),
+	}
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, v.key, opts...); err != nil {
+		return apikeys.Key{}, err
+	}
+
+	sub, _ := claims.GetSubject()
+	if sub == "" {
+		return apikeys.Key{}, fmt.Errorf("token without subject")
+	}
+	k := apikeys.Key{Name: sub, Fingerprint: "oidc:" + sub, Scopes: v.scopes(claims[v.cfg.ScopesClaim])}
+	if v.cfg.TenantClaim != "" {
+		k.Tenant, _ = claims[v.cfg.TenantClaim].(string)
+	}
+	return k, nil
+}
+
+// scopes maps the values of the scopes claim to the scopes of LocalAI with the scope mapping
+func (v *Verifier) scopes(claim interface{}) []string {
+	values := []string{}
+	switch c := claim.(type) {
+	case string:
+		values = strings.Fields(c)
+	case []interface{}:
+		for _, s := range c {
+			if s, ok := s.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	scopes := []string{}
+	for _, val := range values {
+		scopes = append(scopes, v.cfg.ScopeMapping[val]...)
+	}
+	return scopes
+}
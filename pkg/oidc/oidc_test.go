@@ -0,0 +1,148 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/go-skynet/LocalAI/pkg/apikeys"
+	"github.com/go-skynet/LocalAI/pkg/oidc"
+	"github.com/golang-jwt/jwt/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// issuer serves the OpenID configuration and the keys of an OIDC issuer signing with key
+func issuer(key *rsa.PrivateKey, kid string) *httptest.Server {
+	mux := http.NewServeMux()
+	var srv *httptest.Server
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": srv.URL, "jwks_uri": srv.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kid": kid,
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	srv = httptest.NewServer(mux)
+	return srv
+}
+
+func sign(key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	t.Header["kid"] = kid
+	s, err := t.SignedString(key)
+	Expect(err).ToNot(HaveOccurred())
+	return s
+}
+
+var _ = Describe("OIDC", func() {
+	var key *rsa.PrivateKey
+	var srv *httptest.Server
+	var verifier *oidc.Verifier
+
+	claims := func(extra jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{"iss": srv.URL, "aud": "localai", "sub": "alice", "exp": time.Now().Add(time.Hour).Unix()}
+		for k, v := range extra {
+			c[k] = v
+		}
+		return c
+	}
+
+	BeforeEach(func() {
+		var err error
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).ToNot(HaveOccurred())
+		srv = issuer(key, "k1")
+		verifier, err = oidc.New(context.Background(), oidc.Config{
+			Issuer:       srv.URL,
+			Audience:     "localai",
+			ScopeMapping: map[string][]string{"ml-users": {apikeys.ScopeChat, apikeys.ScopeEmbeddings}, "ml-admins": {apikeys.ScopeAdmin}},
+			TenantClaim:  "team",
+		})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		srv.Close()
+	})
+
+	It("maps the claims of the valid tokens", func() {
+		token := sign(key, "k1", claims(jwt.MapClaims{"scope": "openid ml-users", "team": "team-a"}))
+		Expect(oidc.IsJWT(token)).To(BeTrue())
+		Expect(oidc.IsJWT("sk-0123")).To(BeFalse())
+
+		k, err := verifier.Verify(token)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(k.Fingerprint).To(Equal("oidc:alice"))
+		Expect(k.Tenant).To(Equal("team-a"))
+		Expect(k.Scopes).To(Equal([]string{apikeys.ScopeChat, apikeys.ScopeEmbeddings}))
+
+		k, err = verifier.Verify(sign(key, "k1", claims(jwt.MapClaims{"scope": []interface{}{"ml-admins"}})))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(k.Allows(apikeys.ScopeImages)).To(BeTrue())
+	})
+
+	It("only grants the mapped scopes", func() {
+		k, err := verifier.Verify(sign(key, "k1", claims(jwt.MapClaims{"scope": []interface{}{"admin", "chat"}})))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(k.Scopes).To(BeEmpty())
+		Expect(k.Allows(apikeys.ScopeAdmin)).To(BeFalse())
+		Expect(k.Allows(apikeys.ScopeChat)).To(BeFalse())
+	})
+
+	It("requires the audience", func() {
+		_, err := oidc.New(context.Background(), oidc.Config{Issuer: srv.URL})
+		Expect(err).To(HaveOccurred())
+
+		noAud := claims(nil)
+		delete(noAud, "aud")
+		_, err = verifier.Verify(sign(key, "k1", noAud))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects the invalid tokens", func() {
+		_, err := verifier.Verify(sign(key, "k1", claims(jwt.MapClaims{"aud": "other"})))
+		Expect(err).To(HaveOccurred())
+		_, err = verifier.Verify(sign(key, "k1", claims(jwt.MapClaims{"iss": "https://other"})))
+		Expect(err).To(HaveOccurred())
+		_, err = verifier.Verify(sign(key, "k1", claims(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()})))
+		Expect(err).To(HaveOccurred())
+		_, err = verifier.Verify(sign(key, "unknown", claims(nil)))
+		Expect(err).To(HaveOccurred())
+
+		other, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = verifier.Verify(sign(other, "k1", claims(nil)))
+		Expect(err).To(HaveOccurred())
+
+		noExp := claims(nil)
+		delete(noExp, "exp")
+		_, err = verifier.Verify(sign(key, "k1", noExp))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("parses the scope mappings", func() {
+		m, err := oidc.ParseScopeMapping([]string{"ml-users=chat,embeddings", "ml-admins=admin"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(m).To(Equal(map[string][]string{
+			"ml-users":  {apikeys.ScopeChat, apikeys.ScopeEmbeddings},
+			"ml-admins": {apikeys.ScopeAdmin},
+		}))
+
+		_, err = oidc.ParseScopeMapping([]string{"ml-users"})
+		Expect(err).To(HaveOccurred())
+		_, err = oidc.ParseScopeMapping([]string{"ml-users=unknown"})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,209 @@
+// Package openapi generates the OpenAPI 3.1 document of the API, from the operations declared by the endpoints
+// and the routes registered in the server. The schemas of the requests and responses are generated from their Go types.
+package openapi
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Operation documents a route of the API
+type Operation struct {
+	Method string
+	// Path of the route, with the parameters in the fiber syntax (e.g. /v1/files/:file_id)
+	Path    string
+	Summary string
+	Tag     string
+	// Request is a value of the type of the JSON body, nil without body
+	Request interface{}
+	// Form is true when the body is sent as multipart/form-data, with the fields of Request
+	Form bool
+	// Response is a value of the type of the JSON response, nil when it is not described
+	Response interface{}
+	// ResponseType is the content type of the responses which are not JSON (e.g. audio/wav)
+	ResponseType string
+	// Stream is true when the response can be streamed as server-sent events
+	Stream bool
+	// Query are the names of the query parameters
+	Query []string
+	// Public is true when the route does not require an API key
+	Public bool
+	// Extension is true for the endpoints which are not part of the OpenAI API
+	Extension bool
+}
+
+// Route is a route registered in the server
+type Route struct {
+	Method string
+	Path   string
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Document struct {
+	OpenAPI    string                                 `json:"openapi"`
+	Info       Info                                   `json:"info"`
+	Paths      map[string]map[string]*operationObject `json:"paths"`
+	Components components                             `json:"components"`
+	Security   []map[string][]string                  `json:"security,omitempty"`
+}
+
+type components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]securityScheme `json:"securitySchemes"`
+}
+
+type securityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+}
+
+type operationObject struct {
+	OperationID string                 `json:"operationId"`
+	Summary     string                 `json:"summary,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Parameters  []parameter            `json:"parameters,omitempty"`
+	RequestBody *body                  `json:"requestBody,omitempty"`
+	Responses   map[string]*body       `json:"responses"`
+	Security    *[]map[string][]string `json:"security,omitempty"`
+	Extension   bool                   `json:"x-localai-extension,omitempty"`
+}
+
+type parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type body struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]mediaType `json:"content,omitempty"`
+}
+
+type mediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Spec holds the operations documenting the API
+type Spec struct {
+	info       Info
+	operations map[string]Operation
+	errors     interface{}
+}
+
+func New(info Info) *Spec {
+	return &Spec{info: info, operations: map[string]Operation{}}
+}
+
+// SetErrorResponse sets a value of the type of the error responses of all the operations
+func (s *Spec) SetErrorResponse(v interface{}) {
+	s.errors = v
+}
+
+// Add documents the operations, replacing the ones of the same routes
+func (s *Spec) Add(ops ...Operation) {
+	for _, op := range ops {
+		s.operations[op.Method+" "+op.Path] = op
+	}
+}
+
+var pathParameter = regexp.MustCompile(`:([A-Za-z0-9_]+)\??`)
+
+// Document returns the document of the registered routes. The routes which were not documented
+// are described without their schemas, and the documented operations which are not registered are left out.
+func (s *Spec) Document(routes []Route) *Document {
+	d := &Document{
+		OpenAPI: "3.1.0",
+		Info:    s.info,
+		Paths:   map[string]map[string]*operationObject{},
+		Components: components{
+			SecuritySchemes: map[string]securityScheme{"bearerAuth": {Type: "http", Scheme: "bearer"}},
+		},
+		Security: []map[string][]string{{"bearerAuth": {}}},
+	}
+	sch := newSchemas()
+
+	for _, r := range routes {
+		// the HEAD routes are added along with the GET ones, and the wildcards serve static files
+		if r.Method == http.MethodHead || strings.Contains(r.Path, "*") {
+			continue
+		}
+		op, ok := s.operations[r.Method+" "+r.Path]
+		if !ok {
+			op = Operation{Method: r.Method, Path: r.Path}
+		}
+		path := pathParameter.ReplaceAllString(r.Path, "{$1}")
+		if d.Paths[path] == nil {
+			d.Paths[path] = map[string]*operationObject{}
+		}
+		d.Paths[path][strings.ToLower(r.Method)] = s.operation(op, sch)
+	}
+
+	d.Components.Schemas = sch.components
+	return d
+}
+
+func (s *Spec) operation(op Operation, sch *schemas) *operationObject {
+	o := &operationObject{
+		OperationID: operationID(op.Method, op.Path),
+		Summary:     op.Summary,
+		Responses:   map[string]*body{},
+		Extension:   op.Extension,
+	}
+	if op.Tag != "" {
+		o.Tags = []string{op.Tag}
+	}
+	if op.Public {
+		o.Security = &[]map[string][]string{}
+	}
+
+	for _, m := range pathParameter.FindAllStringSubmatch(op.Path, -1) {
+		o.Parameters = append(o.Parameters, parameter{Name: m[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+	for _, q := range op.Query {
+		o.Parameters = append(o.Parameters, parameter{Name: q, In: "query", Schema: &Schema{Type: "string"}})
+	}
+
+	if op.Request != nil {
+		contentType := "application/json"
+		if op.Form {
+			contentType = "multipart/form-data"
+		}
+		o.RequestBody = &body{Required: true, Content: map[string]mediaType{contentType: {Schema: sch.of(op.Request)}}}
+	}
+
+	ok := &body{Description: "OK"}
+	switch {
+	case op.Response != nil:
+		ok.Content = map[string]mediaType{"application/json": {Schema: sch.of(op.Response)}}
+	case op.ResponseType != "":
+		ok.Content = map[string]mediaType{op.ResponseType: {Schema: &Schema{Type: "string", Format: "binary"}}}
+	}
+	if op.Stream {
+		if ok.Content == nil {
+			ok.Content = map[string]mediaType{}
+		}
+		ok.Content["text/event-stream"] = mediaType{Schema: &Schema{Type: "string"}}
+	}
+	o.Responses["200"] = ok
+	o.Responses["default"] = &body{Description: "Error"}
+	if s.errors != nil {
+		o.Responses["default"].Content = map[string]mediaType{"application/json": {Schema: sch.of(s.errors)}}
+	}
+	return o
+}
+
+var nonAlphanumeric = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// operationID names the operation after its method and path, e.g. post_v1_chat_completions
+func operationID(method, path string) string {
+	id := strings.Trim(nonAlphanumeric.ReplaceAllString(pathParameter.ReplaceAllString(path, "by_$1"), "_"), "_")
+	return strings.ToLower(method) + "_" + id
+}
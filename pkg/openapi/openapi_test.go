@@ -0,0 +1,132 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/go-skynet/LocalAI/pkg/openapi"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type Options struct {
+	Temperature float64 `json:"temperature"`
+}
+
+type Message struct {
+	Role    string     `json:"role"`
+	Replies []*Message `json:"replies,omitempty"`
+}
+
+type Request struct {
+	Options
+	Model    string            `json:"model"`
+	Messages []Message         `json:"messages"`
+	Metadata map[string]string `json:"metadata"`
+	Created  time.Time         `json:"created"`
+	Data     []byte            `json:"data"`
+	Input    interface{}       `json:"input"`
+	Internal string            `json:"-"`
+	hidden   string
+}
+
+type Config struct {
+	Options `yaml:"parameters"`
+	Name    string `yaml:"name"`
+}
+
+type page[T any] struct {
+	Data []T `json:"data"`
+}
+
+type Upload struct {
+	File Binary `json:"file"`
+}
+
+var _ = Describe("OpenAPI documents", func() {
+	// document returns the document of the routes, as decoded from JSON
+	document := func(spec *Spec, routes ...Route) map[string]interface{} {
+		dat, err := json.Marshal(spec.Document(routes))
+		Expect(err).ToNot(HaveOccurred())
+		res := map[string]interface{}{}
+		Expect(json.Unmarshal(dat, &res)).To(Succeed())
+		return res
+	}
+
+	It("generates the schemas of the types", func() {
+		spec := New(Info{Title: "test", Version: "v1"})
+		spec.Add(
+			Operation{Method: "POST", Path: "/v1/chat", Request: Request{}, Response: Config{}},
+			Operation{Method: "GET", Path: "/v1/messages", Response: page[Message]{}},
+		)
+		d := document(spec, Route{Method: "POST", Path: "/v1/chat"}, Route{Method: "GET", Path: "/v1/messages"})
+
+		schemas := d["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+		request := schemas["Request"].(map[string]interface{})["properties"].(map[string]interface{})
+		Expect(request).To(HaveKey("temperature"))
+		Expect(request).To(HaveKey("model"))
+		Expect(request).ToNot(HaveKey("Internal"))
+		Expect(request).ToNot(HaveKey("hidden"))
+		Expect(request["messages"]).To(Equal(map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/components/schemas/Message"}}))
+		Expect(request["metadata"]).To(Equal(map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}}))
+		Expect(request["created"]).To(Equal(map[string]interface{}{"type": "string", "format": "date-time"}))
+		Expect(request["data"]).To(Equal(map[string]interface{}{"type": "string", "format": "byte"}))
+		Expect(request["input"]).To(Equal(map[string]interface{}{}))
+
+		// recursive types are referenced
+		message := schemas["Message"].(map[string]interface{})["properties"].(map[string]interface{})
+		Expect(message["replies"]).To(Equal(map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/components/schemas/Message"}}))
+
+		// the generic types are named after their arguments
+		Expect(schemas).To(HaveKey("MessagePage"))
+
+		// the YAML tags are used without JSON tags
+		config := schemas["Config"].(map[string]interface{})["properties"].(map[string]interface{})
+		Expect(config).To(HaveKey("parameters"))
+		Expect(config).To(HaveKey("name"))
+	})
+
+	It("documents the registered routes", func() {
+		spec := New(Info{Title: "test", Version: "v1"})
+		spec.Add(
+			Operation{Method: "POST", Path: "/v1/files", Request: Upload{}, Form: true, Tag: "Files"},
+			Operation{Method: "GET", Path: "/v1/files/:file_id", Response: Upload{}, Query: []string{"purpose"}},
+			Operation{Method: "GET", Path: "/healthz", Public: true, Extension: true},
+			Operation{Method: "GET", Path: "/v1/batches"},
+		)
+		d := document(spec,
+			Route{Method: "POST", Path: "/v1/files"},
+			Route{Method: "GET", Path: "/v1/files/:file_id"},
+			Route{Method: "HEAD", Path: "/v1/files/:file_id"},
+			Route{Method: "GET", Path: "/healthz"},
+			Route{Method: "GET", Path: "/generated-images*"},
+			Route{Method: "POST", Path: "/undocumented"},
+		)
+		Expect(d["openapi"]).To(Equal("3.1.0"))
+
+		paths := d["paths"].(map[string]interface{})
+		Expect(paths).To(HaveLen(4))
+		Expect(paths).ToNot(HaveKey("/v1/batches"))
+
+		upload := paths["/v1/files"].(map[string]interface{})["post"].(map[string]interface{})
+		Expect(upload["operationId"]).To(Equal("post_v1_files"))
+		Expect(upload["tags"]).To(Equal([]interface{}{"Files"}))
+		Expect(upload["requestBody"]).To(HaveKeyWithValue("content", HaveKey("multipart/form-data")))
+
+		get := paths["/v1/files/{file_id}"].(map[string]interface{})
+		Expect(get).ToNot(HaveKey("head"))
+		Expect(get["get"].(map[string]interface{})["operationId"]).To(Equal("get_v1_files_by_file_id"))
+		Expect(get["get"].(map[string]interface{})["parameters"]).To(Equal([]interface{}{
+			map[string]interface{}{"name": "file_id", "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"}},
+			map[string]interface{}{"name": "purpose", "in": "query", "schema": map[string]interface{}{"type": "string"}},
+		}))
+
+		schemas := d["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+		Expect(schemas["Upload"]).To(HaveKeyWithValue("properties", HaveKeyWithValue("file", map[string]interface{}{"type": "string", "format": "binary"})))
+
+		healthz := paths["/healthz"].(map[string]interface{})["get"].(map[string]interface{})
+		Expect(healthz).To(HaveKeyWithValue("security", BeEmpty()))
+		Expect(healthz["x-localai-extension"]).To(BeTrue())
+		Expect(paths["/undocumented"]).To(HaveKey("post"))
+	})
+})
@@ -0,0 +1,161 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema, as used by OpenAPI 3.1
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	rawJSONType   = reflect.TypeOf(json.RawMessage{})
+	binaryType    = reflect.TypeOf(Binary{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// Binary is the type of the files sent in the multipart/form-data requests
+type Binary []byte
+
+var componentName = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// schemas generates the schemas of the Go types, from their JSON encoding.
+// The named structs are added to the components of the document, and referenced.
+type schemas struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{components: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+func (s *schemas) of(v interface{}) *Schema {
+	return s.schema(reflect.TypeOf(v))
+}
+
+func (s *schemas) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawJSONType:
+		return &Schema{}
+	case t == binaryType:
+		return &Schema{Type: "string", Format: "binary"}
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType):
+		// encoded in a custom way
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return s.ref(t)
+	}
+	// interfaces accept any value
+	return &Schema{}
+}
+
+// ref adds the struct to the components, named after the type (and its package when the name is taken)
+func (s *schemas) ref(t reflect.Type) *Schema {
+	name, ok := s.names[t]
+	if !ok {
+		name = componentName.ReplaceAllString(typeName(t), "_")
+		if _, taken := s.components[name]; taken {
+			pkg := t.PkgPath()
+			pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+			name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+		}
+		s.names[t] = name
+		// reserve the name before generating the properties, as they might reference the type
+		s.components[name] = &Schema{}
+		*s.components[name] = *s.object(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// typeName returns the name of the type, with the arguments of the generic types
+// prepended to their name (e.g. AssistantObjectList for objectList[schema.Assistant])
+func typeName(t reflect.Type) string {
+	name, args, generic := strings.Cut(t.Name(), "[")
+	if !generic {
+		return name
+	}
+	prefix := ""
+	for _, arg := range strings.Split(strings.TrimSuffix(args, "]"), ",") {
+		prefix += arg[strings.LastIndexAny(arg, "./")+1:]
+	}
+	return prefix + strings.ToUpper(name[:1]) + name[1:]
+}
+
+func (s *schemas) object(t reflect.Type) *Schema {
+	res := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.fields(t, res)
+	return res
+}
+
+// fields adds the fields of the struct to the properties, with the fields of the embedded structs
+func (s *schemas) fields(t reflect.Type, res *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		// the types read as YAML (e.g. the model configurations) only have YAML tags
+		tag, ok := f.Tag.Lookup("json")
+		if !ok {
+			tag = f.Tag.Get("yaml")
+		}
+		if tag == "-" {
+			continue
+		}
+		name, flags, _ := strings.Cut(tag, ",")
+
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ((f.Anonymous && name == "") || strings.Contains(flags, "inline")) && ft.Kind() == reflect.Struct {
+			s.fields(ft, res)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		res.Properties[name] = s.schema(f.Type)
+	}
+}
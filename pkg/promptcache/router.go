@@ -0,0 +1,145 @@
+// Package promptcache routes the prompts of a model to the slots of its backend, so the prompts sharing a prefix
+// (e.g. a long system prompt) are processed by the slot which already has the prefix in its KV cache.
+package promptcache
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+// DefaultBlockSize is the size in bytes of the blocks of the prompts which are hashed
+const DefaultBlockSize = 256
+
+// Stats are the statistics of the prompt cache of a model
+type Stats struct {
+	Requests int64 `json:"requests"`
+	// Hits are the requests routed to a slot which processed a prompt with the same prefix
+	Hits int64 `json:"hits"`
+	// MatchedBytes is the length of the prefixes found in the slots
+	MatchedBytes int64 `json:"matched_bytes"`
+	// CachedTokens are the tokens of the prompts reused from the KV cache, as reported by the backend
+	CachedTokens int64 `json:"cached_tokens"`
+	// EvaluatedTokens are the tokens of the prompts the backend had to evaluate
+	EvaluatedTokens int64 `json:"evaluated_tokens"`
+	// HitRate is the share of the requests which were hits
+	HitRate float64 `json:"hit_rate"`
+}
+
+type slot struct {
+	// hashes of the blocks of the last prompt, each one covering all the blocks before it
+	blocks   []uint64
+	busy     int
+	lastUsed int64
+}
+
+// Router assigns the prompts to the slots of a backend
+type Router struct {
+	mu        sync.Mutex
+	blockSize int
+	slots     []slot
+	clock     int64
+	// incremented when the slots are resized
+	generation int
+	stats      Stats
+}
+
+// New returns a router for a backend with the given number of slots
+func New(slots, blockSize int) *Router {
+	if slots < 1 {
+		slots = 1
+	}
+	if blockSize < 1 {
+		blockSize = DefaultBlockSize
+	}
+	return &Router{blockSize: blockSize, slots: make([]slot, slots)}
+}
+
+// blocks hashes the complete blocks of the prompt, the hash of each block covers the whole prefix up to the block
+func (r *Router) blocks(prompt string) []uint64 {
+	res := make([]uint64, 0, len(prompt)/r.blockSize)
+	h := fnv.New64a()
+	for end := r.blockSize; end <= len(prompt); end += r.blockSize {
+		h.Write([]byte(prompt[end-r.blockSize : end]))
+		res = append(res, h.Sum64())
+	}
+	return res
+}
+
+// Route returns the slot for the prompt: the free slot which shares the longest prefix with it, or the least recently
+// used free slot. The slot is held until release is called, and the slot is busy when all of them are.
+func (r *Router) Route(prompt string) (id int, release func()) {
+	blocks := r.blocks(prompt)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id, best := -1, 0
+	for i, s := range r.slots {
+		n := 0
+		for n < len(blocks) && n < len(s.blocks) && blocks[n] == s.blocks[n] {
+			n++
+		}
+		switch {
+		case id == -1,
+			// a free slot is preferred, even without the prefix
+			s.busy < r.slots[id].busy,
+			s.busy == r.slots[id].busy && n > best,
+			s.busy == r.slots[id].busy && n == best && s.lastUsed < r.slots[id].lastUsed:
+			id, best = i, n
+		}
+	}
+
+	r.clock++
+	s := &r.slots[id]
+	s.blocks = blocks
+	s.busy++
+	s.lastUsed = r.clock
+
+	r.stats.Requests++
+	if best > 0 {
+		r.stats.Hits++
+		r.stats.MatchedBytes += int64(best * r.blockSize)
+	}
+
+	released, generation := false, r.generation
+	return id, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		// the slot is gone when the slots were resized since
+		if !released && generation == r.generation {
+			released = true
+			r.slots[id].busy--
+		}
+	}
+}
+
+// Resize changes the number of slots, once the backend was reconfigured with an empty KV cache, so the prefixes of
+// the slots are forgotten
+func (r *Router) Resize(slots int) {
+	if slots < 1 {
+		slots = 1
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.slots = make([]slot, slots)
+	r.generation++
+}
+
+// Observe records the tokens of a prompt which the backend reused from its KV cache, and the ones it evaluated
+func (r *Router) Observe(cached, evaluated int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.CachedTokens += int64(cached)
+	r.stats.EvaluatedTokens += int64(evaluated)
+}
+
+// Stats returns the statistics of the prompts routed so far
+func (r *Router) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	st := r.stats
+	if st.Requests > 0 {
+		st.HitRate = float64(st.Hits) / float64(st.Requests)
+	}
+	return st
+}
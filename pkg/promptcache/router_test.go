@@ -0,0 +1,87 @@
+package promptcache_test
+
+import (
+	"strings"
+
+	. "github.com/go-skynet/LocalAI/pkg/promptcache"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Router", func() {
+	system := strings.Repeat("You answer with the documents below. ", 20)
+
+	It("routes the prompts sharing a prefix to the same slot", func() {
+		r := New(4, 64)
+
+		a, release := r.Route(system + "What is LocalAI?")
+		release()
+		b, release := r.Route("Something else entirely, " + strings.Repeat("x", 200))
+		release()
+		Expect(b).ToNot(Equal(a))
+
+		c, release := r.Route(system + "How do I install it?")
+		release()
+		Expect(c).To(Equal(a))
+
+		st := r.Stats()
+		Expect(st.Requests).To(Equal(int64(3)))
+		Expect(st.Hits).To(Equal(int64(1)))
+		Expect(st.MatchedBytes).To(BeNumerically(">=", len(system)-64))
+		Expect(st.HitRate).To(BeNumerically("~", 1.0/3, 0.01))
+	})
+
+	It("spreads the prompts over the free slots", func() {
+		r := New(2, 64)
+
+		a, releaseA := r.Route(system + "first")
+		// the slot with the prefix is busy
+		b, releaseB := r.Route(system + "second")
+		Expect(b).ToNot(Equal(a))
+		releaseA()
+		releaseA()
+		releaseB()
+
+		// both slots have the prefix now, the least recently used one is picked
+		c, release := r.Route(system + "third")
+		release()
+		Expect(c).To(Equal(a))
+	})
+
+	It("uses the least recently used slot without a shared prefix", func() {
+		r := New(3, 64)
+		used := map[int]bool{}
+		for _, p := range []string{"a", "b", "c"} {
+			id, release := r.Route(strings.Repeat(p, 100))
+			release()
+			used[id] = true
+		}
+		Expect(used).To(HaveLen(3))
+		Expect(r.Stats().Hits).To(BeZero())
+	})
+
+	It("forgets the prefixes when the slots are resized", func() {
+		r := New(4, 64)
+		_, release := r.Route(system + "first")
+		r.Resize(2)
+		// releasing a slot which is gone is a no-op
+		release()
+
+		used := map[int]bool{}
+		for i := 0; i < 3; i++ {
+			id, release := r.Route(system + "again")
+			release()
+			used[id] = true
+			Expect(id).To(BeNumerically("<", 2))
+		}
+		Expect(r.Stats().Hits).To(Equal(int64(2)))
+	})
+
+	It("records the tokens reused by the backend", func() {
+		r := New(1, 0)
+		r.Observe(120, 8)
+		r.Observe(0, 50)
+		Expect(r.Stats().CachedTokens).To(Equal(int64(120)))
+		Expect(r.Stats().EvaluatedTokens).To(Equal(int64(58)))
+	})
+})
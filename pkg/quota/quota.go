@@ -0,0 +1,161 @@
+// Package quota enforces the daily or monthly token quotas of the API keys and of their tenants, from the usage
+// accounted. Once a quota is exhausted, the requests are either rejected or served by a smaller model until the
+// period resets.
+package quota
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-skynet/LocalAI/pkg/usage"
+	"gopkg.in/yaml.v3"
+)
+
+// how long the tokens used are cached, not to read the usage on every request
+const usageCacheTTL = 10 * time.Second
+
+type Period string
+
+const (
+	Daily   Period = "daily"
+	Monthly Period = "monthly"
+)
+
+// Action is what happens to the requests once the quota is exhausted
+type Action string
+
+const (
+	Reject  Action = "reject"
+	Degrade Action = "degrade"
+)
+
+// Quota limits the tokens, prompt and completion, used by an API key or the keys of a tenant over a period
+type Quota struct {
+	// Key is the fingerprint of an API key, as in the usage and the audit log
+	Key string `yaml:"key" json:"key,omitempty"`
+	// Tenant is the tenant of the API keys with scopes, or of the OIDC tokens
+	Tenant string `yaml:"tenant" json:"tenant,omitempty"`
+	Period Period `yaml:"period" json:"period"`
+	Tokens int64  `yaml:"tokens" json:"tokens"`
+	// OnExhausted is Reject by default
+	OnExhausted Action `yaml:"on_exhausted" json:"on_exhausted,omitempty"`
+	// FallbackModel serves the requests once the quota is exhausted, with Degrade
+	FallbackModel string `yaml:"fallback_model" json:"fallback_model,omitempty"`
+}
+
+func (q Quota) validate() error {
+	switch {
+	case (q.Key == "") == (q.Tenant == ""):
+		return fmt.Errorf("either key or tenant has to be set")
+	case q.Period != Daily && q.Period != Monthly:
+		return fmt.Errorf("invalid period %q, expected %s or %s", q.Period, Daily, Monthly)
+	case q.Tokens <= 0:
+		return fmt.Errorf("no tokens")
+	case q.OnExhausted != "" && q.OnExhausted != Reject && q.OnExhausted != Degrade:
+		return fmt.Errorf("invalid on_exhausted %q, expected %s or %s", q.OnExhausted, Reject, Degrade)
+	case q.OnExhausted == Degrade && q.FallbackModel == "":
+		return fmt.Errorf("no fallback_model to degrade to")
+	}
+	return nil
+}
+
+// Start returns the start of the period of the quota including t, the periods starting at midnight UTC
+func (q Quota) Start(t time.Time) time.Time {
+	t = t.UTC()
+	if q.Period == Monthly {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Reset returns when the period of the quota including t ends
+func (q Quota) Reset(t time.Time) time.Time {
+	start := q.Start(t)
+	if q.Period == Monthly {
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// Load reads the quotas of a YAML file
+func Load(file string) ([]Quota, error) {
+	dat, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read quota file: %w", err)
+	}
+	quotas := []Quota{}
+	if err := yaml.Unmarshal(dat, &quotas); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal quota file: %w", err)
+	}
+	for i, q := range quotas {
+		if err := q.validate(); err != nil {
+			return nil, fmt.Errorf("invalid quota %d of %s: %w", i, file, err)
+		}
+	}
+	return quotas, nil
+}
+
+type cachedUsage struct {
+	tokens int64
+	start  time.Time
+	at     time.Time
+}
+
+// Enforcer checks the quotas against the usage accounted
+type Enforcer struct {
+	quotas []Quota
+	usage  *usage.Store
+
+	mu    sync.Mutex
+	cache map[int]cachedUsage
+}
+
+// NewEnforcer returns an enforcer of the quotas. The quotas of the tenants count the usage accounted to the tenants.
+func NewEnforcer(quotas []Quota, u *usage.Store) *Enforcer {
+	return &Enforcer{quotas: quotas, usage: u, cache: map[int]cachedUsage{}}
+}
+
+// Exhausted returns the first quota of the API key, or of its tenant, whose tokens are used up
+func (e *Enforcer) Exhausted(key, tenant string) (Quota, bool, error) {
+	now := time.Now()
+	for i, q := range e.quotas {
+		if (q.Key == "" || q.Key != key) && (q.Tenant == "" || q.Tenant != tenant) {
+			continue
+		}
+		used, err := e.used(i, q, now)
+		if err != nil {
+			return Quota{}, false, err
+		}
+		if used >= q.Tokens {
+			return q, true, nil
+		}
+	}
+	return Quota{}, false, nil
+}
+
+// used returns the tokens used in the current period of the i-th quota
+func (e *Enforcer) used(i int, q Quota, now time.Time) (int64, error) {
+	start := q.Start(now)
+	e.mu.Lock()
+	c, ok := e.cache[i]
+	e.mu.Unlock()
+	if ok && c.start.Equal(start) && now.Sub(c.at) < usageCacheTTL {
+		return c.tokens, nil
+	}
+
+	entries, err := e.usage.Query(usage.Query{From: start, Key: q.Key, Tenant: q.Tenant})
+	if err != nil {
+		return 0, err
+	}
+	var tokens int64
+	for _, en := range entries {
+		tokens += en.PromptTokens + en.CompletionTokens
+	}
+
+	e.mu.Lock()
+	e.cache[i] = cachedUsage{tokens: tokens, start: start, at: now}
+	e.mu.Unlock()
+	return tokens, nil
+}
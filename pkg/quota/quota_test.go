@@ -0,0 +1,90 @@
+package quota_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-skynet/LocalAI/pkg/quota"
+	"github.com/go-skynet/LocalAI/pkg/usage"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Quotas", func() {
+	var tempdir string
+	var store *usage.Store
+
+	BeforeEach(func() {
+		var err error
+		tempdir, err = os.MkdirTemp("", "quota")
+		Expect(err).ToNot(HaveOccurred())
+		store, err = usage.Open(filepath.Join(tempdir, "usage.db"))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		store.Close()
+		os.RemoveAll(tempdir)
+	})
+
+	It("computes the periods", func() {
+		t := time.Date(2023, 11, 20, 15, 4, 5, 0, time.UTC)
+		daily := quota.Quota{Period: quota.Daily}
+		Expect(daily.Start(t)).To(Equal(time.Date(2023, 11, 20, 0, 0, 0, 0, time.UTC)))
+		Expect(daily.Reset(t)).To(Equal(time.Date(2023, 11, 21, 0, 0, 0, 0, time.UTC)))
+		monthly := quota.Quota{Period: quota.Monthly}
+		Expect(monthly.Start(t)).To(Equal(time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC)))
+		Expect(monthly.Reset(t)).To(Equal(time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)))
+	})
+
+	It("loads and validates the quotas", func() {
+		file := filepath.Join(tempdir, "quotas.yaml")
+		Expect(os.WriteFile(file, []byte(`
+- key: sha256:0123456789abcdef
+  period: daily
+  tokens: 1000
+- tenant: team-a
+  period: monthly
+  tokens: 100000
+  on_exhausted: degrade
+  fallback_model: phi-2
+`), 0600)).To(Succeed())
+		quotas, err := quota.Load(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(quotas).To(HaveLen(2))
+		Expect(quotas[1].FallbackModel).To(Equal("phi-2"))
+
+		Expect(os.WriteFile(file, []byte("- tenant: team-a\n  period: daily\n  tokens: 10\n  on_exhausted: degrade\n"), 0600)).To(Succeed())
+		_, err = quota.Load(file)
+		Expect(err).To(HaveOccurred())
+		Expect(os.WriteFile(file, []byte("- tenant: team-a\n  period: weekly\n  tokens: 10\n"), 0600)).To(Succeed())
+		_, err = quota.Load(file)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("finds the exhausted quotas of the keys and tenants", func() {
+		e := quota.NewEnforcer([]quota.Quota{
+			{Key: "solo", Period: quota.Daily, Tokens: 100},
+			{Tenant: "team-a", Period: quota.Monthly, Tokens: 100, OnExhausted: quota.Degrade, FallbackModel: "small"},
+		}, store)
+
+		store.Add(usage.Record{Key: "solo", Model: "m", PromptTokens: 40, CompletionTokens: 60})
+		store.Add(usage.Record{Key: "a1", Tenant: "team-a", Model: "m", PromptTokens: 40})
+		// an older period
+		store.Add(usage.Record{Time: time.Now().AddDate(0, -2, 0), Key: "a2", Tenant: "team-a", Model: "m", CompletionTokens: 1000})
+
+		q, exhausted, err := e.Exhausted("solo", "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exhausted).To(BeTrue())
+		Expect(q.Key).To(Equal("solo"))
+
+		_, exhausted, err = e.Exhausted("a2", "team-a")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exhausted).To(BeFalse())
+
+		_, exhausted, err = e.Exhausted("other", "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exhausted).To(BeFalse())
+	})
+})
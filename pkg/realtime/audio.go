@@ -0,0 +1,178 @@
+// Package realtime contains the audio processing used by the Realtime API:
+// PCM16 and WAV encoding, resampling, voice activity detection, and the
+// splitting of the streamed text in sentences to synthesize them as they are generated.
+package realtime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// DecodePCM16 decodes little endian signed 16 bit samples
+func DecodePCM16(dat []byte) []int16 {
+	samples := make([]int16, len(dat)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(dat[2*i:]))
+	}
+	return samples
+}
+
+// EncodePCM16 encodes samples as little endian signed 16 bit
+func EncodePCM16(samples []int16) []byte {
+	dat := make([]byte, 2*len(samples))
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(dat[2*i:], uint16(s))
+	}
+	return dat
+}
+
+// Resample converts mono samples from a sample rate to another, with linear interpolation
+func Resample(samples []int16, from, to int) []int16 {
+	if from == to || len(samples) == 0 {
+		return samples
+	}
+
+	n := int(int64(len(samples)) * int64(to) / int64(from))
+	res := make([]int16, n)
+	for i := range res {
+		pos := float64(i) * float64(from) / float64(to)
+		j := int(pos)
+		if j >= len(samples)-1 {
+			res[i] = samples[len(samples)-1]
+			continue
+		}
+		frac := pos - float64(j)
+		res[i] = int16(float64(samples[j])*(1-frac) + float64(samples[j+1])*frac)
+	}
+	return res
+}
+
+// Amplify multiplies the amplitude of the samples by the gain, clipping them
+func Amplify(samples []int16, gain float64) []int16 {
+	if gain == 1 {
+		return samples
+	}
+	res := make([]int16, len(samples))
+	for i, s := range samples {
+		v := float64(s) * gain
+		res[i] = int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, v)))
+	}
+	return res
+}
+
+// StreamedWAVSize is the size of the samples in the header of a WAV file streamed before its size is known, the
+// largest one
+const StreamedWAVSize = math.MaxUint32 - 36
+
+// WriteWAV writes mono 16 bit samples as a WAV file
+func WriteWAV(w io.Writer, samples []int16, rate int) error {
+	if err := WriteWAVHeader(w, rate, uint32(2*len(samples))); err != nil {
+		return err
+	}
+	_, err := w.Write(EncodePCM16(samples))
+	return err
+}
+
+// WriteWAVHeader writes the header of a mono 16 bit WAV file with dataLen bytes of samples
+func WriteWAVHeader(w io.Writer, rate int, dataLen uint32) error {
+	header := []interface{}{
+		[4]byte{'R', 'I', 'F', 'F'}, 36 + dataLen, [4]byte{'W', 'A', 'V', 'E'},
+		[4]byte{'f', 'm', 't', ' '}, uint32(16),
+		uint16(1),        // PCM
+		uint16(1),        // mono
+		uint32(rate),     // sample rate
+		uint32(rate * 2), // byte rate
+		uint16(2),        // block align
+		uint16(16),       // bits per sample
+		[4]byte{'d', 'a', 't', 'a'}, dataLen,
+	}
+	for _, v := range header {
+		if err := binary.Write(w, binary.LittleEndian, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadWAV reads a 16 bit PCM WAV file, and returns its samples mixed down to mono, and its sample rate
+func ReadWAV(dat []byte) ([]int16, int, error) {
+	r := bytes.NewReader(dat)
+	riff := struct {
+		ID   [4]byte
+		Size uint32
+		Wave [4]byte
+	}{}
+	if err := binary.Read(r, binary.LittleEndian, &riff); err != nil {
+		return nil, 0, fmt.Errorf("not a WAV file: %w", err)
+	}
+	if string(riff.ID[:]) != "RIFF" || string(riff.Wave[:]) != "WAVE" {
+		return nil, 0, fmt.Errorf("not a WAV file")
+	}
+
+	var channels, bits uint16
+	var rate uint32
+	for {
+		chunk := struct {
+			ID   [4]byte
+			Size uint32
+		}{}
+		if err := binary.Read(r, binary.LittleEndian, &chunk); err != nil {
+			return nil, 0, fmt.Errorf("no data in the WAV file: %w", err)
+		}
+
+		switch string(chunk.ID[:]) {
+		case "fmt ":
+			format := struct {
+				Format     uint16
+				Channels   uint16
+				Rate       uint32
+				ByteRate   uint32
+				BlockAlign uint16
+				Bits       uint16
+			}{}
+			if err := binary.Read(r, binary.LittleEndian, &format); err != nil {
+				return nil, 0, err
+			}
+			if format.Format != 1 || format.Bits != 16 {
+				return nil, 0, fmt.Errorf("unsupported WAV format %d with %d bits per sample, only 16 bit PCM is supported", format.Format, format.Bits)
+			}
+			channels, bits, rate = format.Channels, format.Bits, format.Rate
+			// skip the extensions of the format
+			if _, err := r.Seek(int64(chunk.Size)-16, io.SeekCurrent); err != nil {
+				return nil, 0, err
+			}
+		case "data":
+			if bits == 0 || channels == 0 {
+				return nil, 0, fmt.Errorf("the WAV data comes before its format")
+			}
+			size := int(chunk.Size)
+			// streamed WAV files might not know their size
+			if size > r.Len() || size == 0 {
+				size = r.Len()
+			}
+			pcm := make([]byte, size)
+			if _, err := io.ReadFull(r, pcm); err != nil {
+				return nil, 0, err
+			}
+			interleaved := DecodePCM16(pcm)
+
+			samples := make([]int16, len(interleaved)/int(channels))
+			for i := range samples {
+				sum := 0
+				for c := 0; c < int(channels); c++ {
+					sum += int(interleaved[i*int(channels)+c])
+				}
+				samples[i] = int16(sum / int(channels))
+			}
+			return samples, int(rate), nil
+		default:
+			// chunks are padded to an even size
+			if _, err := r.Seek(int64(chunk.Size+chunk.Size%2), io.SeekCurrent); err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package realtime_test
+
+import (
+	"bytes"
+
+	. "github.com/go-skynet/LocalAI/pkg/realtime"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Audio", func() {
+	It("encodes and decodes PCM16", func() {
+		samples := []int16{0, 1, -1, 32767, -32768}
+		Expect(DecodePCM16(EncodePCM16(samples))).To(Equal(samples))
+	})
+
+	It("resamples", func() {
+		samples := []int16{0, 100, 200, 300}
+		Expect(Resample(samples, 16000, 16000)).To(Equal(samples))
+		Expect(Resample(samples, 16000, 32000)).To(Equal([]int16{0, 50, 100, 150, 200, 250, 300, 300}))
+		Expect(Resample(samples, 16000, 8000)).To(Equal([]int16{0, 200}))
+	})
+
+	It("amplifies", func() {
+		samples := []int16{0, 100, -100, 20000, -20000}
+		Expect(Amplify(samples, 1)).To(Equal(samples))
+		Expect(Amplify(samples, 0.5)).To(Equal([]int16{0, 50, -50, 10000, -10000}))
+		Expect(Amplify(samples, 2)).To(Equal([]int16{0, 200, -200, 32767, -32768}))
+		Expect(Amplify(samples, 0)).To(Equal([]int16{0, 0, 0, 0, 0}))
+	})
+
+	It("writes and reads WAV files", func() {
+		samples := []int16{0, 1000, -1000, 32767}
+		buf := &bytes.Buffer{}
+		Expect(WriteWAV(buf, samples, 24000)).To(Succeed())
+		Expect(buf.Len()).To(Equal(44 + 2*len(samples)))
+
+		read, rate, err := ReadWAV(buf.Bytes())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rate).To(Equal(24000))
+		Expect(read).To(Equal(samples))
+	})
+
+	It("fails with invalid WAV files", func() {
+		_, _, err := ReadWAV([]byte("not a wav file"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,144 @@
+package realtime
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// Chunk is a part of a long audio, transcribed while the rest of the audio is decoded
+type Chunk struct {
+	Samples []int16
+	// Start is the offset of the chunk in the audio
+	Start time.Duration
+}
+
+// SplitAudio reads mono 16 bit samples at the rate from r, and calls f with chunks of at most length as soon as they
+// are read. The chunks are cut at the quietest frame of their last seconds, not to split a word between two chunks.
+func SplitAudio(r io.Reader, rate int, length time.Duration, f func(Chunk) error) error {
+	size := int(int64(rate) * int64(length) / int64(time.Second))
+	frame := int(int64(rate) * int64(vadFrame) / int64(time.Second))
+	if size < 2*frame {
+		return fmt.Errorf("chunks of %s are too short", length)
+	}
+	// the cut is searched in the last quarter of the chunk, at most 2s
+	window := size / 4
+	if limit := 2 * rate; window > limit {
+		window = limit
+	}
+
+	pending := make([]int16, 0, size)
+	buf := make([]byte, 2*size)
+	offset := 0
+	var odd []byte
+	for {
+		n, err := io.ReadFull(r, buf[len(odd):])
+		copy(buf, odd)
+		n += len(odd)
+		// an odd byte is kept for the next read
+		odd = append(odd[:0], buf[n-n%2:n]...)
+		pending = append(pending, DecodePCM16(buf[:n-n%2])...)
+
+		for len(pending) >= size {
+			cut := quietestFrame(pending[size-window:size], frame) + size - window
+			if err := f(Chunk{Samples: append([]int16{}, pending[:cut]...), Start: samplesDuration(offset, rate)}); err != nil {
+				return err
+			}
+			offset += cut
+			pending = append(pending[:0], pending[cut:]...)
+		}
+
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(pending) > 0 {
+		return f(Chunk{Samples: pending, Start: samplesDuration(offset, rate)})
+	}
+	return nil
+}
+
+// quietestFrame returns the start of the frame of the samples with the least energy
+func quietestFrame(samples []int16, frame int) int {
+	best, bestEnergy := len(samples), -1.0
+	for start := 0; start+frame <= len(samples); start += frame {
+		energy := 0.0
+		for _, s := range samples[start : start+frame] {
+			energy += float64(s) * float64(s)
+		}
+		if bestEnergy < 0 || energy < bestEnergy {
+			best, bestEnergy = start, energy
+		}
+	}
+	if best == 0 {
+		// never cut an empty chunk
+		return len(samples)
+	}
+	return best
+}
+
+func samplesDuration(samples, rate int) time.Duration {
+	return time.Duration(int64(samples) * int64(time.Second) / int64(rate))
+}
+
+// DecodeAudio converts an audio file of any format to mono 16 bit samples at the rate with the ffmpeg binary, which
+// are read as they are decoded. Closing the reader stops ffmpeg. The 16 bit PCM WAV files are decoded natively.
+func DecodeAudio(ctx context.Context, ffmpeg, file string, rate int) (io.ReadCloser, error) {
+	if samples, from, err := readWAVFile(file); err == nil {
+		return io.NopCloser(bytes.NewReader(EncodePCM16(Resample(samples, from, rate)))), nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	cmd := exec.CommandContext(ctx, ffmpeg, "-nostdin", "-loglevel", "error", "-i", file,
+		"-f", "s16le", "-acodec", "pcm_s16le", "-ac", "1", "-ar", strconv.Itoa(rate), "-")
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, ffmpegError(file, err, stderr)
+	}
+	return &decoder{ReadCloser: out, file: file, cmd: cmd, cancel: cancel, stderr: stderr}, nil
+}
+
+type decoder struct {
+	io.ReadCloser
+	file   string
+	cmd    *exec.Cmd
+	cancel context.CancelFunc
+	stderr *bytes.Buffer
+	done   bool
+}
+
+// Read reports the errors of ffmpeg once the audio is read
+func (d *decoder) Read(p []byte) (int, error) {
+	n, err := d.ReadCloser.Read(p)
+	if err == io.EOF && !d.done {
+		d.done = true
+		if werr := d.cmd.Wait(); werr != nil {
+			return n, ffmpegError(d.file, werr, d.stderr)
+		}
+	}
+	return n, err
+}
+
+func (d *decoder) Close() error {
+	d.cancel()
+	if !d.done {
+		d.done = true
+		d.cmd.Wait()
+	}
+	return nil
+}
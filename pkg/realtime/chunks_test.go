@@ -0,0 +1,54 @@
+package realtime_test
+
+import (
+	"bytes"
+	"errors"
+	"testing/iotest"
+	"time"
+
+	. "github.com/go-skynet/LocalAI/pkg/realtime"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Chunks", func() {
+	speech := append(append(audio(9*time.Second, true), audio(200*time.Millisecond, false)...), audio(5*time.Second, true)...)
+
+	It("splits the audio at the silences", func() {
+		chunks := []Chunk{}
+		err := SplitAudio(iotest.HalfReader(bytes.NewReader(EncodePCM16(speech))), 16000, 10*time.Second, func(c Chunk) error {
+			chunks = append(chunks, c)
+			return nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(chunks).To(HaveLen(2))
+		Expect(chunks[0].Start).To(Equal(time.Duration(0)))
+		Expect(chunks[0].Samples).To(HaveLen(9 * 16000))
+		Expect(chunks[1].Start).To(Equal(9 * time.Second))
+		Expect(chunks[1].Samples).To(Equal(speech[9*16000:]))
+	})
+
+	It("cuts the chunks without silence at their length", func() {
+		chunks := []Chunk{}
+		err := SplitAudio(bytes.NewReader(EncodePCM16(audio(25*time.Second, true))), 16000, 10*time.Second, func(c Chunk) error {
+			chunks = append(chunks, c)
+			return nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(chunks).To(HaveLen(3))
+		for _, c := range chunks {
+			Expect(len(c.Samples)).To(BeNumerically("<=", 10*16000))
+		}
+		Expect(chunks[2].Start + time.Duration(len(chunks[2].Samples))*time.Second/16000).To(Equal(25 * time.Second))
+	})
+
+	It("stops on the errors", func() {
+		calls := 0
+		err := SplitAudio(bytes.NewReader(EncodePCM16(speech)), 16000, 10*time.Second, func(c Chunk) error {
+			calls++
+			return errors.New("disconnected")
+		})
+		Expect(err).To(MatchError("disconnected"))
+		Expect(calls).To(Equal(1))
+	})
+})
@@ -0,0 +1,13 @@
+package realtime_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRealtime(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Realtime test suite")
+}
@@ -0,0 +1,54 @@
+package realtime
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SentenceSplitter accumulates the text streamed by a model, and returns it
+// sentence by sentence, so each one can be synthesized as soon as it is complete
+type SentenceSplitter struct {
+	buf strings.Builder
+}
+
+// Write adds text, and returns the sentences it completes
+func (s *SentenceSplitter) Write(text string) []string {
+	s.buf.WriteString(text)
+	current := s.buf.String()
+
+	sentences := []string{}
+	start := 0
+	runes := []rune(current)
+	for i, r := range runes {
+		if !isSentenceEnd(r) {
+			continue
+		}
+		// the end of the sentence is known once it is followed by a space
+		if i+1 >= len(runes) || !unicode.IsSpace(runes[i+1]) {
+			continue
+		}
+		if sentence := strings.TrimSpace(string(runes[start : i+1])); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		start = i + 1
+	}
+
+	s.buf.Reset()
+	s.buf.WriteString(string(runes[start:]))
+	return sentences
+}
+
+// Flush returns the text which does not end with a complete sentence
+func (s *SentenceSplitter) Flush() string {
+	rest := strings.TrimSpace(s.buf.String())
+	s.buf.Reset()
+	return rest
+}
+
+func isSentenceEnd(r rune) bool {
+	switch r {
+	case '.', '!', '?', ';', ':', '\n', '。', '！', '？':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package realtime_test
+
+import (
+	. "github.com/go-skynet/LocalAI/pkg/realtime"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SentenceSplitter", func() {
+	It("returns the complete sentences", func() {
+		s := &SentenceSplitter{}
+		Expect(s.Write("Hello")).To(BeEmpty())
+		Expect(s.Write(" there! How a")).To(Equal([]string{"Hello there!"}))
+		Expect(s.Write("re you? I'm fine. Th")).To(Equal([]string{"How are you?", "I'm fine."}))
+		Expect(s.Write("anks")).To(BeEmpty())
+		Expect(s.Flush()).To(Equal("Thanks"))
+		Expect(s.Flush()).To(BeEmpty())
+	})
+
+	It("does not split numbers", func() {
+		s := &SentenceSplitter{}
+		Expect(s.Write("It costs 3.50 dollars.")).To(BeEmpty())
+		Expect(s.Write(" ")).To(Equal([]string{"It costs 3.50 dollars."}))
+	})
+})
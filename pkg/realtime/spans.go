@@ -0,0 +1,66 @@
+package realtime
+
+import (
+	"sort"
+	"time"
+)
+
+// Span is a part of an audio
+type Span struct {
+	Start, End time.Duration
+}
+
+// Timeline maps the offsets of an audio made of the spans of another audio to the offsets of the other audio
+type Timeline []timelineSpan
+
+type timelineSpan struct {
+	// the offsets of the span in the audio made of the spans, and in the original audio
+	at, original time.Duration
+	length       time.Duration
+}
+
+// KeepSpans returns the samples of the spans of the audio at the rate, each followed by a gap of silence, and the
+// timeline of the returned samples. The spans are widened by pad, and merged when they overlap.
+func KeepSpans(samples []int16, rate int, spans []Span, pad, gap time.Duration) ([]int16, Timeline) {
+	total := samplesDuration(len(samples), rate)
+	widened := make([]Span, 0, len(spans))
+	for _, s := range spans {
+		s.Start, s.End = max(s.Start-pad, 0), min(s.End+pad, total)
+		if s.End > s.Start {
+			widened = append(widened, s)
+		}
+	}
+	sort.Slice(widened, func(i, j int) bool { return widened[i].Start < widened[j].Start })
+
+	merged := []Span{}
+	for _, s := range widened {
+		if n := len(merged); n > 0 && s.Start <= merged[n-1].End {
+			merged[n-1].End = max(merged[n-1].End, s.End)
+			continue
+		}
+		merged = append(merged, s)
+	}
+
+	index := func(d time.Duration) int {
+		return int(int64(d) * int64(rate) / int64(time.Second))
+	}
+	silence := make([]int16, index(gap))
+	res := []int16{}
+	timeline := Timeline{}
+	for _, s := range merged {
+		part := samples[index(s.Start):index(s.End)]
+		timeline = append(timeline, timelineSpan{at: samplesDuration(len(res), rate), original: s.Start, length: samplesDuration(len(part), rate)})
+		res = append(append(res, part...), silence...)
+	}
+	return res, timeline
+}
+
+// Original returns the offset in the original audio of an offset of the audio made of the spans. The offsets in the
+// gap after a span are the end of the span.
+func (t Timeline) Original(d time.Duration) time.Duration {
+	i := sort.Search(len(t), func(i int) bool { return t[i].at > d }) - 1
+	if i < 0 {
+		return d
+	}
+	return t[i].original + min(d-t[i].at, t[i].length)
+}
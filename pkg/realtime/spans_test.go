@@ -0,0 +1,46 @@
+package realtime_test
+
+import (
+	"time"
+
+	. "github.com/go-skynet/LocalAI/pkg/realtime"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Spans", func() {
+	// 10s of audio at 1kHz, whose samples are their index
+	samples := make([]int16, 10000)
+	for i := range samples {
+		samples[i] = int16(i)
+	}
+
+	It("keeps the spans of the audio, separated by gaps", func() {
+		kept, timeline := KeepSpans(samples, 1000, []Span{{Start: 6 * time.Second, End: 7 * time.Second}, {Start: time.Second, End: 2 * time.Second}}, 0, 500*time.Millisecond)
+		Expect(kept).To(HaveLen(3000))
+		Expect(kept[0]).To(Equal(int16(1000)))
+		Expect(kept[999]).To(Equal(int16(1999)))
+		Expect(kept[1000:1500]).To(Equal(make([]int16, 500)))
+		Expect(kept[1500]).To(Equal(int16(6000)))
+
+		Expect(timeline.Original(0)).To(Equal(time.Second))
+		Expect(timeline.Original(500 * time.Millisecond)).To(Equal(1500 * time.Millisecond))
+		// the gaps are the end of the span before
+		Expect(timeline.Original(1200 * time.Millisecond)).To(Equal(2 * time.Second))
+		Expect(timeline.Original(1700 * time.Millisecond)).To(Equal(6200 * time.Millisecond))
+	})
+
+	It("pads and merges the spans", func() {
+		kept, timeline := KeepSpans(samples, 1000, []Span{{Start: 200 * time.Millisecond, End: time.Second}, {Start: 1200 * time.Millisecond, End: 2 * time.Second}, {Start: 9900 * time.Millisecond, End: 10 * time.Second}}, 200*time.Millisecond, 0)
+		Expect(kept).To(HaveLen(2200 + 300))
+		Expect(kept[0]).To(Equal(int16(0)))
+		Expect(kept[2200]).To(Equal(int16(9700)))
+		Expect(timeline.Original(2300 * time.Millisecond)).To(Equal(9800 * time.Millisecond))
+	})
+
+	It("keeps nothing without spans", func() {
+		kept, timeline := KeepSpans(samples, 1000, nil, 200*time.Millisecond, time.Second)
+		Expect(kept).To(BeEmpty())
+		Expect(timeline.Original(time.Second)).To(Equal(time.Second))
+	})
+})
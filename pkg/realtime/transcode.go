@@ -0,0 +1,94 @@
+package realtime
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNoFFmpeg is returned when an audio needs ffmpeg to be decoded, and it is not installed
+	ErrNoFFmpeg = errors.New("ffmpeg is not available")
+	// ErrUnsupportedAudio is returned when an audio can't be decoded
+	ErrUnsupportedAudio = errors.New("unsupported audio")
+)
+
+// AudioFormat returns the format of an audio file from its first bytes: wav, mp3, ogg (vorbis or opus), flac, webm
+// (or matroska) and m4a (or any MP4 container), empty if unknown.
+func AudioFormat(header []byte) string {
+	switch {
+	case len(header) >= 12 && string(header[:4]) == "RIFF" && string(header[8:12]) == "WAVE":
+		return "wav"
+	case bytes.HasPrefix(header, []byte("ID3")), len(header) >= 2 && header[0] == 0xFF && header[1]&0xE0 == 0xE0:
+		return "mp3"
+	case bytes.HasPrefix(header, []byte("OggS")):
+		return "ogg"
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		return "flac"
+	case bytes.HasPrefix(header, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return "webm"
+	case len(header) >= 8 && string(header[4:8]) == "ftyp":
+		return "m4a"
+	}
+	return ""
+}
+
+// TranscodeWAV converts an audio file to a mono 16 bit WAV file at the rate. The 16 bit PCM WAV files are converted
+// natively, the other formats are decoded with the ffmpeg binary.
+func TranscodeWAV(ctx context.Context, ffmpeg, src, dst string, rate int) error {
+	if samples, from, err := readWAVFile(src); err == nil {
+		out, err := os.Create(dst)
+		if err != nil {
+			return err
+		}
+		err = WriteWAV(out, Resample(samples, from, rate), rate)
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, ffmpeg, "-nostdin", "-loglevel", "error", "-y", "-i", src,
+		"-f", "wav", "-acodec", "pcm_s16le", "-ac", "1", "-ar", strconv.Itoa(rate), dst)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return ffmpegError(src, err, stderr)
+	}
+	return nil
+}
+
+// readWAVFile reads the samples of a 16 bit PCM WAV file
+func readWAVFile(file string) ([]int16, int, error) {
+	dat, err := os.ReadFile(file)
+	if err != nil {
+		return nil, 0, err
+	}
+	if AudioFormat(dat) != "wav" {
+		return nil, 0, fmt.Errorf("not a WAV file")
+	}
+	return ReadWAV(dat)
+}
+
+// ffmpegError explains why ffmpeg could not decode the audio file
+func ffmpegError(file string, err error, stderr *bytes.Buffer) error {
+	format := "the audio"
+	if f, ferr := os.Open(file); ferr == nil {
+		header := make([]byte, 12)
+		n, _ := io.ReadFull(f, header)
+		f.Close()
+		if name := AudioFormat(header[:n]); name != "" {
+			format = "the " + name + " audio"
+		}
+	}
+	if errors.Is(err, exec.ErrNotFound) || errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: it is required to decode %s, install it or convert the audio to WAV", ErrNoFFmpeg, format)
+	}
+	return fmt.Errorf("%w: cannot decode %s: %v: %s", ErrUnsupportedAudio, format, err, strings.TrimSpace(stderr.String()))
+}
@@ -0,0 +1,76 @@
+package realtime_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/go-skynet/LocalAI/pkg/realtime"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Transcode", func() {
+	var dir string
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	writeWAV := func(name string, samples []int16, rate int) string {
+		file := filepath.Join(dir, name)
+		buf := &bytes.Buffer{}
+		Expect(WriteWAV(buf, samples, rate)).To(Succeed())
+		Expect(os.WriteFile(file, buf.Bytes(), 0600)).To(Succeed())
+		return file
+	}
+
+	It("sniffs the formats of the audio", func() {
+		for header, format := range map[string]string{
+			"RIFF\x24\x00\x00\x00WAVEfmt ": "wav",
+			"ID3\x04\x00":                  "mp3",
+			"\xff\xfb\x90\x64":             "mp3",
+			"OggS\x00\x02":                 "ogg",
+			"fLaC\x00\x00":                 "flac",
+			"\x1a\x45\xdf\xa3\x9f\x42\x86": "webm",
+			"\x00\x00\x00\x20ftypM4A ":     "m4a",
+			"not an audio":                 "",
+		} {
+			Expect(AudioFormat([]byte(header))).To(Equal(format), header)
+		}
+	})
+
+	It("resamples the WAV files natively", func() {
+		src := writeWAV("audio.wav", audio(time.Second, true)[:8000], 8000)
+		dst := filepath.Join(dir, "converted.wav")
+		Expect(TranscodeWAV(context.Background(), "missing-ffmpeg", src, dst, 16000)).To(Succeed())
+
+		dat, err := os.ReadFile(dst)
+		Expect(err).ToNot(HaveOccurred())
+		samples, rate, err := ReadWAV(dat)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rate).To(Equal(16000))
+		Expect(samples).To(HaveLen(16000))
+	})
+
+	It("requires ffmpeg for the other formats", func() {
+		src := filepath.Join(dir, "audio.webm")
+		Expect(os.WriteFile(src, []byte("\x1a\x45\xdf\xa3\x9f\x42\x86"), 0600)).To(Succeed())
+		err := TranscodeWAV(context.Background(), filepath.Join(dir, "missing-ffmpeg"), src, filepath.Join(dir, "converted.wav"), 16000)
+		Expect(err).To(MatchError(ErrNoFFmpeg))
+		Expect(err.Error()).To(ContainSubstring("webm"))
+	})
+
+	It("decodes the WAV files natively", func() {
+		samples := audio(time.Second, true)
+		src := writeWAV("audio.wav", samples, 16000)
+		r, err := DecodeAudio(context.Background(), "missing-ffmpeg", src, 16000)
+		Expect(err).ToNot(HaveOccurred())
+		defer r.Close()
+		dat, err := io.ReadAll(r)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(DecodePCM16(dat)).To(Equal(samples))
+	})
+})
@@ -0,0 +1,90 @@
+package realtime
+
+import (
+	"math"
+	"time"
+)
+
+// VAD detects when the user starts and stops speaking, from the energy of the audio
+type VAD struct {
+	// Threshold is the RMS energy, between 0 and 1, above which a frame contains speech
+	Threshold float64
+	// SilenceDuration is how long the energy has to stay below the threshold for the speech to stop
+	SilenceDuration time.Duration
+	// Rate is the sample rate of the audio
+	Rate int
+
+	speaking bool
+	// samples processed so far, and the last sample with speech
+	processed, lastSpeech int
+	// samples of the frame which is not complete yet
+	pending []int16
+}
+
+// VAD events
+const (
+	SpeechStarted = "speech_started"
+	SpeechStopped = "speech_stopped"
+)
+
+type VADEvent struct {
+	Type string
+	// Offset of the event since the start of the audio
+	Offset time.Duration
+}
+
+// frames of 20ms are analyzed
+const vadFrame = 20 * time.Millisecond
+
+func NewVAD(rate int, threshold float64, silence time.Duration) *VAD {
+	return &VAD{Rate: rate, Threshold: threshold, SilenceDuration: silence}
+}
+
+func (v *VAD) offset(samples int) time.Duration {
+	return time.Duration(int64(samples) * int64(time.Second) / int64(v.Rate))
+}
+
+// Speaking returns true if the user is speaking
+func (v *VAD) Speaking() bool {
+	return v.speaking
+}
+
+// Process analyzes the next samples of the audio, and returns the detected events
+func (v *VAD) Process(samples []int16) []VADEvent {
+	events := []VADEvent{}
+	frame := int(int64(v.Rate) * int64(vadFrame) / int64(time.Second))
+	silence := int(int64(v.Rate) * int64(v.SilenceDuration) / int64(time.Second))
+
+	v.pending = append(v.pending, samples...)
+	for len(v.pending) >= frame {
+		f := v.pending[:frame]
+		v.pending = v.pending[frame:]
+
+		sum := 0.0
+		for _, s := range f {
+			x := float64(s) / math.MaxInt16
+			sum += x * x
+		}
+		rms := math.Sqrt(sum / float64(len(f)))
+
+		if rms >= v.Threshold {
+			if !v.speaking {
+				v.speaking = true
+				events = append(events, VADEvent{Type: SpeechStarted, Offset: v.offset(v.processed)})
+			}
+			v.lastSpeech = v.processed + frame
+		} else if v.speaking && v.processed+frame-v.lastSpeech >= silence {
+			v.speaking = false
+			events = append(events, VADEvent{Type: SpeechStopped, Offset: v.offset(v.lastSpeech)})
+		}
+		v.processed += frame
+	}
+	return events
+}
+
+// Reset forgets the audio processed so far
+func (v *VAD) Reset() {
+	v.speaking = false
+	v.processed, v.lastSpeech = 0, 0
+	v.pending = nil
+}
@@ -0,0 +1,59 @@
+package realtime_test
+
+import (
+	"time"
+
+	. "github.com/go-skynet/LocalAI/pkg/realtime"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// audio of the given duration at 16kHz, silent or loud
+func audio(d time.Duration, loud bool) []int16 {
+	samples := make([]int16, 16*d.Milliseconds())
+	if loud {
+		for i := range samples {
+			samples[i] = 10000
+			if i%2 == 0 {
+				samples[i] = -10000
+			}
+		}
+	}
+	return samples
+}
+
+var _ = Describe("VAD", func() {
+	It("detects the speech", func() {
+		vad := NewVAD(16000, 0.1, 500*time.Millisecond)
+
+		Expect(vad.Process(audio(200*time.Millisecond, false))).To(BeEmpty())
+		Expect(vad.Process(audio(time.Second, true))).To(Equal([]VADEvent{{Type: SpeechStarted, Offset: 200 * time.Millisecond}}))
+		Expect(vad.Speaking()).To(BeTrue())
+
+		// short pauses don't stop the speech
+		Expect(vad.Process(audio(300*time.Millisecond, false))).To(BeEmpty())
+		Expect(vad.Process(audio(100*time.Millisecond, true))).To(BeEmpty())
+
+		Expect(vad.Process(audio(time.Second, false))).To(Equal([]VADEvent{{Type: SpeechStopped, Offset: 1600 * time.Millisecond}}))
+		Expect(vad.Speaking()).To(BeFalse())
+	})
+
+	It("processes small chunks", func() {
+		vad := NewVAD(16000, 0.1, 100*time.Millisecond)
+		events := []VADEvent{}
+		chunk := audio(5*time.Millisecond, true)
+		for i := 0; i < 10; i++ {
+			events = append(events, vad.Process(chunk)...)
+		}
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Type).To(Equal(SpeechStarted))
+	})
+
+	It("resets", func() {
+		vad := NewVAD(16000, 0.1, 100*time.Millisecond)
+		vad.Process(audio(100*time.Millisecond, true))
+		vad.Reset()
+		Expect(vad.Speaking()).To(BeFalse())
+		Expect(vad.Process(audio(100*time.Millisecond, true))).To(Equal([]VADEvent{{Type: SpeechStarted}}))
+	})
+})
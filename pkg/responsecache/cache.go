@@ -0,0 +1,156 @@
+// Package responsecache caches the responses of the models, by request, and optionally by the similarity of the
+// embeddings of the prompts, so that repeated requests don't run the inference again.
+package responsecache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"sync"
+	"time"
+)
+
+// Key returns the key of a request from its normalized parts
+func Key(parts ...interface{}) string {
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	for _, p := range parts {
+		enc.Encode(p)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type entry struct {
+	key   string
+	value []byte
+	// the embedding of the prompt, compared only with the ones of the same partition
+	partition string
+	embedding []float32
+	expires   time.Time
+}
+
+// Stats are the statistics of a cache
+type Stats struct {
+	Entries int   `json:"entries"`
+	Hits    int64 `json:"hits"`
+	// SemanticHits are the hits of requests which were not identical to the cached one, but similar
+	SemanticHits int64 `json:"semantic_hits"`
+	// Misses are the requests looked up by key without a response
+	Misses int64 `json:"misses"`
+}
+
+// Cache keeps the most recently used responses, until they expire
+type Cache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	entries    map[string]*list.Element
+	// the entries from the most recently used
+	lru   *list.List
+	stats Stats
+}
+
+// New returns a cache of up to maxEntries responses (no limit when 0), which expire after ttl (never when 0)
+func New(maxEntries int, ttl time.Duration) *Cache {
+	return &Cache{maxEntries: maxEntries, ttl: ttl, entries: map[string]*list.Element{}, lru: list.New()}
+}
+
+func (c *Cache) expired(e *entry) bool {
+	return !e.expires.IsZero() && time.Now().After(e.expires)
+}
+
+func (c *Cache) remove(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.entries, el.Value.(*entry).key)
+}
+
+// Get returns the response of the request with the key
+func (c *Cache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if ok && c.expired(el.Value.(*entry)) {
+		c.remove(el)
+		ok = false
+	}
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	c.stats.Hits++
+	return el.Value.(*entry).value, true
+}
+
+// Similar returns the response of the request of the partition whose embedding is the most similar to the given one,
+// if their cosine similarity is at least threshold. The partition identifies what the requests have in common besides
+// their prompts (e.g. the type of the response and the parameters), so the similar prompts of different requests don't
+// share their responses.
+func (c *Cache) Similar(partition string, embedding []float32, threshold float64) ([]byte, float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var best *list.Element
+	bestScore := threshold
+	for el := c.lru.Front(); el != nil; {
+		next := el.Next()
+		e := el.Value.(*entry)
+		if c.expired(e) {
+			c.remove(el)
+		} else if e.embedding != nil && e.partition == partition {
+			if score := cosine(embedding, e.embedding); score >= bestScore {
+				best, bestScore = el, score
+			}
+		}
+		el = next
+	}
+	if best == nil {
+		return nil, 0, false
+	}
+	c.lru.MoveToFront(best)
+	c.stats.SemanticHits++
+	return best.Value.(*entry).value, bestScore, true
+}
+
+// Put caches the response of the request with the key, and with the embedding of its prompt in the partition if not nil
+func (c *Cache) Put(key string, value []byte, partition string, embedding []float32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+	e := &entry{key: key, value: value, partition: partition, embedding: embedding}
+	if c.ttl > 0 {
+		e.expires = time.Now().Add(c.ttl)
+	}
+	c.entries[key] = c.lru.PushFront(e)
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+// Stats returns the statistics of the cache
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st := c.stats
+	st.Entries = c.lru.Len()
+	return st
+}
+
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
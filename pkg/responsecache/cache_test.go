@@ -0,0 +1,80 @@
+package responsecache_test
+
+import (
+	"time"
+
+	. "github.com/go-skynet/LocalAI/pkg/responsecache"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cache", func() {
+	It("returns the responses of the same requests", func() {
+		c := New(0, 0)
+		key := Key("model", "Hello", 0.7)
+		Expect(key).To(Equal(Key("model", "Hello", 0.7)))
+		Expect(key).ToNot(Equal(Key("model", "Hello", 0.8)))
+
+		_, ok := c.Get(key)
+		Expect(ok).To(BeFalse())
+		c.Put(key, []byte("Hi!"), "", nil)
+		v, ok := c.Get(key)
+		Expect(ok).To(BeTrue())
+		Expect(string(v)).To(Equal("Hi!"))
+		Expect(c.Stats()).To(Equal(Stats{Entries: 1, Hits: 1, Misses: 1}))
+	})
+
+	It("evicts the least recently used responses", func() {
+		c := New(2, 0)
+		c.Put("a", []byte("a"), "", nil)
+		c.Put("b", []byte("b"), "", nil)
+		c.Get("a")
+		c.Put("c", []byte("c"), "", nil)
+
+		_, ok := c.Get("b")
+		Expect(ok).To(BeFalse())
+		_, ok = c.Get("a")
+		Expect(ok).To(BeTrue())
+		Expect(c.Stats().Entries).To(Equal(2))
+	})
+
+	It("expires the responses", func() {
+		c := New(0, 10*time.Millisecond)
+		c.Put("a", []byte("a"), "", nil)
+		Eventually(func() bool {
+			_, ok := c.Get("a")
+			return ok
+		}).Should(BeFalse())
+		Expect(c.Stats().Entries).To(BeZero())
+	})
+
+	It("returns the responses of similar requests", func() {
+		c := New(0, 0)
+		c.Put("a", []byte("a"), "chat", []float32{1, 0, 0})
+		c.Put("b", []byte("b"), "chat", []float32{0, 1, 0})
+		c.Put("c", []byte("c"), "", nil)
+
+		v, score, ok := c.Similar("chat", []float32{0.1, 1, 0}, 0.95)
+		Expect(ok).To(BeTrue())
+		Expect(string(v)).To(Equal("b"))
+		Expect(score).To(BeNumerically(">", 0.99))
+
+		_, _, ok = c.Similar("chat", []float32{1, 1, 0}, 0.95)
+		Expect(ok).To(BeFalse())
+		Expect(c.Stats().SemanticHits).To(Equal(int64(1)))
+	})
+
+	It("returns the responses of similar requests of the same partition only", func() {
+		c := New(0, 0)
+		c.Put("chat", []byte("chat"), Key("chat.completion", 0.7), []float32{1, 0, 0})
+
+		_, _, ok := c.Similar(Key("text_completion", 0.7), []float32{1, 0, 0}, 0.95)
+		Expect(ok).To(BeFalse())
+		_, _, ok = c.Similar(Key("chat.completion", 0.8), []float32{1, 0, 0}, 0.95)
+		Expect(ok).To(BeFalse())
+
+		v, _, ok := c.Similar(Key("chat.completion", 0.7), []float32{1, 0, 0}, 0.95)
+		Expect(ok).To(BeTrue())
+		Expect(string(v)).To(Equal("chat"))
+	})
+})
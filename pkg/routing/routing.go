@@ -0,0 +1,142 @@
+// Package routing maps the model names requested by the clients to the configured models,
+// so the applications which hardcode the OpenAI model names (e.g. gpt-4) are served by local models.
+package routing
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule routes the requests of the model names it matches to a configured model
+type Rule struct {
+	// Match is the requested model name, where * matches any sequence of characters and ? any single character
+	Match string `yaml:"match,omitempty" json:"match,omitempty"`
+	// Regex is a regular expression the whole requested model name has to match, instead of Match
+	Regex string `yaml:"regex,omitempty" json:"regex,omitempty"`
+	// Model is the name of the model serving the requests
+	Model string `yaml:"model" json:"model"`
+	// Parameters override the parameters of the model, as in the parameters of its config file
+	Parameters map[string]interface{} `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// Exact returns true when the rule matches a single model name
+func (r Rule) Exact() bool {
+	return r.Regex == "" && !strings.ContainsAny(r.Match, "*?")
+}
+
+// Table is an ordered list of rules, the first rule matching a model name routes it
+type Table struct {
+	rules []Rule
+}
+
+// New validates and compiles the rules
+func New(rules []Rule) (*Table, error) {
+	t := &Table{}
+	for i, r := range rules {
+		switch {
+		case r.Model == "":
+			return nil, fmt.Errorf("rule %d: no model to route to", i)
+		case (r.Match == "") == (r.Regex == ""):
+			return nil, fmt.Errorf("rule %d: either match or regex has to be set", i)
+		}
+
+		pattern := r.Regex
+		if r.Match != "" {
+			pattern = regexp.QuoteMeta(r.Match)
+			pattern = strings.ReplaceAll(pattern, `\*`, ".*")
+			pattern = strings.ReplaceAll(pattern, `\?`, ".")
+		}
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+		r.re = re
+		t.rules = append(t.rules, r)
+	}
+	return t, nil
+}
+
+// Load reads the rules of a YAML file
+func Load(file string) (*Table, error) {
+	dat, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read routing file: %w", err)
+	}
+	rules := []Rule{}
+	if err := yaml.Unmarshal(dat, &rules); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal routing file: %w", err)
+	}
+	t, err := New(rules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid routing file %s: %w", file, err)
+	}
+	return t, nil
+}
+
+// Save writes the rules to a YAML file, in the format of Load
+func (t *Table) Save(file string) error {
+	dat, err := yaml.Marshal(t.Rules())
+	if err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, dat, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// Rules returns the rules of the table, in order
+func (t *Table) Rules() []Rule {
+	if t == nil {
+		return nil
+	}
+	return append([]Rule{}, t.rules...)
+}
+
+// WithAlias returns a table where the alias routes to the model: the exact rule of the alias is replaced, keeping its
+// parameters, or added before the other rules. The table is not modified, so that the requests being served keep a
+// consistent view of the routes.
+func (t *Table) WithAlias(alias, model string) (*Table, error) {
+	rules := t.Rules()
+	for i, r := range rules {
+		if r.Exact() && r.Match == alias {
+			rules[i].Model = model
+			return New(rules)
+		}
+	}
+	return New(append([]Rule{{Match: alias, Model: model}}, rules...))
+}
+
+// Resolve returns the first rule matching the model name, and false if there is none
+func (t *Table) Resolve(name string) (Rule, bool) {
+	if t == nil {
+		return Rule{}, false
+	}
+	for _, r := range t.rules {
+		if r.re.MatchString(name) {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Aliases returns the model names of the exact rules
+func (t *Table) Aliases() []string {
+	if t == nil {
+		return nil
+	}
+	res := []string{}
+	for _, r := range t.rules {
+		if r.Exact() {
+			res = append(res, r.Match)
+		}
+	}
+	return res
+}
@@ -0,0 +1,126 @@
+package routing_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/go-skynet/LocalAI/pkg/routing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Routing", func() {
+	It("routes the model names to the first matching rule", func() {
+		t, err := New([]Rule{
+			{Match: "gpt-4", Model: "mistral", Parameters: map[string]interface{}{"temperature": 0.2}},
+			{Match: "gpt-3.5-*", Model: "phi-2"},
+			{Regex: "claude-[0-9]+(-.*)?", Model: "llama-3"},
+			{Match: "*", Model: "fallback"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		r, ok := t.Resolve("gpt-4")
+		Expect(ok).To(BeTrue())
+		Expect(r.Model).To(Equal("mistral"))
+		Expect(r.Parameters).To(HaveKeyWithValue("temperature", 0.2))
+
+		r, _ = t.Resolve("gpt-3.5-turbo-16k")
+		Expect(r.Model).To(Equal("phi-2"))
+		// the dots of the patterns are not wildcards
+		r, _ = t.Resolve("gpt-3x5-turbo")
+		Expect(r.Model).To(Equal("fallback"))
+		r, _ = t.Resolve("claude-3-opus")
+		Expect(r.Model).To(Equal("llama-3"))
+		// the regular expressions match the whole name
+		r, _ = t.Resolve("my-claude-3")
+		Expect(r.Model).To(Equal("fallback"))
+		r, _ = t.Resolve("gpt-4-turbo")
+		Expect(r.Model).To(Equal("fallback"))
+
+		Expect(t.Aliases()).To(Equal([]string{"gpt-4"}))
+	})
+
+	It("does not route without rules", func() {
+		var t *Table
+		_, ok := t.Resolve("gpt-4")
+		Expect(ok).To(BeFalse())
+		Expect(t.Aliases()).To(BeEmpty())
+	})
+
+	It("rejects the invalid rules", func() {
+		_, err := New([]Rule{{Match: "gpt-4"}})
+		Expect(err).To(HaveOccurred())
+		_, err = New([]Rule{{Match: "gpt-4", Regex: "gpt-4", Model: "mistral"}})
+		Expect(err).To(HaveOccurred())
+		_, err = New([]Rule{{Regex: "gpt-(", Model: "mistral"}})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("loads the rules of a file", func() {
+		file := filepath.Join(GinkgoT().TempDir(), "routes.yaml")
+		Expect(os.WriteFile(file, []byte(`
+- match: gpt-4*
+  model: mistral
+  parameters:
+    temperature: 0.2
+    max_tokens: 1024
+- regex: "text-embedding-.*"
+  model: bert
+`), 0644)).To(Succeed())
+
+		t, err := Load(file)
+		Expect(err).ToNot(HaveOccurred())
+		r, ok := t.Resolve("gpt-4o")
+		Expect(ok).To(BeTrue())
+		Expect(r.Model).To(Equal("mistral"))
+		Expect(r.Parameters).To(HaveKeyWithValue("max_tokens", 1024))
+		r, _ = t.Resolve("text-embedding-ada-002")
+		Expect(r.Model).To(Equal("bert"))
+		_, ok = t.Resolve("whisper-1")
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("Aliases", func() {
+	It("switches an alias to another model, keeping the table", func() {
+		t, err := New([]Rule{
+			{Match: "chat", Model: "mistral-v1", Parameters: map[string]interface{}{"temperature": 0.2}},
+			{Match: "*", Model: "fallback"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		switched, err := t.WithAlias("chat", "mistral-v2")
+		Expect(err).ToNot(HaveOccurred())
+		r, _ := switched.Resolve("chat")
+		Expect(r.Model).To(Equal("mistral-v2"))
+		Expect(r.Parameters).To(HaveKeyWithValue("temperature", 0.2))
+		r, _ = t.Resolve("chat")
+		Expect(r.Model).To(Equal("mistral-v1"))
+
+		// the new aliases are matched before the patterns
+		added, err := switched.WithAlias("embed", "bert")
+		Expect(err).ToNot(HaveOccurred())
+		r, _ = added.Resolve("embed")
+		Expect(r.Model).To(Equal("bert"))
+		Expect(added.Aliases()).To(Equal([]string{"embed", "chat"}))
+
+		var empty *Table
+		created, err := empty.WithAlias("chat", "phi-2")
+		Expect(err).ToNot(HaveOccurred())
+		r, _ = created.Resolve("chat")
+		Expect(r.Model).To(Equal("phi-2"))
+	})
+
+	It("saves the rules to a file", func() {
+		file := filepath.Join(GinkgoT().TempDir(), "routes.yaml")
+		t, err := New([]Rule{{Match: "chat", Model: "mistral"}, {Regex: "gpt-.*", Model: "phi-2"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(t.Save(file)).To(Succeed())
+
+		loaded, err := Load(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loaded.Rules()).To(HaveLen(2))
+		r, _ := loaded.Resolve("gpt-4")
+		Expect(r.Model).To(Equal("phi-2"))
+	})
+})
@@ -0,0 +1,178 @@
+// Package sandbox contains the backend processes started by LocalAI: they can run as another user, with their
+// filesystem restricted by Landlock and their system calls filtered by a seccomp profile. The backends are started
+// through LocalAI itself, which applies the restrictions to its own process before executing the backend, as they are
+// inherited by the executed program.
+package sandbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"runtime"
+	"strconv"
+	"strings"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"gopkg.in/yaml.v3"
+)
+
+// Command is the hidden command of LocalAI executing the backends in the sandbox. The programs embedding LocalAI must
+// call Exec with the arguments following it.
+const Command = "backend-sandbox"
+
+// system directories the backends can read and execute with Landlock, when they exist
+var systemPaths = []string{"/usr", "/bin", "/sbin", "/lib", "/lib32", "/lib64", "/etc", "/proc", "/sys"}
+
+// system directories the backends can write to with Landlock: the devices are needed to access the GPUs
+var systemWritablePaths = []string{"/dev", "/tmp"}
+
+// Spec is the sandbox of a backend
+type Spec struct {
+	// User runs the backend as the user, given by name or as uid[:gid]. LocalAI must run as root.
+	User string `json:"user,omitempty"`
+	// Landlock restricts the filesystem the backend can access to the system directories and the given paths.
+	// It requires Linux 5.13 or later.
+	Landlock bool `json:"landlock,omitempty"`
+	// ReadOnly are the paths the backend can read and execute with Landlock
+	ReadOnly []string `json:"read_only,omitempty"`
+	// ReadWrite are the paths the backend can write to with Landlock
+	ReadWrite []string `json:"read_write,omitempty"`
+	// Seccomp is the file of the seccomp profile filtering the system calls of the backend
+	Seccomp string `json:"seccomp,omitempty"`
+}
+
+// Enabled returns true if the spec restricts anything
+func (s Spec) Enabled() bool {
+	return s.User != "" || s.Landlock || s.Seccomp != ""
+}
+
+// Command returns the command running the backend with the given arguments in the sandbox. The user and the seccomp
+// profile are checked beforehand, to fail before starting the backend.
+func (s Spec) Command(backend string, args ...string) (string, []string, error) {
+	if runtime.GOOS != "linux" {
+		return "", nil, fmt.Errorf("the sandbox of the backends is only supported on Linux")
+	}
+	if s.User != "" {
+		if _, err := lookupUser(s.User); err != nil {
+			return "", nil, err
+		}
+	}
+	if s.Seccomp != "" {
+		if _, err := LoadProfile(s.Seccomp); err != nil {
+			return "", nil, err
+		}
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot find the executable of LocalAI to start the sandbox: %w", err)
+	}
+	spec, err := json.Marshal(s)
+	if err != nil {
+		return "", nil, err
+	}
+	return exe, append([]string{Command, string(spec), backend}, args...), nil
+}
+
+// Exec applies the sandbox to the current process, and replaces it with the backend. The arguments are the spec as
+// JSON, the backend and its arguments. It only returns on errors.
+func Exec(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: %s <spec> <backend> [arguments...]", Command)
+	}
+	s := Spec{}
+	if err := json.Unmarshal([]byte(args[0]), &s); err != nil {
+		return fmt.Errorf("invalid sandbox spec: %w", err)
+	}
+	return s.exec(args[1], args[2:])
+}
+
+type credentials struct {
+	uid, gid int
+	groups   []int
+}
+
+// lookupUser returns the credentials of the user, given by name (with its groups) or as uid[:gid]
+func lookupUser(name string) (credentials, error) {
+	id, group, hasGroup := strings.Cut(name, ":")
+	if uid, err := strconv.Atoi(id); err == nil {
+		c := credentials{uid: uid, gid: uid}
+		if hasGroup {
+			gid, err := strconv.Atoi(group)
+			if err != nil {
+				return credentials{}, fmt.Errorf("invalid group of the backend user %q", name)
+			}
+			c.gid = gid
+		}
+		return c, nil
+	}
+
+	u, err := user.Lookup(name)
+	if err != nil {
+		return credentials{}, fmt.Errorf("cannot find the backend user: %w", err)
+	}
+	c := credentials{}
+	if c.uid, err = strconv.Atoi(u.Uid); err != nil {
+		return credentials{}, err
+	}
+	if c.gid, err = strconv.Atoi(u.Gid); err != nil {
+		return credentials{}, err
+	}
+	gids, err := u.GroupIds()
+	if err != nil {
+		return credentials{}, fmt.Errorf("cannot find the groups of the backend user %s: %w", name, err)
+	}
+	for _, g := range gids {
+		if gid, err := strconv.Atoi(g); err == nil {
+			c.groups = append(c.groups, gid)
+		}
+	}
+	return c, nil
+}
+
+// profile is the YAML of the seccomp profiles
+type profile struct {
+	DefaultAction string `yaml:"default_action"`
+	Syscalls      []struct {
+		Action string   `yaml:"action"`
+		Names  []string `yaml:"names"`
+	} `yaml:"syscalls"`
+}
+
+// LoadProfile reads a seccomp profile, the action taken on the system calls not listed and the actions of groups of
+// system calls:
+//
+//	default_action: errno
+//	syscalls:
+//	  - action: allow
+//	    names: [read, write, ...]
+//
+// The actions are allow, errno, log, trap, kill_thread and kill_process.
+func LoadProfile(file string) (seccomp.Policy, error) {
+	dat, err := os.ReadFile(file)
+	if err != nil {
+		return seccomp.Policy{}, fmt.Errorf("cannot read the seccomp profile: %w", err)
+	}
+	p := profile{}
+	if err := yaml.Unmarshal(dat, &p); err != nil {
+		return seccomp.Policy{}, fmt.Errorf("cannot parse the seccomp profile %s: %w", file, err)
+	}
+
+	policy := seccomp.Policy{}
+	if err := policy.DefaultAction.Unpack(p.DefaultAction); err != nil {
+		return seccomp.Policy{}, fmt.Errorf("invalid default_action of the seccomp profile %s: %w", file, err)
+	}
+	for i, g := range p.Syscalls {
+		group := seccomp.SyscallGroup{Names: g.Names}
+		if err := group.Action.Unpack(g.Action); err != nil {
+			return seccomp.Policy{}, fmt.Errorf("invalid action of the group %d of the seccomp profile %s: %w", i, file, err)
+		}
+		policy.Syscalls = append(policy.Syscalls, group)
+	}
+	// the unknown system calls are found when assembling the filter
+	if _, err := policy.Assemble(); err != nil {
+		return seccomp.Policy{}, fmt.Errorf("invalid seccomp profile %s: %w", file, err)
+	}
+	return policy, nil
+}
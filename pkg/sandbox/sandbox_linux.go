@@ -0,0 +1,131 @@
+//go:build linux
+// +build linux
+
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// the access rights of the first version of Landlock, handled by all the kernels supporting it
+	landlockAccess = unix.LANDLOCK_ACCESS_FS_EXECUTE | unix.LANDLOCK_ACCESS_FS_WRITE_FILE | unix.LANDLOCK_ACCESS_FS_READ_FILE |
+		unix.LANDLOCK_ACCESS_FS_READ_DIR | unix.LANDLOCK_ACCESS_FS_REMOVE_DIR | unix.LANDLOCK_ACCESS_FS_REMOVE_FILE |
+		unix.LANDLOCK_ACCESS_FS_MAKE_CHAR | unix.LANDLOCK_ACCESS_FS_MAKE_DIR | unix.LANDLOCK_ACCESS_FS_MAKE_REG |
+		unix.LANDLOCK_ACCESS_FS_MAKE_SOCK | unix.LANDLOCK_ACCESS_FS_MAKE_FIFO | unix.LANDLOCK_ACCESS_FS_MAKE_BLOCK |
+		unix.LANDLOCK_ACCESS_FS_MAKE_SYM
+	landlockReadOnly = unix.LANDLOCK_ACCESS_FS_EXECUTE | unix.LANDLOCK_ACCESS_FS_READ_FILE | unix.LANDLOCK_ACCESS_FS_READ_DIR
+	// the rights which apply to the files, the others only apply to the directories
+	landlockFile = unix.LANDLOCK_ACCESS_FS_EXECUTE | unix.LANDLOCK_ACCESS_FS_WRITE_FILE | unix.LANDLOCK_ACCESS_FS_READ_FILE
+)
+
+func (s Spec) exec(backend string, args []string) error {
+	// the restrictions of Landlock and seccomp apply to the thread, which executes the backend
+	runtime.LockOSThread()
+
+	path, err := exec.LookPath(backend)
+	if err != nil {
+		return err
+	}
+
+	if s.User != "" {
+		c, err := lookupUser(s.User)
+		if err != nil {
+			return err
+		}
+		if err := syscall.Setgroups(c.groups); err != nil {
+			return fmt.Errorf("failed setting the groups of the backend: %w", err)
+		}
+		if err := syscall.Setgid(c.gid); err != nil {
+			return fmt.Errorf("failed setting the group of the backend: %w", err)
+		}
+		if err := syscall.Setuid(c.uid); err != nil {
+			return fmt.Errorf("failed setting the user of the backend: %w", err)
+		}
+	}
+
+	// the backend cannot gain privileges, e.g. with setuid executables, which also allows the unprivileged filters
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("failed setting no_new_privs: %w", err)
+	}
+
+	if s.Landlock {
+		if err := restrictFilesystem(append(systemPaths, s.ReadOnly...), append(systemWritablePaths, s.ReadWrite...)); err != nil {
+			return err
+		}
+	}
+
+	// the seccomp filter comes last, as the profile might deny the system calls of the other restrictions
+	if s.Seccomp != "" {
+		policy, err := LoadProfile(s.Seccomp)
+		if err != nil {
+			return err
+		}
+		if err := seccomp.LoadFilter(seccomp.Filter{NoNewPrivs: true, Policy: policy}); err != nil {
+			return fmt.Errorf("failed loading the seccomp profile %s: %w", s.Seccomp, err)
+		}
+	}
+
+	return syscall.Exec(path, append([]string{backend}, args...), os.Environ())
+}
+
+// restrictFilesystem restricts the access to the filesystem to the paths with Landlock
+func restrictFilesystem(readOnly, readWrite []string) error {
+	attr := unix.LandlockRulesetAttr{Access_fs: landlockAccess}
+	ruleset, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr), 0)
+	if errno != 0 {
+		return fmt.Errorf("Landlock is not supported by the kernel: %w", errno)
+	}
+	defer unix.Close(int(ruleset))
+
+	allow := func(path string, access uint64) error {
+		if path == "" {
+			return nil
+		}
+		fd, err := unix.Open(path, unix.O_PATH|unix.O_CLOEXEC, 0)
+		if errors.Is(err, unix.ENOENT) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("cannot open %s: %w", path, err)
+		}
+		defer unix.Close(fd)
+
+		st := unix.Stat_t{}
+		if err := unix.Fstat(fd, &st); err != nil {
+			return err
+		}
+		if st.Mode&unix.S_IFMT != unix.S_IFDIR {
+			access &= landlockFile
+		}
+		rule := unix.LandlockPathBeneathAttr{Allowed_access: access, Parent_fd: int32(fd)}
+		if _, _, errno := unix.Syscall6(unix.SYS_LANDLOCK_ADD_RULE, ruleset, unix.LANDLOCK_RULE_PATH_BENEATH, uintptr(unsafe.Pointer(&rule)), 0, 0, 0); errno != 0 {
+			return fmt.Errorf("failed allowing %s with Landlock: %w", path, errno)
+		}
+		return nil
+	}
+	for _, p := range readOnly {
+		if err := allow(p, landlockReadOnly); err != nil {
+			return err
+		}
+	}
+	for _, p := range readWrite {
+		if err := allow(p, landlockAccess); err != nil {
+			return err
+		}
+	}
+
+	if _, _, errno := unix.Syscall(unix.SYS_LANDLOCK_RESTRICT_SELF, ruleset, 0, 0); errno != 0 {
+		return fmt.Errorf("failed restricting the filesystem with Landlock: %w", errno)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package sandbox_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/go-skynet/LocalAI/pkg/sandbox"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sandbox", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	writeProfile := func(content string) string {
+		file := filepath.Join(dir, "profile.yaml")
+		Expect(os.WriteFile(file, []byte(content), 0644)).To(Succeed())
+		return file
+	}
+
+	It("loads the seccomp profiles", func() {
+		_, err := sandbox.LoadProfile(writeProfile("default_action: allow\nsyscalls:\n  - action: errno\n    names: [ptrace, mount]\n"))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = sandbox.LoadProfile(writeProfile("default_action: deny\nsyscalls:\n  - action: allow\n    names: [read]\n"))
+		Expect(err).To(HaveOccurred())
+		_, err = sandbox.LoadProfile(writeProfile("default_action: errno\nsyscalls:\n  - action: allow\n    names: [not_a_syscall]\n"))
+		Expect(err).To(HaveOccurred())
+		_, err = sandbox.LoadProfile(filepath.Join(dir, "missing.yaml"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("runs the backends through LocalAI", func() {
+		if runtime.GOOS != "linux" {
+			Skip("the sandbox is only supported on Linux")
+		}
+		spec := sandbox.Spec{User: "1000:1000", Landlock: true, ReadOnly: []string{"/models"}}
+		Expect(spec.Enabled()).To(BeTrue())
+		Expect(sandbox.Spec{}.Enabled()).To(BeFalse())
+
+		name, args, err := spec.Command("/backends/llama", "--addr", "127.0.0.1:5000")
+		Expect(err).ToNot(HaveOccurred())
+		exe, err := os.Executable()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(name).To(Equal(exe))
+		Expect(args[0]).To(Equal(sandbox.Command))
+		Expect(args[2:]).To(Equal([]string{"/backends/llama", "--addr", "127.0.0.1:5000"}))
+
+		decoded := sandbox.Spec{}
+		Expect(json.Unmarshal([]byte(args[1]), &decoded)).To(Succeed())
+		Expect(decoded).To(Equal(spec))
+
+		_, _, err = sandbox.Spec{User: "no-such-user-of-localai"}.Command("/backends/llama")
+		Expect(err).To(HaveOccurred())
+		_, _, err = sandbox.Spec{Seccomp: filepath.Join(dir, "missing.yaml")}.Command("/backends/llama")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package sandbox
+
+import "fmt"
+
+func (s Spec) exec(backend string, args []string) error {
+	return fmt.Errorf("the sandbox of the backends is only supported on Linux")
+}
@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"math/bits"
+	"sync"
+)
+
+const (
+	// weight of the newest observation in the moving averages
+	smoothing = 0.2
+	// predicted output when nothing is known about the model yet
+	defaultPrediction = 128
+)
+
+// Predictor estimates the number of tokens a model will generate for a prompt, from the outputs of the previous
+// requests with a prompt of similar length. It is meant to be cheap rather than accurate: it is only used to order
+// the queued requests.
+type Predictor struct {
+	mu      sync.Mutex
+	buckets map[int]float64
+	overall float64
+	samples int
+}
+
+func NewPredictor() *Predictor {
+	return &Predictor{buckets: map[int]float64{}}
+}
+
+// prompts are grouped by the order of magnitude of their length
+func bucket(promptLen int) int {
+	if promptLen <= 0 {
+		return 0
+	}
+	return bits.Len(uint(promptLen))
+}
+
+// Predict returns the expected output length of a prompt of promptLen characters, capped to maxTokens (if not 0)
+func (p *Predictor) Predict(promptLen, maxTokens int) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	prediction := float64(defaultPrediction)
+	if v, ok := p.buckets[bucket(promptLen)]; ok {
+		prediction = v
+	} else if p.samples > 0 {
+		prediction = p.overall
+	}
+
+	if maxTokens > 0 && prediction > float64(maxTokens) {
+		return maxTokens
+	}
+	return int(prediction + 0.5)
+}
+
+// Observe records the output length of a request, to refine the next predictions
+func (p *Predictor) Observe(promptLen, outputTokens int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	b := bucket(promptLen)
+	if v, ok := p.buckets[b]; ok {
+		p.buckets[b] = v + smoothing*(float64(outputTokens)-v)
+	} else {
+		p.buckets[b] = float64(outputTokens)
+	}
+
+	if p.samples == 0 {
+		p.overall = float64(outputTokens)
+	} else {
+		p.overall += smoothing * (float64(outputTokens) - p.overall)
+	}
+	p.samples++
+}
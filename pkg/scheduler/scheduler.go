@@ -0,0 +1,422 @@
+// Package scheduler orders the requests waiting for a model, to improve the aggregate throughput of the backends
+// processing several requests at once (continuous batching).
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type Policy string
+
+const (
+	// FIFO serves the requests in their order of arrival
+	FIFO Policy = "fifo"
+	// SJF serves first the requests expected to generate the shortest outputs
+	SJF Policy = "sjf"
+	// Fair serves the clients in turn, starting with the ones with the fewest requests being processed, so that a
+	// client sending many requests at once doesn't hold all the slots
+	Fair Policy = "fair"
+)
+
+// DefaultMaxWait is the time after which a request is served before the shorter ones, so that long requests don't starve
+const DefaultMaxWait = 30 * time.Second
+
+// weight of the newest request in the moving average of the processing time
+const durationSmoothing = 0.2
+
+// QueueFullError is returned for the requests which cannot be queued, as the queue is full of requests of the same
+// priority or higher, and for the queued requests which were dropped for a request of a higher priority
+type QueueFullError struct {
+	// RetryAfter is the estimated time before a slot is free
+	RetryAfter time.Duration
+}
+
+func (e *QueueFullError) Error() string {
+	return fmt.Sprintf("too many requests queued, retry in %s", e.RetryAfter.Round(time.Second))
+}
+
+// Request is a request waiting for a slot
+type Request struct {
+	// Client identifies the client of the request (e.g. its API key), which the Fair policy serves in turn with the others
+	Client string
+	// Priority orders the requests before the policy, the requests of a higher priority are served first
+	Priority int
+	// Cost is the expected output length of the request
+	Cost int
+}
+
+type client struct {
+	// number of requests being processed
+	busy int
+	// dispatch of the last request of the client
+	served uint64
+}
+
+type waiter struct {
+	Request
+	seq      uint64
+	enqueued time.Time
+	ready    chan struct{}
+	// dropped is set before ready is closed, when the request is dropped from the queue
+	dropped bool
+	// changed is signaled when the position of the waiter may have changed
+	changed chan struct{}
+}
+
+// Status is the position of a queued request, and the estimated time before it is processed
+type Status struct {
+	// Position is 1 for the next request to be processed
+	Position int
+	// ETA is 0 while the processing time of the requests is unknown
+	ETA time.Duration
+}
+
+// Scheduler admits up to slots requests at once, and queues the others according to its policy
+type Scheduler struct {
+	mu      sync.Mutex
+	slots   int
+	busy    int
+	policy  Policy
+	maxWait time.Duration
+	// maximum number of queued requests, 0 for no limit
+	maxQueue int
+	seq      uint64
+	waiters  []*waiter
+	// the clients with requests being processed
+	clients    map[string]client
+	dispatches uint64
+	// closed once the requests being processed are done, while the slots are resized
+	drained chan struct{}
+	// average time a request holds a slot
+	duration time.Duration
+}
+
+func New(slots int, policy Policy, maxWait time.Duration) (*Scheduler, error) {
+	switch policy {
+	case FIFO, SJF, Fair:
+	default:
+		return nil, fmt.Errorf("unknown scheduling policy %q", policy)
+	}
+	if slots < 1 {
+		slots = 1
+	}
+	if maxWait <= 0 {
+		maxWait = DefaultMaxWait
+	}
+	return &Scheduler{slots: slots, policy: policy, maxWait: maxWait, clients: map[string]client{}}, nil
+}
+
+// SetMaxQueue limits the number of queued requests, the requests beyond it fail with a QueueFullError. 0 removes the limit.
+func (s *Scheduler) SetMaxQueue(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxQueue = n
+}
+
+// Acquire waits for a free slot for a request of the given cost (its expected output length), and returns the
+// function to call once the request is processed
+func (s *Scheduler) Acquire(ctx context.Context, cost int) (func(), error) {
+	return s.AcquireWithStatus(ctx, cost, nil)
+}
+
+// AcquireWithStatus is like Acquire, and calls status with the position of the request each time it changes while it is queued
+func (s *Scheduler) AcquireWithStatus(ctx context.Context, cost int, status func(Status)) (func(), error) {
+	return s.AcquireRequest(ctx, Request{Cost: cost}, status)
+}
+
+// AcquireRequest is like AcquireWithStatus, for a request of a given client and priority
+func (s *Scheduler) AcquireRequest(ctx context.Context, r Request, status func(Status)) (func(), error) {
+	s.mu.Lock()
+	if s.busy < s.slots && len(s.waiters) == 0 && s.drained == nil {
+		s.busy++
+		s.start(r.Client)
+		s.mu.Unlock()
+		return s.releaser(r.Client), nil
+	}
+
+	if s.maxQueue > 0 && len(s.waiters) >= s.maxQueue {
+		// the newest of the requests of the lowest priority makes room for a request of a higher priority
+		victim := 0
+		for i, w := range s.waiters {
+			if w.Priority <= s.waiters[victim].Priority {
+				victim = i
+			}
+		}
+		if s.waiters[victim].Priority >= r.Priority {
+			err := &QueueFullError{RetryAfter: s.retryAfter()}
+			s.mu.Unlock()
+			return nil, err
+		}
+		w := s.waiters[victim]
+		s.waiters = append(s.waiters[:victim], s.waiters[victim+1:]...)
+		w.dropped = true
+		close(w.ready)
+	}
+
+	s.seq++
+	w := &waiter{Request: r, seq: s.seq, enqueued: time.Now(), ready: make(chan struct{}), changed: make(chan struct{}, 1)}
+	s.waiters = append(s.waiters, w)
+	s.notify()
+	s.mu.Unlock()
+
+	last := Status{}
+	for {
+		select {
+		case <-w.ready:
+			if w.dropped {
+				s.mu.Lock()
+				defer s.mu.Unlock()
+				s.forget(r.Client)
+				return nil, &QueueFullError{RetryAfter: s.retryAfter()}
+			}
+			return s.releaser(r.Client), nil
+		case <-w.changed:
+			if status == nil {
+				continue
+			}
+			s.mu.Lock()
+			st, queued := s.status(w)
+			s.mu.Unlock()
+			if queued && st.Position != last.Position {
+				last = st
+				status(st)
+			}
+		case <-ctx.Done():
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			for i, ww := range s.waiters {
+				if ww == w {
+					s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+					s.forget(r.Client)
+					s.notify()
+					return nil, ctx.Err()
+				}
+			}
+			if !w.dropped {
+				// the slot was granted in the meantime: give it to the next request
+				s.release(r.Client)
+			}
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Queued returns the number of requests waiting for a slot
+func (s *Scheduler) Queued() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.waiters)
+}
+
+// Busy returns the number of requests being processed
+func (s *Scheduler) Busy() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.busy
+}
+
+// Slots returns the number of requests processed at once
+func (s *Scheduler) Slots() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.slots
+}
+
+// Resize changes the number of slots. The queued requests wait until the requests being processed are done, then
+// apply is called (e.g. to reconfigure the backend, if not nil), and the slots are resized if it succeeds.
+func (s *Scheduler) Resize(ctx context.Context, slots int, apply func() error) error {
+	if slots < 1 {
+		slots = 1
+	}
+
+	s.mu.Lock()
+	if s.drained != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("the slots are already being resized")
+	}
+	drained := make(chan struct{})
+	s.drained = drained
+	if s.busy == 0 {
+		close(drained)
+	}
+	s.mu.Unlock()
+
+	var err error
+	select {
+	case <-drained:
+		if apply != nil {
+			err = apply()
+		}
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drained = nil
+	if err == nil {
+		s.slots = slots
+	}
+	s.dispatch()
+	s.notify()
+	return err
+}
+
+// retryAfter estimates the time before the queue has room again, it must be called with the lock held
+func (s *Scheduler) retryAfter() time.Duration {
+	d := s.duration / time.Duration(s.slots)
+	if d < time.Second {
+		d = time.Second
+	}
+	return d
+}
+
+// releaser returns the function releasing a slot of the client, which records how long the slot was held
+func (s *Scheduler) releaser(client string) func() {
+	start := time.Now()
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		d := time.Since(start)
+		if s.duration == 0 {
+			s.duration = d
+		} else {
+			s.duration += time.Duration(durationSmoothing * float64(d-s.duration))
+		}
+		s.release(client)
+	}
+}
+
+// release frees a slot of the client, it must be called with the lock held
+func (s *Scheduler) release(client string) {
+	s.busy--
+	s.done(client)
+	if s.drained != nil {
+		if s.busy == 0 {
+			close(s.drained)
+		}
+		return
+	}
+	s.dispatch()
+}
+
+// start records that a request of the client is processed, it must be called with the lock held
+func (s *Scheduler) start(name string) {
+	s.dispatches++
+	c := s.clients[name]
+	c.busy++
+	c.served = s.dispatches
+	s.clients[name] = c
+}
+
+// done records that a request of the client is not processed anymore, it must be called with the lock held
+func (s *Scheduler) done(name string) {
+	c := s.clients[name]
+	c.busy--
+	s.clients[name] = c
+	s.forget(name)
+}
+
+// forget removes the client once it has no request left, waiting or processed, it must be called with the lock held
+func (s *Scheduler) forget(name string) {
+	if c, ok := s.clients[name]; !ok || c.busy > 0 {
+		return
+	}
+	for _, w := range s.waiters {
+		if w.Client == name {
+			return
+		}
+	}
+	delete(s.clients, name)
+}
+
+// dispatch hands the free slots to the waiters, it must be called with the lock held
+func (s *Scheduler) dispatch() {
+	dispatched := false
+	for s.busy < s.slots && len(s.waiters) > 0 && s.drained == nil {
+		i := s.next(s.waiters, s.clients)
+		w := s.waiters[i]
+		s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+		s.busy++
+		s.start(w.Client)
+		close(w.ready)
+		dispatched = true
+	}
+	if dispatched {
+		s.notify()
+	}
+}
+
+// notify signals the waiters that their position may have changed, it must be called with the lock held
+func (s *Scheduler) notify() {
+	for _, w := range s.waiters {
+		select {
+		case w.changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// status returns the position of a waiter in the order it will be served, by serving the waiters with the
+// requests being processed as they are, it must be called with the lock held
+func (s *Scheduler) status(w *waiter) (Status, bool) {
+	clients := map[string]client{}
+	for name, c := range s.clients {
+		clients[name] = c
+	}
+	dispatches := s.dispatches
+	waiters := append([]*waiter{}, s.waiters...)
+	position := 1
+	for ; len(waiters) > 0; position++ {
+		i := s.next(waiters, clients)
+		if waiters[i] == w {
+			break
+		}
+		dispatches++
+		c := clients[waiters[i].Client]
+		c.busy++
+		c.served = dispatches
+		clients[waiters[i].Client] = c
+		waiters = append(waiters[:i], waiters[i+1:]...)
+	}
+	if len(waiters) == 0 {
+		return Status{}, false
+	}
+
+	// the requests are served by groups of slots
+	rounds := (position + s.slots - 1) / s.slots
+	return Status{Position: position, ETA: time.Duration(rounds) * s.duration}, true
+}
+
+// next returns the index of the waiter to serve among the waiters, kept in their order of arrival
+func (s *Scheduler) next(waiters []*waiter, clients map[string]client) int {
+	// the requests waiting for too long are served first, whatever their priority
+	if time.Since(waiters[0].enqueued) > s.maxWait {
+		return 0
+	}
+
+	best := 0
+	for i, w := range waiters {
+		b := waiters[best]
+		switch {
+		case w.Priority != b.Priority:
+			if w.Priority > b.Priority {
+				best = i
+			}
+		case s.policy == SJF:
+			if w.Cost < b.Cost {
+				best = i
+			}
+		case s.policy == Fair:
+			// the client with the fewest requests being processed, and then the one served the longest time ago
+			c, bc := clients[w.Client], clients[b.Client]
+			if c.busy < bc.busy || (c.busy == bc.busy && c.served < bc.served) {
+				best = i
+			}
+		}
+	}
+	return best
+}
@@ -0,0 +1,272 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/go-skynet/LocalAI/pkg/scheduler"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// queue enqueues the requests with the given costs one after the other, while the only slot is taken,
+// and returns the order in which they are served
+func queue(s *Scheduler, costs ...int) []int {
+	release, err := s.Acquire(context.Background(), 0)
+	Expect(err).ToNot(HaveOccurred())
+
+	mu := sync.Mutex{}
+	order := []int{}
+	wg := sync.WaitGroup{}
+	for i, cost := range costs {
+		wg.Add(1)
+		go func(cost int) {
+			defer wg.Done()
+			r, err := s.Acquire(context.Background(), cost)
+			Expect(err).ToNot(HaveOccurred())
+			mu.Lock()
+			order = append(order, cost)
+			mu.Unlock()
+			r()
+		}(cost)
+		Eventually(s.Queued).Should(Equal(i + 1))
+	}
+
+	release()
+	wg.Wait()
+	return order
+}
+
+var _ = Describe("Scheduler", func() {
+	It("serves the requests in order of arrival with FIFO", func() {
+		s, err := New(1, FIFO, 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(queue(s, 30, 10, 20)).To(Equal([]int{30, 10, 20}))
+	})
+
+	It("serves the shortest requests first with SJF", func() {
+		s, err := New(1, SJF, 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(queue(s, 30, 10, 20)).To(Equal([]int{10, 20, 30}))
+	})
+
+	It("does not let long requests starve", func() {
+		s, err := New(1, SJF, time.Nanosecond)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(queue(s, 30, 10, 20)).To(Equal([]int{30, 10, 20}))
+	})
+
+	It("serves the clients in turn with Fair", func() {
+		s, err := New(1, Fair, 0)
+		Expect(err).ToNot(HaveOccurred())
+		release, err := s.AcquireRequest(context.Background(), Request{Client: "a"}, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		mu := sync.Mutex{}
+		order := []string{}
+		wg := sync.WaitGroup{}
+		for i, r := range []string{"a1", "a2", "a3", "b1", "b2"} {
+			wg.Add(1)
+			go func(r string) {
+				defer wg.Done()
+				release, err := s.AcquireRequest(context.Background(), Request{Client: r[:1]}, nil)
+				Expect(err).ToNot(HaveOccurred())
+				mu.Lock()
+				order = append(order, r)
+				mu.Unlock()
+				release()
+			}(r)
+			Eventually(s.Queued).Should(Equal(i + 1))
+		}
+
+		release()
+		wg.Wait()
+		Expect(order).To(Equal([]string{"b1", "a1", "b2", "a2", "a3"}))
+	})
+
+	It("serves the requests of higher priority first", func() {
+		s, err := New(1, SJF, 0)
+		Expect(err).ToNot(HaveOccurred())
+		release, err := s.Acquire(context.Background(), 0)
+		Expect(err).ToNot(HaveOccurred())
+
+		mu := sync.Mutex{}
+		order := []int{}
+		wg := sync.WaitGroup{}
+		for i, r := range []Request{{Cost: 10}, {Cost: 30, Priority: 1}, {Cost: 20, Priority: 1}, {Cost: 5, Priority: -1}} {
+			wg.Add(1)
+			go func(r Request) {
+				defer wg.Done()
+				release, err := s.AcquireRequest(context.Background(), r, nil)
+				Expect(err).ToNot(HaveOccurred())
+				mu.Lock()
+				order = append(order, r.Cost)
+				mu.Unlock()
+				release()
+			}(r)
+			Eventually(s.Queued).Should(Equal(i + 1))
+		}
+
+		release()
+		wg.Wait()
+		Expect(order).To(Equal([]int{20, 30, 10, 5}))
+	})
+
+	It("rejects the requests beyond the queue depth", func() {
+		s, err := New(1, FIFO, 0)
+		Expect(err).ToNot(HaveOccurred())
+		s.SetMaxQueue(1)
+		release, err := s.Acquire(context.Background(), 0)
+		Expect(err).ToNot(HaveOccurred())
+
+		low := make(chan error, 1)
+		go func() {
+			r, err := s.AcquireRequest(context.Background(), Request{Priority: -1}, nil)
+			if err == nil {
+				r()
+			}
+			low <- err
+		}()
+		Eventually(s.Queued).Should(Equal(1))
+
+		_, err = s.AcquireRequest(context.Background(), Request{Priority: -1}, nil)
+		var full *QueueFullError
+		Expect(errors.As(err, &full)).To(BeTrue())
+		Expect(full.RetryAfter).To(BeNumerically(">=", time.Second))
+
+		// a request of a higher priority takes the place of the queued one
+		high := make(chan error, 1)
+		go func() {
+			r, err := s.AcquireRequest(context.Background(), Request{Priority: 1}, nil)
+			if err == nil {
+				r()
+			}
+			high <- err
+		}()
+		Eventually(low).Should(Receive(BeAssignableToTypeOf(&QueueFullError{})))
+		Expect(s.Queued()).To(Equal(1))
+
+		release()
+		Eventually(high).Should(Receive(BeNil()))
+	})
+
+	It("admits as many requests as slots", func() {
+		s, err := New(2, SJF, 0)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = s.Acquire(context.Background(), 1)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = s.Acquire(context.Background(), 1)
+		Expect(err).ToNot(HaveOccurred())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = s.Acquire(ctx, 1)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		Expect(s.Queued()).To(Equal(0))
+	})
+
+	It("reports the position of the queued requests", func() {
+		s, err := New(1, FIFO, 0)
+		Expect(err).ToNot(HaveOccurred())
+		release, err := s.Acquire(context.Background(), 0)
+		Expect(err).ToNot(HaveOccurred())
+		time.Sleep(10 * time.Millisecond)
+		release()
+		release, err = s.Acquire(context.Background(), 0)
+		Expect(err).ToNot(HaveOccurred())
+
+		hold := make(chan struct{})
+		go func() {
+			r, err := s.Acquire(context.Background(), 0)
+			Expect(err).ToNot(HaveOccurred())
+			<-hold
+			r()
+		}()
+		Eventually(s.Queued).Should(Equal(1))
+
+		statuses := make(chan Status, 10)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			r, err := s.AcquireWithStatus(context.Background(), 0, func(st Status) { statuses <- st })
+			Expect(err).ToNot(HaveOccurred())
+			r()
+		}()
+
+		var st Status
+		Eventually(statuses).Should(Receive(&st))
+		Expect(st.Position).To(Equal(2))
+		Expect(st.ETA).To(BeNumerically(">=", 20*time.Millisecond))
+
+		release()
+		Eventually(statuses).Should(Receive(&st))
+		Expect(st.Position).To(Equal(1))
+		close(hold)
+		Eventually(done).Should(BeClosed())
+	})
+
+	It("resizes the slots once the requests being processed are done", func() {
+		s, err := New(1, FIFO, 0)
+		Expect(err).ToNot(HaveOccurred())
+		release, err := s.Acquire(context.Background(), 0)
+		Expect(err).ToNot(HaveOccurred())
+
+		applied := make(chan struct{})
+		resized := make(chan error, 1)
+		go func() {
+			resized <- s.Resize(context.Background(), 2, func() error {
+				close(applied)
+				return nil
+			})
+		}()
+		Consistently(applied, 20*time.Millisecond).ShouldNot(BeClosed())
+
+		// the requests wait for the resize
+		acquired := make(chan struct{}, 2)
+		for i := 0; i < 2; i++ {
+			go func() {
+				_, err := s.Acquire(context.Background(), 0)
+				Expect(err).ToNot(HaveOccurred())
+				acquired <- struct{}{}
+			}()
+		}
+		Eventually(s.Queued).Should(Equal(2))
+
+		release()
+		Eventually(resized).Should(Receive(BeNil()))
+		Expect(s.Slots()).To(Equal(2))
+		Eventually(acquired).Should(HaveLen(2))
+		Expect(s.Busy()).To(Equal(2))
+	})
+
+	It("keeps the slots when the resize fails", func() {
+		s, err := New(2, FIFO, 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.Resize(context.Background(), 4, func() error { return errors.New("busy") })).ToNot(Succeed())
+		Expect(s.Slots()).To(Equal(2))
+		Expect(s.Resize(context.Background(), 1, nil)).To(Succeed())
+		Expect(s.Slots()).To(Equal(1))
+	})
+
+	It("rejects unknown policies", func() {
+		_, err := New(1, "lifo", 0)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Predictor", func() {
+	It("predicts from the outputs of the prompts of similar length", func() {
+		p := NewPredictor()
+		Expect(p.Predict(100, 0)).To(Equal(128))
+		Expect(p.Predict(100, 64)).To(Equal(64))
+
+		p.Observe(100, 10)
+		p.Observe(5000, 500)
+		Expect(p.Predict(110, 0)).To(Equal(10))
+		Expect(p.Predict(4500, 0)).To(Equal(500))
+		// unknown lengths fall back to the average of all the outputs
+		Expect(p.Predict(1, 0)).To(Equal(108))
+	})
+})
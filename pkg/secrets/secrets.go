@@ -0,0 +1,153 @@
+// Package secrets holds the credentials of the sources the models are downloaded from (HuggingFace, OCI registries,
+// S3 buckets), which the model configs reference by name instead of embedding the tokens. The values are given in
+// the secrets file, or read from environment variables or files with env:<NAME> and file:<path>.
+package secrets
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Secret is the credentials of a source of models
+type Secret struct {
+	// Token is sent as a bearer token to HuggingFace and the HTTP(S) sources, and to the OCI registries. It is the
+	// access token of Google Cloud Storage.
+	Token string `yaml:"token"`
+	// Username and Password authenticate to the OCI registries, and to the HTTP(S) sources with the basic
+	// authentication
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// the keys of S3, or of a S3 compatible storage with its endpoint
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+	SessionToken    string `yaml:"session_token"`
+	Region          string `yaml:"region"`
+	Endpoint        string `yaml:"endpoint"`
+	// URLs are the hosts (e.g. huggingface.co) or the prefixes of the URLs (e.g. https://huggingface.co/my-org/,
+	// oci://registry.example.com/, s3://bucket/) the secret is sent to. The configs and the galleries can't use the
+	// secret to download other URLs.
+	URLs []string `yaml:"urls"`
+}
+
+// resolve replaces the values read from the environment variables and the files
+func (s *Secret) resolve() error {
+	v := reflect.ValueOf(s).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if f.Kind() != reflect.String {
+			continue
+		}
+		field := v.Type().Field(i).Tag.Get("yaml")
+		switch ref := f.String(); {
+		case strings.HasPrefix(ref, "env:"):
+			name := strings.TrimPrefix(ref, "env:")
+			val, ok := os.LookupEnv(name)
+			if !ok {
+				return fmt.Errorf("the environment variable %s of %s is not set", name, field)
+			}
+			f.SetString(val)
+		case strings.HasPrefix(ref, "file:"):
+			dat, err := os.ReadFile(strings.TrimPrefix(ref, "file:"))
+			if err != nil {
+				return fmt.Errorf("cannot read %s: %w", field, err)
+			}
+			f.SetString(strings.TrimSpace(string(dat)))
+		}
+	}
+	return nil
+}
+
+// Authorization returns the Authorization header of the HTTP(S) requests, empty without token nor password
+func (s Secret) Authorization() string {
+	switch {
+	case s.Token != "":
+		return "Bearer " + s.Token
+	case s.Username != "" || s.Password != "":
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(s.Username+":"+s.Password))
+	}
+	return ""
+}
+
+// allows returns true if the secret can be sent to the URL
+func (s Secret) allows(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range s.URLs {
+		if !strings.Contains(allowed, "://") {
+			if u.Host != "" && strings.EqualFold(u.Hostname(), allowed) {
+				return true
+			}
+			continue
+		}
+		// the prefix ends at a path separator, so https://host doesn't allow https://host.example.com
+		rest, ok := strings.CutPrefix(uri, allowed)
+		if ok && (rest == "" || strings.HasSuffix(allowed, "/") || strings.ContainsAny(rest[:1], "/?#")) {
+			return true
+		}
+	}
+	return false
+}
+
+// Store holds the secrets by name
+type Store struct {
+	secrets map[string]Secret
+}
+
+// Load reads the secrets of a YAML file, by name:
+//
+//	hf-private:
+//	  token: env:HF_PRIVATE_TOKEN
+//	registry:
+//	  username: localai
+//	  password: file:/run/secrets/registry
+func Load(file string) (*Store, error) {
+	dat, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read secrets file: %w", err)
+	}
+	secrets := map[string]Secret{}
+	if err := yaml.Unmarshal(dat, &secrets); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal secrets file: %w", err)
+	}
+	for name, s := range secrets {
+		if err := s.resolve(); err != nil {
+			return nil, fmt.Errorf("invalid secret %s of %s: %w", name, file, err)
+		}
+		secrets[name] = s
+	}
+	return &Store{secrets: secrets}, nil
+}
+
+// Get returns the secret of the name
+func (s *Store) Get(name string) (Secret, error) {
+	if s != nil {
+		if secret, ok := s.secrets[name]; ok {
+			return secret, nil
+		}
+	}
+	return Secret{}, fmt.Errorf("unknown secret %q", name)
+}
+
+// ForURL returns the secret of the name to download the URL, refusing the URLs which don't match the ones of the
+// secret: the configs and the galleries name the secrets, they can't send them elsewhere
+func (s *Store) ForURL(name, uri string) (Secret, error) {
+	secret, err := s.Get(name)
+	if err != nil {
+		return Secret{}, err
+	}
+	if len(secret.URLs) == 0 {
+		return Secret{}, fmt.Errorf("secret %q has no urls it can be sent to", name)
+	}
+	if !secret.allows(uri) {
+		return Secret{}, fmt.Errorf("secret %q can't be sent to %s", name, uri)
+	}
+	return secret, nil
+}
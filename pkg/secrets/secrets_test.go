@@ -0,0 +1,109 @@
+package secrets_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/go-skynet/LocalAI/pkg/secrets"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Secrets", func() {
+	var dir string
+
+	write := func(content string) string {
+		file := filepath.Join(dir, "secrets.yaml")
+		Expect(os.WriteFile(file, []byte(content), 0600)).To(Succeed())
+		return file
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	It("reads the values of the file, the environment and the files", func() {
+		GinkgoT().Setenv("TEST_HF_TOKEN", "hf_secret")
+		Expect(os.WriteFile(filepath.Join(dir, "password"), []byte("p4ssw0rd\n"), 0600)).To(Succeed())
+		s, err := secrets.Load(write(`
+hf-private:
+  token: env:TEST_HF_TOKEN
+registry:
+  username: localai
+  password: file:` + filepath.Join(dir, "password") + `
+bucket:
+  access_key_id: access
+  secret_access_key: key
+  region: eu-west-1
+`))
+		Expect(err).ToNot(HaveOccurred())
+
+		hf, err := s.Get("hf-private")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hf.Token).To(Equal("hf_secret"))
+		Expect(hf.Authorization()).To(Equal("Bearer hf_secret"))
+
+		registry, err := s.Get("registry")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(registry.Password).To(Equal("p4ssw0rd"))
+		Expect(registry.Authorization()).To(Equal("Basic bG9jYWxhaTpwNHNzdzByZA=="))
+
+		bucket, err := s.Get("bucket")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bucket.SecretAccessKey).To(Equal("key"))
+		Expect(bucket.Authorization()).To(BeEmpty())
+
+		_, err = s.Get("unknown")
+		Expect(err).To(MatchError(ContainSubstring("unknown secret")))
+	})
+
+	It("refuses the missing environment variables", func() {
+		_, err := secrets.Load(write("hf:\n  token: env:TEST_UNSET_TOKEN\n"))
+		Expect(err).To(MatchError(ContainSubstring("TEST_UNSET_TOKEN")))
+	})
+
+	It("sends the secrets only to their URLs", func() {
+		s, err := secrets.Load(write(`
+hf-private:
+  token: hf_secret
+  urls: ["https://huggingface.co/my-org/", "oci://registry.example.com"]
+bucket:
+  access_key_id: access
+  urls: [storage.example.com]
+unbound:
+  token: secret
+`))
+		Expect(err).ToNot(HaveOccurred())
+
+		hf, err := s.ForURL("hf-private", "https://huggingface.co/my-org/model/resolve/main/model.gguf")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hf.Token).To(Equal("hf_secret"))
+		_, err = s.ForURL("hf-private", "oci://registry.example.com/models/phi:latest")
+		Expect(err).ToNot(HaveOccurred())
+		_, err = s.ForURL("bucket", "https://STORAGE.example.com/models/model.gguf")
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, uri := range []string{
+			"https://huggingface.co/other-org/model/resolve/main/model.gguf",
+			"https://attacker.example.com/https://huggingface.co/my-org/",
+			"oci://registry.example.com.attacker.example.com/models/phi:latest",
+			"http://huggingface.co/my-org/model",
+		} {
+			_, err = s.ForURL("hf-private", uri)
+			Expect(err).To(MatchError(ContainSubstring("can't be sent to")), uri)
+		}
+		_, err = s.ForURL("bucket", "https://storage.example.com.attacker.example.com/model.gguf")
+		Expect(err).To(HaveOccurred())
+
+		_, err = s.ForURL("unbound", "https://huggingface.co/my-org/model")
+		Expect(err).To(MatchError(ContainSubstring("has no urls")))
+		_, err = s.Get("unbound")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("finds no secret without store", func() {
+		var s *secrets.Store
+		_, err := s.Get("hf")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,271 @@
+// Package ssml reads the Speech Synthesis Markup Language documents given to the TTS endpoints, for the backends which
+// don't understand it: the text is split at the breaks, the say-as and sub tags are spoken as text, and the volume of
+// the prosody is kept as a gain. The other tags (e.g. the rate and the pitch of the prosody) are stripped.
+package ssml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Part is a text to speak with a gain, or a break when the text is empty
+type Part struct {
+	Text string
+	// Gain is the factor of the amplitude of the speech, 1 by default
+	Gain float64
+	// Break is the duration of the silence of a break
+	Break time.Duration
+	// implicit breaks are the pauses after the paragraphs and the sentences
+	implicit bool
+}
+
+// IsSSML returns true if the text is a SSML document, starting with a speak tag
+func IsSSML(text string) bool {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "<?xml") {
+		if end := strings.Index(text, "?>"); end >= 0 {
+			text = strings.TrimSpace(text[end+2:])
+		}
+	}
+	return strings.HasPrefix(text, "<speak")
+}
+
+// the durations of the strengths of the breaks
+var breakStrengths = map[string]time.Duration{
+	"none":     0,
+	"x-weak":   100 * time.Millisecond,
+	"weak":     250 * time.Millisecond,
+	"medium":   500 * time.Millisecond,
+	"strong":   750 * time.Millisecond,
+	"x-strong": time.Second,
+}
+
+// the gains of the volumes of the prosody, in dB
+var volumes = map[string]float64{
+	"silent":  math.Inf(-1),
+	"x-soft":  -12,
+	"soft":    -6,
+	"medium":  0,
+	"default": 0,
+	"loud":    6,
+	"x-loud":  12,
+}
+
+// Parse returns the parts of the document. The consecutive texts of the same gain are joined.
+func Parse(document string) ([]Part, error) {
+	d := xml.NewDecoder(strings.NewReader(document))
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+
+	p := &parser{gains: []float64{1}}
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid SSML: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if err := p.start(t); err != nil {
+				return nil, err
+			}
+		case xml.EndElement:
+			p.end(t)
+		case xml.CharData:
+			p.text(string(t))
+		}
+	}
+
+	parts := []Part{}
+	for _, part := range p.parts {
+		part.Text = strings.Join(strings.Fields(part.Text), " ")
+		if part.Text == "" && part.Break == 0 {
+			continue
+		}
+		parts = append(parts, part)
+	}
+	for len(parts) > 0 && parts[len(parts)-1].implicit {
+		parts = parts[:len(parts)-1]
+	}
+	return parts, nil
+}
+
+// Text returns the text of the document, spoken without the breaks and the gains
+func Text(document string) (string, error) {
+	parts, err := Parse(document)
+	if err != nil {
+		return "", err
+	}
+	texts := []string{}
+	for _, p := range parts {
+		if p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, " "), nil
+}
+
+type parser struct {
+	parts []Part
+	gains []float64
+	// the element whose text is replaced (say-as, sub), and its text
+	replaced *xml.StartElement
+	content  strings.Builder
+}
+
+func attr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func (p *parser) gain() float64 {
+	return p.gains[len(p.gains)-1]
+}
+
+func (p *parser) start(e xml.StartElement) error {
+	switch e.Name.Local {
+	case "break":
+		d, err := breakDuration(e)
+		if err != nil {
+			return err
+		}
+		p.parts = append(p.parts, Part{Break: d})
+	case "prosody":
+		g, err := gain(attr(e, "volume"))
+		if err != nil {
+			return err
+		}
+		p.gains = append(p.gains, p.gain()*g)
+	case "say-as", "sub":
+		p.replaced = &e
+		p.content.Reset()
+	}
+	return nil
+}
+
+func (p *parser) end(e xml.EndElement) {
+	switch e.Name.Local {
+	case "prosody":
+		if len(p.gains) > 1 {
+			p.gains = p.gains[:len(p.gains)-1]
+		}
+	case "p", "s":
+		// the paragraphs and the sentences are followed by a pause, unless the document ends
+		d := breakStrengths["weak"]
+		if e.Name.Local == "p" {
+			d = breakStrengths["strong"]
+		}
+		p.parts = append(p.parts, Part{Break: d, implicit: true})
+	case "say-as", "sub":
+		if p.replaced == nil {
+			return
+		}
+		r := p.replaced
+		p.replaced = nil
+		if r.Name.Local == "sub" {
+			p.text(attr(*r, "alias"))
+		} else {
+			p.text(sayAs(attr(*r, "interpret-as"), p.content.String()))
+		}
+	}
+}
+
+func (p *parser) text(t string) {
+	if p.replaced != nil {
+		p.content.WriteString(t)
+		return
+	}
+	if n := len(p.parts); n > 0 && p.parts[n-1].Break == 0 && p.parts[n-1].Gain == p.gain() {
+		p.parts[n-1].Text += t
+		return
+	}
+	p.parts = append(p.parts, Part{Text: t, Gain: p.gain()})
+}
+
+// breakDuration returns the duration of a break, given by its time or by its strength, medium by default
+func breakDuration(e xml.StartElement) (time.Duration, error) {
+	if t := attr(e, "time"); t != "" {
+		d, err := time.ParseDuration(t)
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("invalid time of break: %s", t)
+		}
+		return d, nil
+	}
+	strength := attr(e, "strength")
+	if strength == "" {
+		strength = "medium"
+	}
+	d, ok := breakStrengths[strength]
+	if !ok {
+		return 0, fmt.Errorf("invalid strength of break: %s", strength)
+	}
+	return d, nil
+}
+
+// gain returns the factor of the amplitude of a volume, given by name or in dB (e.g. +6dB)
+func gain(volume string) (float64, error) {
+	if volume == "" {
+		return 1, nil
+	}
+	db, ok := volumes[volume]
+	if !ok {
+		v, err := strconv.ParseFloat(strings.TrimSuffix(volume, "dB"), 64)
+		if err != nil || !strings.HasSuffix(volume, "dB") {
+			return 0, fmt.Errorf("invalid volume of prosody: %s", volume)
+		}
+		db = v
+	}
+	return math.Pow(10, db/20), nil
+}
+
+// sayAs returns the text to speak of the content of a say-as element
+func sayAs(interpretAs, content string) string {
+	content = strings.TrimSpace(content)
+	switch interpretAs {
+	case "characters", "spell-out", "letters", "verbatim":
+		chars := []string{}
+		for _, r := range content {
+			if !unicode.IsSpace(r) {
+				chars = append(chars, string(r))
+			}
+		}
+		return strings.Join(chars, " ")
+	case "digits":
+		digits := []string{}
+		for _, r := range content {
+			if unicode.IsDigit(r) {
+				digits = append(digits, string(r))
+			}
+		}
+		return strings.Join(digits, " ")
+	case "telephone":
+		// the digits are spoken one by one, the groups separated by a pause
+		groups := strings.FieldsFunc(content, func(r rune) bool { return !unicode.IsDigit(r) && r != '+' })
+		for i, g := range groups {
+			spoken := []string{}
+			for _, r := range g {
+				if r == '+' {
+					spoken = append(spoken, "plus")
+				} else {
+					spoken = append(spoken, string(r))
+				}
+			}
+			groups[i] = strings.Join(spoken, " ")
+		}
+		return strings.Join(groups, ", ")
+	}
+	return content
+}
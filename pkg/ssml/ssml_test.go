@@ -0,0 +1,59 @@
+package ssml_test
+
+import (
+	"time"
+
+	. "github.com/go-skynet/LocalAI/pkg/ssml"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SSML", func() {
+	It("detects the documents", func() {
+		Expect(IsSSML(`<speak>Hello</speak>`)).To(BeTrue())
+		Expect(IsSSML(` <?xml version="1.0"?><speak version="1.1">Hello</speak>`)).To(BeTrue())
+		Expect(IsSSML(`Hello <speak>`)).To(BeFalse())
+	})
+
+	It("splits the text at the breaks", func() {
+		parts, err := Parse(`<speak>Welcome to Acme. <break time="1500ms"/> For sales,
+			press one.<break strength="weak"/>For support, press two.<break/></speak>`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parts).To(Equal([]Part{
+			{Text: "Welcome to Acme.", Gain: 1},
+			{Break: 1500 * time.Millisecond},
+			{Text: "For sales, press one.", Gain: 1},
+			{Break: 250 * time.Millisecond},
+			{Text: "For support, press two.", Gain: 1},
+			{Break: 500 * time.Millisecond},
+		}))
+	})
+
+	It("speaks the say-as and the sub as text", func() {
+		text, err := Text(`<speak>Your code is <say-as interpret-as="characters">AB 12</say-as>, call
+			<say-as interpret-as="telephone">+1-800-555</say-as> or <sub alias="World Wide Web Consortium">W3C</sub>,
+			extension <say-as interpret-as="digits">42</say-as> for <say-as interpret-as="cardinal">42</say-as> days.</speak>`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(text).To(Equal("Your code is A B 1 2, call plus 1, 8 0 0, 5 5 5 or World Wide Web Consortium, extension 4 2 for 42 days."))
+	})
+
+	It("keeps the volume of the prosody as a gain, and strips the other tags", func() {
+		parts, err := Parse(`<speak><p>Please <emphasis>listen</emphasis>
+			<prosody rate="slow" volume="+6dB">carefully</prosody></p><p><prosody volume="silent">hidden</prosody></p></speak>`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parts).To(HaveLen(4))
+		Expect(parts[0]).To(Equal(Part{Text: "Please listen", Gain: 1}))
+		Expect(parts[1].Text).To(Equal("carefully"))
+		Expect(parts[1].Gain).To(BeNumerically("~", 1.995, 0.001))
+		Expect(parts[2].Text).To(BeEmpty())
+		Expect(parts[2].Break).To(Equal(750 * time.Millisecond))
+		Expect(parts[3]).To(Equal(Part{Text: "hidden", Gain: 0}))
+	})
+
+	It("rejects the invalid documents", func() {
+		_, err := Parse(`<speak>Wait <break time="soon"/></speak>`)
+		Expect(err).To(HaveOccurred())
+		_, err = Parse(`<speak><prosody volume="louder">Hello</prosody></speak>`)
+		Expect(err).To(HaveOccurred())
+	})
+})
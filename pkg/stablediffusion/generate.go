@@ -4,11 +4,17 @@
 package stablediffusion
 
 import (
+	"fmt"
+
 	stableDiffusion "github.com/mudler/go-stable-diffusion"
 )
 
-func GenerateImage(height, width, mode, step, seed int, positive_prompt, negative_prompt, dst, asset_dir string) error {
+func GenerateImage(height, width, mode, step, seed int, positive_prompt, negative_prompt, src, dst, asset_dir string) error {
 	if height > 512 || width > 512 {
+		// the upscaled images have no init image
+		if src != "" {
+			return fmt.Errorf("the images generated from an image are at most 512x512")
+		}
 		return stableDiffusion.GenerateImageUpscaled(
 			height,
 			width,
@@ -29,7 +35,7 @@ func GenerateImage(height, width, mode, step, seed int, positive_prompt, negativ
 		positive_prompt,
 		negative_prompt,
 		dst,
-		"",
+		src,
 		asset_dir,
 	)
 }
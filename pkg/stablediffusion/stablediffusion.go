@@ -15,6 +15,7 @@ func New(assetDir string) (*StableDiffusion, error) {
 	}, nil
 }
 
-func (s *StableDiffusion) GenerateImage(height, width, mode, step, seed int, positive_prompt, negative_prompt, dst string) error {
-	return GenerateImage(height, width, mode, step, seed, positive_prompt, negative_prompt, dst, s.assetDir)
+// GenerateImage generates an image of the prompts, from the image of src if not empty
+func (s *StableDiffusion) GenerateImage(height, width, mode, step, seed int, positive_prompt, negative_prompt, src, dst string) error {
+	return GenerateImage(height, width, mode, step, seed, positive_prompt, negative_prompt, src, dst, s.assetDir)
 }
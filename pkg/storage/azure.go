@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const azureVersion = "2021-08-06"
+
+// azure is a container of Azure Blob Storage, in the account AZURE_STORAGE_ACCOUNT. The requests are authorized
+// with the SAS token AZURE_STORAGE_SAS_TOKEN, or with the access key AZURE_STORAGE_KEY, and are anonymous otherwise.
+type azure struct {
+	container, prefix string
+	account           string
+	// endpoint of the account, AZURE_STORAGE_ENDPOINT for the emulators (e.g. http://127.0.0.1:10000/devstoreaccount1)
+	endpoint string
+	sas      url.Values
+	key      []byte
+}
+
+func newAzure(container, prefix string) (*azure, error) {
+	a := &azure{
+		container: container,
+		prefix:    prefix,
+		account:   os.Getenv("AZURE_STORAGE_ACCOUNT"),
+		endpoint:  strings.TrimSuffix(os.Getenv("AZURE_STORAGE_ENDPOINT"), "/"),
+	}
+	if a.account == "" {
+		return nil, fmt.Errorf("AZURE_STORAGE_ACCOUNT is required for the az:// storages")
+	}
+	if a.endpoint == "" {
+		a.endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", a.account)
+	}
+	if sas := os.Getenv("AZURE_STORAGE_SAS_TOKEN"); sas != "" {
+		v, err := url.ParseQuery(strings.TrimPrefix(sas, "?"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid AZURE_STORAGE_SAS_TOKEN: %w", err)
+		}
+		a.sas = v
+	} else if key := os.Getenv("AZURE_STORAGE_KEY"); key != "" {
+		k, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AZURE_STORAGE_KEY: %w", err)
+		}
+		a.key = k
+	}
+	return a, nil
+}
+
+func (a *azure) String() string {
+	return "az://" + a.container + "/" + a.prefix
+}
+
+type azureList struct {
+	Blobs struct {
+		Blob []struct {
+			Name       string `xml:"Name"`
+			Properties struct {
+				ContentLength int64  `xml:"Content-Length"`
+				LastModified  string `xml:"Last-Modified"`
+			} `xml:"Properties"`
+		} `xml:"Blob"`
+	} `xml:"Blobs"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+func (a *azure) List(ctx context.Context) ([]Object, error) {
+	res := []Object{}
+	marker := ""
+	for {
+		query := url.Values{"restype": {"container"}, "comp": {"list"}, "prefix": {a.prefix}}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		resp, err := a.get(ctx, a.container, query)
+		if err != nil {
+			return nil, err
+		}
+		list := azureList{}
+		err = xml.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed reading the blobs of %s: %w", a, err)
+		}
+
+		for _, b := range list.Blobs.Blob {
+			modTime, _ := http.ParseTime(b.Properties.LastModified)
+			res = append(res, Object{Name: strings.TrimPrefix(b.Name, a.prefix), Size: b.Properties.ContentLength, ModTime: modTime})
+		}
+		if list.NextMarker == "" {
+			return res, nil
+		}
+		marker = list.NextMarker
+	}
+}
+
+func (a *azure) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	resp, err := a.get(ctx, a.container+"/"+escapePath(a.prefix+name), nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (a *azure) get(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	for k, v := range a.sas {
+		q[k] = v
+	}
+	u, err := url.Parse(a.endpoint + "/" + path)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-ms-version", azureVersion)
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	if a.key != nil {
+		a.sign(req, query)
+	}
+	return do(req)
+}
+
+// sign authorizes the request with the Shared Key of the account
+func (a *azure) sign(req *http.Request, query url.Values) {
+	headers := []string{}
+	for k := range req.Header {
+		if k := strings.ToLower(k); strings.HasPrefix(k, "x-ms-") {
+			headers = append(headers, k)
+		}
+	}
+	sort.Strings(headers)
+	canonicalHeaders := ""
+	for _, h := range headers {
+		canonicalHeaders += h + ":" + strings.TrimSpace(req.Header.Get(h)) + "\n"
+	}
+
+	resource := "/" + a.account + req.URL.EscapedPath()
+	keys := []string{}
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		values := append([]string{}, query[k]...)
+		sort.Strings(values)
+		resource += "\n" + strings.ToLower(k) + ":" + strings.Join(values, ",")
+	}
+
+	// verb, the standard headers (none are sent), then the x-ms- headers and the resource
+	stringToSign := req.Method + strings.Repeat("\n", 12) + canonicalHeaders + resource
+
+	h := hmac.New(sha256.New, a.key)
+	h.Write([]byte(stringToSign))
+	req.Header.Set("Authorization", "SharedKey "+a.account+":"+base64.StdEncoding.EncodeToString(h.Sum(nil)))
+}
@@ -0,0 +1,276 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-skynet/LocalAI/pkg/utils"
+	"github.com/rs/zerolog/log"
+)
+
+// configExtensions are the files synced with the storage when the cache starts, as they are read before the models are used
+var configExtensions = []string{".yaml", ".yml", ".tmpl", ".json"}
+
+func isConfig(name string) bool {
+	for _, ext := range configExtensions {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+type fetch struct {
+	done chan struct{}
+	err  error
+}
+
+// Cache keeps the models of a storage in a local directory (the models path). The models are fetched on first use,
+// and the least recently used ones are evicted when the cached models grow over the size of the cache.
+type Cache struct {
+	dir     string
+	storage Storage
+	size    int64
+
+	mu      sync.Mutex
+	objects map[string]Object
+	// used is when the models were last used, the cached models never used since the start are the oldest
+	used     map[string]time.Time
+	pins     map[string]int
+	fetching map[string]*fetch
+}
+
+// NewCache returns a cache of the storage in dir, limited to size bytes (0 means no limit)
+func NewCache(dir string, s Storage, size int64) *Cache {
+	return &Cache{
+		dir:      dir,
+		storage:  s,
+		size:     size,
+		objects:  map[string]Object{},
+		used:     map[string]time.Time{},
+		pins:     map[string]int{},
+		fetching: map[string]*fetch{},
+	}
+}
+
+// Sync lists the objects of the storage, and downloads the model configurations and templates
+// which are missing in the directory or have changed in the storage
+func (c *Cache) Sync(ctx context.Context) error {
+	objects, err := c.storage.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed listing the models of %s: %w", c.storage, err)
+	}
+
+	c.mu.Lock()
+	c.objects = map[string]Object{}
+	for _, o := range objects {
+		c.objects[o.Name] = o
+	}
+	c.mu.Unlock()
+
+	for _, o := range objects {
+		if !isConfig(o.Name) {
+			continue
+		}
+		path, err := c.path(o.Name)
+		if err != nil {
+			log.Warn().Msgf("Skipping %s of %s: %s", o.Name, c.storage, err.Error())
+			continue
+		}
+		if info, err := os.Stat(path); err == nil && info.Size() == o.Size && !info.ModTime().Before(o.ModTime) {
+			continue
+		}
+		if err := c.download(ctx, o.Name, path); err != nil {
+			return err
+		}
+	}
+	log.Info().Msgf("Models path backed by %s (%d files)", c.storage, len(objects))
+	return nil
+}
+
+// Exists returns true if the storage has the object, as listed by the last sync
+func (c *Cache) Exists(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.objects[name]
+	return ok
+}
+
+// Models returns the names of the models of the storage, as listed by the last sync
+func (c *Cache) Models() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res := []string{}
+	for name := range c.objects {
+		if !isConfig(name) {
+			res = append(res, name)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Acquire prevents the model from being evicted, until it is released (e.g. while it is loaded)
+func (c *Cache) Acquire(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pins[name]++
+	c.used[name] = time.Now()
+}
+
+func (c *Cache) Release(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pins[name] <= 1 {
+		delete(c.pins, name)
+	} else {
+		c.pins[name]--
+	}
+	c.used[name] = time.Now()
+}
+
+// Fetch downloads the model from the storage if it is not in the directory, and returns its path.
+// The least recently used models are evicted first to make room for it.
+func (c *Cache) Fetch(ctx context.Context, name string) (string, error) {
+	path, err := c.path(name)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	c.used[name] = time.Now()
+	if _, err := os.Stat(path); err == nil {
+		c.mu.Unlock()
+		return path, nil
+	}
+	// another request is fetching the model
+	if f, ok := c.fetching[name]; ok {
+		c.mu.Unlock()
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+		return path, f.err
+	}
+	o, ok := c.objects[name]
+	if !ok {
+		c.mu.Unlock()
+		return "", fmt.Errorf("model %s not found in %s: %w", name, c.storage, ErrNotFound)
+	}
+	f := &fetch{done: make(chan struct{})}
+	c.fetching[name] = f
+	c.mu.Unlock()
+
+	c.evict(o.Size, name)
+	f.err = c.download(ctx, name, path)
+
+	c.mu.Lock()
+	delete(c.fetching, name)
+	c.mu.Unlock()
+	close(f.done)
+	return path, f.err
+}
+
+// evict removes the least recently used models, which are not in use, until room bytes fit in the cache
+func (c *Cache) evict(room int64, keep string) {
+	if c.size <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	type cached struct {
+		name string
+		path string
+		size int64
+		used time.Time
+	}
+	entries := []cached{}
+	var total int64
+	for name := range c.objects {
+		if isConfig(name) || name == keep {
+			continue
+		}
+		path, err := c.path(name)
+		if err != nil {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		total += info.Size()
+		entries = append(entries, cached{name: name, path: path, size: info.Size(), used: c.used[name]})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].used.Before(entries[j].used) })
+
+	for _, e := range entries {
+		if total+room <= c.size {
+			return
+		}
+		if c.pins[e.name] > 0 {
+			continue
+		}
+		if err := os.Remove(e.path); err != nil {
+			log.Warn().Msgf("Failed evicting %s from the models cache: %s", e.name, err.Error())
+			continue
+		}
+		log.Info().Msgf("Evicted %s from the models cache (%d bytes)", e.name, e.size)
+		total -= e.size
+	}
+	if total+room > c.size {
+		log.Warn().Msgf("The models in use take %d bytes, over the size of the models cache (%d bytes)", total+room, c.size)
+	}
+}
+
+func (c *Cache) download(ctx context.Context, name, path string) error {
+	start := time.Now()
+	log.Info().Msgf("Downloading %s from %s", name, c.storage)
+
+	r, err := c.storage.Open(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed downloading %s from %s: %w", name, c.storage, err)
+	}
+	defer r.Close()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// download next to the model, so that it appears only once complete
+	tmp := path + ".partial"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed downloading %s from %s: %w", name, c.storage, err)
+	}
+
+	log.Info().Msgf("Downloaded %s from %s (%d bytes in %s)", name, c.storage, n, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// path returns the path of the object in the directory, refusing the names escaping it
+func (c *Cache) path(name string) (string, error) {
+	if err := utils.VerifyPath(name, c.dir); err != nil {
+		return "", fmt.Errorf("invalid model name %q: %w", name, err)
+	}
+	return filepath.Join(c.dir, name), nil
+}
@@ -0,0 +1,133 @@
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/go-skynet/LocalAI/pkg/storage"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// memoryStorage is a storage counting the downloads of its objects
+type memoryStorage struct {
+	mu        sync.Mutex
+	objects   map[string][]byte
+	downloads map[string]int
+}
+
+func (m *memoryStorage) List(ctx context.Context) ([]Object, error) {
+	res := []Object{}
+	for name, dat := range m.objects {
+		res = append(res, Object{Name: name, Size: int64(len(dat)), ModTime: time.Unix(0, 0)})
+	}
+	return res, nil
+}
+
+func (m *memoryStorage) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	dat, ok := m.objects[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	m.downloads[name]++
+	return io.NopCloser(bytes.NewReader(dat)), nil
+}
+
+func (m *memoryStorage) String() string {
+	return "memory://"
+}
+
+var _ = Describe("Models cache", func() {
+	var dir string
+	var s *memoryStorage
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		s = &memoryStorage{
+			objects: map[string][]byte{
+				"phi-2.yaml":       []byte("name: phi-2"),
+				"phi-2.gguf":       make([]byte, 100),
+				"mistral.gguf":     make([]byte, 100),
+				"llama.gguf":       make([]byte, 100),
+				"nested/bert.gguf": make([]byte, 10),
+			},
+			downloads: map[string]int{},
+		}
+	})
+
+	It("syncs the configurations at startup", func() {
+		c := NewCache(dir, s, 0)
+		Expect(c.Sync(context.Background())).To(Succeed())
+		Expect(filepath.Join(dir, "phi-2.yaml")).To(BeARegularFile())
+		Expect(filepath.Join(dir, "phi-2.gguf")).ToNot(BeAnExistingFile())
+		Expect(c.Exists("phi-2.gguf")).To(BeTrue())
+		Expect(c.Exists("missing.gguf")).To(BeFalse())
+		Expect(c.Models()).To(Equal([]string{"llama.gguf", "mistral.gguf", "nested/bert.gguf", "phi-2.gguf"}))
+
+		// the configurations are not downloaded again
+		Expect(c.Sync(context.Background())).To(Succeed())
+		Expect(s.downloads["phi-2.yaml"]).To(Equal(1))
+	})
+
+	It("fetches the models once", func() {
+		c := NewCache(dir, s, 0)
+		Expect(c.Sync(context.Background())).To(Succeed())
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				path, err := c.Fetch(context.Background(), "nested/bert.gguf")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(path).To(Equal(filepath.Join(dir, "nested", "bert.gguf")))
+			}()
+		}
+		wg.Wait()
+		Expect(filepath.Join(dir, "nested", "bert.gguf")).To(BeARegularFile())
+		Expect(s.downloads["nested/bert.gguf"]).To(Equal(1))
+
+		_, err := c.Fetch(context.Background(), "missing.gguf")
+		Expect(err).To(MatchError(ErrNotFound))
+		_, err = c.Fetch(context.Background(), "../escape.gguf")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("evicts the least recently used models which are not in use", func() {
+		c := NewCache(dir, s, 250)
+		Expect(c.Sync(context.Background())).To(Succeed())
+
+		_, err := c.Fetch(context.Background(), "phi-2.gguf")
+		Expect(err).ToNot(HaveOccurred())
+		c.Acquire("phi-2.gguf")
+		_, err = c.Fetch(context.Background(), "mistral.gguf")
+		Expect(err).ToNot(HaveOccurred())
+
+		// phi-2 is the least recently used, but is loaded
+		_, err = c.Fetch(context.Background(), "llama.gguf")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filepath.Join(dir, "phi-2.gguf")).To(BeARegularFile())
+		Expect(filepath.Join(dir, "mistral.gguf")).ToNot(BeAnExistingFile())
+		Expect(filepath.Join(dir, "llama.gguf")).To(BeARegularFile())
+
+		c.Release("phi-2.gguf")
+		_, err = c.Fetch(context.Background(), "llama.gguf")
+		Expect(err).ToNot(HaveOccurred())
+		_, err = c.Fetch(context.Background(), "mistral.gguf")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filepath.Join(dir, "phi-2.gguf")).ToNot(BeAnExistingFile())
+		Expect(filepath.Join(dir, "phi-2.yaml")).To(BeARegularFile())
+
+		entries, err := os.ReadDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(3))
+	})
+})
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const gcsMetadataToken = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// gcs is a bucket of Google Cloud Storage. The requests are authenticated with GOOGLE_OAUTH_ACCESS_TOKEN,
+// or with the service account of the instance (e.g. GKE workload identity), and are anonymous otherwise.
+type gcs struct {
+	bucket, prefix string
+	endpoint       string
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+	// metadata is false once the metadata server was found unreachable, to not wait for it at each request
+	metadata bool
+}
+
+func newGCS(bucket, prefix string) *gcs {
+	g := &gcs{
+		bucket:   bucket,
+		prefix:   prefix,
+		endpoint: "https://storage.googleapis.com",
+		token:    os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"),
+		metadata: true,
+	}
+	// used by the emulators, e.g. fake-gcs-server
+	if host := os.Getenv("STORAGE_EMULATOR_HOST"); host != "" {
+		if !strings.Contains(host, "://") {
+			host = "http://" + host
+		}
+		g.endpoint = strings.TrimSuffix(host, "/")
+		g.metadata = false
+	}
+	return g
+}
+
+func (g *gcs) String() string {
+	return "gs://" + g.bucket + "/" + g.prefix
+}
+
+type gcsList struct {
+	Items []struct {
+		Name    string    `json:"name"`
+		Size    string    `json:"size"`
+		Updated time.Time `json:"updated"`
+	} `json:"items"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+func (g *gcs) List(ctx context.Context) ([]Object, error) {
+	res := []Object{}
+	page := ""
+	for {
+		query := url.Values{"prefix": {g.prefix}, "fields": {"items(name,size,updated),nextPageToken"}}
+		if page != "" {
+			query.Set("pageToken", page)
+		}
+		resp, err := g.get(ctx, fmt.Sprintf("%s/storage/v1/b/%s/o?%s", g.endpoint, url.PathEscape(g.bucket), query.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		list := gcsList{}
+		err = json.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed reading the objects of %s: %w", g, err)
+		}
+
+		for _, i := range list.Items {
+			if strings.HasSuffix(i.Name, "/") {
+				continue
+			}
+			size, _ := strconv.ParseInt(i.Size, 10, 64)
+			res = append(res, Object{Name: strings.TrimPrefix(i.Name, g.prefix), Size: size, ModTime: i.Updated})
+		}
+		if list.NextPageToken == "" {
+			return res, nil
+		}
+		page = list.NextPageToken
+	}
+}
+
+func (g *gcs) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	resp, err := g.get(ctx, fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media", g.endpoint, url.PathEscape(g.bucket), url.PathEscape(g.prefix+name)))
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (g *gcs) get(ctx context.Context, u string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token := g.accessToken(ctx); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return do(req)
+}
+
+// accessToken returns the token of the environment, or the one of the service account of the instance
+func (g *gcs) accessToken(ctx context.Context) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.token != "" && (g.expires.IsZero() || time.Now().Before(g.expires)) {
+		return g.token
+	}
+	if !g.metadata {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcsMetadataToken, nil)
+	if err != nil {
+		return ""
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := do(req)
+	if err != nil {
+		// not running on Google Cloud
+		g.metadata = false
+		return ""
+	}
+	defer resp.Body.Close()
+
+	token := struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return ""
+	}
+	g.token = token.AccessToken
+	// renew the token a minute before it expires
+	g.expires = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return g.token
+}
@@ -0,0 +1,125 @@
+// Package storage backs the models path with object storage (S3, Google Cloud Storage, Azure Blob Storage):
+// the models are fetched on first use and cached in the models path, which is kept under a size limit by
+// evicting the least recently used models. This allows stateless deployments where the models don't fit in the image.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-skynet/LocalAI/pkg/secrets"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+// Object is a file of the storage, named relatively to the prefix of the storage
+type Object struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+}
+
+type Storage interface {
+	// List returns all the objects under the prefix of the storage
+	List(ctx context.Context) ([]Object, error)
+	// Open returns the content of the object
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+	// String returns the URI of the storage
+	String() string
+}
+
+var client = http.DefaultClient
+
+// New returns the storage of the URI, one of:
+//   - s3://<bucket>/<prefix>
+//   - gs://<bucket>/<prefix>
+//   - az://<container>/<prefix>
+func New(uri string) (Storage, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage %q: %w", uri, err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid storage %q: the bucket is missing", uri)
+	}
+	prefix := strings.Trim(u.Path, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	switch u.Scheme {
+	case "s3":
+		return newS3(u.Host, prefix), nil
+	case "gs":
+		return newGCS(u.Host, prefix), nil
+	case "az":
+		return newAzure(u.Host, prefix)
+	}
+	return nil, fmt.Errorf("invalid storage %q: unsupported scheme %q (s3, gs or az)", uri, u.Scheme)
+}
+
+// NewWithSecret returns the storage of the URI like New, authenticated with the secret instead of the environment:
+// with the keys of S3, or the access token of Google Cloud Storage
+func NewWithSecret(uri string, secret secrets.Secret) (Storage, error) {
+	st, err := New(uri)
+	if err != nil {
+		return nil, err
+	}
+	switch s := st.(type) {
+	case *s3:
+		s.accessKey, s.secretKey, s.sessionToken = secret.AccessKeyID, secret.SecretAccessKey, secret.SessionToken
+		if secret.Region != "" {
+			s.region = secret.Region
+		}
+		if secret.Endpoint != "" {
+			s.endpoint = strings.TrimSuffix(secret.Endpoint, "/") + "/" + s.bucket
+		} else if secret.Region != "" && os.Getenv("AWS_ENDPOINT_URL_S3") == "" && os.Getenv("AWS_ENDPOINT_URL") == "" {
+			s.endpoint = fmt.Sprintf("https://%s.s3.%s.amazonaws.com", s.bucket, s.region)
+		}
+	case *gcs:
+		s.token = secret.Token
+		s.metadata = false
+	default:
+		return nil, fmt.Errorf("the secrets are not supported by the storage %s", uri)
+	}
+	return st, nil
+}
+
+// do sends the request, and returns an error for the unsuccessful responses
+func do(req *http.Request) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s: unexpected status %d: %s", req.Method, req.URL.Redacted(), resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+// escapePath escapes the segments of an object name, keeping the slashes
+func escapePath(name string) string {
+	segments := strings.Split(name, "/")
+	for i, s := range segments {
+		segments[i] = escape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// escape escapes a string as specified by RFC 3986, as required by the signatures
+func escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
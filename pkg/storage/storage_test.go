@@ -0,0 +1,161 @@
+package storage_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/go-skynet/LocalAI/pkg/storage"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Object storages", func() {
+	var server *httptest.Server
+	var requests []*http.Request
+
+	serve := func(handler func(w http.ResponseWriter, r *http.Request)) {
+		requests = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r)
+			handler(w, r)
+		}))
+	}
+
+	AfterEach(func() {
+		if server != nil {
+			server.Close()
+			server = nil
+		}
+	})
+
+	read := func(s Storage, name string) string {
+		r, err := s.Open(context.Background(), name)
+		Expect(err).ToNot(HaveOccurred())
+		defer r.Close()
+		dat, err := io.ReadAll(r)
+		Expect(err).ToNot(HaveOccurred())
+		return string(dat)
+	}
+
+	It("rejects the unsupported storages", func() {
+		_, err := New("ftp://bucket/models")
+		Expect(err).To(HaveOccurred())
+		_, err = New("s3:///models")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("reads S3 compatible buckets", func() {
+		serve(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/models/" && r.URL.Query().Get("continuation-token") == "":
+				fmt.Fprint(w, `<ListBucketResult><Contents><Key>localai/phi-2.gguf</Key><Size>5</Size><LastModified>2024-01-01T00:00:00.000Z</LastModified></Contents>`+
+					`<Contents><Key>localai/sub/</Key><Size>0</Size></Contents><IsTruncated>true</IsTruncated><NextContinuationToken>next</NextContinuationToken></ListBucketResult>`)
+			case r.URL.Path == "/models/":
+				fmt.Fprint(w, `<ListBucketResult><Contents><Key>localai/phi 2.yaml</Key><Size>11</Size></Contents><IsTruncated>false</IsTruncated></ListBucketResult>`)
+			case r.URL.Path == "/models/localai/phi 2.yaml":
+				fmt.Fprint(w, "name: phi-2")
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+		GinkgoT().Setenv("AWS_ENDPOINT_URL", server.URL)
+		GinkgoT().Setenv("AWS_ACCESS_KEY_ID", "access")
+		GinkgoT().Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+		GinkgoT().Setenv("AWS_REGION", "eu-west-1")
+
+		s, err := New("s3://models/localai")
+		Expect(err).ToNot(HaveOccurred())
+		objects, err := s.List(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(objects).To(HaveLen(2))
+		Expect(objects[0].Name).To(Equal("phi-2.gguf"))
+		Expect(objects[0].Size).To(Equal(int64(5)))
+		Expect(objects[0].ModTime.Year()).To(Equal(2024))
+		Expect(objects[1].Name).To(Equal("phi 2.yaml"))
+
+		Expect(read(s, "phi 2.yaml")).To(Equal("name: phi-2"))
+		Expect(requests[2].URL.EscapedPath()).To(Equal("/models/localai/phi%202.yaml"))
+		Expect(requests[2].Header.Get("Authorization")).To(MatchRegexp(`^AWS4-HMAC-SHA256 Credential=access/\d{8}/eu-west-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=[0-9a-f]{64}$`))
+
+		_, err = s.Open(context.Background(), "missing.gguf")
+		Expect(err).To(MatchError(ErrNotFound))
+	})
+
+	It("reads Google Cloud Storage buckets", func() {
+		serve(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/storage/v1/b/models/o" && r.URL.Query().Get("pageToken") == "":
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"items":         []map[string]string{{"name": "localai/phi-2.gguf", "size": "5", "updated": "2024-01-01T00:00:00Z"}},
+					"nextPageToken": "next",
+				})
+			case r.URL.Path == "/storage/v1/b/models/o":
+				json.NewEncoder(w).Encode(map[string]interface{}{"items": []map[string]string{{"name": "localai/phi-2.yaml", "size": "11"}}})
+			case r.URL.Path == "/storage/v1/b/models/o/localai/phi-2.yaml" && r.URL.Query().Get("alt") == "media":
+				fmt.Fprint(w, "name: phi-2")
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+		GinkgoT().Setenv("STORAGE_EMULATOR_HOST", server.URL)
+		GinkgoT().Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "token")
+
+		s, err := New("gs://models/localai/")
+		Expect(err).ToNot(HaveOccurred())
+		objects, err := s.List(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(objects).To(HaveLen(2))
+		Expect(objects[0].Name).To(Equal("phi-2.gguf"))
+		Expect(objects[0].Size).To(Equal(int64(5)))
+		Expect(requests[0].URL.Query().Get("prefix")).To(Equal("localai/"))
+
+		Expect(read(s, "phi-2.yaml")).To(Equal("name: phi-2"))
+		Expect(requests[2].Header.Get("Authorization")).To(Equal("Bearer token"))
+	})
+
+	It("reads Azure Blob Storage containers", func() {
+		serve(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("sig") != "signature" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			switch {
+			case r.URL.Path == "/account/models" && r.URL.Query().Get("comp") == "list":
+				fmt.Fprint(w, `<EnumerationResults><Blobs><Blob><Name>localai/phi-2.gguf</Name><Properties><Content-Length>5</Content-Length>`+
+					`<Last-Modified>Mon, 01 Jan 2024 00:00:00 GMT</Last-Modified></Properties></Blob></Blobs><NextMarker/></EnumerationResults>`)
+			case r.URL.Path == "/account/models/localai/phi-2.gguf":
+				fmt.Fprint(w, "phi-2")
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+		GinkgoT().Setenv("AZURE_STORAGE_ACCOUNT", "account")
+		GinkgoT().Setenv("AZURE_STORAGE_ENDPOINT", server.URL+"/account")
+		GinkgoT().Setenv("AZURE_STORAGE_SAS_TOKEN", "?sv=2021-08-06&sig=signature")
+
+		s, err := New("az://models/localai")
+		Expect(err).ToNot(HaveOccurred())
+		objects, err := s.List(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(objects).To(HaveLen(1))
+		Expect(objects[0].Name).To(Equal("phi-2.gguf"))
+		Expect(objects[0].Size).To(Equal(int64(5)))
+		Expect(objects[0].ModTime.Year()).To(Equal(2024))
+
+		Expect(read(s, "phi-2.gguf")).To(Equal("phi-2"))
+		Expect(requests[1].Header.Get("x-ms-version")).ToNot(BeEmpty())
+
+		GinkgoT().Setenv("AZURE_STORAGE_SAS_TOKEN", "")
+		GinkgoT().Setenv("AZURE_STORAGE_KEY", "c2VjcmV0")
+		s, err = New("az://models/localai")
+		Expect(err).ToNot(HaveOccurred())
+		_, err = s.List(context.Background())
+		Expect(err).To(MatchError(ContainSubstring("403")))
+		Expect(strings.HasPrefix(requests[2].Header.Get("Authorization"), "SharedKey account:")).To(BeTrue())
+	})
+})
@@ -0,0 +1,51 @@
+// Package subtitles formats the transcriptions of the audio as SubRip (SRT) and WebVTT subtitles.
+package subtitles
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Cue is a text shown between the start and the end, said by the speaker when known
+type Cue struct {
+	Start, End time.Duration
+	Text       string
+	Speaker    string
+}
+
+// SRT formats the cues as SubRip subtitles, the speakers prefixing their text in brackets
+func SRT(cues []Cue) string {
+	b := strings.Builder{}
+	for i, c := range cues {
+		text := strings.TrimSpace(c.Text)
+		if c.Speaker != "" {
+			text = "[" + c.Speaker + "] " + text
+		}
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1, timestamp(c.Start, ","), timestamp(c.End, ","), text)
+	}
+	return b.String()
+}
+
+// VTT formats the cues as WebVTT subtitles, the speakers in voice tags
+func VTT(cues []Cue) string {
+	b := strings.Builder{}
+	b.WriteString("WEBVTT\n\n")
+	for _, c := range cues {
+		text := strings.TrimSpace(c.Text)
+		if c.Speaker != "" {
+			text = "<v " + c.Speaker + ">" + text
+		}
+		fmt.Fprintf(&b, "%s --> %s\n%s\n\n", timestamp(c.Start, "."), timestamp(c.End, "."), text)
+	}
+	return b.String()
+}
+
+// timestamp formats d as hours:minutes:seconds, then the milliseconds after the separator
+func timestamp(d time.Duration, sep string) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
@@ -0,0 +1,13 @@
+package subtitles_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSubtitles(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Subtitles test suite")
+}
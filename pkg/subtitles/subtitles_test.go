@@ -0,0 +1,32 @@
+package subtitles_test
+
+import (
+	"time"
+
+	. "github.com/go-skynet/LocalAI/pkg/subtitles"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Subtitles", func() {
+	cues := []Cue{
+		{Start: 0, End: 2500 * time.Millisecond, Text: " Hello there."},
+		{Start: 2500 * time.Millisecond, End: time.Hour + 2*time.Minute + 3*time.Second + 45*time.Millisecond, Text: " General Kenobi!", Speaker: "SPEAKER_01"},
+	}
+
+	It("formats SRT", func() {
+		Expect(SRT(cues)).To(Equal("1\n00:00:00,000 --> 00:00:02,500\nHello there.\n\n" +
+			"2\n00:00:02,500 --> 01:02:03,045\n[SPEAKER_01] General Kenobi!\n\n"))
+	})
+
+	It("formats WebVTT", func() {
+		Expect(VTT(cues)).To(Equal("WEBVTT\n\n" +
+			"00:00:00.000 --> 00:00:02.500\nHello there.\n\n" +
+			"00:00:02.500 --> 01:02:03.045\n<v SPEAKER_01>General Kenobi!\n\n"))
+	})
+
+	It("formats no cues", func() {
+		Expect(SRT(nil)).To(BeEmpty())
+		Expect(VTT(nil)).To(Equal("WEBVTT\n\n"))
+	})
+})
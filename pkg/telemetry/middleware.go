@@ -0,0 +1,28 @@
+package telemetry
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// APIMiddleware records a usage event for every API call
+func APIMiddleware(e *Exporter) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !e.Enabled(KindUsage) {
+			return c.Next()
+		}
+
+		start := time.Now()
+		err := c.Next()
+
+		// the route pattern is used rather than the path, so parameters (e.g. job IDs) are not sent
+		e.Record(KindUsage, map[string]interface{}{
+			"method":      c.Method(),
+			"route":       c.Route().Path,
+			"status":      c.Response().StatusCode(),
+			"duration_ms": time.Since(start).Milliseconds(),
+		})
+		return err
+	}
+}
@@ -0,0 +1,254 @@
+// Package telemetry ships usage and health metrics of a LocalAI node to a collector.
+// It is disabled unless a collector endpoint is configured. Events are buffered
+// while the collector can't be reached, and sent once it is available again.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// KindUsage events describe API calls: route, method, status code and duration. Request and response contents are never included.
+	KindUsage = "usage"
+	// KindHealth events describe the state of the node: uptime, memory and goroutines.
+	KindHealth = "health"
+)
+
+type Event struct {
+	Time time.Time              `json:"time"`
+	Node string                 `json:"node,omitempty"`
+	Kind string                 `json:"kind"`
+	Data map[string]interface{} `json:"data"`
+}
+
+type Exporter struct {
+	endpoint   string
+	node       string
+	interval   time.Duration
+	bufferFile string
+	maxEvents  int
+	kinds      map[string]bool
+	client     *http.Client
+	started    time.Time
+
+	sync.Mutex
+	events []Event
+}
+
+type Option func(*Exporter)
+
+// WithNodeName sets the name identifying the node in the events. By default no name is sent.
+func WithNodeName(name string) Option {
+	return func(e *Exporter) {
+		e.node = name
+	}
+}
+
+func WithInterval(interval time.Duration) Option {
+	return func(e *Exporter) {
+		e.interval = interval
+	}
+}
+
+// WithBufferFile persists the events which could not be sent yet to file, so they survive restarts
+func WithBufferFile(file string) Option {
+	return func(e *Exporter) {
+		e.bufferFile = file
+	}
+}
+
+// WithMaxEvents sets the maximum number of buffered events, the oldest are dropped first
+func WithMaxEvents(max int) Option {
+	return func(e *Exporter) {
+		e.maxEvents = max
+	}
+}
+
+// WithKinds restricts the events which are collected to the given kinds (see KindUsage, KindHealth)
+func WithKinds(kinds ...string) Option {
+	return func(e *Exporter) {
+		e.kinds = map[string]bool{}
+		for _, k := range kinds {
+			e.kinds[k] = true
+		}
+	}
+}
+
+func WithHTTPClient(c *http.Client) Option {
+	return func(e *Exporter) {
+		e.client = c
+	}
+}
+
+func NewExporter(endpoint string, opts ...Option) (*Exporter, error) {
+	e := &Exporter{
+		endpoint:  endpoint,
+		interval:  5 * time.Minute,
+		maxEvents: 10000,
+		kinds:     map[string]bool{KindUsage: true, KindHealth: true},
+		client:    &http.Client{Timeout: 30 * time.Second},
+		started:   time.Now(),
+	}
+	for _, o := range opts {
+		o(e)
+	}
+
+	if e.endpoint == "" {
+		return nil, fmt.Errorf("a telemetry endpoint is required")
+	}
+
+	if e.bufferFile != "" {
+		dat, err := os.ReadFile(e.bufferFile)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if len(dat) > 0 {
+			if err := json.Unmarshal(dat, &e.events); err != nil {
+				log.Warn().Msgf("Discarding unreadable telemetry buffer %s: %s", e.bufferFile, err.Error())
+				e.events = nil
+			}
+		}
+	}
+
+	return e, nil
+}
+
+// Enabled returns true if events of the given kind are collected
+func (e *Exporter) Enabled(kind string) bool {
+	return e != nil && e.kinds[kind]
+}
+
+// Record buffers an event, to be sent with the next flush
+func (e *Exporter) Record(kind string, data map[string]interface{}) {
+	if !e.Enabled(kind) {
+		return
+	}
+
+	e.Lock()
+	defer e.Unlock()
+	e.events = append(e.events, Event{Time: time.Now().UTC(), Node: e.node, Kind: kind, Data: data})
+	if len(e.events) > e.maxEvents {
+		e.events = e.events[len(e.events)-e.maxEvents:]
+	}
+}
+
+func (e *Exporter) recordHealth() {
+	mem := runtime.MemStats{}
+	runtime.ReadMemStats(&mem)
+	e.Record(KindHealth, map[string]interface{}{
+		"uptime_seconds": int64(time.Since(e.started).Seconds()),
+		"goroutines":     runtime.NumGoroutine(),
+		"memory_bytes":   mem.Sys,
+	})
+}
+
+// Flush sends the buffered events to the collector. If sending fails,
+// the events are kept (and persisted, if a buffer file is set) for the next attempt.
+func (e *Exporter) Flush(ctx context.Context) error {
+	e.Lock()
+	events := e.events
+	e.events = nil
+	e.Unlock()
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	err := e.send(ctx, events)
+	if err != nil {
+		e.Lock()
+		e.events = append(events, e.events...)
+		if len(e.events) > e.maxEvents {
+			e.events = e.events[len(e.events)-e.maxEvents:]
+		}
+		e.Unlock()
+	}
+
+	if perr := e.persist(); perr != nil {
+		log.Warn().Msgf("Failed to persist the telemetry buffer: %s", perr.Error())
+	}
+	return err
+}
+
+func (e *Exporter) send(ctx context.Context, events []Event) error {
+	dat, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(dat))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("telemetry collector returned status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// persist writes the pending events to the buffer file, removing it when there is nothing left to send
+func (e *Exporter) persist() error {
+	if e.bufferFile == "" {
+		return nil
+	}
+
+	e.Lock()
+	defer e.Unlock()
+
+	if len(e.events) == 0 {
+		err := os.Remove(e.bufferFile)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	dat, err := json.Marshal(e.events)
+	if err != nil {
+		return err
+	}
+	tmp := e.bufferFile + ".tmp"
+	if err := os.WriteFile(tmp, dat, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, e.bufferFile)
+}
+
+// Run collects the health events and flushes the buffer every interval, until ctx is done
+func (e *Exporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := e.persist(); err != nil {
+				log.Warn().Msgf("Failed to persist the telemetry buffer: %s", err.Error())
+			}
+			return
+		case <-ticker.C:
+			e.recordHealth()
+			if err := e.Flush(ctx); err != nil {
+				log.Debug().Msgf("Telemetry collector not reachable, events are kept for later: %s", err.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package telemetry_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+
+	. "github.com/go-skynet/LocalAI/pkg/telemetry"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type collector struct {
+	sync.Mutex
+	online   bool
+	received []Event
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.Lock()
+	defer c.Unlock()
+	if !c.online {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	events := []Event{}
+	Expect(json.NewDecoder(r.Body).Decode(&events)).To(Succeed())
+	c.received = append(c.received, events...)
+}
+
+var _ = Describe("Exporter", func() {
+	var tempdir string
+	var c *collector
+	var srv *httptest.Server
+
+	BeforeEach(func() {
+		var err error
+		tempdir, err = os.MkdirTemp("", "telemetry")
+		Expect(err).ToNot(HaveOccurred())
+		c = &collector{}
+		srv = httptest.NewServer(c)
+	})
+
+	AfterEach(func() {
+		srv.Close()
+		os.RemoveAll(tempdir)
+	})
+
+	It("buffers events while the collector is offline", func() {
+		buffer := filepath.Join(tempdir, "buffer.json")
+		e, err := NewExporter(srv.URL, WithBufferFile(buffer), WithNodeName("node-1"))
+		Expect(err).ToNot(HaveOccurred())
+
+		e.Record(KindUsage, map[string]interface{}{"route": "/v1/chat/completions"})
+		Expect(e.Flush(context.Background())).ToNot(Succeed())
+		_, err = os.Stat(buffer)
+		Expect(err).ToNot(HaveOccurred())
+
+		// the buffer survives a restart
+		e, err = NewExporter(srv.URL, WithBufferFile(buffer))
+		Expect(err).ToNot(HaveOccurred())
+		e.Record(KindUsage, map[string]interface{}{"route": "/v1/embeddings"})
+
+		c.online = true
+		Expect(e.Flush(context.Background())).To(Succeed())
+		Expect(c.received).To(HaveLen(2))
+		Expect(c.received[0].Node).To(Equal("node-1"))
+		Expect(c.received[0].Data["route"]).To(Equal("/v1/chat/completions"))
+		Expect(c.received[1].Data["route"]).To(Equal("/v1/embeddings"))
+
+		_, err = os.Stat(buffer)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("only collects the enabled kinds", func() {
+		e, err := NewExporter(srv.URL, WithKinds(KindHealth))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(e.Enabled(KindUsage)).To(BeFalse())
+
+		e.Record(KindUsage, map[string]interface{}{"route": "/v1/chat/completions"})
+		c.online = true
+		Expect(e.Flush(context.Background())).To(Succeed())
+		Expect(c.received).To(BeEmpty())
+	})
+
+	It("drops the oldest events when the buffer is full", func() {
+		e, err := NewExporter(srv.URL, WithMaxEvents(2))
+		Expect(err).ToNot(HaveOccurred())
+		for _, r := range []string{"a", "b", "c"} {
+			e.Record(KindUsage, map[string]interface{}{"route": r})
+		}
+		c.online = true
+		Expect(e.Flush(context.Background())).To(Succeed())
+		Expect(c.received).To(HaveLen(2))
+		Expect(c.received[0].Data["route"]).To(Equal("b"))
+	})
+})
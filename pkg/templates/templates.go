@@ -0,0 +1,159 @@
+// Package templates holds the bundles of default prompt templates and stop words of the model families
+// whose chat conventions differ from the defaults (e.g. Qwen, Yi, Sarashina), selected from the GGUF metadata of the models.
+package templates
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-skynet/LocalAI/pkg/gguf"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed bundles/*.yaml
+var bundleFiles embed.FS
+
+// Template mirrors the template settings of the model configurations
+type Template struct {
+	Chat        string `yaml:"chat" json:"chat,omitempty"`
+	ChatMessage string `yaml:"chat_message" json:"chat_message,omitempty"`
+	Completion  string `yaml:"completion" json:"completion,omitempty"`
+	Functions   string `yaml:"function" json:"function,omitempty"`
+}
+
+// Match selects the models of a bundle from their GGUF metadata. All the conditions which are set must match.
+type Match struct {
+	// Architectures are the values of general.architecture
+	Architectures []string `yaml:"architectures" json:"architectures,omitempty"`
+	// Name is a regular expression matched against general.name (or general.basename)
+	Name string `yaml:"name" json:"name,omitempty"`
+	// ChatTemplate is a regular expression matched against the chat template of the tokenizer (tokenizer.chat_template)
+	ChatTemplate string `yaml:"chat_template" json:"chat_template,omitempty"`
+}
+
+type Bundle struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description"`
+	// Fallback bundles are only selected when no other bundle matches
+	Fallback     bool     `yaml:"fallback" json:"fallback,omitempty"`
+	Match        Match    `yaml:"match" json:"match"`
+	SystemPrompt string   `yaml:"system_prompt" json:"system_prompt,omitempty"`
+	Template     Template `yaml:"template" json:"template"`
+	StopWords    []string `yaml:"stopwords" json:"stopwords,omitempty"`
+
+	name, chatTemplate *regexp.Regexp
+}
+
+var bundles []*Bundle
+
+func init() {
+	entries, err := bundleFiles.ReadDir("bundles")
+	if err != nil {
+		panic(err)
+	}
+	for _, e := range entries {
+		dat, err := bundleFiles.ReadFile("bundles/" + e.Name())
+		if err != nil {
+			panic(err)
+		}
+		b := &Bundle{}
+		if err := yaml.Unmarshal(dat, b); err != nil {
+			panic(fmt.Sprintf("invalid template bundle %s: %s", e.Name(), err))
+		}
+		if b.Match.Name != "" {
+			b.name = regexp.MustCompile(b.Match.Name)
+		}
+		if b.Match.ChatTemplate != "" {
+			b.chatTemplate = regexp.MustCompile(b.Match.ChatTemplate)
+		}
+		bundles = append(bundles, b)
+	}
+	// the fallbacks last
+	sort.SliceStable(bundles, func(i, j int) bool { return !bundles[i].Fallback && bundles[j].Fallback })
+}
+
+// Bundles returns all the bundles
+func Bundles() []*Bundle {
+	return bundles
+}
+
+// Get returns the bundle with the given name
+func Get(name string) (*Bundle, bool) {
+	for _, b := range bundles {
+		if b.Name == name {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+func (b *Bundle) matches(metadata map[string]interface{}) bool {
+	str := func(key string) string {
+		s, _ := metadata[key].(string)
+		return s
+	}
+	if b.name == nil && b.chatTemplate == nil && len(b.Match.Architectures) == 0 {
+		return false
+	}
+	if len(b.Match.Architectures) > 0 && !slices.Contains(b.Match.Architectures, str("general.architecture")) {
+		return false
+	}
+	if b.name != nil && !b.name.MatchString(str("general.name")) && !b.name.MatchString(str("general.basename")) {
+		return false
+	}
+	if b.chatTemplate != nil && !b.chatTemplate.MatchString(str("tokenizer.chat_template")) {
+		return false
+	}
+	return true
+}
+
+// Detect returns the bundle matching the GGUF metadata of a model, if any
+func Detect(metadata map[string]interface{}) (*Bundle, bool) {
+	for _, b := range bundles {
+		if b.matches(metadata) {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+type detection struct {
+	modTime time.Time
+	size    int64
+	bundle  *Bundle
+}
+
+var detectionsMu sync.Mutex
+var detections = map[string]detection{}
+
+// DetectFile returns the bundle matching the metadata of the GGUF model file at path, if any.
+// The result is kept until the file changes, as the metadata of the large models take a while to read.
+func DetectFile(path string) (*Bundle, bool) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return nil, false
+	}
+
+	detectionsMu.Lock()
+	d, ok := detections[path]
+	detectionsMu.Unlock()
+	if ok && d.modTime.Equal(info.ModTime()) && d.size == info.Size() {
+		return d.bundle, d.bundle != nil
+	}
+
+	d = detection{modTime: info.ModTime(), size: info.Size()}
+	// the files which are not GGUF have no bundle
+	if f, err := gguf.Read(path); err == nil {
+		d.bundle, _ = Detect(f.Metadata)
+	}
+	detectionsMu.Lock()
+	detections[path] = d
+	detectionsMu.Unlock()
+	return d.bundle, d.bundle != nil
+}
@@ -0,0 +1,93 @@
+package templates_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	. "github.com/go-skynet/LocalAI/pkg/templates"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// writeGGUF writes a GGUF v3 file without tensors, with the given string metadata
+func writeGGUF(path string, metadata map[string]string) {
+	b := &bytes.Buffer{}
+	w := func(v interface{}) { binary.Write(b, binary.LittleEndian, v) }
+	str := func(s string) {
+		w(uint64(len(s)))
+		b.WriteString(s)
+	}
+
+	b.WriteString("GGUF")
+	w(uint32(3))
+	w(uint64(0))
+	w(uint64(len(metadata)))
+	for k, v := range metadata {
+		str(k)
+		w(uint32(8))
+		str(v)
+	}
+	Expect(os.WriteFile(path, b.Bytes(), 0600)).To(Succeed())
+}
+
+var _ = Describe("Template bundles", func() {
+	detect := func(metadata map[string]interface{}) string {
+		b, ok := Detect(metadata)
+		if !ok {
+			return ""
+		}
+		return b.Name
+	}
+
+	It("selects the bundle of the model family", func() {
+		Expect(detect(map[string]interface{}{"general.architecture": "qwen2", "general.name": "Qwen2-7B-Instruct"})).To(Equal("qwen"))
+		Expect(detect(map[string]interface{}{"general.architecture": "llama", "general.name": "Yi 34B Chat"})).To(Equal("yi"))
+		Expect(detect(map[string]interface{}{"general.architecture": "llama", "general.basename": "sarashina2.2-instruct"})).To(Equal("sarashina"))
+		Expect(detect(map[string]interface{}{"general.architecture": "llama", "general.name": "LLaMA v2"})).To(BeEmpty())
+		Expect(detect(map[string]interface{}{})).To(BeEmpty())
+	})
+
+	It("selects the fallbacks last", func() {
+		chatml := "{% for message in messages %}<|im_start|>{{ message['role'] }}{% endfor %}"
+		Expect(detect(map[string]interface{}{"general.architecture": "qwen2", "tokenizer.chat_template": chatml})).To(Equal("qwen"))
+		Expect(detect(map[string]interface{}{"general.architecture": "llama", "tokenizer.chat_template": chatml})).To(Equal("chatml"))
+	})
+
+	It("has valid templates", func() {
+		Expect(Bundles()).ToNot(BeEmpty())
+		for _, b := range Bundles() {
+			for _, t := range []string{b.Template.Chat, b.Template.ChatMessage, b.Template.Completion, b.Template.Functions} {
+				_, err := template.New("prompt").Parse(t)
+				Expect(err).ToNot(HaveOccurred(), b.Name)
+			}
+			Expect(b.StopWords).ToNot(BeEmpty(), b.Name)
+		}
+		_, ok := Get("qwen")
+		Expect(ok).To(BeTrue())
+		_, ok = Get("unknown")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("reads the metadata of the model files", func() {
+		dir := GinkgoT().TempDir()
+		path := filepath.Join(dir, "model.gguf")
+		writeGGUF(path, map[string]string{"general.architecture": "qwen2", "general.name": "Qwen2"})
+		b, ok := DetectFile(path)
+		Expect(ok).To(BeTrue())
+		Expect(b.Name).To(Equal("qwen"))
+
+		// the file changed
+		writeGGUF(path, map[string]string{"general.architecture": "llama", "general.name": "Mistral 7B Instruct v0.2"})
+		_, ok = DetectFile(path)
+		Expect(ok).To(BeFalse())
+
+		Expect(os.WriteFile(filepath.Join(dir, "model.bin"), []byte("ggml"), 0600)).To(Succeed())
+		_, ok = DetectFile(filepath.Join(dir, "model.bin"))
+		Expect(ok).To(BeFalse())
+		_, ok = DetectFile(filepath.Join(dir, "missing.gguf"))
+		Expect(ok).To(BeFalse())
+	})
+})
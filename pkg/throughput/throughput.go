@@ -0,0 +1,160 @@
+package throughput
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultWindow is the period of the inferences the statistics are computed over
+const DefaultWindow = 5 * time.Minute
+
+// the inferences kept at most, whatever the window, to bound the memory of the busy models
+const maxSamples = 1024
+
+// Inference is a completed inference of a model
+type Inference struct {
+	// PromptTokens are the tokens of the prompt evaluated by the backend, zero when unknown
+	PromptTokens int
+	// OutputTokens are the tokens generated
+	OutputTokens int
+	// FirstToken is the time until the first token was generated, zero when the inference was not streamed
+	FirstToken time.Duration
+	// Latency is the time of the whole inference
+	Latency time.Duration
+}
+
+type sample struct {
+	Inference
+	at time.Time
+}
+
+// Percentiles of a duration, in seconds
+type Percentiles struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+}
+
+// Stats are the statistics of the inferences of a model over the window
+type Stats struct {
+	Requests     int `json:"requests"`
+	PromptTokens int `json:"prompt_tokens"`
+	OutputTokens int `json:"output_tokens"`
+	// PrefillTokensPerSecond is the rate the prompts were evaluated at, measured on the streamed inferences
+	PrefillTokensPerSecond float64 `json:"prefill_tokens_per_second"`
+	// DecodeTokensPerSecond is the rate the tokens were generated at, after the first one for the streamed inferences
+	DecodeTokensPerSecond float64 `json:"decode_tokens_per_second"`
+	// TimeToFirstToken is measured on the streamed inferences
+	TimeToFirstToken Percentiles `json:"time_to_first_token"`
+	Latency          Percentiles `json:"latency"`
+	// Window is the period the statistics are computed over, in seconds
+	Window float64 `json:"window"`
+}
+
+// Tracker keeps the inferences of the models over a rolling window
+type Tracker struct {
+	mu      sync.Mutex
+	window  time.Duration
+	samples map[string][]sample
+}
+
+// New returns a tracker computing the statistics over the given window, DefaultWindow if zero
+func New(window time.Duration) *Tracker {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Tracker{window: window, samples: map[string][]sample{}}
+}
+
+// Observe records an inference of the model
+func (t *Tracker) Observe(model string, i Inference) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	s := append(t.expire(t.samples[model], now), sample{Inference: i, at: now})
+	if len(s) > maxSamples {
+		s = s[len(s)-maxSamples:]
+	}
+	t.samples[model] = s
+}
+
+// expire drops the samples older than the window
+func (t *Tracker) expire(s []sample, now time.Time) []sample {
+	i := sort.Search(len(s), func(i int) bool { return now.Sub(s[i].at) <= t.window })
+	return s[i:]
+}
+
+// Stats returns the statistics of the models with inferences in the window, by name
+func (t *Tracker) Stats() map[string]Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	stats := map[string]Stats{}
+	for model, s := range t.samples {
+		s = t.expire(s, now)
+		if len(s) == 0 {
+			delete(t.samples, model)
+			continue
+		}
+		t.samples[model] = s
+		stats[model] = t.stats(s)
+	}
+	return stats
+}
+
+func (t *Tracker) stats(samples []sample) Stats {
+	st := Stats{Requests: len(samples), Window: t.window.Seconds()}
+	var prefillTokens, decodeTokens int
+	var prefillTime, decodeTime time.Duration
+	var firstTokens, latencies []time.Duration
+	for _, s := range samples {
+		st.PromptTokens += s.PromptTokens
+		st.OutputTokens += s.OutputTokens
+		latencies = append(latencies, s.Latency)
+
+		if s.FirstToken <= 0 {
+			decodeTokens += s.OutputTokens
+			decodeTime += s.Latency
+			continue
+		}
+		firstTokens = append(firstTokens, s.FirstToken)
+		if s.PromptTokens > 0 {
+			prefillTokens += s.PromptTokens
+			prefillTime += s.FirstToken
+		}
+		if s.OutputTokens > 1 {
+			decodeTokens += s.OutputTokens - 1
+			decodeTime += s.Latency - s.FirstToken
+		}
+	}
+	st.PrefillTokensPerSecond = rate(prefillTokens, prefillTime)
+	st.DecodeTokensPerSecond = rate(decodeTokens, decodeTime)
+	st.TimeToFirstToken = percentiles(firstTokens)
+	st.Latency = percentiles(latencies)
+	return st
+}
+
+func rate(tokens int, d time.Duration) float64 {
+	if tokens == 0 || d <= 0 {
+		return 0
+	}
+	return float64(tokens) / d.Seconds()
+}
+
+// percentiles returns the nearest-rank percentiles of the durations
+func percentiles(d []time.Duration) Percentiles {
+	if len(d) == 0 {
+		return Percentiles{}
+	}
+	sort.Slice(d, func(i, j int) bool { return d[i] < d[j] })
+	at := func(p float64) float64 {
+		i := int(math.Ceil(p*float64(len(d)))) - 1
+		if i < 0 {
+			i = 0
+		}
+		return d[i].Seconds()
+	}
+	return Percentiles{P50: at(0.5), P90: at(0.9), P99: at(0.99)}
+}
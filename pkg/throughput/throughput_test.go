@@ -0,0 +1,58 @@
+package throughput_test
+
+import (
+	"time"
+
+	. "github.com/go-skynet/LocalAI/pkg/throughput"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tracker", func() {
+	It("computes the rates and the percentiles of the inferences of each model", func() {
+		t := New(time.Minute)
+		for i := 1; i <= 10; i++ {
+			t.Observe("mistral", Inference{
+				PromptTokens: 100,
+				OutputTokens: 51,
+				FirstToken:   time.Duration(i) * 100 * time.Millisecond,
+				Latency:      time.Duration(i)*100*time.Millisecond + time.Second,
+			})
+		}
+		// not streamed: only the latency and the decoding are measured
+		t.Observe("phi", Inference{OutputTokens: 20, Latency: 2 * time.Second})
+
+		stats := t.Stats()
+		Expect(stats).To(HaveLen(2))
+
+		st := stats["mistral"]
+		Expect(st.Requests).To(Equal(10))
+		Expect(st.PromptTokens).To(Equal(1000))
+		Expect(st.OutputTokens).To(Equal(510))
+		Expect(st.PrefillTokensPerSecond).To(BeNumerically("~", 1000/5.5, 0.01))
+		Expect(st.DecodeTokensPerSecond).To(BeNumerically("~", 50, 0.01))
+		Expect(st.TimeToFirstToken.P50).To(BeNumerically("~", 0.5, 0.001))
+		Expect(st.TimeToFirstToken.P90).To(BeNumerically("~", 0.9, 0.001))
+		Expect(st.TimeToFirstToken.P99).To(BeNumerically("~", 1, 0.001))
+		Expect(st.Latency.P50).To(BeNumerically("~", 1.5, 0.001))
+		Expect(st.Window).To(Equal(60.0))
+
+		st = stats["phi"]
+		Expect(st.PrefillTokensPerSecond).To(BeZero())
+		Expect(st.DecodeTokensPerSecond).To(BeNumerically("~", 10, 0.01))
+		Expect(st.TimeToFirstToken).To(Equal(Percentiles{}))
+		Expect(st.Latency.P99).To(BeNumerically("~", 2, 0.001))
+	})
+
+	It("forgets the inferences older than the window", func() {
+		t := New(50 * time.Millisecond)
+		t.Observe("mistral", Inference{OutputTokens: 10, Latency: time.Second})
+		Expect(t.Stats()).To(HaveKey("mistral"))
+
+		time.Sleep(100 * time.Millisecond)
+		t.Observe("phi", Inference{OutputTokens: 10, Latency: time.Second})
+		stats := t.Stats()
+		Expect(stats).ToNot(HaveKey("mistral"))
+		Expect(stats["phi"].Requests).To(Equal(1))
+	})
+})
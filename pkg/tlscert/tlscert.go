@@ -0,0 +1,101 @@
+// Package tlscert serves the HTTP API over TLS with a certificate which is reloaded when its files change, e.g. when
+// they are renewed by certbot or cert-manager, without restarting LocalAI.
+package tlscert
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Reloader holds the certificate of a key pair of files, reloaded when they change
+type Reloader struct {
+	certFile, keyFile string
+
+	sync.RWMutex
+	cert *tls.Certificate
+	// the modification times of the files of the certificate loaded
+	certTime, keyTime time.Time
+}
+
+// NewReloader loads the certificate of the key pair of files
+func NewReloader(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload loads the certificate again if its files changed, and returns true if it did. The current certificate is
+// kept when the new files can't be loaded, e.g. while they are being written.
+func (r *Reloader) Reload() (bool, error) {
+	certTime, err := modTime(r.certFile)
+	if err != nil {
+		return false, err
+	}
+	keyTime, err := modTime(r.keyFile)
+	if err != nil {
+		return false, err
+	}
+	r.RLock()
+	unchanged := r.cert != nil && certTime.Equal(r.certTime) && keyTime.Equal(r.keyTime)
+	r.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	pair, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("cannot load the TLS certificate: %w", err)
+	}
+	r.Lock()
+	defer r.Unlock()
+	r.cert, r.certTime, r.keyTime = &pair, certTime, keyTime
+	return true, nil
+}
+
+// GetCertificate returns the current certificate, for the tls.Config of the listener
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.RLock()
+	defer r.RUnlock()
+	return r.cert, nil
+}
+
+// Config returns the configuration of a TLS listener serving the current certificate
+func (r *Reloader) Config() *tls.Config {
+	return &tls.Config{MinVersion: tls.VersionTLS12, GetCertificate: r.GetCertificate}
+}
+
+// Run checks the files of the certificate at every interval, and when reload receives, until the context is canceled
+func (r *Reloader) Run(ctx context.Context, interval time.Duration, reload <-chan os.Signal) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-reload:
+		}
+		reloaded, err := r.Reload()
+		if err != nil {
+			log.Error().Msgf("Reloading the TLS certificate failed, the current one is kept: %v", err)
+		} else if reloaded {
+			log.Info().Msgf("Reloaded the TLS certificate %s", r.certFile)
+		}
+	}
+}
+
+func modTime(file string) (time.Time, error) {
+	st, err := os.Stat(file)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot read the TLS certificate: %w", err)
+	}
+	return st.ModTime(), nil
+}
@@ -0,0 +1,13 @@
+package tlscert_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTLSCert(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "TLS certificate test suite")
+}
@@ -0,0 +1,90 @@
+package tlscert_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/go-skynet/LocalAI/pkg/tlscert"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reloader", func() {
+	var dir, certFile, keyFile string
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		certFile, keyFile = filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	})
+
+	// writeCert writes a self-signed certificate of the host, modified at the time
+	writeCert := func(host string, at time.Time) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: host},
+			DNSNames:     []string{host},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+		Expect(err).ToNot(HaveOccurred())
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)).To(Succeed())
+		Expect(os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)).To(Succeed())
+		Expect(os.Chtimes(certFile, at, at)).To(Succeed())
+		Expect(os.Chtimes(keyFile, at, at)).To(Succeed())
+	}
+
+	host := func(r *Reloader) string {
+		cert, err := r.GetCertificate(nil)
+		Expect(err).ToNot(HaveOccurred())
+		parsed, err := x509.ParseCertificate(cert.Certificate[0])
+		Expect(err).ToNot(HaveOccurred())
+		return parsed.Subject.CommonName
+	}
+
+	It("reloads the certificate when its files change", func() {
+		start := time.Now().Add(-time.Minute)
+		writeCert("old.example.com", start)
+		r, err := NewReloader(certFile, keyFile)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(host(r)).To(Equal("old.example.com"))
+
+		reloaded, err := r.Reload()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reloaded).To(BeFalse())
+
+		writeCert("new.example.com", start.Add(time.Second))
+		reloaded, err = r.Reload()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reloaded).To(BeTrue())
+		Expect(host(r)).To(Equal("new.example.com"))
+	})
+
+	It("keeps the current certificate when the new one is invalid", func() {
+		start := time.Now().Add(-time.Minute)
+		writeCert("old.example.com", start)
+		r, err := NewReloader(certFile, keyFile)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(os.WriteFile(keyFile, []byte("half written"), 0600)).To(Succeed())
+		_, err = r.Reload()
+		Expect(err).To(HaveOccurred())
+		Expect(host(r)).To(Equal("old.example.com"))
+	})
+
+	It("requires the files of the certificate", func() {
+		_, err := NewReloader(certFile, keyFile)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,429 @@
+// Package tokenizer tokenizes the texts like llama.cpp, with the vocabulary embedded in the GGUF model files, so the
+// tokens are counted without loading the model in a backend.
+package tokenizer
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-skynet/LocalAI/pkg/gguf"
+)
+
+// ErrUnsupported is returned for the vocabularies which are not tokenized like llama.cpp does, those models are
+// tokenized by their backend
+var ErrUnsupported = errors.New("unsupported tokenizer")
+
+// types of the tokens of the vocabulary (tokenizer.ggml.token_type)
+const (
+	TypeNormal      int32 = 1
+	TypeUnknown     int32 = 2
+	TypeControl     int32 = 3
+	TypeUserDefined int32 = 4
+	TypeUnused      int32 = 5
+	TypeByte        int32 = 6
+)
+
+// pre-tokenization of the BPE vocabularies, splitting the texts in words before merging their bytes
+var (
+	gpt2Split   = regexp.MustCompile(`'s|'t|'re|'ve|'m|'ll|'d| ?\p{L}+| ?\p{N}+| ?[^\s\p{L}\p{N}]+|\s+`)
+	llama3Split = regexp.MustCompile(`(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+`)
+)
+
+// Vocab is the vocabulary of a model, as in the tokenizer.ggml metadata of its GGUF file
+type Vocab struct {
+	// Model is llama (SentencePiece) or gpt2 (byte-level BPE)
+	Model string
+	// Pre is the pre-tokenization of the BPE vocabularies: default (GPT-2) or llama-bpe (Llama 3)
+	Pre    string
+	Tokens []string
+	Scores []float32
+	Types  []int32
+	// Merges are the BPE merges by priority, as "left right"
+	Merges []string
+	BOS    int32
+	AddBOS bool
+	// AddSpacePrefix prefixes the SentencePiece texts with a space
+	AddSpacePrefix bool
+}
+
+type Tokenizer struct {
+	vocab Vocab
+	ids   map[string]int32
+	// the rank of each BPE merge
+	ranks map[string]int
+	split *regexp.Regexp
+	// the control and user-defined tokens, matched as-is in the texts, by first byte and longest first
+	special map[byte][]string
+	unknown int32
+}
+
+// New returns the tokenizer of the vocabulary
+func New(v Vocab) (*Tokenizer, error) {
+	t := &Tokenizer{vocab: v, ids: map[string]int32{}, special: map[byte][]string{}, unknown: -1}
+	switch v.Model {
+	case "llama":
+	case "gpt2":
+		switch v.Pre {
+		case "", "default", "gpt-2":
+			t.split = gpt2Split
+		case "llama-bpe", "llama3":
+			t.split = llama3Split
+		default:
+			return nil, fmt.Errorf("%w: pre-tokenizer %s", ErrUnsupported, v.Pre)
+		}
+		t.ranks = map[string]int{}
+		for i, m := range v.Merges {
+			if _, ok := t.ranks[m]; !ok {
+				t.ranks[m] = i
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: model %q", ErrUnsupported, v.Model)
+	}
+	if len(v.Tokens) == 0 {
+		return nil, fmt.Errorf("the vocabulary has no tokens")
+	}
+	if (len(v.Scores) != 0 && len(v.Scores) != len(v.Tokens)) || (len(v.Types) != 0 && len(v.Types) != len(v.Tokens)) {
+		return nil, fmt.Errorf("the vocabulary has %d tokens, %d scores and %d types", len(v.Tokens), len(v.Scores), len(v.Types))
+	}
+
+	for i, tok := range v.Tokens {
+		if _, ok := t.ids[tok]; !ok {
+			t.ids[tok] = int32(i)
+		}
+		switch t.tokenType(int32(i)) {
+		case TypeUnknown:
+			if t.unknown < 0 {
+				t.unknown = int32(i)
+			}
+		case TypeControl, TypeUserDefined:
+			if tok != "" {
+				t.special[tok[0]] = append(t.special[tok[0]], tok)
+			}
+		}
+	}
+	for _, list := range t.special {
+		sort.Slice(list, func(i, j int) bool { return len(list[i]) > len(list[j]) })
+	}
+	return t, nil
+}
+
+// FromGGUF returns the tokenizer of the vocabulary of a GGUF model file
+func FromGGUF(path string) (*Tokenizer, error) {
+	f, err := gguf.ReadArrays(path, "tokenizer.ggml.tokens", "tokenizer.ggml.scores", "tokenizer.ggml.token_type", "tokenizer.ggml.merges")
+	if err != nil {
+		return nil, err
+	}
+
+	v := Vocab{BOS: -1}
+	v.Model, _ = f.Metadata["tokenizer.ggml.model"].(string)
+	v.Pre, _ = f.Metadata["tokenizer.ggml.pre"].(string)
+	// the defaults of llama.cpp
+	v.AddBOS, v.AddSpacePrefix = v.Model == "llama", v.Model == "llama"
+	if b, ok := f.Metadata["tokenizer.ggml.add_bos_token"].(bool); ok {
+		v.AddBOS = b
+	}
+	if b, ok := f.Metadata["tokenizer.ggml.add_space_prefix"].(bool); ok {
+		v.AddSpacePrefix = b
+	}
+	if id, ok := f.Metadata["tokenizer.ggml.bos_token_id"].(uint32); ok {
+		v.BOS = int32(id)
+	}
+
+	for _, tok := range f.Arrays["tokenizer.ggml.tokens"] {
+		s, _ := tok.(string)
+		v.Tokens = append(v.Tokens, s)
+	}
+	for _, score := range f.Arrays["tokenizer.ggml.scores"] {
+		s, _ := score.(float32)
+		v.Scores = append(v.Scores, s)
+	}
+	for _, typ := range f.Arrays["tokenizer.ggml.token_type"] {
+		t, _ := typ.(int32)
+		v.Types = append(v.Types, t)
+	}
+	for _, merge := range f.Arrays["tokenizer.ggml.merges"] {
+		m, _ := merge.(string)
+		v.Merges = append(v.Merges, m)
+	}
+	return New(v)
+}
+
+func (t *Tokenizer) tokenType(id int32) int32 {
+	if len(t.vocab.Types) == 0 {
+		return TypeNormal
+	}
+	return t.vocab.Types[id]
+}
+
+// Encode returns the tokens of the text, starting with the BOS token if the vocabulary adds it. The control and
+// user-defined tokens of the text are kept as-is.
+func (t *Tokenizer) Encode(text string) []int32 {
+	tokens := []int32{}
+	if t.vocab.AddBOS && t.vocab.BOS >= 0 && int(t.vocab.BOS) < len(t.vocab.Tokens) {
+		tokens = append(tokens, t.vocab.BOS)
+	}
+
+	start := 0
+	for i := 0; i < len(text); i++ {
+		for _, s := range t.special[text[i]] {
+			if !strings.HasPrefix(text[i:], s) {
+				continue
+			}
+			tokens = t.encode(tokens, text[start:i], start == 0)
+			tokens = append(tokens, t.ids[s])
+			i += len(s) - 1
+			start = i + 1
+			break
+		}
+	}
+	return t.encode(tokens, text[start:], start == 0)
+}
+
+// encode appends the tokens of a text without special tokens
+func (t *Tokenizer) encode(tokens []int32, text string, first bool) []int32 {
+	if text == "" {
+		return tokens
+	}
+	if t.split == nil {
+		if first && t.vocab.AddSpacePrefix {
+			text = " " + text
+		}
+		return t.encodeSPM(tokens, strings.ReplaceAll(text, " ", "▁"))
+	}
+	return t.encodeBPE(tokens, text)
+}
+
+// symbol is a piece of the text being merged, linked to its neighbours
+type symbol struct {
+	start, n   int
+	prev, next int
+}
+
+type bigram struct {
+	left, right int
+	score       float32
+	// the length of the merged text, which tells when one of the symbols changed since the bigram was added
+	n int
+}
+
+type bigrams []bigram
+
+func (b bigrams) Len() int { return len(b) }
+func (b bigrams) Less(i, j int) bool {
+	if b[i].score != b[j].score {
+		return b[i].score > b[j].score
+	}
+	return b[i].left < b[j].left
+}
+func (b bigrams) Swap(i, j int)       { b[i], b[j] = b[j], b[i] }
+func (b *bigrams) Push(x interface{}) { *b = append(*b, x.(bigram)) }
+func (b *bigrams) Pop() interface{} {
+	old := *b
+	x := old[len(old)-1]
+	*b = old[:len(old)-1]
+	return x
+}
+
+// encodeSPM merges the characters of the text by the highest scores of the merged tokens, as SentencePiece does, and
+// falls back to the byte tokens for the characters out of the vocabulary
+func (t *Tokenizer) encodeSPM(tokens []int32, text string) []int32 {
+	symbols := []symbol{}
+	for i := 0; i < len(text); {
+		_, size := utf8.DecodeRuneInString(text[i:])
+		symbols = append(symbols, symbol{start: i, n: size, prev: len(symbols) - 1, next: len(symbols) + 1})
+		i += size
+	}
+	symbols[len(symbols)-1].next = -1
+
+	queue := &bigrams{}
+	add := func(left, right int) {
+		if left < 0 || right < 0 {
+			return
+		}
+		merged := text[symbols[left].start : symbols[right].start+symbols[right].n]
+		id, ok := t.ids[merged]
+		if !ok {
+			return
+		}
+		var score float32
+		if len(t.vocab.Scores) > 0 {
+			score = t.vocab.Scores[id]
+		}
+		heap.Push(queue, bigram{left: left, right: right, score: score, n: len(merged)})
+	}
+	for i := 1; i < len(symbols); i++ {
+		add(i-1, i)
+	}
+
+	for queue.Len() > 0 {
+		b := heap.Pop(queue).(bigram)
+		left, right := &symbols[b.left], &symbols[b.right]
+		if left.n == 0 || right.n == 0 || left.n+right.n != b.n {
+			continue
+		}
+		left.n += right.n
+		right.n = 0
+		left.next = right.next
+		if right.next >= 0 {
+			symbols[right.next].prev = b.left
+		}
+		add(left.prev, b.left)
+		add(b.left, left.next)
+	}
+
+	for i := 0; i >= 0; i = symbols[i].next {
+		piece := text[symbols[i].start : symbols[i].start+symbols[i].n]
+		if id, ok := t.ids[piece]; ok {
+			tokens = append(tokens, id)
+			continue
+		}
+		for j := 0; j < len(piece); j++ {
+			if id, ok := t.ids[fmt.Sprintf("<0x%02X>", piece[j])]; ok {
+				tokens = append(tokens, id)
+			} else if t.unknown >= 0 {
+				tokens = append(tokens, t.unknown)
+			}
+		}
+	}
+	return tokens
+}
+
+// encodeBPE splits the text in words, maps their bytes to the characters of the vocabulary and merges them by the
+// ranks of the merges
+func (t *Tokenizer) encodeBPE(tokens []int32, text string) []int32 {
+	for _, word := range t.words(text) {
+		b := strings.Builder{}
+		for i := 0; i < len(word); i++ {
+			b.WriteRune(byteRunes[word[i]])
+		}
+		mapped := b.String()
+		// llama.cpp uses the tokens of the whole words of the Llama 3 vocabulary without merging them
+		if id, ok := t.ids[mapped]; ok && t.split == llama3Split {
+			tokens = append(tokens, id)
+			continue
+		}
+
+		parts := []string{}
+		for _, r := range mapped {
+			parts = append(parts, string(r))
+		}
+		for len(parts) > 1 {
+			best, bestRank := -1, 0
+			for i := 0; i+1 < len(parts); i++ {
+				if rank, ok := t.ranks[parts[i]+" "+parts[i+1]]; ok && (best < 0 || rank < bestRank) {
+					best, bestRank = i, rank
+				}
+			}
+			if best < 0 {
+				break
+			}
+			parts[best] += parts[best+1]
+			parts = append(parts[:best+1], parts[best+2:]...)
+		}
+
+		for _, p := range parts {
+			if id, ok := t.ids[p]; ok {
+				tokens = append(tokens, id)
+				continue
+			}
+			for _, r := range p {
+				if id, ok := t.ids[string(r)]; ok {
+					tokens = append(tokens, id)
+				} else if t.unknown >= 0 {
+					tokens = append(tokens, t.unknown)
+				}
+			}
+		}
+	}
+	return tokens
+}
+
+// words splits the text with the pre-tokenizer. The expressions of the pre-tokenizers end with \s+(?!\S)|\s+, where
+// the lookahead, which Go doesn't support, keeps the last space of a run for the word that follows it.
+func (t *Tokenizer) words(text string) []string {
+	words := []string{}
+	for len(text) > 0 {
+		loc := t.split.FindStringIndex(text)
+		if loc == nil || loc[0] != 0 || loc[1] == 0 {
+			// never happens: the expressions match every character
+			return append(words, text)
+		}
+		end := loc[1]
+		word := text[:end]
+		// the runs of spaces with a newline are matched by \s*[\r\n]+ in the Llama 3 expression
+		if end < len(text) && strings.Trim(word, " \t\n\f\r") == "" && (t.split == gpt2Split || !strings.ContainsAny(word, "\r\n")) {
+			if _, size := utf8.DecodeLastRuneInString(word); size < len(word) {
+				end -= size
+			}
+		}
+		words = append(words, text[:end])
+		text = text[end:]
+	}
+	return words
+}
+
+// Decode returns the text of the tokens, without the control tokens
+func (t *Tokenizer) Decode(tokens []int32) string {
+	b := []byte{}
+	for _, id := range tokens {
+		if id < 0 || int(id) >= len(t.vocab.Tokens) {
+			continue
+		}
+		piece := t.vocab.Tokens[id]
+		switch t.tokenType(id) {
+		case TypeControl, TypeUnknown, TypeUnused:
+			continue
+		case TypeUserDefined:
+			b = append(b, piece...)
+			continue
+		}
+
+		if t.split != nil {
+			for _, r := range piece {
+				if c, ok := runeBytes[r]; ok {
+					b = append(b, c)
+				} else {
+					b = utf8.AppendRune(b, r)
+				}
+			}
+			continue
+		}
+		if t.tokenType(id) == TypeByte || (len(piece) == 6 && strings.HasPrefix(piece, "<0x") && strings.HasSuffix(piece, ">")) {
+			if c, err := strconv.ParseUint(piece[3:5], 16, 8); err == nil {
+				b = append(b, byte(c))
+				continue
+			}
+		}
+		piece = strings.ReplaceAll(piece, "▁", " ")
+		if len(b) == 0 && t.vocab.AddSpacePrefix {
+			piece = strings.TrimPrefix(piece, " ")
+		}
+		b = append(b, piece...)
+	}
+	return string(b)
+}
+
+// byteRunes maps the bytes to the printable characters of the byte-level BPE vocabularies, as GPT-2 does, and
+// runeBytes maps them back
+var byteRunes, runeBytes = func() ([256]rune, map[rune]byte) {
+	var runes [256]rune
+	bytes := map[rune]byte{}
+	n := 0
+	for c := 0; c < 256; c++ {
+		r := rune(c)
+		if !(c >= '!' && c <= '~') && !(c >= 0xA1 && c <= 0xAC) && !(c >= 0xAE && c <= 0xFF) {
+			r = rune(256 + n)
+			n++
+		}
+		runes[c] = r
+		bytes[r] = byte(c)
+	}
+	return runes, bytes
+}()
@@ -0,0 +1,163 @@
+package tokenizer_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+
+	. "github.com/go-skynet/LocalAI/pkg/tokenizer"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// spmVocab is a SentencePiece vocabulary merging "hello" and "world" step by step
+func spmVocab() Vocab {
+	v := Vocab{Model: "llama", BOS: 1, AddBOS: true, AddSpacePrefix: true}
+	add := func(tok string, score float32, typ int32) {
+		v.Tokens = append(v.Tokens, tok)
+		v.Scores = append(v.Scores, score)
+		v.Types = append(v.Types, typ)
+	}
+	add("<unk>", 0, TypeUnknown)
+	add("<s>", 0, TypeControl)
+	add("</s>", 0, TypeControl)
+	add("<0x0A>", 0, TypeByte)
+	for _, c := range []string{"▁", "h", "e", "l", "o", "w", "r", "d"} {
+		add(c, -10, TypeNormal)
+	}
+	add("ll", -1, TypeNormal)
+	add("▁h", -5, TypeNormal)
+	add("▁he", -4, TypeNormal)
+	add("▁hell", -3, TypeNormal)
+	add("▁hello", -2, TypeNormal)
+	add("▁w", -5, TypeNormal)
+	add("▁wo", -4, TypeNormal)
+	add("▁wor", -3, TypeNormal)
+	add("▁worl", -2, TypeNormal)
+	add("▁world", -1, TypeNormal)
+	return v
+}
+
+// bpeVocab is a byte-level BPE vocabulary, where Ġ is the space
+func bpeVocab() Vocab {
+	return Vocab{
+		Model:  "gpt2",
+		Tokens: []string{"h", "e", "l", "o", "w", "r", "d", "Ġ", "he", "ll", "hell", "hello", "Ġw", "or", "Ġwor", "ld", "Ġworld", "<|end|>"},
+		Types:  []int32{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, TypeControl},
+		Merges: []string{"h e", "l l", "he ll", "hell o", "Ġ w", "o r", "Ġw or", "l d", "Ġwor ld"},
+		BOS:    -1,
+	}
+}
+
+func id(v Vocab, tok string) int32 {
+	for i, t := range v.Tokens {
+		if t == tok {
+			return int32(i)
+		}
+	}
+	Fail("no token " + tok)
+	return -1
+}
+
+var _ = Describe("Tokenizer", func() {
+	Context("SentencePiece", func() {
+		It("merges the pieces with the highest scores", func() {
+			v := spmVocab()
+			t, err := New(v)
+			Expect(err).ToNot(HaveOccurred())
+
+			tokens := t.Encode("hello world")
+			Expect(tokens).To(Equal([]int32{1, id(v, "▁hello"), id(v, "▁world")}))
+			Expect(t.Decode(tokens)).To(Equal("hello world"))
+		})
+
+		It("falls back to the bytes, and keeps the control tokens", func() {
+			v := spmVocab()
+			t, err := New(v)
+			Expect(err).ToNot(HaveOccurred())
+
+			tokens := t.Encode("hello\n</s>")
+			Expect(tokens).To(Equal([]int32{1, id(v, "▁hello"), id(v, "<0x0A>"), 2}))
+			Expect(t.Decode(tokens)).To(Equal("hello\n"))
+
+			Expect(t.Encode("x")).To(Equal([]int32{1, id(v, "▁"), 0}))
+		})
+	})
+
+	Context("BPE", func() {
+		It("merges the bytes by the ranks of the merges", func() {
+			v := bpeVocab()
+			t, err := New(v)
+			Expect(err).ToNot(HaveOccurred())
+
+			tokens := t.Encode("hello world<|end|>")
+			Expect(tokens).To(Equal([]int32{id(v, "hello"), id(v, "Ġworld"), id(v, "<|end|>")}))
+			Expect(t.Decode(tokens)).To(Equal("hello world"))
+		})
+
+		It("keeps the last space of a run with the next word", func() {
+			v := bpeVocab()
+			t, err := New(v)
+			Expect(err).ToNot(HaveOccurred())
+
+			tokens := t.Encode("hello  world")
+			Expect(tokens).To(Equal([]int32{id(v, "hello"), id(v, "Ġ"), id(v, "Ġworld")}))
+			Expect(t.Decode(tokens)).To(Equal("hello  world"))
+		})
+	})
+
+	It("refuses the vocabularies it doesn't tokenize like llama.cpp", func() {
+		_, err := New(Vocab{Model: "bert", Tokens: []string{"a"}})
+		Expect(err).To(MatchError(ErrUnsupported))
+
+		v := bpeVocab()
+		v.Pre = "deepseek-coder"
+		_, err = New(v)
+		Expect(err).To(MatchError(ErrUnsupported))
+	})
+
+	It("reads the vocabulary of a GGUF file", func() {
+		v := spmVocab()
+		b := &bytes.Buffer{}
+		w := func(v interface{}) { binary.Write(b, binary.LittleEndian, v) }
+		str := func(s string) {
+			w(uint64(len(s)))
+			b.WriteString(s)
+		}
+		b.WriteString("GGUF")
+		w(uint32(3))
+		w(uint64(0))
+		w(uint64(5))
+		str("tokenizer.ggml.model")
+		w(uint32(8))
+		str("llama")
+		str("tokenizer.ggml.bos_token_id")
+		w(uint32(4))
+		w(uint32(1))
+		str("tokenizer.ggml.tokens")
+		w(uint32(9))
+		w(uint32(8))
+		w(uint64(len(v.Tokens)))
+		for _, t := range v.Tokens {
+			str(t)
+		}
+		str("tokenizer.ggml.scores")
+		w(uint32(9))
+		w(uint32(6))
+		w(uint64(len(v.Scores)))
+		w(v.Scores)
+		str("tokenizer.ggml.token_type")
+		w(uint32(9))
+		w(uint32(5))
+		w(uint64(len(v.Types)))
+		w(v.Types)
+
+		path := filepath.Join(GinkgoT().TempDir(), "model.gguf")
+		Expect(os.WriteFile(path, b.Bytes(), 0600)).To(Succeed())
+
+		t, err := FromGGUF(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(t.Encode("hello world")).To(Equal([]int32{1, id(v, "▁hello"), id(v, "▁world")}))
+	})
+})
@@ -0,0 +1,282 @@
+// Package usage accounts the requests, tokens and audio served to each API key by model, to charge them back. The
+// usage is aggregated by hour in a bbolt database, written to in batches.
+package usage
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// how often the usage recorded is written to the database
+const flushInterval = 10 * time.Second
+
+var hoursBucket = []byte("hours")
+
+// Usage is what was served to an API key with a model
+type Usage struct {
+	Requests         int64   `json:"requests"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	AudioSeconds     float64 `json:"audio_seconds"`
+}
+
+func (u *Usage) add(o Usage) {
+	u.Requests += o.Requests
+	u.PromptTokens += o.PromptTokens
+	u.CompletionTokens += o.CompletionTokens
+	u.AudioSeconds += o.AudioSeconds
+}
+
+// Record is a request served
+type Record struct {
+	Time time.Time
+	// Key identifies the API key of the caller, empty for the anonymous calls
+	Key string
+	// Tenant is the tenant of the caller, if any
+	Tenant           string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	AudioSeconds     float64
+}
+
+// Entry is the usage of an API key with a model over the period queried
+type Entry struct {
+	Key    string `json:"key"`
+	Tenant string `json:"tenant,omitempty"`
+	Model  string `json:"model"`
+	Usage
+}
+
+// Query selects the usage of the hours from From (included) to To (excluded), of the given key, tenant and model if
+// set. The zero times do not bound the period.
+type Query struct {
+	From   time.Time
+	To     time.Time
+	Key    string
+	Tenant string
+	Model  string
+}
+
+// the usage is aggregated by hour, key, model and tenant
+type slot struct {
+	hour               time.Time
+	key, model, tenant string
+}
+
+// the keys of the database sort by hour, then key and model. The tenant is only appended when set, as it was not
+// accounted before.
+func (s slot) dbKey() []byte {
+	k := s.hour.UTC().Format(time.RFC3339) + "\x00" + s.key + "\x00" + s.model
+	if s.tenant != "" {
+		k += "\x00" + s.tenant
+	}
+	return []byte(k)
+}
+
+func parseSlot(k []byte) (slot, bool) {
+	parts := strings.SplitN(string(k), "\x00", 4)
+	if len(parts) < 3 {
+		return slot{}, false
+	}
+	hour, err := time.Parse(time.RFC3339, parts[0])
+	if err != nil {
+		return slot{}, false
+	}
+	sl := slot{hour: hour, key: parts[1], model: parts[2]}
+	if len(parts) == 4 {
+		sl.tenant = parts[3]
+	}
+	return sl, true
+}
+
+type Store struct {
+	db *bolt.DB
+
+	mu      sync.Mutex
+	pending map[slot]Usage
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Open opens the database at path, creating it if needed
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(hoursBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	s := &Store{db: db, pending: map[slot]Usage{}, done: make(chan struct{})}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		t := time.NewTicker(flushInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-t.C:
+				s.Flush()
+			}
+		}
+	}()
+	return s, nil
+}
+
+// Add accounts a request served. It is written to the database with the next flush.
+func (s *Store) Add(r Record) {
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	sl := slot{hour: r.Time.UTC().Truncate(time.Hour), key: r.Key, model: r.Model, tenant: r.Tenant}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.pending[sl]
+	u.add(Usage{Requests: 1, PromptTokens: int64(r.PromptTokens), CompletionTokens: int64(r.CompletionTokens), AudioSeconds: r.AudioSeconds})
+	s.pending[sl] = u
+}
+
+// Flush writes the usage recorded since the last flush to the database
+func (s *Store) Flush() error {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = map[slot]Usage{}
+	s.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(hoursBucket)
+		for sl, u := range pending {
+			k := sl.dbKey()
+			if v := b.Get(k); v != nil {
+				stored := Usage{}
+				if err := json.Unmarshal(v, &stored); err != nil {
+					return err
+				}
+				u.add(stored)
+			}
+			v, err := json.Marshal(u)
+			if err != nil {
+				return err
+			}
+			if err := b.Put(k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// kept for the next flush
+		s.mu.Lock()
+		for sl, u := range pending {
+			p := s.pending[sl]
+			p.add(u)
+			s.pending[sl] = p
+		}
+		s.mu.Unlock()
+	}
+	return err
+}
+
+// matches returns true if the usage of the slot is selected by the query
+func (q Query) matches(sl slot) bool {
+	if !q.From.IsZero() && sl.hour.Before(q.From.UTC().Truncate(time.Hour)) {
+		return false
+	}
+	if !q.To.IsZero() && !sl.hour.Before(q.To) {
+		return false
+	}
+	return (q.Key == "" || sl.key == q.Key) && (q.Tenant == "" || sl.tenant == q.Tenant) && (q.Model == "" || sl.model == q.Model)
+}
+
+// Query returns the usage of each key and model over the period, sorted by key and model, along with the tenant of the
+// key. The usage not written yet is included.
+func (s *Store) Query(q Query) ([]Entry, error) {
+	totals := map[slot]Usage{}
+	addTo := func(sl slot, u Usage) {
+		total := slot{key: sl.key, model: sl.model, tenant: sl.tenant}
+		t := totals[total]
+		t.add(u)
+		totals[total] = t
+	}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(hoursBucket).Cursor()
+		k, v := c.First()
+		if !q.From.IsZero() {
+			k, v = c.Seek([]byte(q.From.UTC().Truncate(time.Hour).Format(time.RFC3339)))
+		}
+		for ; k != nil; k, v = c.Next() {
+			sl, ok := parseSlot(k)
+			if !ok {
+				continue
+			}
+			if !q.To.IsZero() && !sl.hour.Before(q.To) {
+				break
+			}
+			if !q.matches(sl) {
+				continue
+			}
+			u := Usage{}
+			if err := json.Unmarshal(v, &u); err != nil {
+				return err
+			}
+			addTo(sl, u)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	for sl, u := range s.pending {
+		if q.matches(sl) {
+			addTo(sl, u)
+		}
+	}
+	s.mu.Unlock()
+
+	entries := make([]Entry, 0, len(totals))
+	for sl, u := range totals {
+		entries = append(entries, Entry{Key: sl.key, Tenant: sl.tenant, Model: sl.model, Usage: u})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Key != entries[j].Key {
+			return entries[i].Key < entries[j].Key
+		}
+		if entries[i].Model != entries[j].Model {
+			return entries[i].Model < entries[j].Model
+		}
+		return entries[i].Tenant < entries[j].Tenant
+	})
+	return entries, nil
+}
+
+// Close writes the usage recorded and closes the database
+func (s *Store) Close() error {
+	close(s.done)
+	s.wg.Wait()
+	err := s.Flush()
+	if cerr := s.db.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,113 @@
+package usage_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-skynet/LocalAI/pkg/usage"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Usage accounting", func() {
+	var tempdir, db string
+
+	BeforeEach(func() {
+		var err error
+		tempdir, err = os.MkdirTemp("", "usage")
+		Expect(err).ToNot(HaveOccurred())
+		db = filepath.Join(tempdir, "usage.db")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempdir)
+	})
+
+	day := time.Date(2023, 11, 20, 0, 0, 0, 0, time.UTC)
+
+	It("sums the usage by key and model", func() {
+		s, err := usage.Open(db)
+		Expect(err).ToNot(HaveOccurred())
+		defer s.Close()
+
+		s.Add(usage.Record{Time: day.Add(time.Hour), Key: "team-a", Model: "mistral", PromptTokens: 10, CompletionTokens: 20})
+		s.Add(usage.Record{Time: day.Add(2 * time.Hour), Key: "team-a", Model: "mistral", PromptTokens: 5, CompletionTokens: 1})
+		s.Add(usage.Record{Time: day.Add(2 * time.Hour), Key: "team-a", Model: "whisper", AudioSeconds: 12.5})
+		s.Add(usage.Record{Time: day.Add(3 * time.Hour), Key: "team-b", Model: "mistral", PromptTokens: 1, CompletionTokens: 2})
+
+		entries, err := s.Query(usage.Query{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(Equal([]usage.Entry{
+			{Key: "team-a", Model: "mistral", Usage: usage.Usage{Requests: 2, PromptTokens: 15, CompletionTokens: 21}},
+			{Key: "team-a", Model: "whisper", Usage: usage.Usage{Requests: 1, AudioSeconds: 12.5}},
+			{Key: "team-b", Model: "mistral", Usage: usage.Usage{Requests: 1, PromptTokens: 1, CompletionTokens: 2}},
+		}))
+
+		entries, err = s.Query(usage.Query{Key: "team-a", Model: "mistral"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Requests).To(Equal(int64(2)))
+	})
+
+	It("sums the usage of the tenants", func() {
+		s, err := usage.Open(db)
+		Expect(err).ToNot(HaveOccurred())
+		defer s.Close()
+
+		s.Add(usage.Record{Time: day, Key: "a1", Tenant: "team-a", Model: "tenants/team-a/mistral", PromptTokens: 10})
+		s.Add(usage.Record{Time: day, Key: "a2", Tenant: "team-a", Model: "mistral", PromptTokens: 5})
+		s.Add(usage.Record{Time: day, Key: "b1", Tenant: "team-b", Model: "mistral", PromptTokens: 1})
+		s.Add(usage.Record{Time: day, Key: "static", Model: "mistral", PromptTokens: 2})
+		Expect(s.Flush()).To(Succeed())
+
+		entries, err := s.Query(usage.Query{Tenant: "team-a"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(Equal([]usage.Entry{
+			{Key: "a1", Tenant: "team-a", Model: "tenants/team-a/mistral", Usage: usage.Usage{Requests: 1, PromptTokens: 10}},
+			{Key: "a2", Tenant: "team-a", Model: "mistral", Usage: usage.Usage{Requests: 1, PromptTokens: 5}},
+		}))
+
+		entries, err = s.Query(usage.Query{Model: "mistral"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(3))
+	})
+
+	It("queries the hours of a period", func() {
+		s, err := usage.Open(db)
+		Expect(err).ToNot(HaveOccurred())
+		defer s.Close()
+
+		for h := 0; h < 48; h++ {
+			s.Add(usage.Record{Time: day.Add(time.Duration(h)*time.Hour + 30*time.Minute), Key: "team-a", Model: "mistral", CompletionTokens: 1})
+		}
+
+		entries, err := s.Query(usage.Query{From: day.Add(24 * time.Hour)})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries[0].CompletionTokens).To(Equal(int64(24)))
+
+		entries, err = s.Query(usage.Query{From: day.Add(10 * time.Hour), To: day.Add(12 * time.Hour)})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries[0].CompletionTokens).To(Equal(int64(2)))
+
+		entries, err = s.Query(usage.Query{From: day.Add(72 * time.Hour)})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+
+	It("persists the usage", func() {
+		s, err := usage.Open(db)
+		Expect(err).ToNot(HaveOccurred())
+		s.Add(usage.Record{Time: day, Key: "team-a", Model: "mistral", PromptTokens: 3})
+		Expect(s.Close()).To(Succeed())
+
+		s, err = usage.Open(db)
+		Expect(err).ToNot(HaveOccurred())
+		defer s.Close()
+		s.Add(usage.Record{Time: day, Key: "team-a", Model: "mistral", PromptTokens: 4})
+
+		entries, err := s.Query(usage.Query{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(Equal([]usage.Entry{{Key: "team-a", Model: "mistral", Usage: usage.Usage{Requests: 2, PromptTokens: 7}}}))
+	})
+})
@@ -0,0 +1,469 @@
+// Package vectorstore stores collections of vectors with their metadata and searches the nearest ones, for the
+// applications doing retrieval augmented generation without an external vector database. The collections are kept in
+// memory and saved to a JSON file each, so their size is bound by the memory of LocalAI; the search is exhaustive.
+package vectorstore
+
+import (
+	"container/heap"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The metrics of the collections. The scores of the matches are higher for the nearest vectors: the cosine
+// similarity, the dot product or the opposite of the euclidean distance.
+const (
+	MetricCosine    = "cosine"
+	MetricDot       = "dot"
+	MetricEuclidean = "euclidean"
+)
+
+var (
+	ErrNotFound = errors.New("collection not found")
+	ErrExists   = errors.New("collection already exists")
+)
+
+var validName = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// Collection describes a collection of vectors
+type Collection struct {
+	Name       string `json:"name"`
+	Dimensions int    `json:"dimensions"`
+	Metric     string `json:"metric"`
+	// Model is the embedding model of the texts upserted or queried without their vectors, if any
+	Model   string    `json:"model,omitempty"`
+	Count   int       `json:"count"`
+	Created time.Time `json:"created"`
+}
+
+// Vector is an entry of a collection
+type Vector struct {
+	ID       string                 `json:"id"`
+	Values   []float32              `json:"values,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Match is a vector found by a query, with its score
+type Match struct {
+	Vector
+	Score float64 `json:"score"`
+}
+
+type collection struct {
+	Collection
+	Vectors map[string]Vector `json:"vectors"`
+}
+
+type Store struct {
+	dir string
+
+	mu          sync.RWMutex
+	collections map[string]*collection
+}
+
+// Open loads the collections stored in dir, if it exists
+func Open(dir string) (*Store, error) {
+	s := &Store{dir: dir, collections: map[string]*collection{}}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		dat, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		c := &collection{}
+		if err := json.Unmarshal(dat, c); err != nil {
+			return nil, fmt.Errorf("failed parsing the vector store collection %s: %w", f, err)
+		}
+		if c.Vectors == nil {
+			c.Vectors = map[string]Vector{}
+		}
+		s.collections[c.Name] = c
+	}
+	return s, nil
+}
+
+func (s *Store) path(name string) string {
+	return filepath.Join(s.dir, name+".json")
+}
+
+// save writes the collection to a temporary file first, to never leave a truncated file. It must be called with
+// the lock held.
+func (s *Store) save(c *collection) error {
+	c.Count = len(c.Vectors)
+	dat, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+	tmp := s.path(c.Name) + ".tmp"
+	if err := os.WriteFile(tmp, dat, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path(c.Name))
+}
+
+// Create creates an empty collection of vectors of the given dimensions, compared with the metric (cosine when empty).
+// The model is the one embedding the texts of the collection, it is only recorded.
+func (s *Store) Create(name string, dimensions int, metric, model string) (Collection, error) {
+	if !validName.MatchString(name) {
+		return Collection{}, fmt.Errorf("invalid collection name %q: letters, digits, '-' and '_' only", name)
+	}
+	if dimensions <= 0 {
+		return Collection{}, fmt.Errorf("the dimensions of the collection must be positive")
+	}
+	switch metric {
+	case "":
+		metric = MetricCosine
+	case MetricCosine, MetricDot, MetricEuclidean:
+	default:
+		return Collection{}, fmt.Errorf("unknown metric %q, expected one of %s, %s or %s", metric, MetricCosine, MetricDot, MetricEuclidean)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.collections[name]; ok {
+		return Collection{}, fmt.Errorf("%w: %s", ErrExists, name)
+	}
+	c := &collection{
+		Collection: Collection{Name: name, Dimensions: dimensions, Metric: metric, Model: model, Created: time.Now().UTC()},
+		Vectors:    map[string]Vector{},
+	}
+	if err := s.save(c); err != nil {
+		return Collection{}, err
+	}
+	s.collections[name] = c
+	return c.Collection, nil
+}
+
+// Get returns the collection
+func (s *Store) Get(name string) (Collection, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.collections[name]
+	if !ok {
+		return Collection{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return c.Collection, nil
+}
+
+// List returns the collections, sorted by name
+func (s *Store) List() []Collection {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := []Collection{}
+	for _, c := range s.collections {
+		list = append(list, c.Collection)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Drop deletes the collection and its vectors
+func (s *Store) Drop(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.collections[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err := os.Remove(s.path(name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	delete(s.collections, name)
+	return nil
+}
+
+// Upsert adds the vectors to the collection, replacing the ones with the same IDs. Either all the vectors are
+// stored, or none when one is invalid.
+func (s *Store) Upsert(name string, vectors []Vector) (Collection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.collections[name]
+	if !ok {
+		return Collection{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	for i, v := range vectors {
+		if v.ID == "" {
+			return Collection{}, fmt.Errorf("vector %d has no id", i)
+		}
+		if len(v.Values) != c.Dimensions {
+			return Collection{}, fmt.Errorf("vector %s has %d dimensions, the collection %d", v.ID, len(v.Values), c.Dimensions)
+		}
+	}
+
+	previous := map[string]Vector{}
+	for _, v := range vectors {
+		if old, ok := c.Vectors[v.ID]; ok {
+			previous[v.ID] = old
+		}
+		c.Vectors[v.ID] = v
+	}
+	if err := s.save(c); err != nil {
+		for _, v := range vectors {
+			delete(c.Vectors, v.ID)
+		}
+		for id, v := range previous {
+			c.Vectors[id] = v
+		}
+		c.Count = len(c.Vectors)
+		return Collection{}, err
+	}
+	return c.Collection, nil
+}
+
+// Delete removes the vectors with the IDs, and the ones matching the filter when it is not empty. It returns the
+// number of vectors removed.
+func (s *Store) Delete(name string, ids []string, filter map[string]interface{}) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.collections[name]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	removed := map[string]Vector{}
+	for _, id := range ids {
+		if v, ok := c.Vectors[id]; ok {
+			removed[id] = v
+		}
+	}
+	if len(filter) > 0 {
+		for id, v := range c.Vectors {
+			match, err := Matches(v.Metadata, filter)
+			if err != nil {
+				return 0, err
+			}
+			if match {
+				removed[id] = v
+			}
+		}
+	}
+	if len(removed) == 0 {
+		return 0, nil
+	}
+	for id := range removed {
+		delete(c.Vectors, id)
+	}
+	if err := s.save(c); err != nil {
+		for id, v := range removed {
+			c.Vectors[id] = v
+		}
+		c.Count = len(c.Vectors)
+		return 0, err
+	}
+	return len(removed), nil
+}
+
+// Query returns the topK vectors of the collection nearest to the vector, among the ones matching the filter, from
+// the nearest. The values of the vectors are returned if withValues is true.
+func (s *Store) Query(name string, vector []float32, topK int, filter map[string]interface{}, withValues bool) ([]Match, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.collections[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if len(vector) != c.Dimensions {
+		return nil, fmt.Errorf("the query has %d dimensions, the collection %d", len(vector), c.Dimensions)
+	}
+	if topK <= 0 {
+		return nil, fmt.Errorf("top_k must be positive")
+	}
+	// validated once, so that an invalid filter fails on the empty collections too
+	if _, err := Matches(nil, filter); err != nil {
+		return nil, err
+	}
+
+	h := &matches{}
+	for _, v := range c.Vectors {
+		if len(filter) > 0 {
+			if match, _ := Matches(v.Metadata, filter); !match {
+				continue
+			}
+		}
+		score := similarity(c.Metric, vector, v.Values)
+		if h.Len() < topK {
+			heap.Push(h, Match{Vector: v, Score: score})
+		} else if score > (*h)[0].Score {
+			(*h)[0] = Match{Vector: v, Score: score}
+			heap.Fix(h, 0)
+		}
+	}
+
+	result := make([]Match, h.Len())
+	for i := len(result) - 1; i >= 0; i-- {
+		m := heap.Pop(h).(Match)
+		if !withValues {
+			m.Values = nil
+		}
+		result[i] = m
+	}
+	return result, nil
+}
+
+// matches is a min-heap of the best matches found, by score and then by ID for the results to be stable
+type matches []Match
+
+func (m matches) Len() int { return len(m) }
+func (m matches) Less(i, j int) bool {
+	if m[i].Score != m[j].Score {
+		return m[i].Score < m[j].Score
+	}
+	return m[i].ID > m[j].ID
+}
+func (m matches) Swap(i, j int)       { m[i], m[j] = m[j], m[i] }
+func (m *matches) Push(x interface{}) { *m = append(*m, x.(Match)) }
+func (m *matches) Pop() interface{} {
+	old := *m
+	x := old[len(old)-1]
+	*m = old[:len(old)-1]
+	return x
+}
+
+func similarity(metric string, a, b []float32) float64 {
+	var dot, na, nb, dist float64
+	for i := range a {
+		x, y := float64(a[i]), float64(b[i])
+		dot += x * y
+		na += x * x
+		nb += y * y
+		dist += (x - y) * (x - y)
+	}
+	switch metric {
+	case MetricDot:
+		return dot
+	case MetricEuclidean:
+		return -math.Sqrt(dist)
+	default:
+		if na == 0 || nb == 0 {
+			return 0
+		}
+		return dot / (math.Sqrt(na) * math.Sqrt(nb))
+	}
+}
+
+// Matches returns true if the metadata matches all the conditions of the filter. A condition is either a value the
+// field must equal, or an object of operators: $eq, $ne, $in, $nin, $gt, $gte, $lt and $lte.
+func Matches(metadata map[string]interface{}, filter map[string]interface{}) (bool, error) {
+	match := true
+	for field, cond := range filter {
+		value, present := metadata[field]
+		ops, isOps := cond.(map[string]interface{})
+		if !isOps {
+			match = match && present && equal(value, cond)
+			continue
+		}
+		for op, arg := range ops {
+			ok, err := apply(op, value, present, arg)
+			if err != nil {
+				return false, fmt.Errorf("filter of %s: %w", field, err)
+			}
+			match = match && ok
+		}
+	}
+	return match, nil
+}
+
+func apply(op string, value interface{}, present bool, arg interface{}) (bool, error) {
+	switch op {
+	case "$eq":
+		return present && equal(value, arg), nil
+	case "$ne":
+		return !present || !equal(value, arg), nil
+	case "$in", "$nin":
+		list, ok := arg.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("%s expects a list", op)
+		}
+		in := false
+		for _, a := range list {
+			in = in || (present && equal(value, a))
+		}
+		return in == (op == "$in"), nil
+	case "$gt", "$gte", "$lt", "$lte":
+		c, ok := compare(value, arg)
+		if _, isNumber := number(arg); !isNumber {
+			if _, isString := arg.(string); !isString {
+				return false, fmt.Errorf("%s expects a number or a string", op)
+			}
+		}
+		if !present || !ok {
+			return false, nil
+		}
+		switch op {
+		case "$gt":
+			return c > 0, nil
+		case "$gte":
+			return c >= 0, nil
+		case "$lt":
+			return c < 0, nil
+		default:
+			return c <= 0, nil
+		}
+	default:
+		return false, fmt.Errorf("unknown operator %q", op)
+	}
+}
+
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func equal(a, b interface{}) bool {
+	if c, ok := compare(a, b); ok {
+		return c == 0
+	}
+	if x, ok := a.(bool); ok {
+		y, ok := b.(bool)
+		return ok && x == y
+	}
+	return a == nil && b == nil
+}
+
+// compare orders two numbers or two strings
+func compare(a, b interface{}) (int, bool) {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	}
+	x, ok := a.(string)
+	if !ok {
+		return 0, false
+	}
+	y, ok := b.(string)
+	if !ok {
+		return 0, false
+	}
+	return strings.Compare(x, y), true
+}
@@ -0,0 +1,13 @@
+package vectorstore_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestVectorStore(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Vector store test suite")
+}
@@ -0,0 +1,154 @@
+package vectorstore_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/go-skynet/LocalAI/pkg/vectorstore"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Vector store", func() {
+	var dir string
+	var s *vectorstore.Store
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "vectorstore")
+		Expect(err).ToNot(HaveOccurred())
+		s, err = vectorstore.Open(dir)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	docs := []vectorstore.Vector{
+		{ID: "a", Values: []float32{1, 0, 0}, Metadata: map[string]interface{}{"lang": "en", "year": 2021.0}},
+		{ID: "b", Values: []float32{0.9, 0.1, 0}, Metadata: map[string]interface{}{"lang": "fr", "year": 2023.0}},
+		{ID: "c", Values: []float32{0, 1, 0}, Metadata: map[string]interface{}{"lang": "en", "year": 2024.0}},
+	}
+
+	It("creates the collections and keeps them across restarts", func() {
+		_, err := s.Create("docs", 3, "", "")
+		Expect(err).ToNot(HaveOccurred())
+		_, err = s.Create("docs", 3, "", "")
+		Expect(errors.Is(err, vectorstore.ErrExists)).To(BeTrue())
+		_, err = s.Create("../docs", 3, "", "")
+		Expect(err).To(HaveOccurred())
+		_, err = s.Create("other", 3, "manhattan", "")
+		Expect(err).To(HaveOccurred())
+
+		c, err := s.Upsert("docs", docs)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c.Count).To(Equal(3))
+		Expect(c.Metric).To(Equal(vectorstore.MetricCosine))
+
+		s, err = vectorstore.Open(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.List()).To(HaveLen(1))
+		matches, err := s.Query("docs", []float32{1, 0, 0}, 1, nil, true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(matches[0].ID).To(Equal("a"))
+		Expect(matches[0].Values).To(Equal([]float32{1, 0, 0}))
+
+		Expect(s.Drop("docs")).To(Succeed())
+		Expect(errors.Is(s.Drop("docs"), vectorstore.ErrNotFound)).To(BeTrue())
+		s, err = vectorstore.Open(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.List()).To(BeEmpty())
+	})
+
+	It("refuses the vectors of other dimensions", func() {
+		_, err := s.Create("docs", 3, "", "")
+		Expect(err).ToNot(HaveOccurred())
+		_, err = s.Upsert("docs", []vectorstore.Vector{docs[0], {ID: "d", Values: []float32{1, 0}}})
+		Expect(err).To(HaveOccurred())
+		c, _ := s.Get("docs")
+		Expect(c.Count).To(Equal(0))
+		_, err = s.Query("docs", []float32{1, 0}, 1, nil, false)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns the nearest vectors first", func() {
+		_, err := s.Create("docs", 3, vectorstore.MetricEuclidean, "")
+		Expect(err).ToNot(HaveOccurred())
+		_, err = s.Upsert("docs", docs)
+		Expect(err).ToNot(HaveOccurred())
+
+		matches, err := s.Query("docs", []float32{1, 0, 0}, 2, nil, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(matches).To(HaveLen(2))
+		Expect(matches[0].ID).To(Equal("a"))
+		Expect(matches[1].ID).To(Equal("b"))
+		Expect(matches[0].Score).To(BeNumerically(">", matches[1].Score))
+		Expect(matches[0].Values).To(BeNil())
+		Expect(matches[1].Metadata["lang"]).To(Equal("fr"))
+
+		// replaced by id
+		_, err = s.Upsert("docs", []vectorstore.Vector{{ID: "c", Values: []float32{1, 0, 0}}})
+		Expect(err).ToNot(HaveOccurred())
+		matches, err = s.Query("docs", []float32{1, 0, 0}, 10, nil, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(matches).To(HaveLen(3))
+		Expect(matches[2].ID).To(Equal("b"))
+	})
+
+	It("filters the vectors by their metadata", func() {
+		_, err := s.Create("docs", 3, "", "")
+		Expect(err).ToNot(HaveOccurred())
+		_, err = s.Upsert("docs", docs)
+		Expect(err).ToNot(HaveOccurred())
+
+		filter := func(js string) map[string]interface{} {
+			f := map[string]interface{}{}
+			Expect(json.Unmarshal([]byte(js), &f)).To(Succeed())
+			return f
+		}
+		ids := func(matches []vectorstore.Match) []string {
+			list := []string{}
+			for _, m := range matches {
+				list = append(list, m.ID)
+			}
+			return list
+		}
+
+		matches, err := s.Query("docs", []float32{1, 0, 0}, 10, filter(`{"lang": "en"}`), false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ids(matches)).To(Equal([]string{"a", "c"}))
+
+		matches, err = s.Query("docs", []float32{1, 0, 0}, 10, filter(`{"year": {"$gte": 2023}, "lang": {"$in": ["en", "fr"]}}`), false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ids(matches)).To(Equal([]string{"b", "c"}))
+
+		matches, err = s.Query("docs", []float32{1, 0, 0}, 10, filter(`{"lang": {"$ne": "en"}}`), false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ids(matches)).To(Equal([]string{"b"}))
+
+		_, err = s.Query("docs", []float32{1, 0, 0}, 10, filter(`{"lang": {"$like": "e%"}}`), false)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("deletes the vectors by id and by filter", func() {
+		_, err := s.Create("docs", 3, "", "")
+		Expect(err).ToNot(HaveOccurred())
+		_, err = s.Upsert("docs", docs)
+		Expect(err).ToNot(HaveOccurred())
+
+		n, err := s.Delete("docs", []string{"a", "unknown"}, map[string]interface{}{"lang": "fr"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n).To(Equal(2))
+		c, _ := s.Get("docs")
+		Expect(c.Count).To(Equal(1))
+
+		s, err = vectorstore.Open(dir)
+		Expect(err).ToNot(HaveOccurred())
+		matches, err := s.Query("docs", []float32{1, 0, 0}, 10, nil, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(matches).To(HaveLen(1))
+		Expect(matches[0].ID).To(Equal("c"))
+	})
+})
@@ -0,0 +1,216 @@
+// Package workspace manages the directories where LocalAI writes temporary artifacts
+// (uploaded audio, downloaded and generated images, backends scratch files).
+// Artifacts older than a maximum age are removed periodically, and the oldest ones
+// are evicted first when the managed directories grow over a size quota.
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type Workspace struct {
+	dir      string
+	quota    int64
+	maxAge   time.Duration
+	interval time.Duration
+	managed  []string
+}
+
+type Option func(*Workspace)
+
+// WithQuota sets the maximum size in bytes of the managed directories (0 means no limit)
+func WithQuota(bytes int64) Option {
+	return func(w *Workspace) {
+		w.quota = bytes
+	}
+}
+
+// WithMaxAge sets after how long artifacts are removed (0 means never)
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(w *Workspace) {
+		w.maxAge = maxAge
+	}
+}
+
+func WithInterval(interval time.Duration) Option {
+	return func(w *Workspace) {
+		w.interval = interval
+	}
+}
+
+// WithManagedDirs adds directories which are cleaned up along with the workspace,
+// e.g. the directories where generated images and audio are served from
+func WithManagedDirs(dirs ...string) Option {
+	return func(w *Workspace) {
+		for _, d := range dirs {
+			if d != "" {
+				w.managed = append(w.managed, d)
+			}
+		}
+	}
+}
+
+func New(dir string, opts ...Option) (*Workspace, error) {
+	w := &Workspace{
+		dir:      dir,
+		interval: 10 * time.Minute,
+	}
+	for _, o := range opts {
+		o(w)
+	}
+
+	if w.dir == "" {
+		return nil, fmt.Errorf("a workspace directory is required")
+	}
+	if err := os.MkdirAll(w.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed creating the workspace directory: %w", err)
+	}
+
+	return w, nil
+}
+
+// Dir returns the directory of the workspace. A nil workspace uses the default temporary directory.
+func (w *Workspace) Dir() string {
+	if w == nil {
+		return os.TempDir()
+	}
+	return w.dir
+}
+
+// MkdirTemp creates a new temporary directory in the workspace, see os.MkdirTemp
+func (w *Workspace) MkdirTemp(pattern string) (string, error) {
+	return os.MkdirTemp(w.Dir(), pattern)
+}
+
+// CreateTemp creates a new temporary file in the workspace, see os.CreateTemp
+func (w *Workspace) CreateTemp(pattern string) (*os.File, error) {
+	return os.CreateTemp(w.Dir(), pattern)
+}
+
+type entry struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// entries returns the top level entries of the managed directories, oldest first.
+// The size and modification time of a directory are the ones of the files in it.
+func (w *Workspace) entries() ([]entry, error) {
+	res := []entry{}
+	for _, dir := range append([]string{w.dir}, w.managed...) {
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		for _, f := range files {
+			e := entry{path: filepath.Join(dir, f.Name())}
+			err := filepath.Walk(e.path, func(_ string, info os.FileInfo, err error) error {
+				if err != nil {
+					// files might be removed while walking
+					if os.IsNotExist(err) {
+						return nil
+					}
+					return err
+				}
+				if info.IsDir() {
+					return nil
+				}
+				e.size += info.Size()
+				if info.ModTime().After(e.modTime) {
+					e.modTime = info.ModTime()
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			// empty directories
+			if e.modTime.IsZero() {
+				if info, err := f.Info(); err == nil {
+					e.modTime = info.ModTime()
+				}
+			}
+			res = append(res, e)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].modTime.Before(res[j].modTime) })
+	return res, nil
+}
+
+// Usage returns the size in bytes of the managed directories
+func (w *Workspace) Usage() (int64, error) {
+	entries, err := w.entries()
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, e := range entries {
+		total += e.size
+	}
+	return total, nil
+}
+
+// Cleanup removes the artifacts older than the maximum age, then the oldest
+// ones until the managed directories fit in the quota. It returns the number of bytes freed.
+func (w *Workspace) Cleanup() (int64, error) {
+	entries, err := w.entries()
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, e := range entries {
+		total += e.size
+	}
+
+	var freed int64
+	for _, e := range entries {
+		expired := w.maxAge > 0 && time.Since(e.modTime) > w.maxAge
+		overQuota := w.quota > 0 && total > w.quota
+		if !expired && !overQuota {
+			// entries are sorted by age, so the following ones are not expired either
+			break
+		}
+
+		if err := os.RemoveAll(e.path); err != nil {
+			log.Warn().Msgf("Failed removing %s from the workspace: %s", e.path, err.Error())
+			continue
+		}
+		total -= e.size
+		freed += e.size
+	}
+
+	return freed, nil
+}
+
+// Run cleans up the workspace periodically until the context is done
+func (w *Workspace) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		freed, err := w.Cleanup()
+		if err != nil {
+			log.Warn().Msgf("Failed cleaning up the workspace %s: %s", w.dir, err.Error())
+		} else if freed > 0 {
+			log.Debug().Msgf("Workspace cleanup freed %d bytes", freed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
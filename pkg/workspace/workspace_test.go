@@ -0,0 +1,100 @@
+package workspace_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/go-skynet/LocalAI/pkg/workspace"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func writeFile(path string, size int, age time.Duration) {
+	Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+	Expect(os.WriteFile(path, make([]byte, size), 0644)).To(Succeed())
+	t := time.Now().Add(-age)
+	Expect(os.Chtimes(path, t, t)).To(Succeed())
+}
+
+var _ = Describe("Workspace", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "workspace")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("creates temporary files and directories in the workspace", func() {
+		w, err := New(filepath.Join(dir, "ws"))
+		Expect(err).ToNot(HaveOccurred())
+
+		d, err := w.MkdirTemp("whisper")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filepath.Dir(d)).To(Equal(w.Dir()))
+
+		f, err := w.CreateTemp("image")
+		Expect(err).ToNot(HaveOccurred())
+		f.Close()
+		Expect(filepath.Dir(f.Name())).To(Equal(w.Dir()))
+	})
+
+	It("falls back to the default temporary directory", func() {
+		var w *Workspace
+		Expect(w.Dir()).To(Equal(os.TempDir()))
+	})
+
+	It("removes the artifacts older than the maximum age", func() {
+		w, err := New(dir, WithMaxAge(time.Hour))
+		Expect(err).ToNot(HaveOccurred())
+
+		writeFile(filepath.Join(dir, "old"), 10, 2*time.Hour)
+		writeFile(filepath.Join(dir, "olddir", "file"), 10, 2*time.Hour)
+		writeFile(filepath.Join(dir, "new"), 10, time.Minute)
+
+		freed, err := w.Cleanup()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(freed).To(Equal(int64(20)))
+		Expect(filepath.Join(dir, "old")).ToNot(BeAnExistingFile())
+		Expect(filepath.Join(dir, "olddir")).ToNot(BeADirectory())
+		Expect(filepath.Join(dir, "new")).To(BeAnExistingFile())
+	})
+
+	It("evicts the oldest artifacts of all the managed directories to fit in the quota", func() {
+		images := filepath.Join(dir, "images")
+		w, err := New(filepath.Join(dir, "ws"), WithQuota(25), WithManagedDirs(images))
+		Expect(err).ToNot(HaveOccurred())
+
+		writeFile(filepath.Join(images, "a.png"), 10, 3*time.Hour)
+		writeFile(filepath.Join(w.Dir(), "b"), 10, 2*time.Hour)
+		writeFile(filepath.Join(images, "c.png"), 10, time.Hour)
+
+		usage, err := w.Usage()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(usage).To(Equal(int64(30)))
+
+		freed, err := w.Cleanup()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(freed).To(Equal(int64(10)))
+		Expect(filepath.Join(images, "a.png")).ToNot(BeAnExistingFile())
+		Expect(filepath.Join(w.Dir(), "b")).To(BeAnExistingFile())
+		Expect(filepath.Join(images, "c.png")).To(BeAnExistingFile())
+	})
+
+	It("keeps everything without a quota or a maximum age", func() {
+		w, err := New(dir)
+		Expect(err).ToNot(HaveOccurred())
+
+		writeFile(filepath.Join(dir, "old"), 10, 1000*time.Hour)
+
+		freed, err := w.Cleanup()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(freed).To(BeZero())
+		Expect(filepath.Join(dir, "old")).To(BeAnExistingFile())
+	})
+})